@@ -0,0 +1,57 @@
+// 太初集群代理：部署在无法被管理端直接访问的集群内，主动建立反向隧道
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/taichu-system/cluster-management/internal/agent"
+)
+
+func main() {
+	config := agent.Config{}
+	flag.StringVar(&config.ServerURL, "server", os.Getenv("TAICHU_SERVER_URL"), "management plane URL, e.g. https://taichu.example.com")
+	flag.StringVar(&config.Token, "token", os.Getenv("TAICHU_AGENT_TOKEN"), "one-time registration token")
+	flag.StringVar(&config.CredentialsFile, "credentials-file", envOrDefault("TAICHU_AGENT_CREDENTIALS_FILE", "/var/lib/taichu-agent/credentials.json"), "file storing agent credentials when running outside the cluster")
+	flag.StringVar(&config.CredentialsSecret, "credentials-secret", envOrDefault("TAICHU_AGENT_CREDENTIALS_SECRET", "taichu-agent-credentials"), "secret storing agent credentials when running in the cluster")
+	flag.StringVar(&config.APIServerAddr, "apiserver", envOrDefault("TAICHU_APISERVER_ADDR", agent.DefaultAPIServerAddr), "apiserver address forwarded through the tunnel")
+	flag.StringVar(&config.CAFile, "ca-file", os.Getenv("TAICHU_CA_FILE"), "CA bundle used to verify the management plane")
+	flag.BoolVar(&config.InsecureSkipVerify, "insecure-skip-verify", envBool("TAICHU_INSECURE_SKIP_VERIFY"), "skip management plane certificate verification")
+	flag.DurationVar(&config.HeartbeatInterval, "heartbeat-interval", agent.DefaultHeartbeatInterval, "heartbeat interval")
+	flag.Parse()
+
+	a, err := agent.New(config)
+	if err != nil {
+		log.Fatalf("Failed to create agent: %v", err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	log.Printf("Starting taichu agent %s, server=%s, apiserver=%s", agent.Version, config.ServerURL, config.APIServerAddr)
+	if err := a.Run(ctx); err != nil {
+		log.Fatalf("Agent stopped: %v", err)
+	}
+
+	// 给正在转发的连接留出关闭时间
+	time.Sleep(time.Second)
+	log.Println("Agent exited")
+}
+
+func envOrDefault(key, value string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return value
+}
+
+func envBool(key string) bool {
+	v, _ := strconv.ParseBool(os.Getenv(key))
+	return v
+}
@@ -54,6 +54,10 @@ func main() {
 	}
 
 	clusterManager := service.NewClusterManager(cfg.ClusterManager.ClientTimeout, cfg.ClusterManager.MaxClients)
+	clusterManager.SetRateLimits(float32(cfg.Kubernetes.QPS), cfg.Kubernetes.Burst)
+	clusterManager.SetCircuitBreaker(cfg.ClusterManager.BreakerFailureThreshold, cfg.ClusterManager.BreakerBaseBackoff, cfg.ClusterManager.BreakerMaxBackoff)
+	clusterManager.StartCleanup(cfg.ClusterManager.CleanupInterval)
+	defer clusterManager.Stop()
 
 	clusterRepo := repository.NewClusterRepository(db)
 	stateRepo := repository.NewClusterStateRepository(db)
@@ -75,12 +79,52 @@ func main() {
 	quotaRepo := repository.NewQuotaRepository(db)
 	classificationRepo := repository.NewResourceClassificationRepository(db)
 	alertRepo := repository.NewAlertRepository(db)
+	healthHistoryRepo := repository.NewClusterHealthHistoryRepository(db)
+	machineCredentialRepo := repository.NewMachineCredentialRepository(db)
+	machineService := service.NewMachineService(machineRepo, machineCredentialRepo, encryptionService)
+	if n, err := machineService.EncryptPlaintextSecrets(); err != nil {
+		log.Printf("Warning: Failed to encrypt plaintext machine secrets: %v", err)
+	} else if n > 0 {
+		log.Printf("Encrypted secrets of %d machines stored in plaintext", n)
+	}
+
+	healthHistoryService := service.NewHealthHistoryService(
+		clusterRepo,
+		healthHistoryRepo,
+		cfg.HealthHistory.RawRetention,
+		cfg.HealthHistory.DailyRetention,
+		cfg.HealthHistory.SLOTarget,
+	)
+
+	// 集群代理的心跳写入集群状态与健康历史
+	clusterAgentService := service.NewClusterAgentService(
+		clusterRepo,
+		repository.NewClusterAgentRepository(db),
+		stateRepo,
+		healthHistoryService,
+		cfg.Agent.TokenTTL,
+		cfg.Agent.HeartbeatTimeout,
+		cfg.Agent.ConnectTimeout,
+	)
+	defer clusterAgentService.Close()
+
+	// 连接设置需在 Worker 启动前注册到 ClusterManager，保证首次检查即经由堡垒机、代理或集群代理
+	clusterConnectionService := service.NewClusterConnectionService(
+		clusterRepo,
+		repository.NewClusterConnectionRepository(db),
+		machineService,
+		encryptionService,
+		clusterManager,
+		clusterAgentService,
+	)
+	defer clusterConnectionService.Close()
 
 	healthCheckWorker := worker.NewHealthCheckWorker(
 		clusterRepo,
 		stateRepo,
 		clusterManager,
 		encryptionService,
+		healthHistoryService,
 	)
 
 	resourceClassificationWorker := worker.NewResourceClassificationWorker(
@@ -94,6 +138,7 @@ func main() {
 		classificationRepo,
 	)
 
+	resourceSyncRepo := repository.NewResourceSyncRepository(db)
 	resourceSyncWorker := worker.NewResourceSyncWorker(
 		clusterRepo,
 		nodeRepo,
@@ -104,10 +149,12 @@ func main() {
 		securityPolicyRepo,
 		clusterManager,
 		encryptionService,
+		resourceSyncRepo,
 		resourceClassificationWorker,
 	)
 
 	log.Printf("Worker.Enabled: %v", cfg.Worker.Enabled)
+	var informerResourceSyncWorker *worker.InformerResourceSyncWorker
 	if cfg.Worker.Enabled {
 		log.Println("Starting health check worker...")
 		healthCheckWorker.Start()
@@ -116,7 +163,7 @@ func main() {
 		log.Println("Starting resource sync worker...")
 		if cfg.Worker.UseInformerMode {
 			log.Println("Using Informer-based resource sync worker")
-			informerResourceSyncWorker := worker.NewInformerResourceSyncWorker(
+			informerResourceSyncWorker = worker.NewInformerResourceSyncWorker(
 				clusterRepo,
 				nodeRepo,
 				eventRepo,
@@ -126,6 +173,8 @@ func main() {
 				securityPolicyRepo,
 				clusterManager,
 				encryptionService,
+				resourceSyncRepo,
+				worker.NewClassificationSyncer(tenantRepo, environmentRepo, applicationRepo, quotaRepo, classificationRepo),
 			)
 			informerResourceSyncWorker.Start()
 			defer informerResourceSyncWorker.Stop()
@@ -135,9 +184,14 @@ func main() {
 			defer resourceSyncWorker.Stop()
 		}
 
-		log.Println("Starting resource classification worker...")
-		resourceClassificationWorker.Start()
-		defer resourceClassificationWorker.Stop()
+		// Informer 模式下由 watch 事件增量分类，不再定时全量分类；手动触发仍可使用
+		if cfg.Worker.UseInformerMode {
+			log.Println("Resource classification is driven by informer events")
+		} else {
+			log.Println("Starting resource classification worker...")
+			resourceClassificationWorker.Start()
+			defer resourceClassificationWorker.Stop()
+		}
 	} else {
 		log.Println("Worker is disabled in configuration")
 	}
@@ -153,6 +207,7 @@ func main() {
 		encryptionService,
 		clusterManager,
 		auditService,
+		healthHistoryService,
 	)
 
 	nodeService := service.NewNodeService(
@@ -162,6 +217,14 @@ func main() {
 		clusterRepo,
 	)
 
+	nodeOperationService := service.NewNodeOperationService(
+		clusterRepo,
+		repository.NewNodeDrainRepository(db),
+		clusterManager,
+		encryptionService,
+		auditService,
+	)
+
 	eventService := service.NewEventService(
 		clusterManager,
 		encryptionService,
@@ -210,6 +273,26 @@ func main() {
 		clusterEnvironmentRepo,
 	)
 
+	if cfg.ManagerAccount.GrantUserKubeconfig {
+		log.Println("Warning: manager_account.grant_user_kubeconfig is enabled, the manager token can gain admin (including Secrets) in any namespace")
+	}
+	clusterCredentialRepo := repository.NewClusterCredentialRepository(db)
+	managerAccountService := service.NewManagerAccountService(
+		clusterRepo,
+		repository.NewClusterServiceAccountRepository(db),
+		clusterCredentialRepo,
+		encryptionService,
+		clusterManager,
+		cfg.ManagerAccount.Namespace,
+		cfg.ManagerAccount.TokenTTL,
+		cfg.ManagerAccount.RotateBefore,
+		cfg.ManagerAccount.GrantImpersonation,
+		cfg.ManagerAccount.GrantUserKubeconfig,
+		cfg.Kubeconfig.ServiceAccountNamespace,
+	)
+
+	clusterFingerprintService := service.NewClusterFingerprintService(clusterRepo, encryptionService, clusterManager)
+	importValidator := service.NewImportValidator(clusterRepo, cfg.Import.MinVersion, cfg.Import.MaxVersion, cfg.Import.ValidationTimeout)
 	importService := service.NewImportService(
 		importRepo,
 		clusterRepo,
@@ -220,8 +303,24 @@ func main() {
 		environmentRepo,
 		applicationRepo,
 		quotaRepo,
+		clusterConnectionService,
+		managerAccountService,
+		importValidator,
 	)
 
+	clusterCredentialService := service.NewClusterCredentialService(
+		clusterRepo,
+		clusterCredentialRepo,
+		encryptionService,
+		clusterManager,
+		importValidator,
+		managerAccountService,
+	)
+	if informerResourceSyncWorker != nil {
+		clusterCredentialService.AddCredentialListener(informerResourceSyncWorker)
+		managerAccountService.AddCredentialListener(informerResourceSyncWorker)
+	}
+
 	expansionRepo := repository.NewExpansionRepository(db)
 	expansionService := service.NewExpansionService(
 		expansionRepo,
@@ -231,13 +330,95 @@ func main() {
 	)
 
 	// 创建新服务
-	machineService := service.NewMachineService(machineRepo)
 	configGenerator := service.NewConfigGenerator()
+	provisioners := []service.Provisioner{
+		service.NewKKProvisioner(configGenerator),
+		service.NewKubeadmProvisioner(configGenerator),
+	}
+	if cfg.Provisioner.EnableFake {
+		provisioners = append(provisioners, service.NewFakeProvisioner())
+	}
+	provisionerRegistry := service.NewProvisionerRegistry(cfg.Provisioner.DefaultDriver, provisioners...)
+	clusterTemplateRepo := repository.NewClusterTemplateRepository(db)
+	clusterTemplateService := service.NewClusterTemplateService(clusterTemplateRepo, provisionerRegistry)
 	createClusterService := service.NewCreateClusterService(
 		createTaskRepo,
 		machineService,
-		configGenerator,
+		provisionerRegistry,
+		clusterTemplateService,
+		clusterService,
+		encryptionService,
+	)
+	clusterDecommissionService := service.NewClusterDecommissionService(
+		clusterRepo,
+		stateRepo,
+		nodeRepo,
+		backupRepo,
+		createTaskRepo,
+		repository.NewClusterDecommissionRepository(db),
+		repository.NewClusterArchiveRepository(db),
+		machineService,
+		provisionerRegistry,
+		backupService,
+		clusterManager,
+		encryptionService,
+		auditService,
+	)
+
+	certificateService := service.NewCertificateService(
+		clusterRepo,
+		repository.NewClusterCertificateRepository(db),
+		repository.NewCertificateRotationRepository(db),
+		machineService,
+		clusterManager,
+		encryptionService,
+		alertService,
+		auditService,
+	)
+	maintenanceCampaignService := service.NewMaintenanceCampaignService(
+		clusterRepo,
+		repository.NewMaintenanceCampaignRepository(db),
+		machineService,
+		clusterManager,
+		encryptionService,
+		auditService,
+	)
+	deprecatedAPIService := service.NewDeprecatedAPIService(
+		clusterRepo,
+		environmentRepo,
+		applicationRepo,
+		clusterManager,
+		encryptionService,
 	)
+	if n, err := maintenanceCampaignService.RecoverInterrupted(); err != nil {
+		log.Printf("Warning: Failed to recover interrupted maintenance campaigns: %v", err)
+	} else if n > 0 {
+		log.Printf("Paused %d maintenance campaigns interrupted by restart", n)
+	}
+	if n, err := nodeOperationService.RecoverInterrupted(); err != nil {
+		log.Printf("Warning: Failed to recover interrupted node drains: %v", err)
+	} else if n > 0 {
+		log.Printf("Marked %d node drains interrupted by restart as failed", n)
+	}
+	if n, err := clusterDecommissionService.RecoverInterrupted(); err != nil {
+		log.Printf("Warning: Failed to recover interrupted cluster decommissions: %v", err)
+	} else if n > 0 {
+		log.Printf("Marked %d cluster decommissions interrupted by restart as failed", n)
+	}
+
+	if cfg.Worker.Enabled {
+		certificateExpiryWorker := worker.NewCertificateExpiryWorker(clusterRepo, certificateService)
+		certificateExpiryWorker.Start()
+		defer certificateExpiryWorker.Stop()
+
+		healthHistoryWorker := worker.NewHealthHistoryWorker(healthHistoryService, cfg.HealthHistory.CompactInterval)
+		healthHistoryWorker.Start()
+		defer healthHistoryWorker.Stop()
+
+		managerTokenWorker := worker.NewManagerTokenRotationWorker(managerAccountService, cfg.ManagerAccount.RotationInterval)
+		managerTokenWorker.Start()
+		defer managerTokenWorker.Stop()
+	}
 
 	// 创建认证服务和处理器
 	userRepo := repository.NewUserRepository(db)
@@ -245,7 +426,7 @@ func main() {
 	authHandler := handler.NewAuthHandler(authService, auditService)
 
 	// 三级分类模型相关服务
-	tenantService := service.NewTenantService(tenantRepo, quotaRepo, environmentRepo, auditService)
+	tenantService := service.NewTenantService(tenantRepo, quotaRepo, environmentRepo, auditService, repository.NewTenantMemberRepository(db), userRepo)
 	environmentService := service.NewEnvironmentService(environmentRepo, quotaRepo, auditService)
 	applicationService := service.NewApplicationService(applicationRepo, quotaRepo, auditService)
 	kubeClient, _ := kubernetesService.GetClientset("")
@@ -264,15 +445,51 @@ func main() {
 	)
 
 	nodeHandler := handler.NewNodeHandler(nodeService)
+	nodeOperationHandler := handler.NewNodeOperationHandler(nodeOperationService, auditService)
 	eventHandler := handler.NewEventHandler(eventService)
 	securityPolicyHandler := handler.NewSecurityPolicyHandler(securityPolicyService)
 	autoscalingPolicyHandler := handler.NewAutoscalingPolicyHandler(autoscalingPolicyService)
 	backupHandler := handler.NewBackupHandler(backupService, restoreService, auditService)
 	topologyHandler := handler.NewTopologyHandler(topologyService)
 	importHandler := handler.NewImportHandler(importService, healthCheckWorker, resourceSyncWorker, auditService)
+	clusterFingerprintHandler := handler.NewClusterFingerprintHandler(clusterFingerprintService)
+	clusterCredentialHandler := handler.NewClusterCredentialHandler(clusterCredentialService, auditService)
 	auditHandler := handler.NewAuditHandler(auditService)
 	expansionHandler := handler.NewExpansionHandler(expansionService)
 	machineHandler := handler.NewMachineHandler(machineService, auditService)
+	clusterTemplateHandler := handler.NewClusterTemplateHandler(clusterTemplateService, auditService)
+	clusterDecommissionHandler := handler.NewClusterDecommissionHandler(clusterDecommissionService, auditService)
+	certificateHandler := handler.NewCertificateHandler(certificateService, auditService)
+	maintenanceCampaignHandler := handler.NewMaintenanceCampaignHandler(maintenanceCampaignService, auditService)
+	deprecatedAPIHandler := handler.NewDeprecatedAPIHandler(deprecatedAPIService)
+	nodeInventoryHandler := handler.NewNodeInventoryHandler(service.NewNodeInventoryService(clusterRepo, stateRepo, nodeRepo))
+	healthHistoryHandler := handler.NewHealthHistoryHandler(healthHistoryService)
+	clusterAgentHandler := handler.NewClusterAgentHandler(clusterAgentService, auditService)
+	userKubeconfigRepo := repository.NewUserKubeconfigRepository(db)
+	clusterProxyService := service.NewClusterProxyService(clusterRepo, encryptionService, clusterManager, tenantService, userKubeconfigRepo, cfg.APIProxy.UserPrefix, cfg.APIProxy.GroupPrefix)
+	clusterProxyHandler := handler.NewClusterProxyHandler(clusterProxyService, auditService)
+	userKubeconfigService := service.NewUserKubeconfigService(
+		clusterRepo,
+		userKubeconfigRepo,
+		environmentRepo,
+		encryptionService,
+		clusterManager,
+		clusterConnectionService,
+		tenantService,
+		clusterProxyService,
+		"your-secret-key",
+		cfg.Kubeconfig.DefaultTTL,
+		cfg.Kubeconfig.MaxTTL,
+		cfg.Kubeconfig.ServiceAccountNamespace,
+		cfg.Kubeconfig.ServerURL,
+	)
+	userKubeconfigHandler := handler.NewUserKubeconfigHandler(userKubeconfigService, auditService, cfg.Kubeconfig.TrustForwardedHeaders)
+	if cfg.Worker.Enabled {
+		kubeconfigExpiryWorker := worker.NewKubeconfigExpiryWorker(userKubeconfigService, cfg.Kubeconfig.CleanupInterval)
+		kubeconfigExpiryWorker.Start()
+		defer kubeconfigExpiryWorker.Stop()
+	}
+	clusterConnectionHandler := handler.NewClusterConnectionHandler(clusterConnectionService, auditService)
 
 	// 三级分类模型相关Handler
 	tenantHandler := handler.NewTenantHandler(tenantService, constraintValidator)
@@ -290,7 +507,7 @@ func main() {
 		nil,
 	)
 
-	r := setupRoutes(clusterHandler, nodeHandler, eventHandler, securityPolicyHandler, autoscalingPolicyHandler, backupHandler, topologyHandler, importHandler, auditHandler, expansionHandler, machineHandler, authHandler, tenantHandler, environmentHandler, applicationHandler, constraintHandler, resourceClassificationHandler)
+	r := setupRoutes(clusterHandler, nodeHandler, eventHandler, securityPolicyHandler, autoscalingPolicyHandler, backupHandler, topologyHandler, importHandler, auditHandler, expansionHandler, machineHandler, authHandler, tenantHandler, environmentHandler, applicationHandler, constraintHandler, resourceClassificationHandler, clusterTemplateHandler, clusterDecommissionHandler, certificateHandler, nodeOperationHandler, maintenanceCampaignHandler, deprecatedAPIHandler, nodeInventoryHandler, healthHistoryHandler, clusterConnectionHandler, clusterAgentHandler, clusterProxyHandler, userKubeconfigHandler, clusterFingerprintHandler, clusterCredentialHandler)
 
 	srv := &http.Server{
 		Addr:    ":" + cfg.Server.Port,
@@ -464,6 +681,20 @@ func setupRoutes(
 	applicationHandler *handler.ApplicationHandler,
 	constraintHandler *handler.ConstraintHandler,
 	resourceClassificationHandler *handler.ResourceClassificationHandler,
+	clusterTemplateHandler *handler.ClusterTemplateHandler,
+	clusterDecommissionHandler *handler.ClusterDecommissionHandler,
+	certificateHandler *handler.CertificateHandler,
+	nodeOperationHandler *handler.NodeOperationHandler,
+	maintenanceCampaignHandler *handler.MaintenanceCampaignHandler,
+	deprecatedAPIHandler *handler.DeprecatedAPIHandler,
+	nodeInventoryHandler *handler.NodeInventoryHandler,
+	healthHistoryHandler *handler.HealthHistoryHandler,
+	clusterConnectionHandler *handler.ClusterConnectionHandler,
+	clusterAgentHandler *handler.ClusterAgentHandler,
+	clusterProxyHandler *handler.ClusterProxyHandler,
+	userKubeconfigHandler *handler.UserKubeconfigHandler,
+	clusterFingerprintHandler *handler.ClusterFingerprintHandler,
+	clusterCredentialHandler *handler.ClusterCredentialHandler,
 ) *gin.Engine {
 	r := gin.New()
 
@@ -481,6 +712,13 @@ func setupRoutes(
 		auth.GET("/token", authHandler.GenerateToken) // 测试用，移除了userId参数
 	}
 
+	// 集群代理注册与反向隧道（代理以注册令牌或代理密钥认证）
+	agentRoutes := r.Group("/api/v1/agent")
+	{
+		agentRoutes.POST("/register", clusterAgentHandler.Register)
+		agentRoutes.GET("/connect", clusterAgentHandler.Connect)
+	}
+
 	// 需要认证的路由
 	v1 := r.Group("/api/v1")
 	// v1.Use(middleware.JWTMiddleware("your-secret-key"))
@@ -498,18 +736,57 @@ func setupRoutes(
 			clusters.POST("/create", clusterHandler.CreateClusterByMachines)
 			clusters.GET("", clusterHandler.ListClusters)
 			clusters.GET(":id", clusterHandler.GetCluster)
+			clusters.GET(":id/health", clusterHandler.GetClusterHealth)
+			clusters.GET(":id/health/uptime", healthHistoryHandler.GetUptime)
+			clusters.GET(":id/health/incidents", healthHistoryHandler.ListIncidents)
+			clusters.GET(":id/health/slo", healthHistoryHandler.GetSLO)
+			clusters.GET(":id/connection", clusterConnectionHandler.GetConnection)
+			clusters.PUT(":id/connection", clusterConnectionHandler.UpdateConnection)
+			clusters.DELETE(":id/connection", clusterConnectionHandler.DeleteConnection)
+			clusters.POST(":id/connection/test", clusterConnectionHandler.TestConnection)
+			clusters.GET(":id/agent", clusterAgentHandler.GetAgent)
+			clusters.POST(":id/agent/token", clusterAgentHandler.IssueToken)
+			clusters.DELETE(":id/agent", clusterAgentHandler.RevokeAgent)
+
+			// Kubernetes API 代理，以 JWT 用户身份模拟访问集群，同时接受代理模式 kubeconfig 的令牌
+			proxy := clusters.Group(":id/proxy", middleware.ProxyJWTMiddleware("your-secret-key"))
+			proxy.Any("/*path", clusterProxyHandler.Proxy)
+
+			// 用户 kubeconfig，权限限定在所属租户环境的命名空间
+			clusters.POST(":id/kubeconfigs", middleware.JWTMiddleware("your-secret-key"), userKubeconfigHandler.Issue)
+			clusters.GET(":id/kubeconfigs", userKubeconfigHandler.List)
+			clusters.DELETE(":id/kubeconfigs/:kubeconfigId", userKubeconfigHandler.Revoke)
 			clusters.DELETE(":id", clusterHandler.DeleteCluster)
+			clusters.POST(":id/decommission", clusterDecommissionHandler.DecommissionCluster)
+			clusters.GET(":id/decommission", clusterDecommissionHandler.GetClusterDecommission)
 
 			// 集群导入接口
 			clusters.POST("/import", importHandler.ImportCluster)
+			clusters.POST("/import/contexts", importHandler.ListKubeconfigContexts)
+			clusters.POST("/import/bulk", importHandler.BulkImport)
 			clusters.GET("/imports", importHandler.ListImports)
+			clusters.GET("/duplicates", clusterFingerprintHandler.GetDuplicateReport)
+			clusters.POST(":id/fingerprint", clusterFingerprintHandler.RefreshFingerprint)
+			clusters.PUT(":id/credentials", clusterCredentialHandler.UpdateCredentials)
+			clusters.GET(":id/credentials", clusterCredentialHandler.ListCredentials)
+			clusters.POST(":id/credentials/:credentialId/rollback", clusterCredentialHandler.RollbackCredentials)
 
 			// 节点相关接口
 			nodes := clusters.Group(":id/nodes")
 			{
 				nodes.GET("", nodeHandler.ListNodes)
 				nodes.GET(":nodeName", nodeHandler.GetNode)
+				nodes.POST(":nodeName/cordon", nodeOperationHandler.CordonNode)
+				nodes.POST(":nodeName/uncordon", nodeOperationHandler.UncordonNode)
+				nodes.POST(":nodeName/drain", nodeOperationHandler.DrainNode)
+				nodes.GET(":nodeName/drains", nodeOperationHandler.ListDrainTasks)
+				nodes.PATCH(":nodeName/labels", nodeOperationHandler.UpdateNodeLabels)
+				nodes.PATCH(":nodeName/taints", nodeOperationHandler.UpdateNodeTaints)
 			}
+			clusters.GET(":id/node-drains/:taskId", nodeOperationHandler.GetDrainTask)
+			// 按标签选择器批量修改节点标签与污点
+			clusters.PATCH(":id/node-labels", nodeOperationHandler.BulkUpdateNodeLabels)
+			clusters.PATCH(":id/node-taints", nodeOperationHandler.BulkUpdateNodeTaints)
 
 			// 事件相关接口
 			events := clusters.Group(":id/events")
@@ -565,6 +842,19 @@ func setupRoutes(
 				backupSchedules.DELETE(":scheduleId", backupHandler.DeleteBackupSchedule)
 			}
 
+			// 证书到期检查与轮换接口
+			certificates := clusters.Group(":id/certificates")
+			{
+				certificates.GET("", certificateHandler.GetClusterCertificates)
+				certificates.POST("/check", certificateHandler.CheckClusterCertificates)
+				certificates.POST("/rotate", certificateHandler.RotateClusterCertificates)
+				certificates.GET("/rotations", certificateHandler.ListCertificateRotations)
+				certificates.GET("/rotations/:rotationId", certificateHandler.GetCertificateRotation)
+			}
+
+			// 升级前弃用API扫描
+			clusters.GET(":id/deprecated-apis", deprecatedAPIHandler.ScanDeprecatedAPIs)
+
 			// 审计相关接口
 			audit := clusters.Group(":id/audit")
 			{
@@ -596,6 +886,18 @@ func setupRoutes(
 			machines.PUT(":id/status", machineHandler.UpdateMachineStatus)
 		}
 
+		// 机器共享凭据接口
+		machineCredentials := v1.Group("/machine-credentials")
+		{
+			machineCredentials.POST("", machineHandler.CreateCredential)
+			machineCredentials.GET("", machineHandler.ListCredentials)
+			machineCredentials.GET(":id", machineHandler.GetCredential)
+			machineCredentials.PUT(":id", machineHandler.UpdateCredential)
+			machineCredentials.DELETE(":id", machineHandler.DeleteCredential)
+			machineCredentials.POST(":id/assign", machineHandler.AssignCredential)
+			machineCredentials.POST(":id/unassign", machineHandler.UnassignCredential)
+		}
+
 		// 创建任务接口
 		createTasks := v1.Group("/create-tasks")
 		{
@@ -603,6 +905,42 @@ func setupRoutes(
 			createTasks.GET(":taskId", clusterHandler.GetCreateTask)
 		}
 
+		// 部署驱动接口
+		v1.GET("/provisioners", clusterHandler.ListProvisioners)
+
+		// 集群模板接口
+		clusterTemplates := v1.Group("/cluster-templates")
+		{
+			clusterTemplates.POST("", clusterTemplateHandler.CreateTemplate)
+			clusterTemplates.GET("", clusterTemplateHandler.ListTemplates)
+			clusterTemplates.GET(":id", clusterTemplateHandler.GetTemplate)
+			clusterTemplates.DELETE(":id", clusterTemplateHandler.DeleteTemplate)
+			clusterTemplates.GET(":id/versions", clusterTemplateHandler.ListVersions)
+			clusterTemplates.POST(":id/versions", clusterTemplateHandler.PublishVersion)
+		}
+
+		// 集群退役任务与归档接口
+		v1.GET("/cluster-decommissions", clusterDecommissionHandler.ListDecommissions)
+		v1.GET("/cluster-decommissions/:id", clusterDecommissionHandler.GetDecommission)
+		v1.GET("/cluster-archives", clusterDecommissionHandler.ListArchives)
+		v1.GET("/cluster-archives/:id", clusterDecommissionHandler.GetArchive)
+
+		// 全部集群中即将到期的证书
+		v1.GET("/certificates/expiring", certificateHandler.ListExpiringCertificates)
+
+		// 全部集群的版本偏差与节点运行时报告
+		v1.GET("/reports/version-skew", nodeInventoryHandler.GetFleetVersionReport)
+
+		// 节点滚动维护接口
+		maintenanceCampaigns := v1.Group("/maintenance-campaigns")
+		{
+			maintenanceCampaigns.POST("", maintenanceCampaignHandler.CreateCampaign)
+			maintenanceCampaigns.GET("", maintenanceCampaignHandler.ListCampaigns)
+			maintenanceCampaigns.GET(":id", maintenanceCampaignHandler.GetCampaign)
+			maintenanceCampaigns.POST(":id/resume", maintenanceCampaignHandler.ResumeCampaign)
+			maintenanceCampaigns.POST(":id/cancel", maintenanceCampaignHandler.CancelCampaign)
+		}
+
 		// 三级分类模型接口
 		tenants := v1.Group("/tenants")
 		{
@@ -614,6 +952,9 @@ func setupRoutes(
 			tenants.GET(":id/quota", tenantHandler.GetTenantQuota)
 			tenants.PUT(":id/quota", tenantHandler.UpdateTenantQuota)
 			tenants.GET(":id/environments", tenantHandler.GetTenantEnvironments)
+			tenants.GET(":id/members", tenantHandler.ListTenantMembers)
+			tenants.POST(":id/members", tenantHandler.AddTenantMember)
+			tenants.DELETE(":id/members/:userId", tenantHandler.RemoveTenantMember)
 			tenants.GET("/predefined", tenantHandler.PredefinedTenants)
 			tenants.POST("/predefined/initialize", tenantHandler.InitializePredefinedTenants)
 		}
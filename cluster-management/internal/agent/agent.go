@@ -0,0 +1,371 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/net/websocket"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// DefaultAPIServerAddr 集群内访问 apiserver 的默认地址
+	DefaultAPIServerAddr = "kubernetes.default.svc:443"
+
+	dialTimeout   = 15 * time.Second
+	minRetryDelay = time.Second
+	maxRetryDelay = time.Minute
+	probeTimeout  = 10 * time.Second
+)
+
+// Config 集群代理配置
+type Config struct {
+	ServerURL          string // 管理端地址，如 https://taichu.example.com
+	Token              string // 一次性注册令牌，已注册时忽略
+	CredentialsFile    string // 集群外运行时保存代理密钥的文件
+	CredentialsSecret  string // 集群内运行时保存代理密钥的 Secret 名称
+	APIServerAddr      string // 隧道转发的 apiserver 地址
+	CAFile             string // 校验管理端证书的 CA
+	InsecureSkipVerify bool
+	HeartbeatInterval  time.Duration
+}
+
+// Agent 集群代理：主动连接管理端并转发其对 apiserver 的访问
+type Agent struct {
+	config     Config
+	tlsConfig  *tls.Config
+	httpClient *http.Client
+	sshConfig  *ssh.ServerConfig
+	store      credentialStore
+	clientset  kubernetes.Interface // 集群内运行时用于心跳探测，集群外为空
+}
+
+// New 创建集群代理
+func New(config Config) (*Agent, error) {
+	if config.ServerURL == "" {
+		return nil, errors.New("server url is required")
+	}
+	if config.APIServerAddr == "" {
+		config.APIServerAddr = DefaultAPIServerAddr
+	}
+	if config.HeartbeatInterval <= 0 {
+		config.HeartbeatInterval = DefaultHeartbeatInterval
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify}
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	// 隧道内的 SSH 仅用于多路复用，身份已由代理密钥在 WebSocket 握手时认证
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate host key: %w", err)
+	}
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create host key signer: %w", err)
+	}
+	sshConfig := &ssh.ServerConfig{NoClientAuth: true}
+	sshConfig.AddHostKey(signer)
+
+	a := &Agent{
+		config:    config,
+		tlsConfig: tlsConfig,
+		httpClient: &http.Client{
+			Timeout:   dialTimeout,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment},
+		},
+		sshConfig: sshConfig,
+	}
+
+	if restConfig, err := rest.InClusterConfig(); err == nil {
+		if a.clientset, err = kubernetes.NewForConfig(restConfig); err != nil {
+			return nil, fmt.Errorf("failed to create in-cluster client: %w", err)
+		}
+	}
+	if a.clientset != nil && config.CredentialsSecret != "" {
+		a.store = &secretStore{clientset: a.clientset, namespace: podNamespace(), name: config.CredentialsSecret}
+	} else {
+		if config.CredentialsFile == "" {
+			return nil, errors.New("credentials file is required when running outside the cluster")
+		}
+		a.store = &fileStore{path: config.CredentialsFile}
+	}
+	return a, nil
+}
+
+// Run 注册并保持隧道连接，断开后指数退避重连，直到 ctx 取消
+func (a *Agent) Run(ctx context.Context) error {
+	credentials, err := a.credentials(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("[AGENT] registered for cluster %s", credentials.ClusterID)
+
+	delay := minRetryDelay
+	for {
+		start := time.Now()
+		err := a.serve(ctx, credentials)
+		if ctx.Err() != nil {
+			return nil
+		}
+		// 连接保持过一段时间说明配置无误，重置退避
+		if time.Since(start) > maxRetryDelay {
+			delay = minRetryDelay
+		}
+		log.Printf("[AGENT] tunnel closed: %v, reconnecting in %s", err, delay)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+// credentials 读取已保存的代理密钥，不存在时使用注册令牌注册
+func (a *Agent) credentials(ctx context.Context) (*RegisterResponse, error) {
+	credentials, err := a.store.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agent credentials: %w", err)
+	}
+	if credentials != nil {
+		return credentials, nil
+	}
+	if a.config.Token == "" {
+		return nil, errors.New("agent is not registered and no registration token was provided")
+	}
+
+	credentials, err = a.register(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.store.Save(ctx, credentials); err != nil {
+		return nil, fmt.Errorf("failed to save agent credentials: %w", err)
+	}
+	return credentials, nil
+}
+
+// register 使用一次性令牌换取代理密钥
+func (a *Agent) register(ctx context.Context) (*RegisterResponse, error) {
+	hostname, _ := os.Hostname()
+	body, err := json.Marshal(RegisterRequest{Token: a.config.Token, AgentVersion: Version, Hostname: hostname})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(a.config.ServerURL, "/")+RegisterPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register agent: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Code    int              `json:"code"`
+		Message string           `json:"message"`
+		Data    RegisterResponse `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode register response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || result.Code != 0 {
+		return nil, fmt.Errorf("agent registration rejected: %s", result.Message)
+	}
+	return &result.Data, nil
+}
+
+// serve 建立一条隧道并处理管理端的转发请求，隧道断开时返回
+func (a *Agent) serve(ctx context.Context, credentials *RegisterResponse) error {
+	ws, err := a.dial(ctx, credentials.Secret)
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+
+	conn, chans, reqs, err := ssh.NewServerConn(ws, a.sshConfig)
+	if err != nil {
+		return fmt.Errorf("tunnel handshake failed: %w", err)
+	}
+	defer conn.Close()
+	log.Printf("[AGENT] tunnel established to %s", a.config.ServerURL)
+
+	go ssh.DiscardRequests(reqs)
+	stop := make(chan struct{})
+	defer close(stop)
+	go a.heartbeat(conn, stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-stop:
+		}
+	}()
+
+	for ch := range chans {
+		if ch.ChannelType() != "direct-tcpip" {
+			ch.Reject(ssh.UnknownChannelType, "only direct-tcpip is supported")
+			continue
+		}
+		go a.forward(ch)
+	}
+	return conn.Wait()
+}
+
+// dial 以 WebSocket 连接管理端
+func (a *Agent) dial(ctx context.Context, secret string) (*websocket.Conn, error) {
+	server, err := url.Parse(strings.TrimRight(a.config.ServerURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid server url: %w", err)
+	}
+	location := *server
+	switch server.Scheme {
+	case "https":
+		location.Scheme = "wss"
+	case "http":
+		location.Scheme = "ws"
+	default:
+		return nil, fmt.Errorf("unsupported server url scheme %q", server.Scheme)
+	}
+	location.Path += ConnectPath
+
+	config, err := websocket.NewConfig(location.String(), server.String())
+	if err != nil {
+		return nil, err
+	}
+	config.TlsConfig = a.tlsConfig
+	config.Dialer = &net.Dialer{Timeout: dialTimeout}
+	config.Header = http.Header{}
+	config.Header.Set("Authorization", "Bearer "+secret)
+	config.Header.Set("User-Agent", "taichu-agent/"+Version)
+
+	dialCtx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+	ws, err := config.DialContext(dialCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect %s: %w", location.String(), err)
+	}
+	ws.PayloadType = websocket.BinaryFrame
+	return ws, nil
+}
+
+// forward 将管理端的通道转发到 apiserver，忽略请求中的目标地址，隧道只能访问 apiserver
+func (a *Agent) forward(newChannel ssh.NewChannel) {
+	target, err := net.DialTimeout("tcp", a.config.APIServerAddr, dialTimeout)
+	if err != nil {
+		newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	ch, reqs, err := newChannel.Accept()
+	if err != nil {
+		target.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		io.Copy(target, ch)
+		if tcp, ok := target.(*net.TCPConn); ok {
+			tcp.CloseWrite()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		io.Copy(ch, target)
+		ch.CloseWrite()
+	}()
+	wg.Wait()
+	ch.Close()
+	target.Close()
+}
+
+// heartbeat 定期上报心跳，上报失败说明隧道已断开
+func (a *Agent) heartbeat(conn ssh.Conn, stop <-chan struct{}) {
+	ticker := time.NewTicker(a.config.HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		payload, err := json.Marshal(a.probe())
+		if err == nil {
+			if _, _, err := conn.SendRequest(HeartbeatRequest, true, payload); err != nil {
+				log.Printf("[AGENT] heartbeat failed: %v", err)
+				conn.Close()
+				return
+			}
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probe 探测 apiserver，集群内运行时读取版本与节点数，否则仅检查端口可达
+func (a *Agent) probe() Heartbeat {
+	heartbeat := Heartbeat{AgentVersion: Version, Timestamp: time.Now()}
+	if a.clientset == nil {
+		conn, err := net.DialTimeout("tcp", a.config.APIServerAddr, probeTimeout)
+		if err != nil {
+			heartbeat.Error = err.Error()
+			return heartbeat
+		}
+		conn.Close()
+		heartbeat.APIServerReachable = true
+		return heartbeat
+	}
+
+	info, err := a.clientset.Discovery().ServerVersion()
+	if err != nil {
+		heartbeat.Error = err.Error()
+		return heartbeat
+	}
+	heartbeat.APIServerReachable = true
+	heartbeat.KubernetesVersion = info.GitVersion
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	nodes, err := a.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		heartbeat.Error = fmt.Sprintf("failed to list nodes: %v", err)
+		return heartbeat
+	}
+	heartbeat.NodeCount = len(nodes.Items)
+	return heartbeat
+}
@@ -0,0 +1,126 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	credentialsKey       = "credentials.json"
+	serviceAccountNSFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// credentialStore 保存注册后获得的代理密钥，注册令牌只能使用一次，重启后需从此处恢复
+type credentialStore interface {
+	Load(ctx context.Context) (*RegisterResponse, error)
+	Save(ctx context.Context, credentials *RegisterResponse) error
+}
+
+// fileStore 将代理密钥保存到本地文件
+type fileStore struct {
+	path string
+}
+
+func (s *fileStore) Load(ctx context.Context) (*RegisterResponse, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return decodeCredentials(data)
+}
+
+func (s *fileStore) Save(ctx context.Context, credentials *RegisterResponse) error {
+	data, err := json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0o600)
+}
+
+// secretStore 集群内运行时将代理密钥保存到所在命名空间的 Secret
+type secretStore struct {
+	clientset kubernetes.Interface
+	namespace string
+	name      string
+}
+
+func (s *secretStore) Load(ctx context.Context) (*RegisterResponse, error) {
+	secret, err := s.clientset.CoreV1().Secrets(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	data, ok := secret.Data[credentialsKey]
+	if !ok {
+		return nil, nil
+	}
+	return decodeCredentials(data)
+}
+
+func (s *secretStore) Save(ctx context.Context, credentials *RegisterResponse) error {
+	data, err := json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+	secrets := s.clientset.CoreV1().Secrets(s.namespace)
+	secret, err := secrets.Get(ctx, s.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = secrets.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: s.name, Namespace: s.namespace},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       map[string][]byte{credentialsKey: data},
+		}, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+	secret.Data[credentialsKey] = data
+	_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+	return err
+}
+
+func decodeCredentials(data []byte) (*RegisterResponse, error) {
+	var credentials RegisterResponse
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		return nil, fmt.Errorf("invalid agent credentials: %w", err)
+	}
+	if credentials.Secret == "" {
+		return nil, errors.New("invalid agent credentials: secret is empty")
+	}
+	return &credentials, nil
+}
+
+// podNamespace 代理所在命名空间，优先使用 POD_NAMESPACE 环境变量
+func podNamespace() string {
+	if ns := os.Getenv("POD_NAMESPACE"); ns != "" {
+		return ns
+	}
+	if data, err := os.ReadFile(serviceAccountNSFile); err == nil {
+		if ns := strings.TrimSpace(string(data)); ns != "" {
+			return ns
+		}
+	}
+	return "default"
+}
@@ -0,0 +1,47 @@
+package agent
+
+import "time"
+
+// 管理端与集群代理之间的协议约定
+// 代理主动以 WebSocket 连接管理端，并在该连接上运行 SSH 服务端：
+// 管理端作为 SSH 客户端通过 direct-tcpip 通道访问 apiserver，代理通过全局请求上报心跳
+const (
+	// RegisterPath 使用一次性注册令牌换取代理密钥
+	RegisterPath = "/api/v1/agent/register"
+	// ConnectPath 建立反向隧道，请求头 Authorization: Bearer <代理密钥>
+	ConnectPath = "/api/v1/agent/connect"
+
+	// HeartbeatRequest 心跳全局请求类型，负载为 JSON 编码的 Heartbeat
+	HeartbeatRequest = "heartbeat@taichu-system"
+	// SSHUser 隧道内 SSH 握手使用的用户名，身份已由代理密钥认证
+	SSHUser = "taichu-agent"
+
+	// DefaultHeartbeatInterval 默认心跳间隔
+	DefaultHeartbeatInterval = 30 * time.Second
+)
+
+// Version 代理版本，构建时可通过 -ldflags 覆盖
+var Version = "0.1.0"
+
+// RegisterRequest 代理注册请求
+type RegisterRequest struct {
+	Token        string `json:"token"`
+	AgentVersion string `json:"agent_version"`
+	Hostname     string `json:"hostname"`
+}
+
+// RegisterResponse 代理注册结果，Secret 仅返回一次，由代理自行持久化
+type RegisterResponse struct {
+	ClusterID string `json:"cluster_id"`
+	Secret    string `json:"secret"`
+}
+
+// Heartbeat 代理心跳，携带代理侧观察到的 apiserver 状态
+type Heartbeat struct {
+	AgentVersion       string    `json:"agent_version"`
+	KubernetesVersion  string    `json:"kubernetes_version,omitempty"`
+	APIServerReachable bool      `json:"apiserver_reachable"`
+	NodeCount          int       `json:"node_count"`
+	Error              string    `json:"error,omitempty"`
+	Timestamp          time.Time `json:"timestamp"`
+}
@@ -14,6 +14,13 @@ type Config struct {
 	Worker         WorkerConfig         `mapstructure:"worker"`
 	Logging        LoggingConfig        `mapstructure:"logging"`
 	Kubernetes     KubernetesConfig     `mapstructure:"kubernetes"`
+	Provisioner    ProvisionerConfig    `mapstructure:"provisioner"`
+	HealthHistory  HealthHistoryConfig  `mapstructure:"health_history"`
+	Agent          AgentConfig          `mapstructure:"agent"`
+	APIProxy       APIProxyConfig       `mapstructure:"api_proxy"`
+	Kubeconfig     KubeconfigConfig     `mapstructure:"kubeconfig"`
+	ManagerAccount ManagerAccountConfig `mapstructure:"manager_account"`
+	Import         ImportConfig         `mapstructure:"import"`
 }
 
 type ServerConfig struct {
@@ -45,10 +52,15 @@ type EncryptionConfig struct {
 	Algorithm string `mapstructure:"algorithm"`
 }
 
+// ClusterManagerConfig 集群客户端池配置，空闲超过 CleanupInterval 的客户端被移除
+// 集群连续 BreakerFailureThreshold 次请求失败后熔断，熔断时长从 BreakerBaseBackoff 起每次加倍，最长 BreakerMaxBackoff
 type ClusterManagerConfig struct {
-	ClientTimeout   time.Duration `mapstructure:"client_timeout"`
-	MaxClients      int           `mapstructure:"max_clients"`
-	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+	ClientTimeout           time.Duration `mapstructure:"client_timeout"`
+	MaxClients              int           `mapstructure:"max_clients"`
+	CleanupInterval         time.Duration `mapstructure:"cleanup_interval"`
+	BreakerFailureThreshold int           `mapstructure:"breaker_failure_threshold"`
+	BreakerBaseBackoff      time.Duration `mapstructure:"breaker_base_backoff"`
+	BreakerMaxBackoff       time.Duration `mapstructure:"breaker_max_backoff"`
 }
 
 type WorkerConfig struct {
@@ -72,6 +84,60 @@ type KubernetesConfig struct {
 	Timeout time.Duration `mapstructure:"timeout"`
 }
 
+type ProvisionerConfig struct {
+	DefaultDriver string `mapstructure:"default_driver"`
+	EnableFake    bool   `mapstructure:"enable_fake"`
+}
+
+// HealthHistoryConfig 集群健康历史的保留、降采样与默认 SLO 目标
+type HealthHistoryConfig struct {
+	RawRetention    time.Duration `mapstructure:"raw_retention"`
+	DailyRetention  time.Duration `mapstructure:"daily_retention"`
+	CompactInterval time.Duration `mapstructure:"compact_interval"`
+	SLOTarget       float64       `mapstructure:"slo_target"`
+}
+
+// AgentConfig 集群代理的注册令牌有效期、心跳超时与导入时等待代理连接的时长
+type AgentConfig struct {
+	TokenTTL         time.Duration `mapstructure:"token_ttl"`
+	HeartbeatTimeout time.Duration `mapstructure:"heartbeat_timeout"`
+	ConnectTimeout   time.Duration `mapstructure:"connect_timeout"`
+}
+
+// APIProxyConfig 集群 API 代理模拟身份使用的用户名与用户组前缀
+type APIProxyConfig struct {
+	UserPrefix  string `mapstructure:"user_prefix"`
+	GroupPrefix string `mapstructure:"group_prefix"`
+}
+
+// KubeconfigConfig 用户 kubeconfig 签发的有效期、ServiceAccount 命名空间与代理模式使用的平台地址
+// TrustForwardedHeaders 仅在平台部署于会改写 X-Forwarded-* 请求头的反向代理之后时开启
+type KubeconfigConfig struct {
+	DefaultTTL              time.Duration `mapstructure:"default_ttl"`
+	MaxTTL                  time.Duration `mapstructure:"max_ttl"`
+	ServiceAccountNamespace string        `mapstructure:"service_account_namespace"`
+	ServerURL               string        `mapstructure:"server_url"`
+	TrustForwardedHeaders   bool          `mapstructure:"trust_forwarded_headers"`
+	CleanupInterval         time.Duration `mapstructure:"cleanup_interval"`
+}
+
+// ManagerAccountConfig 最小权限导入时创建的 taichu-manager ServiceAccount 的命名空间、令牌有效期与轮换设置
+type ManagerAccountConfig struct {
+	Namespace           string        `mapstructure:"namespace"`
+	TokenTTL            time.Duration `mapstructure:"token_ttl"`
+	RotateBefore        time.Duration `mapstructure:"rotate_before"`
+	RotationInterval    time.Duration `mapstructure:"rotation_interval"`
+	GrantImpersonation  bool          `mapstructure:"grant_impersonation"`
+	GrantUserKubeconfig bool          `mapstructure:"grant_user_kubeconfig"`
+}
+
+// ImportConfig 导入校验支持的 Kubernetes 版本范围与校验超时
+type ImportConfig struct {
+	MinVersion        string        `mapstructure:"min_version"`
+	MaxVersion        string        `mapstructure:"max_version"`
+	ValidationTimeout time.Duration `mapstructure:"validation_timeout"`
+}
+
 func Load() (*Config, error) {
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
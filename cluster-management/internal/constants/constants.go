@@ -9,6 +9,8 @@ const (
 	StatusActive    = "active"
 	StatusInactive  = "inactive"
 	StatusDeleted   = "deleted"
+	StatusPaused    = "paused"
+	StatusCancelled = "cancelled"
 )
 
 const (
@@ -60,6 +62,11 @@ const (
 	ClusterStatusDeleting  = "deleting"
 )
 
+const (
+	// ClusterSourcePlatform 由平台在机器上创建的集群
+	ClusterSourcePlatform = "platform"
+)
+
 const (
 	ApplicationStatusCreating = "creating"
 	ApplicationStatusRunning  = "running"
@@ -111,3 +118,6 @@ const (
 	AuthInvalidUserRoleFormat = "Invalid user role format"
 	AuthInsufficientPermissions = "Insufficient permissions"
 )
+
+// KubeconfigProxyAudience 代理模式 kubeconfig 令牌的 audience，这类令牌只能访问集群 API 代理
+const KubeconfigProxyAudience = "cluster-api-proxy"
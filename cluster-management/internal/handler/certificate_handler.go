@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taichu-system/cluster-management/internal/constants"
+	"github.com/taichu-system/cluster-management/internal/service"
+	"github.com/taichu-system/cluster-management/pkg/utils"
+)
+
+// CertificateHandler 集群证书处理器
+type CertificateHandler struct {
+	certificateService *service.CertificateService
+	auditService       *service.AuditService
+}
+
+// NewCertificateHandler 创建集群证书处理器
+func NewCertificateHandler(certificateService *service.CertificateService, auditService *service.AuditService) *CertificateHandler {
+	return &CertificateHandler{
+		certificateService: certificateService,
+		auditService:       auditService,
+	}
+}
+
+// GetClusterCertificates 获取集群最近一次检查的证书
+func (h *CertificateHandler) GetClusterCertificates(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	certificates, err := h.certificateService.GetCertificates(id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, gin.H{
+		"certificates": certificates,
+		"total":        len(certificates),
+	})
+}
+
+// CheckClusterCertificates 立即检查集群证书，部分来源失败时返回已采集的结果与错误信息
+func (h *CertificateHandler) CheckClusterCertificates(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Minute)
+	defer cancel()
+
+	certificates, err := h.certificateService.CheckClusterByID(ctx, id)
+	if certificates == nil && err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	result := gin.H{
+		"certificates": certificates,
+		"total":        len(certificates),
+	}
+	if err != nil {
+		result["errors"] = err.Error()
+	}
+	utils.Success(c, http.StatusOK, result)
+}
+
+// ListExpiringCertificates 获取全部集群中即将到期的证书
+func (h *CertificateHandler) ListExpiringCertificates(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid days")
+		return
+	}
+
+	certificates, err := h.certificateService.ListExpiring(days)
+	if err != nil {
+		utils.Error(c, utils.ErrCodeInternalError, "Failed to list certificates: %v", err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, gin.H{
+		"certificates": certificates,
+		"total":        len(certificates),
+		"days":         days,
+	})
+}
+
+// RotateClusterCertificates 续期平台创建集群的控制平面证书
+func (h *CertificateHandler) RotateClusterCertificates(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	rotation, err := h.certificateService.StartRotation(id, "api-user")
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	if h.auditService != nil {
+		h.auditService.CreateAuditEvent(
+			id,
+			constants.EventTypeUpdate,
+			"start_certificate_rotation",
+			constants.ResourceTypeCluster,
+			id.String(),
+			"api-user",
+			c.ClientIP(),
+			c.GetHeader("User-Agent"),
+			nil,
+			nil,
+			map[string]interface{}{
+				"rotation_id": rotation.ID.String(),
+			},
+			constants.StatusSuccess,
+		)
+	}
+
+	utils.Success(c, http.StatusAccepted, rotation)
+}
+
+// ListCertificateRotations 获取集群证书轮换任务
+func (h *CertificateHandler) ListCertificateRotations(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	rotations, err := h.certificateService.ListRotations(id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, gin.H{
+		"rotations": rotations,
+		"total":     len(rotations),
+	})
+}
+
+// GetCertificateRotation 获取证书轮换任务详情
+func (h *CertificateHandler) GetCertificateRotation(c *gin.Context) {
+	rotationID, err := utils.ParseUUID(c.Param("rotationId"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid rotation ID")
+		return
+	}
+
+	rotation, err := h.certificateService.GetRotation(rotationID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	if rotation.ClusterID.String() != c.Param("id") {
+		utils.Error(c, utils.ErrCodeNotFound, "Certificate rotation not found")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, rotation)
+}
+
+// handleError 转换证书相关错误
+func (h *CertificateHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrClusterNotFound):
+		utils.Error(c, utils.ErrCodeNotFound, "Cluster not found")
+	case errors.Is(err, service.ErrRotationNotFound):
+		utils.Error(c, utils.ErrCodeNotFound, "Certificate rotation not found")
+	case errors.Is(err, service.ErrRotationInProgress):
+		utils.Error(c, utils.ErrCodeConflict, "Certificate rotation already in progress")
+	case errors.Is(err, service.ErrRotationNotSupported):
+		utils.Error(c, utils.ErrCodeValidationFailed, "%v", err)
+	default:
+		utils.Error(c, utils.ErrCodeInternalError, "Certificate operation failed: %v", err)
+	}
+}
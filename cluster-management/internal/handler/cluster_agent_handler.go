@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/agent"
+	"github.com/taichu-system/cluster-management/internal/constants"
+	"github.com/taichu-system/cluster-management/internal/service"
+	"github.com/taichu-system/cluster-management/pkg/utils"
+	"golang.org/x/net/websocket"
+)
+
+// ClusterAgentHandler 集群代理处理器
+type ClusterAgentHandler struct {
+	agentService *service.ClusterAgentService
+	auditService *service.AuditService
+}
+
+// NewClusterAgentHandler 创建集群代理处理器
+func NewClusterAgentHandler(agentService *service.ClusterAgentService, auditService *service.AuditService) *ClusterAgentHandler {
+	return &ClusterAgentHandler{
+		agentService: agentService,
+		auditService: auditService,
+	}
+}
+
+// IssueToken 为集群签发一次性注册令牌，代理首次启动时使用
+func (h *ClusterAgentHandler) IssueToken(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	token, err := h.agentService.IssueToken(id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.audit(c, id, constants.EventTypeCreate, "issue_agent_token", map[string]interface{}{
+		"expires_at": token.ExpiresAt,
+	})
+	utils.Success(c, http.StatusCreated, token)
+}
+
+// GetAgent 获取集群代理状态
+func (h *ClusterAgentHandler) GetAgent(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	view, err := h.agentService.Get(id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	utils.Success(c, http.StatusOK, view)
+}
+
+// RevokeAgent 吊销集群代理并断开隧道
+func (h *ClusterAgentHandler) RevokeAgent(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	if err := h.agentService.Revoke(id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.audit(c, id, constants.EventTypeDelete, "revoke_cluster_agent", nil)
+	utils.Success(c, http.StatusOK, gin.H{"message": "Cluster agent revoked"})
+}
+
+// Register 代理使用一次性注册令牌换取代理密钥
+func (h *ClusterAgentHandler) Register(c *gin.Context) {
+	var req agent.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid request body: %v", err)
+		return
+	}
+
+	resp, err := h.agentService.Register(req, c.ClientIP())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	clusterID, _ := uuid.Parse(resp.ClusterID)
+	h.audit(c, clusterID, constants.EventTypeCreate, "register_cluster_agent", map[string]interface{}{
+		"hostname":      req.Hostname,
+		"agent_version": req.AgentVersion,
+	})
+	utils.Success(c, http.StatusOK, resp)
+}
+
+// Connect 代理建立反向隧道，升级为 WebSocket 后阻塞到隧道断开
+func (h *ClusterAgentHandler) Connect(c *gin.Context) {
+	secret := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	clusterID, err := h.agentService.Authenticate(secret)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	remoteAddr := c.ClientIP()
+	server := websocket.Server{
+		// 代理不是浏览器，不校验 Origin
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			ws.PayloadType = websocket.BinaryFrame
+			// 清除 HTTP 服务的读写超时，隧道为长连接
+			ws.SetDeadline(time.Time{})
+			if err := h.agentService.Serve(clusterID, ws, remoteAddr); err != nil {
+				log.Printf("[AGENT] tunnel for cluster %s closed: %v", clusterID, err)
+			}
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+func (h *ClusterAgentHandler) audit(c *gin.Context, clusterID uuid.UUID, eventType, action string, details map[string]interface{}) {
+	if h.auditService == nil {
+		return
+	}
+	h.auditService.CreateAuditEvent(
+		clusterID,
+		eventType,
+		action,
+		constants.ResourceTypeCluster,
+		clusterID.String(),
+		"api-user",
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+		nil,
+		nil,
+		details,
+		constants.StatusSuccess,
+	)
+}
+
+// handleError 转换集群代理相关错误
+func (h *ClusterAgentHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrClusterNotFound):
+		utils.Error(c, utils.ErrCodeNotFound, "Cluster not found")
+	case errors.Is(err, service.ErrAgentNotFound):
+		utils.Error(c, utils.ErrCodeNotFound, "Cluster agent not found")
+	case errors.Is(err, service.ErrInvalidAgentToken), errors.Is(err, service.ErrAgentUnauthorized):
+		utils.Error(c, utils.ErrCodeUnauthorized, "%v", err)
+	default:
+		utils.Error(c, utils.ErrCodeInternalError, "Failed to process cluster agent: %v", err)
+	}
+}
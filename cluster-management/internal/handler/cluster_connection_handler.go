@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/constants"
+	"github.com/taichu-system/cluster-management/internal/service"
+	"github.com/taichu-system/cluster-management/pkg/utils"
+)
+
+// ClusterConnectionHandler 集群连接设置处理器
+type ClusterConnectionHandler struct {
+	connectionService *service.ClusterConnectionService
+	auditService      *service.AuditService
+}
+
+// NewClusterConnectionHandler 创建集群连接设置处理器
+func NewClusterConnectionHandler(connectionService *service.ClusterConnectionService, auditService *service.AuditService) *ClusterConnectionHandler {
+	return &ClusterConnectionHandler{
+		connectionService: connectionService,
+		auditService:      auditService,
+	}
+}
+
+// ClusterConnectionRequest 集群连接设置请求
+// ssh_bastion 模式需提供 bastion_credential_id，或 bastion_user 加密码/私钥；更新时未提供的密钥保留原值
+type ClusterConnectionRequest struct {
+	Mode                string     `json:"mode" binding:"required,oneof=direct ssh_bastion proxy agent"`
+	BastionHost         string     `json:"bastion_host" binding:"omitempty,max=255"`
+	BastionPort         int        `json:"bastion_port" binding:"omitempty,min=1,max=65535"`
+	BastionCredentialID *uuid.UUID `json:"bastion_credential_id"`
+	BastionUser         string     `json:"bastion_user" binding:"omitempty,max=100"`
+	BastionPassword     string     `json:"bastion_password"`
+	BastionPrivateKey   string     `json:"bastion_private_key"`
+	BastionPassphrase   string     `json:"bastion_passphrase"`
+	BastionHostKey      string     `json:"bastion_host_key"`
+	ProxyURL            string     `json:"proxy_url"`
+	TLSServerName       string     `json:"tls_server_name" binding:"omitempty,max=255"`
+}
+
+// toInput 转换为服务层参数
+func (r *ClusterConnectionRequest) toInput() service.ClusterConnectionInput {
+	return service.ClusterConnectionInput{
+		Mode:                r.Mode,
+		BastionHost:         r.BastionHost,
+		BastionPort:         r.BastionPort,
+		BastionCredentialID: r.BastionCredentialID,
+		BastionUser:         r.BastionUser,
+		BastionPassword:     r.BastionPassword,
+		BastionPrivateKey:   r.BastionPrivateKey,
+		BastionPassphrase:   r.BastionPassphrase,
+		BastionHostKey:      r.BastionHostKey,
+		ProxyURL:            r.ProxyURL,
+		TLSServerName:       r.TLSServerName,
+	}
+}
+
+// GetConnection 获取集群连接设置
+func (h *ClusterConnectionHandler) GetConnection(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	view, err := h.connectionService.Get(id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	utils.Success(c, http.StatusOK, view)
+}
+
+// UpdateConnection 保存集群连接设置，缓存的客户端与隧道随之重建
+func (h *ClusterConnectionHandler) UpdateConnection(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	var req ClusterConnectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid request body: %v", err)
+		return
+	}
+
+	view, err := h.connectionService.Save(id, req.toInput())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.audit(c, id, constants.EventTypeUpdate, "update_cluster_connection", map[string]interface{}{
+		"mode":            view.Mode,
+		"bastion_host":    view.BastionHost,
+		"proxy_url":       view.ProxyURL,
+		"tls_server_name": view.TLSServerName,
+	})
+	utils.Success(c, http.StatusOK, view)
+}
+
+// DeleteConnection 删除集群连接设置，恢复直连
+func (h *ClusterConnectionHandler) DeleteConnection(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	if err := h.connectionService.Delete(id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.audit(c, id, constants.EventTypeDelete, "delete_cluster_connection", nil)
+	utils.Success(c, http.StatusOK, gin.H{"message": "Cluster connection reset to direct"})
+}
+
+// TestConnection 使用当前连接设置访问 apiserver
+func (h *ClusterConnectionHandler) TestConnection(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	result, err := h.connectionService.Test(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	utils.Success(c, http.StatusOK, result)
+}
+
+func (h *ClusterConnectionHandler) audit(c *gin.Context, clusterID uuid.UUID, eventType, action string, details map[string]interface{}) {
+	if h.auditService == nil {
+		return
+	}
+	h.auditService.CreateAuditEvent(
+		clusterID,
+		eventType,
+		action,
+		constants.ResourceTypeCluster,
+		clusterID.String(),
+		"api-user",
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+		nil,
+		nil,
+		details,
+		constants.StatusSuccess,
+	)
+}
+
+// handleError 转换连接设置相关错误
+func (h *ClusterConnectionHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrClusterNotFound):
+		utils.Error(c, utils.ErrCodeNotFound, "Cluster not found")
+	case errors.Is(err, service.ErrInvalidConnection):
+		utils.Error(c, utils.ErrCodeValidationFailed, "%v", err)
+	default:
+		utils.Error(c, utils.ErrCodeInternalError, "Failed to process cluster connection: %v", err)
+	}
+}
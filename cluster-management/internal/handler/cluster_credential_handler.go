@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/constants"
+	"github.com/taichu-system/cluster-management/internal/repository"
+	"github.com/taichu-system/cluster-management/internal/service"
+	"github.com/taichu-system/cluster-management/pkg/utils"
+)
+
+// ClusterCredentialHandler 集群凭据更换与回滚处理器
+type ClusterCredentialHandler struct {
+	credentialService *service.ClusterCredentialService
+	auditService      *service.AuditService
+}
+
+// NewClusterCredentialHandler 创建集群凭据处理器
+func NewClusterCredentialHandler(credentialService *service.ClusterCredentialService, auditService *service.AuditService) *ClusterCredentialHandler {
+	return &ClusterCredentialHandler{
+		credentialService: credentialService,
+		auditService:      auditService,
+	}
+}
+
+// UpdateCredentials 更换集群保存的 kubeconfig，新凭据需指向同一集群
+func (h *ClusterCredentialHandler) UpdateCredentials(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	var req service.UpdateCredentialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid request body: %v", err)
+		return
+	}
+
+	user := requestUser(c)
+	result, err := h.credentialService.Update(c.Request.Context(), id, req, user)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.audit(c, id, "update_cluster_credentials", result, user)
+	utils.Success(c, http.StatusOK, result)
+}
+
+// ListCredentials 获取集群的凭据历史，不包含 kubeconfig 内容
+func (h *ClusterCredentialHandler) ListCredentials(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	credentials, err := h.credentialService.List(id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	utils.Success(c, http.StatusOK, credentials)
+}
+
+// RollbackCredentials 恢复集群的一条历史凭据
+func (h *ClusterCredentialHandler) RollbackCredentials(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+	credentialID, err := utils.ParseUUID(c.Param("credentialId"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid credential ID")
+		return
+	}
+
+	var req service.RollbackCredentialRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.Error(c, utils.ErrCodeValidationFailed, "Invalid request body: %v", err)
+			return
+		}
+	}
+
+	user := requestUser(c)
+	result, err := h.credentialService.Rollback(c.Request.Context(), id, credentialID, req, user)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.audit(c, id, "rollback_cluster_credentials", result, user)
+	utils.Success(c, http.StatusOK, result)
+}
+
+func (h *ClusterCredentialHandler) audit(c *gin.Context, clusterID uuid.UUID, action string, result *service.CredentialUpdateResult, user string) {
+	if h.auditService == nil {
+		return
+	}
+	details := map[string]interface{}{
+		"source":               result.Credential.Source,
+		"api_server_url":       result.Credential.APIServerURL,
+		"fingerprint_verified": result.Credential.FingerprintVerified,
+		"reason":               result.Credential.Reason,
+	}
+	if result.Credential.RollbackOf != nil {
+		details["rollback_of"] = result.Credential.RollbackOf.String()
+	}
+	h.auditService.CreateAuditEvent(
+		clusterID,
+		constants.EventTypeUpdate,
+		action,
+		constants.ResourceTypeCluster,
+		result.Credential.ID.String(),
+		user,
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+		nil,
+		nil,
+		details,
+		constants.StatusSuccess,
+	)
+}
+
+// handleError 转换凭据更换相关错误
+func (h *ClusterCredentialHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrClusterNotFound):
+		utils.Error(c, utils.ErrCodeNotFound, "Cluster not found")
+	case errors.Is(err, service.ErrClusterCredentialNotFound):
+		utils.Error(c, utils.ErrCodeNotFound, "Credential not found")
+	case errors.Is(err, repository.ErrClusterCredentialChanged),
+		errors.Is(err, service.ErrCredentialAlreadyActive):
+		utils.Error(c, utils.ErrCodeConflict, "%v", err)
+	case errors.Is(err, service.ErrInvalidKubeConfig),
+		errors.Is(err, service.ErrCredentialValidationFailed),
+		errors.Is(err, service.ErrCredentialFingerprintMismatch),
+		errors.Is(err, service.ErrCredentialExpired):
+		utils.Error(c, utils.ErrCodeValidationFailed, "%v", err)
+	default:
+		utils.Error(c, utils.ErrCodeInternalError, "Failed to update cluster credentials: %v", err)
+	}
+}
+
+// requestUser 当前请求的用户名，未认证时为 api-user
+func requestUser(c *gin.Context) string {
+	if user := c.GetString("username"); user != "" {
+		return user
+	}
+	return "api-user"
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taichu-system/cluster-management/internal/constants"
+	"github.com/taichu-system/cluster-management/internal/service"
+	"github.com/taichu-system/cluster-management/pkg/utils"
+)
+
+// ClusterDecommissionHandler 集群退役处理器
+type ClusterDecommissionHandler struct {
+	decommissionService *service.ClusterDecommissionService
+	auditService        *service.AuditService
+}
+
+// NewClusterDecommissionHandler 创建集群退役处理器
+func NewClusterDecommissionHandler(decommissionService *service.ClusterDecommissionService, auditService *service.AuditService) *ClusterDecommissionHandler {
+	return &ClusterDecommissionHandler{
+		decommissionService: decommissionService,
+		auditService:        auditService,
+	}
+}
+
+// DecommissionClusterRequest 集群退役请求
+type DecommissionClusterRequest struct {
+	FinalBackup   bool   `json:"final_backup"`
+	BackupType    string `json:"backup_type" binding:"omitempty,oneof=full etcd resources"`
+	RetentionDays int    `json:"retention_days" binding:"omitempty,min=1"`
+	Force         bool   `json:"force"`
+}
+
+// DecommissionCluster 退役集群：可选最终备份，重置全部节点，归档记录并回收机器
+func (h *ClusterDecommissionHandler) DecommissionCluster(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	var req DecommissionClusterRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.Error(c, utils.ErrCodeValidationFailed, "Invalid request body: %v", err)
+			return
+		}
+	}
+
+	decommission, err := h.decommissionService.StartDecommission(id, service.DecommissionOptions{
+		FinalBackup:   req.FinalBackup,
+		BackupType:    req.BackupType,
+		RetentionDays: req.RetentionDays,
+		Force:         req.Force,
+		Operator:      "api-user",
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrClusterNotFound):
+			utils.Error(c, utils.ErrCodeNotFound, "Cluster not found")
+		case errors.Is(err, service.ErrDecommissionInProgress):
+			utils.Error(c, utils.ErrCodeConflict, "Cluster decommission already in progress")
+		default:
+			utils.Error(c, utils.ErrCodeValidationFailed, "Failed to start decommission: %v", err)
+		}
+		return
+	}
+
+	if h.auditService != nil {
+		h.auditService.CreateAuditEvent(
+			id,
+			constants.EventTypeDelete,
+			"start_decommission",
+			constants.ResourceTypeCluster,
+			id.String(),
+			"api-user",
+			c.ClientIP(),
+			c.GetHeader("User-Agent"),
+			nil,
+			nil,
+			map[string]interface{}{
+				"decommission_id": decommission.ID.String(),
+				"name":            decommission.ClusterName,
+				"final_backup":    req.FinalBackup,
+				"force":           req.Force,
+				"provisioner":     decommission.Provisioner,
+			},
+			constants.StatusSuccess,
+		)
+	}
+
+	utils.Success(c, http.StatusAccepted, decommission)
+}
+
+// GetClusterDecommission 获取集群最近一次退役任务
+func (h *ClusterDecommissionHandler) GetClusterDecommission(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	decommission, err := h.decommissionService.GetLatestDecommission(id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, decommission)
+}
+
+// GetDecommission 获取退役任务详情
+func (h *ClusterDecommissionHandler) GetDecommission(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid decommission ID")
+		return
+	}
+
+	decommission, err := h.decommissionService.GetDecommission(id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, decommission)
+}
+
+// ListDecommissions 获取退役任务列表
+func (h *ClusterDecommissionHandler) ListDecommissions(c *gin.Context) {
+	page, limit := parsePageLimit(c)
+
+	decommissions, total, err := h.decommissionService.ListDecommissions(page, limit)
+	if err != nil {
+		utils.Error(c, utils.ErrCodeInternalError, "Failed to list decommissions: %v", err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, gin.H{
+		"decommissions": decommissions,
+		"total":         total,
+		"page":          page,
+		"limit":         limit,
+	})
+}
+
+// ListArchives 获取已退役集群的归档列表
+func (h *ClusterDecommissionHandler) ListArchives(c *gin.Context) {
+	page, limit := parsePageLimit(c)
+
+	archives, total, err := h.decommissionService.ListArchives(page, limit, c.Query("name"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeInternalError, "Failed to list archives: %v", err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, gin.H{
+		"archives": archives,
+		"total":    total,
+		"page":     page,
+		"limit":    limit,
+	})
+}
+
+// GetArchive 获取归档快照
+func (h *ClusterDecommissionHandler) GetArchive(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid archive ID")
+		return
+	}
+
+	archive, err := h.decommissionService.GetArchive(id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, archive)
+}
+
+// handleError 转换退役相关错误
+func (h *ClusterDecommissionHandler) handleError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrDecommissionNotFound) {
+		utils.Error(c, utils.ErrCodeNotFound, "Decommission not found")
+		return
+	}
+	utils.Error(c, utils.ErrCodeInternalError, "Decommission operation failed: %v", err)
+}
+
+// parsePageLimit 解析分页参数
+func parsePageLimit(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return page, limit
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taichu-system/cluster-management/internal/service"
+	"github.com/taichu-system/cluster-management/pkg/utils"
+)
+
+// ClusterFingerprintHandler 集群指纹与重复集群处理器
+type ClusterFingerprintHandler struct {
+	fingerprintService *service.ClusterFingerprintService
+}
+
+// NewClusterFingerprintHandler 创建集群指纹处理器
+func NewClusterFingerprintHandler(fingerprintService *service.ClusterFingerprintService) *ClusterFingerprintHandler {
+	return &ClusterFingerprintHandler{fingerprintService: fingerprintService}
+}
+
+// GetDuplicateReport 列出指向同一 Kubernetes 集群的集群记录，refresh=false 时不为缺少指纹的集群读取指纹
+func (h *ClusterFingerprintHandler) GetDuplicateReport(c *gin.Context) {
+	refresh := c.DefaultQuery("refresh", "true") != "false"
+
+	report, err := h.fingerprintService.DuplicateReport(c.Request.Context(), refresh)
+	if err != nil {
+		utils.Error(c, utils.ErrCodeInternalError, "Failed to generate duplicate report: %v", err)
+		return
+	}
+	utils.Success(c, http.StatusOK, report)
+}
+
+// RefreshFingerprint 重新读取集群指纹并返回与其重复的集群
+func (h *ClusterFingerprintHandler) RefreshFingerprint(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	fingerprint, duplicates, err := h.fingerprintService.RefreshByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrClusterNotFound) {
+			utils.Error(c, utils.ErrCodeNotFound, "Cluster not found")
+			return
+		}
+		utils.Error(c, utils.ErrCodeInternalError, "Failed to read cluster fingerprint: %v", err)
+		return
+	}
+
+	duplicateIDs := make([]string, 0, len(duplicates))
+	for _, duplicate := range duplicates {
+		duplicateIDs = append(duplicateIDs, duplicate.ID.String())
+	}
+	utils.Success(c, http.StatusOK, gin.H{
+		"fingerprint":           fingerprint,
+		"duplicate_cluster_ids": duplicateIDs,
+	})
+}
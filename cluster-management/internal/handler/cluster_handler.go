@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -10,6 +11,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/taichu-system/cluster-management/internal/constants"
 	"github.com/taichu-system/cluster-management/internal/model"
+	"github.com/taichu-system/cluster-management/internal/repository"
 	"github.com/taichu-system/cluster-management/internal/service"
 	"github.com/taichu-system/cluster-management/internal/service/worker"
 	"github.com/taichu-system/cluster-management/pkg/utils"
@@ -32,27 +34,33 @@ type CreateClusterRequest struct {
 }
 
 type CreateClusterByMachinesRequest struct {
-	ClusterName  string            `json:"cluster_name" binding:"required,min=1,max=63"`
-	Description  string            `json:"description" binding:"max=500"`
-	MachineIDs   []uuid.UUID       `json:"machine_ids" binding:"required,min=1"`
-	Kubernetes   KubernetesConfig  `json:"kubernetes"`
-	Network      NetworkConfig     `json:"network"`
-	ArtifactPath string            `json:"artifact_path"`
-	WithPackages bool              `json:"with_packages"`
-	AutoApprove  bool              `json:"auto_approve"`
-	Labels       map[string]string `json:"labels"`
+	ClusterName  string                 `json:"cluster_name" binding:"required,min=1,max=63"`
+	Description  string                 `json:"description" binding:"max=500"`
+	MachineIDs   []uuid.UUID            `json:"machine_ids" binding:"required,min=1"`
+	Kubernetes   KubernetesConfig       `json:"kubernetes"`
+	Network      NetworkConfig          `json:"network"`
+	ArtifactPath string                 `json:"artifact_path"`
+	WithPackages bool                   `json:"with_packages"`
+	AutoApprove  bool                   `json:"auto_approve"`
+	Provisioner  string                 `json:"provisioner"`
+	TemplateID   *uuid.UUID             `json:"template_id"` // 指定模板版本时忽略 kubernetes/network/artifact_path/addons
+	Addons       []service.AddonConfig  `json:"addons"`
+	Topology     service.TopologyConfig `json:"topology"` // 高可用、etcd、镜像仓库与组件参数
+	Labels       map[string]string      `json:"labels"`
 }
 
+// KubernetesConfig 未使用模板时各字段必填，见 validateClusterSpec
 type KubernetesConfig struct {
-	Version          string `json:"version" binding:"required,semver"`
-	ImageRepo        string `json:"image_repo" binding:"required"`
-	ContainerManager string `json:"container_manager" binding:"required,oneof=docker containerd"`
+	Version          string `json:"version" binding:"omitempty,semver"`
+	ImageRepo        string `json:"image_repo"`
+	ContainerManager string `json:"container_manager" binding:"omitempty,oneof=docker containerd"`
 }
 
+// NetworkConfig 未使用模板时各字段必填，见 validateClusterSpec
 type NetworkConfig struct {
-	Plugin      string `json:"plugin" binding:"required,oneof=flannel calico cilium weave"`
-	PodsCIDR    string `json:"pods_cidr" binding:"required,cidr"`
-	ServiceCIDR string `json:"service_cidr" binding:"required,cidr"`
+	Plugin      string `json:"plugin" binding:"omitempty,oneof=flannel calico cilium weave"`
+	PodsCIDR    string `json:"pods_cidr" binding:"omitempty,cidr"`
+	ServiceCIDR string `json:"service_cidr" binding:"omitempty,cidr"`
 }
 
 type CreateClusterResponse struct {
@@ -66,6 +74,7 @@ type CreateClusterResponse struct {
 type CreateTaskResponse struct {
 	ID          uuid.UUID `json:"id"`
 	ClusterName string    `json:"cluster_name"`
+	Provisioner string    `json:"provisioner"`
 	Status      string    `json:"status"`
 	Progress    int       `json:"progress"`
 	CurrentStep string    `json:"current_step"`
@@ -308,30 +317,31 @@ func (h *ClusterHandler) GetCluster(c *gin.Context) {
 	}
 
 	response := struct {
-		ID                  uuid.UUID                  `json:"id"`
-		Name                string                     `json:"name"`
-		Description         string                     `json:"description"`
-		Provider            string                     `json:"provider"`
-		Region              string                     `json:"region"`
-		Status              string                     `json:"status"`
-		Version             string                     `json:"version"`
-		Labels              map[string]string          `json:"labels"`
-		NodeCount           int                        `json:"node_count"`
-		Nodes               []*service.NodeWithDetails `json:"nodes,omitempty"`
-		TotalCPUCores       int                        `json:"total_cpu_cores"`
-		UsedCPUCores        float64                    `json:"used_cpu_cores"`
-		CPUUsagePercent     float64                    `json:"cpu_usage_percent"`
-		TotalMemoryBytes    int64                      `json:"total_memory_bytes"`
-		UsedMemoryBytes     int64                      `json:"used_memory_bytes"`
-		MemoryUsagePercent  float64                    `json:"memory_usage_percent"`
-		TotalStorageBytes   int64                      `json:"total_storage_bytes"`
-		UsedStorageBytes    int64                      `json:"used_storage_bytes"`
-		StorageUsagePercent float64                    `json:"storage_usage_percent"`
-		KubernetesVersion   string                     `json:"kubernetes_version"`
-		APIServerURL        string                     `json:"api_server_url"`
-		LastHeartbeatAt     string                     `json:"last_heartbeat_at"`
-		CreatedAt           string                     `json:"created_at"`
-		UpdatedAt           string                     `json:"updated_at"`
+		ID                  uuid.UUID                    `json:"id"`
+		Name                string                       `json:"name"`
+		Description         string                       `json:"description"`
+		Provider            string                       `json:"provider"`
+		Region              string                       `json:"region"`
+		Status              string                       `json:"status"`
+		Version             string                       `json:"version"`
+		Labels              map[string]string            `json:"labels"`
+		NodeCount           int                          `json:"node_count"`
+		Nodes               []*service.NodeWithDetails   `json:"nodes,omitempty"`
+		TotalCPUCores       int                          `json:"total_cpu_cores"`
+		UsedCPUCores        float64                      `json:"used_cpu_cores"`
+		CPUUsagePercent     float64                      `json:"cpu_usage_percent"`
+		TotalMemoryBytes    int64                        `json:"total_memory_bytes"`
+		UsedMemoryBytes     int64                        `json:"used_memory_bytes"`
+		MemoryUsagePercent  float64                      `json:"memory_usage_percent"`
+		TotalStorageBytes   int64                        `json:"total_storage_bytes"`
+		UsedStorageBytes    int64                        `json:"used_storage_bytes"`
+		StorageUsagePercent float64                      `json:"storage_usage_percent"`
+		KubernetesVersion   string                       `json:"kubernetes_version"`
+		APIServerURL        string                       `json:"api_server_url"`
+		LastHeartbeatAt     string                       `json:"last_heartbeat_at"`
+		CircuitBreaker      service.CircuitBreakerStatus `json:"circuit_breaker"`
+		CreatedAt           string                       `json:"created_at"`
+		UpdatedAt           string                       `json:"updated_at"`
 	}{
 		ID:          cluster.Cluster.ID,
 		Name:        cluster.Cluster.Name,
@@ -405,13 +415,35 @@ func (h *ClusterHandler) GetCluster(c *gin.Context) {
 			}
 			return ""
 		}(),
-		CreatedAt: cluster.Cluster.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: cluster.Cluster.UpdatedAt.Format(time.RFC3339),
+		CircuitBreaker: h.clusterService.CircuitBreakerStatus(id),
+		CreatedAt:      cluster.Cluster.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      cluster.Cluster.UpdatedAt.Format(time.RFC3339),
 	}
 
 	utils.Success(c, http.StatusOK, response)
 }
 
+// GetClusterHealth 获取集群控制面与附加组件的健康状态，refresh=true 时实时检查
+func (h *ClusterHandler) GetClusterHealth(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	report, err := h.clusterService.GetClusterHealth(id.String(), c.Query("refresh") == "true")
+	if err != nil {
+		if errors.Is(err, service.ErrClusterNotFound) {
+			utils.Error(c, utils.ErrCodeNotFound, "Cluster not found")
+			return
+		}
+		utils.Error(c, utils.ErrCodeInternalError, "Failed to get cluster health: %v", err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, report)
+}
+
 func convertLabels(labels model.JSONMap) map[string]string {
 	result := make(map[string]string)
 	for k, v := range labels {
@@ -479,6 +511,13 @@ func (h *ClusterHandler) CreateClusterByMachines(c *gin.Context) {
 		return
 	}
 
+	if req.TemplateID == nil {
+		if err := validateClusterSpec(req.Kubernetes, req.Network); err != nil {
+			utils.Error(c, utils.ErrCodeValidationFailed, "%v", err)
+			return
+		}
+	}
+
 	createReq := service.CreateClusterRequest{
 		ClusterName: req.ClusterName,
 		MachineIDs:  req.MachineIDs,
@@ -495,9 +534,25 @@ func (h *ClusterHandler) CreateClusterByMachines(c *gin.Context) {
 		ArtifactPath: req.ArtifactPath,
 		WithPackages: req.WithPackages,
 		AutoApprove:  req.AutoApprove,
+		Provisioner:  req.Provisioner,
+		Addons:       req.Addons,
+		TemplateID:   req.TemplateID,
+		Topology:     req.Topology,
 	}
 
 	task, err := h.createClusterService.CreateCluster(createReq)
+	if errors.Is(err, service.ErrClusterTemplateNotFound) {
+		utils.Error(c, utils.ErrCodeNotFound, "Cluster template not found")
+		return
+	}
+	if errors.Is(err, service.ErrInvalidTopology) || errors.Is(err, service.ErrInvalidAddon) {
+		utils.Error(c, utils.ErrCodeValidationFailed, "%v", err)
+		return
+	}
+	if errors.Is(err, repository.ErrMachinesUnavailable) {
+		utils.Error(c, utils.ErrCodeConflict, "%v", err)
+		return
+	}
 	if err != nil {
 		utils.Error(c, utils.ErrCodeInternalError, "Failed to create cluster: %v", err)
 		return
@@ -511,10 +566,13 @@ func (h *ClusterHandler) CreateClusterByMachines(c *gin.Context) {
 			"cluster",
 			user,
 			map[string]interface{}{
-				"cluster_name":  req.ClusterName,
-				"machine_count": len(req.MachineIDs),
-				"description":   req.Description,
-				"task_id":       task.ID,
+				"cluster_name":     req.ClusterName,
+				"machine_count":    len(req.MachineIDs),
+				"description":      req.Description,
+				"task_id":          task.ID,
+				"provisioner":      task.Provisioner,
+				"template":         task.TemplateName,
+				"template_version": task.TemplateVersion,
 			},
 		)
 	}
@@ -522,6 +580,7 @@ func (h *ClusterHandler) CreateClusterByMachines(c *gin.Context) {
 	response := CreateTaskResponse{
 		ID:          task.ID,
 		ClusterName: task.ClusterName,
+		Provisioner: task.Provisioner,
 		Status:      task.Status,
 		Progress:    task.Progress,
 		CurrentStep: task.CurrentStep,
@@ -548,22 +607,30 @@ func (h *ClusterHandler) GetCreateTask(c *gin.Context) {
 	}
 
 	response := struct {
-		ID                uuid.UUID `json:"id"`
-		ClusterName       string    `json:"cluster_name"`
-		Status            string    `json:"status"`
-		Progress          int       `json:"progress"`
-		CurrentStep       string    `json:"current_step"`
-		Logs              string    `json:"logs"`
-		ErrorMsg          string    `json:"error_msg"`
-		KubernetesVersion string    `json:"kubernetes_version"`
-		NetworkPlugin     string    `json:"network_plugin"`
-		StartedAt         string    `json:"started_at"`
-		CompletedAt       string    `json:"completed_at"`
-		CreatedAt         string    `json:"created_at"`
-		UpdatedAt         string    `json:"updated_at"`
+		ID                uuid.UUID  `json:"id"`
+		ClusterName       string     `json:"cluster_name"`
+		Provisioner       string     `json:"provisioner"`
+		TemplateID        *uuid.UUID `json:"template_id"`
+		TemplateName      string     `json:"template_name"`
+		TemplateVersion   int        `json:"template_version"`
+		Status            string     `json:"status"`
+		Progress          int        `json:"progress"`
+		CurrentStep       string     `json:"current_step"`
+		Logs              string     `json:"logs"`
+		ErrorMsg          string     `json:"error_msg"`
+		KubernetesVersion string     `json:"kubernetes_version"`
+		NetworkPlugin     string     `json:"network_plugin"`
+		StartedAt         string     `json:"started_at"`
+		CompletedAt       string     `json:"completed_at"`
+		CreatedAt         string     `json:"created_at"`
+		UpdatedAt         string     `json:"updated_at"`
 	}{
 		ID:                task.ID,
 		ClusterName:       task.ClusterName,
+		Provisioner:       task.Provisioner,
+		TemplateID:        task.TemplateID,
+		TemplateName:      task.TemplateName,
+		TemplateVersion:   task.TemplateVersion,
 		Status:            task.Status,
 		Progress:          task.Progress,
 		CurrentStep:       task.CurrentStep,
@@ -611,6 +678,34 @@ func (h *ClusterHandler) ListCreateTasks(c *gin.Context) {
 	})
 }
 
+// ListProvisioners 获取可用的部署驱动
+func (h *ClusterHandler) ListProvisioners(c *gin.Context) {
+	names, defaultDriver := h.createClusterService.ListProvisioners()
+	utils.Success(c, http.StatusOK, gin.H{
+		"provisioners": names,
+		"default":      defaultDriver,
+	})
+}
+
+// validateClusterSpec 未使用模板时校验必填的集群配置
+func validateClusterSpec(k KubernetesConfig, n NetworkConfig) error {
+	switch {
+	case k.Version == "":
+		return errors.New("kubernetes.version is required when no template is used")
+	case k.ImageRepo == "":
+		return errors.New("kubernetes.image_repo is required when no template is used")
+	case k.ContainerManager == "":
+		return errors.New("kubernetes.container_manager is required when no template is used")
+	case n.Plugin == "":
+		return errors.New("network.plugin is required when no template is used")
+	case n.PodsCIDR == "":
+		return errors.New("network.pods_cidr is required when no template is used")
+	case n.ServiceCIDR == "":
+		return errors.New("network.service_cidr is required when no template is used")
+	}
+	return nil
+}
+
 // getTimeString 转换时间指针为字符串
 func getTimeString(t *time.Time) string {
 	if t == nil {
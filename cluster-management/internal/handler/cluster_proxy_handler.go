@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/constants"
+	"github.com/taichu-system/cluster-management/internal/service"
+	"github.com/taichu-system/cluster-management/pkg/utils"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+)
+
+// ClusterProxyHandler 集群 Kubernetes API 代理处理器
+type ClusterProxyHandler struct {
+	proxyService *service.ClusterProxyService
+	auditService *service.AuditService
+}
+
+// NewClusterProxyHandler 创建集群 API 代理处理器
+func NewClusterProxyHandler(proxyService *service.ClusterProxyService, auditService *service.AuditService) *ClusterProxyHandler {
+	return &ClusterProxyHandler{
+		proxyService: proxyService,
+		auditService: auditService,
+	}
+}
+
+// Proxy 将 /clusters/:id/proxy/* 转发到集群 apiserver
+// 以 JWT 用户身份模拟访问，支持 watch、日志流以及 exec/attach/port-forward 的协议升级，变更类请求写入审计
+func (h *ClusterProxyHandler) Proxy(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	identity := service.ProxyIdentity{
+		UserID:   c.GetString("user_id"),
+		Username: c.GetString("username"),
+		Role:     c.GetString("role"),
+		TokenID:  c.GetString("token_id"),
+	}
+	// exec/attach/port-forward 等协议升级请求使用 HTTP/1.1 传输层
+	target, err := h.proxyService.Prepare(id, identity, httpstream.IsUpgradeRequest(c.Request))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrProxyUnauthenticated), errors.Is(err, service.ErrKubeconfigRevoked):
+			utils.Error(c, utils.ErrCodeUnauthorized, "%v", err)
+		case errors.Is(err, service.ErrClusterNotFound):
+			utils.Error(c, utils.ErrCodeNotFound, "Cluster not found")
+		default:
+			utils.Error(c, utils.ErrCodeInternalError, "Failed to prepare cluster proxy: %v", err)
+		}
+		return
+	}
+
+	path := c.Param("path")
+	proxy := &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = target.Host.Scheme
+			req.URL.Host = target.Host.Host
+			req.URL.Path = strings.TrimRight(target.Host.Path, "/") + path
+			req.URL.RawPath = ""
+			req.Host = ""
+			// 平台凭据不转发，模拟身份只能由代理设置
+			req.Header.Del("Authorization")
+			req.Header.Del("Cookie")
+			for key := range req.Header {
+				if strings.HasPrefix(key, "Impersonate-") {
+					req.Header.Del(key)
+				}
+			}
+		},
+		Transport: target.Transport,
+		// 立即刷新，保证 watch 与日志流实时返回
+		FlushInterval: -1,
+		ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+			log.Printf("[API-PROXY] cluster %s %s %s failed: %v", id, req.Method, path, err)
+			// 响应已开始流式返回时不能再写入错误响应体，只能中断连接
+			if c.Writer.Written() {
+				return
+			}
+			utils.Error(c, utils.ErrCodeServiceUnavailable, "Failed to reach cluster apiserver: %v", err)
+		},
+	}
+
+	start := time.Now()
+	proxy.ServeHTTP(c.Writer, c.Request)
+
+	if isMutatingMethod(c.Request.Method) {
+		h.audit(c, id, identity, target, path, time.Since(start))
+	}
+}
+
+// audit 记录变更类代理请求
+func (h *ClusterProxyHandler) audit(c *gin.Context, clusterID uuid.UUID, identity service.ProxyIdentity, target *service.ProxyTarget, path string, duration time.Duration) {
+	if h.auditService == nil {
+		return
+	}
+
+	eventType := constants.EventTypeUpdate
+	switch c.Request.Method {
+	case http.MethodPost:
+		eventType = constants.EventTypeCreate
+	case http.MethodDelete:
+		eventType = constants.EventTypeDelete
+	}
+
+	status := c.Writer.Status()
+	result := constants.StatusSuccess
+	if status >= http.StatusBadRequest {
+		result = constants.StatusFailed
+	}
+
+	resource := parseProxyPath(path)
+	details := map[string]interface{}{
+		"method":              c.Request.Method,
+		"path":                path,
+		"query":               c.Request.URL.RawQuery,
+		"status_code":         status,
+		"duration_ms":         duration.Milliseconds(),
+		"namespace":           resource.namespace,
+		"resource":            resource.resource,
+		"name":                resource.name,
+		"subresource":         resource.subresource,
+		"impersonated_user":   target.Impersonate.UserName,
+		"impersonated_groups": target.Impersonate.Groups,
+	}
+
+	if err := h.auditService.CreateAuditEvent(
+		clusterID,
+		eventType,
+		"proxy_"+strings.ToLower(c.Request.Method),
+		constants.ResourceTypeCluster,
+		resource.id(),
+		identity.Username,
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+		nil,
+		nil,
+		details,
+		result,
+	); err != nil {
+		log.Printf("[API-PROXY] failed to audit %s %s: %v", c.Request.Method, path, err)
+	}
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// proxyResource 从 Kubernetes API 路径解析出的资源
+type proxyResource struct {
+	namespace   string
+	resource    string
+	name        string
+	subresource string
+}
+
+func (r proxyResource) id() string {
+	parts := make([]string, 0, 4)
+	for _, part := range []string{r.namespace, r.resource, r.name, r.subresource} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// parseProxyPath 解析 /api/v1/... 与 /apis/<group>/<version>/... 形式的资源路径
+func parseProxyPath(path string) proxyResource {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	var rest []string
+	switch {
+	case len(segments) > 2 && segments[0] == "api":
+		rest = segments[2:]
+	case len(segments) > 3 && segments[0] == "apis":
+		rest = segments[3:]
+	default:
+		return proxyResource{}
+	}
+
+	var r proxyResource
+	if len(rest) > 2 && rest[0] == "namespaces" {
+		r.namespace = rest[1]
+		rest = rest[2:]
+	}
+	r.resource = rest[0]
+	if len(rest) > 1 {
+		r.name = rest[1]
+	}
+	if len(rest) > 2 {
+		r.subresource = rest[2]
+	}
+	return r
+}
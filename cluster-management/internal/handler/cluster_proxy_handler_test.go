@@ -0,0 +1,76 @@
+package handler
+
+import "testing"
+
+func TestParseProxyPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		want   proxyResource
+		wantID string
+	}{
+		{name: "core cluster-scoped list", path: "/api/v1/nodes", want: proxyResource{resource: "nodes"}, wantID: "nodes"},
+		{name: "core cluster-scoped object", path: "/api/v1/nodes/worker-1", want: proxyResource{resource: "nodes", name: "worker-1"}, wantID: "nodes/worker-1"},
+		{name: "namespace list", path: "/api/v1/namespaces", want: proxyResource{resource: "namespaces"}, wantID: "namespaces"},
+		{name: "namespace object", path: "/api/v1/namespaces/default", want: proxyResource{resource: "namespaces", name: "default"}, wantID: "namespaces/default"},
+		{
+			name:   "core namespaced list",
+			path:   "/api/v1/namespaces/default/pods",
+			want:   proxyResource{namespace: "default", resource: "pods"},
+			wantID: "default/pods",
+		},
+		{
+			name:   "core namespaced subresource",
+			path:   "/api/v1/namespaces/default/pods/web-0/exec",
+			want:   proxyResource{namespace: "default", resource: "pods", name: "web-0", subresource: "exec"},
+			wantID: "default/pods/web-0/exec",
+		},
+		{
+			name:   "group namespaced object",
+			path:   "/apis/apps/v1/namespaces/prod/deployments/api",
+			want:   proxyResource{namespace: "prod", resource: "deployments", name: "api"},
+			wantID: "prod/deployments/api",
+		},
+		{
+			name:   "group cluster-scoped object",
+			path:   "/apis/rbac.authorization.k8s.io/v1/clusterroles/admin",
+			want:   proxyResource{resource: "clusterroles", name: "admin"},
+			wantID: "clusterroles/admin",
+		},
+		{
+			name:   "group subresource with trailing slash",
+			path:   "/apis/apps/v1/namespaces/prod/deployments/api/scale/",
+			want:   proxyResource{namespace: "prod", resource: "deployments", name: "api", subresource: "scale"},
+			wantID: "prod/deployments/api/scale",
+		},
+		{name: "core discovery", path: "/api/v1"},
+		{name: "group discovery", path: "/apis/apps/v1"},
+		{name: "api root", path: "/api"},
+		{name: "non resource path", path: "/healthz"},
+		{name: "version path", path: "/version/extra/path"},
+		{name: "empty path", path: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseProxyPath(tt.path)
+			if got != tt.want {
+				t.Errorf("parseProxyPath(%q) = %+v, want %+v", tt.path, got, tt.want)
+			}
+			if id := got.id(); id != tt.wantID {
+				t.Errorf("id() = %q, want %q", id, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestIsMutatingMethod(t *testing.T) {
+	for method, want := range map[string]bool{
+		"GET": false, "HEAD": false, "OPTIONS": false,
+		"POST": true, "PUT": true, "PATCH": true, "DELETE": true,
+	} {
+		if got := isMutatingMethod(method); got != want {
+			t.Errorf("isMutatingMethod(%s) = %v, want %v", method, got, want)
+		}
+	}
+}
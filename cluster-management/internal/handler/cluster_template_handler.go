@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/constants"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"github.com/taichu-system/cluster-management/internal/service"
+	"github.com/taichu-system/cluster-management/pkg/utils"
+)
+
+// ClusterTemplateHandler 集群模板处理器
+type ClusterTemplateHandler struct {
+	templateService *service.ClusterTemplateService
+	auditService    *service.AuditService
+}
+
+// NewClusterTemplateHandler 创建集群模板处理器
+func NewClusterTemplateHandler(templateService *service.ClusterTemplateService, auditService *service.AuditService) *ClusterTemplateHandler {
+	return &ClusterTemplateHandler{
+		templateService: templateService,
+		auditService:    auditService,
+	}
+}
+
+// ClusterTemplateRequest 创建模板/发布新版本请求
+type ClusterTemplateRequest struct {
+	Name             string                             `json:"name" binding:"max=255"`
+	Description      string                             `json:"description"`
+	Provisioner      string                             `json:"provisioner"`
+	Kubernetes       KubernetesConfig                   `json:"kubernetes"`
+	Network          NetworkConfig                      `json:"network"`
+	ArtifactPath     string                             `json:"artifact_path"`
+	WithPackages     bool                               `json:"with_packages"`
+	Addons           []service.AddonConfig              `json:"addons"`
+	RoleRequirements map[string]service.RoleRequirement `json:"role_requirements"`
+}
+
+// CreateTemplate 创建集群模板
+func (h *ClusterTemplateHandler) CreateTemplate(c *gin.Context) {
+	var req ClusterTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid request body: %v", err)
+		return
+	}
+
+	if req.Name == "" {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Template name is required")
+		return
+	}
+
+	template, err := buildClusterTemplate(&req)
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "%v", err)
+		return
+	}
+
+	if err := h.templateService.CreateTemplate(template); err != nil {
+		utils.Error(c, utils.ErrCodeInternalError, "Failed to create template: %v", err)
+		return
+	}
+
+	h.logTemplateAudit(c, constants.EventTypeCreate, template, "create_cluster_template")
+
+	utils.Success(c, http.StatusCreated, template)
+}
+
+// ListTemplates 获取模板列表（每个模板的最新版本）
+func (h *ClusterTemplateHandler) ListTemplates(c *gin.Context) {
+	templates, err := h.templateService.ListTemplates()
+	if err != nil {
+		utils.Error(c, utils.ErrCodeInternalError, "Failed to list templates: %v", err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, gin.H{
+		"templates": templates,
+		"total":     len(templates),
+	})
+}
+
+// GetTemplate 获取模板版本详情
+func (h *ClusterTemplateHandler) GetTemplate(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid template ID")
+		return
+	}
+
+	template, err := h.templateService.GetTemplate(id)
+	if err != nil {
+		h.handleTemplateError(c, err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, template)
+}
+
+// ListVersions 获取模板的全部版本
+func (h *ClusterTemplateHandler) ListVersions(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid template ID")
+		return
+	}
+
+	versions, err := h.templateService.ListVersions(id)
+	if err != nil {
+		h.handleTemplateError(c, err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, gin.H{
+		"versions": versions,
+		"total":    len(versions),
+	})
+}
+
+// PublishVersion 发布模板新版本
+func (h *ClusterTemplateHandler) PublishVersion(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid template ID")
+		return
+	}
+
+	var req ClusterTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid request body: %v", err)
+		return
+	}
+
+	template, err := buildClusterTemplate(&req)
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "%v", err)
+		return
+	}
+
+	if err := h.templateService.PublishVersion(id, template); err != nil {
+		h.handleTemplateError(c, err)
+		return
+	}
+
+	h.logTemplateAudit(c, constants.EventTypeUpdate, template, "publish_cluster_template_version")
+
+	utils.Success(c, http.StatusCreated, template)
+}
+
+// DeleteTemplate 删除模板版本
+func (h *ClusterTemplateHandler) DeleteTemplate(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid template ID")
+		return
+	}
+
+	template, err := h.templateService.GetTemplate(id)
+	if err != nil {
+		h.handleTemplateError(c, err)
+		return
+	}
+
+	if err := h.templateService.DeleteTemplate(id); err != nil {
+		h.handleTemplateError(c, err)
+		return
+	}
+
+	h.logTemplateAudit(c, constants.EventTypeDelete, template, "delete_cluster_template")
+
+	utils.Success(c, http.StatusOK, gin.H{"message": "Template deleted successfully"})
+}
+
+// handleTemplateError 转换模板错误
+func (h *ClusterTemplateHandler) handleTemplateError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrClusterTemplateNotFound) {
+		utils.Error(c, utils.ErrCodeNotFound, "Template not found")
+		return
+	}
+	utils.Error(c, utils.ErrCodeInternalError, "Template operation failed: %v", err)
+}
+
+// logTemplateAudit 记录模板审计事件
+func (h *ClusterTemplateHandler) logTemplateAudit(c *gin.Context, eventType string, template *model.ClusterTemplate, operation string) {
+	if h.auditService == nil {
+		return
+	}
+
+	h.auditService.CreateAuditEvent(
+		uuid.Nil,
+		"cluster_template",
+		eventType,
+		"cluster_template",
+		template.ID.String(),
+		"api-user",
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+		nil,
+		map[string]interface{}{
+			"name":    template.Name,
+			"version": template.Version,
+		},
+		map[string]interface{}{
+			"operation": operation,
+		},
+		constants.StatusSuccess,
+	)
+}
+
+// buildClusterTemplate 将请求转换为模板模型
+func buildClusterTemplate(req *ClusterTemplateRequest) (*model.ClusterTemplate, error) {
+	if err := validateClusterSpec(req.Kubernetes, req.Network); err != nil {
+		return nil, err
+	}
+
+	addons, err := service.AddonsToJSONMap(req.Addons)
+	if err != nil {
+		return nil, err
+	}
+
+	requirements := make(model.JSONMap, len(req.RoleRequirements))
+	for role, r := range req.RoleRequirements {
+		requirements[role] = map[string]interface{}{
+			"min": r.Min,
+			"max": r.Max,
+		}
+	}
+
+	return &model.ClusterTemplate{
+		Name:              req.Name,
+		Description:       req.Description,
+		Provisioner:       req.Provisioner,
+		KubernetesVersion: req.Kubernetes.Version,
+		ImageRepo:         req.Kubernetes.ImageRepo,
+		ContainerManager:  req.Kubernetes.ContainerManager,
+		NetworkPlugin:     req.Network.Plugin,
+		PodsCIDR:          req.Network.PodsCIDR,
+		ServiceCIDR:       req.Network.ServiceCIDR,
+		ArtifactPath:      req.ArtifactPath,
+		WithPackages:      req.WithPackages,
+		Addons:            addons,
+		RoleRequirements:  requirements,
+		CreatedBy:         "api-user",
+	}, nil
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taichu-system/cluster-management/internal/service"
+	"github.com/taichu-system/cluster-management/pkg/utils"
+)
+
+// DeprecatedAPIHandler 弃用API扫描处理器
+type DeprecatedAPIHandler struct {
+	deprecatedAPIService *service.DeprecatedAPIService
+}
+
+// NewDeprecatedAPIHandler 创建弃用API扫描处理器
+func NewDeprecatedAPIHandler(deprecatedAPIService *service.DeprecatedAPIService) *DeprecatedAPIHandler {
+	return &DeprecatedAPIHandler{
+		deprecatedAPIService: deprecatedAPIService,
+	}
+}
+
+// ScanDeprecatedAPIs 扫描集群在目标版本中已弃用或移除的API使用情况
+func (h *DeprecatedAPIHandler) ScanDeprecatedAPIs(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+	targetVersion := c.Query("target_version")
+	if targetVersion == "" {
+		utils.Error(c, utils.ErrCodeValidationFailed, "target_version is required")
+		return
+	}
+
+	report, err := h.deprecatedAPIService.Scan(c.Request.Context(), id, targetVersion)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrClusterNotFound):
+			utils.Error(c, utils.ErrCodeNotFound, "Cluster not found")
+		case errors.Is(err, service.ErrInvalidTargetVersion):
+			utils.Error(c, utils.ErrCodeValidationFailed, "%v", err)
+		default:
+			utils.Error(c, utils.ErrCodeInternalError, "Failed to scan deprecated APIs: %v", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, report)
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taichu-system/cluster-management/internal/service"
+	"github.com/taichu-system/cluster-management/pkg/utils"
+)
+
+// defaultHealthHistoryWindow 未指定时间范围时默认统计最近 30 天
+const defaultHealthHistoryWindow = "30d"
+
+// HealthHistoryHandler 集群健康历史处理器
+type HealthHistoryHandler struct {
+	historyService *service.HealthHistoryService
+}
+
+// NewHealthHistoryHandler 创建集群健康历史处理器
+func NewHealthHistoryHandler(historyService *service.HealthHistoryService) *HealthHistoryHandler {
+	return &HealthHistoryHandler{
+		historyService: historyService,
+	}
+}
+
+// GetUptime 获取集群在时间窗口内的可用率
+// 支持 start/end（RFC3339）或 window（如 30d、12h），默认最近 30 天
+func (h *HealthHistoryHandler) GetUptime(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+	start, end, err := parseTimeWindow(c)
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "%v", err)
+		return
+	}
+
+	report, err := h.historyService.GetUptime(id, start, end)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	utils.Success(c, http.StatusOK, report)
+}
+
+// ListIncidents 获取集群在时间窗口内的故障时间线
+func (h *HealthHistoryHandler) ListIncidents(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+	start, end, err := parseTimeWindow(c)
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "%v", err)
+		return
+	}
+
+	report, err := h.historyService.ListIncidents(id, start, end, c.Query("include_degraded") == "true")
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	utils.Success(c, http.StatusOK, report)
+}
+
+// GetSLO 获取集群滚动窗口内的 SLO 达成与错误预算燃烧情况
+func (h *HealthHistoryHandler) GetSLO(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	var target float64
+	if value := c.Query("target"); value != "" {
+		target, err = strconv.ParseFloat(value, 64)
+		if err != nil || target <= 0 || target >= 100 {
+			utils.Error(c, utils.ErrCodeValidationFailed, "target must be a percentage between 0 and 100, e.g. 99.9")
+			return
+		}
+	}
+	window, err := service.ParseWindow(c.DefaultQuery("window", defaultHealthHistoryWindow))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "%v", err)
+		return
+	}
+
+	report, err := h.historyService.GetSLO(id, target, window)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	utils.Success(c, http.StatusOK, report)
+}
+
+func (h *HealthHistoryHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrClusterNotFound):
+		utils.Error(c, utils.ErrCodeNotFound, "Cluster not found")
+	case errors.Is(err, service.ErrInvalidTimeWindow), errors.Is(err, service.ErrInvalidSLOTarget):
+		utils.Error(c, utils.ErrCodeValidationFailed, "%v", err)
+	default:
+		utils.Error(c, utils.ErrCodeInternalError, "Failed to query health history: %v", err)
+	}
+}
+
+// parseTimeWindow 解析 start/end，未提供 start 时按 window 从 end 往前推
+func parseTimeWindow(c *gin.Context) (time.Time, time.Time, error) {
+	end := time.Now()
+	if value := c.Query("end"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("end must be an RFC3339 timestamp")
+		}
+		end = parsed
+	}
+
+	if value := c.Query("start"); value != "" {
+		start, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("start must be an RFC3339 timestamp")
+		}
+		if !end.After(start) {
+			return time.Time{}, time.Time{}, errors.New("end must be after start")
+		}
+		return start, end, nil
+	}
+
+	window, err := service.ParseWindow(c.DefaultQuery("window", defaultHealthHistoryWindow))
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return end.Add(-window), end, nil
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"log"
 	"net/http"
 
@@ -27,6 +28,18 @@ type ImportClusterRequest struct {
 	Region           string            `json:"region"`
 	Kubeconfig       string            `json:"kubeconfig" binding:"required"`
 	Labels           map[string]string `json:"labels"`
+	// Connection 集群只能经由堡垒机或代理访问时指定
+	Connection *ClusterConnectionRequest `json:"connection"`
+	// CredentialMode 为 service_account 时只用 kubeconfig 创建最小权限 ServiceAccount，不保存 kubeconfig 本身
+	CredentialMode string `json:"credential_mode" binding:"omitempty,oneof=kubeconfig service_account"`
+	// OnDuplicate 集群已被导入时的处理方式：reject 导入失败，merge 合并到已导入的集群
+	OnDuplicate string `json:"on_duplicate" binding:"omitempty,oneof=reject merge"`
+}
+
+// ListKubeconfigContextsRequest 解析多上下文 kubeconfig 的请求，Precheck 未指定时预检连通性
+type ListKubeconfigContextsRequest struct {
+	Kubeconfig string `json:"kubeconfig" binding:"required"`
+	Precheck   *bool  `json:"precheck"`
 }
 
 type ImportRecordSummary struct {
@@ -70,27 +83,23 @@ func (h *ImportHandler) ImportCluster(c *gin.Context) {
 		return
 	}
 
-	importRecord, err := h.importService.ImportCluster(req.ImportSource, req.Name, req.Description, req.EnvironmentType, req.Region, req.Kubeconfig, req.Labels)
+	var connection *service.ClusterConnectionInput
+	if req.Connection != nil {
+		input := req.Connection.toInput()
+		connection = &input
+	}
+
+	importRecord, err := h.importService.ImportCluster(req.ImportSource, req.Name, req.Description, req.EnvironmentType, req.Region, req.Kubeconfig, req.Labels, connection, req.CredentialMode, req.OnDuplicate)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidConnection) {
+			utils.Error(c, utils.ErrCodeValidationFailed, "%v", err)
+			return
+		}
 		utils.Error(c, utils.ErrCodeInternalError, "Failed to import cluster: %s", err.Error())
 		return
 	}
 
-	log.Printf("Starting async import task for import record ID: %s", importRecord.ID.String())
-	go h.importService.ExecuteImport(importRecord.ID.String())
-	log.Printf("Async import task started for import record ID: %s", importRecord.ID.String())
-
-	if importRecord.ClusterID != nil {
-		if h.healthWorker != nil {
-			log.Printf("Triggering health check for cluster ID: %s", importRecord.ClusterID.String())
-			go h.healthWorker.TriggerSync(*importRecord.ClusterID)
-		}
-
-		if h.resourceSyncWorker != nil {
-			log.Printf("Triggering resource sync for cluster ID: %s", importRecord.ClusterID.String())
-			go h.resourceSyncWorker.TriggerSync(*importRecord.ClusterID)
-		}
-	}
+	h.startImport(importRecord.ID, importRecord.ClusterID)
 
 	if h.auditService != nil {
 		user := "api-user"
@@ -137,6 +146,106 @@ func (h *ImportHandler) ImportCluster(c *gin.Context) {
 	utils.Success(c, http.StatusOK, response)
 }
 
+// startImport 异步执行导入并触发健康检查与资源同步
+func (h *ImportHandler) startImport(importID uuid.UUID, clusterID *uuid.UUID) {
+	log.Printf("Starting async import task for import record ID: %s", importID.String())
+	go h.importService.ExecuteImport(importID.String())
+	log.Printf("Async import task started for import record ID: %s", importID.String())
+
+	if clusterID != nil {
+		if h.healthWorker != nil {
+			log.Printf("Triggering health check for cluster ID: %s", clusterID.String())
+			go h.healthWorker.TriggerSync(*clusterID)
+		}
+
+		if h.resourceSyncWorker != nil {
+			log.Printf("Triggering resource sync for cluster ID: %s", clusterID.String())
+			go h.resourceSyncWorker.TriggerSync(*clusterID)
+		}
+	}
+}
+
+// ListKubeconfigContexts 列出多上下文 kubeconfig 中的上下文，默认预检各上下文是否可达
+func (h *ImportHandler) ListKubeconfigContexts(c *gin.Context) {
+	var req ListKubeconfigContextsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid request body: %v", err)
+		return
+	}
+	precheck := req.Precheck == nil || *req.Precheck
+
+	contexts, err := h.importService.ListKubeconfigContexts(c.Request.Context(), req.Kubeconfig, precheck)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidKubeConfig) {
+			utils.Error(c, utils.ErrCodeValidationFailed, "%v", err)
+			return
+		}
+		utils.Error(c, utils.ErrCodeInternalError, "Failed to list kubeconfig contexts: %v", err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, gin.H{
+		"contexts": contexts,
+		"total":    len(contexts),
+	})
+}
+
+// BulkImport 将多上下文 kubeconfig 中选择的上下文分别导入为独立集群
+func (h *ImportHandler) BulkImport(c *gin.Context) {
+	var req service.BulkImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid request body: %v", err)
+		return
+	}
+
+	results, err := h.importService.BulkImport(req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidKubeConfig) ||
+			errors.Is(err, service.ErrKubeconfigContextNotFound) ||
+			errors.Is(err, service.ErrNoContextsSelected) {
+			utils.Error(c, utils.ErrCodeValidationFailed, "%v", err)
+			return
+		}
+		utils.Error(c, utils.ErrCodeInternalError, "Failed to import clusters: %v", err)
+		return
+	}
+
+	succeeded := 0
+	for _, result := range results {
+		if result.ImportID == nil {
+			continue
+		}
+		succeeded++
+		h.startImport(*result.ImportID, result.ClusterID)
+
+		if h.auditService != nil {
+			clusterID := uuid.Nil
+			if result.ClusterID != nil {
+				clusterID = *result.ClusterID
+			}
+			h.auditService.LogClusterOperation(
+				clusterID,
+				"import",
+				"cluster",
+				"api-user",
+				map[string]interface{}{
+					"import_id":          result.ImportID,
+					"import_source":      req.ImportSource,
+					"name":               result.Name,
+					"kubeconfig_context": result.Context,
+					"bulk":               true,
+				},
+			)
+		}
+	}
+
+	utils.Success(c, http.StatusOK, gin.H{
+		"results":   results,
+		"succeeded": succeeded,
+		"failed":    len(results) - succeeded,
+	})
+}
+
 func (h *ImportHandler) GetImportStatus(c *gin.Context) {
 	importID := c.Param("importId")
 
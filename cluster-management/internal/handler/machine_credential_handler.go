@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/constants"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"github.com/taichu-system/cluster-management/internal/service"
+	"github.com/taichu-system/cluster-management/pkg/utils"
+)
+
+// MachineCredentialRequest 共享凭据请求
+type MachineCredentialRequest struct {
+	Name        string `json:"name" binding:"required,min=1,max=255"`
+	Description string `json:"description"`
+	Username    string `json:"username" binding:"required,min=1,max=50"`
+	AuthType    string `json:"auth_type" binding:"omitempty,oneof=password private_key"`
+	Password    string `json:"password" binding:"omitempty,min=6"`
+	PrivateKey  string `json:"private_key"`
+	Passphrase  string `json:"passphrase"`
+}
+
+// AssignCredentialRequest 批量分配凭据请求
+type AssignCredentialRequest struct {
+	MachineIDs []string `json:"machine_ids" binding:"required,min=1"`
+}
+
+// CreateCredential 创建共享凭据
+func (h *MachineHandler) CreateCredential(c *gin.Context) {
+	var req MachineCredentialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid request body: %v", err)
+		return
+	}
+
+	credential := &model.MachineCredential{
+		Name:        req.Name,
+		Description: req.Description,
+		Username:    req.Username,
+		AuthType:    req.AuthType,
+	}
+
+	if err := h.machineService.CreateCredential(credential, service.MachineSecrets{
+		Password:   req.Password,
+		PrivateKey: req.PrivateKey,
+		Passphrase: req.Passphrase,
+	}); err != nil {
+		utils.Error(c, utils.ErrCodeInternalError, "Failed to create credential: %v", err)
+		return
+	}
+
+	h.logCredentialAudit(c, constants.EventTypeCreate, credential.ID.String(), nil, credentialAuditData(credential), "create_machine_credential")
+
+	utils.Success(c, http.StatusCreated, credential)
+}
+
+// ListCredentials 获取共享凭据列表
+func (h *MachineHandler) ListCredentials(c *gin.Context) {
+	credentials, err := h.machineService.ListCredentials()
+	if err != nil {
+		utils.Error(c, utils.ErrCodeInternalError, "Failed to list credentials: %v", err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, gin.H{
+		"credentials": credentials,
+		"total":       len(credentials),
+	})
+}
+
+// GetCredential 获取共享凭据详情
+func (h *MachineHandler) GetCredential(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid credential ID")
+		return
+	}
+
+	credential, err := h.machineService.GetCredential(id)
+	if err != nil {
+		utils.Error(c, utils.ErrCodeNotFound, "Credential not found")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, credential)
+}
+
+// UpdateCredential 更新共享凭据
+func (h *MachineHandler) UpdateCredential(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid credential ID")
+		return
+	}
+
+	var req MachineCredentialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid request body: %v", err)
+		return
+	}
+
+	credential, err := h.machineService.GetCredential(id)
+	if err != nil {
+		utils.Error(c, utils.ErrCodeNotFound, "Credential not found")
+		return
+	}
+
+	oldData := credentialAuditData(credential)
+	credential.Name = req.Name
+	credential.Description = req.Description
+	credential.Username = req.Username
+	if req.AuthType != "" {
+		credential.AuthType = req.AuthType
+	}
+
+	if err := h.machineService.UpdateCredential(credential, service.MachineSecrets{
+		Password:   req.Password,
+		PrivateKey: req.PrivateKey,
+		Passphrase: req.Passphrase,
+	}); err != nil {
+		utils.Error(c, utils.ErrCodeInternalError, "Failed to update credential: %v", err)
+		return
+	}
+
+	h.logCredentialAudit(c, constants.EventTypeUpdate, id.String(), oldData, credentialAuditData(credential), "update_machine_credential")
+
+	utils.Success(c, http.StatusOK, credential)
+}
+
+// DeleteCredential 删除共享凭据
+func (h *MachineHandler) DeleteCredential(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid credential ID")
+		return
+	}
+
+	credential, err := h.machineService.GetCredential(id)
+	if err != nil {
+		utils.Error(c, utils.ErrCodeNotFound, "Credential not found")
+		return
+	}
+
+	if err := h.machineService.DeleteCredential(id); err != nil {
+		utils.Error(c, utils.ErrCodeConflict, "Failed to delete credential: %v", err)
+		return
+	}
+
+	h.logCredentialAudit(c, constants.EventTypeDelete, id.String(), credentialAuditData(credential), nil, "delete_machine_credential")
+
+	utils.Success(c, http.StatusOK, gin.H{"message": "Credential deleted successfully"})
+}
+
+// AssignCredential 批量为机器分配共享凭据
+func (h *MachineHandler) AssignCredential(c *gin.Context) {
+	h.changeCredentialAssignment(c, true)
+}
+
+// UnassignCredential 批量解除机器的共享凭据
+func (h *MachineHandler) UnassignCredential(c *gin.Context) {
+	h.changeCredentialAssignment(c, false)
+}
+
+// changeCredentialAssignment 处理凭据分配/解除
+func (h *MachineHandler) changeCredentialAssignment(c *gin.Context, assign bool) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid credential ID")
+		return
+	}
+
+	var req AssignCredentialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid request body: %v", err)
+		return
+	}
+
+	machineIDs := make([]uuid.UUID, 0, len(req.MachineIDs))
+	for _, idStr := range req.MachineIDs {
+		machineID, err := utils.ParseUUID(idStr)
+		if err != nil {
+			utils.Error(c, utils.ErrCodeValidationFailed, "Invalid machine ID: %s", idStr)
+			return
+		}
+		machineIDs = append(machineIDs, machineID)
+	}
+
+	var affected int64
+	operation := "assign_machine_credential"
+	if assign {
+		affected, err = h.machineService.AssignCredential(id, machineIDs)
+	} else {
+		operation = "unassign_machine_credential"
+		affected, err = h.machineService.UnassignCredential(id, machineIDs)
+	}
+	if err != nil {
+		utils.Error(c, utils.ErrCodeInternalError, "Failed to change credential assignment: %v", err)
+		return
+	}
+
+	h.logCredentialAudit(c, constants.EventTypeUpdate, id.String(), nil,
+		map[string]interface{}{
+			"machine_ids": req.MachineIDs,
+			"affected":    affected,
+		},
+		operation,
+	)
+
+	utils.Success(c, http.StatusOK, gin.H{
+		"credential_id": id,
+		"affected":      affected,
+	})
+}
+
+// logCredentialAudit 记录凭据审计事件，不包含敏感字段
+func (h *MachineHandler) logCredentialAudit(c *gin.Context, eventType, resourceID string, oldData, newData map[string]interface{}, operation string) {
+	if h.auditService == nil {
+		return
+	}
+
+	h.auditService.CreateAuditEvent(
+		uuid.Nil,
+		"machine_credential",
+		eventType,
+		"machine_credential",
+		resourceID,
+		"api-user",
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+		oldData,
+		newData,
+		map[string]interface{}{
+			"operation": operation,
+		},
+		constants.StatusSuccess,
+	)
+}
+
+// credentialAuditData 凭据的审计数据
+func credentialAuditData(credential *model.MachineCredential) map[string]interface{} {
+	return map[string]interface{}{
+		"name":      credential.Name,
+		"username":  credential.Username,
+		"auth_type": credential.AuthType,
+	}
+}
@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -10,6 +12,7 @@ import (
 	"github.com/taichu-system/cluster-management/internal/model"
 	"github.com/taichu-system/cluster-management/internal/service"
 	"github.com/taichu-system/cluster-management/pkg/utils"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // MachineHandler 机器处理器
@@ -32,7 +35,12 @@ type CreateMachineRequest struct {
 	IPAddress        string            `json:"ip_address" binding:"required,ip"`
 	InternalAddress  string            `json:"internal_address" binding:"omitempty,ip"`
 	User             string            `json:"user" binding:"required,min=1,max=50"`
-	Password         string            `json:"password" binding:"required,min=6"`
+	Password         string            `json:"password" binding:"omitempty,min=6"`
+	AuthType         string            `json:"auth_type" binding:"omitempty,oneof=password private_key"`
+	PrivateKey       string            `json:"private_key"`
+	Passphrase       string            `json:"passphrase"`
+	SSHPort          int               `json:"ssh_port" binding:"omitempty,min=1,max=65535"`
+	CredentialID     string            `json:"credential_id" binding:"omitempty,uuid"`
 	Role             string            `json:"role" binding:"required,oneof=master worker etcd registry"`
 	ArtifactPath     string            `json:"artifact_path"`
 	ImageRepo        string            `json:"image_repo"`
@@ -53,6 +61,10 @@ func (h *MachineHandler) CreateMachine(c *gin.Context) {
 		utils.Error(c, utils.ErrCodeValidationFailed, "Machine name is required")
 		return
 	}
+	if err := validateMachineName(req.Name); err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "%v", err)
+		return
+	}
 	if req.IPAddress == "" {
 		utils.Error(c, utils.ErrCodeValidationFailed, "IP address is required")
 		return
@@ -61,23 +73,28 @@ func (h *MachineHandler) CreateMachine(c *gin.Context) {
 		utils.Error(c, utils.ErrCodeValidationFailed, "User is required")
 		return
 	}
-	if req.Password == "" {
-		utils.Error(c, utils.ErrCodeValidationFailed, "Password is required")
-		return
-	}
 
 	machine := &model.Machine{
 		Name:             req.Name,
 		IPAddress:        req.IPAddress,
 		InternalAddress:  req.InternalAddress,
 		User:             req.User,
-		Password:         req.Password,
+		AuthType:         req.AuthType,
+		SSHPort:          req.SSHPort,
 		Role:             req.Role,
 		ArtifactPath:     req.ArtifactPath,
 		ImageRepo:        req.ImageRepo,
 		RegistryAddress:  req.RegistryAddress,
 		Labels:           convertMachineLabelsToJSONMap(req.Labels),
 	}
+	if machine.SSHPort == 0 {
+		machine.SSHPort = 22
+	}
+
+	if err := h.applyMachineAuth(machine, &req); err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid machine credential: %v", err)
+		return
+	}
 
 	if err := h.machineService.CreateMachine(machine); err != nil {
 		utils.Error(c, utils.ErrCodeInternalError, "Failed to create machine: %v", err)
@@ -180,6 +197,10 @@ func (h *MachineHandler) UpdateMachine(c *gin.Context) {
 		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid request body: %v", err)
 		return
 	}
+	if err := validateMachineName(req.Name); err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "%v", err)
+		return
+	}
 
 	machine, err := h.machineService.GetMachine(id)
 	if err != nil {
@@ -192,8 +213,15 @@ func (h *MachineHandler) UpdateMachine(c *gin.Context) {
 	machine.IPAddress = req.IPAddress
 	machine.InternalAddress = req.InternalAddress
 	machine.User = req.User
-	if req.Password != "" {
-		machine.Password = req.Password
+	if req.AuthType != "" {
+		machine.AuthType = req.AuthType
+	}
+	if req.SSHPort != 0 {
+		machine.SSHPort = req.SSHPort
+	}
+	if err := h.applyMachineAuth(machine, &req); err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid machine credential: %v", err)
+		return
 	}
 	machine.Role = req.Role
 	machine.ArtifactPath = req.ArtifactPath
@@ -350,6 +378,38 @@ func (h *MachineHandler) UpdateMachineStatus(c *gin.Context) {
 	utils.Success(c, http.StatusOK, gin.H{"message": "Machine status updated successfully"})
 }
 
+// validateMachineName 机器名称会作为节点名写入 kubeadm 命令，必须是 DNS-1123 标签
+func validateMachineName(name string) error {
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return fmt.Errorf("invalid machine name %q: %s", name, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// applyMachineAuth 应用请求中的认证信息，敏感字段由服务层加密
+func (h *MachineHandler) applyMachineAuth(machine *model.Machine, req *CreateMachineRequest) error {
+	if req.CredentialID != "" {
+		credentialID, err := utils.ParseUUID(req.CredentialID)
+		if err != nil {
+			return err
+		}
+		if _, err := h.machineService.GetCredential(credentialID); err != nil {
+			return fmt.Errorf("credential not found")
+		}
+		machine.CredentialID = &credentialID
+	}
+
+	if err := h.machineService.ApplySecrets(machine, service.MachineSecrets{
+		Password:   req.Password,
+		PrivateKey: req.PrivateKey,
+		Passphrase: req.Passphrase,
+	}); err != nil {
+		return err
+	}
+
+	return h.machineService.ValidateMachineAuth(machine)
+}
+
 // convertMachineLabelsToJSONMap 转换标签为JSONMap
 func convertMachineLabelsToJSONMap(labels map[string]string) model.JSONMap {
 	jsonMap := make(model.JSONMap)
@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/constants"
+	"github.com/taichu-system/cluster-management/internal/service"
+	"github.com/taichu-system/cluster-management/pkg/utils"
+)
+
+// MaintenanceCampaignHandler 节点滚动维护处理器
+type MaintenanceCampaignHandler struct {
+	campaignService *service.MaintenanceCampaignService
+	auditService    *service.AuditService
+}
+
+// NewMaintenanceCampaignHandler 创建节点滚动维护处理器
+func NewMaintenanceCampaignHandler(campaignService *service.MaintenanceCampaignService, auditService *service.AuditService) *MaintenanceCampaignHandler {
+	return &MaintenanceCampaignHandler{
+		campaignService: campaignService,
+		auditService:    auditService,
+	}
+}
+
+// CreateMaintenanceCampaignRequest 创建维护活动请求，selector 与 node_names 二选一
+type CreateMaintenanceCampaignRequest struct {
+	ClusterID           string            `json:"cluster_id" binding:"required"`
+	Name                string            `json:"name" binding:"required,max=255"`
+	Selector            string            `json:"selector"`
+	NodeNames           []string          `json:"node_names"`
+	MaxUnavailable      int               `json:"max_unavailable" binding:"omitempty,min=1"`
+	Commands            []string          `json:"commands" binding:"required,min=1,dive,required"`
+	Drain               *DrainNodeRequest `json:"drain"`
+	ReadyTimeoutSeconds int               `json:"ready_timeout_seconds" binding:"omitempty,min=60,max=86400"`
+}
+
+// CreateCampaign 创建维护活动并异步执行
+func (h *MaintenanceCampaignHandler) CreateCampaign(c *gin.Context) {
+	var req CreateMaintenanceCampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid request body: %v", err)
+		return
+	}
+	clusterID, err := utils.ParseUUID(req.ClusterID)
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+	if (req.Selector == "") == (len(req.NodeNames) == 0) {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Exactly one of selector or node_names is required")
+		return
+	}
+
+	// 维护场景下默认跳过 DaemonSet Pod
+	opts := service.DrainOptions{
+		IgnoreDaemonSets:   true,
+		GracePeriodSeconds: -1,
+	}
+	if req.Drain != nil {
+		opts.IgnoreDaemonSets = req.Drain.IgnoreDaemonSets
+		opts.DeleteEmptyDirData = req.Drain.DeleteEmptyDirData
+		opts.Force = req.Drain.Force
+		opts.TimeoutSeconds = req.Drain.TimeoutSeconds
+		if req.Drain.GracePeriodSeconds != nil {
+			opts.GracePeriodSeconds = *req.Drain.GracePeriodSeconds
+		}
+	}
+
+	campaign, err := h.campaignService.Create(service.MaintenanceCampaignRequest{
+		ClusterID:           clusterID,
+		Name:                req.Name,
+		Selector:            req.Selector,
+		NodeNames:           req.NodeNames,
+		MaxUnavailable:      req.MaxUnavailable,
+		Commands:            req.Commands,
+		DrainOptions:        opts,
+		ReadyTimeoutSeconds: req.ReadyTimeoutSeconds,
+	}, "api-user")
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.audit(c, clusterID, constants.EventTypeCreate, "create_maintenance_campaign", campaign.ID, map[string]interface{}{
+		"name":            campaign.Name,
+		"nodes":           []string(campaign.NodeNames),
+		"max_unavailable": campaign.MaxUnavailable,
+		"commands":        []string(campaign.Commands),
+	})
+
+	utils.Success(c, http.StatusAccepted, campaign)
+}
+
+// ListCampaigns 获取维护活动列表，可按集群过滤
+func (h *MaintenanceCampaignHandler) ListCampaigns(c *gin.Context) {
+	var clusterID *uuid.UUID
+	if value := c.Query("cluster_id"); value != "" {
+		id, err := utils.ParseUUID(value)
+		if err != nil {
+			utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+			return
+		}
+		clusterID = &id
+	}
+
+	campaigns, err := h.campaignService.List(clusterID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, gin.H{
+		"campaigns": campaigns,
+		"total":     len(campaigns),
+	})
+}
+
+// GetCampaign 获取维护活动详情，包含各节点日志
+func (h *MaintenanceCampaignHandler) GetCampaign(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid campaign ID")
+		return
+	}
+
+	campaign, err := h.campaignService.Get(id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, campaign)
+}
+
+// ResumeCampaign 恢复暂停的维护活动
+func (h *MaintenanceCampaignHandler) ResumeCampaign(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid campaign ID")
+		return
+	}
+
+	campaign, err := h.campaignService.Resume(id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.audit(c, campaign.ClusterID, constants.EventTypeUpdate, "resume_maintenance_campaign", campaign.ID, nil)
+	utils.Success(c, http.StatusAccepted, campaign)
+}
+
+// CancelCampaign 取消维护活动
+func (h *MaintenanceCampaignHandler) CancelCampaign(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid campaign ID")
+		return
+	}
+
+	campaign, err := h.campaignService.Cancel(id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.audit(c, campaign.ClusterID, constants.EventTypeUpdate, "cancel_maintenance_campaign", campaign.ID, nil)
+	utils.Success(c, http.StatusOK, campaign)
+}
+
+// audit 记录维护活动审计事件
+func (h *MaintenanceCampaignHandler) audit(c *gin.Context, clusterID uuid.UUID, eventType, action string, campaignID uuid.UUID, details map[string]interface{}) {
+	if h.auditService == nil {
+		return
+	}
+	h.auditService.CreateAuditEvent(
+		clusterID,
+		eventType,
+		action,
+		constants.ResourceTypeCluster,
+		campaignID.String(),
+		"api-user",
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+		nil,
+		nil,
+		details,
+		constants.StatusSuccess,
+	)
+}
+
+// handleError 转换维护活动相关错误
+func (h *MaintenanceCampaignHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrClusterNotFound):
+		utils.Error(c, utils.ErrCodeNotFound, "Cluster not found")
+	case errors.Is(err, service.ErrCampaignNotFound):
+		utils.Error(c, utils.ErrCodeNotFound, "Maintenance campaign not found")
+	case errors.Is(err, service.ErrNodeNotFound), errors.Is(err, service.ErrInvalidCampaign):
+		utils.Error(c, utils.ErrCodeValidationFailed, "%v", err)
+	case errors.Is(err, service.ErrCampaignInProgress):
+		utils.Error(c, utils.ErrCodeConflict, "Cluster already has an unfinished maintenance campaign")
+	case errors.Is(err, service.ErrCampaignStateConflict):
+		utils.Error(c, utils.ErrCodeConflict, "%v", err)
+	default:
+		utils.Error(c, utils.ErrCodeInternalError, "Maintenance campaign operation failed: %v", err)
+	}
+}
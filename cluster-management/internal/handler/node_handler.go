@@ -51,6 +51,14 @@ type NodeDetailResponse struct {
 	MemoryUsagePercent float64  `json:"memory_usage_percent"`
 	PodCount          int       `json:"pod_count"`
 	Labels            map[string]string `json:"labels"`
+	Taints            []string        `json:"taints"`
+	KubeletVersion          string `json:"kubelet_version"`
+	KubeProxyVersion        string `json:"kube_proxy_version"`
+	ContainerRuntime        string `json:"container_runtime"`
+	ContainerRuntimeVersion string `json:"container_runtime_version"`
+	OSImage                 string `json:"os_image"`
+	KernelVersion           string `json:"kernel_version"`
+	Architecture            string `json:"architecture"`
 	Conditions        []NodeCondition `json:"conditions"`
 	Addresses         []NodeAddress   `json:"addresses"`
 	CreatedAt         string    `json:"created_at"`
@@ -156,6 +164,14 @@ func (h *NodeHandler) GetNode(c *gin.Context) {
 		MemoryUsagePercent: float64(node.MemoryUsedBytes) / float64(node.MemoryBytes) * 100,
 		PodCount:          node.PodCount,
 		Labels:            convertLabels(node.Labels),
+		Taints:            node.Taints,
+		KubeletVersion:          node.KubeletVersion,
+		KubeProxyVersion:        node.KubeProxyVersion,
+		ContainerRuntime:        node.ContainerRuntime,
+		ContainerRuntimeVersion: node.ContainerRuntimeVersion,
+		OSImage:                 node.OSImage,
+		KernelVersion:           node.KernelVersion,
+		Architecture:            node.Architecture,
 		CreatedAt:         node.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:         node.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
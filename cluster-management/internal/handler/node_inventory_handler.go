@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taichu-system/cluster-management/internal/service"
+	"github.com/taichu-system/cluster-management/pkg/utils"
+)
+
+// NodeInventoryHandler 节点运行时清单处理器
+type NodeInventoryHandler struct {
+	nodeInventoryService *service.NodeInventoryService
+}
+
+// NewNodeInventoryHandler 创建节点运行时清单处理器
+func NewNodeInventoryHandler(nodeInventoryService *service.NodeInventoryService) *NodeInventoryHandler {
+	return &NodeInventoryHandler{
+		nodeInventoryService: nodeInventoryService,
+	}
+}
+
+// GetFleetVersionReport 获取全部集群的版本偏差与运行时报告
+func (h *NodeInventoryHandler) GetFleetVersionReport(c *gin.Context) {
+	report, err := h.nodeInventoryService.FleetVersionReport()
+	if err != nil {
+		utils.Error(c, utils.ErrCodeInternalError, "Failed to build version report: %v", err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, report)
+}
@@ -0,0 +1,373 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/constants"
+	"github.com/taichu-system/cluster-management/internal/service"
+	"github.com/taichu-system/cluster-management/pkg/utils"
+)
+
+// NodeOperationHandler 节点运维操作处理器
+type NodeOperationHandler struct {
+	nodeOperationService *service.NodeOperationService
+	auditService         *service.AuditService
+}
+
+// NewNodeOperationHandler 创建节点运维操作处理器
+func NewNodeOperationHandler(nodeOperationService *service.NodeOperationService, auditService *service.AuditService) *NodeOperationHandler {
+	return &NodeOperationHandler{
+		nodeOperationService: nodeOperationService,
+		auditService:         auditService,
+	}
+}
+
+// DrainNodeRequest 节点排空请求
+type DrainNodeRequest struct {
+	IgnoreDaemonSets   bool `json:"ignore_daemonsets"`
+	DeleteEmptyDirData bool `json:"delete_emptydir_data"`
+	Force              bool `json:"force"`
+	GracePeriodSeconds *int `json:"grace_period_seconds" binding:"omitempty,min=-1"`
+	TimeoutSeconds     int  `json:"timeout_seconds" binding:"omitempty,min=1,max=86400"`
+}
+
+// CordonNode 封锁节点
+func (h *NodeOperationHandler) CordonNode(c *gin.Context) {
+	h.setSchedulable(c, "cordon_node")
+}
+
+// UncordonNode 解除节点封锁
+func (h *NodeOperationHandler) UncordonNode(c *gin.Context) {
+	h.setSchedulable(c, "uncordon_node")
+}
+
+// setSchedulable 执行封锁或解除封锁
+func (h *NodeOperationHandler) setSchedulable(c *gin.Context, action string) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+	nodeName := c.Param("nodeName")
+
+	if action == "cordon_node" {
+		err = h.nodeOperationService.Cordon(c.Request.Context(), id, nodeName)
+	} else {
+		err = h.nodeOperationService.Uncordon(c.Request.Context(), id, nodeName)
+	}
+	h.audit(c, id, action, nodeName, nil, err)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, gin.H{
+		"node_name":     nodeName,
+		"unschedulable": action == "cordon_node",
+	})
+}
+
+// DrainNode 排空节点，异步执行并返回任务
+func (h *NodeOperationHandler) DrainNode(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+	nodeName := c.Param("nodeName")
+
+	var req DrainNodeRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.Error(c, utils.ErrCodeValidationFailed, "Invalid request body: %v", err)
+			return
+		}
+	}
+
+	opts := service.DrainOptions{
+		IgnoreDaemonSets:   req.IgnoreDaemonSets,
+		DeleteEmptyDirData: req.DeleteEmptyDirData,
+		Force:              req.Force,
+		GracePeriodSeconds: -1,
+		TimeoutSeconds:     req.TimeoutSeconds,
+	}
+	if req.GracePeriodSeconds != nil {
+		opts.GracePeriodSeconds = *req.GracePeriodSeconds
+	}
+
+	task, err := h.nodeOperationService.StartDrain(id, nodeName, opts, "api-user")
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.audit(c, id, "start_drain_node", nodeName, map[string]interface{}{
+		"drain_task_id": task.ID.String(),
+		"options":       task.Options,
+	}, nil)
+
+	utils.Success(c, http.StatusAccepted, task)
+}
+
+// ListDrainTasks 获取节点的排空任务
+func (h *NodeOperationHandler) ListDrainTasks(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	tasks, err := h.nodeOperationService.ListDrainTasks(id, c.Param("nodeName"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, gin.H{
+		"tasks": tasks,
+		"total": len(tasks),
+	})
+}
+
+// GetDrainTask 获取排空任务详情
+func (h *NodeOperationHandler) GetDrainTask(c *gin.Context) {
+	taskID, err := utils.ParseUUID(c.Param("taskId"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid drain task ID")
+		return
+	}
+
+	task, err := h.nodeOperationService.GetDrainTask(taskID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	if task.ClusterID.String() != c.Param("id") {
+		utils.Error(c, utils.ErrCodeNotFound, "Drain task not found")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, task)
+}
+
+// NodeLabelsRequest 节点标签修改请求，批量修改时需要 selector
+type NodeLabelsRequest struct {
+	Selector  string            `json:"selector"`
+	Operation string            `json:"operation" binding:"required,oneof=add remove replace"`
+	Labels    map[string]string `json:"labels"`
+	Keys      []string          `json:"keys"`
+}
+
+// NodeTaintsRequest 节点污点修改请求，批量修改时需要 selector
+type NodeTaintsRequest struct {
+	Selector  string              `json:"selector"`
+	Operation string              `json:"operation" binding:"required,oneof=add remove replace"`
+	Taints    []service.TaintSpec `json:"taints"`
+}
+
+// UpdateNodeLabels 修改单个节点的标签
+func (h *NodeOperationHandler) UpdateNodeLabels(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	var req NodeLabelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid request body: %v", err)
+		return
+	}
+
+	result, err := h.nodeOperationService.UpdateNodeLabels(c.Request.Context(), id, c.Param("nodeName"), service.NodeLabelChange{
+		Operation: req.Operation,
+		Labels:    req.Labels,
+		Keys:      req.Keys,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.auditMetadata(c, id, "update_node_labels", req.Operation, result)
+	if !result.Success {
+		utils.Error(c, utils.ErrCodeInternalError, "Failed to update node labels: %s", result.Error)
+		return
+	}
+	utils.Success(c, http.StatusOK, result)
+}
+
+// UpdateNodeTaints 修改单个节点的污点
+func (h *NodeOperationHandler) UpdateNodeTaints(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	var req NodeTaintsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid request body: %v", err)
+		return
+	}
+
+	result, err := h.nodeOperationService.UpdateNodeTaints(c.Request.Context(), id, c.Param("nodeName"), service.NodeTaintChange{
+		Operation: req.Operation,
+		Taints:    req.Taints,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.auditMetadata(c, id, "update_node_taints", req.Operation, result)
+	if !result.Success {
+		utils.Error(c, utils.ErrCodeInternalError, "Failed to update node taints: %s", result.Error)
+		return
+	}
+	utils.Success(c, http.StatusOK, result)
+}
+
+// BulkUpdateNodeLabels 修改选择器匹配的全部节点的标签
+func (h *NodeOperationHandler) BulkUpdateNodeLabels(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	var req NodeLabelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid request body: %v", err)
+		return
+	}
+
+	results, err := h.nodeOperationService.BulkUpdateNodeLabels(c.Request.Context(), id, req.Selector, service.NodeLabelChange{
+		Operation: req.Operation,
+		Labels:    req.Labels,
+		Keys:      req.Keys,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	for _, result := range results {
+		h.auditMetadata(c, id, "update_node_labels", req.Operation, result)
+	}
+	utils.Success(c, http.StatusOK, gin.H{
+		"selector": req.Selector,
+		"results":  results,
+		"total":    len(results),
+	})
+}
+
+// BulkUpdateNodeTaints 修改选择器匹配的全部节点的污点
+func (h *NodeOperationHandler) BulkUpdateNodeTaints(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	var req NodeTaintsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid request body: %v", err)
+		return
+	}
+
+	results, err := h.nodeOperationService.BulkUpdateNodeTaints(c.Request.Context(), id, req.Selector, service.NodeTaintChange{
+		Operation: req.Operation,
+		Taints:    req.Taints,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	for _, result := range results {
+		h.auditMetadata(c, id, "update_node_taints", req.Operation, result)
+	}
+	utils.Success(c, http.StatusOK, gin.H{
+		"selector": req.Selector,
+		"results":  results,
+		"total":    len(results),
+	})
+}
+
+// auditMetadata 记录单个节点标签或污点修改的审计事件
+func (h *NodeOperationHandler) auditMetadata(c *gin.Context, clusterID uuid.UUID, action, operation string, result *service.NodeMetadataResult) {
+	if h.auditService == nil {
+		return
+	}
+	status := constants.StatusSuccess
+	details := map[string]interface{}{"operation": operation}
+	if !result.Success {
+		status = constants.StatusFailed
+		details["error"] = result.Error
+	}
+	h.auditService.CreateAuditEvent(
+		clusterID,
+		constants.EventTypeUpdate,
+		action,
+		constants.ResourceTypeNode,
+		result.NodeName,
+		"api-user",
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+		map[string]interface{}{"value": result.Old},
+		map[string]interface{}{"value": result.New},
+		details,
+		status,
+	)
+}
+
+// audit 记录节点操作审计事件
+func (h *NodeOperationHandler) audit(c *gin.Context, clusterID uuid.UUID, action, nodeName string, details map[string]interface{}, err error) {
+	if h.auditService == nil {
+		return
+	}
+	status := constants.StatusSuccess
+	if err != nil {
+		status = constants.StatusFailed
+		if details == nil {
+			details = map[string]interface{}{}
+		}
+		details["error"] = err.Error()
+	}
+	h.auditService.CreateAuditEvent(
+		clusterID,
+		constants.EventTypeUpdate,
+		action,
+		constants.ResourceTypeNode,
+		nodeName,
+		"api-user",
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+		nil,
+		nil,
+		details,
+		status,
+	)
+}
+
+// handleError 转换节点操作相关错误
+func (h *NodeOperationHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrClusterNotFound):
+		utils.Error(c, utils.ErrCodeNotFound, "Cluster not found")
+	case errors.Is(err, service.ErrNodeNotFound):
+		utils.Error(c, utils.ErrCodeNotFound, "Node not found")
+	case errors.Is(err, service.ErrDrainTaskNotFound):
+		utils.Error(c, utils.ErrCodeNotFound, "Drain task not found")
+	case errors.Is(err, service.ErrInvalidNodeMetadata):
+		utils.Error(c, utils.ErrCodeValidationFailed, "%v", err)
+	case errors.Is(err, service.ErrDrainInProgress):
+		utils.Error(c, utils.ErrCodeConflict, "Node drain already in progress")
+	default:
+		utils.Error(c, utils.ErrCodeInternalError, "Node operation failed: %v", err)
+	}
+}
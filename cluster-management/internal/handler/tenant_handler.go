@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -316,3 +317,69 @@ func (h *TenantHandler) InitializePredefinedTenants(c *gin.Context) {
 	})
 }
 
+
+// AddTenantMember 添加租户成员，成员经 API 代理访问集群时获得对应租户用户组
+func (h *TenantHandler) AddTenantMember(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "租户ID格式错误")
+		return
+	}
+
+	var req service.AddTenantMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "请求参数错误: %v", err)
+		return
+	}
+
+	member, err := h.tenantService.AddTenantMember(id.String(), req)
+	if err != nil {
+		h.handleMemberError(c, err)
+		return
+	}
+	utils.Success(c, http.StatusCreated, member)
+}
+
+// ListTenantMembers 获取租户成员
+func (h *TenantHandler) ListTenantMembers(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "租户ID格式错误")
+		return
+	}
+
+	members, err := h.tenantService.ListTenantMembers(id.String())
+	if err != nil {
+		h.handleMemberError(c, err)
+		return
+	}
+	utils.Success(c, http.StatusOK, members)
+}
+
+// RemoveTenantMember 移除租户成员
+func (h *TenantHandler) RemoveTenantMember(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "租户ID格式错误")
+		return
+	}
+
+	if err := h.tenantService.RemoveTenantMember(id.String(), c.Param("userId")); err != nil {
+		h.handleMemberError(c, err)
+		return
+	}
+	utils.Success(c, http.StatusOK, gin.H{"message": "移除成功"})
+}
+
+func (h *TenantHandler) handleMemberError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrTenantNotFound):
+		utils.Error(c, utils.ErrCodeNotFound, "租户不存在")
+	case errors.Is(err, service.ErrUserNotFound):
+		utils.Error(c, utils.ErrCodeNotFound, "用户不存在")
+	case errors.Is(err, service.ErrTenantMemberNotFound):
+		utils.Error(c, utils.ErrCodeNotFound, "租户成员不存在")
+	default:
+		utils.Error(c, utils.ErrCodeInternalError, "处理租户成员失败: %v", err)
+	}
+}
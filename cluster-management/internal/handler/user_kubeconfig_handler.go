@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/constants"
+	"github.com/taichu-system/cluster-management/internal/service"
+	"github.com/taichu-system/cluster-management/pkg/utils"
+)
+
+// UserKubeconfigHandler 用户 kubeconfig 签发处理器
+type UserKubeconfigHandler struct {
+	kubeconfigService     *service.UserKubeconfigService
+	auditService          *service.AuditService
+	trustForwardedHeaders bool
+}
+
+// NewUserKubeconfigHandler 创建用户 kubeconfig 签发处理器
+// trustForwardedHeaders 为 true 时，未配置平台地址的情况下按反向代理设置的请求头推导
+func NewUserKubeconfigHandler(kubeconfigService *service.UserKubeconfigService, auditService *service.AuditService, trustForwardedHeaders bool) *UserKubeconfigHandler {
+	return &UserKubeconfigHandler{
+		kubeconfigService:     kubeconfigService,
+		auditService:          auditService,
+		trustForwardedHeaders: trustForwardedHeaders,
+	}
+}
+
+// Issue 为当前用户签发集群 kubeconfig，权限限定在所属租户环境的命名空间
+func (h *UserKubeconfigHandler) Issue(c *gin.Context) {
+	// 代理令牌只能访问集群 API 代理，不能用来签发新的 kubeconfig
+	if c.GetString("token_id") != "" {
+		utils.Error(c, utils.ErrCodeForbidden, "Kubeconfig proxy tokens cannot issue kubeconfigs")
+		return
+	}
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	var req service.IssueKubeconfigRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.Error(c, utils.ErrCodeValidationFailed, "Invalid request body: %v", err)
+			return
+		}
+	}
+	if h.trustForwardedHeaders {
+		req.ServerURL = forwardedBaseURL(c)
+	}
+
+	identity := service.ProxyIdentity{
+		UserID:   c.GetString("user_id"),
+		Username: c.GetString("username"),
+		Role:     c.GetString("role"),
+	}
+	issued, err := h.kubeconfigService.Issue(c.Request.Context(), id, identity, req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.audit(c, id, constants.EventTypeCreate, "issue_user_kubeconfig", issued.ID.String(), identity.Username, map[string]interface{}{
+		"mode":       issued.Mode,
+		"subject":    issued.Subject,
+		"namespaces": issued.Namespaces,
+		"expires_at": issued.ExpiresAt,
+	})
+	utils.Success(c, http.StatusCreated, issued)
+}
+
+// List 获取集群的 kubeconfig 签发记录，支持 user_id 与 status 过滤
+func (h *UserKubeconfigHandler) List(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+
+	var userID *uuid.UUID
+	if value := c.Query("user_id"); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			utils.Error(c, utils.ErrCodeValidationFailed, "Invalid user_id")
+			return
+		}
+		userID = &parsed
+	}
+
+	kubeconfigs, err := h.kubeconfigService.List(id, userID, c.Query("status"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	utils.Success(c, http.StatusOK, kubeconfigs)
+}
+
+// Revoke 吊销 kubeconfig 并删除集群内的授权
+func (h *UserKubeconfigHandler) Revoke(c *gin.Context) {
+	id, err := utils.ParseUUID(c.Param("id"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid cluster ID")
+		return
+	}
+	kubeconfigID, err := utils.ParseUUID(c.Param("kubeconfigId"))
+	if err != nil {
+		utils.Error(c, utils.ErrCodeValidationFailed, "Invalid kubeconfig ID")
+		return
+	}
+
+	user := c.GetString("username")
+	if user == "" {
+		user = "api-user"
+	}
+	record, err := h.kubeconfigService.Revoke(c.Request.Context(), id, kubeconfigID, user)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.audit(c, id, constants.EventTypeDelete, "revoke_user_kubeconfig", record.ID.String(), user, map[string]interface{}{
+		"username":      record.Username,
+		"mode":          record.Mode,
+		"cleanup_error": record.CleanupError,
+	})
+	utils.Success(c, http.StatusOK, record)
+}
+
+func (h *UserKubeconfigHandler) audit(c *gin.Context, clusterID uuid.UUID, eventType, action, resourceID, user string, details map[string]interface{}) {
+	if h.auditService == nil {
+		return
+	}
+	h.auditService.CreateAuditEvent(
+		clusterID,
+		eventType,
+		action,
+		constants.ResourceTypeCluster,
+		resourceID,
+		user,
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+		nil,
+		nil,
+		details,
+		constants.StatusSuccess,
+	)
+}
+
+// handleError 转换 kubeconfig 签发相关错误
+func (h *UserKubeconfigHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrClusterNotFound):
+		utils.Error(c, utils.ErrCodeNotFound, "Cluster not found")
+	case errors.Is(err, service.ErrUserKubeconfigNotFound):
+		utils.Error(c, utils.ErrCodeNotFound, "Kubeconfig not found")
+	case errors.Is(err, service.ErrProxyUnauthenticated):
+		utils.Error(c, utils.ErrCodeUnauthorized, "%v", err)
+	case errors.Is(err, service.ErrNoTenantNamespaces),
+		errors.Is(err, service.ErrTenantMemberNotFound),
+		errors.Is(err, service.ErrInvalidKubeconfigExpiration),
+		errors.Is(err, service.ErrKubeconfigRequiresProxy),
+		errors.Is(err, service.ErrKubeconfigServerURLRequired):
+		utils.Error(c, utils.ErrCodeValidationFailed, "%v", err)
+	default:
+		utils.Error(c, utils.ErrCodeInternalError, "Failed to process kubeconfig: %v", err)
+	}
+}
+
+// forwardedBaseURL 根据可信反向代理设置的 X-Forwarded-Proto 与 X-Forwarded-Host 推导平台对外地址
+// 请求头由客户端提供，只能在反向代理会覆盖这些请求头时使用
+func forwardedBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+	host := c.Request.Host
+	if forwarded := c.GetHeader("X-Forwarded-Host"); forwarded != "" {
+		host = strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	return scheme + "://" + host
+}
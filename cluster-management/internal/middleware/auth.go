@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/taichu-system/cluster-management/internal/constants"
 )
 
 // JWTClaims JWT声明结构
@@ -18,7 +19,18 @@ type JWTClaims struct {
 }
 
 // JWTMiddleware JWT认证中间件
+// 代理模式 kubeconfig 签发的令牌只能用于集群 API 代理，在此拒绝
 func JWTMiddleware(secretKey string) gin.HandlerFunc {
+	return authenticate(secretKey, false)
+}
+
+// ProxyJWTMiddleware 集群 API 代理的认证中间件，同时接受平台令牌与 kubeconfig 代理令牌
+// 代理令牌的 ID 写入上下文的 token_id，由代理服务校验吊销状态与所属集群
+func ProxyJWTMiddleware(secretKey string) gin.HandlerFunc {
+	return authenticate(secretKey, true)
+}
+
+func authenticate(secretKey string, allowProxyToken bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 从请求头获取token
 		authHeader := c.GetHeader("Authorization")
@@ -49,10 +61,19 @@ func JWTMiddleware(secretKey string) gin.HandlerFunc {
 
 		// 验证token有效性
 		if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
+			proxyToken := isProxyToken(claims)
+			if proxyToken && (!allowProxyToken || claims.ID == "") {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Token is only valid for the cluster API proxy"})
+				c.Abort()
+				return
+			}
 			// 将用户信息存储到上下文中
 			c.Set("user_id", claims.UserID)
 			c.Set("username", claims.Username)
 			c.Set("role", claims.Role)
+			if proxyToken {
+				c.Set("token_id", claims.ID)
+			}
 			c.Next()
 		} else {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
@@ -62,6 +83,16 @@ func JWTMiddleware(secretKey string) gin.HandlerFunc {
 	}
 }
 
+// isProxyToken 令牌是否为代理模式 kubeconfig 签发
+func isProxyToken(claims *JWTClaims) bool {
+	for _, audience := range claims.Audience {
+		if audience == constants.KubeconfigProxyAudience {
+			return true
+		}
+	}
+	return false
+}
+
 // GenerateToken 生成JWT token
 func GenerateToken(userID, username, role, secretKey string) (string, error) {
 	// 创建token
@@ -141,8 +172,8 @@ func OptionalAuthMiddleware(secretKey string) gin.HandlerFunc {
 			return
 		}
 
-		// 验证token有效性
-		if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
+		// 验证token有效性，代理令牌不作为平台身份
+		if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid && !isProxyToken(claims) {
 			// 将用户信息存储到上下文中
 			c.Set("user_id", claims.UserID)
 			c.Set("username", claims.Username)
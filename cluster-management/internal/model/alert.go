@@ -30,6 +30,7 @@ const (
 	AlertTypeScheduleFailed  AlertType = "schedule_failed"
 	AlertTypeSystemError     AlertType = "system_error"
 	AlertTypeResourceExhausted AlertType = "resource_exhausted"
+	AlertTypeCertificateExpiring AlertType = "certificate_expiring"
 )
 
 type Alert struct {
@@ -55,6 +55,9 @@ type Cluster struct {
 	LastBackupAt        *time.Time `json:"last_backup_at"`
 	EnvironmentType     string    `json:"environment_type" gorm:"size:50;default:'production'"`
 	ImportSource        string    `json:"import_source" gorm:"size:100"`
+	ClusterUID          string    `json:"cluster_uid,omitempty" gorm:"column:cluster_uid;size:64;index"` // kube-system 命名空间 UID，用于识别重复导入
+	CAHash              string    `json:"ca_hash,omitempty" gorm:"column:ca_hash;size:64"`               // 集群根 CA 证书的 SHA-256
+	Fingerprint         string    `json:"fingerprint,omitempty" gorm:"size:64;index"`                    // 由 ClusterUID 与 CAHash 计算的集群指纹
 
 	State *ClusterState `json:"state,omitempty" gorm:"-"`
 }
@@ -74,6 +77,7 @@ type ClusterState struct {
 	LastSyncAt          time.Time `json:"last_sync_at" gorm:"autoUpdateTime"`
 	SyncError           string    `json:"sync_error" gorm:"type:text"`
 	SyncSuccess         bool      `json:"sync_success" gorm:"default:false"`
+	Details             JSONMap   `json:"details" gorm:"type:jsonb"` // 组件级健康检查结果
 	CreatedAt           time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt           time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
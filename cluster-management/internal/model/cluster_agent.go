@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 集群代理状态
+const (
+	ClusterAgentPending      = "pending"      // 已签发注册令牌，代理尚未注册
+	ClusterAgentConnected    = "connected"    // 隧道已建立
+	ClusterAgentDisconnected = "disconnected" // 已注册，隧道断开
+)
+
+// ClusterAgent 部署在集群内、主动连接管理端的代理
+// 注册令牌与代理密钥只保存 SHA-256 摘要
+type ClusterAgent struct {
+	ID              uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ClusterID       uuid.UUID  `json:"cluster_id" gorm:"type:uuid;not null;uniqueIndex"`
+	Status          string     `json:"status" gorm:"size:20;not null;default:'pending'"`
+	TokenHash       string     `json:"-" gorm:"size:64;index"`
+	TokenExpiresAt  *time.Time `json:"token_expires_at"`
+	SecretHash      string     `json:"-" gorm:"size:64"`
+	AgentVersion    string     `json:"agent_version" gorm:"size:50"`
+	Hostname        string     `json:"hostname" gorm:"size:255"`
+	RemoteAddr      string     `json:"remote_addr" gorm:"size:255"`
+	RegisteredAt    *time.Time `json:"registered_at"`
+	ConnectedAt     *time.Time `json:"connected_at"`
+	DisconnectedAt  *time.Time `json:"disconnected_at"`
+	LastHeartbeatAt *time.Time `json:"last_heartbeat_at"`
+	LastHeartbeat   JSONMap    `json:"last_heartbeat" gorm:"type:jsonb"`
+	CreatedAt       time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (ClusterAgent) TableName() string {
+	return "cluster_agents"
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 证书来源
+const (
+	CertificateSourceAPIServer  = "apiserver"  // apiserver 对外提供的服务端证书
+	CertificateSourceKubeconfig = "kubeconfig" // 平台保存的 kubeconfig 中的客户端证书与CA
+	CertificateSourceNode       = "node"       // 平台创建集群节点上的 PKI 文件
+)
+
+// ClusterCertificate 集群证书到期信息
+// 每次检查按集群整体替换，AlertedThreshold 在证书更新前保留以避免重复告警
+type ClusterCertificate struct {
+	ID               uuid.UUID   `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ClusterID        uuid.UUID   `json:"cluster_id" gorm:"type:uuid;not null;index"`
+	Source           string      `json:"source" gorm:"size:50;not null"`
+	Name             string      `json:"name" gorm:"size:255;not null"` // 证书用途或节点上的文件路径
+	NodeName         string      `json:"node_name" gorm:"size:255"`
+	Subject          string      `json:"subject" gorm:"size:500"`
+	Issuer           string      `json:"issuer" gorm:"size:500"`
+	SerialNumber     string      `json:"serial_number" gorm:"size:100"`
+	DNSNames         StringSlice `json:"dns_names" gorm:"type:jsonb"`
+	IsCA             bool        `json:"is_ca" gorm:"default:false"`
+	NotBefore        time.Time   `json:"not_before"`
+	NotAfter         time.Time   `json:"not_after" gorm:"index"`
+	DaysRemaining    int         `json:"days_remaining"`
+	AlertedThreshold int         `json:"alerted_threshold" gorm:"default:0"` // 已告警的最小阈值天数，0 表示未告警
+	CheckedAt        time.Time   `json:"checked_at"`
+	CreatedAt        time.Time   `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time   `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 返回表名
+func (ClusterCertificate) TableName() string {
+	return "cluster_certificates"
+}
+
+// CertificateRotation 证书轮换任务，仅适用于平台创建的集群
+type CertificateRotation struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ClusterID   uuid.UUID  `json:"cluster_id" gorm:"type:uuid;not null;index"`
+	Status      string     `json:"status" gorm:"size:50;default:'pending'"`
+	CurrentStep string     `json:"current_step" gorm:"size:255"`
+	Logs        string     `json:"logs" gorm:"type:text"`
+	ErrorMsg    string     `json:"error_msg" gorm:"type:text"`
+	CreatedBy   string     `json:"created_by" gorm:"size:100"`
+	StartedAt   *time.Time `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 返回表名
+func (CertificateRotation) TableName() string {
+	return "certificate_rotations"
+}
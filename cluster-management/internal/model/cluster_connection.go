@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 集群连接方式
+const (
+	ClusterConnectionDirect     = "direct"
+	ClusterConnectionSSHBastion = "ssh_bastion"
+	ClusterConnectionProxy      = "proxy"
+	ClusterConnectionAgent      = "agent" // 经集群内代理建立的反向隧道访问
+)
+
+// ClusterConnection 集群 apiserver 的连接设置，所有客户端、Informer 与健康检查共用
+// 堡垒机凭据可引用共享凭据，也可内联保存，内联密钥与代理地址均加密存储
+type ClusterConnection struct {
+	ID                  uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ClusterID           uuid.UUID  `json:"cluster_id" gorm:"type:uuid;not null;uniqueIndex"`
+	Mode                string     `json:"mode" gorm:"size:20;not null;default:'direct'"` // direct/ssh_bastion/proxy/agent
+	BastionHost         string     `json:"bastion_host" gorm:"size:255"`
+	BastionPort         int        `json:"bastion_port" gorm:"default:22"`
+	BastionCredentialID *uuid.UUID `json:"bastion_credential_id" gorm:"type:uuid"`
+	BastionUser         string     `json:"bastion_user" gorm:"size:100"`
+	BastionPassword     string     `json:"-" gorm:"type:text"`
+	BastionPrivateKey   string     `json:"-" gorm:"type:text"`
+	BastionPassphrase   string     `json:"-" gorm:"type:text"`
+	BastionHostKey      string     `json:"bastion_host_key" gorm:"type:text"` // authorized_keys 格式，为空时不校验主机密钥
+	ProxyURL            string     `json:"-" gorm:"type:text"`                // http/https/socks5，可能包含认证信息
+	TLSServerName       string     `json:"tls_server_name" gorm:"size:255"`
+	CreatedAt           time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 返回表名
+func (ClusterConnection) TableName() string {
+	return "cluster_connections"
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 集群凭据来源
+const (
+	// ClusterCredentialSourceImport 导入时保存的凭据，首次更换时补记
+	ClusterCredentialSourceImport = "import"
+	// ClusterCredentialSourceUpdate 通过凭据更换接口保存
+	ClusterCredentialSourceUpdate = "update"
+	// ClusterCredentialSourceRollback 回滚到历史凭据
+	ClusterCredentialSourceRollback = "rollback"
+	// ClusterCredentialSourceRotation 最小权限导入的 ServiceAccount 令牌轮换
+	ClusterCredentialSourceRotation = "rotation"
+)
+
+// ClusterCredential 集群保存过的 kubeconfig，用于回滚；Active 为当前使用的凭据
+type ClusterCredential struct {
+	ID                  uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ClusterID           uuid.UUID  `json:"cluster_id" gorm:"type:uuid;not null;index"`
+	KubeconfigEncrypted string     `json:"-" gorm:"column:kubeconfig_encrypted;type:text;not null"`
+	Source              string     `json:"source" gorm:"size:20;not null"` // import/update/rollback/rotation
+	RollbackOf          *uuid.UUID `json:"rollback_of,omitempty" gorm:"type:uuid"`
+	APIServerURL        string     `json:"api_server_url" gorm:"size:255"`
+	Fingerprint         string     `json:"fingerprint,omitempty" gorm:"size:64"`
+	FingerprintVerified bool       `json:"fingerprint_verified" gorm:"default:false"` // 与集群已记录的指纹一致
+	Active              bool       `json:"active" gorm:"default:false"`
+	Reason              string     `json:"reason,omitempty" gorm:"type:text"`
+	CreatedBy           string     `json:"created_by" gorm:"size:100"`
+	CreatedAt           time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (ClusterCredential) TableName() string {
+	return "cluster_credentials"
+}
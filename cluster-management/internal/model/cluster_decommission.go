@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ClusterDecommission 集群退役任务
+// 记录最终备份、节点重置、机器回收与归档的执行过程
+type ClusterDecommission struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ClusterID   uuid.UUID  `json:"cluster_id" gorm:"type:uuid;not null;index"` // 集群归档后不再存在，仅保留ID
+	ClusterName string     `json:"cluster_name" gorm:"size:255;not null"`
+	Provisioner string     `json:"provisioner" gorm:"size:50"`
+	FinalBackup bool       `json:"final_backup" gorm:"default:false"`
+	BackupID    *uuid.UUID `json:"backup_id,omitempty" gorm:"type:uuid"`
+	Force       bool       `json:"force" gorm:"default:false"` // 节点重置失败时仍继续回收与归档
+	MachineIDs  JSONMap    `json:"machine_ids" gorm:"type:jsonb"`
+	Status      string     `json:"status" gorm:"size:50;default:'pending'"`
+	Progress    int        `json:"progress" gorm:"default:0"`
+	CurrentStep string     `json:"current_step" gorm:"size:255"`
+	Logs        string     `json:"logs" gorm:"type:text"`
+	ErrorMsg    string     `json:"error_msg" gorm:"type:text"`
+	ArchiveID   *uuid.UUID `json:"archive_id,omitempty" gorm:"type:uuid"`
+	CreatedBy   string     `json:"created_by" gorm:"size:100"`
+	StartedAt   *time.Time `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 返回表名
+func (ClusterDecommission) TableName() string {
+	return "cluster_decommissions"
+}
+
+// ClusterArchive 已退役集群的归档快照
+// 集群相关记录删除前整体保存，用于审计与追溯
+type ClusterArchive struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ClusterID      uuid.UUID  `json:"cluster_id" gorm:"type:uuid;not null;index"`
+	ClusterName    string     `json:"cluster_name" gorm:"size:255;not null;index"`
+	DecommissionID *uuid.UUID `json:"decommission_id,omitempty" gorm:"type:uuid"`
+	Snapshot       JSONMap    `json:"snapshot" gorm:"type:jsonb"`
+	ArchivedBy     string     `json:"archived_by" gorm:"size:100"`
+	ArchivedAt     time.Time  `json:"archived_at" gorm:"autoCreateTime"`
+}
+
+// TableName 返回表名
+func (ClusterArchive) TableName() string {
+	return "cluster_archives"
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ClusterHealthPeriod 集群健康状态区间，每次状态变化开启新区间
+// 状态不变的检查只更新 LastCheckedAt 与 CheckCount
+type ClusterHealthPeriod struct {
+	ID              uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ClusterID       uuid.UUID  `json:"cluster_id" gorm:"type:uuid;not null;index"`
+	Status          string     `json:"status" gorm:"size:50;not null"`
+	Message         string     `json:"message" gorm:"type:text"` // 断连原因或异常组件
+	StartedAt       time.Time  `json:"started_at" gorm:"not null;index"`
+	EndedAt         *time.Time `json:"ended_at" gorm:"index"` // 为空表示当前状态
+	DurationSeconds int64      `json:"duration_seconds"`      // 区间结束时写入
+	LastCheckedAt   time.Time  `json:"last_checked_at"`
+	CheckCount      int        `json:"check_count" gorm:"default:1"`
+	CreatedAt       time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 返回表名
+func (ClusterHealthPeriod) TableName() string {
+	return "cluster_health_periods"
+}
+
+// ClusterHealthDaily 超过原始保留期的状态区间按天降采样后的各状态累计秒数（UTC 日）
+type ClusterHealthDaily struct {
+	ID                  uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ClusterID           uuid.UUID `json:"cluster_id" gorm:"type:uuid;not null;uniqueIndex:idx_cluster_health_daily_cluster_day"`
+	Day                 time.Time `json:"day" gorm:"type:date;not null;uniqueIndex:idx_cluster_health_daily_cluster_day"`
+	HealthySeconds      int64     `json:"healthy_seconds"`
+	DegradedSeconds     int64     `json:"degraded_seconds"`
+	UnhealthySeconds    int64     `json:"unhealthy_seconds"`
+	DisconnectedSeconds int64     `json:"disconnected_seconds"`
+	IncidentCount       int       `json:"incident_count"` // 当天开始的不可用区间数
+	CreatedAt           time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 返回表名
+func (ClusterHealthDaily) TableName() string {
+	return "cluster_health_daily"
+}
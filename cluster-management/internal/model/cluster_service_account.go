@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 集群凭据方式
+const (
+	// ClusterCredentialKubeconfig 直接保存导入时提供的 kubeconfig
+	ClusterCredentialKubeconfig = "kubeconfig"
+	// ClusterCredentialServiceAccount 导入时使用提供的凭据创建最小权限 ServiceAccount，只保存其令牌
+	ClusterCredentialServiceAccount = "service_account"
+)
+
+// ClusterServiceAccount 管理端在集群内使用的最小权限 ServiceAccount 及其令牌轮换状态
+type ClusterServiceAccount struct {
+	ID                   uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ClusterID            uuid.UUID  `json:"cluster_id" gorm:"type:uuid;not null;uniqueIndex"`
+	Namespace            string     `json:"namespace" gorm:"size:255;not null"`
+	Name                 string     `json:"name" gorm:"size:255;not null"`
+	ClusterRole          string     `json:"cluster_role" gorm:"size:255;not null"`
+	TokenExpiresAt       time.Time  `json:"token_expires_at" gorm:"not null;index"`
+	LastRotatedAt        time.Time  `json:"last_rotated_at"`
+	LastRotationError    string     `json:"last_rotation_error,omitempty" gorm:"type:text"`
+	LastRotationFailedAt *time.Time `json:"last_rotation_failed_at,omitempty"`
+	CreatedAt            time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt            time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (ClusterServiceAccount) TableName() string {
+	return "cluster_service_accounts"
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ClusterTemplate 集群模板
+// 同名模板按版本递增保存，已发布的版本不可修改
+type ClusterTemplate struct {
+	ID                uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Name              string    `json:"name" gorm:"size:255;not null;uniqueIndex:idx_cluster_templates_name_version"`
+	Version           int       `json:"version" gorm:"not null;uniqueIndex:idx_cluster_templates_name_version"`
+	Description       string    `json:"description" gorm:"type:text"`
+	Provisioner       string    `json:"provisioner" gorm:"size:50"`
+	KubernetesVersion string    `json:"kubernetes_version" gorm:"size:50"`
+	ImageRepo         string    `json:"image_repo" gorm:"size:255"`
+	ContainerManager  string    `json:"container_manager" gorm:"size:50"`
+	NetworkPlugin     string    `json:"network_plugin" gorm:"size:50"`
+	PodsCIDR          string    `json:"pods_cidr" gorm:"size:50"`
+	ServiceCIDR       string    `json:"service_cidr" gorm:"size:50"`
+	ArtifactPath      string    `json:"artifact_path" gorm:"type:text"`
+	WithPackages      bool      `json:"with_packages" gorm:"default:false"`
+	Addons            JSONMap   `json:"addons" gorm:"type:jsonb;default:'{}'"`            // 插件名 -> 插件配置
+	RoleRequirements  JSONMap   `json:"role_requirements" gorm:"type:jsonb;default:'{}'"` // 角色 -> {min, max}
+	CreatedBy         string    `json:"created_by" gorm:"size:100"`
+	CreatedAt         time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 返回表名
+func (ClusterTemplate) TableName() string {
+	return "cluster_templates"
+}
@@ -7,13 +7,19 @@ import (
 )
 
 // CreateTask 集群创建任务模型
-// 用于跟踪通过 kk 创建集群的异步任务
+// 用于跟踪通过部署驱动创建集群的异步任务
 type CreateTask struct {
 	ID              uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
 	ClusterName     string    `json:"cluster_name" gorm:"size:255;not null;index"`
 	ClusterID       *uuid.UUID `json:"cluster_id" gorm:"index"` // 成功后关联的集群ID
 	MachineIDs      JSONMap   `json:"machine_ids" gorm:"type:jsonb;default:'[]'"` // 使用的机器ID列表
 	ConfigYaml      string    `json:"config_yaml" gorm:"type:text"` // 生成的配置文件内容
+	Provisioner     string    `json:"provisioner" gorm:"size:50;default:'kk'"` // 部署驱动 kk/kubeadm/fake
+	TemplateID      *uuid.UUID `json:"template_id" gorm:"type:uuid;index"` // 使用的集群模板版本
+	TemplateName    string    `json:"template_name" gorm:"size:255"`
+	TemplateVersion int       `json:"template_version"`
+	Addons          JSONMap   `json:"addons" gorm:"type:jsonb;default:'{}'"` // 插件名 -> 插件配置
+	RegistryAuthsEncrypted string `json:"-" gorm:"type:text"` // 加密的镜像仓库认证，执行时注入配置
 	Status          string    `json:"status" gorm:"size:50;default:'pending'"` // pending/running/success/failed
 	Progress        int       `json:"progress" gorm:"default:0"` // 进度百分比 0-100
 	CurrentStep     string    `json:"current_step" gorm:"size:255"` // 当前执行步骤
@@ -10,6 +10,8 @@ type Event struct {
 	ID              uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
 	ClusterID       uuid.UUID `json:"cluster_id" gorm:"type:uuid;not null;index"`
 	NodeID          *uuid.UUID `json:"node_id" gorm:"type:uuid;index"`
+	// EventUID Kubernetes Event 的 UID，同一事件再次发生时按此更新记录
+	EventUID        string    `json:"event_uid,omitempty" gorm:"type:varchar(64)"`
 	EventType       string    `json:"event_type" gorm:"type:varchar(50);not null"`
 	Message         string    `json:"message" gorm:"type:text;not null"`
 	Severity        string    `json:"severity" gorm:"type:varchar(20);default:'info';check:severity IN ('info','warning','error','critical')"`
@@ -9,23 +9,45 @@ import (
 // Machine 机器信息模型
 // 用于管理基础设施机器，包括部署节点信息
 type Machine struct {
-	ID               uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	Name             string    `json:"name" gorm:"size:255;not null;index"`
-	IPAddress        string    `json:"ip_address" gorm:"size:50;not null;index"`
-	InternalAddress  string    `json:"internal_address" gorm:"size:50"`
-	User             string    `json:"user" gorm:"size:100;not null"`
-	Password         string    `json:"-" gorm:"size:255"` // 加密存储，不在JSON中返回
-	Role             string    `json:"role" gorm:"size:50;not null"` // master/worker/etcd/registry
-	Status           string    `json:"status" gorm:"size:50;default:'available'"`
-	ArtifactPath     string    `json:"artifact_path" gorm:"type:text"`
-	ImageRepo        string    `json:"image_repo" gorm:"size:255"`
-	RegistryAddress  string    `json:"registry_address" gorm:"size:255"`
-	Labels           JSONMap   `json:"labels" gorm:"type:jsonb;default:'{}'"`
-	CreatedAt        time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt        time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	ID               uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Name             string     `json:"name" gorm:"size:255;not null;index"`
+	IPAddress        string     `json:"ip_address" gorm:"size:50;not null;index"`
+	InternalAddress  string     `json:"internal_address" gorm:"size:50"`
+	User             string     `json:"user" gorm:"size:100;not null"`
+	Password         string     `json:"-" gorm:"type:text"`                          // 加密存储，不在JSON中返回
+	AuthType         string     `json:"auth_type" gorm:"size:20;default:'password'"` // password/private_key
+	SSHPort          int        `json:"ssh_port" gorm:"column:ssh_port;default:22"`
+	PrivateKey       string     `json:"-" gorm:"type:text"`                             // 加密存储
+	Passphrase       string     `json:"-" gorm:"type:text"`                             // 加密存储
+	CredentialID     *uuid.UUID `json:"credential_id,omitempty" gorm:"type:uuid;index"` // 共享凭据，优先于机器自身凭据
+	SecretsEncrypted bool       `json:"-" gorm:"default:false"`                         // 历史数据为明文，保存时再加密
+	Role             string     `json:"role" gorm:"size:50;not null"`                   // master/worker/etcd/registry
+	Status           string     `json:"status" gorm:"size:50;default:'available'"`
+	ClusterID        *uuid.UUID `json:"cluster_id,omitempty" gorm:"type:uuid;index"` // 由平台创建的集群，退役后清空
+	ArtifactPath     string     `json:"artifact_path" gorm:"type:text"`
+	ImageRepo        string     `json:"image_repo" gorm:"size:255"`
+	RegistryAddress  string     `json:"registry_address" gorm:"size:255"`
+	Labels           JSONMap    `json:"labels" gorm:"type:jsonb;default:'{}'"`
+	CreatedAt        time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
 // TableName 返回表名
 func (Machine) TableName() string {
 	return "machines"
 }
+
+// 机器状态
+const (
+	MachineStatusAvailable   = "available"
+	MachineStatusInUse       = "in-use"
+	MachineStatusDeploying   = "deploying"
+	MachineStatusMaintenance = "maintenance"
+	MachineStatusOffline     = "offline"
+)
+
+// 机器认证方式
+const (
+	MachineAuthTypePassword   = "password"
+	MachineAuthTypePrivateKey = "private_key"
+)
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MachineCredential 共享的机器SSH凭据
+// 可批量分配给多台机器，敏感字段均加密存储
+type MachineCredential struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Name        string    `json:"name" gorm:"size:255;not null;uniqueIndex"`
+	Description string    `json:"description" gorm:"type:text"`
+	Username    string    `json:"username" gorm:"size:100;not null"`
+	AuthType    string    `json:"auth_type" gorm:"size:20;not null;default:'password'"` // password/private_key
+	Password    string    `json:"-" gorm:"type:text"`
+	PrivateKey  string    `json:"-" gorm:"type:text"`
+	Passphrase  string    `json:"-" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 返回表名
+func (MachineCredential) TableName() string {
+	return "machine_credentials"
+}
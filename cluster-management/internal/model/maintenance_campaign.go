@@ -0,0 +1,59 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaintenanceCampaign 节点滚动维护活动
+// 按最大不可用数逐个节点执行封锁、排空、SSH命令、等待就绪与解除封锁，失败时暂停
+type MaintenanceCampaign struct {
+	ID                  uuid.UUID   `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ClusterID           uuid.UUID   `json:"cluster_id" gorm:"type:uuid;not null;index"`
+	Name                string      `json:"name" gorm:"size:255;not null"`
+	Selector            string      `json:"selector" gorm:"size:500"`
+	NodeNames           StringSlice `json:"node_names" gorm:"type:jsonb"` // 创建时解析出的目标节点，按执行顺序排列
+	MaxUnavailable      int         `json:"max_unavailable" gorm:"default:1"`
+	Commands            StringSlice `json:"commands" gorm:"type:jsonb"`
+	DrainOptions        JSONMap     `json:"drain_options" gorm:"type:jsonb"`
+	ReadyTimeoutSeconds int         `json:"ready_timeout_seconds" gorm:"default:900"`
+	Status              string      `json:"status" gorm:"size:50;default:'pending';index"`
+	CurrentStep         string      `json:"current_step" gorm:"size:255"`
+	ErrorMsg            string      `json:"error_msg" gorm:"type:text"`
+	CreatedBy           string      `json:"created_by" gorm:"size:100"`
+	StartedAt           *time.Time  `json:"started_at"`
+	CompletedAt         *time.Time  `json:"completed_at"`
+	CreatedAt           time.Time   `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time   `json:"updated_at" gorm:"autoUpdateTime"`
+
+	Nodes []MaintenanceNodeRun `json:"nodes,omitempty" gorm:"foreignKey:CampaignID"`
+}
+
+// TableName 返回表名
+func (MaintenanceCampaign) TableName() string {
+	return "maintenance_campaigns"
+}
+
+// MaintenanceNodeRun 维护活动中单个节点的执行记录
+type MaintenanceNodeRun struct {
+	ID               uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	CampaignID       uuid.UUID  `json:"campaign_id" gorm:"type:uuid;not null;index"`
+	NodeName         string     `json:"node_name" gorm:"size:255;not null"`
+	MachineID        uuid.UUID  `json:"machine_id" gorm:"type:uuid"`
+	Sequence         int        `json:"sequence"`
+	Status           string     `json:"status" gorm:"size:50;default:'pending'"`
+	Step             string     `json:"step" gorm:"size:50"`                    // drain/commands/wait_ready/uncordon
+	WasUnschedulable bool       `json:"was_unschedulable" gorm:"default:false"` // 维护前已被封锁的节点完成后保持封锁
+	Logs             string     `json:"logs" gorm:"type:text"`
+	ErrorMsg         string     `json:"error_msg" gorm:"type:text"`
+	StartedAt        *time.Time `json:"started_at"`
+	CompletedAt      *time.Time `json:"completed_at"`
+	CreatedAt        time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 返回表名
+func (MaintenanceNodeRun) TableName() string {
+	return "maintenance_node_runs"
+}
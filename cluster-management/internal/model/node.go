@@ -18,6 +18,15 @@ type Node struct {
 	MemoryUsedBytes int64     `json:"memory_used_bytes" gorm:"type:bigint;default:0"`
 	PodCount        int       `json:"pod_count" gorm:"type:integer;default:0"`
 	Labels          JSONMap   `json:"labels" gorm:"type:jsonb;default:'{}'::jsonb"`
+	Taints          StringSlice `json:"taints" gorm:"type:jsonb;default:'[]'::jsonb"` // key=value:effect
+	// 节点运行时信息，来自 node.status.nodeInfo
+	KubeletVersion          string `json:"kubelet_version" gorm:"type:varchar(50)"`
+	KubeProxyVersion        string `json:"kube_proxy_version" gorm:"type:varchar(50)"`
+	ContainerRuntime        string `json:"container_runtime" gorm:"type:varchar(50)"`
+	ContainerRuntimeVersion string `json:"container_runtime_version" gorm:"type:varchar(100)"`
+	OSImage                 string `json:"os_image" gorm:"type:varchar(255)"`
+	KernelVersion           string `json:"kernel_version" gorm:"type:varchar(255)"`
+	Architecture            string `json:"architecture" gorm:"type:varchar(50)"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
 
@@ -48,6 +57,14 @@ func (list NodeList) ToJSON() []map[string]interface{} {
 			"memory_usage_percent": calculateMemoryPercent(node.MemoryBytes, node.MemoryUsedBytes),
 			"pod_count":         node.PodCount,
 			"labels":            node.Labels,
+			"taints":            node.Taints,
+			"kubelet_version":   node.KubeletVersion,
+			"kube_proxy_version": node.KubeProxyVersion,
+			"container_runtime": node.ContainerRuntime,
+			"container_runtime_version": node.ContainerRuntimeVersion,
+			"os_image":          node.OSImage,
+			"kernel_version":    node.KernelVersion,
+			"architecture":      node.Architecture,
 			"created_at":        node.CreatedAt,
 			"updated_at":        node.UpdatedAt,
 		})
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NodeDrainTask 节点排空任务
+// 记录通过驱逐API迁移的Pod，以及因PDB或选项限制未能驱逐的Pod
+type NodeDrainTask struct {
+	ID          uuid.UUID   `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ClusterID   uuid.UUID   `json:"cluster_id" gorm:"type:uuid;not null;index"`
+	NodeName    string      `json:"node_name" gorm:"size:255;not null;index"`
+	Options     JSONMap     `json:"options" gorm:"type:jsonb"`
+	Status      string      `json:"status" gorm:"size:50;default:'pending'"`
+	CurrentStep string      `json:"current_step" gorm:"size:255"`
+	EvictedPods StringSlice `json:"evicted_pods" gorm:"type:jsonb"` // namespace/name
+	BlockedPods StringSlice `json:"blocked_pods" gorm:"type:jsonb"` // namespace/name: 原因
+	SkippedPods StringSlice `json:"skipped_pods" gorm:"type:jsonb"` // DaemonSet 与静态Pod
+	Logs        string      `json:"logs" gorm:"type:text"`
+	ErrorMsg    string      `json:"error_msg" gorm:"type:text"`
+	CreatedBy   string      `json:"created_by" gorm:"size:100"`
+	StartedAt   *time.Time  `json:"started_at"`
+	CompletedAt *time.Time  `json:"completed_at"`
+	CreatedAt   time.Time   `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time   `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 返回表名
+func (NodeDrainTask) TableName() string {
+	return "node_drain_tasks"
+}
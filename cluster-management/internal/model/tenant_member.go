@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 租户成员角色
+const (
+	TenantMemberRoleOwner  = "owner"
+	TenantMemberRoleMember = "member"
+	TenantMemberRoleViewer = "viewer"
+)
+
+// TenantMember 租户成员，访问集群 API 代理时据此推导模拟的用户组
+type TenantMember struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	TenantID  uuid.UUID `json:"tenant_id" gorm:"type:uuid;not null;uniqueIndex:idx_tenant_members_tenant_user"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_tenant_members_tenant_user;index"`
+	Role      string    `json:"role" gorm:"size:20;not null;default:'member'"` // owner/member/viewer
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (TenantMember) TableName() string {
+	return "tenant_members"
+}
+
+// TenantMembership 用户所属租户及角色
+type TenantMembership struct {
+	TenantID   uuid.UUID `json:"tenant_id"`
+	TenantName string    `json:"tenant_name"`
+	Role       string    `json:"role"`
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 用户 kubeconfig 凭据方式
+const (
+	// UserKubeconfigModeServiceAccount 在集群内创建 ServiceAccount，通过 TokenRequest 签发短期令牌直连 apiserver
+	UserKubeconfigModeServiceAccount = "service_account"
+	// UserKubeconfigModeProxy 指向平台 API 代理，使用平台签发的短期令牌
+	UserKubeconfigModeProxy = "proxy"
+)
+
+// 用户 kubeconfig 状态
+const (
+	UserKubeconfigStatusActive  = "active"
+	UserKubeconfigStatusRevoked = "revoked"
+	UserKubeconfigStatusExpired = "expired"
+)
+
+// UserKubeconfig 为用户签发的集群 kubeconfig，权限限定在所属租户环境的命名空间内
+type UserKubeconfig struct {
+	ID           uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ClusterID    uuid.UUID  `json:"cluster_id" gorm:"type:uuid;not null;index"`
+	UserID       uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	Username     string     `json:"username" gorm:"size:100;not null"`
+	TenantID     *uuid.UUID `json:"tenant_id,omitempty" gorm:"type:uuid"`
+	Mode         string     `json:"mode" gorm:"size:20;not null"`                    // service_account/proxy
+	Status       string     `json:"status" gorm:"size:20;not null;default:'active'"` // active/revoked/expired
+	Subject      string     `json:"subject" gorm:"size:255"`                         // RoleBinding 授权的主体，ServiceAccount 为 namespace/name
+	BindingName  string     `json:"binding_name" gorm:"size:255"`                    // 各命名空间内 RoleBinding 的名称
+	Namespaces   JSONMap    `json:"namespaces" gorm:"type:jsonb"`                    // 命名空间 -> 绑定的 ClusterRole
+	ExpiresAt    time.Time  `json:"expires_at" gorm:"not null;index"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy    string     `json:"revoked_by,omitempty" gorm:"size:100"`
+	CleanupError string     `json:"cleanup_error,omitempty" gorm:"type:text"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (UserKubeconfig) TableName() string {
+	return "user_kubeconfigs"
+}
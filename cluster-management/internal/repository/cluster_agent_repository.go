@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ClusterAgentRepository 集群代理数据访问
+type ClusterAgentRepository struct {
+	db *gorm.DB
+}
+
+// NewClusterAgentRepository 创建集群代理仓库
+func NewClusterAgentRepository(db *gorm.DB) *ClusterAgentRepository {
+	return &ClusterAgentRepository{db: db}
+}
+
+// GetByClusterID 获取集群的代理
+func (r *ClusterAgentRepository) GetByClusterID(clusterID uuid.UUID) (*model.ClusterAgent, error) {
+	var agent model.ClusterAgent
+	if err := r.db.Where("cluster_id = ?", clusterID).First(&agent).Error; err != nil {
+		return nil, err
+	}
+	return &agent, nil
+}
+
+// GetByTokenHash 按注册令牌摘要查找代理
+func (r *ClusterAgentRepository) GetByTokenHash(tokenHash string) (*model.ClusterAgent, error) {
+	var agent model.ClusterAgent
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&agent).Error; err != nil {
+		return nil, err
+	}
+	return &agent, nil
+}
+
+// SaveToken 保存新签发的注册令牌，已注册的代理密钥保持有效直到重新注册
+func (r *ClusterAgentRepository) SaveToken(agent *model.ClusterAgent) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cluster_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"token_hash", "token_expires_at", "updated_at"}),
+	}).Create(agent).Error
+}
+
+// Register 消费注册令牌并保存代理密钥，令牌已被使用时返回 gorm.ErrRecordNotFound
+func (r *ClusterAgentRepository) Register(clusterID uuid.UUID, tokenHash string, fields map[string]interface{}) error {
+	fields["token_hash"] = ""
+	fields["token_expires_at"] = nil
+	result := r.db.Model(&model.ClusterAgent{}).
+		Where("cluster_id = ? AND token_hash = ?", clusterID, tokenHash).
+		Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Update 更新集群代理的指定字段
+func (r *ClusterAgentRepository) Update(clusterID uuid.UUID, fields map[string]interface{}) error {
+	return r.db.Model(&model.ClusterAgent{}).Where("cluster_id = ?", clusterID).Updates(fields).Error
+}
+
+// Delete 删除集群代理，代理密钥随之失效
+func (r *ClusterAgentRepository) Delete(clusterID uuid.UUID) error {
+	return r.db.Where("cluster_id = ?", clusterID).Delete(&model.ClusterAgent{}).Error
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/constants"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"gorm.io/gorm"
+)
+
+// ClusterCertificateRepository 集群证书数据访问层
+type ClusterCertificateRepository struct {
+	db *gorm.DB
+}
+
+// NewClusterCertificateRepository 创建集群证书仓库
+func NewClusterCertificateRepository(db *gorm.DB) *ClusterCertificateRepository {
+	return &ClusterCertificateRepository{db: db}
+}
+
+// ListByClusterID 获取集群证书，按到期时间升序
+func (r *ClusterCertificateRepository) ListByClusterID(clusterID uuid.UUID) ([]*model.ClusterCertificate, error) {
+	var certificates []*model.ClusterCertificate
+	err := r.db.Where("cluster_id = ?", clusterID).Order("not_after ASC").Find(&certificates).Error
+	return certificates, err
+}
+
+// ListExpiringBefore 获取剩余天数不超过 days 的证书
+func (r *ClusterCertificateRepository) ListExpiringBefore(days int) ([]*model.ClusterCertificate, error) {
+	var certificates []*model.ClusterCertificate
+	err := r.db.Where("days_remaining <= ?", days).Order("not_after ASC").Find(&certificates).Error
+	return certificates, err
+}
+
+// ReplaceForCluster 在同一事务中替换集群的全部证书记录
+func (r *ClusterCertificateRepository) ReplaceForCluster(clusterID uuid.UUID, certificates []*model.ClusterCertificate) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("cluster_id = ?", clusterID).Delete(&model.ClusterCertificate{}).Error; err != nil {
+			return err
+		}
+		if len(certificates) == 0 {
+			return nil
+		}
+		return tx.Create(&certificates).Error
+	})
+}
+
+// CertificateRotationRepository 证书轮换任务数据访问层
+type CertificateRotationRepository struct {
+	db *gorm.DB
+}
+
+// NewCertificateRotationRepository 创建证书轮换任务仓库
+func NewCertificateRotationRepository(db *gorm.DB) *CertificateRotationRepository {
+	return &CertificateRotationRepository{db: db}
+}
+
+// Create 创建轮换任务
+func (r *CertificateRotationRepository) Create(rotation *model.CertificateRotation) error {
+	return r.db.Create(rotation).Error
+}
+
+// GetByID 根据ID获取轮换任务
+func (r *CertificateRotationRepository) GetByID(id uuid.UUID) (*model.CertificateRotation, error) {
+	var rotation model.CertificateRotation
+	if err := r.db.First(&rotation, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &rotation, nil
+}
+
+// ListByClusterID 获取集群的轮换任务，不返回日志
+func (r *CertificateRotationRepository) ListByClusterID(clusterID uuid.UUID) ([]*model.CertificateRotation, error) {
+	var rotations []*model.CertificateRotation
+	err := r.db.Omit("logs").Where("cluster_id = ?", clusterID).Order("created_at DESC").Find(&rotations).Error
+	return rotations, err
+}
+
+// ExistsActive 检查集群是否有未结束的轮换任务
+func (r *CertificateRotationRepository) ExistsActive(clusterID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.CertificateRotation{}).
+		Where("cluster_id = ? AND status IN ?", clusterID, []string{constants.StatusPending, constants.StatusRunning}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// UpdateFields 按字段更新轮换任务
+func (r *CertificateRotationRepository) UpdateFields(id uuid.UUID, fields map[string]interface{}) error {
+	return r.db.Model(&model.CertificateRotation{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// AppendLogs 追加日志
+func (r *CertificateRotationRepository) AppendLogs(id uuid.UUID, log string) error {
+	return r.db.Model(&model.CertificateRotation{}).
+		Where("id = ?", id).
+		Update("logs", gorm.Expr("COALESCE(logs, '') || ?", log)).Error
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ClusterConnectionRepository 集群连接设置数据访问
+type ClusterConnectionRepository struct {
+	db *gorm.DB
+}
+
+// NewClusterConnectionRepository 创建集群连接设置仓库
+func NewClusterConnectionRepository(db *gorm.DB) *ClusterConnectionRepository {
+	return &ClusterConnectionRepository{db: db}
+}
+
+// GetByClusterID 获取集群的连接设置
+func (r *ClusterConnectionRepository) GetByClusterID(clusterID uuid.UUID) (*model.ClusterConnection, error) {
+	var connection model.ClusterConnection
+	if err := r.db.Where("cluster_id = ?", clusterID).First(&connection).Error; err != nil {
+		return nil, err
+	}
+	return &connection, nil
+}
+
+// Save 按集群保存连接设置，已存在时整体覆盖
+func (r *ClusterConnectionRepository) Save(connection *model.ClusterConnection) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "cluster_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"mode", "bastion_host", "bastion_port", "bastion_credential_id", "bastion_user",
+			"bastion_password", "bastion_private_key", "bastion_passphrase", "bastion_host_key",
+			"proxy_url", "tls_server_name", "updated_at",
+		}),
+	}).Create(connection).Error
+}
+
+// Delete 删除集群的连接设置，恢复直连
+func (r *ClusterConnectionRepository) Delete(clusterID uuid.UUID) error {
+	return r.db.Where("cluster_id = ?", clusterID).Delete(&model.ClusterConnection{}).Error
+}
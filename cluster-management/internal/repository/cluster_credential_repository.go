@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrClusterCredentialChanged 更换凭据期间集群保存的凭据已被其他操作修改
+var ErrClusterCredentialChanged = errors.New("cluster credential was changed concurrently")
+
+// ClusterCredentialRepository 集群凭据历史数据访问
+type ClusterCredentialRepository struct {
+	db *gorm.DB
+}
+
+// NewClusterCredentialRepository 创建集群凭据历史仓库
+func NewClusterCredentialRepository(db *gorm.DB) *ClusterCredentialRepository {
+	return &ClusterCredentialRepository{db: db}
+}
+
+// List 获取集群的凭据历史，最新的在前
+func (r *ClusterCredentialRepository) List(clusterID uuid.UUID) ([]*model.ClusterCredential, error) {
+	var credentials []*model.ClusterCredential
+	err := r.db.Where("cluster_id = ?", clusterID).Order("created_at DESC").Find(&credentials).Error
+	return credentials, err
+}
+
+// GetByID 获取集群的一条历史凭据
+func (r *ClusterCredentialRepository) GetByID(clusterID, id uuid.UUID) (*model.ClusterCredential, error) {
+	var credential model.ClusterCredential
+	if err := r.db.Where("id = ? AND cluster_id = ?", id, clusterID).First(&credential).Error; err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+// Swap 在同一事务中替换集群保存的 kubeconfig 并写入凭据历史
+// 集群当前凭据与 expected 不一致时返回 ErrClusterCredentialChanged；尚无历史时先补记当前凭据，超出 keep 条的旧凭据被删除
+func (r *ClusterCredentialRepository) Swap(expected string, previous, credential *model.ClusterCredential, keep int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var cluster model.Cluster
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", credential.ClusterID).First(&cluster).Error; err != nil {
+			return err
+		}
+		if cluster.KubeconfigEncrypted != expected {
+			return ErrClusterCredentialChanged
+		}
+
+		var count int64
+		if err := tx.Model(&model.ClusterCredential{}).Where("cluster_id = ?", credential.ClusterID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 && previous != nil {
+			if err := tx.Create(previous).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&model.ClusterCredential{}).
+			Where("cluster_id = ? AND active = ?", credential.ClusterID, true).
+			Update("active", false).Error; err != nil {
+			return err
+		}
+		credential.Active = true
+		if err := tx.Create(credential).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Cluster{}).Where("id = ?", credential.ClusterID).
+			Update("kubeconfig_encrypted", credential.KubeconfigEncrypted).Error; err != nil {
+			return err
+		}
+
+		if keep > 0 {
+			return tx.Exec(`DELETE FROM cluster_credentials WHERE cluster_id = ? AND active = FALSE AND id NOT IN (
+				SELECT id FROM cluster_credentials WHERE cluster_id = ? ORDER BY created_at DESC LIMIT ?)`,
+				credential.ClusterID, credential.ClusterID, keep).Error
+		}
+		return nil
+	})
+}
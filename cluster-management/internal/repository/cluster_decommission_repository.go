@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/constants"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"gorm.io/gorm"
+)
+
+// ClusterDecommissionRepository 集群退役任务数据访问层
+type ClusterDecommissionRepository struct {
+	db *gorm.DB
+}
+
+// NewClusterDecommissionRepository 创建集群退役任务仓库
+func NewClusterDecommissionRepository(db *gorm.DB) *ClusterDecommissionRepository {
+	return &ClusterDecommissionRepository{db: db}
+}
+
+// Create 创建退役任务
+// 集群已有未结束的退役任务时违反唯一索引，返回 gorm.ErrDuplicatedKey
+func (r *ClusterDecommissionRepository) Create(decommission *model.ClusterDecommission) error {
+	err := r.db.Create(decommission).Error
+	if translator, ok := r.db.Dialector.(gorm.ErrorTranslator); ok && err != nil {
+		err = translator.Translate(err)
+	}
+	return err
+}
+
+// GetByID 根据ID获取退役任务
+func (r *ClusterDecommissionRepository) GetByID(id uuid.UUID) (*model.ClusterDecommission, error) {
+	var decommission model.ClusterDecommission
+	if err := r.db.First(&decommission, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &decommission, nil
+}
+
+// GetLatestByClusterID 获取集群最近一次退役任务
+func (r *ClusterDecommissionRepository) GetLatestByClusterID(clusterID uuid.UUID) (*model.ClusterDecommission, error) {
+	var decommission model.ClusterDecommission
+	if err := r.db.Where("cluster_id = ?", clusterID).Order("created_at DESC").First(&decommission).Error; err != nil {
+		return nil, err
+	}
+	return &decommission, nil
+}
+
+// ExistsActive 检查集群是否有未结束的退役任务
+func (r *ClusterDecommissionRepository) ExistsActive(clusterID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.ClusterDecommission{}).
+		Where("cluster_id = ? AND status IN ?", clusterID, []string{constants.StatusPending, constants.StatusRunning}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// List 获取退役任务列表
+func (r *ClusterDecommissionRepository) List(page, limit int) ([]*model.ClusterDecommission, int64, error) {
+	var decommissions []*model.ClusterDecommission
+	var total int64
+
+	query := r.db.Model(&model.ClusterDecommission{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	if err := query.Omit("logs").Offset(offset).Limit(limit).Order("created_at DESC").Find(&decommissions).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return decommissions, total, nil
+}
+
+// MarkInterrupted 将服务重启时未结束的退役任务标记为失败，返回更新数量
+// 任务结束后不再占用集群的唯一索引，可重新发起退役
+func (r *ClusterDecommissionRepository) MarkInterrupted() (int64, error) {
+	now := time.Now()
+	result := r.db.Model(&model.ClusterDecommission{}).
+		Where("status IN ?", []string{constants.StatusPending, constants.StatusRunning}).
+		Updates(map[string]interface{}{
+			"status":       constants.StatusFailed,
+			"current_step": "Interrupted by service restart, decommission can be started again",
+			"error_msg":    "decommission interrupted by service restart",
+			"completed_at": &now,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// UpdateFields 按字段更新退役任务
+func (r *ClusterDecommissionRepository) UpdateFields(id uuid.UUID, fields map[string]interface{}) error {
+	return r.db.Model(&model.ClusterDecommission{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// AppendLogs 追加日志
+func (r *ClusterDecommissionRepository) AppendLogs(id uuid.UUID, log string) error {
+	return r.db.Model(&model.ClusterDecommission{}).
+		Where("id = ?", id).
+		Update("logs", gorm.Expr("COALESCE(logs, '') || ?", log)).Error
+}
+
+// ClusterArchiveRepository 集群归档数据访问层
+type ClusterArchiveRepository struct {
+	db *gorm.DB
+}
+
+// NewClusterArchiveRepository 创建集群归档仓库
+func NewClusterArchiveRepository(db *gorm.DB) *ClusterArchiveRepository {
+	return &ClusterArchiveRepository{db: db}
+}
+
+// GetByID 根据ID获取归档
+func (r *ClusterArchiveRepository) GetByID(id uuid.UUID) (*model.ClusterArchive, error) {
+	var archive model.ClusterArchive
+	if err := r.db.First(&archive, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &archive, nil
+}
+
+// List 获取归档列表，不加载快照内容
+func (r *ClusterArchiveRepository) List(page, limit int, name string) ([]*model.ClusterArchive, int64, error) {
+	var archives []*model.ClusterArchive
+	var total int64
+
+	query := r.db.Model(&model.ClusterArchive{})
+	if name != "" {
+		query = query.Where("cluster_name ILIKE ?", "%"+name+"%")
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	if err := query.Omit("snapshot").Offset(offset).Limit(limit).Order("archived_at DESC").Find(&archives).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return archives, total, nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/constants"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"github.com/taichu-system/cluster-management/internal/testutil"
+)
+
+func TestClusterDecommissionMarkInterrupted(t *testing.T) {
+	repo := NewClusterDecommissionRepository(testutil.OpenDB(t, &model.ClusterDecommission{}))
+
+	statuses := []string{constants.StatusPending, constants.StatusRunning, constants.StatusSuccess, constants.StatusFailed}
+	ids := make(map[string]uuid.UUID, len(statuses))
+	for _, status := range statuses {
+		decommission := &model.ClusterDecommission{
+			ID:          uuid.New(),
+			ClusterID:   uuid.New(),
+			ClusterName: "cluster-" + status,
+			Status:      status,
+			CurrentStep: "step before restart",
+		}
+		if err := repo.Create(decommission); err != nil {
+			t.Fatalf("failed to create %s decommission: %v", status, err)
+		}
+		ids[status] = decommission.ID
+	}
+
+	n, err := repo.MarkInterrupted()
+	if err != nil {
+		t.Fatalf("MarkInterrupted failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("MarkInterrupted updated %d tasks, want 2", n)
+	}
+
+	for _, status := range []string{constants.StatusPending, constants.StatusRunning} {
+		decommission, err := repo.GetByID(ids[status])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decommission.Status != constants.StatusFailed {
+			t.Errorf("%s task status = %q, want %q", status, decommission.Status, constants.StatusFailed)
+		}
+		if decommission.CompletedAt == nil || decommission.ErrorMsg == "" {
+			t.Errorf("%s task should record completion time and error, got %+v", status, decommission)
+		}
+		active, err := repo.ExistsActive(decommission.ClusterID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if active {
+			t.Errorf("cluster of interrupted %s task still has an active decommission", status)
+		}
+	}
+
+	for _, status := range []string{constants.StatusSuccess, constants.StatusFailed} {
+		decommission, err := repo.GetByID(ids[status])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decommission.Status != status || decommission.CurrentStep != "step before restart" {
+			t.Errorf("finished %s task was modified: status %q, step %q", status, decommission.Status, decommission.CurrentStep)
+		}
+	}
+}
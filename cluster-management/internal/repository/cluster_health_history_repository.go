@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ClusterHealthHistoryRepository 集群健康历史数据访问
+type ClusterHealthHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewClusterHealthHistoryRepository 创建集群健康历史仓库
+func NewClusterHealthHistoryRepository(db *gorm.DB) *ClusterHealthHistoryRepository {
+	return &ClusterHealthHistoryRepository{db: db}
+}
+
+// Record 记录一次健康检查结果，状态变化时关闭当前区间并开启新区间
+func (r *ClusterHealthHistoryRepository) Record(clusterID uuid.UUID, status, message string, checkedAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current model.ClusterHealthPeriod
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("cluster_id = ? AND ended_at IS NULL", clusterID).
+			Order("started_at DESC").
+			First(&current).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		if err == nil {
+			// 乱序到达的旧检查结果不影响区间
+			if checkedAt.Before(current.LastCheckedAt) {
+				return nil
+			}
+			if current.Status == status {
+				return tx.Model(&model.ClusterHealthPeriod{}).Where("id = ?", current.ID).Updates(map[string]interface{}{
+					"message":         message,
+					"last_checked_at": checkedAt,
+					"check_count":     gorm.Expr("check_count + 1"),
+				}).Error
+			}
+			if err := tx.Model(&model.ClusterHealthPeriod{}).Where("id = ?", current.ID).Updates(map[string]interface{}{
+				"ended_at":         checkedAt,
+				"duration_seconds": int64(checkedAt.Sub(current.StartedAt).Seconds()),
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&model.ClusterHealthPeriod{
+			ClusterID:     clusterID,
+			Status:        status,
+			Message:       message,
+			StartedAt:     checkedAt,
+			LastCheckedAt: checkedAt,
+			CheckCount:    1,
+		}).Error
+	})
+}
+
+// ListPeriods 获取与时间窗口有交集的状态区间，按开始时间升序
+func (r *ClusterHealthHistoryRepository) ListPeriods(clusterID uuid.UUID, start, end time.Time) ([]*model.ClusterHealthPeriod, error) {
+	var periods []*model.ClusterHealthPeriod
+	err := r.db.Where("cluster_id = ? AND started_at < ? AND (ended_at IS NULL OR ended_at > ?)", clusterID, end, start).
+		Order("started_at ASC").
+		Find(&periods).Error
+	return periods, err
+}
+
+// ListDaily 获取日期范围内的降采样数据，包含 startDay 与 endDay
+func (r *ClusterHealthHistoryRepository) ListDaily(clusterID uuid.UUID, startDay, endDay time.Time) ([]*model.ClusterHealthDaily, error) {
+	var rows []*model.ClusterHealthDaily
+	err := r.db.Where("cluster_id = ? AND day >= ? AND day <= ?", clusterID, startDay, endDay).
+		Order("day ASC").
+		Find(&rows).Error
+	return rows, err
+}
+
+// ListClosedBefore 获取在 cutoff 之前结束的区间，用于降采样
+func (r *ClusterHealthHistoryRepository) ListClosedBefore(cutoff time.Time, limit int) ([]*model.ClusterHealthPeriod, error) {
+	var periods []*model.ClusterHealthPeriod
+	err := r.db.Where("ended_at IS NOT NULL AND ended_at <= ?", cutoff).
+		Order("ended_at ASC").
+		Limit(limit).
+		Find(&periods).Error
+	return periods, err
+}
+
+// ApplyDaily 累加降采样数据并删除已折叠的原始区间，两者在同一事务中完成
+func (r *ClusterHealthHistoryRepository) ApplyDaily(rows []*model.ClusterHealthDaily, foldedPeriodIDs []uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if len(rows) > 0 {
+			err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "cluster_id"}, {Name: "day"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"healthy_seconds":      gorm.Expr("cluster_health_daily.healthy_seconds + EXCLUDED.healthy_seconds"),
+					"degraded_seconds":     gorm.Expr("cluster_health_daily.degraded_seconds + EXCLUDED.degraded_seconds"),
+					"unhealthy_seconds":    gorm.Expr("cluster_health_daily.unhealthy_seconds + EXCLUDED.unhealthy_seconds"),
+					"disconnected_seconds": gorm.Expr("cluster_health_daily.disconnected_seconds + EXCLUDED.disconnected_seconds"),
+					"incident_count":       gorm.Expr("cluster_health_daily.incident_count + EXCLUDED.incident_count"),
+					"updated_at":           time.Now(),
+				}),
+			}).Create(&rows).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(foldedPeriodIDs) == 0 {
+			return nil
+		}
+		return tx.Where("id IN ?", foldedPeriodIDs).Delete(&model.ClusterHealthPeriod{}).Error
+	})
+}
+
+// DeleteDailyBefore 删除早于指定日期的降采样数据
+func (r *ClusterHealthHistoryRepository) DeleteDailyBefore(day time.Time) (int64, error) {
+	result := r.db.Where("day < ?", day).Delete(&model.ClusterHealthDaily{})
+	return result.RowsAffected, result.Error
+}
@@ -54,6 +54,43 @@ func (r *ClusterRepository) Update(cluster *model.Cluster) error {
 	return r.db.Save(cluster).Error
 }
 
+// UpdateKubeconfig 只更新集群保存的加密 kubeconfig，避免覆盖其他字段的并发修改
+func (r *ClusterRepository) UpdateKubeconfig(id string, kubeconfigEncrypted string) error {
+	return r.db.Model(&model.Cluster{}).Where("id = ?", id).Update("kubeconfig_encrypted", kubeconfigEncrypted).Error
+}
+
+// UpdateFingerprint 记录集群的 kube-system 命名空间 UID、根 CA 哈希与指纹
+func (r *ClusterRepository) UpdateFingerprint(id string, clusterUID, caHash, fingerprint string) error {
+	return r.db.Model(&model.Cluster{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"cluster_uid": clusterUID,
+		"ca_hash":     caHash,
+		"fingerprint": fingerprint,
+	}).Error
+}
+
+// FindDuplicates 查找 kube-system 命名空间 UID 相同且根 CA 哈希一致的其他集群，任一方 CA 哈希未知时只按 UID 匹配
+func (r *ClusterRepository) FindDuplicates(clusterUID, caHash string, excludeID string) ([]*model.Cluster, error) {
+	var clusters []*model.Cluster
+	query := r.db.Where("id <> ? AND cluster_uid = ?", excludeID, clusterUID)
+	if caHash != "" {
+		query = query.Where("(ca_hash = ? OR COALESCE(ca_hash, '') = '')", caHash)
+	}
+	err := query.Order("created_at ASC").Find(&clusters).Error
+	return clusters, err
+}
+
+// FindWithoutFingerprint 查找尚未记录指纹的集群
+func (r *ClusterRepository) FindWithoutFingerprint() ([]*model.Cluster, error) {
+	var clusters []*model.Cluster
+	err := r.db.Where("COALESCE(fingerprint, '') = ''").Find(&clusters).Error
+	return clusters, err
+}
+
+// UpdateLabels 只更新集群标签
+func (r *ClusterRepository) UpdateLabels(id string, labels model.JSONMap) error {
+	return r.db.Model(&model.Cluster{}).Where("id = ?", id).Update("labels", labels).Error
+}
+
 func (r *ClusterRepository) Delete(id string) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		cluster, err := r.GetByID(id)
@@ -61,36 +98,58 @@ func (r *ClusterRepository) Delete(id string) error {
 			return err
 		}
 
-		if err := tx.Exec("DELETE FROM cluster_states WHERE cluster_id = ?", id).Error; err != nil {
-			return err
-		}
+		return deleteClusterRecords(tx, cluster)
+	})
+}
 
-		if err := tx.Exec("DELETE FROM cluster_resources WHERE cluster_id = ?", id).Error; err != nil {
+// ArchiveAndDelete 在同一事务中写入归档快照并删除集群相关记录
+func (r *ClusterRepository) ArchiveAndDelete(id string, archive *model.ClusterArchive) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		cluster, err := r.GetByID(id)
+		if err != nil {
 			return err
 		}
 
-		if err := tx.Exec("DELETE FROM applications WHERE environment_id IN (SELECT id FROM environments WHERE cluster_id = ?)", id).Error; err != nil {
+		if err := tx.Create(archive).Error; err != nil {
 			return err
 		}
 
-		if err := tx.Exec("DELETE FROM application_resource_specs WHERE application_id IN (SELECT id FROM applications WHERE environment_id IN (SELECT id FROM environments WHERE cluster_id = ?))", id).Error; err != nil {
-			return err
-		}
+		return deleteClusterRecords(tx, cluster)
+	})
+}
 
-		if err := tx.Exec("DELETE FROM resource_quotas WHERE environment_id IN (SELECT id FROM environments WHERE cluster_id = ?)", id).Error; err != nil {
-			return err
-		}
+// deleteClusterRecords 删除集群及其关联记录
+func deleteClusterRecords(tx *gorm.DB, cluster *model.Cluster) error {
+	id := cluster.ID.String()
+	if err := tx.Exec("DELETE FROM cluster_states WHERE cluster_id = ?", id).Error; err != nil {
+		return err
+	}
 
-		if err := tx.Exec("DELETE FROM environments WHERE cluster_id = ?", id).Error; err != nil {
-			return err
-		}
+	if err := tx.Exec("DELETE FROM cluster_resources WHERE cluster_id = ?", id).Error; err != nil {
+		return err
+	}
 
-		if err := tx.Unscoped().Delete(cluster).Error; err != nil {
-			return err
-		}
+	if err := tx.Exec("DELETE FROM applications WHERE environment_id IN (SELECT id FROM environments WHERE cluster_id = ?)", id).Error; err != nil {
+		return err
+	}
 
-		return nil
-	})
+	if err := tx.Exec("DELETE FROM application_resource_specs WHERE application_id IN (SELECT id FROM applications WHERE environment_id IN (SELECT id FROM environments WHERE cluster_id = ?))", id).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Exec("DELETE FROM resource_quotas WHERE environment_id IN (SELECT id FROM environments WHERE cluster_id = ?)", id).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Exec("DELETE FROM environments WHERE cluster_id = ?", id).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Unscoped().Delete(cluster).Error; err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func (r *ClusterRepository) List(params ListClustersParams) ([]*model.ClusterWithState, int64, error) {
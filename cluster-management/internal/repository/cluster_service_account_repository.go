@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ClusterServiceAccountRepository 集群管理 ServiceAccount 数据访问
+type ClusterServiceAccountRepository struct {
+	db *gorm.DB
+}
+
+// NewClusterServiceAccountRepository 创建集群管理 ServiceAccount 仓库
+func NewClusterServiceAccountRepository(db *gorm.DB) *ClusterServiceAccountRepository {
+	return &ClusterServiceAccountRepository{db: db}
+}
+
+// GetByClusterID 获取集群的管理 ServiceAccount
+func (r *ClusterServiceAccountRepository) GetByClusterID(clusterID uuid.UUID) (*model.ClusterServiceAccount, error) {
+	var account model.ClusterServiceAccount
+	if err := r.db.Where("cluster_id = ?", clusterID).First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// Save 保存集群的管理 ServiceAccount，重新导入时覆盖
+func (r *ClusterServiceAccountRepository) Save(account *model.ClusterServiceAccount) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "cluster_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"namespace", "name", "cluster_role", "token_expires_at", "last_rotated_at",
+			"last_rotation_error", "last_rotation_failed_at", "updated_at",
+		}),
+	}).Create(account).Error
+}
+
+// ListDue 获取令牌在 before 之前过期、需要轮换的记录
+func (r *ClusterServiceAccountRepository) ListDue(before time.Time) ([]*model.ClusterServiceAccount, error) {
+	var accounts []*model.ClusterServiceAccount
+	err := r.db.Where("token_expires_at <= ?", before).Order("token_expires_at").Find(&accounts).Error
+	return accounts, err
+}
+
+// UpdateFields 更新指定字段
+func (r *ClusterServiceAccountRepository) UpdateFields(clusterID uuid.UUID, fields map[string]interface{}) error {
+	return r.db.Model(&model.ClusterServiceAccount{}).Where("cluster_id = ?", clusterID).Updates(fields).Error
+}
+
+// Delete 删除集群的管理 ServiceAccount 记录
+func (r *ClusterServiceAccountRepository) Delete(clusterID uuid.UUID) error {
+	return r.db.Where("cluster_id = ?", clusterID).Delete(&model.ClusterServiceAccount{}).Error
+}
@@ -47,11 +47,18 @@ func (r *ClusterStateRepository) Upsert(state *model.ClusterState) error {
 				"last_sync_at":         state.LastSyncAt,
 				"sync_error":           state.SyncError,
 				"sync_success":         state.SyncSuccess,
+				"details":              state.Details,
 			}).Error
 	}
 	return r.db.Create(state).Error
 }
 
+// UpdateFields 更新集群状态的指定字段，返回是否存在状态记录
+func (r *ClusterStateRepository) UpdateFields(clusterID string, fields map[string]interface{}) (bool, error) {
+	result := r.db.Model(&model.ClusterState{}).Where("cluster_id = ?", clusterID).Updates(fields)
+	return result.RowsAffected > 0, result.Error
+}
+
 func (r *ClusterStateRepository) Update(state *model.ClusterState) error {
 	return r.db.Save(state).Error
 }
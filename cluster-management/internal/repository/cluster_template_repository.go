@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"gorm.io/gorm"
+)
+
+// ClusterTemplateRepository 集群模板数据访问层
+type ClusterTemplateRepository struct {
+	db *gorm.DB
+}
+
+// NewClusterTemplateRepository 创建集群模板仓库
+func NewClusterTemplateRepository(db *gorm.DB) *ClusterTemplateRepository {
+	return &ClusterTemplateRepository{db: db}
+}
+
+// Create 创建模板版本
+func (r *ClusterTemplateRepository) Create(template *model.ClusterTemplate) error {
+	return r.db.Create(template).Error
+}
+
+// GetByID 根据ID获取模板版本
+func (r *ClusterTemplateRepository) GetByID(id uuid.UUID) (*model.ClusterTemplate, error) {
+	var template model.ClusterTemplate
+	if err := r.db.First(&template, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// GetByNameAndVersion 获取指定版本
+func (r *ClusterTemplateRepository) GetByNameAndVersion(name string, version int) (*model.ClusterTemplate, error) {
+	var template model.ClusterTemplate
+	if err := r.db.First(&template, "name = ? AND version = ?", name, version).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// GetLatestByName 获取模板最新版本
+func (r *ClusterTemplateRepository) GetLatestByName(name string) (*model.ClusterTemplate, error) {
+	var template model.ClusterTemplate
+	if err := r.db.Where("name = ?", name).Order("version DESC").First(&template).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// ExistsByName 检查模板名称是否存在
+func (r *ClusterTemplateRepository) ExistsByName(name string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.ClusterTemplate{}).Where("name = ?", name).Count(&count).Error
+	return count > 0, err
+}
+
+// ListLatest 获取每个模板的最新版本
+func (r *ClusterTemplateRepository) ListLatest() ([]*model.ClusterTemplate, error) {
+	var templates []*model.ClusterTemplate
+	err := r.db.Where("(name, version) IN (?)",
+		r.db.Model(&model.ClusterTemplate{}).Select("name, MAX(version)").Group("name"),
+	).Order("name").Find(&templates).Error
+	if err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// ListVersions 获取模板全部版本
+func (r *ClusterTemplateRepository) ListVersions(name string) ([]*model.ClusterTemplate, error) {
+	var templates []*model.ClusterTemplate
+	if err := r.db.Where("name = ?", name).Order("version DESC").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// Delete 删除模板版本
+func (r *ClusterTemplateRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&model.ClusterTemplate{}, "id = ?", id).Error
+}
+
+// GetDB 获取数据库连接
+func (r *ClusterTemplateRepository) GetDB() *gorm.DB {
+	return r.db
+}
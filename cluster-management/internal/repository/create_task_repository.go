@@ -1,12 +1,17 @@
 package repository
 
 import (
+	"errors"
+
 	"github.com/google/uuid"
 	"github.com/taichu-system/cluster-management/internal/constants"
 	"github.com/taichu-system/cluster-management/internal/model"
 	"gorm.io/gorm"
 )
 
+// ErrMachinesUnavailable 部分机器已被其他任务占用或不处于可用状态
+var ErrMachinesUnavailable = errors.New("some machines are no longer available")
+
 // CreateTaskRepository 创建任务数据访问层
 type CreateTaskRepository struct {
 	db *gorm.DB
@@ -22,6 +27,31 @@ func (r *CreateTaskRepository) Create(task *model.CreateTask) error {
 	return r.db.Create(task).Error
 }
 
+// CreateReservingMachines 在同一事务中创建任务并将机器从可用状态改为部署中
+// 只更新仍处于可用状态的机器，更新数量不足时回滚并返回 ErrMachinesUnavailable，避免并发任务占用同一批机器
+func (r *CreateTaskRepository) CreateReservingMachines(task *model.CreateTask, machineIDs []uuid.UUID) error {
+	unique := make(map[uuid.UUID]bool, len(machineIDs))
+	for _, id := range machineIDs {
+		unique[id] = true
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(task).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&model.Machine{}).
+			Where("id IN ? AND status = ?", machineIDs, model.MachineStatusAvailable).
+			Update("status", model.MachineStatusDeploying)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected < int64(len(unique)) {
+			return ErrMachinesUnavailable
+		}
+		return nil
+	})
+}
+
 // GetByID 根据ID获取任务
 func (r *CreateTaskRepository) GetByID(id uuid.UUID) (*model.CreateTask, error) {
 	var task model.CreateTask
@@ -55,7 +85,12 @@ func (r *CreateTaskRepository) UpdateProgress(id uuid.UUID, progress int, curren
 func (r *CreateTaskRepository) AppendLogs(id uuid.UUID, log string) error {
 	return r.db.Model(&model.CreateTask{}).
 		Where("id = ?", id).
-		Update("logs", gorm.Expr("logs || ?", log)).Error
+		Update("logs", gorm.Expr("COALESCE(logs, '') || ?", log)).Error
+}
+
+// UpdateFields 按字段更新任务，避免整行保存覆盖并发写入的日志与进度
+func (r *CreateTaskRepository) UpdateFields(id uuid.UUID, fields map[string]interface{}) error {
+	return r.db.Model(&model.CreateTask{}).Where("id = ?", id).Updates(fields).Error
 }
 
 // List 获取任务列表
@@ -79,6 +114,15 @@ func (r *CreateTaskRepository) List(page, limit int) ([]*model.CreateTask, int64
 	return tasks, total, nil
 }
 
+// GetLatestByClusterID 获取创建指定集群的最近一次任务
+func (r *CreateTaskRepository) GetLatestByClusterID(clusterID uuid.UUID) (*model.CreateTask, error) {
+	var task model.CreateTask
+	if err := r.db.Where("cluster_id = ?", clusterID).Order("created_at DESC").First(&task).Error; err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
 // GetByStatus 根据状态获取任务
 func (r *CreateTaskRepository) GetByStatus(status string) ([]*model.CreateTask, error) {
 	var tasks []*model.CreateTask
@@ -94,3 +94,24 @@ func (r *EventRepository) CheckExists(clusterID string, eventType, message, comp
 		Count(&count).Error
 	return count > 0, err
 }
+
+// ListByUIDs 按 Kubernetes Event UID 获取集群的事件记录
+func (r *EventRepository) ListByUIDs(clusterID string, uids []string) ([]model.Event, error) {
+	var events []model.Event
+	if len(uids) == 0 {
+		return events, nil
+	}
+	err := r.db.Where("cluster_id = ? AND event_uid IN ?", clusterID, uids).Find(&events).Error
+	return events, err
+}
+
+// ListWithoutUID 获取尚未记录 Event UID 的历史事件，按首次发生时间过滤
+func (r *EventRepository) ListWithoutUID(clusterID string, firstTimestamps []time.Time) ([]model.Event, error) {
+	var events []model.Event
+	if len(firstTimestamps) == 0 {
+		return events, nil
+	}
+	err := r.db.Where("cluster_id = ? AND (event_uid IS NULL OR event_uid = '') AND first_timestamp IN ?", clusterID, firstTimestamps).
+		Find(&events).Error
+	return events, err
+}
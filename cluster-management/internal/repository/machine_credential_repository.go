@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"gorm.io/gorm"
+)
+
+// MachineCredentialRepository 机器凭据数据访问层
+type MachineCredentialRepository struct {
+	db *gorm.DB
+}
+
+// NewMachineCredentialRepository 创建机器凭据仓库
+func NewMachineCredentialRepository(db *gorm.DB) *MachineCredentialRepository {
+	return &MachineCredentialRepository{db: db}
+}
+
+// Create 创建凭据
+func (r *MachineCredentialRepository) Create(credential *model.MachineCredential) error {
+	return r.db.Create(credential).Error
+}
+
+// GetByID 根据ID获取凭据
+func (r *MachineCredentialRepository) GetByID(id uuid.UUID) (*model.MachineCredential, error) {
+	var credential model.MachineCredential
+	if err := r.db.First(&credential, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+// GetByIDs 根据ID列表批量获取凭据
+func (r *MachineCredentialRepository) GetByIDs(ids []uuid.UUID) ([]*model.MachineCredential, error) {
+	var credentials []*model.MachineCredential
+	if err := r.db.Where("id IN ?", ids).Find(&credentials).Error; err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
+// ExistsByName 检查凭据名称是否存在
+func (r *MachineCredentialRepository) ExistsByName(name string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.MachineCredential{}).Where("name = ?", name).Count(&count).Error
+	return count > 0, err
+}
+
+// List 获取凭据列表
+func (r *MachineCredentialRepository) List() ([]*model.MachineCredential, error) {
+	var credentials []*model.MachineCredential
+	if err := r.db.Order("created_at DESC").Find(&credentials).Error; err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
+// Update 更新凭据
+func (r *MachineCredentialRepository) Update(credential *model.MachineCredential) error {
+	return r.db.Save(credential).Error
+}
+
+// Delete 删除凭据
+func (r *MachineCredentialRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&model.MachineCredential{}, "id = ?", id).Error
+}
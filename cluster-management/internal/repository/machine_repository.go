@@ -106,3 +106,85 @@ func (r *MachineRepository) GetByRole(role string) ([]*model.Machine, error) {
 	}
 	return machines, nil
 }
+
+// AssignCredential 批量为机器分配共享凭据
+func (r *MachineRepository) AssignCredential(ids []uuid.UUID, credentialID *uuid.UUID) (int64, error) {
+	result := r.db.Model(&model.Machine{}).Where("id IN ?", ids).Update("credential_id", credentialID)
+	return result.RowsAffected, result.Error
+}
+
+// ClearCredential 批量解除机器与指定共享凭据的关联
+func (r *MachineRepository) ClearCredential(ids []uuid.UUID, credentialID uuid.UUID) (int64, error) {
+	result := r.db.Model(&model.Machine{}).
+		Where("id IN ? AND credential_id = ?", ids, credentialID).
+		Update("credential_id", nil)
+	return result.RowsAffected, result.Error
+}
+
+// CountByCredential 统计使用指定凭据的机器数量
+func (r *MachineRepository) CountByCredential(credentialID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Machine{}).Where("credential_id = ?", credentialID).Count(&count).Error
+	return count, err
+}
+
+// ListPlaintextSecrets 获取凭据尚未加密的机器
+func (r *MachineRepository) ListPlaintextSecrets() ([]*model.Machine, error) {
+	var machines []*model.Machine
+	if err := r.db.Where("secrets_encrypted = ? OR secrets_encrypted IS NULL", false).Find(&machines).Error; err != nil {
+		return nil, err
+	}
+	return machines, nil
+}
+
+// UpdateSecretsIfPlaintext 写入加密后的凭据，仅在机器凭据仍为明文时更新，返回是否更新
+func (r *MachineRepository) UpdateSecretsIfPlaintext(machine *model.Machine) (bool, error) {
+	result := r.db.Model(&model.Machine{}).
+		Where("id = ? AND (secrets_encrypted = ? OR secrets_encrypted IS NULL)", machine.ID, false).
+		Updates(map[string]interface{}{
+			"password":          machine.Password,
+			"private_key":       machine.PrivateKey,
+			"passphrase":        machine.Passphrase,
+			"secrets_encrypted": true,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// UpdateStatusByIDs 批量更新机器状态
+func (r *MachineRepository) UpdateStatusByIDs(ids []uuid.UUID, status string) error {
+	return r.db.Model(&model.Machine{}).Where("id IN ?", ids).Update("status", status).Error
+}
+
+// BindCluster 将机器关联到集群并标记为使用中
+func (r *MachineRepository) BindCluster(ids []uuid.UUID, clusterID uuid.UUID) error {
+	return r.db.Model(&model.Machine{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+		"cluster_id": clusterID,
+		"status":     model.MachineStatusInUse,
+	}).Error
+}
+
+// GetByClusterID 获取集群使用的机器
+func (r *MachineRepository) GetByClusterID(clusterID uuid.UUID) ([]*model.Machine, error) {
+	var machines []*model.Machine
+	if err := r.db.Where("cluster_id = ?", clusterID).Order("role, name").Find(&machines).Error; err != nil {
+		return nil, err
+	}
+	return machines, nil
+}
+
+// ReleaseByIDs 解除机器与集群的关联并放回可用池
+func (r *MachineRepository) ReleaseByIDs(ids []uuid.UUID) (int64, error) {
+	result := r.db.Model(&model.Machine{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+		"cluster_id": nil,
+		"status":     model.MachineStatusAvailable,
+	})
+	return result.RowsAffected, result.Error
+}
+
+// GetByAddresses 根据名称或地址查找机器，用于将集群节点对应到机器
+func (r *MachineRepository) GetByAddresses(names, addresses []string) ([]*model.Machine, error) {
+	var machines []*model.Machine
+	err := r.db.Where("name IN ? OR ip_address IN ? OR internal_address IN ?", names, addresses, addresses).
+		Find(&machines).Error
+	return machines, err
+}
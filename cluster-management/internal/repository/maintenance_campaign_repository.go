@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/constants"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"gorm.io/gorm"
+)
+
+// MaintenanceCampaignRepository 节点维护活动数据访问层
+type MaintenanceCampaignRepository struct {
+	db *gorm.DB
+}
+
+// NewMaintenanceCampaignRepository 创建节点维护活动仓库
+func NewMaintenanceCampaignRepository(db *gorm.DB) *MaintenanceCampaignRepository {
+	return &MaintenanceCampaignRepository{db: db}
+}
+
+// Create 在同一事务中创建维护活动及其节点记录
+func (r *MaintenanceCampaignRepository) Create(campaign *model.MaintenanceCampaign, runs []*model.MaintenanceNodeRun) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Nodes").Create(campaign).Error; err != nil {
+			return err
+		}
+		for _, run := range runs {
+			run.CampaignID = campaign.ID
+		}
+		if len(runs) == 0 {
+			return nil
+		}
+		return tx.Create(&runs).Error
+	})
+}
+
+// GetByID 根据ID获取维护活动，包含节点执行记录
+func (r *MaintenanceCampaignRepository) GetByID(id uuid.UUID) (*model.MaintenanceCampaign, error) {
+	var campaign model.MaintenanceCampaign
+	err := r.db.Preload("Nodes", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sequence")
+	}).First(&campaign, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+// List 获取维护活动列表，clusterID 为空时返回全部
+func (r *MaintenanceCampaignRepository) List(clusterID *uuid.UUID) ([]*model.MaintenanceCampaign, error) {
+	var campaigns []*model.MaintenanceCampaign
+	query := r.db.Order("created_at DESC")
+	if clusterID != nil {
+		query = query.Where("cluster_id = ?", *clusterID)
+	}
+	err := query.Find(&campaigns).Error
+	return campaigns, err
+}
+
+// ExistsActive 检查集群是否有未结束的维护活动，暂停的活动同样占用集群
+func (r *MaintenanceCampaignRepository) ExistsActive(clusterID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.MaintenanceCampaign{}).
+		Where("cluster_id = ? AND status IN ?", clusterID, []string{constants.StatusPending, constants.StatusRunning, constants.StatusPaused}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// UpdateFields 按字段更新维护活动
+func (r *MaintenanceCampaignRepository) UpdateFields(id uuid.UUID, fields map[string]interface{}) error {
+	return r.db.Model(&model.MaintenanceCampaign{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// UpdateStatusFrom 仅当活动处于给定状态之一时更新，返回是否更新成功
+func (r *MaintenanceCampaignRepository) UpdateStatusFrom(id uuid.UUID, from []string, fields map[string]interface{}) (bool, error) {
+	result := r.db.Model(&model.MaintenanceCampaign{}).
+		Where("id = ? AND status IN ?", id, from).
+		Updates(fields)
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetStatus 获取维护活动当前状态
+func (r *MaintenanceCampaignRepository) GetStatus(id uuid.UUID) (string, error) {
+	var status string
+	err := r.db.Model(&model.MaintenanceCampaign{}).Where("id = ?", id).Pluck("status", &status).Error
+	return status, err
+}
+
+// MarkInterrupted 将服务重启前仍在运行的活动标记为暂停
+func (r *MaintenanceCampaignRepository) MarkInterrupted() (int64, error) {
+	result := r.db.Model(&model.MaintenanceCampaign{}).
+		Where("status = ?", constants.StatusRunning).
+		Updates(map[string]interface{}{
+			"status":       constants.StatusPaused,
+			"current_step": "Interrupted by service restart",
+		})
+	return result.RowsAffected, result.Error
+}
+
+// UpdateNodeRun 按字段更新节点执行记录
+func (r *MaintenanceCampaignRepository) UpdateNodeRun(id uuid.UUID, fields map[string]interface{}) error {
+	return r.db.Model(&model.MaintenanceNodeRun{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// AppendNodeLogs 追加节点执行日志
+func (r *MaintenanceCampaignRepository) AppendNodeLogs(id uuid.UUID, log string) error {
+	return r.db.Model(&model.MaintenanceNodeRun{}).
+		Where("id = ?", id).
+		Update("logs", gorm.Expr("COALESCE(logs, '') || ?", log)).Error
+}
+
+// ResetUnfinishedNodes 将未成功的节点记录重置为待执行，用于恢复活动
+func (r *MaintenanceCampaignRepository) ResetUnfinishedNodes(campaignID uuid.UUID) error {
+	return r.db.Model(&model.MaintenanceNodeRun{}).
+		Where("campaign_id = ? AND status <> ?", campaignID, constants.StatusSuccess).
+		Updates(map[string]interface{}{
+			"status":    constants.StatusPending,
+			"error_msg": "",
+		}).Error
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/constants"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"gorm.io/gorm"
+)
+
+// NodeDrainRepository 节点排空任务数据访问层
+type NodeDrainRepository struct {
+	db *gorm.DB
+}
+
+// NewNodeDrainRepository 创建节点排空任务仓库
+func NewNodeDrainRepository(db *gorm.DB) *NodeDrainRepository {
+	return &NodeDrainRepository{db: db}
+}
+
+// Create 创建排空任务
+func (r *NodeDrainRepository) Create(task *model.NodeDrainTask) error {
+	return r.db.Create(task).Error
+}
+
+// GetByID 根据ID获取排空任务
+func (r *NodeDrainRepository) GetByID(id uuid.UUID) (*model.NodeDrainTask, error) {
+	var task model.NodeDrainTask
+	if err := r.db.First(&task, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// ListByNode 获取节点的排空任务，不返回日志
+func (r *NodeDrainRepository) ListByNode(clusterID uuid.UUID, nodeName string) ([]*model.NodeDrainTask, error) {
+	var tasks []*model.NodeDrainTask
+	err := r.db.Omit("logs").
+		Where("cluster_id = ? AND node_name = ?", clusterID, nodeName).
+		Order("created_at DESC").
+		Find(&tasks).Error
+	return tasks, err
+}
+
+// ExistsActive 检查节点是否有未结束的排空任务
+func (r *NodeDrainRepository) ExistsActive(clusterID uuid.UUID, nodeName string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.NodeDrainTask{}).
+		Where("cluster_id = ? AND node_name = ? AND status IN ?", clusterID, nodeName, []string{constants.StatusPending, constants.StatusRunning}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// MarkInterrupted 将服务重启时未结束的排空任务标记为失败，返回更新数量
+func (r *NodeDrainRepository) MarkInterrupted() (int64, error) {
+	now := time.Now()
+	result := r.db.Model(&model.NodeDrainTask{}).
+		Where("status IN ?", []string{constants.StatusPending, constants.StatusRunning}).
+		Updates(map[string]interface{}{
+			"status":       constants.StatusFailed,
+			"current_step": "Interrupted by service restart, node remains cordoned",
+			"error_msg":    "drain interrupted by service restart",
+			"completed_at": &now,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// UpdateFields 按字段更新排空任务
+func (r *NodeDrainRepository) UpdateFields(id uuid.UUID, fields map[string]interface{}) error {
+	return r.db.Model(&model.NodeDrainTask{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// AppendLogs 追加日志
+func (r *NodeDrainRepository) AppendLogs(id uuid.UUID, log string) error {
+	return r.db.Model(&model.NodeDrainTask{}).
+		Where("id = ?", id).
+		Update("logs", gorm.Expr("COALESCE(logs, '') || ?", log)).Error
+}
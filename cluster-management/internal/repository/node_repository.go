@@ -120,6 +120,16 @@ func (r *NodeRepository) GetByClusterID(clusterID string) ([]model.Node, error)
 	return nodes, err
 }
 
+// ListByNames 按名称获取集群的节点记录
+func (r *NodeRepository) ListByNames(clusterID string, names []string) ([]model.Node, error) {
+	var nodes []model.Node
+	if len(names) == 0 {
+		return nodes, nil
+	}
+	err := r.db.Where("cluster_id = ? AND name IN ?", clusterID, names).Find(&nodes).Error
+	return nodes, err
+}
+
 func (r *NodeRepository) DeleteByClusterID(clusterID string) error {
 	return r.db.Where("cluster_id = ?", clusterID).Delete(&model.Node{}).Error
 }
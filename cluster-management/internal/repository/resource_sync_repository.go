@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/taichu-system/cluster-management/internal/model"
+)
+
+// resourceSyncBatchSize 批量 upsert 时每条 INSERT 语句的行数
+const resourceSyncBatchSize = 200
+
+// nodeSyncColumns 节点冲突时更新的列；CPU/内存用量由指标采集维护，不在同步中覆盖
+var nodeSyncColumns = []string{
+	"type", "status", "cpu_cores", "memory_bytes", "pod_count", "labels", "taints",
+	"kubelet_version", "kube_proxy_version", "container_runtime", "container_runtime_version",
+	"os_image", "kernel_version", "architecture", "updated_at",
+}
+
+// eventSyncColumns 事件冲突时更新的列
+var eventSyncColumns = []string{
+	"node_id", "event_type", "message", "severity", "component",
+	"first_timestamp", "last_timestamp", "count", "updated_at",
+}
+
+// ResourceSyncBatch 单个集群一次同步需要写入的节点与事件变更
+type ResourceSyncBatch struct {
+	ClusterID    uuid.UUID
+	UpsertNodes  []model.Node
+	DeleteNodes  []string
+	UpsertEvents []model.Event
+	// AdoptEvents 匹配到未记录 Event UID 的历史事件，按 ID 更新并补写 UID
+	AdoptEvents []model.Event
+}
+
+// Empty 没有需要写入的变更
+func (b *ResourceSyncBatch) Empty() bool {
+	return len(b.UpsertNodes) == 0 && len(b.DeleteNodes) == 0 && len(b.UpsertEvents) == 0 && len(b.AdoptEvents) == 0
+}
+
+// ResourceSyncResult 一次批量写入实际影响的行数
+type ResourceSyncResult struct {
+	NodesUpserted  int64 `json:"nodes_upserted"`
+	NodesDeleted   int64 `json:"nodes_deleted"`
+	EventsUpserted int64 `json:"events_upserted"`
+}
+
+// RowsWritten 写入的总行数
+func (r ResourceSyncResult) RowsWritten() int64 {
+	return r.NodesUpserted + r.NodesDeleted + r.EventsUpserted
+}
+
+// ResourceSyncRepository 资源同步的批量写入，同一集群的变更在一个事务内完成
+type ResourceSyncRepository struct {
+	db *gorm.DB
+}
+
+// NewResourceSyncRepository 创建资源同步仓库
+func NewResourceSyncRepository(db *gorm.DB) *ResourceSyncRepository {
+	return &ResourceSyncRepository{db: db}
+}
+
+// Apply 以 INSERT ... ON CONFLICT 批量写入节点与事件，并批量删除已不存在的节点
+// 节点按 (cluster_id, name) 冲突更新，事件按 (cluster_id, event_uid) 冲突更新，历史事件按 ID 更新
+func (r *ResourceSyncRepository) Apply(batch *ResourceSyncBatch) (ResourceSyncResult, error) {
+	var result ResourceSyncResult
+	if batch.Empty() {
+		return result, nil
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if len(batch.UpsertNodes) > 0 {
+			res := tx.Omit(clause.Associations).Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "cluster_id"}, {Name: "name"}},
+				DoUpdates: clause.AssignmentColumns(nodeSyncColumns),
+			}).CreateInBatches(&batch.UpsertNodes, resourceSyncBatchSize)
+			if res.Error != nil {
+				return fmt.Errorf("failed to upsert nodes: %w", res.Error)
+			}
+			result.NodesUpserted = res.RowsAffected
+		}
+
+		if len(batch.DeleteNodes) > 0 {
+			res := tx.Where("cluster_id = ? AND name IN ?", batch.ClusterID, batch.DeleteNodes).Delete(&model.Node{})
+			if res.Error != nil {
+				return fmt.Errorf("failed to delete nodes: %w", res.Error)
+			}
+			result.NodesDeleted = res.RowsAffected
+		}
+
+		if len(batch.UpsertEvents) > 0 {
+			res := tx.Omit(clause.Associations).Clauses(clause.OnConflict{
+				Columns:     []clause.Column{{Name: "cluster_id"}, {Name: "event_uid"}},
+				TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "event_uid <> ''"}}},
+				DoUpdates:   clause.AssignmentColumns(eventSyncColumns),
+			}).CreateInBatches(&batch.UpsertEvents, resourceSyncBatchSize)
+			if res.Error != nil {
+				return fmt.Errorf("failed to upsert events: %w", res.Error)
+			}
+			result.EventsUpserted = res.RowsAffected
+		}
+
+		for _, event := range batch.AdoptEvents {
+			res := tx.Model(&model.Event{}).
+				Where("id = ? AND cluster_id = ?", event.ID, batch.ClusterID).
+				Select(append([]string{"event_uid"}, eventSyncColumns...)).
+				Updates(&event)
+			if res.Error != nil {
+				return fmt.Errorf("failed to update legacy event %s: %w", event.ID, res.Error)
+			}
+			result.EventsUpserted += res.RowsAffected
+		}
+		return nil
+	})
+	if err != nil {
+		return ResourceSyncResult{}, err
+	}
+	return result, nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TenantMemberRepository 租户成员数据访问
+type TenantMemberRepository struct {
+	db *gorm.DB
+}
+
+// NewTenantMemberRepository 创建租户成员仓库
+func NewTenantMemberRepository(db *gorm.DB) *TenantMemberRepository {
+	return &TenantMemberRepository{db: db}
+}
+
+// Save 添加租户成员，已存在时更新角色
+func (r *TenantMemberRepository) Save(member *model.TenantMember) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "updated_at"}),
+	}).Create(member).Error
+}
+
+// ListByTenant 获取租户的全部成员
+func (r *TenantMemberRepository) ListByTenant(tenantID uuid.UUID) ([]*model.TenantMember, error) {
+	var members []*model.TenantMember
+	err := r.db.Where("tenant_id = ?", tenantID).Order("created_at").Find(&members).Error
+	return members, err
+}
+
+// ListMemberships 获取用户所属的租户，仅包含启用状态的租户
+func (r *TenantMemberRepository) ListMemberships(userID uuid.UUID) ([]model.TenantMembership, error) {
+	var memberships []model.TenantMembership
+	err := r.db.Table("tenant_members").
+		Select("tenants.id AS tenant_id, tenants.name AS tenant_name, tenant_members.role AS role").
+		Joins("JOIN tenants ON tenants.id = tenant_members.tenant_id").
+		Where("tenant_members.user_id = ? AND tenants.status = ?", userID, model.TenantStatusActive).
+		Order("tenants.name").
+		Scan(&memberships).Error
+	return memberships, err
+}
+
+// Delete 移除租户成员，返回是否存在该成员
+func (r *TenantMemberRepository) Delete(tenantID, userID uuid.UUID) (bool, error) {
+	result := r.db.Where("tenant_id = ? AND user_id = ?", tenantID, userID).Delete(&model.TenantMember{})
+	return result.RowsAffected > 0, result.Error
+}
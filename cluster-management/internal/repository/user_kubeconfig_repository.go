@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"gorm.io/gorm"
+)
+
+// UserKubeconfigRepository 用户 kubeconfig 签发记录数据访问
+type UserKubeconfigRepository struct {
+	db *gorm.DB
+}
+
+// NewUserKubeconfigRepository 创建用户 kubeconfig 仓库
+func NewUserKubeconfigRepository(db *gorm.DB) *UserKubeconfigRepository {
+	return &UserKubeconfigRepository{db: db}
+}
+
+// Create 保存签发记录
+func (r *UserKubeconfigRepository) Create(kubeconfig *model.UserKubeconfig) error {
+	return r.db.Create(kubeconfig).Error
+}
+
+// GetByID 获取签发记录
+func (r *UserKubeconfigRepository) GetByID(id uuid.UUID) (*model.UserKubeconfig, error) {
+	var kubeconfig model.UserKubeconfig
+	if err := r.db.Where("id = ?", id).First(&kubeconfig).Error; err != nil {
+		return nil, err
+	}
+	return &kubeconfig, nil
+}
+
+// List 获取集群的签发记录，可按用户与状态过滤
+func (r *UserKubeconfigRepository) List(clusterID uuid.UUID, userID *uuid.UUID, status string) ([]*model.UserKubeconfig, error) {
+	query := r.db.Where("cluster_id = ?", clusterID)
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var kubeconfigs []*model.UserKubeconfig
+	err := query.Order("created_at DESC").Find(&kubeconfigs).Error
+	return kubeconfigs, err
+}
+
+// ListExpired 获取已过期但仍为 active 的签发记录
+func (r *UserKubeconfigRepository) ListExpired(now time.Time, limit int) ([]*model.UserKubeconfig, error) {
+	var kubeconfigs []*model.UserKubeconfig
+	err := r.db.Where("status = ? AND expires_at <= ?", model.UserKubeconfigStatusActive, now).
+		Order("expires_at").
+		Limit(limit).
+		Find(&kubeconfigs).Error
+	return kubeconfigs, err
+}
+
+// UpdateFields 更新签发记录的指定字段
+func (r *UserKubeconfigRepository) UpdateFields(id uuid.UUID, fields map[string]interface{}) error {
+	return r.db.Model(&model.UserKubeconfig{}).Where("id = ?", id).Updates(fields).Error
+}
@@ -122,3 +122,45 @@ func (s *AlertService) AlertSystemError(component, errorMsg string) error {
 
 	return err
 }
+
+// AlertCertificateExpiring 证书剩余天数达到告警阈值
+func (s *AlertService) AlertCertificateExpiring(clusterID, clusterName string, cert *model.ClusterCertificate, threshold int) error {
+	severity := model.AlertSeverityMedium
+	switch {
+	case threshold <= 7:
+		severity = model.AlertSeverityCritical
+	case threshold <= 14:
+		severity = model.AlertSeverityHigh
+	}
+
+	location := cert.Source
+	if cert.NodeName != "" {
+		location = cert.Source + "@" + cert.NodeName
+	}
+	status := fmt.Sprintf("expires in %d days", cert.DaysRemaining)
+	if cert.DaysRemaining < 0 {
+		status = fmt.Sprintf("expired %d days ago", -cert.DaysRemaining)
+	}
+
+	metadata := model.JSONMap{
+		"cluster_id":     clusterID,
+		"source":         cert.Source,
+		"name":           cert.Name,
+		"node_name":      cert.NodeName,
+		"not_after":      cert.NotAfter,
+		"days_remaining": cert.DaysRemaining,
+		"threshold":      threshold,
+	}
+
+	_, err := s.CreateAlert(
+		model.AlertTypeCertificateExpiring,
+		severity,
+		fmt.Sprintf("Certificate %s %s: %s", cert.Name, status, clusterName),
+		fmt.Sprintf("Certificate %s (%s) of cluster %s %s, not after %s", cert.Name, location, clusterName, status, cert.NotAfter.Format(time.RFC3339)),
+		"cluster",
+		clusterID,
+		metadata,
+	)
+
+	return err
+}
@@ -71,7 +71,7 @@ func (s *AutoscalingPolicyService) SyncAutoscalingPolicyFromKubernetes(clusterID
 	}
 
 	ctx := context.Background()
-	clientset, err := s.clusterManager.GetClient(ctx, kubeconfig)
+	clientset, err := s.clusterManager.GetClientForCluster(ctx, cluster.ID, kubeconfig)
 	if err != nil {
 		return fmt.Errorf("failed to get client: %w", err)
 	}
@@ -117,7 +117,7 @@ func (s *AutoscalingPolicyService) getHPAPolicies(clusterID string) []HPAPolicy
 	}
 
 	ctx := context.Background()
-	clientset, err := s.clusterManager.GetClient(ctx, kubeconfig)
+	clientset, err := s.clusterManager.GetClientForCluster(ctx, cluster.ID, kubeconfig)
 	if err != nil {
 		return nil
 	}
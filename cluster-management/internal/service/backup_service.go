@@ -101,7 +101,7 @@ func (s *BackupService) ExecuteBackup(backupID string) error {
 	}
 
 	ctx := context.Background()
-	clientset, err := s.clusterManager.GetClient(ctx, kubeconfig)
+	clientset, err := s.clusterManager.GetClientForCluster(ctx, cluster.ID, kubeconfig)
 	if err != nil {
 		return s.handleBackupError(backup, fmt.Errorf("failed to get client: %w", err))
 	}
@@ -565,7 +565,7 @@ func (s *BackupService) ExecuteEtcdBackup(backupID string) error {
 
 	// 创建Kubernetes客户端
 	ctx := context.Background()
-	clientset, err := s.clusterManager.GetClient(ctx, kubeconfig)
+	clientset, err := s.clusterManager.GetClientForCluster(ctx, cluster.ID, kubeconfig)
 	if err != nil {
 		return s.handleBackupError(backup, fmt.Errorf("failed to get client: %w", err))
 	}
@@ -665,7 +665,7 @@ func (s *BackupService) ExecuteResourceBackup(backupID string) error {
 
 	// 创建Kubernetes客户端
 	ctx := context.Background()
-	clientset, err := s.clusterManager.GetClient(ctx, kubeconfig)
+	clientset, err := s.clusterManager.GetClientForCluster(ctx, cluster.ID, kubeconfig)
 	if err != nil {
 		return s.handleBackupError(backup, fmt.Errorf("failed to get client: %w", err))
 	}
@@ -0,0 +1,633 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/constants"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"github.com/taichu-system/cluster-management/internal/repository"
+	"gorm.io/gorm"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var (
+	// ErrRotationInProgress 集群已有进行中的证书轮换任务
+	ErrRotationInProgress = errors.New("certificate rotation already in progress")
+	// ErrRotationNotSupported 集群不是平台创建的，无法通过SSH轮换证书
+	ErrRotationNotSupported = errors.New("certificate rotation is only supported for platform-created clusters")
+	// ErrRotationNotFound 轮换任务不存在
+	ErrRotationNotFound = errors.New("certificate rotation not found")
+)
+
+// certificateAlertThresholds 证书到期告警阈值（天），按从大到小依次触发
+var certificateAlertThresholds = []int{30, 14, 7}
+
+// nodeCertificateScript 输出节点上各证书文件中的证书块，不读取私钥内容
+const nodeCertificateScript = `for f in /etc/kubernetes/pki/*.crt /etc/kubernetes/pki/etcd/*.crt /etc/ssl/etcd/ssl/*.pem /var/lib/kubelet/pki/kubelet-client-current.pem /var/lib/kubelet/pki/kubelet.crt; do
+  case "$f" in *-key.pem) continue ;; esac
+  [ -f "$f" ] || continue
+  echo "### $f"
+  sed -n '/-----BEGIN CERTIFICATE-----/,/-----END CERTIFICATE-----/p' "$f"
+done`
+
+// certificateRotationCommands 在 master 上续期 kubeadm 管理的证书并重启控制平面组件
+var certificateRotationCommands = []string{
+	"kubeadm certs renew all || kubeadm alpha certs renew all",
+	`for c in kube-apiserver kube-controller-manager kube-scheduler etcd; do ids=$(crictl ps -q --name "^$c$"); [ -z "$ids" ] || crictl stop $ids; done`,
+	"systemctl restart kubelet",
+	`for i in $(seq 1 60); do curl -ksf https://127.0.0.1:6443/healthz >/dev/null && exit 0; sleep 5; done; echo "kube-apiserver not healthy after restart"; exit 1`,
+}
+
+// CertificateService 集群证书到期检查与轮换服务
+type CertificateService struct {
+	clusterRepo       *repository.ClusterRepository
+	certificateRepo   *repository.ClusterCertificateRepository
+	rotationRepo      *repository.CertificateRotationRepository
+	machineService    *MachineService
+	clusterManager    *ClusterManager
+	encryptionService *EncryptionService
+	alertService      *AlertService
+	auditService      *AuditService
+}
+
+// NewCertificateService 创建证书服务
+func NewCertificateService(
+	clusterRepo *repository.ClusterRepository,
+	certificateRepo *repository.ClusterCertificateRepository,
+	rotationRepo *repository.CertificateRotationRepository,
+	machineService *MachineService,
+	clusterManager *ClusterManager,
+	encryptionService *EncryptionService,
+	alertService *AlertService,
+	auditService *AuditService,
+) *CertificateService {
+	return &CertificateService{
+		clusterRepo:       clusterRepo,
+		certificateRepo:   certificateRepo,
+		rotationRepo:      rotationRepo,
+		machineService:    machineService,
+		clusterManager:    clusterManager,
+		encryptionService: encryptionService,
+		alertService:      alertService,
+		auditService:      auditService,
+	}
+}
+
+// GetCertificates 获取集群最近一次检查的证书
+func (s *CertificateService) GetCertificates(clusterID uuid.UUID) ([]*model.ClusterCertificate, error) {
+	if _, err := s.getCluster(clusterID); err != nil {
+		return nil, err
+	}
+	return s.certificateRepo.ListByClusterID(clusterID)
+}
+
+// ListExpiring 获取全部集群中剩余天数不超过 days 的证书
+func (s *CertificateService) ListExpiring(days int) ([]*model.ClusterCertificate, error) {
+	return s.certificateRepo.ListExpiringBefore(days)
+}
+
+// CheckClusterByID 立即检查指定集群的证书
+func (s *CertificateService) CheckClusterByID(ctx context.Context, clusterID uuid.UUID) ([]*model.ClusterCertificate, error) {
+	cluster, err := s.getCluster(clusterID)
+	if err != nil {
+		return nil, err
+	}
+	return s.CheckCluster(ctx, cluster)
+}
+
+// CheckCluster 采集集群证书并保存，单个来源失败时保存其余来源并返回汇总错误
+func (s *CertificateService) CheckCluster(ctx context.Context, cluster *model.Cluster) ([]*model.ClusterCertificate, error) {
+	kubeconfig, err := s.encryptionService.Decrypt(cluster.KubeconfigEncrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt kubeconfig: %w", err)
+	}
+
+	var certificates []*model.ClusterCertificate
+	var errs []error
+	// 采集失败的来源保留上次结果，避免节点临时不可达时证书记录消失
+	failed := make(map[string]bool)
+
+	if certs, err := kubeconfigCertificates(kubeconfig); err != nil {
+		errs = append(errs, fmt.Errorf("kubeconfig: %w", err))
+		failed[model.CertificateSourceKubeconfig+"|"] = true
+	} else {
+		certificates = append(certificates, certs...)
+	}
+
+	config, err := s.clusterManager.RESTConfigForCluster(cluster.ID, kubeconfig)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("apiserver: %w", err))
+		failed[model.CertificateSourceAPIServer+"|"] = true
+	} else if cert, err := servingCertificate(ctx, config); err != nil {
+		errs = append(errs, fmt.Errorf("apiserver: %w", err))
+		failed[model.CertificateSourceAPIServer+"|"] = true
+	} else if cert != nil {
+		certificates = append(certificates, cert)
+	}
+
+	if cluster.ImportSource == constants.ClusterSourcePlatform {
+		machines, err := s.machineService.GetMachinesByCluster(cluster.ID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get cluster machines: %w", err))
+		}
+		for _, machine := range machines {
+			certs, err := s.nodeCertificates(machine)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("node %s: %w", machine.Name, err))
+				failed[model.CertificateSourceNode+"|"+machine.Name] = true
+				continue
+			}
+			certificates = append(certificates, certs...)
+		}
+	}
+
+	previous, err := s.certificateRepo.ListByClusterID(cluster.ID)
+	if err != nil {
+		return nil, err
+	}
+	alerted := make(map[string]int, len(previous))
+	for _, cert := range previous {
+		alerted[certificateKey(cert)] = cert.AlertedThreshold
+	}
+
+	now := time.Now()
+	for _, cert := range certificates {
+		cert.ClusterID = cluster.ID
+		cert.CheckedAt = now
+		cert.DaysRemaining = int(math.Floor(cert.NotAfter.Sub(now).Hours() / 24))
+		// 证书续期后序列号变化，告警阈值重新计算
+		cert.AlertedThreshold = alerted[certificateKey(cert)]
+		s.evaluateAlert(cluster, cert)
+	}
+	for _, cert := range previous {
+		if !failed[cert.Source+"|"+cert.NodeName] {
+			continue
+		}
+		cert.ID = uuid.Nil
+		cert.DaysRemaining = int(math.Floor(cert.NotAfter.Sub(now).Hours() / 24))
+		s.evaluateAlert(cluster, cert)
+		certificates = append(certificates, cert)
+	}
+
+	if err := s.certificateRepo.ReplaceForCluster(cluster.ID, certificates); err != nil {
+		return nil, fmt.Errorf("failed to save certificates: %w", err)
+	}
+
+	return certificates, errors.Join(errs...)
+}
+
+// evaluateAlert 剩余天数跨过新的阈值时产生告警
+func (s *CertificateService) evaluateAlert(cluster *model.Cluster, cert *model.ClusterCertificate) {
+	threshold := 0
+	for _, t := range certificateAlertThresholds {
+		if cert.DaysRemaining <= t {
+			threshold = t
+		}
+	}
+	if threshold == 0 || (cert.AlertedThreshold != 0 && cert.AlertedThreshold <= threshold) {
+		return
+	}
+
+	if s.alertService != nil {
+		if err := s.alertService.AlertCertificateExpiring(cluster.ID.String(), cluster.Name, cert, threshold); err != nil {
+			return
+		}
+	}
+	cert.AlertedThreshold = threshold
+}
+
+// nodeCertificates 通过SSH读取节点上的证书文件
+func (s *CertificateService) nodeCertificates(machine *model.Machine) ([]*model.ClusterCertificate, error) {
+	auth, err := s.machineService.ResolveSSHAuth(machine)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s.machineService.ConnectMachine(machine)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	cmd := nodeCertificateScript
+	if auth.Username != "root" {
+		cmd = "sudo -n sh -c " + shellQuote(cmd)
+	}
+	out, err := client.ExecuteCommand(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificates: %v: %s", err, strings.TrimSpace(out))
+	}
+
+	certificates := parseNodeCertificates(out)
+	for _, cert := range certificates {
+		cert.NodeName = machine.Name
+	}
+	return certificates, nil
+}
+
+// StartRotation 创建证书轮换任务并异步执行
+func (s *CertificateService) StartRotation(clusterID uuid.UUID, operator string) (*model.CertificateRotation, error) {
+	cluster, err := s.getCluster(clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if cluster.ImportSource != constants.ClusterSourcePlatform {
+		return nil, ErrRotationNotSupported
+	}
+
+	machines, err := s.machineService.GetMachinesByCluster(clusterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster machines: %w", err)
+	}
+	if countRole(machines, "master") == 0 {
+		return nil, fmt.Errorf("%w: no master machines bound to cluster", ErrRotationNotSupported)
+	}
+	for _, machine := range machines {
+		if err := s.machineService.ValidateMachineAuth(machine); err != nil {
+			return nil, err
+		}
+	}
+
+	active, err := s.rotationRepo.ExistsActive(clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if active {
+		return nil, ErrRotationInProgress
+	}
+
+	rotation := &model.CertificateRotation{
+		ClusterID:   clusterID,
+		Status:      constants.StatusPending,
+		CurrentStep: "Waiting to start",
+		CreatedBy:   operator,
+	}
+	if err := s.rotationRepo.Create(rotation); err != nil {
+		return nil, fmt.Errorf("failed to create rotation task: %w", err)
+	}
+
+	go s.executeRotation(rotation.ID)
+
+	return rotation, nil
+}
+
+// GetRotation 获取轮换任务
+func (s *CertificateService) GetRotation(id uuid.UUID) (*model.CertificateRotation, error) {
+	rotation, err := s.rotationRepo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRotationNotFound
+	}
+	return rotation, err
+}
+
+// ListRotations 获取集群的轮换任务
+func (s *CertificateService) ListRotations(clusterID uuid.UUID) ([]*model.CertificateRotation, error) {
+	return s.rotationRepo.ListByClusterID(clusterID)
+}
+
+// executeRotation 执行证书轮换（异步）
+func (s *CertificateService) executeRotation(id uuid.UUID) {
+	rotation, err := s.rotationRepo.GetByID(id)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	s.rotationRepo.UpdateFields(id, map[string]interface{}{
+		"status":       constants.StatusRunning,
+		"current_step": "Starting rotation",
+		"started_at":   &now,
+	})
+
+	err = s.runRotation(rotation)
+
+	completedAt := time.Now()
+	fields := map[string]interface{}{
+		"completed_at": &completedAt,
+	}
+	result := constants.StatusSuccess
+	if err != nil {
+		result = constants.StatusFailed
+		fields["status"] = constants.StatusFailed
+		fields["current_step"] = fmt.Sprintf("Rotation failed: %v", err)
+		fields["error_msg"] = err.Error()
+		s.logLine(id, err.Error(), true)
+	} else {
+		fields["status"] = constants.StatusSuccess
+		fields["current_step"] = "Certificates rotated"
+	}
+	s.rotationRepo.UpdateFields(id, fields)
+
+	if s.auditService != nil {
+		details := map[string]interface{}{
+			"rotation_id": id.String(),
+		}
+		if err != nil {
+			details["error"] = err.Error()
+		}
+		s.auditService.CreateAuditEvent(
+			rotation.ClusterID,
+			constants.EventTypeUpdate,
+			"rotate_certificates",
+			constants.ResourceTypeCluster,
+			rotation.ClusterID.String(),
+			rotation.CreatedBy,
+			"",
+			"",
+			nil,
+			nil,
+			details,
+			result,
+		)
+	}
+}
+
+// runRotation 逐个 master 续期证书，完成后更新平台保存的 kubeconfig 并重新检查
+func (s *CertificateService) runRotation(rotation *model.CertificateRotation) error {
+	cluster, err := s.getCluster(rotation.ClusterID)
+	if err != nil {
+		return err
+	}
+	machines, err := s.machineService.GetMachinesByCluster(cluster.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get cluster machines: %w", err)
+	}
+
+	// 逐个处理 master，保证同一时刻只有一个控制平面重启
+	var firstMaster *model.Machine
+	for _, machine := range machines {
+		if machine.Role != "master" {
+			continue
+		}
+		if firstMaster == nil {
+			firstMaster = machine
+		}
+		s.step(rotation.ID, fmt.Sprintf("Renewing certificates on %s", machine.Name))
+		if err := s.renewOnMaster(rotation.ID, machine); err != nil {
+			return err
+		}
+	}
+	if countRole(machines, "etcd") > 0 {
+		s.logLine(rotation.ID, "external etcd certificates are not managed by kubeadm and were left unchanged", false)
+	}
+
+	s.step(rotation.ID, "Updating stored kubeconfig")
+	if err := s.refreshKubeconfig(cluster, firstMaster); err != nil {
+		return fmt.Errorf("failed to update kubeconfig: %w", err)
+	}
+
+	s.step(rotation.ID, "Checking certificates")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	if _, err := s.CheckClusterByID(ctx, cluster.ID); err != nil {
+		s.logLine(rotation.ID, fmt.Sprintf("certificate check after rotation: %v", err), true)
+	}
+	return nil
+}
+
+// renewOnMaster 在单个 master 上续期证书
+func (s *CertificateService) renewOnMaster(id uuid.UUID, machine *model.Machine) error {
+	auth, err := s.machineService.ResolveSSHAuth(machine)
+	if err != nil {
+		return err
+	}
+	client, err := s.machineService.ConnectMachine(machine)
+	if err != nil {
+		return fmt.Errorf("failed to connect %s: %w", machine.Name, err)
+	}
+	defer client.Close()
+
+	for _, cmd := range certificateRotationCommands {
+		if auth.Username != "root" {
+			cmd = "sudo -n sh -c " + shellQuote(cmd)
+		}
+		out, err := client.ExecuteCommand(cmd)
+		for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+			if line != "" {
+				s.logLine(id, fmt.Sprintf("[%s] %s", machine.Name, line), false)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("rotation on %s failed: %w", machine.Name, err)
+		}
+	}
+	return nil
+}
+
+// refreshKubeconfig 读取续期后的 admin.conf，保留原 server 地址后加密保存
+func (s *CertificateService) refreshKubeconfig(cluster *model.Cluster, master *model.Machine) error {
+	oldKubeconfig, err := s.encryptionService.Decrypt(cluster.KubeconfigEncrypted)
+	if err != nil {
+		return err
+	}
+	server, err := s.clusterManager.GetAPIServerURL(oldKubeconfig)
+	if err != nil {
+		return err
+	}
+
+	auth, err := s.machineService.ResolveSSHAuth(master)
+	if err != nil {
+		return err
+	}
+	client, err := s.machineService.ConnectMachine(master)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	cmd := "cat " + kubeadmAdminConfig
+	if auth.Username != "root" {
+		cmd = "sudo -n " + cmd
+	}
+	out, err := client.ExecuteCommand(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to read %s on %s: %w", kubeadmAdminConfig, master.Name, err)
+	}
+
+	kubeconfig, err := rewriteKubeconfigServer(out, server)
+	if err != nil {
+		return err
+	}
+	encrypted, err := s.encryptionService.Encrypt(kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	cluster.KubeconfigEncrypted = encrypted
+	if err := s.clusterRepo.Update(cluster); err != nil {
+		return err
+	}
+	s.clusterManager.RemoveClient(oldKubeconfig)
+	return nil
+}
+
+// getCluster 获取集群，不存在时返回 ErrClusterNotFound
+func (s *CertificateService) getCluster(clusterID uuid.UUID) (*model.Cluster, error) {
+	cluster, err := s.clusterRepo.GetByID(clusterID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClusterNotFound
+		}
+		return nil, err
+	}
+	return cluster, nil
+}
+
+// step 更新当前步骤
+func (s *CertificateService) step(id uuid.UUID, step string) {
+	s.rotationRepo.UpdateFields(id, map[string]interface{}{"current_step": step})
+	s.logLine(id, step, false)
+}
+
+// logLine 追加带时间戳的日志
+func (s *CertificateService) logLine(id uuid.UUID, line string, isError bool) {
+	appendTimestampedLog(func(log string) { s.rotationRepo.AppendLogs(id, log) }, line, isError)
+}
+
+// kubeconfigCertificates 解析 kubeconfig 当前上下文的客户端证书与CA证书
+func kubeconfigCertificates(kubeconfig string) ([]*model.ClusterCertificate, error) {
+	config, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return nil, err
+	}
+	kubeContext, ok := config.Contexts[config.CurrentContext]
+	if !ok {
+		return nil, fmt.Errorf("current context %q not found", config.CurrentContext)
+	}
+
+	var certificates []*model.ClusterCertificate
+	if authInfo, ok := config.AuthInfos[kubeContext.AuthInfo]; ok && len(authInfo.ClientCertificateData) > 0 {
+		certs, err := parsePEMCertificates(authInfo.ClientCertificateData)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate: %w", err)
+		}
+		if len(certs) > 0 {
+			certificates = append(certificates, newClusterCertificate(model.CertificateSourceKubeconfig, "client", certs[0]))
+		}
+	}
+	if cluster, ok := config.Clusters[kubeContext.Cluster]; ok && len(cluster.CertificateAuthorityData) > 0 {
+		certs, err := parsePEMCertificates(cluster.CertificateAuthorityData)
+		if err != nil {
+			return nil, fmt.Errorf("certificate authority: %w", err)
+		}
+		for i, cert := range certs {
+			name := "ca"
+			if i > 0 {
+				name = fmt.Sprintf("ca-%d", i)
+			}
+			certificates = append(certificates, newClusterCertificate(model.CertificateSourceKubeconfig, name, cert))
+		}
+	}
+	return certificates, nil
+}
+
+// servingCertificate 连接 apiserver 读取其服务端证书，http 地址返回 nil
+// 经由 REST 配置中的代理或堡垒机拨号连接，与集群客户端走相同路径
+func servingCertificate(ctx context.Context, config *rest.Config) (*model.ClusterCertificate, error) {
+	u, err := url.Parse(config.Host)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "https" {
+		return nil, nil
+	}
+
+	serverName := config.TLSClientConfig.ServerName
+	if serverName == "" {
+		serverName = u.Hostname()
+	}
+	// 仅读取证书内容，不依赖证书校验结果，过期证书同样需要记录
+	transport := &http.Transport{
+		Proxy:               config.Proxy,
+		DialContext:         (&net.Dialer{Timeout: 10 * time.Second}).DialContext,
+		TLSClientConfig:     &tls.Config{ServerName: serverName, InsecureSkipVerify: true},
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+	if config.Dial != nil {
+		transport.DialContext = config.Dial
+	}
+	defer transport.CloseIdleConnections()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(config.Host, "/")+"/version", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return nil, fmt.Errorf("no certificate presented by %s", u.Host)
+	}
+	return newClusterCertificate(model.CertificateSourceAPIServer, "serving", resp.TLS.PeerCertificates[0]), nil
+}
+
+// parseNodeCertificates 解析 nodeCertificateScript 的输出，每个文件取首个证书
+func parseNodeCertificates(out string) []*model.ClusterCertificate {
+	var certificates []*model.ClusterCertificate
+	for _, section := range strings.Split(out, "### ")[1:] {
+		path, body, _ := strings.Cut(section, "\n")
+		certs, err := parsePEMCertificates([]byte(body))
+		if err != nil || len(certs) == 0 {
+			continue
+		}
+		certificates = append(certificates, newClusterCertificate(model.CertificateSourceNode, strings.TrimSpace(path), certs[0]))
+	}
+	return certificates
+}
+
+// parsePEMCertificates 解析PEM中的全部证书块
+func parsePEMCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// newClusterCertificate 由 x509 证书生成记录
+func newClusterCertificate(source, name string, cert *x509.Certificate) *model.ClusterCertificate {
+	return &model.ClusterCertificate{
+		Source:       source,
+		Name:         name,
+		Subject:      cert.Subject.String(),
+		Issuer:       cert.Issuer.String(),
+		SerialNumber: hex.EncodeToString(cert.SerialNumber.Bytes()),
+		DNSNames:     model.StringSlice(cert.DNSNames),
+		IsCA:         cert.IsCA,
+		NotBefore:    cert.NotBefore,
+		NotAfter:     cert.NotAfter,
+	}
+}
+
+// certificateKey 证书记录的唯一标识，包含序列号以便续期后重新告警
+func certificateKey(cert *model.ClusterCertificate) string {
+	return strings.Join([]string{cert.Source, cert.NodeName, cert.Name, cert.SerialNumber}, "|")
+}
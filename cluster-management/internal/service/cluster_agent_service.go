@@ -0,0 +1,528 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/agent"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"github.com/taichu-system/cluster-management/internal/repository"
+	"golang.org/x/crypto/ssh"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidAgentToken 注册令牌不存在、已使用或已过期
+	ErrInvalidAgentToken = errors.New("invalid or expired agent registration token")
+	// ErrAgentUnauthorized 代理密钥无效
+	ErrAgentUnauthorized = errors.New("agent credentials are invalid")
+	// ErrAgentNotConnected 集群代理未连接到当前实例
+	ErrAgentNotConnected = errors.New("cluster agent is not connected")
+	// ErrAgentNotFound 集群未签发过代理
+	ErrAgentNotFound = errors.New("cluster agent not found")
+)
+
+const (
+	defaultAgentTokenTTL         = 24 * time.Hour
+	defaultAgentHeartbeatTimeout = 3 * agent.DefaultHeartbeatInterval
+	defaultAgentConnectTimeout   = 30 * time.Minute
+)
+
+// AgentRegistrationToken 签发的一次性注册令牌，明文只返回一次
+type AgentRegistrationToken struct {
+	ClusterID uuid.UUID `json:"cluster_id"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ClusterAgentView 集群代理状态
+type ClusterAgentView struct {
+	*model.ClusterAgent
+	Online bool `json:"online"` // 隧道连接在当前实例上
+}
+
+// agentSession 一条已建立的反向隧道
+type agentSession struct {
+	client        *ssh.Client
+	remoteAddr    string
+	lastHeartbeat time.Time
+}
+
+// ClusterAgentService 集群代理服务：签发注册令牌、认证代理并维护反向隧道
+// 隧道连接保存在接收连接的实例内存中
+type ClusterAgentService struct {
+	clusterRepo      *repository.ClusterRepository
+	agentRepo        *repository.ClusterAgentRepository
+	stateRepo        *repository.ClusterStateRepository
+	healthHistory    *HealthHistoryService
+	tokenTTL         time.Duration
+	heartbeatTimeout time.Duration
+	connectTimeout   time.Duration
+
+	// onRegistered 代理注册成功后调用，用于将集群连接方式切换为代理
+	onRegistered func(clusterID uuid.UUID) error
+
+	mu       sync.RWMutex
+	sessions map[uuid.UUID]*agentSession
+}
+
+// NewClusterAgentService 创建集群代理服务，时长参数为 0 时使用默认值
+func NewClusterAgentService(
+	clusterRepo *repository.ClusterRepository,
+	agentRepo *repository.ClusterAgentRepository,
+	stateRepo *repository.ClusterStateRepository,
+	healthHistory *HealthHistoryService,
+	tokenTTL, heartbeatTimeout, connectTimeout time.Duration,
+) *ClusterAgentService {
+	if tokenTTL <= 0 {
+		tokenTTL = defaultAgentTokenTTL
+	}
+	if heartbeatTimeout <= 0 {
+		heartbeatTimeout = defaultAgentHeartbeatTimeout
+	}
+	if connectTimeout <= 0 {
+		connectTimeout = defaultAgentConnectTimeout
+	}
+	return &ClusterAgentService{
+		clusterRepo:      clusterRepo,
+		agentRepo:        agentRepo,
+		stateRepo:        stateRepo,
+		healthHistory:    healthHistory,
+		tokenTTL:         tokenTTL,
+		heartbeatTimeout: heartbeatTimeout,
+		connectTimeout:   connectTimeout,
+		sessions:         make(map[uuid.UUID]*agentSession),
+	}
+}
+
+// OnRegistered 设置代理注册成功后的回调
+func (s *ClusterAgentService) OnRegistered(fn func(clusterID uuid.UUID) error) {
+	s.onRegistered = fn
+}
+
+// IssueToken 为集群签发一次性注册令牌，之前未使用的令牌随之失效
+func (s *ClusterAgentService) IssueToken(clusterID uuid.UUID) (*AgentRegistrationToken, error) {
+	if _, err := s.clusterRepo.GetByID(clusterID.String()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClusterNotFound
+		}
+		return nil, fmt.Errorf("failed to get cluster: %w", err)
+	}
+
+	token, err := randomAgentSecret()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(s.tokenTTL)
+	record := &model.ClusterAgent{
+		ClusterID:      clusterID,
+		Status:         model.ClusterAgentPending,
+		TokenHash:      hashAgentSecret(token),
+		TokenExpiresAt: &expiresAt,
+	}
+	if err := s.agentRepo.SaveToken(record); err != nil {
+		return nil, fmt.Errorf("failed to save agent token: %w", err)
+	}
+	return &AgentRegistrationToken{ClusterID: clusterID, Token: token, ExpiresAt: expiresAt}, nil
+}
+
+// Register 消费注册令牌并签发代理密钥，同一集群已连接的旧代理会被断开
+func (s *ClusterAgentService) Register(req agent.RegisterRequest, remoteAddr string) (*agent.RegisterResponse, error) {
+	if req.Token == "" {
+		return nil, ErrInvalidAgentToken
+	}
+	tokenHash := hashAgentSecret(req.Token)
+	record, err := s.agentRepo.GetByTokenHash(tokenHash)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAgentToken
+		}
+		return nil, fmt.Errorf("failed to get agent: %w", err)
+	}
+	if record.TokenExpiresAt == nil || time.Now().After(*record.TokenExpiresAt) {
+		return nil, ErrInvalidAgentToken
+	}
+
+	random, err := randomAgentSecret()
+	if err != nil {
+		return nil, err
+	}
+	// 密钥以集群ID为前缀，认证时据此定位记录
+	secret := record.ClusterID.String() + "." + random
+	now := time.Now()
+	err = s.agentRepo.Register(record.ClusterID, tokenHash, map[string]interface{}{
+		"secret_hash":   hashAgentSecret(secret),
+		"status":        model.ClusterAgentDisconnected,
+		"agent_version": req.AgentVersion,
+		"hostname":      req.Hostname,
+		"remote_addr":   remoteAddr,
+		"registered_at": now,
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAgentToken
+		}
+		return nil, fmt.Errorf("failed to register agent: %w", err)
+	}
+	s.closeSession(record.ClusterID)
+	log.Printf("[AGENT] cluster %s registered agent %s from %s", record.ClusterID, req.Hostname, remoteAddr)
+
+	if s.onRegistered != nil {
+		if err := s.onRegistered(record.ClusterID); err != nil {
+			log.Printf("[AGENT] failed to switch cluster %s to agent connection: %v", record.ClusterID, err)
+		}
+	}
+	return &agent.RegisterResponse{ClusterID: record.ClusterID.String(), Secret: secret}, nil
+}
+
+// Authenticate 校验代理密钥，返回所属集群
+func (s *ClusterAgentService) Authenticate(secret string) (uuid.UUID, error) {
+	prefix, _, ok := strings.Cut(secret, ".")
+	if !ok {
+		return uuid.Nil, ErrAgentUnauthorized
+	}
+	clusterID, err := uuid.Parse(prefix)
+	if err != nil {
+		return uuid.Nil, ErrAgentUnauthorized
+	}
+	record, err := s.agentRepo.GetByClusterID(clusterID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, ErrAgentUnauthorized
+		}
+		return uuid.Nil, fmt.Errorf("failed to get agent: %w", err)
+	}
+	if record.SecretHash == "" || subtle.ConstantTimeCompare([]byte(record.SecretHash), []byte(hashAgentSecret(secret))) != 1 {
+		return uuid.Nil, ErrAgentUnauthorized
+	}
+	return clusterID, nil
+}
+
+// Serve 在代理连接上建立隧道并阻塞到隧道断开，期间处理心跳
+func (s *ClusterAgentService) Serve(clusterID uuid.UUID, conn net.Conn, remoteAddr string) error {
+	conn.SetDeadline(time.Now().Add(sshTunnelDialTimeout))
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, remoteAddr, &ssh.ClientConfig{
+		User: agent.SSHUser,
+		// 隧道两端的身份已由 TLS 与代理密钥认证，SSH 仅用于多路复用
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	})
+	if err != nil {
+		return fmt.Errorf("tunnel handshake failed: %w", err)
+	}
+	conn.SetDeadline(time.Time{})
+	// 全局请求由本服务处理，客户端只需处理通道
+	noRequests := make(chan *ssh.Request)
+	close(noRequests)
+	session := &agentSession{
+		client:        ssh.NewClient(sshConn, chans, noRequests),
+		remoteAddr:    remoteAddr,
+		lastHeartbeat: time.Now(),
+	}
+	defer session.client.Close()
+
+	s.mu.Lock()
+	previous := s.sessions[clusterID]
+	s.sessions[clusterID] = session
+	s.mu.Unlock()
+	if previous != nil {
+		previous.client.Close()
+	}
+
+	now := time.Now()
+	if err := s.agentRepo.Update(clusterID, map[string]interface{}{
+		"status":       model.ClusterAgentConnected,
+		"remote_addr":  remoteAddr,
+		"connected_at": now,
+	}); err != nil {
+		log.Printf("[AGENT] failed to update agent status for %s: %v", clusterID, err)
+	}
+	log.Printf("[AGENT] cluster %s agent connected from %s", clusterID, remoteAddr)
+
+	done := make(chan struct{})
+	go s.watchHeartbeat(clusterID, session, done)
+	for req := range reqs {
+		if req.Type != agent.HeartbeatRequest {
+			if req.WantReply {
+				req.Reply(false, nil)
+			}
+			continue
+		}
+		var heartbeat agent.Heartbeat
+		if err := json.Unmarshal(req.Payload, &heartbeat); err != nil {
+			req.Reply(false, nil)
+			continue
+		}
+		s.mu.Lock()
+		session.lastHeartbeat = time.Now()
+		s.mu.Unlock()
+		s.recordHeartbeat(clusterID, &heartbeat)
+		req.Reply(true, nil)
+	}
+	close(done)
+
+	// 仅当隧道仍是当前连接时标记断开，代理重连时旧连接关闭不影响新连接
+	s.mu.Lock()
+	current := s.sessions[clusterID] == session
+	if current {
+		delete(s.sessions, clusterID)
+	}
+	s.mu.Unlock()
+	if current {
+		s.markDisconnected(clusterID)
+	}
+	log.Printf("[AGENT] cluster %s agent disconnected from %s", clusterID, remoteAddr)
+	return nil
+}
+
+// Dialer 返回经代理隧道访问 apiserver 的拨号函数，每次拨号使用当前连接
+func (s *ClusterAgentService) Dialer(clusterID uuid.UUID) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		s.mu.RLock()
+		session := s.sessions[clusterID]
+		s.mu.RUnlock()
+		if session == nil {
+			return nil, fmt.Errorf("%w: cluster %s", ErrAgentNotConnected, clusterID)
+		}
+		conn, err := session.client.DialContext(ctx, network, address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reach apiserver via cluster agent: %w", err)
+		}
+		return conn, nil
+	}
+}
+
+// IsConnected 集群代理是否连接到当前实例
+func (s *ClusterAgentService) IsConnected(clusterID uuid.UUID) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sessions[clusterID] != nil
+}
+
+// WaitConnected 等待集群代理连接，超过 connect_timeout 或 ctx 取消时返回错误
+func (s *ClusterAgentService) WaitConnected(ctx context.Context, clusterID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, s.connectTimeout)
+	defer cancel()
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for !s.IsConnected(clusterID) {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: waited %s", ErrAgentNotConnected, s.connectTimeout)
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// Get 获取集群代理状态
+func (s *ClusterAgentService) Get(clusterID uuid.UUID) (*ClusterAgentView, error) {
+	if _, err := s.clusterRepo.GetByID(clusterID.String()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClusterNotFound
+		}
+		return nil, fmt.Errorf("failed to get cluster: %w", err)
+	}
+	record, err := s.agentRepo.GetByClusterID(clusterID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAgentNotFound
+		}
+		return nil, fmt.Errorf("failed to get agent: %w", err)
+	}
+	return &ClusterAgentView{ClusterAgent: record, Online: s.IsConnected(clusterID)}, nil
+}
+
+// Revoke 吊销集群代理并断开隧道，代理需使用新令牌重新注册
+func (s *ClusterAgentService) Revoke(clusterID uuid.UUID) error {
+	if _, err := s.Get(clusterID); err != nil {
+		return err
+	}
+	if err := s.agentRepo.Delete(clusterID); err != nil {
+		return fmt.Errorf("failed to delete agent: %w", err)
+	}
+	s.closeSession(clusterID)
+	return nil
+}
+
+// Close 断开全部隧道
+func (s *ClusterAgentService) Close() {
+	s.mu.Lock()
+	sessions := s.sessions
+	s.sessions = make(map[uuid.UUID]*agentSession)
+	s.mu.Unlock()
+	for _, session := range sessions {
+		session.client.Close()
+	}
+}
+
+// watchHeartbeat 超过心跳超时未收到心跳时断开隧道
+func (s *ClusterAgentService) watchHeartbeat(clusterID uuid.UUID, session *agentSession, done <-chan struct{}) {
+	ticker := time.NewTicker(s.heartbeatTimeout / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			s.mu.RLock()
+			last := session.lastHeartbeat
+			s.mu.RUnlock()
+			if time.Since(last) > s.heartbeatTimeout {
+				log.Printf("[AGENT] cluster %s agent missed heartbeats since %s, closing tunnel", clusterID, last.Format(time.RFC3339))
+				session.client.Close()
+				return
+			}
+		}
+	}
+}
+
+// recordHeartbeat 记录心跳并更新集群状态
+// apiserver 可达时只刷新心跳时间与版本，健康与降级的判定仍由健康检查负责
+func (s *ClusterAgentService) recordHeartbeat(clusterID uuid.UUID, heartbeat *agent.Heartbeat) {
+	now := time.Now()
+	lastHeartbeat, _ := toJSONValue(heartbeat).(map[string]interface{})
+	if err := s.agentRepo.Update(clusterID, map[string]interface{}{
+		"agent_version":     heartbeat.AgentVersion,
+		"last_heartbeat_at": now,
+		"last_heartbeat":    model.JSONMap(lastHeartbeat),
+	}); err != nil {
+		log.Printf("[AGENT] failed to record heartbeat for %s: %v", clusterID, err)
+	}
+
+	state, err := s.stateRepo.GetByClusterID(clusterID.String())
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("[AGENT] failed to get cluster state for %s: %v", clusterID, err)
+		return
+	}
+
+	fields := map[string]interface{}{
+		"last_heartbeat_at": now,
+		"updated_at":        now,
+	}
+	status := ""
+	if heartbeat.APIServerReachable {
+		if heartbeat.KubernetesVersion != "" {
+			fields["kubernetes_version"] = heartbeat.KubernetesVersion
+		}
+		if heartbeat.NodeCount > 0 {
+			fields["node_count"] = heartbeat.NodeCount
+		}
+		if state == nil || (state.Status != ClusterHealthHealthy && state.Status != ClusterHealthDegraded) {
+			status = ClusterHealthHealthy
+			fields["sync_error"] = ""
+		}
+	} else {
+		status = ClusterHealthUnhealthy
+		fields["sync_error"] = "cluster agent cannot reach apiserver: " + heartbeat.Error
+	}
+	if status != "" {
+		fields["status"] = status
+	}
+	s.updateState(clusterID, state, fields)
+
+	if status != "" {
+		message := ""
+		if status == ClusterHealthUnhealthy {
+			message = fields["sync_error"].(string)
+		}
+		s.recordHistory(clusterID, status, message)
+	}
+}
+
+// markDisconnected 隧道断开后更新代理与集群状态
+func (s *ClusterAgentService) markDisconnected(clusterID uuid.UUID) {
+	now := time.Now()
+	if err := s.agentRepo.Update(clusterID, map[string]interface{}{
+		"status":          model.ClusterAgentDisconnected,
+		"disconnected_at": now,
+	}); err != nil {
+		log.Printf("[AGENT] failed to update agent status for %s: %v", clusterID, err)
+	}
+
+	message := "cluster agent disconnected"
+	state, err := s.stateRepo.GetByClusterID(clusterID.String())
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("[AGENT] failed to get cluster state for %s: %v", clusterID, err)
+		return
+	}
+	s.updateState(clusterID, state, map[string]interface{}{
+		"status":       ClusterHealthDisconnected,
+		"sync_success": false,
+		"sync_error":   message,
+		"updated_at":   now,
+	})
+	s.recordHistory(clusterID, ClusterHealthDisconnected, message)
+}
+
+func (s *ClusterAgentService) updateState(clusterID uuid.UUID, state *model.ClusterState, fields map[string]interface{}) {
+	if state != nil {
+		if _, err := s.stateRepo.UpdateFields(clusterID.String(), fields); err != nil {
+			log.Printf("[AGENT] failed to update cluster state for %s: %v", clusterID, err)
+		}
+		return
+	}
+
+	state = &model.ClusterState{ClusterID: clusterID, Status: ClusterHealthDisconnected}
+	if status, ok := fields["status"].(string); ok {
+		state.Status = status
+	}
+	if version, ok := fields["kubernetes_version"].(string); ok {
+		state.KubernetesVersion = version
+	}
+	if nodeCount, ok := fields["node_count"].(int); ok {
+		state.NodeCount = nodeCount
+	}
+	if heartbeatAt, ok := fields["last_heartbeat_at"].(time.Time); ok {
+		state.LastHeartbeatAt = &heartbeatAt
+	}
+	state.SyncError, _ = fields["sync_error"].(string)
+	if err := s.stateRepo.Create(state); err != nil {
+		log.Printf("[AGENT] failed to create cluster state for %s: %v", clusterID, err)
+	}
+}
+
+func (s *ClusterAgentService) recordHistory(clusterID uuid.UUID, status, message string) {
+	if s.healthHistory == nil {
+		return
+	}
+	if err := s.healthHistory.Record(clusterID, status, message, time.Now()); err != nil {
+		log.Printf("[AGENT] failed to record health history for %s: %v", clusterID, err)
+	}
+}
+
+// closeSession 断开集群当前的隧道
+func (s *ClusterAgentService) closeSession(clusterID uuid.UUID) {
+	s.mu.Lock()
+	session := s.sessions[clusterID]
+	delete(s.sessions, clusterID)
+	s.mu.Unlock()
+	if session != nil {
+		session.client.Close()
+		s.markDisconnected(clusterID)
+	}
+}
+
+func randomAgentSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate agent secret: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashAgentSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
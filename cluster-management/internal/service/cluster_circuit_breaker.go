@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// 集群熔断器状态
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+)
+
+const (
+	defaultBreakerFailureThreshold = 5
+	defaultBreakerBaseBackoff      = 30 * time.Second
+	defaultBreakerMaxBackoff       = 30 * time.Minute
+)
+
+// ErrClusterCircuitOpen 集群连续访问失败，熔断期内后台任务跳过该集群
+var ErrClusterCircuitOpen = errors.New("cluster circuit breaker is open")
+
+// CircuitBreakerStatus 集群熔断器状态，随集群状态返回
+type CircuitBreakerStatus struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	Trips               int        `json:"trips"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastFailureAt       *time.Time `json:"last_failure_at,omitempty"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"`
+}
+
+// circuitBreaker 按集群统计连续失败的请求，达到阈值后熔断，熔断时长按连续熔断次数指数增长
+// 熔断到期后进入半开状态，放行一次探测，探测成功则恢复，失败则再次熔断
+type circuitBreaker struct {
+	mu            sync.Mutex
+	threshold     int
+	baseBackoff   time.Duration
+	maxBackoff    time.Duration
+	probeTimeout  time.Duration
+	state         string
+	failures      int
+	trips         int
+	lastError     string
+	lastFailureAt time.Time
+	retryAt       time.Time
+	probeAt       time.Time
+}
+
+func newCircuitBreaker(threshold int, baseBackoff, maxBackoff, probeTimeout time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold:    threshold,
+		baseBackoff:  baseBackoff,
+		maxBackoff:   maxBackoff,
+		probeTimeout: probeTimeout,
+		state:        CircuitClosed,
+	}
+}
+
+// allow 熔断期内返回 ErrClusterCircuitOpen；熔断到期后只放行一个探测，探测超时未返回结果时放行下一个
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	switch b.state {
+	case CircuitOpen:
+		if now.Before(b.retryAt) {
+			return fmt.Errorf("%w: retry at %s: %s", ErrClusterCircuitOpen, b.retryAt.Format(time.RFC3339), b.lastError)
+		}
+		b.state = CircuitHalfOpen
+		b.probeAt = now
+	case CircuitHalfOpen:
+		if now.Sub(b.probeAt) < b.probeTimeout {
+			return fmt.Errorf("%w: probe in progress", ErrClusterCircuitOpen)
+		}
+		b.probeAt = now
+	}
+	return nil
+}
+
+func (b *circuitBreaker) recordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = CircuitClosed
+	b.failures = 0
+	b.trips = 0
+	b.retryAt = time.Time{}
+}
+
+func (b *circuitBreaker) recordFailure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.failures++
+	b.lastError = err.Error()
+	b.lastFailureAt = now
+	// 熔断期间仍在进行的请求失败时不延长熔断
+	if b.state == CircuitOpen || (b.state == CircuitClosed && b.failures < b.threshold) {
+		return
+	}
+
+	backoff := b.baseBackoff << b.trips
+	if backoff <= 0 || backoff > b.maxBackoff {
+		backoff = b.maxBackoff
+	}
+	b.trips++
+	b.state = CircuitOpen
+	b.retryAt = now.Add(backoff)
+}
+
+func (b *circuitBreaker) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = CircuitClosed
+	b.failures = 0
+	b.trips = 0
+	b.lastError = ""
+	b.lastFailureAt = time.Time{}
+	b.retryAt = time.Time{}
+}
+
+func (b *circuitBreaker) status() CircuitBreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := CircuitBreakerStatus{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		Trips:               b.trips,
+		LastError:           b.lastError,
+	}
+	if !b.lastFailureAt.IsZero() {
+		lastFailureAt := b.lastFailureAt
+		status.LastFailureAt = &lastFailureAt
+	}
+	if b.state != CircuitClosed {
+		retryAt := b.retryAt
+		status.RetryAt = &retryAt
+	}
+	return status
+}
+
+// breakerRoundTripper 记录每个请求的结果：连接错误与网关错误计为失败，其余响应计为成功
+type breakerRoundTripper struct {
+	breaker *circuitBreaker
+	next    http.RoundTripper
+}
+
+func (rt *breakerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.next.RoundTrip(req)
+	switch {
+	case err != nil:
+		// 调用方取消的请求不代表集群不可达
+		if !errors.Is(req.Context().Err(), context.Canceled) {
+			rt.breaker.recordFailure(err)
+		}
+	case resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusGatewayTimeout:
+		rt.breaker.recordFailure(fmt.Errorf("apiserver returned %s", resp.Status))
+	default:
+		rt.breaker.recordSuccess()
+	}
+	return resp, err
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	type step struct {
+		// op 为 fail、success、allow、expire（熔断与探测到期）或 reset
+		op      string
+		blocked bool
+		state   string
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "failures below threshold stay closed",
+			steps: []step{
+				{op: "fail", state: CircuitClosed},
+				{op: "fail", state: CircuitClosed},
+				{op: "allow", state: CircuitClosed},
+			},
+		},
+		{
+			name: "success resets consecutive failures",
+			steps: []step{
+				{op: "fail", state: CircuitClosed},
+				{op: "fail", state: CircuitClosed},
+				{op: "success", state: CircuitClosed},
+				{op: "fail", state: CircuitClosed},
+				{op: "fail", state: CircuitClosed},
+				{op: "allow", state: CircuitClosed},
+			},
+		},
+		{
+			name: "threshold opens the breaker",
+			steps: []step{
+				{op: "fail", state: CircuitClosed},
+				{op: "fail", state: CircuitClosed},
+				{op: "fail", state: CircuitOpen},
+				{op: "allow", blocked: true, state: CircuitOpen},
+			},
+		},
+		{
+			name: "expired breaker lets one probe through",
+			steps: []step{
+				{op: "fail"}, {op: "fail"}, {op: "fail", state: CircuitOpen},
+				{op: "expire", state: CircuitOpen},
+				{op: "allow", state: CircuitHalfOpen},
+				{op: "allow", blocked: true, state: CircuitHalfOpen},
+			},
+		},
+		{
+			name: "successful probe closes the breaker",
+			steps: []step{
+				{op: "fail"}, {op: "fail"}, {op: "fail", state: CircuitOpen},
+				{op: "expire"},
+				{op: "allow", state: CircuitHalfOpen},
+				{op: "success", state: CircuitClosed},
+				{op: "allow", state: CircuitClosed},
+			},
+		},
+		{
+			name: "failed probe opens the breaker again",
+			steps: []step{
+				{op: "fail"}, {op: "fail"}, {op: "fail", state: CircuitOpen},
+				{op: "expire"},
+				{op: "allow", state: CircuitHalfOpen},
+				{op: "fail", state: CircuitOpen},
+				{op: "allow", blocked: true, state: CircuitOpen},
+			},
+		},
+		{
+			name: "timed out probe lets the next probe through",
+			steps: []step{
+				{op: "fail"}, {op: "fail"}, {op: "fail", state: CircuitOpen},
+				{op: "expire"},
+				{op: "allow", state: CircuitHalfOpen},
+				{op: "expire"},
+				{op: "allow", state: CircuitHalfOpen},
+			},
+		},
+		{
+			name: "reset closes an open breaker",
+			steps: []step{
+				{op: "fail"}, {op: "fail"}, {op: "fail", state: CircuitOpen},
+				{op: "reset", state: CircuitClosed},
+				{op: "allow", state: CircuitClosed},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newCircuitBreaker(3, time.Minute, time.Hour, time.Minute)
+			for i, s := range tt.steps {
+				var err error
+				switch s.op {
+				case "fail":
+					b.recordFailure(errors.New("connection refused"))
+				case "success":
+					b.recordSuccess()
+				case "allow":
+					err = b.allow()
+				case "expire":
+					b.mu.Lock()
+					b.retryAt = time.Now().Add(-time.Second)
+					b.probeAt = time.Now().Add(-2 * time.Minute)
+					b.mu.Unlock()
+				case "reset":
+					b.reset()
+				default:
+					t.Fatalf("unknown op %q", s.op)
+				}
+
+				if blocked := errors.Is(err, ErrClusterCircuitOpen); blocked != s.blocked {
+					t.Fatalf("step %d (%s): blocked = %v, want %v (err = %v)", i, s.op, blocked, s.blocked, err)
+				}
+				if s.state != "" {
+					if state := b.status().State; state != s.state {
+						t.Fatalf("step %d (%s): state = %q, want %q", i, s.op, state, s.state)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerCooldown(t *testing.T) {
+	tests := []struct {
+		name string
+		// trips 熔断前已连续熔断的次数
+		trips int
+		want  time.Duration
+	}{
+		{name: "first trip uses base backoff", trips: 0, want: 30 * time.Second},
+		{name: "second trip doubles", trips: 1, want: time.Minute},
+		{name: "third trip doubles again", trips: 2, want: 2 * time.Minute},
+		{name: "capped at max backoff", trips: 10, want: 10 * time.Minute},
+		{name: "overflowing shift capped at max backoff", trips: 64, want: 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newCircuitBreaker(1, 30*time.Second, 10*time.Minute, time.Minute)
+			b.trips = tt.trips
+			b.recordFailure(errors.New("connection refused"))
+
+			status := b.status()
+			if status.State != CircuitOpen {
+				t.Fatalf("state = %q, want %q", status.State, CircuitOpen)
+			}
+			if status.RetryAt == nil || status.LastFailureAt == nil {
+				t.Fatal("open breaker should report last failure and retry time")
+			}
+			if got := status.RetryAt.Sub(*status.LastFailureAt); got != tt.want {
+				t.Errorf("cooldown = %s, want %s", got, tt.want)
+			}
+			if status.Trips != tt.trips+1 {
+				t.Errorf("trips = %d, want %d", status.Trips, tt.trips+1)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerFailureWhileOpenDoesNotExtendCooldown(t *testing.T) {
+	b := newCircuitBreaker(1, time.Minute, time.Hour, time.Minute)
+	b.recordFailure(errors.New("connection refused"))
+	retryAt := *b.status().RetryAt
+
+	b.recordFailure(errors.New("connection refused"))
+	status := b.status()
+	if !status.RetryAt.Equal(retryAt) {
+		t.Errorf("retry at = %s, want %s", status.RetryAt, retryAt)
+	}
+	if status.Trips != 1 {
+		t.Errorf("trips = %d, want 1", status.Trips)
+	}
+}
@@ -0,0 +1,519 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"github.com/taichu-system/cluster-management/internal/repository"
+	"golang.org/x/crypto/ssh"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidConnection 集群连接设置不合法
+var ErrInvalidConnection = errors.New("invalid cluster connection settings")
+
+// ClusterConnectionConfig 解析后的集群连接方式，由 ClusterManager 应用到 REST 配置
+type ClusterConnectionConfig struct {
+	Revision      string // 设置变更后变化，用于区分客户端缓存
+	TLSServerName string
+	Proxy         func(*http.Request) (*url.URL, error)
+	Dial          func(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// ConnectionResolver 按集群解析连接方式，返回 nil 表示直连
+type ConnectionResolver interface {
+	ResolveConnection(clusterID uuid.UUID) (*ClusterConnectionConfig, error)
+}
+
+// ClusterConnectionInput 保存连接设置的参数，未提供的密钥在模式不变时保留原值
+type ClusterConnectionInput struct {
+	Mode                string
+	BastionHost         string
+	BastionPort         int
+	BastionCredentialID *uuid.UUID
+	BastionUser         string
+	BastionPassword     string
+	BastionPrivateKey   string
+	BastionPassphrase   string
+	BastionHostKey      string
+	ProxyURL            string
+	TLSServerName       string
+}
+
+// ClusterConnectionView 连接设置的对外视图，不包含密钥
+type ClusterConnectionView struct {
+	ClusterID            uuid.UUID  `json:"cluster_id"`
+	Mode                 string     `json:"mode"`
+	BastionHost          string     `json:"bastion_host,omitempty"`
+	BastionPort          int        `json:"bastion_port,omitempty"`
+	BastionCredentialID  *uuid.UUID `json:"bastion_credential_id,omitempty"`
+	BastionUser          string     `json:"bastion_user,omitempty"`
+	HasBastionPassword   bool       `json:"has_bastion_password"`
+	HasBastionPrivateKey bool       `json:"has_bastion_private_key"`
+	BastionHostKey       string     `json:"bastion_host_key,omitempty"`
+	ProxyURL             string     `json:"proxy_url,omitempty"` // 密码已脱敏
+	TLSServerName        string     `json:"tls_server_name,omitempty"`
+	UpdatedAt            *time.Time `json:"updated_at,omitempty"`
+}
+
+// ConnectionTestResult 连接测试结果
+type ConnectionTestResult struct {
+	Mode      string `json:"mode"`
+	Success   bool   `json:"success"`
+	Version   string `json:"version,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ClusterConnectionService 集群连接设置服务，实现 ConnectionResolver
+type ClusterConnectionService struct {
+	clusterRepo       *repository.ClusterRepository
+	connectionRepo    *repository.ClusterConnectionRepository
+	machineService    *MachineService
+	encryptionService *EncryptionService
+	clusterManager    *ClusterManager
+	agents            *ClusterAgentService
+	tunnels           *SSHTunnelPool
+
+	mu    sync.RWMutex
+	cache map[uuid.UUID]*ClusterConnectionConfig // nil 值表示直连，避免重复查询
+}
+
+// NewClusterConnectionService 创建集群连接设置服务并注册到 ClusterManager
+func NewClusterConnectionService(
+	clusterRepo *repository.ClusterRepository,
+	connectionRepo *repository.ClusterConnectionRepository,
+	machineService *MachineService,
+	encryptionService *EncryptionService,
+	clusterManager *ClusterManager,
+	agents *ClusterAgentService,
+) *ClusterConnectionService {
+	s := &ClusterConnectionService{
+		clusterRepo:       clusterRepo,
+		connectionRepo:    connectionRepo,
+		machineService:    machineService,
+		encryptionService: encryptionService,
+		clusterManager:    clusterManager,
+		agents:            agents,
+		tunnels:           NewSSHTunnelPool(),
+		cache:             make(map[uuid.UUID]*ClusterConnectionConfig),
+	}
+	clusterManager.SetConnectionResolver(s)
+	if agents != nil {
+		agents.OnRegistered(s.useAgent)
+	}
+	return s
+}
+
+// Close 关闭全部堡垒机隧道
+func (s *ClusterConnectionService) Close() {
+	s.tunnels.CloseAll()
+}
+
+// ResolveConnection 解析集群连接方式，结果缓存到设置变更为止
+func (s *ClusterConnectionService) ResolveConnection(clusterID uuid.UUID) (*ClusterConnectionConfig, error) {
+	s.mu.RLock()
+	config, ok := s.cache[clusterID]
+	s.mu.RUnlock()
+	if ok {
+		return config, nil
+	}
+
+	connection, err := s.connectionRepo.GetByClusterID(clusterID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get connection settings: %w", err)
+	}
+	if connection != nil {
+		if config, err = s.buildConfig(connection); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	s.cache[clusterID] = config
+	s.mu.Unlock()
+	return config, nil
+}
+
+// Get 获取集群连接设置，未设置时返回直连
+func (s *ClusterConnectionService) Get(clusterID uuid.UUID) (*ClusterConnectionView, error) {
+	if err := s.ensureCluster(clusterID); err != nil {
+		return nil, err
+	}
+	connection, err := s.connectionRepo.GetByClusterID(clusterID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &ClusterConnectionView{ClusterID: clusterID, Mode: model.ClusterConnectionDirect}, nil
+		}
+		return nil, fmt.Errorf("failed to get connection settings: %w", err)
+	}
+	return s.view(connection), nil
+}
+
+// Save 校验并保存连接设置，已缓存的客户端与隧道随之失效
+func (s *ClusterConnectionService) Save(clusterID uuid.UUID, input ClusterConnectionInput) (*ClusterConnectionView, error) {
+	if err := s.ensureCluster(clusterID); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.connectionRepo.GetByClusterID(clusterID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get connection settings: %w", err)
+	}
+
+	connection, err := s.buildConnection(clusterID, input, existing)
+	if err != nil {
+		return nil, err
+	}
+	// 保存前解析堡垒机凭据，保证凭据可解密、私钥与主机密钥格式正确
+	if connection.Mode == model.ClusterConnectionSSHBastion {
+		if _, err := s.bastionClientConfig(connection); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidConnection, err)
+		}
+	}
+	if err := s.connectionRepo.Save(connection); err != nil {
+		return nil, fmt.Errorf("failed to save connection settings: %w", err)
+	}
+
+	s.invalidate(clusterID)
+	saved, err := s.connectionRepo.GetByClusterID(clusterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection settings: %w", err)
+	}
+	return s.view(saved), nil
+}
+
+// Delete 删除连接设置，恢复直连
+func (s *ClusterConnectionService) Delete(clusterID uuid.UUID) error {
+	if err := s.ensureCluster(clusterID); err != nil {
+		return err
+	}
+	if err := s.connectionRepo.Delete(clusterID); err != nil {
+		return fmt.Errorf("failed to delete connection settings: %w", err)
+	}
+	s.invalidate(clusterID)
+	return nil
+}
+
+// Test 使用当前设置连接 apiserver 并读取版本
+func (s *ClusterConnectionService) Test(ctx context.Context, clusterID uuid.UUID) (*ConnectionTestResult, error) {
+	cluster, err := s.clusterRepo.GetByID(clusterID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClusterNotFound
+		}
+		return nil, fmt.Errorf("failed to get cluster: %w", err)
+	}
+	view, err := s.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+	kubeconfig, err := s.encryptionService.Decrypt(cluster.KubeconfigEncrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt kubeconfig: %w", err)
+	}
+
+	result := &ConnectionTestResult{Mode: view.Mode}
+	start := time.Now()
+	clientset, err := s.clusterManager.GetClientForCluster(ctx, clusterID, kubeconfig)
+	if err == nil {
+		info, versionErr := clientset.Discovery().ServerVersion()
+		if err = versionErr; err == nil {
+			result.Version = info.String()
+		}
+	}
+	result.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	result.Success = true
+	return result, nil
+}
+
+// AwaitAgent 集群经代理连接时等待代理连接，其他连接方式直接返回
+func (s *ClusterConnectionService) AwaitAgent(ctx context.Context, clusterID uuid.UUID) error {
+	connection, err := s.connectionRepo.GetByClusterID(clusterID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get connection settings: %w", err)
+	}
+	if connection.Mode != model.ClusterConnectionAgent || s.agents == nil {
+		return nil
+	}
+	return s.agents.WaitConnected(ctx, clusterID)
+}
+
+// useAgent 代理注册后将集群切换为经代理连接，保留已设置的 TLS ServerName
+func (s *ClusterConnectionService) useAgent(clusterID uuid.UUID) error {
+	input := ClusterConnectionInput{Mode: model.ClusterConnectionAgent}
+	existing, err := s.connectionRepo.GetByClusterID(clusterID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to get connection settings: %w", err)
+	}
+	if existing != nil {
+		if existing.Mode == model.ClusterConnectionAgent {
+			return nil
+		}
+		input.TLSServerName = existing.TLSServerName
+	}
+	_, err = s.Save(clusterID, input)
+	return err
+}
+
+// buildConnection 根据输入生成待保存的设置，切换模式时清除其他模式的字段
+func (s *ClusterConnectionService) buildConnection(clusterID uuid.UUID, input ClusterConnectionInput, existing *model.ClusterConnection) (*model.ClusterConnection, error) {
+	connection := &model.ClusterConnection{
+		ClusterID:     clusterID,
+		Mode:          input.Mode,
+		TLSServerName: input.TLSServerName,
+	}
+	if connection.Mode == "" {
+		connection.Mode = model.ClusterConnectionDirect
+	}
+	sameMode := existing != nil && existing.Mode == connection.Mode
+
+	switch connection.Mode {
+	case model.ClusterConnectionDirect:
+	case model.ClusterConnectionSSHBastion:
+		if input.BastionHost == "" {
+			return nil, fmt.Errorf("%w: bastion_host is required", ErrInvalidConnection)
+		}
+		connection.BastionHost = input.BastionHost
+		connection.BastionPort = input.BastionPort
+		if connection.BastionPort == 0 {
+			connection.BastionPort = 22
+		}
+		connection.BastionHostKey = input.BastionHostKey
+		if input.BastionCredentialID != nil {
+			connection.BastionCredentialID = input.BastionCredentialID
+			break
+		}
+		if input.BastionUser == "" {
+			return nil, fmt.Errorf("%w: bastion_credential_id or bastion_user is required", ErrInvalidConnection)
+		}
+		connection.BastionUser = input.BastionUser
+
+		secrets := []struct {
+			value    string
+			dst      *string
+			previous string
+		}{
+			{input.BastionPassword, &connection.BastionPassword, ""},
+			{input.BastionPrivateKey, &connection.BastionPrivateKey, ""},
+			{input.BastionPassphrase, &connection.BastionPassphrase, ""},
+		}
+		if sameMode && existing.BastionCredentialID == nil {
+			secrets[0].previous = existing.BastionPassword
+			// 更换私钥时口令随之替换
+			if input.BastionPrivateKey == "" {
+				secrets[1].previous = existing.BastionPrivateKey
+				secrets[2].previous = existing.BastionPassphrase
+			}
+		}
+		for _, secret := range secrets {
+			if secret.value == "" {
+				*secret.dst = secret.previous
+				continue
+			}
+			encrypted, err := s.encryptionService.Encrypt(secret.value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encrypt bastion credential: %w", err)
+			}
+			*secret.dst = encrypted
+		}
+		if connection.BastionPassword == "" && connection.BastionPrivateKey == "" {
+			return nil, fmt.Errorf("%w: bastion_password or bastion_private_key is required", ErrInvalidConnection)
+		}
+	case model.ClusterConnectionAgent:
+		if s.agents == nil {
+			return nil, fmt.Errorf("%w: cluster agent is not enabled", ErrInvalidConnection)
+		}
+	case model.ClusterConnectionProxy:
+		proxyURL := input.ProxyURL
+		if proxyURL == "" && sameMode {
+			// 未提供时沿用已保存的代理地址（对外展示的是脱敏地址，不能回写）
+			connection.ProxyURL = existing.ProxyURL
+			break
+		}
+		if err := validateProxyURL(proxyURL); err != nil {
+			return nil, err
+		}
+		encrypted, err := s.encryptionService.Encrypt(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt proxy url: %w", err)
+		}
+		connection.ProxyURL = encrypted
+	default:
+		return nil, fmt.Errorf("%w: unsupported mode %q", ErrInvalidConnection, connection.Mode)
+	}
+	return connection, nil
+}
+
+// buildConfig 将保存的设置转换为连接方式，堡垒机拨号经过连接池
+func (s *ClusterConnectionService) buildConfig(connection *model.ClusterConnection) (*ClusterConnectionConfig, error) {
+	config := &ClusterConnectionConfig{
+		Revision:      strconv.FormatInt(connection.UpdatedAt.UnixNano(), 36),
+		TLSServerName: connection.TLSServerName,
+	}
+
+	switch connection.Mode {
+	case model.ClusterConnectionSSHBastion:
+		sshConfig, err := s.bastionClientConfig(connection)
+		if err != nil {
+			return nil, err
+		}
+		addr := net.JoinHostPort(connection.BastionHost, strconv.Itoa(connection.BastionPort))
+		config.Dial = s.tunnels.Dialer(connection.ClusterID.String(), addr, sshConfig)
+	case model.ClusterConnectionProxy:
+		raw, err := s.encryptionService.Decrypt(connection.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt proxy url: %w", err)
+		}
+		proxyURL, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url: %w", err)
+		}
+		config.Proxy = http.ProxyURL(proxyURL)
+	case model.ClusterConnectionAgent:
+		if s.agents == nil {
+			return nil, errors.New("cluster agent is not enabled")
+		}
+		config.Dial = s.agents.Dialer(connection.ClusterID)
+	}
+
+	if config.TLSServerName == "" && config.Proxy == nil && config.Dial == nil {
+		return nil, nil
+	}
+	return config, nil
+}
+
+// bastionClientConfig 生成堡垒机SSH配置，设置了主机公钥时校验主机密钥
+func (s *ClusterConnectionService) bastionClientConfig(connection *model.ClusterConnection) (*ssh.ClientConfig, error) {
+	auth, err := s.bastionAuth(connection)
+	if err != nil {
+		return nil, err
+	}
+	methods, err := buildSSHAuthMethods(auth)
+	if err != nil {
+		return nil, err
+	}
+	hostKeyCallback := ssh.InsecureIgnoreHostKey()
+	if connection.BastionHostKey != "" {
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(connection.BastionHostKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid bastion host key: %w", err)
+		}
+		hostKeyCallback = ssh.FixedHostKey(key)
+	}
+	return &ssh.ClientConfig{
+		User:            auth.Username,
+		Auth:            methods,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         sshTunnelDialTimeout,
+	}, nil
+}
+
+// bastionAuth 解析堡垒机凭据，共享凭据优先
+func (s *ClusterConnectionService) bastionAuth(connection *model.ClusterConnection) (*SSHAuth, error) {
+	if connection.BastionCredentialID != nil {
+		return s.machineService.ResolveCredentialAuth(*connection.BastionCredentialID)
+	}
+	auth := &SSHAuth{Username: connection.BastionUser}
+	fields := []struct {
+		src string
+		dst *string
+	}{
+		{connection.BastionPassword, &auth.Password},
+		{connection.BastionPrivateKey, &auth.PrivateKey},
+		{connection.BastionPassphrase, &auth.Passphrase},
+	}
+	for _, f := range fields {
+		if f.src == "" {
+			continue
+		}
+		plaintext, err := s.encryptionService.Decrypt(f.src)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt bastion credential: %w", err)
+		}
+		*f.dst = plaintext
+	}
+	return auth, nil
+}
+
+// invalidate 清除缓存的连接方式、堡垒机隧道与集群客户端
+func (s *ClusterConnectionService) invalidate(clusterID uuid.UUID) {
+	s.mu.Lock()
+	delete(s.cache, clusterID)
+	s.mu.Unlock()
+	s.tunnels.Close(clusterID.String())
+	s.clusterManager.InvalidateCluster(clusterID)
+}
+
+func (s *ClusterConnectionService) view(connection *model.ClusterConnection) *ClusterConnectionView {
+	updatedAt := connection.UpdatedAt
+	view := &ClusterConnectionView{
+		ClusterID:            connection.ClusterID,
+		Mode:                 connection.Mode,
+		BastionHost:          connection.BastionHost,
+		BastionCredentialID:  connection.BastionCredentialID,
+		BastionUser:          connection.BastionUser,
+		HasBastionPassword:   connection.BastionPassword != "",
+		HasBastionPrivateKey: connection.BastionPrivateKey != "",
+		BastionHostKey:       connection.BastionHostKey,
+		TLSServerName:        connection.TLSServerName,
+		UpdatedAt:            &updatedAt,
+	}
+	if connection.Mode == model.ClusterConnectionSSHBastion {
+		view.BastionPort = connection.BastionPort
+	}
+	if connection.ProxyURL != "" {
+		if raw, err := s.encryptionService.Decrypt(connection.ProxyURL); err == nil {
+			if u, err := url.Parse(raw); err == nil {
+				view.ProxyURL = u.Redacted()
+			}
+		}
+	}
+	return view
+}
+
+func (s *ClusterConnectionService) ensureCluster(clusterID uuid.UUID) error {
+	if _, err := s.clusterRepo.GetByID(clusterID.String()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrClusterNotFound
+		}
+		return fmt.Errorf("failed to get cluster: %w", err)
+	}
+	return nil
+}
+
+// validateProxyURL 校验代理地址，支持 http、https、socks5
+func validateProxyURL(raw string) error {
+	if raw == "" {
+		return fmt.Errorf("%w: proxy_url is required", ErrInvalidConnection)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("%w: invalid proxy_url: %v", ErrInvalidConnection, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("%w: proxy_url scheme must be http, https or socks5", ErrInvalidConnection)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%w: proxy_url must include a host", ErrInvalidConnection)
+	}
+	return nil
+}
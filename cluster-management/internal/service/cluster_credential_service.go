@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/taichu-system/cluster-management/internal/model"
+	"github.com/taichu-system/cluster-management/internal/repository"
+)
+
+// clusterCredentialHistoryLimit 每个集群保留的凭据历史条数
+const clusterCredentialHistoryLimit = 10
+
+var (
+	// ErrClusterCredentialNotFound 凭据历史中没有该凭据
+	ErrClusterCredentialNotFound = errors.New("cluster credential not found")
+	// ErrCredentialValidationFailed 新凭据无法连接集群或权限不足
+	ErrCredentialValidationFailed = errors.New("credential validation failed")
+	// ErrCredentialFingerprintMismatch 新凭据指向的集群与已记录的集群指纹不一致
+	ErrCredentialFingerprintMismatch = errors.New("credential does not belong to this cluster")
+	// ErrCredentialAlreadyActive 回滚目标即为当前凭据
+	ErrCredentialAlreadyActive = errors.New("credential is already active")
+	// ErrCredentialExpired 回滚目标的 ServiceAccount 令牌已过期
+	ErrCredentialExpired = errors.New("credential token has expired")
+)
+
+// CredentialListener 集群凭据更换后的回调，用于重建 Informer 等长连接
+type CredentialListener interface {
+	ClusterCredentialsChanged(clusterID uuid.UUID)
+}
+
+// credentialListeners 凭据更换回调列表，凭据更换与令牌轮换共用
+type credentialListeners struct {
+	mu        sync.RWMutex
+	listeners []CredentialListener
+}
+
+func (l *credentialListeners) add(listener CredentialListener) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.listeners = append(l.listeners, listener)
+}
+
+func (l *credentialListeners) notify(clusterID uuid.UUID) {
+	l.mu.RLock()
+	listeners := append([]CredentialListener{}, l.listeners...)
+	l.mu.RUnlock()
+	for _, listener := range listeners {
+		listener.ClusterCredentialsChanged(clusterID)
+	}
+}
+
+// UpdateCredentialRequest 更换集群 kubeconfig 请求
+// 集群根 CA 轮换时需要 AllowCAChange，kube-system UID 不一致时始终拒绝
+type UpdateCredentialRequest struct {
+	Kubeconfig    string `json:"kubeconfig" binding:"required"`
+	Reason        string `json:"reason"`
+	AllowCAChange bool   `json:"allow_ca_change"`
+}
+
+// RollbackCredentialRequest 回滚集群凭据请求
+type RollbackCredentialRequest struct {
+	Reason        string `json:"reason"`
+	AllowCAChange bool   `json:"allow_ca_change"`
+}
+
+// CredentialUpdateResult 凭据更换结果
+type CredentialUpdateResult struct {
+	Credential  *model.ClusterCredential `json:"credential"`
+	Fingerprint *ClusterFingerprint      `json:"fingerprint,omitempty"`
+	Checks      []ValidationCheckResult  `json:"checks"`
+}
+
+// ClusterCredentialService 更换集群保存的 kubeconfig，校验集群指纹并保留凭据历史用于回滚
+type ClusterCredentialService struct {
+	clusterRepo       *repository.ClusterRepository
+	credentialRepo    *repository.ClusterCredentialRepository
+	encryptionService *EncryptionService
+	clusterManager    *ClusterManager
+	validator         *ImportValidator
+	managerAccounts   *ManagerAccountService
+	listeners         credentialListeners
+}
+
+// NewClusterCredentialService 创建集群凭据服务
+func NewClusterCredentialService(
+	clusterRepo *repository.ClusterRepository,
+	credentialRepo *repository.ClusterCredentialRepository,
+	encryptionService *EncryptionService,
+	clusterManager *ClusterManager,
+	validator *ImportValidator,
+	managerAccounts *ManagerAccountService,
+) *ClusterCredentialService {
+	return &ClusterCredentialService{
+		clusterRepo:       clusterRepo,
+		credentialRepo:    credentialRepo,
+		encryptionService: encryptionService,
+		clusterManager:    clusterManager,
+		validator:         validator,
+		managerAccounts:   managerAccounts,
+	}
+}
+
+// AddCredentialListener 注册凭据更换回调
+func (s *ClusterCredentialService) AddCredentialListener(listener CredentialListener) {
+	s.listeners.add(listener)
+}
+
+// List 获取集群的凭据历史，最新的在前
+func (s *ClusterCredentialService) List(clusterID uuid.UUID) ([]*model.ClusterCredential, error) {
+	if _, err := s.getCluster(clusterID); err != nil {
+		return nil, err
+	}
+	return s.credentialRepo.List(clusterID)
+}
+
+// Update 校验新 kubeconfig 并替换集群保存的凭据
+// 最小权限导入的集群使用新凭据重新创建 ServiceAccount，只保存其令牌
+func (s *ClusterCredentialService) Update(ctx context.Context, clusterID uuid.UUID, req UpdateCredentialRequest, user string) (*CredentialUpdateResult, error) {
+	cluster, err := s.getCluster(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	var account *model.ClusterServiceAccount
+	if _, err := s.managerAccounts.Get(clusterID); err == nil {
+		// 先确认凭据属于该集群，再在集群内创建 ServiceAccount
+		if _, err := s.verify(ctx, cluster, req.Kubeconfig, req.AllowCAChange); err != nil {
+			return nil, err
+		}
+		var kubeconfig string
+		kubeconfig, account, err = s.managerAccounts.Bootstrap(ctx, clusterID, req.Kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCredentialValidationFailed, err)
+		}
+		req.Kubeconfig = kubeconfig
+	} else if !errors.Is(err, ErrManagerAccountNotFound) {
+		return nil, fmt.Errorf("failed to get manager service account: %w", err)
+	}
+
+	result, err := s.apply(ctx, cluster, req.Kubeconfig, &model.ClusterCredential{
+		Source:    model.ClusterCredentialSourceUpdate,
+		Reason:    req.Reason,
+		CreatedBy: user,
+	}, req.AllowCAChange)
+	if err != nil {
+		return nil, err
+	}
+	// 新令牌已保存后再记录其过期时间，更换失败时保留原令牌的记录
+	if account != nil {
+		if err := s.managerAccounts.SaveAccount(account); err != nil {
+			log.Printf("Failed to record manager service account of cluster %s: %v", clusterID, err)
+		}
+	}
+	return result, nil
+}
+
+// Rollback 将集群凭据恢复为历史中的一条，恢复前同样校验连通性与集群指纹
+func (s *ClusterCredentialService) Rollback(ctx context.Context, clusterID, credentialID uuid.UUID, req RollbackCredentialRequest, user string) (*CredentialUpdateResult, error) {
+	cluster, err := s.getCluster(clusterID)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := s.credentialRepo.GetByID(clusterID, credentialID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClusterCredentialNotFound
+		}
+		return nil, fmt.Errorf("failed to get credential: %w", err)
+	}
+	if previous.Active || previous.KubeconfigEncrypted == cluster.KubeconfigEncrypted {
+		return nil, ErrCredentialAlreadyActive
+	}
+
+	kubeconfig, err := s.encryptionService.Decrypt(previous.KubeconfigEncrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt kubeconfig: %w", err)
+	}
+
+	// 最小权限集群的历史凭据为 ServiceAccount 令牌，过期后无法再轮换
+	_, accountErr := s.managerAccounts.Get(clusterID)
+	if accountErr != nil && !errors.Is(accountErr, ErrManagerAccountNotFound) {
+		return nil, fmt.Errorf("failed to get manager service account: %w", accountErr)
+	}
+	expiresAt, hasExpiry := kubeconfigTokenExpiry(kubeconfig)
+	if accountErr == nil && hasExpiry && !expiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: expired at %s", ErrCredentialExpired, expiresAt.Format(time.RFC3339))
+	}
+
+	result, err := s.apply(ctx, cluster, kubeconfig, &model.ClusterCredential{
+		Source:     model.ClusterCredentialSourceRollback,
+		RollbackOf: &previous.ID,
+		Reason:     req.Reason,
+		CreatedBy:  user,
+	}, req.AllowCAChange)
+	if err != nil {
+		return nil, err
+	}
+	if accountErr == nil && hasExpiry {
+		if err := s.managerAccounts.RecordRestoredToken(clusterID, expiresAt); err != nil {
+			log.Printf("Failed to record restored token expiry of cluster %s: %v", clusterID, err)
+		}
+	}
+	return result, nil
+}
+
+// apply 校验凭据后加密保存并写入历史，随后清理客户端缓存并通知 Informer 重建
+func (s *ClusterCredentialService) apply(ctx context.Context, cluster *model.Cluster, kubeconfig string, credential *model.ClusterCredential, allowCAChange bool) (*CredentialUpdateResult, error) {
+	report, err := s.verify(ctx, cluster, kubeconfig, allowCAChange)
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := s.encryptionService.Encrypt(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt kubeconfig: %w", err)
+	}
+	credential.ClusterID = cluster.ID
+	credential.KubeconfigEncrypted = encrypted
+	credential.APIServerURL, _ = s.clusterManager.GetAPIServerURL(kubeconfig)
+	if report.Fingerprint != nil {
+		credential.Fingerprint = report.Fingerprint.Fingerprint
+		credential.FingerprintVerified = cluster.ClusterUID != ""
+	}
+
+	previous := importedCredential(cluster, s.encryptionService, s.clusterManager)
+	if err := s.credentialRepo.Swap(cluster.KubeconfigEncrypted, previous, credential, clusterCredentialHistoryLimit); err != nil {
+		if errors.Is(err, repository.ErrClusterCredentialChanged) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to save credential: %w", err)
+	}
+
+	if fingerprint := report.Fingerprint; fingerprint != nil {
+		if err := s.clusterRepo.UpdateFingerprint(cluster.ID.String(), fingerprint.ClusterUID, fingerprint.CAHash, fingerprint.Fingerprint); err != nil {
+			log.Printf("Failed to record fingerprint of cluster %s: %v", cluster.ID, err)
+		}
+	}
+
+	s.clusterManager.InvalidateCluster(cluster.ID)
+	s.listeners.notify(cluster.ID)
+
+	return &CredentialUpdateResult{
+		Credential:  credential,
+		Fingerprint: report.Fingerprint,
+		Checks:      report.Checks,
+	}, nil
+}
+
+// verify 使用新凭据执行导入校验，并确认其指向的集群与已记录的指纹一致
+// 集群尚未记录指纹时接受新凭据，保存后的凭据 FingerprintVerified 为 false
+func (s *ClusterCredentialService) verify(ctx context.Context, cluster *model.Cluster, kubeconfig string, allowCAChange bool) (*ImportValidationReport, error) {
+	if valid, err := s.clusterManager.ValidateKubeconfig(kubeconfig); !valid {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKubeConfig, err)
+	}
+	// 候选凭据使用独立客户端，更换成功前不替换池中的客户端，也不影响集群的熔断状态
+	clientset, err := s.clusterManager.CandidateClientForCluster(cluster.ID, kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCredentialValidationFailed, err)
+	}
+
+	var extra []permissionCheck
+	if _, err := s.managerAccounts.Get(cluster.ID); err == nil {
+		extra = append(extra, s.managerAccounts.TokenPermissionCheck())
+	}
+	report := s.validator.Validate(ctx, cluster.ID, clientset, extra...)
+	// 同一集群存在其他记录不影响更换凭据
+	if err := report.Error(ValidationCheckDuplicate); err != nil {
+		return report, fmt.Errorf("%w: %v", ErrCredentialValidationFailed, err)
+	}
+
+	if cluster.ClusterUID == "" {
+		return report, nil
+	}
+	fingerprint := report.Fingerprint
+	if fingerprint == nil {
+		return report, fmt.Errorf("%w: failed to read cluster fingerprint", ErrCredentialFingerprintMismatch)
+	}
+	if fingerprint.ClusterUID != cluster.ClusterUID {
+		return report, fmt.Errorf("%w: kube-system UID %s, expected %s", ErrCredentialFingerprintMismatch, fingerprint.ClusterUID, cluster.ClusterUID)
+	}
+	if cluster.CAHash != "" && fingerprint.CAHash != "" && fingerprint.CAHash != cluster.CAHash && !allowCAChange {
+		return report, fmt.Errorf("%w: root CA changed, set allow_ca_change to accept", ErrCredentialFingerprintMismatch)
+	}
+	return report, nil
+}
+
+// importedCredential 集群当前保存的凭据，尚无历史时由 Swap 补记，保证第一次更换后也能回滚
+func importedCredential(cluster *model.Cluster, encryptionService *EncryptionService, clusterManager *ClusterManager) *model.ClusterCredential {
+	previous := &model.ClusterCredential{
+		ClusterID:           cluster.ID,
+		KubeconfigEncrypted: cluster.KubeconfigEncrypted,
+		Source:              model.ClusterCredentialSourceImport,
+		Fingerprint:         cluster.Fingerprint,
+		CreatedBy:           "system",
+	}
+	if current, err := encryptionService.Decrypt(cluster.KubeconfigEncrypted); err == nil {
+		previous.APIServerURL, _ = clusterManager.GetAPIServerURL(current)
+	}
+	return previous
+}
+
+func (s *ClusterCredentialService) getCluster(clusterID uuid.UUID) (*model.Cluster, error) {
+	cluster, err := s.clusterRepo.GetByID(clusterID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClusterNotFound
+		}
+		return nil, fmt.Errorf("failed to get cluster: %w", err)
+	}
+	return cluster, nil
+}
@@ -0,0 +1,552 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/constants"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"github.com/taichu-system/cluster-management/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrDecommissionInProgress 集群已有进行中的退役任务
+	ErrDecommissionInProgress = errors.New("cluster decommission already in progress")
+	// ErrDecommissionNotFound 退役任务或归档不存在
+	ErrDecommissionNotFound = errors.New("decommission not found")
+)
+
+// nodeCleanupCommands 节点重置后清理残留的数据目录与虚拟网卡
+var nodeCleanupCommands = []string{
+	"systemctl stop kubelet >/dev/null 2>&1 || true",
+	"for m in $(awk '$2 ~ \"^/var/lib/kubelet\" {print $2}' /proc/mounts | sort -r); do umount $m || true; done",
+	"rm -rf /etc/kubernetes /var/lib/etcd /var/lib/kubelet /var/lib/cni /etc/cni/net.d /var/lib/calico /run/calico /run/flannel $HOME/.kube",
+	"for i in cni0 flannel.1 tunl0 vxlan.calico kube-ipvs0 nodelocaldns; do ip link delete $i >/dev/null 2>&1 || true; done",
+}
+
+// DecommissionOptions 退役选项
+type DecommissionOptions struct {
+	FinalBackup   bool
+	BackupType    string
+	RetentionDays int
+	Force         bool
+	Operator      string
+}
+
+// ClusterDecommissionService 集群退役服务
+// 依次执行最终备份、驱动删除、节点数据清理、记录归档与机器回收
+type ClusterDecommissionService struct {
+	clusterRepo       *repository.ClusterRepository
+	stateRepo         *repository.ClusterStateRepository
+	nodeRepo          *repository.NodeRepository
+	backupRepo        *repository.BackupRepository
+	taskRepo          *repository.CreateTaskRepository
+	decommissionRepo  *repository.ClusterDecommissionRepository
+	archiveRepo       *repository.ClusterArchiveRepository
+	machineService    *MachineService
+	provisioners      *ProvisionerRegistry
+	backupService     *BackupService
+	clusterManager    *ClusterManager
+	encryptionService *EncryptionService
+	auditService      *AuditService
+}
+
+// NewClusterDecommissionService 创建集群退役服务
+func NewClusterDecommissionService(
+	clusterRepo *repository.ClusterRepository,
+	stateRepo *repository.ClusterStateRepository,
+	nodeRepo *repository.NodeRepository,
+	backupRepo *repository.BackupRepository,
+	taskRepo *repository.CreateTaskRepository,
+	decommissionRepo *repository.ClusterDecommissionRepository,
+	archiveRepo *repository.ClusterArchiveRepository,
+	machineService *MachineService,
+	provisioners *ProvisionerRegistry,
+	backupService *BackupService,
+	clusterManager *ClusterManager,
+	encryptionService *EncryptionService,
+	auditService *AuditService,
+) *ClusterDecommissionService {
+	return &ClusterDecommissionService{
+		clusterRepo:       clusterRepo,
+		stateRepo:         stateRepo,
+		nodeRepo:          nodeRepo,
+		backupRepo:        backupRepo,
+		taskRepo:          taskRepo,
+		decommissionRepo:  decommissionRepo,
+		archiveRepo:       archiveRepo,
+		machineService:    machineService,
+		provisioners:      provisioners,
+		backupService:     backupService,
+		clusterManager:    clusterManager,
+		encryptionService: encryptionService,
+		auditService:      auditService,
+	}
+}
+
+// StartDecommission 创建退役任务并异步执行
+func (s *ClusterDecommissionService) StartDecommission(clusterID uuid.UUID, opts DecommissionOptions) (*model.ClusterDecommission, error) {
+	cluster, err := s.clusterRepo.GetByID(clusterID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClusterNotFound
+		}
+		return nil, err
+	}
+
+	active, err := s.decommissionRepo.ExistsActive(clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if active {
+		return nil, ErrDecommissionInProgress
+	}
+
+	machines, err := s.machineService.GetMachinesByCluster(clusterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster machines: %w", err)
+	}
+	for _, machine := range machines {
+		if err := s.machineService.ValidateMachineAuth(machine); err != nil {
+			return nil, err
+		}
+	}
+
+	driver := s.resolveProvisioner(cluster)
+	if len(machines) > 0 {
+		if _, err := s.provisioners.Get(driver); err != nil {
+			return nil, err
+		}
+	}
+
+	machineIDs := make([]uuid.UUID, 0, len(machines))
+	for _, machine := range machines {
+		machineIDs = append(machineIDs, machine.ID)
+	}
+
+	decommission := &model.ClusterDecommission{
+		ClusterID:   cluster.ID,
+		ClusterName: cluster.Name,
+		Provisioner: driver,
+		FinalBackup: opts.FinalBackup,
+		Force:       opts.Force,
+		MachineIDs:  convertUUIDsToJSONMap(machineIDs),
+		Status:      constants.StatusPending,
+		CurrentStep: "Waiting to start",
+		CreatedBy:   opts.Operator,
+	}
+	if err := s.decommissionRepo.Create(decommission); err != nil {
+		// 并发请求都通过了 ExistsActive 检查，由唯一索引保证只创建一个
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrDecommissionInProgress
+		}
+		return nil, fmt.Errorf("failed to create decommission task: %w", err)
+	}
+
+	go s.executeDecommission(decommission.ID, opts)
+
+	return decommission, nil
+}
+
+// RecoverInterrupted 服务启动时将中断的退役任务标记为失败，节点与机器保持中断时的状态，可重新发起退役
+func (s *ClusterDecommissionService) RecoverInterrupted() (int64, error) {
+	return s.decommissionRepo.MarkInterrupted()
+}
+
+// GetDecommission 获取退役任务
+func (s *ClusterDecommissionService) GetDecommission(id uuid.UUID) (*model.ClusterDecommission, error) {
+	decommission, err := s.decommissionRepo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDecommissionNotFound
+	}
+	return decommission, err
+}
+
+// GetLatestDecommission 获取集群最近一次退役任务，集群归档后仍可查询
+func (s *ClusterDecommissionService) GetLatestDecommission(clusterID uuid.UUID) (*model.ClusterDecommission, error) {
+	decommission, err := s.decommissionRepo.GetLatestByClusterID(clusterID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDecommissionNotFound
+	}
+	return decommission, err
+}
+
+// ListDecommissions 获取退役任务列表
+func (s *ClusterDecommissionService) ListDecommissions(page, limit int) ([]*model.ClusterDecommission, int64, error) {
+	return s.decommissionRepo.List(page, limit)
+}
+
+// GetArchive 获取集群归档
+func (s *ClusterDecommissionService) GetArchive(id uuid.UUID) (*model.ClusterArchive, error) {
+	archive, err := s.archiveRepo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDecommissionNotFound
+	}
+	return archive, err
+}
+
+// ListArchives 获取集群归档列表
+func (s *ClusterDecommissionService) ListArchives(page, limit int, name string) ([]*model.ClusterArchive, int64, error) {
+	return s.archiveRepo.List(page, limit, name)
+}
+
+// executeDecommission 执行退役任务（异步）
+func (s *ClusterDecommissionService) executeDecommission(id uuid.UUID, opts DecommissionOptions) {
+	decommission, err := s.decommissionRepo.GetByID(id)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	s.decommissionRepo.UpdateFields(id, map[string]interface{}{
+		"status":       constants.StatusRunning,
+		"current_step": "Starting decommission",
+		"started_at":   &now,
+	})
+
+	err = s.runDecommission(decommission, opts)
+
+	completedAt := time.Now()
+	fields := map[string]interface{}{
+		"completed_at": &completedAt,
+	}
+	result := constants.StatusSuccess
+	if err != nil {
+		result = constants.StatusFailed
+		fields["status"] = constants.StatusFailed
+		fields["current_step"] = fmt.Sprintf("Decommission failed: %v", err)
+		fields["error_msg"] = err.Error()
+		s.logLine(id, err.Error(), true)
+	} else {
+		fields["status"] = constants.StatusSuccess
+		fields["progress"] = 100
+		fields["current_step"] = "Cluster decommissioned"
+	}
+	s.decommissionRepo.UpdateFields(id, fields)
+
+	if s.auditService != nil {
+		details := map[string]interface{}{
+			"decommission_id": id.String(),
+			"name":            decommission.ClusterName,
+			"final_backup":    decommission.FinalBackup,
+			"force":           decommission.Force,
+		}
+		if err != nil {
+			details["error"] = err.Error()
+		}
+		s.auditService.CreateAuditEvent(
+			decommission.ClusterID,
+			constants.EventTypeDelete,
+			"decommission_cluster",
+			constants.ResourceTypeCluster,
+			decommission.ClusterID.String(),
+			decommission.CreatedBy,
+			"",
+			"",
+			nil,
+			nil,
+			details,
+			result,
+		)
+	}
+}
+
+// runDecommission 按步骤退役集群，force 时节点侧失败不阻断回收与归档
+func (s *ClusterDecommissionService) runDecommission(decommission *model.ClusterDecommission, opts DecommissionOptions) error {
+	cluster, err := s.clusterRepo.GetByID(decommission.ClusterID.String())
+	if err != nil {
+		return fmt.Errorf("failed to get cluster: %w", err)
+	}
+
+	// 1. 最终备份
+	if decommission.FinalBackup {
+		s.progress(decommission.ID, 5, "Creating final backup")
+		if err := s.finalBackup(decommission, cluster, opts); err != nil {
+			if !decommission.Force {
+				return fmt.Errorf("final backup failed: %w", err)
+			}
+			s.logLine(decommission.ID, fmt.Sprintf("final backup failed, continuing because force is set: %v", err), true)
+		}
+	}
+
+	machineIDs, err := parseJSONMapUUIDs(decommission.MachineIDs)
+	if err != nil {
+		return err
+	}
+	machines, err := s.machineService.GetMachinesByIDs(machineIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get machines: %w", err)
+	}
+
+	if len(machines) > 0 {
+		// 2. 通过驱动删除集群
+		s.progress(decommission.ID, 10, fmt.Sprintf("Deleting cluster with %s", decommission.Provisioner))
+		if err := s.deleteWithProvisioner(decommission, cluster, machines); err != nil {
+			if !decommission.Force {
+				return fmt.Errorf("provisioner delete failed: %w", err)
+			}
+			s.logLine(decommission.ID, fmt.Sprintf("provisioner delete failed, continuing because force is set: %v", err), true)
+		}
+
+		// 3. 清理节点数据目录
+		s.progress(decommission.ID, 70, "Cleaning node data directories")
+		if err := s.cleanupNodes(decommission.ID, machines); err != nil {
+			if !decommission.Force {
+				return err
+			}
+			s.logLine(decommission.ID, fmt.Sprintf("%v, continuing because force is set", err), true)
+		}
+	} else {
+		s.logLine(decommission.ID, "no platform machines bound to cluster, skipping node reset", false)
+	}
+
+	// 4. 归档集群记录
+	s.progress(decommission.ID, 85, "Archiving cluster records")
+	archive, err := s.buildArchive(decommission, cluster, machines)
+	if err != nil {
+		return fmt.Errorf("failed to build archive: %w", err)
+	}
+	if err := s.clusterRepo.ArchiveAndDelete(cluster.ID.String(), archive); err != nil {
+		return fmt.Errorf("failed to archive cluster: %w", err)
+	}
+	s.decommissionRepo.UpdateFields(decommission.ID, map[string]interface{}{"archive_id": archive.ID})
+
+	if kubeconfig, err := s.encryptionService.Decrypt(cluster.KubeconfigEncrypted); err == nil {
+		s.clusterManager.RemoveClient(kubeconfig)
+	}
+
+	// 5. 机器放回可用池
+	if len(machineIDs) > 0 {
+		s.progress(decommission.ID, 95, "Releasing machines")
+		released, err := s.machineService.ReleaseMachines(machineIDs)
+		if err != nil {
+			return fmt.Errorf("failed to release machines: %w", err)
+		}
+		s.logLine(decommission.ID, fmt.Sprintf("%d machines released to pool", released), false)
+	}
+
+	return nil
+}
+
+// finalBackup 同步执行最终备份
+func (s *ClusterDecommissionService) finalBackup(decommission *model.ClusterDecommission, cluster *model.Cluster, opts DecommissionOptions) error {
+	backupType := opts.BackupType
+	if backupType == "" {
+		backupType = constants.BackupTypeFull
+	}
+	retentionDays := opts.RetentionDays
+	if retentionDays <= 0 {
+		retentionDays = 30
+	}
+
+	name := fmt.Sprintf("decommission-%s-%s", cluster.Name, time.Now().Format("20060102150405"))
+	backup, err := s.backupService.CreateBackup(cluster.ID.String(), name, backupType, retentionDays)
+	if err != nil {
+		return err
+	}
+	s.decommissionRepo.UpdateFields(decommission.ID, map[string]interface{}{"backup_id": backup.ID})
+	s.logLine(decommission.ID, fmt.Sprintf("final backup %s started", backup.ID), false)
+
+	if err := s.backupService.ExecuteBackup(backup.ID.String()); err != nil {
+		return err
+	}
+	s.logLine(decommission.ID, fmt.Sprintf("final backup %s completed", backup.ID), false)
+	return nil
+}
+
+// deleteWithProvisioner 使用创建集群时的驱动删除集群并重置节点
+func (s *ClusterDecommissionService) deleteWithProvisioner(decommission *model.ClusterDecommission, cluster *model.Cluster, machines []*model.Machine) error {
+	provisioner, err := s.provisioners.Get(decommission.Provisioner)
+	if err != nil {
+		return err
+	}
+
+	creds := make(map[string]*SSHAuth, len(machines))
+	for _, machine := range machines {
+		auth, err := s.machineService.ResolveSSHAuth(machine)
+		if err != nil {
+			return err
+		}
+		creds[machine.Name] = auth
+	}
+
+	op := &ProvisionOperation{
+		ClusterName: cluster.Name,
+		Machines:    machines,
+		Credentials: creds,
+		AutoApprove: true,
+		Reporter:    &decommissionReporter{repo: s.decommissionRepo, id: decommission.ID, from: 10, to: 70},
+	}
+
+	// 优先使用创建时的配置，历史集群按当前机器重新渲染
+	if task, err := s.taskRepo.GetLatestByClusterID(cluster.ID); err == nil {
+		op.ConfigYaml = task.ConfigYaml
+		op.NetworkPlugin = task.NetworkPlugin
+		op.ArtifactPath = task.ArtifactPath
+	} else {
+		configYaml, err := provisioner.RenderConfig(CreateClusterRequest{
+			ClusterName: cluster.Name,
+			Kubernetes:  KubernetesConfig{Version: cluster.Version},
+		}, machines)
+		if err != nil {
+			return fmt.Errorf("failed to render config: %w", err)
+		}
+		op.ConfigYaml = configYaml
+	}
+
+	return provisioner.Delete(context.Background(), op)
+}
+
+// cleanupNodes 逐节点清理数据目录，返回汇总错误
+func (s *ClusterDecommissionService) cleanupNodes(id uuid.UUID, machines []*model.Machine) error {
+	var failed []string
+	for _, machine := range machines {
+		if err := s.cleanupNode(id, machine); err != nil {
+			s.logLine(id, err.Error(), true)
+			failed = append(failed, machine.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to clean data dirs on: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// cleanupNode 清理单个节点
+func (s *ClusterDecommissionService) cleanupNode(id uuid.UUID, machine *model.Machine) error {
+	auth, err := s.machineService.ResolveSSHAuth(machine)
+	if err != nil {
+		return err
+	}
+	client, err := s.machineService.ConnectMachine(machine)
+	if err != nil {
+		return fmt.Errorf("failed to connect %s: %w", machine.Name, err)
+	}
+	defer client.Close()
+
+	for _, cmd := range nodeCleanupCommands {
+		if auth.Username != "root" {
+			cmd = "sudo -n sh -c " + shellQuote(cmd)
+		}
+		if out, err := client.ExecuteCommand(cmd); err != nil {
+			return fmt.Errorf("cleanup on %s failed: %v: %s", machine.Name, err, strings.TrimSpace(out))
+		}
+	}
+	s.logLine(id, fmt.Sprintf("[%s] data directories cleaned", machine.Name), false)
+	return nil
+}
+
+// buildArchive 生成集群归档快照，不包含 kubeconfig 等敏感信息
+func (s *ClusterDecommissionService) buildArchive(decommission *model.ClusterDecommission, cluster *model.Cluster, machines []*model.Machine) (*model.ClusterArchive, error) {
+	snapshot := model.JSONMap{
+		"cluster":         toJSONValue(cluster),
+		"decommission_id": decommission.ID.String(),
+	}
+
+	if state, err := s.stateRepo.GetByClusterID(cluster.ID.String()); err == nil {
+		snapshot["state"] = toJSONValue(state)
+	}
+	if nodes, err := s.nodeRepo.GetByClusterID(cluster.ID.String()); err == nil {
+		snapshot["nodes"] = toJSONValue(nodes)
+	}
+	// 备份记录随集群级联删除，归档中保留存储位置以便恢复
+	if backups, _, err := s.backupRepo.List(cluster.ID.String(), "", 1, 1000); err == nil {
+		snapshot["backups"] = toJSONValue(backups)
+	}
+	if task, err := s.taskRepo.GetLatestByClusterID(cluster.ID); err == nil {
+		snapshot["create_task_id"] = task.ID.String()
+	}
+
+	machineInfo := make([]map[string]interface{}, 0, len(machines))
+	for _, machine := range machines {
+		machineInfo = append(machineInfo, map[string]interface{}{
+			"id":         machine.ID.String(),
+			"name":       machine.Name,
+			"ip_address": machine.IPAddress,
+			"role":       machine.Role,
+		})
+	}
+	snapshot["machines"] = toJSONValue(machineInfo)
+
+	return &model.ClusterArchive{
+		ClusterID:      cluster.ID,
+		ClusterName:    cluster.Name,
+		DecommissionID: &decommission.ID,
+		Snapshot:       snapshot,
+		ArchivedBy:     decommission.CreatedBy,
+	}, nil
+}
+
+// resolveProvisioner 确定集群使用的驱动，导入的集群没有创建任务时使用默认驱动
+func (s *ClusterDecommissionService) resolveProvisioner(cluster *model.Cluster) string {
+	if task, err := s.taskRepo.GetLatestByClusterID(cluster.ID); err == nil && task.Provisioner != "" {
+		return task.Provisioner
+	}
+	if driver, ok := cluster.Labels["provisioner"].(string); ok && driver != "" {
+		return driver
+	}
+	return s.provisioners.DefaultDriver()
+}
+
+// progress 更新进度
+func (s *ClusterDecommissionService) progress(id uuid.UUID, progress int, step string) {
+	s.decommissionRepo.UpdateFields(id, map[string]interface{}{
+		"progress":     progress,
+		"current_step": step,
+	})
+	s.logLine(id, step, false)
+}
+
+// logLine 追加带时间戳的日志
+func (s *ClusterDecommissionService) logLine(id uuid.UUID, line string, isError bool) {
+	appendTimestampedLog(func(log string) { s.decommissionRepo.AppendLogs(id, log) }, line, isError)
+}
+
+// decommissionReporter 将驱动进度映射到退役任务的进度区间
+type decommissionReporter struct {
+	repo     *repository.ClusterDecommissionRepository
+	id       uuid.UUID
+	from, to int
+}
+
+// Log 追加日志
+func (r *decommissionReporter) Log(line string, isError bool) {
+	appendTimestampedLog(func(log string) { r.repo.AppendLogs(r.id, log) }, line, isError)
+}
+
+// Progress 更新进度
+func (r *decommissionReporter) Progress(progress int, step string) {
+	r.repo.UpdateFields(r.id, map[string]interface{}{
+		"progress":     r.from + progress*(r.to-r.from)/100,
+		"current_step": step,
+	})
+}
+
+// appendTimestampedLog 生成带时间戳的日志行
+func appendTimestampedLog(appendFn func(string), line string, isError bool) {
+	timestamp := time.Now().Format("2006-01-02 15:04:05")
+	if isError {
+		appendFn(fmt.Sprintf("[%s] ERROR: %s\n", timestamp, line))
+		return
+	}
+	appendFn(fmt.Sprintf("[%s] %s\n", timestamp, line))
+}
+
+// toJSONValue 将结构体转换为可存入 JSONMap 的通用值
+func toJSONValue(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var result interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil
+	}
+	return result
+}
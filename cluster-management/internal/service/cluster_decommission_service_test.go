@@ -0,0 +1,112 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/constants"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"github.com/taichu-system/cluster-management/internal/repository"
+	"github.com/taichu-system/cluster-management/internal/testutil"
+)
+
+func TestDecommissionRecoverInterruptedUnblocksCluster(t *testing.T) {
+	db := testutil.OpenDB(t,
+		&model.Cluster{}, &model.ClusterState{}, &model.Node{}, &model.ClusterBackup{}, &model.CreateTask{},
+		&model.ClusterDecommission{}, &model.ClusterArchive{}, &model.Machine{}, &model.MachineCredential{},
+		&model.ClusterResource{}, &model.Environment{}, &model.Application{}, &model.ApplicationResourceSpec{}, &model.ResourceQuota{},
+	)
+	// 与迁移 048 相同的唯一索引
+	if err := db.Exec(`CREATE UNIQUE INDEX uq_cluster_decommissions_active ON cluster_decommissions(cluster_id)
+		WHERE status IN ('pending', 'running')`).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	encryption, err := NewEncryptionService("test-encryption-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kubeconfig, err := encryption.Encrypt("apiVersion: v1\nkind: Config\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clusterRepo := repository.NewClusterRepository(db)
+	cluster := &model.Cluster{ID: uuid.New(), Name: "decommission-" + uuid.NewString()[:8], KubeconfigEncrypted: kubeconfig}
+	if err := clusterRepo.Create(cluster); err != nil {
+		t.Fatal(err)
+	}
+
+	decommissionRepo := repository.NewClusterDecommissionRepository(db)
+	service := NewClusterDecommissionService(
+		clusterRepo,
+		repository.NewClusterStateRepository(db),
+		repository.NewNodeRepository(db),
+		repository.NewBackupRepository(db),
+		repository.NewCreateTaskRepository(db),
+		decommissionRepo,
+		repository.NewClusterArchiveRepository(db),
+		NewMachineService(repository.NewMachineRepository(db), repository.NewMachineCredentialRepository(db), encryption),
+		NewProvisionerRegistry(ProvisionerFake, NewFakeProvisioner()),
+		nil,
+		NewClusterManager(time.Second, 10),
+		encryption,
+		nil,
+	)
+
+	// 服务重启前正在执行的退役任务
+	interrupted := &model.ClusterDecommission{
+		ID:          uuid.New(),
+		ClusterID:   cluster.ID,
+		ClusterName: cluster.Name,
+		Status:      constants.StatusRunning,
+		CurrentStep: "Cleaning node data directories",
+	}
+	if err := decommissionRepo.Create(interrupted); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.StartDecommission(cluster.ID, DecommissionOptions{Operator: "test"}); !errors.Is(err, ErrDecommissionInProgress) {
+		t.Fatalf("StartDecommission before recovery error = %v, want ErrDecommissionInProgress", err)
+	}
+
+	n, err := service.RecoverInterrupted()
+	if err != nil {
+		t.Fatalf("RecoverInterrupted failed: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("RecoverInterrupted updated %d tasks, want 1", n)
+	}
+	recovered, err := service.GetDecommission(interrupted.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recovered.Status != constants.StatusFailed {
+		t.Errorf("interrupted task status = %q, want %q", recovered.Status, constants.StatusFailed)
+	}
+
+	decommission, err := service.StartDecommission(cluster.ID, DecommissionOptions{Operator: "test"})
+	if err != nil {
+		t.Fatalf("StartDecommission after recovery failed: %v", err)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		decommission, err = service.GetDecommission(decommission.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decommission.Status == constants.StatusSuccess || decommission.Status == constants.StatusFailed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("decommission %s did not finish in time", decommission.ID)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if decommission.Status != constants.StatusSuccess {
+		t.Fatalf("decommission status = %q (%s), want %q", decommission.Status, decommission.ErrorMsg, constants.StatusSuccess)
+	}
+	if _, err := clusterRepo.GetByID(cluster.ID.String()); err == nil {
+		t.Error("decommissioned cluster was not archived and deleted")
+	}
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/taichu-system/cluster-management/internal/model"
+	"github.com/taichu-system/cluster-management/internal/repository"
+)
+
+// 导入时发现重复集群的处理方式
+const (
+	// DuplicateActionReject 重复集群导入失败
+	DuplicateActionReject = "reject"
+	// DuplicateActionMerge 不创建新集群，标签合并到已导入的集群
+	DuplicateActionMerge = "merge"
+)
+
+const (
+	// rootCAConfigMap 各命名空间自动发布的集群根 CA
+	rootCAConfigMap       = "kube-root-ca.crt"
+	fingerprintRefreshMax = 5
+)
+
+// ClusterFingerprint 集群身份：kube-system 命名空间 UID 与根 CA 证书哈希
+type ClusterFingerprint struct {
+	ClusterUID  string `json:"cluster_uid"`
+	CAHash      string `json:"ca_hash,omitempty"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// ReadClusterFingerprint 读取集群指纹，根 CA 取自 kube-system/kube-root-ca.crt，读取失败时只使用 UID
+func ReadClusterFingerprint(ctx context.Context, clientset kubernetes.Interface) (*ClusterFingerprint, error) {
+	namespace, err := clientset.CoreV1().Namespaces().Get(ctx, metav1.NamespaceSystem, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kube-system namespace: %w", err)
+	}
+	fingerprint := &ClusterFingerprint{ClusterUID: string(namespace.UID)}
+
+	if configMap, err := clientset.CoreV1().ConfigMaps(metav1.NamespaceSystem).Get(ctx, rootCAConfigMap, metav1.GetOptions{}); err == nil {
+		if ca := configMap.Data["ca.crt"]; ca != "" {
+			sum := sha256.Sum256([]byte(ca))
+			fingerprint.CAHash = hex.EncodeToString(sum[:])
+		}
+	}
+
+	sum := sha256.Sum256([]byte(fingerprint.ClusterUID + "/" + fingerprint.CAHash))
+	fingerprint.Fingerprint = hex.EncodeToString(sum[:])
+	return fingerprint, nil
+}
+
+// DuplicateClusterEntry 重复集群报告中的集群
+type DuplicateClusterEntry struct {
+	ID           uuid.UUID `json:"id"`
+	Name         string    `json:"name"`
+	ImportSource string    `json:"import_source"`
+	CAHash       string    `json:"ca_hash,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// DuplicateClusterGroup 指向同一集群的多条集群记录，按创建时间排序
+type DuplicateClusterGroup struct {
+	ClusterUID string                  `json:"cluster_uid"`
+	Clusters   []DuplicateClusterEntry `json:"clusters"`
+}
+
+// UnfingerprintedCluster 无法读取指纹的集群
+type UnfingerprintedCluster struct {
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Error string    `json:"error"`
+}
+
+// DuplicateClusterReport 重复集群报告
+type DuplicateClusterReport struct {
+	Groups          []DuplicateClusterGroup  `json:"groups"`
+	Unfingerprinted []UnfingerprintedCluster `json:"unfingerprinted"`
+	GeneratedAt     time.Time                `json:"generated_at"`
+}
+
+// ClusterFingerprintService 记录集群指纹并识别重复导入的集群
+type ClusterFingerprintService struct {
+	clusterRepo       *repository.ClusterRepository
+	encryptionService *EncryptionService
+	clusterManager    *ClusterManager
+}
+
+// NewClusterFingerprintService 创建集群指纹服务
+func NewClusterFingerprintService(clusterRepo *repository.ClusterRepository, encryptionService *EncryptionService, clusterManager *ClusterManager) *ClusterFingerprintService {
+	return &ClusterFingerprintService{
+		clusterRepo:       clusterRepo,
+		encryptionService: encryptionService,
+		clusterManager:    clusterManager,
+	}
+}
+
+// RefreshByID 重新读取并记录集群指纹，同时返回与其重复的集群
+func (s *ClusterFingerprintService) RefreshByID(ctx context.Context, clusterID uuid.UUID) (*ClusterFingerprint, []*model.Cluster, error) {
+	cluster, err := s.clusterRepo.GetByID(clusterID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrClusterNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get cluster: %w", err)
+	}
+	fingerprint, err := s.Refresh(ctx, cluster)
+	if err != nil {
+		return nil, nil, err
+	}
+	duplicates, err := s.clusterRepo.FindDuplicates(fingerprint.ClusterUID, fingerprint.CAHash, cluster.ID.String())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to look up clusters: %w", err)
+	}
+	return fingerprint, duplicates, nil
+}
+
+// Refresh 连接集群读取并记录指纹
+func (s *ClusterFingerprintService) Refresh(ctx context.Context, cluster *model.Cluster) (*ClusterFingerprint, error) {
+	kubeconfig, err := s.encryptionService.Decrypt(cluster.KubeconfigEncrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt kubeconfig: %w", err)
+	}
+	clientset, err := s.clusterManager.GetClientForCluster(ctx, cluster.ID, kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+	fingerprint, err := ReadClusterFingerprint(ctx, clientset)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.clusterRepo.UpdateFingerprint(cluster.ID.String(), fingerprint.ClusterUID, fingerprint.CAHash, fingerprint.Fingerprint); err != nil {
+		return nil, fmt.Errorf("failed to record fingerprint: %w", err)
+	}
+	cluster.ClusterUID = fingerprint.ClusterUID
+	cluster.CAHash = fingerprint.CAHash
+	cluster.Fingerprint = fingerprint.Fingerprint
+	return fingerprint, nil
+}
+
+// DuplicateReport 按指纹分组列出重复的集群，refresh 为 true 时先为尚未记录指纹的集群读取指纹
+func (s *ClusterFingerprintService) DuplicateReport(ctx context.Context, refresh bool) (*DuplicateClusterReport, error) {
+	report := &DuplicateClusterReport{
+		Groups:          []DuplicateClusterGroup{},
+		Unfingerprinted: []UnfingerprintedCluster{},
+		GeneratedAt:     time.Now(),
+	}
+
+	if refresh {
+		missing, err := s.clusterRepo.FindWithoutFingerprint()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list clusters: %w", err)
+		}
+		report.Unfingerprinted = s.refreshAll(ctx, missing)
+	}
+
+	clusters, err := s.clusterRepo.FindActiveClusters()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clusters: %w", err)
+	}
+	report.Groups = groupDuplicateClusters(clusters)
+	return report, nil
+}
+
+// refreshAll 并发读取指纹，返回读取失败的集群
+func (s *ClusterFingerprintService) refreshAll(ctx context.Context, clusters []*model.Cluster) []UnfingerprintedCluster {
+	failed := []UnfingerprintedCluster{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, fingerprintRefreshMax)
+	for _, cluster := range clusters {
+		wg.Add(1)
+		go func(cluster *model.Cluster) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			if _, err := s.Refresh(ctx, cluster); err != nil {
+				log.Printf("Failed to read fingerprint of cluster %s: %v", cluster.ID, err)
+				mu.Lock()
+				failed = append(failed, UnfingerprintedCluster{ID: cluster.ID, Name: cluster.Name, Error: err.Error()})
+				mu.Unlock()
+			}
+		}(cluster)
+	}
+	wg.Wait()
+	sort.Slice(failed, func(i, j int) bool { return failed[i].Name < failed[j].Name })
+	return failed
+}
+
+// groupDuplicateClusters 按 kube-system UID 与根 CA 哈希分组，CA 哈希未知的集群归入同 UID 的每个分组
+func groupDuplicateClusters(clusters []*model.Cluster) []DuplicateClusterGroup {
+	byUID := map[string][]*model.Cluster{}
+	for _, cluster := range clusters {
+		if cluster.ClusterUID != "" {
+			byUID[cluster.ClusterUID] = append(byUID[cluster.ClusterUID], cluster)
+		}
+	}
+
+	groups := []DuplicateClusterGroup{}
+	for clusterUID, members := range byUID {
+		byCA := map[string][]*model.Cluster{}
+		var unknownCA []*model.Cluster
+		for _, cluster := range members {
+			if cluster.CAHash == "" {
+				unknownCA = append(unknownCA, cluster)
+				continue
+			}
+			byCA[cluster.CAHash] = append(byCA[cluster.CAHash], cluster)
+		}
+		if len(byCA) == 0 {
+			byCA[""] = nil
+		}
+		for _, sameCA := range byCA {
+			group := append(append([]*model.Cluster{}, sameCA...), unknownCA...)
+			if len(group) < 2 {
+				continue
+			}
+			sort.Slice(group, func(i, j int) bool { return group[i].CreatedAt.Before(group[j].CreatedAt) })
+			entries := make([]DuplicateClusterEntry, 0, len(group))
+			for _, cluster := range group {
+				entries = append(entries, DuplicateClusterEntry{
+					ID:           cluster.ID,
+					Name:         cluster.Name,
+					ImportSource: cluster.ImportSource,
+					CAHash:       cluster.CAHash,
+					CreatedAt:    cluster.CreatedAt,
+				})
+			}
+			groups = append(groups, DuplicateClusterGroup{ClusterUID: clusterUID, Clusters: entries})
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Clusters[0].CreatedAt.Before(groups[j].Clusters[0].CreatedAt) })
+	return groups
+}
@@ -5,25 +5,83 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/transport"
+	"k8s.io/client-go/util/flowcontrol"
 )
 
+// directClientPrefix 未纳管集群的客户端按 kubeconfig 哈希缓存
+const directClientPrefix = "kubeconfig/"
+
+// ClusterManager 集群客户端池，按集群 ID 缓存客户端，并为每个集群维护限流器与熔断器
 type ClusterManager struct {
+	// clients 按集群 ID 缓存的客户端，凭据或连接设置变化时重建
 	clients map[string]*ClusterClient
-	mutex   sync.RWMutex
-	timeout time.Duration
+	// inflight 正在创建的客户端，同一集群的并发请求只创建一次
+	inflight map[string]*clientCall
+	// clusters 每个集群共享的限流器与熔断器
+	clusters   map[uuid.UUID]*clusterRuntime
+	mutex      sync.RWMutex
+	timeout    time.Duration
 	maxClients int
+	// idleTTL 客户端空闲超过该时长后由定期清理移除
+	idleTTL time.Duration
+	qps     float32
+	burst   int
+
+	breakerThreshold   int
+	breakerBaseBackoff time.Duration
+	breakerMaxBackoff  time.Duration
+
+	// connections 提供集群的堡垒机、代理与 TLS ServerName 设置，未设置时直连
+	connections ConnectionResolver
+	// transports API 代理使用的 HTTP 传输层，与客户端使用相同的缓存键
+	transports map[string]*clusterTransport
+	stopCh     chan struct{}
+	stopOnce   sync.Once
+}
+
+// clusterTransport 已应用认证与连接设置的 apiserver 传输层
+type clusterTransport struct {
+	roundTripper http.RoundTripper
+	// upgradeRoundTripper 只协商 HTTP/1.1，用于 exec/attach/port-forward 等协议升级请求
+	upgradeRoundTripper http.RoundTripper
+	host                *url.URL
+	credentialKey string
+	lastUsed      time.Time
+}
+
+// clusterRuntime 集群的所有客户端与 Informer 共享的限流器与熔断器
+type clusterRuntime struct {
+	limiter flowcontrol.RateLimiter
+	breaker *circuitBreaker
+}
+
+// clientCall 正在创建的客户端，等待方在 done 关闭后读取结果
+type clientCall struct {
+	done      chan struct{}
+	clientset *kubernetes.Clientset
+	err       error
 }
 
 type ClusterClient struct {
 	Clientset *kubernetes.Clientset
 	LastUsed  time.Time
+	// credentialKey kubeconfig 哈希与连接设置版本
+	credentialKey string
 }
 
 type HealthCheckResult struct {
@@ -38,27 +96,254 @@ type HealthCheckResult struct {
 	APIServerURL      string    `json:"api_server_url"`
 	LastHeartbeatAt   time.Time `json:"last_heartbeat_at"`
 	Error             string    `json:"error,omitempty"`
+	Components        []ComponentHealth `json:"components,omitempty"`
 }
 
 func NewClusterManager(timeout time.Duration, maxClients int) *ClusterManager {
 	return &ClusterManager{
-		clients:   make(map[string]*ClusterClient),
-		timeout:   timeout,
-		maxClients: maxClients,
+		clients:            make(map[string]*ClusterClient),
+		inflight:           make(map[string]*clientCall),
+		clusters:           make(map[uuid.UUID]*clusterRuntime),
+		transports:         make(map[string]*clusterTransport),
+		timeout:            timeout,
+		maxClients:         maxClients,
+		breakerThreshold:   defaultBreakerFailureThreshold,
+		breakerBaseBackoff: defaultBreakerBaseBackoff,
+		breakerMaxBackoff:  defaultBreakerMaxBackoff,
+		stopCh:             make(chan struct{}),
 	}
 }
 
+// SetConnectionResolver 设置集群连接方式解析器
+func (cm *ClusterManager) SetConnectionResolver(resolver ConnectionResolver) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+	cm.connections = resolver
+}
+
+// SetRateLimits 设置每个集群的 QPS 与 Burst，同一集群的客户端与 Informer 共享限额；qps 不大于 0 时使用 client-go 默认值
+func (cm *ClusterManager) SetRateLimits(qps float32, burst int) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+	cm.qps = qps
+	cm.burst = burst
+}
+
+// SetCircuitBreaker 设置熔断阈值与熔断时长，连续失败 threshold 次后熔断 baseBackoff，之后每次熔断时长加倍，最长 maxBackoff
+func (cm *ClusterManager) SetCircuitBreaker(threshold int, baseBackoff, maxBackoff time.Duration) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+	if threshold > 0 {
+		cm.breakerThreshold = threshold
+	}
+	if baseBackoff > 0 {
+		cm.breakerBaseBackoff = baseBackoff
+	}
+	if maxBackoff > 0 {
+		cm.breakerMaxBackoff = maxBackoff
+	}
+}
+
+// StartCleanup 按 interval 定期移除空闲超过 interval 的客户端与传输层
+func (cm *ClusterManager) StartCleanup(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	cm.mutex.Lock()
+	cm.idleTTL = interval
+	cm.mutex.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-cm.stopCh:
+				return
+			case <-ticker.C:
+				if evicted := cm.evictIdle(); evicted > 0 {
+					log.Printf("Evicted %d idle cluster clients", evicted)
+				}
+			}
+		}
+	}()
+}
+
+// Stop 停止定期清理
+func (cm *ClusterManager) Stop() {
+	cm.stopOnce.Do(func() { close(cm.stopCh) })
+}
+
+// GetClient 使用 kubeconfig 直连，用于尚未纳管的集群（如导入前校验）
 func (cm *ClusterManager) GetClient(ctx context.Context, kubeconfig string) (*kubernetes.Clientset, error) {
+	hash := cm.generateKubeconfigHash(kubeconfig)
+	return cm.getClient(ctx, directClientPrefix+hash, hash, func() (*rest.Config, error) {
+		config, err := cm.buildRESTConfig(kubeconfig, nil)
+		if err != nil {
+			return nil, err
+		}
+		config.QPS, config.Burst = cm.rateLimits()
+		return config, nil
+	})
+}
+
+// GetClientForCluster 获取已纳管集群的客户端，应用集群的连接设置、限流与熔断统计
+// 不检查熔断状态，后台任务应先调用 AllowRequest
+func (cm *ClusterManager) GetClientForCluster(ctx context.Context, clusterID uuid.UUID, kubeconfig string) (*kubernetes.Clientset, error) {
+	credentialKey, connection, err := cm.clusterCredentialKey(clusterID, kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	return cm.getClient(ctx, clusterID.String(), credentialKey, func() (*rest.Config, error) {
+		return cm.clusterRESTConfig(clusterID, kubeconfig, connection)
+	})
+}
+
+// CandidateClientForCluster 为待校验的凭据创建独立客户端，应用集群的连接设置
+// 客户端不放入池中，不共享集群的限流器，请求结果不计入熔断统计，校验失败不影响正在使用的凭据
+func (cm *ClusterManager) CandidateClientForCluster(clusterID uuid.UUID, kubeconfig string) (*kubernetes.Clientset, error) {
+	connection, err := cm.resolveConnection(clusterID)
+	if err != nil {
+		return nil, err
+	}
+	return cm.buildClient(func() (*rest.Config, error) {
+		config, err := cm.buildRESTConfig(kubeconfig, connection)
+		if err != nil {
+			return nil, err
+		}
+		config.QPS, config.Burst = cm.rateLimits()
+		return config, nil
+	})
+}
+
+// AllowRequest 集群处于熔断期时返回 ErrClusterCircuitOpen，供后台任务跳过持续不可达的集群
+func (cm *ClusterManager) AllowRequest(clusterID uuid.UUID) error {
+	return cm.runtime(clusterID).breaker.allow()
+}
+
+// CircuitBreakerStatus 获取集群熔断器状态
+func (cm *ClusterManager) CircuitBreakerStatus(clusterID uuid.UUID) CircuitBreakerStatus {
+	cm.mutex.RLock()
+	runtime, ok := cm.clusters[clusterID]
+	cm.mutex.RUnlock()
+	if !ok {
+		return CircuitBreakerStatus{State: CircuitClosed}
+	}
+	return runtime.breaker.status()
+}
+
+// TransportForCluster 返回访问集群 apiserver 的传输层与服务地址，供 API 代理转发原始请求
+// 传输层已应用 kubeconfig 认证与连接设置，不设置请求超时以支持 watch 与日志流
+// upgrade 为 true 时返回只使用 HTTP/1.1 的传输层，HTTP/2 无法承载 Connection: Upgrade
+func (cm *ClusterManager) TransportForCluster(clusterID uuid.UUID, kubeconfig string, upgrade bool) (http.RoundTripper, *url.URL, error) {
+	credentialKey, connection, err := cm.clusterCredentialKey(clusterID, kubeconfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key := clusterID.String()
+	cm.mutex.Lock()
+	cached, ok := cm.transports[key]
+	if ok && cached.credentialKey == credentialKey {
+		cached.lastUsed = time.Now()
+		cm.mutex.Unlock()
+		return cached.pick(upgrade), cached.host, nil
+	}
+	cm.mutex.Unlock()
+
+	config, err := cm.buildRESTConfig(kubeconfig, connection)
+	if err != nil {
+		return nil, nil, err
+	}
+	config.Timeout = 0
+	cm.wrapBreaker(clusterID, config)
+	roundTripper, err := rest.TransportFor(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create transport: %w", err)
+	}
+	upgradeConfig := rest.CopyConfig(config)
+	upgradeConfig.TLSClientConfig.NextProtos = []string{"http/1.1"}
+	upgradeRoundTripper, err := rest.TransportFor(upgradeConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create upgrade transport: %w", err)
+	}
+	host, _, err := rest.DefaultServerUrlFor(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid apiserver address: %w", err)
+	}
+
+	created := &clusterTransport{
+		roundTripper:        roundTripper,
+		upgradeRoundTripper: upgradeRoundTripper,
+		host:                host,
+		credentialKey:       credentialKey,
+		lastUsed:            time.Now(),
+	}
+	cm.mutex.Lock()
+	cm.transports[key] = created
+	cm.mutex.Unlock()
+	return created.pick(upgrade), host, nil
+}
+
+func (t *clusterTransport) pick(upgrade bool) http.RoundTripper {
+	if upgrade {
+		return t.upgradeRoundTripper
+	}
+	return t.roundTripper
+}
+
+// clusterCredentialKey 按 kubeconfig 与连接设置版本生成凭据键，变化时重建集群的客户端
+func (cm *ClusterManager) clusterCredentialKey(clusterID uuid.UUID, kubeconfig string) (string, *ClusterConnectionConfig, error) {
+	connection, err := cm.resolveConnection(clusterID)
+	if err != nil {
+		return "", nil, err
+	}
+	credentialKey := cm.generateKubeconfigHash(kubeconfig)
+	if connection != nil {
+		credentialKey += "#" + connection.Revision
+	}
+	return credentialKey, connection, nil
+}
+
+// RESTConfigForCluster 生成应用了集群连接设置的 REST 配置，供 Informer 等自行创建客户端
+// 与集群客户端共享限流器，请求结果计入熔断统计
+func (cm *ClusterManager) RESTConfigForCluster(clusterID uuid.UUID, kubeconfig string) (*rest.Config, error) {
+	connection, err := cm.resolveConnection(clusterID)
+	if err != nil {
+		return nil, err
+	}
+	return cm.clusterRESTConfig(clusterID, kubeconfig, connection)
+}
+
+// InvalidateCluster 移除集群的缓存客户端并重置熔断器，凭据或连接设置变更后调用
+func (cm *ClusterManager) InvalidateCluster(clusterID uuid.UUID) {
 	cm.mutex.Lock()
 	defer cm.mutex.Unlock()
 
-	cacheKey := cm.generateKubeconfigHash(kubeconfig)
+	key := clusterID.String()
+	delete(cm.clients, key)
+	delete(cm.transports, key)
+	if runtime, ok := cm.clusters[clusterID]; ok {
+		runtime.breaker.reset()
+	}
+}
 
-	if client, exists := cm.clients[cacheKey]; exists {
-		client.LastUsed = time.Now()
-		return client.Clientset, nil
+func (cm *ClusterManager) resolveConnection(clusterID uuid.UUID) (*ClusterConnectionConfig, error) {
+	cm.mutex.RLock()
+	resolver := cm.connections
+	cm.mutex.RUnlock()
+	if resolver == nil {
+		return nil, nil
+	}
+	connection, err := resolver.ResolveConnection(clusterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve connection settings: %w", err)
 	}
+	return connection, nil
+}
 
+// buildRESTConfig 解析 kubeconfig 并应用连接设置
+func (cm *ClusterManager) buildRESTConfig(kubeconfig string, connection *ClusterConnectionConfig) (*rest.Config, error) {
 	config, err := clientcmd.RESTConfigFromKubeConfig([]byte(kubeconfig))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create REST config: %w", err)
@@ -66,47 +351,179 @@ func (cm *ClusterManager) GetClient(ctx context.Context, kubeconfig string) (*ku
 
 	config.Timeout = cm.timeout
 
-	clientset, err := kubernetes.NewForConfig(config)
+	if connection != nil {
+		if connection.TLSServerName != "" {
+			config.TLSClientConfig.ServerName = connection.TLSServerName
+		}
+		if connection.Proxy != nil {
+			config.Proxy = connection.Proxy
+		}
+		if connection.Dial != nil {
+			config.Dial = connection.Dial
+		}
+	}
+	return config, nil
+}
+
+// clusterRESTConfig 在连接设置之上应用集群共享的限流器与熔断统计
+func (cm *ClusterManager) clusterRESTConfig(clusterID uuid.UUID, kubeconfig string, connection *ClusterConnectionConfig) (*rest.Config, error) {
+	config, err := cm.buildRESTConfig(kubeconfig, connection)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create clientset: %w", err)
+		return nil, err
 	}
+	if limiter := cm.runtime(clusterID).limiter; limiter != nil {
+		config.RateLimiter = limiter
+	}
+	cm.wrapBreaker(clusterID, config)
+	return config, nil
+}
+
+func (cm *ClusterManager) wrapBreaker(clusterID uuid.UUID, config *rest.Config) {
+	breaker := cm.runtime(clusterID).breaker
+	config.WrapTransport = transport.Wrappers(config.WrapTransport, func(rt http.RoundTripper) http.RoundTripper {
+		return &breakerRoundTripper{breaker: breaker, next: rt}
+	})
+}
 
-	cm.clients[cacheKey] = &ClusterClient{
-		Clientset: clientset,
-		LastUsed:  time.Now(),
+// runtime 获取集群的限流器与熔断器，不存在时按当前设置创建
+func (cm *ClusterManager) runtime(clusterID uuid.UUID) *clusterRuntime {
+	cm.mutex.RLock()
+	runtime, ok := cm.clusters[clusterID]
+	cm.mutex.RUnlock()
+	if ok {
+		return runtime
 	}
 
-	cm.cleanupOldClients()
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+	if runtime, ok := cm.clusters[clusterID]; ok {
+		return runtime
+	}
+	runtime = &clusterRuntime{
+		breaker: newCircuitBreaker(cm.breakerThreshold, cm.breakerBaseBackoff, cm.breakerMaxBackoff, cm.timeout),
+	}
+	if cm.qps > 0 {
+		burst := cm.burst
+		if burst <= 0 {
+			burst = int(cm.qps)
+		}
+		runtime.limiter = flowcontrol.NewTokenBucketRateLimiter(cm.qps, burst)
+	}
+	cm.clusters[clusterID] = runtime
+	return runtime
+}
 
+func (cm *ClusterManager) rateLimits() (float32, int) {
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+	return cm.qps, cm.burst
+}
+
+// getClient 从池中获取客户端，凭据键变化时重建；同一键的并发请求只创建一次客户端
+func (cm *ClusterManager) getClient(ctx context.Context, key, credentialKey string, buildConfig func() (*rest.Config, error)) (*kubernetes.Clientset, error) {
+	cm.mutex.Lock()
+	if client, exists := cm.clients[key]; exists && client.credentialKey == credentialKey {
+		client.LastUsed = time.Now()
+		cm.mutex.Unlock()
+		return client.Clientset, nil
+	}
+	callKey := key + "|" + credentialKey
+	if call, building := cm.inflight[callKey]; building {
+		cm.mutex.Unlock()
+		select {
+		case <-call.done:
+			return call.clientset, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &clientCall{done: make(chan struct{})}
+	cm.inflight[callKey] = call
+	cm.mutex.Unlock()
+
+	call.clientset, call.err = cm.buildClient(buildConfig)
+
+	cm.mutex.Lock()
+	delete(cm.inflight, callKey)
+	if call.err == nil {
+		cm.clients[key] = &ClusterClient{
+			Clientset:     call.clientset,
+			LastUsed:      time.Now(),
+			credentialKey: credentialKey,
+		}
+		cm.evictOverflow()
+	}
+	cm.mutex.Unlock()
+	close(call.done)
+
+	return call.clientset, call.err
+}
+
+func (cm *ClusterManager) buildClient(buildConfig func() (*rest.Config, error)) (*kubernetes.Clientset, error) {
+	config, err := buildConfig()
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
 	return clientset, nil
 }
 
-func (cm *ClusterManager) cleanupOldClients() {
-	if len(cm.clients) <= cm.maxClients {
+// evictOverflow 客户端数量超过 maxClients 时移除最久未使用的客户端，调用方持有写锁
+func (cm *ClusterManager) evictOverflow() {
+	if cm.maxClients <= 0 || len(cm.clients) <= cm.maxClients {
 		return
 	}
+	keys := make([]string, 0, len(cm.clients))
+	for key := range cm.clients {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return cm.clients[keys[i]].LastUsed.Before(cm.clients[keys[j]].LastUsed) })
+	for _, key := range keys[:len(keys)-cm.maxClients] {
+		delete(cm.clients, key)
+	}
+}
 
-	var oldestKey string
-	var oldestTime = time.Now()
+// evictIdle 移除空闲超过 idleTTL 的客户端与传输层
+// 集群的限流器与熔断器保留，Informer 仍在使用且熔断状态需要跨越客户端重建
+func (cm *ClusterManager) evictIdle() int {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
 
+	deadline := time.Now().Add(-cm.idleTTL)
+	evicted := 0
 	for key, client := range cm.clients {
-		if client.LastUsed.Before(oldestTime) {
-			oldestTime = client.LastUsed
-			oldestKey = key
+		if client.LastUsed.Before(deadline) {
+			delete(cm.clients, key)
+			evicted++
 		}
 	}
-
-	if oldestKey != "" {
-		delete(cm.clients, oldestKey)
+	for key, cached := range cm.transports {
+		if cached.lastUsed.Before(deadline) {
+			delete(cm.transports, key)
+		}
 	}
+	return evicted
 }
 
+// RemoveClient 移除使用该 kubeconfig 的客户端与传输层
 func (cm *ClusterManager) RemoveClient(kubeconfig string) {
 	cm.mutex.Lock()
 	defer cm.mutex.Unlock()
 
-	cacheKey := cm.generateKubeconfigHash(kubeconfig)
-	delete(cm.clients, cacheKey)
+	hash := cm.generateKubeconfigHash(kubeconfig)
+	for key, client := range cm.clients {
+		if strings.HasPrefix(client.credentialKey, hash) {
+			delete(cm.clients, key)
+		}
+	}
+	for key, cached := range cm.transports {
+		if strings.HasPrefix(cached.credentialKey, hash) {
+			delete(cm.transports, key)
+		}
+	}
 }
 
 func (cm *ClusterManager) HealthCheck(ctx context.Context, clientset *kubernetes.Clientset) (*HealthCheckResult, error) {
@@ -142,8 +559,12 @@ func (cm *ClusterManager) HealthCheck(ctx context.Context, clientset *kubernetes
 	// 获取存储信息
 	totalStorage, usedStorage, storagePercent := cm.getStorageInfo(ctx, clientset)
 
+	// 组件级检查决定整体状态：控制面异常为 unhealthy，附加组件异常为 degraded
+	components := cm.CheckComponents(ctx, clientset)
+
 	return &HealthCheckResult{
-		Status:            "healthy",
+		Status:            OverallHealth(components),
+		Components:        components,
 		Version:           version.String(),
 		NodeCount:         readyNodes,
 		TotalCPUCores:     int(totalCPU / 1000),
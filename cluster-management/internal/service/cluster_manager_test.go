@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// testKubeconfig 指向不可达地址的 kubeconfig，创建客户端不会访问 apiserver
+func testKubeconfig(server string) string {
+	return `apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: ` + server + `
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user:
+    token: test-token
+`
+}
+
+func TestClusterManagerInvalidateResetsBreaker(t *testing.T) {
+	cm := NewClusterManager(time.Second, 10)
+	cm.SetCircuitBreaker(2, time.Minute, time.Hour)
+	clusterID := uuid.New()
+
+	breaker := cm.runtime(clusterID).breaker
+	breaker.recordFailure(errors.New("connection refused"))
+	breaker.recordFailure(errors.New("connection refused"))
+	if err := cm.AllowRequest(clusterID); !errors.Is(err, ErrClusterCircuitOpen) {
+		t.Fatalf("AllowRequest error = %v, want ErrClusterCircuitOpen", err)
+	}
+
+	cm.InvalidateCluster(clusterID)
+	status := cm.CircuitBreakerStatus(clusterID)
+	if status.State != CircuitClosed || status.ConsecutiveFailures != 0 || status.Trips != 0 {
+		t.Errorf("status after invalidate = %+v, want closed with no failures", status)
+	}
+	if err := cm.AllowRequest(clusterID); err != nil {
+		t.Errorf("AllowRequest after invalidate = %v, want nil", err)
+	}
+}
+
+func TestClusterManagerInvalidateRebuildsClient(t *testing.T) {
+	cm := NewClusterManager(time.Second, 10)
+	clusterID := uuid.New()
+	kubeconfig := testKubeconfig("https://127.0.0.1:1")
+
+	first, err := cm.GetClientForCluster(context.Background(), clusterID, kubeconfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached, err := cm.GetClientForCluster(context.Background(), clusterID, kubeconfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached != first {
+		t.Error("client should be served from the pool")
+	}
+
+	cm.InvalidateCluster(clusterID)
+	rebuilt, err := cm.GetClientForCluster(context.Background(), clusterID, kubeconfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rebuilt == first {
+		t.Error("client should be rebuilt after invalidate")
+	}
+
+	rotated, err := cm.GetClientForCluster(context.Background(), clusterID, testKubeconfig("https://127.0.0.1:2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated == rebuilt {
+		t.Error("client should be rebuilt when the kubeconfig changes")
+	}
+}
+
+func TestClusterManagerConcurrentGetClientBuildsOnce(t *testing.T) {
+	cm := NewClusterManager(time.Second, 10)
+	clusterID := uuid.New()
+	kubeconfig := testKubeconfig("https://127.0.0.1:1")
+
+	const callers = 32
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	clients := make([]*kubernetes.Clientset, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			clients[i], errs[i] = cm.GetClientForCluster(context.Background(), clusterID, kubeconfig)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	for i := 0; i < callers; i++ {
+		if errs[i] != nil {
+			t.Fatalf("caller %d: %v", i, errs[i])
+		}
+		if clients[i] != clients[0] {
+			t.Fatalf("caller %d got a different client", i)
+		}
+	}
+	if len(cm.clients) != 1 || len(cm.inflight) != 0 {
+		t.Errorf("pool has %d clients and %d in-flight builds, want 1 and 0", len(cm.clients), len(cm.inflight))
+	}
+}
+
+func TestClusterManagerGetClientSingleflight(t *testing.T) {
+	cm := NewClusterManager(time.Second, 10)
+	kubeconfig := testKubeconfig("https://127.0.0.1:1")
+
+	var builds int32
+	release := make(chan struct{})
+	buildConfig := func() (*rest.Config, error) {
+		atomic.AddInt32(&builds, 1)
+		<-release
+		return cm.buildRESTConfig(kubeconfig, nil)
+	}
+
+	const callers = 16
+	var wg sync.WaitGroup
+	clients := make([]*kubernetes.Clientset, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clients[i], _ = cm.getClient(context.Background(), "cluster", "credential", buildConfig)
+		}(i)
+	}
+
+	// 等待首个调用开始创建后再放行，其余调用此时只能等待同一次创建
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&builds) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&builds); got != 1 {
+		t.Errorf("builds = %d, want 1", got)
+	}
+	for i := 1; i < callers; i++ {
+		if clients[i] == nil || clients[i] != clients[0] {
+			t.Fatalf("caller %d got a different client", i)
+		}
+	}
+}
+
+func TestClusterManagerGetClientWaiterHonorsContext(t *testing.T) {
+	cm := NewClusterManager(time.Second, 10)
+	kubeconfig := testKubeconfig("https://127.0.0.1:1")
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cm.getClient(context.Background(), "cluster", "credential", func() (*rest.Config, error) {
+			close(started)
+			<-release
+			return cm.buildRESTConfig(kubeconfig, nil)
+		})
+	}()
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := cm.getClient(ctx, "cluster", "credential", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("waiter error = %v, want context.Canceled", err)
+	}
+	close(release)
+	<-done
+}
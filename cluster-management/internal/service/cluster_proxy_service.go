@@ -0,0 +1,142 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/repository"
+	"gorm.io/gorm"
+	"k8s.io/client-go/transport"
+)
+
+// ErrProxyUnauthenticated 请求未携带平台用户身份
+var ErrProxyUnauthenticated = errors.New("platform user identity is required")
+
+const defaultImpersonationPrefix = "taichu:"
+
+// ProxyIdentity 平台用户身份，来自 JWT
+type ProxyIdentity struct {
+	UserID   string
+	Username string
+	Role     string
+	TokenID  string // 令牌 ID，仅 kubeconfig 签发的代理令牌带有
+}
+
+// ProxyTarget API 代理的转发目标，传输层已附加模拟身份
+type ProxyTarget struct {
+	Host        *url.URL
+	Transport   http.RoundTripper
+	Impersonate transport.ImpersonationConfig
+}
+
+// ClusterProxyService 集群 API 代理服务
+// 使用导入时保存的凭据访问 apiserver，并以平台用户身份模拟（Impersonate），由集群 RBAC 授权
+type ClusterProxyService struct {
+	clusterRepo       *repository.ClusterRepository
+	encryptionService *EncryptionService
+	clusterManager    *ClusterManager
+	tenantService     *TenantService
+	kubeconfigRepo    *repository.UserKubeconfigRepository
+	userPrefix        string
+	groupPrefix       string
+}
+
+// NewClusterProxyService 创建集群 API 代理服务，前缀为空时使用 "taichu:"
+func NewClusterProxyService(
+	clusterRepo *repository.ClusterRepository,
+	encryptionService *EncryptionService,
+	clusterManager *ClusterManager,
+	tenantService *TenantService,
+	kubeconfigRepo *repository.UserKubeconfigRepository,
+	userPrefix, groupPrefix string,
+) *ClusterProxyService {
+	if userPrefix == "" {
+		userPrefix = defaultImpersonationPrefix
+	}
+	if groupPrefix == "" {
+		groupPrefix = defaultImpersonationPrefix
+	}
+	return &ClusterProxyService{
+		clusterRepo:       clusterRepo,
+		encryptionService: encryptionService,
+		clusterManager:    clusterManager,
+		tenantService:     tenantService,
+		kubeconfigRepo:    kubeconfigRepo,
+		userPrefix:        userPrefix,
+		groupPrefix:       groupPrefix,
+	}
+}
+
+// Prepare 解析集群的转发目标与模拟身份，upgrade 表示请求需要协议升级
+func (s *ClusterProxyService) Prepare(clusterID uuid.UUID, identity ProxyIdentity, upgrade bool) (*ProxyTarget, error) {
+	if s.kubeconfigRepo != nil {
+		if err := validateKubeconfigToken(s.kubeconfigRepo, clusterID, identity.TokenID); err != nil {
+			return nil, err
+		}
+	}
+	impersonate, err := s.Impersonation(identity)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster, err := s.clusterRepo.GetByID(clusterID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClusterNotFound
+		}
+		return nil, fmt.Errorf("failed to get cluster: %w", err)
+	}
+	kubeconfig, err := s.encryptionService.Decrypt(cluster.KubeconfigEncrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt kubeconfig: %w", err)
+	}
+	roundTripper, host, err := s.clusterManager.TransportForCluster(clusterID, kubeconfig, upgrade)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProxyTarget{
+		Host:        host,
+		Transport:   transport.NewImpersonatingRoundTripper(impersonate, roundTripper),
+		Impersonate: impersonate,
+	}, nil
+}
+
+// Impersonation 由平台用户推导模拟身份
+// 用户名为 <user_prefix><username>，用户组包含平台角色 <group_prefix>role:<role>
+// 以及所属租户 <group_prefix>tenant:<name> 与 <group_prefix>tenant:<name>:<member_role>
+func (s *ClusterProxyService) Impersonation(identity ProxyIdentity) (transport.ImpersonationConfig, error) {
+	if identity.Username == "" {
+		return transport.ImpersonationConfig{}, ErrProxyUnauthenticated
+	}
+
+	impersonate := transport.ImpersonationConfig{UserName: s.ImpersonatedUser(identity.Username)}
+	if identity.Role != "" {
+		impersonate.Groups = append(impersonate.Groups, s.groupPrefix+"role:"+identity.Role)
+	}
+
+	userID, err := uuid.Parse(identity.UserID)
+	if err != nil || s.tenantService == nil {
+		return impersonate, nil
+	}
+	memberships, err := s.tenantService.ListUserMemberships(userID)
+	if err != nil {
+		// 租户关系查询失败时拒绝请求，避免以缺少用户组的身份访问导致授权结果不一致
+		log.Printf("[API-PROXY] failed to list tenant memberships for %s: %v", identity.Username, err)
+		return transport.ImpersonationConfig{}, fmt.Errorf("failed to resolve tenant membership: %w", err)
+	}
+	for _, membership := range memberships {
+		group := s.groupPrefix + "tenant:" + membership.TenantName
+		impersonate.Groups = append(impersonate.Groups, group, group+":"+membership.Role)
+	}
+	return impersonate, nil
+}
+
+// ImpersonatedUser 平台用户对应的模拟用户名
+func (s *ClusterProxyService) ImpersonatedUser(username string) string {
+	return s.userPrefix + username
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/taichu-system/cluster-management/internal/model"
 	"github.com/taichu-system/cluster-management/internal/repository"
 	"gorm.io/gorm"
@@ -23,6 +24,7 @@ type ClusterService struct {
 	encryptionService   *EncryptionService
 	clusterManager      *ClusterManager
 	auditService        *AuditService
+	healthHistory       *HealthHistoryService
 }
 
 func NewClusterService(
@@ -32,6 +34,7 @@ func NewClusterService(
 	encryptionService *EncryptionService,
 	clusterManager *ClusterManager,
 	auditService *AuditService,
+	healthHistory *HealthHistoryService,
 ) *ClusterService {
 	return &ClusterService{
 		clusterRepo:         clusterRepo,
@@ -40,6 +43,7 @@ func NewClusterService(
 		encryptionService:   encryptionService,
 		clusterManager:      clusterManager,
 		auditService:        auditService,
+		healthHistory:       healthHistory,
 	}
 }
 
@@ -191,7 +195,7 @@ func (s *ClusterService) TriggerSync(clusterID string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	clientset, err := s.clusterManager.GetClient(ctx, kubeconfig)
+	clientset, err := s.clusterManager.GetClientForCluster(ctx, cluster.ID, kubeconfig)
 	if err != nil {
 		return fmt.Errorf("failed to get client: %w", err)
 	}
@@ -212,13 +216,80 @@ func (s *ClusterService) TriggerSync(clusterID string) error {
 		SyncSuccess:       result.Error == "",
 		SyncError:         result.Error,
 	}
+	if len(result.Components) > 0 {
+		state.Details = HealthDetails(result.Components, result.LastHeartbeatAt)
+	}
+
+	if err := s.stateRepo.Upsert(state); err != nil {
+		return err
+	}
 
-	return s.stateRepo.Upsert(state)
+	if s.healthHistory != nil {
+		if err := s.healthHistory.Record(cluster.ID, result.Status, HealthHistoryMessage(result), time.Now()); err != nil {
+			return fmt.Errorf("failed to record health history: %w", err)
+		}
+	}
+	return nil
 }
 
 func (s *ClusterService) GetClusterState(clusterID string) (*model.ClusterState, error) {
 	return s.stateRepo.GetByClusterID(clusterID)
 }
 
+// ClusterHealthReport 集群组件级健康状态
+type ClusterHealthReport struct {
+	ClusterID       string               `json:"cluster_id"`
+	Status          string               `json:"status"`
+	SyncError       string               `json:"sync_error,omitempty"`
+	LastHeartbeatAt *time.Time           `json:"last_heartbeat_at"`
+	CheckedAt       interface{}          `json:"checked_at"`
+	CircuitBreaker  CircuitBreakerStatus `json:"circuit_breaker"`
+	Components      interface{}          `json:"components"`
+}
+
+// GetClusterHealth 获取集群组件健康状态，refresh 为 true 时先实时检查一次
+func (s *ClusterService) GetClusterHealth(clusterID string, refresh bool) (*ClusterHealthReport, error) {
+	if _, err := s.clusterRepo.GetByID(clusterID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrClusterNotFound
+		}
+		return nil, fmt.Errorf("failed to get cluster: %w", err)
+	}
+
+	if refresh {
+		if err := s.TriggerSync(clusterID); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &ClusterHealthReport{ClusterID: clusterID, Status: "unknown", Components: []interface{}{}}
+	if id, err := uuid.Parse(clusterID); err == nil {
+		report.CircuitBreaker = s.clusterManager.CircuitBreakerStatus(id)
+	}
+	state, err := s.stateRepo.GetByClusterID(clusterID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return report, nil
+		}
+		return nil, fmt.Errorf("failed to get cluster state: %w", err)
+	}
+
+	report.Status = state.Status
+	report.SyncError = state.SyncError
+	report.LastHeartbeatAt = state.LastHeartbeatAt
+	if state.Details != nil {
+		report.CheckedAt = state.Details["checked_at"]
+		if components, ok := state.Details["components"]; ok && components != nil {
+			report.Components = components
+		}
+	}
+	return report, nil
+}
+
+// CircuitBreakerStatus 获取集群熔断器状态，熔断期内后台健康检查与同步跳过该集群
+func (s *ClusterService) CircuitBreakerStatus(clusterID uuid.UUID) CircuitBreakerStatus {
+	return s.clusterManager.CircuitBreakerStatus(clusterID)
+}
+
 type ListClustersParams = repository.ListClustersParams
 
\ No newline at end of file
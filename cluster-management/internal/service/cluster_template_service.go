@@ -0,0 +1,304 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"github.com/taichu-system/cluster-management/internal/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ErrClusterTemplateNotFound 模板不存在
+var ErrClusterTemplateNotFound = errors.New("集群模板不存在")
+
+// ErrInvalidAddon 插件配置不合法
+var ErrInvalidAddon = errors.New("invalid addon")
+
+// RoleRequirement 模板对某一角色机器数量的要求，Max 为0表示不限
+type RoleRequirement struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// ClusterTemplateService 集群模板服务
+type ClusterTemplateService struct {
+	templateRepo *repository.ClusterTemplateRepository
+	provisioners *ProvisionerRegistry
+}
+
+// NewClusterTemplateService 创建集群模板服务
+func NewClusterTemplateService(templateRepo *repository.ClusterTemplateRepository, provisioners *ProvisionerRegistry) *ClusterTemplateService {
+	return &ClusterTemplateService{
+		templateRepo: templateRepo,
+		provisioners: provisioners,
+	}
+}
+
+// CreateTemplate 创建模板的第一个版本
+func (s *ClusterTemplateService) CreateTemplate(template *model.ClusterTemplate) error {
+	if err := s.validateTemplate(template); err != nil {
+		return err
+	}
+
+	exists, err := s.templateRepo.ExistsByName(template.Name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("cluster template %s already exists", template.Name)
+	}
+
+	template.ID = uuid.Nil
+	template.Version = 1
+	return s.templateRepo.Create(template)
+}
+
+// PublishVersion 基于已有模板发布新版本，旧版本保持不变
+func (s *ClusterTemplateService) PublishVersion(baseID uuid.UUID, template *model.ClusterTemplate) error {
+	base, err := s.GetTemplate(baseID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.validateTemplate(template); err != nil {
+		return err
+	}
+
+	return s.templateRepo.GetDB().Transaction(func(tx *gorm.DB) error {
+		var latest model.ClusterTemplate
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("name = ?", base.Name).Order("version DESC").First(&latest).Error; err != nil {
+			return err
+		}
+
+		template.ID = uuid.Nil
+		template.Name = base.Name
+		template.Version = latest.Version + 1
+		return tx.Create(template).Error
+	})
+}
+
+// GetTemplate 获取模板版本
+func (s *ClusterTemplateService) GetTemplate(id uuid.UUID) (*model.ClusterTemplate, error) {
+	template, err := s.templateRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClusterTemplateNotFound
+		}
+		return nil, err
+	}
+	return template, nil
+}
+
+// ListTemplates 获取每个模板的最新版本
+func (s *ClusterTemplateService) ListTemplates() ([]*model.ClusterTemplate, error) {
+	return s.templateRepo.ListLatest()
+}
+
+// ListVersions 获取模板的全部版本
+func (s *ClusterTemplateService) ListVersions(id uuid.UUID) ([]*model.ClusterTemplate, error) {
+	template, err := s.GetTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.templateRepo.ListVersions(template.Name)
+}
+
+// DeleteTemplate 删除模板版本，已创建的任务保留模板名称与版本号
+func (s *ClusterTemplateService) DeleteTemplate(id uuid.UUID) error {
+	if _, err := s.GetTemplate(id); err != nil {
+		return err
+	}
+	return s.templateRepo.Delete(id)
+}
+
+// ApplyTemplate 使用模板内容填充创建请求，模板配置优先于请求
+func (s *ClusterTemplateService) ApplyTemplate(template *model.ClusterTemplate, req *CreateClusterRequest) error {
+	addons, err := AddonsFromJSONMap(template.Addons)
+	if err != nil {
+		return fmt.Errorf("invalid addons in template %s v%d: %w", template.Name, template.Version, err)
+	}
+
+	req.Kubernetes = KubernetesConfig{
+		Version:          template.KubernetesVersion,
+		ImageRepo:        template.ImageRepo,
+		ContainerManager: template.ContainerManager,
+	}
+	req.Network = NetworkConfig{
+		Plugin:      template.NetworkPlugin,
+		PodsCIDR:    template.PodsCIDR,
+		ServiceCIDR: template.ServiceCIDR,
+	}
+	req.ArtifactPath = template.ArtifactPath
+	req.WithPackages = template.WithPackages
+	req.Addons = addons
+	if template.Provisioner != "" {
+		req.Provisioner = template.Provisioner
+	}
+	req.TemplateID = &template.ID
+
+	return nil
+}
+
+// CheckRoleRequirements 校验机器角色数量是否满足模板要求
+func (s *ClusterTemplateService) CheckRoleRequirements(template *model.ClusterTemplate, machines []*model.Machine) error {
+	requirements, err := roleRequirementsFromJSONMap(template.RoleRequirements)
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[string]int)
+	for _, machine := range machines {
+		counts[machine.Role]++
+	}
+
+	roles := make([]string, 0, len(requirements))
+	for role := range requirements {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+
+	for _, role := range roles {
+		req := requirements[role]
+		if counts[role] < req.Min {
+			return fmt.Errorf("template %s v%d requires at least %d %s nodes, got %d",
+				template.Name, template.Version, req.Min, role, counts[role])
+		}
+		if req.Max > 0 && counts[role] > req.Max {
+			return fmt.Errorf("template %s v%d allows at most %d %s nodes, got %d",
+				template.Name, template.Version, req.Max, role, counts[role])
+		}
+	}
+
+	return nil
+}
+
+// validateTemplate 校验模板内容
+func (s *ClusterTemplateService) validateTemplate(template *model.ClusterTemplate) error {
+	if template.Name == "" {
+		return errors.New("template name is required")
+	}
+
+	if template.Provisioner != "" {
+		if _, err := s.provisioners.Get(template.Provisioner); err != nil {
+			return err
+		}
+	}
+
+	requirements, err := roleRequirementsFromJSONMap(template.RoleRequirements)
+	if err != nil {
+		return err
+	}
+	for role, req := range requirements {
+		switch role {
+		case "master", "worker", "etcd", "registry":
+		default:
+			return fmt.Errorf("unknown role in requirements: %s", role)
+		}
+		if req.Min < 0 || req.Max < 0 || (req.Max > 0 && req.Max < req.Min) {
+			return fmt.Errorf("invalid count requirement for role %s", role)
+		}
+	}
+
+	addons, err := AddonsFromJSONMap(template.Addons)
+	if err != nil {
+		return fmt.Errorf("invalid addons: %w", err)
+	}
+	return ValidateAddons(addons)
+}
+
+// ValidateAddons 校验插件名称、命名空间与 Chart 来源，这些字段会拼接到节点上执行的命令中
+func ValidateAddons(addons []AddonConfig) error {
+	for _, addon := range addons {
+		if errs := validation.IsDNS1123Label(addon.Name); len(errs) > 0 {
+			return fmt.Errorf("%w: name %q: %s", ErrInvalidAddon, addon.Name, strings.Join(errs, "; "))
+		}
+		if addon.Namespace != "" {
+			if errs := validation.IsDNS1123Label(addon.Namespace); len(errs) > 0 {
+				return fmt.Errorf("%w: addon %s namespace %q: %s", ErrInvalidAddon, addon.Name, addon.Namespace, strings.Join(errs, "; "))
+			}
+		}
+		if addon.Chart == nil {
+			continue
+		}
+		if errs := validation.IsDNS1123Subdomain(addon.Chart.Name); len(errs) > 0 {
+			return fmt.Errorf("%w: addon %s chart name %q: %s", ErrInvalidAddon, addon.Name, addon.Chart.Name, strings.Join(errs, "; "))
+		}
+		repo, err := url.Parse(addon.Chart.Repo)
+		if err != nil || repo.Host == "" || (repo.Scheme != "http" && repo.Scheme != "https" && repo.Scheme != "oci") {
+			return fmt.Errorf("%w: addon %s chart repo %q must be an http(s) or oci URL", ErrInvalidAddon, addon.Name, addon.Chart.Repo)
+		}
+	}
+	return nil
+}
+
+// AddonsToJSONMap 将插件列表转换为以插件名为键的JSONMap
+func AddonsToJSONMap(addons []AddonConfig) (model.JSONMap, error) {
+	result := make(model.JSONMap, len(addons))
+	for _, addon := range addons {
+		if addon.Name == "" {
+			return nil, errors.New("addon name is required")
+		}
+		data, err := json.Marshal(addon)
+		if err != nil {
+			return nil, err
+		}
+		var value map[string]interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return nil, err
+		}
+		result[addon.Name] = value
+	}
+	return result, nil
+}
+
+// AddonsFromJSONMap 解析以插件名为键的JSONMap，按名称排序
+func AddonsFromJSONMap(m model.JSONMap) ([]AddonConfig, error) {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	addons := make([]AddonConfig, 0, len(names))
+	for _, name := range names {
+		data, err := json.Marshal(m[name])
+		if err != nil {
+			return nil, err
+		}
+		var addon AddonConfig
+		if err := json.Unmarshal(data, &addon); err != nil {
+			return nil, fmt.Errorf("addon %s: %w", name, err)
+		}
+		addon.Name = name
+		if addon.Chart == nil && len(addon.YamlPaths) == 0 {
+			return nil, fmt.Errorf("addon %s has neither chart nor yaml_paths", name)
+		}
+		addons = append(addons, addon)
+	}
+	return addons, nil
+}
+
+// roleRequirementsFromJSONMap 解析角色数量要求
+func roleRequirementsFromJSONMap(m model.JSONMap) (map[string]RoleRequirement, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	requirements := make(map[string]RoleRequirement)
+	if len(m) == 0 {
+		return requirements, nil
+	}
+	if err := json.Unmarshal(data, &requirements); err != nil {
+		return nil, fmt.Errorf("invalid role requirements: %w", err)
+	}
+	return requirements, nil
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/taichu-system/cluster-management/internal/model"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ErrInvalidTopology 集群拓扑配置不合法
+var ErrInvalidTopology = errors.New("invalid cluster topology")
+
+// 控制平面高可用模式
+const (
+	HAModeNone     = "none"     // 单控制平面，直接访问首个 master
+	HAModeKubeVIP  = "kube-vip" // kube-vip 虚拟IP
+	HAModeHAProxy  = "haproxy"  // 各节点本地 haproxy 代理全部 master
+	HAModeExternal = "external" // 外部负载均衡
+)
+
+// etcd 部署模式
+const (
+	EtcdModeStacked  = "stacked"  // 与控制平面同机部署
+	EtcdModeExternal = "external" // 部署在 role=etcd 的独立机器上
+)
+
+const defaultControlPlaneDomain = "lb.kubesphere.local"
+
+// argKeyPattern 组件参数名，不带前导 --
+var argKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// reservedAPIServerArgs 由拓扑生成、不允许通过额外参数覆盖的 apiserver 参数
+var reservedAPIServerArgs = map[string]bool{
+	"advertise-address":        true,
+	"etcd-servers":             true,
+	"secure-port":              true,
+	"service-cluster-ip-range": true,
+}
+
+// TopologyConfig 集群拓扑：高可用、etcd、镜像仓库与组件参数
+type TopologyConfig struct {
+	HA                 HAConfig          `json:"ha"`
+	Etcd               EtcdConfig        `json:"etcd"`
+	Registry           RegistryConfig    `json:"registry"`
+	ExtraSANs          []string          `json:"extra_sans,omitempty"`
+	APIServerExtraArgs map[string]string `json:"apiserver_extra_args,omitempty"`
+	KubeletExtraArgs   map[string]string `json:"kubelet_extra_args,omitempty"`
+}
+
+// HAConfig 控制平面高可用配置
+type HAConfig struct {
+	Mode    string `json:"mode"`              // none/kube-vip/haproxy/external，为空时按 master 数量选择
+	Address string `json:"address,omitempty"` // kube-vip 虚拟IP或外部负载均衡地址
+	Domain  string `json:"domain,omitempty"`
+	Port    int    `json:"port,omitempty"`
+}
+
+// EtcdConfig etcd 部署配置
+type EtcdConfig struct {
+	Mode string `json:"mode"` // stacked/external，为空时有 etcd 机器则为 external
+}
+
+// RegistryConfig 镜像仓库配置
+type RegistryConfig struct {
+	PrivateRegistry    string                  `json:"private_registry,omitempty"`
+	NamespaceOverride  string                  `json:"namespace_override,omitempty"`
+	Mirrors            []string                `json:"mirrors,omitempty"`
+	InsecureRegistries []string                `json:"insecure_registries,omitempty"`
+	Auths              map[string]RegistryAuth `json:"auths,omitempty"` // 仓库地址 -> 认证，不写入渲染后的配置
+}
+
+// RegistryAuth 镜像仓库认证
+type RegistryAuth struct {
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	SkipTLSVerify bool   `json:"skip_tls_verify,omitempty"`
+	PlainHTTP     bool   `json:"plain_http,omitempty"`
+}
+
+// ApplyTopologyDefaults 根据机器角色补全高可用与 etcd 模式
+func ApplyTopologyDefaults(topology *TopologyConfig, machines []*model.Machine) {
+	masters, etcds := countRole(machines, "master"), countRole(machines, "etcd")
+
+	if topology.HA.Mode == "" {
+		if masters > 1 {
+			topology.HA.Mode = HAModeHAProxy
+		} else {
+			topology.HA.Mode = HAModeNone
+		}
+	}
+	if topology.HA.Domain == "" {
+		topology.HA.Domain = defaultControlPlaneDomain
+	}
+	if topology.HA.Port == 0 {
+		topology.HA.Port = 6443
+	}
+
+	if topology.Etcd.Mode == "" {
+		if etcds > 0 {
+			topology.Etcd.Mode = EtcdModeExternal
+		} else {
+			topology.Etcd.Mode = EtcdModeStacked
+		}
+	}
+}
+
+// ValidateTopology 校验拓扑与机器角色是否一致，调用前需先执行 ApplyTopologyDefaults
+func ValidateTopology(topology TopologyConfig, machines []*model.Machine) error {
+	masters, etcds := countRole(machines, "master"), countRole(machines, "etcd")
+	if masters == 0 {
+		return topologyError("at least one master node is required")
+	}
+
+	machineIPs := make(map[string]bool, len(machines)*2)
+	for _, machine := range machines {
+		machineIPs[machine.IPAddress] = true
+		if machine.InternalAddress != "" {
+			machineIPs[machine.InternalAddress] = true
+		}
+	}
+
+	ha := topology.HA
+	if ha.Port < 1 || ha.Port > 65535 {
+		return topologyError("invalid control plane port %d", ha.Port)
+	}
+	if errs := validation.IsDNS1123Subdomain(ha.Domain); len(errs) > 0 {
+		return topologyError("invalid control plane domain %q: %s", ha.Domain, strings.Join(errs, "; "))
+	}
+	switch ha.Mode {
+	case HAModeNone:
+		if masters > 1 {
+			return topologyError("%d master nodes require an HA mode (kube-vip, haproxy or external)", masters)
+		}
+		if ha.Address != "" {
+			return topologyError("ha address is only used with kube-vip or external mode")
+		}
+	case HAModeHAProxy:
+		if ha.Address != "" {
+			return topologyError("haproxy mode runs a local proxy on each node and does not take an address")
+		}
+	case HAModeKubeVIP:
+		if net.ParseIP(ha.Address) == nil {
+			return topologyError("kube-vip mode requires a virtual IP address, got %q", ha.Address)
+		}
+		if machineIPs[ha.Address] {
+			return topologyError("kube-vip address %s is already used by a machine", ha.Address)
+		}
+	case HAModeExternal:
+		if ha.Address == "" {
+			return topologyError("external mode requires the load balancer address")
+		}
+		if err := validateHost(ha.Address); err != nil {
+			return topologyError("invalid load balancer address: %v", err)
+		}
+	default:
+		return topologyError("unknown ha mode %q", ha.Mode)
+	}
+
+	switch topology.Etcd.Mode {
+	case EtcdModeStacked:
+		if etcds > 0 {
+			return topologyError("stacked etcd runs on master nodes, remove the %d etcd machines or use external mode", etcds)
+		}
+		if masters%2 == 0 {
+			return topologyError("stacked etcd requires an odd number of master nodes, got %d", masters)
+		}
+	case EtcdModeExternal:
+		if etcds == 0 {
+			return topologyError("external etcd requires machines with role etcd")
+		}
+		if etcds%2 == 0 {
+			return topologyError("external etcd requires an odd number of etcd machines, got %d", etcds)
+		}
+	default:
+		return topologyError("unknown etcd mode %q", topology.Etcd.Mode)
+	}
+
+	for _, san := range topology.ExtraSANs {
+		if err := validateHost(san); err != nil {
+			return topologyError("invalid extra SAN: %v", err)
+		}
+	}
+
+	if err := validateExtraArgs("apiserver", topology.APIServerExtraArgs, reservedAPIServerArgs); err != nil {
+		return err
+	}
+	if err := validateExtraArgs("kubelet", topology.KubeletExtraArgs, nil); err != nil {
+		return err
+	}
+
+	return validateRegistry(topology.Registry)
+}
+
+// validateRegistry 校验镜像仓库配置
+func validateRegistry(registry RegistryConfig) error {
+	if registry.PrivateRegistry != "" {
+		if err := validateRegistryHost(registry.PrivateRegistry); err != nil {
+			return topologyError("invalid private registry: %v", err)
+		}
+	}
+	for _, mirror := range registry.Mirrors {
+		u, err := url.Parse(mirror)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return topologyError("registry mirror %q must be an http(s) URL", mirror)
+		}
+	}
+	for _, registryHost := range registry.InsecureRegistries {
+		if err := validateRegistryHost(registryHost); err != nil {
+			return topologyError("invalid insecure registry: %v", err)
+		}
+	}
+	for registryHost, auth := range registry.Auths {
+		if err := validateRegistryHost(registryHost); err != nil {
+			return topologyError("invalid registry in auths: %v", err)
+		}
+		if auth.Username == "" || auth.Password == "" {
+			return topologyError("registry auth for %s requires username and password", registryHost)
+		}
+	}
+	return nil
+}
+
+// validateExtraArgs 校验组件额外参数
+func validateExtraArgs(component string, args map[string]string, reserved map[string]bool) error {
+	for key, value := range args {
+		if !argKeyPattern.MatchString(key) {
+			return topologyError("invalid %s arg %q, use the flag name without leading dashes", component, key)
+		}
+		if reserved[key] {
+			return topologyError("%s arg %s is managed by the cluster topology", component, key)
+		}
+		if strings.ContainsAny(value, "\n\r") {
+			return topologyError("%s arg %s contains a line break", component, key)
+		}
+	}
+	return nil
+}
+
+// validateHost 校验IP地址或DNS名称
+func validateHost(host string) error {
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+	if errs := validation.IsDNS1123Subdomain(host); len(errs) > 0 {
+		return fmt.Errorf("%q is neither an IP address nor a DNS name", host)
+	}
+	return nil
+}
+
+// validateRegistryHost 校验 host[:port] 形式的仓库地址，不允许带协议
+func validateRegistryHost(registryHost string) error {
+	if strings.Contains(registryHost, "://") {
+		return fmt.Errorf("%q must not include a scheme", registryHost)
+	}
+	host := registryHost
+	if h, port, err := net.SplitHostPort(registryHost); err == nil {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("%q has an invalid port", registryHost)
+		}
+		host = h
+	}
+	return validateHost(host)
+}
+
+// sortedArgs 将参数转换为按名称排序的 key=value 列表
+func sortedArgs(args map[string]string) []string {
+	result := make([]string, 0, len(args))
+	for key, value := range args {
+		result = append(result, key+"="+value)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// countRole 统计指定角色的机器数量
+func countRole(machines []*model.Machine, role string) int {
+	count := 0
+	for _, machine := range machines {
+		if machine.Role == role {
+			count++
+		}
+	}
+	return count
+}
+
+// topologyError 生成包装 ErrInvalidTopology 的错误
+func topologyError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidTopology, fmt.Sprintf(format, args...))
+}
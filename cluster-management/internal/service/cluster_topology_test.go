@@ -0,0 +1,282 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/taichu-system/cluster-management/internal/model"
+)
+
+// topologyMachines 按角色数量生成机器，IP 依次为 10.0.0.1、10.0.0.2……
+func topologyMachines(masters, workers, etcds int) []*model.Machine {
+	var machines []*model.Machine
+	add := func(role string, count int) {
+		for i := 0; i < count; i++ {
+			machines = append(machines, &model.Machine{
+				Name:      fmt.Sprintf("%s-%d", role, i),
+				IPAddress: fmt.Sprintf("10.0.0.%d", len(machines)+1),
+				Role:      role,
+			})
+		}
+	}
+	add("master", masters)
+	add("worker", workers)
+	add("etcd", etcds)
+	return machines
+}
+
+func TestApplyTopologyDefaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		machines []*model.Machine
+		topology TopologyConfig
+		wantHA   string
+		wantEtcd string
+	}{
+		{name: "single master", machines: topologyMachines(1, 2, 0), wantHA: HAModeNone, wantEtcd: EtcdModeStacked},
+		{name: "multiple masters use haproxy", machines: topologyMachines(3, 2, 0), wantHA: HAModeHAProxy, wantEtcd: EtcdModeStacked},
+		{name: "etcd machines use external etcd", machines: topologyMachines(1, 1, 3), wantHA: HAModeNone, wantEtcd: EtcdModeExternal},
+		{
+			name:     "explicit modes are kept",
+			machines: topologyMachines(3, 0, 0),
+			topology: TopologyConfig{HA: HAConfig{Mode: HAModeKubeVIP}, Etcd: EtcdConfig{Mode: EtcdModeExternal}},
+			wantHA:   HAModeKubeVIP,
+			wantEtcd: EtcdModeExternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topology := tt.topology
+			ApplyTopologyDefaults(&topology, tt.machines)
+			if topology.HA.Mode != tt.wantHA {
+				t.Errorf("ha mode = %q, want %q", topology.HA.Mode, tt.wantHA)
+			}
+			if topology.Etcd.Mode != tt.wantEtcd {
+				t.Errorf("etcd mode = %q, want %q", topology.Etcd.Mode, tt.wantEtcd)
+			}
+			if topology.HA.Domain != defaultControlPlaneDomain || topology.HA.Port != 6443 {
+				t.Errorf("control plane endpoint = %s:%d, want %s:6443", topology.HA.Domain, topology.HA.Port, defaultControlPlaneDomain)
+			}
+		})
+	}
+}
+
+func TestValidateTopology(t *testing.T) {
+	tests := []struct {
+		name     string
+		machines []*model.Machine
+		modify   func(*TopologyConfig)
+		// wantErr 错误信息应包含的片段，为空表示校验通过
+		wantErr string
+	}{
+		{name: "single master defaults", machines: topologyMachines(1, 2, 0)},
+		{name: "three masters with haproxy", machines: topologyMachines(3, 2, 0)},
+		{name: "no master", machines: topologyMachines(0, 2, 0), wantErr: "at least one master"},
+		{
+			name:     "multiple masters without ha",
+			machines: topologyMachines(3, 0, 0),
+			modify:   func(tc *TopologyConfig) { tc.HA.Mode = HAModeNone },
+			wantErr:  "require an HA mode",
+		},
+		{
+			name:     "address without ha",
+			machines: topologyMachines(1, 0, 0),
+			modify:   func(tc *TopologyConfig) { tc.HA.Address = "10.0.0.100" },
+			wantErr:  "only used with kube-vip or external",
+		},
+		{
+			name:     "haproxy with address",
+			machines: topologyMachines(3, 0, 0),
+			modify:   func(tc *TopologyConfig) { tc.HA.Address = "10.0.0.100" },
+			wantErr:  "does not take an address",
+		},
+		{
+			name:     "kube-vip with virtual IP",
+			machines: topologyMachines(3, 0, 0),
+			modify: func(tc *TopologyConfig) {
+				tc.HA.Mode = HAModeKubeVIP
+				tc.HA.Address = "10.0.0.100"
+			},
+		},
+		{
+			name:     "kube-vip with DNS name",
+			machines: topologyMachines(3, 0, 0),
+			modify: func(tc *TopologyConfig) {
+				tc.HA.Mode = HAModeKubeVIP
+				tc.HA.Address = "vip.example.com"
+			},
+			wantErr: "requires a virtual IP",
+		},
+		{
+			name:     "kube-vip address used by a machine",
+			machines: topologyMachines(3, 0, 0),
+			modify: func(tc *TopologyConfig) {
+				tc.HA.Mode = HAModeKubeVIP
+				tc.HA.Address = "10.0.0.2"
+			},
+			wantErr: "already used by a machine",
+		},
+		{
+			name:     "external load balancer",
+			machines: topologyMachines(3, 0, 0),
+			modify: func(tc *TopologyConfig) {
+				tc.HA.Mode = HAModeExternal
+				tc.HA.Address = "lb.example.com"
+			},
+		},
+		{
+			name:     "external without address",
+			machines: topologyMachines(3, 0, 0),
+			modify:   func(tc *TopologyConfig) { tc.HA.Mode = HAModeExternal },
+			wantErr:  "requires the load balancer address",
+		},
+		{
+			name:     "unknown ha mode",
+			machines: topologyMachines(1, 0, 0),
+			modify:   func(tc *TopologyConfig) { tc.HA.Mode = "keepalived" },
+			wantErr:  "unknown ha mode",
+		},
+		{
+			name:     "invalid port",
+			machines: topologyMachines(1, 0, 0),
+			modify:   func(tc *TopologyConfig) { tc.HA.Port = 70000 },
+			wantErr:  "invalid control plane port",
+		},
+		{
+			name:     "invalid domain",
+			machines: topologyMachines(1, 0, 0),
+			modify:   func(tc *TopologyConfig) { tc.HA.Domain = "LB_local" },
+			wantErr:  "invalid control plane domain",
+		},
+		{
+			name:     "stacked etcd with even masters",
+			machines: topologyMachines(2, 0, 0),
+			wantErr:  "odd number of master nodes",
+		},
+		{
+			name:     "stacked etcd with etcd machines",
+			machines: topologyMachines(1, 0, 3),
+			modify:   func(tc *TopologyConfig) { tc.Etcd.Mode = EtcdModeStacked },
+			wantErr:  "remove the 3 etcd machines",
+		},
+		{
+			name:     "external etcd with even masters",
+			machines: topologyMachines(2, 0, 3),
+		},
+		{
+			name:     "external etcd without etcd machines",
+			machines: topologyMachines(1, 0, 0),
+			modify:   func(tc *TopologyConfig) { tc.Etcd.Mode = EtcdModeExternal },
+			wantErr:  "requires machines with role etcd",
+		},
+		{
+			name:     "external etcd with even etcd machines",
+			machines: topologyMachines(1, 0, 2),
+			wantErr:  "odd number of etcd machines",
+		},
+		{
+			name:     "unknown etcd mode",
+			machines: topologyMachines(1, 0, 0),
+			modify:   func(tc *TopologyConfig) { tc.Etcd.Mode = "managed" },
+			wantErr:  "unknown etcd mode",
+		},
+		{
+			name:     "extra SANs",
+			machines: topologyMachines(1, 0, 0),
+			modify:   func(tc *TopologyConfig) { tc.ExtraSANs = []string{"10.0.0.200", "api.example.com"} },
+		},
+		{
+			name:     "invalid extra SAN",
+			machines: topologyMachines(1, 0, 0),
+			modify:   func(tc *TopologyConfig) { tc.ExtraSANs = []string{"api example"} },
+			wantErr:  "invalid extra SAN",
+		},
+		{
+			name:     "apiserver arg with dashes",
+			machines: topologyMachines(1, 0, 0),
+			modify: func(tc *TopologyConfig) {
+				tc.APIServerExtraArgs = map[string]string{"--audit-log-path": "/var/log/audit.log"}
+			},
+			wantErr: "without leading dashes",
+		},
+		{
+			name:     "reserved apiserver arg",
+			machines: topologyMachines(1, 0, 0),
+			modify: func(tc *TopologyConfig) {
+				tc.APIServerExtraArgs = map[string]string{"etcd-servers": "https://10.0.0.9:2379"}
+			},
+			wantErr: "managed by the cluster topology",
+		},
+		{
+			name:     "kubelet arg with line break",
+			machines: topologyMachines(1, 0, 0),
+			modify:   func(tc *TopologyConfig) { tc.KubeletExtraArgs = map[string]string{"max-pods": "110\n--foo"} },
+			wantErr:  "contains a line break",
+		},
+		{
+			name:     "registry settings",
+			machines: topologyMachines(1, 0, 0),
+			modify: func(tc *TopologyConfig) {
+				tc.Registry = RegistryConfig{
+					PrivateRegistry:    "registry.example.com:5000",
+					Mirrors:            []string{"https://mirror.example.com"},
+					InsecureRegistries: []string{"10.0.0.50:5000"},
+					Auths:              map[string]RegistryAuth{"registry.example.com:5000": {Username: "admin", Password: "secret"}},
+				}
+			},
+		},
+		{
+			name:     "private registry with scheme",
+			machines: topologyMachines(1, 0, 0),
+			modify:   func(tc *TopologyConfig) { tc.Registry.PrivateRegistry = "https://registry.example.com" },
+			wantErr:  "must not include a scheme",
+		},
+		{
+			name:     "private registry with invalid port",
+			machines: topologyMachines(1, 0, 0),
+			modify:   func(tc *TopologyConfig) { tc.Registry.PrivateRegistry = "registry.example.com:0" },
+			wantErr:  "invalid port",
+		},
+		{
+			name:     "mirror without scheme",
+			machines: topologyMachines(1, 0, 0),
+			modify:   func(tc *TopologyConfig) { tc.Registry.Mirrors = []string{"mirror.example.com"} },
+			wantErr:  "must be an http(s) URL",
+		},
+		{
+			name:     "registry auth without password",
+			machines: topologyMachines(1, 0, 0),
+			modify: func(tc *TopologyConfig) {
+				tc.Registry.Auths = map[string]RegistryAuth{"registry.example.com": {Username: "admin"}}
+			},
+			wantErr: "requires username and password",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var topology TopologyConfig
+			if tt.modify != nil {
+				tt.modify(&topology)
+			}
+			ApplyTopologyDefaults(&topology, tt.machines)
+
+			err := ValidateTopology(topology, tt.machines)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateTopology() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidTopology) {
+				t.Fatalf("ValidateTopology() error = %v, want ErrInvalidTopology", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateTopology() error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
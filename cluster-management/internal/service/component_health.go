@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/taichu-system/cluster-management/internal/model"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// 组件健康状态
+const (
+	ComponentStatusHealthy   = "healthy"
+	ComponentStatusUnhealthy = "unhealthy"
+	ComponentStatusUnknown   = "unknown" // 组件不存在或无权限查看，例如托管集群隐藏了控制面
+)
+
+// 组件类别，控制面组件异常时集群判定为 unhealthy，附加组件异常时为 degraded
+const (
+	ComponentCategoryControlPlane = "control_plane"
+	ComponentCategoryAddon        = "addon"
+)
+
+// 集群整体健康状态
+const (
+	ClusterHealthHealthy      = "healthy"
+	ClusterHealthDegraded     = "degraded"
+	ClusterHealthUnhealthy    = "unhealthy"
+	ClusterHealthDisconnected = "disconnected"
+)
+
+// leaseStaleGrace 租约过期后的容忍时间，避免续约抖动导致误报
+const leaseStaleGrace = 10 * time.Second
+
+// cniDaemonSets 常见 CNI 插件的 DaemonSet，按 namespace/name 表示
+var cniDaemonSets = []string{
+	"kube-system/calico-node",
+	"calico-system/calico-node",
+	"kube-system/cilium",
+	"kube-system/kube-flannel-ds",
+	"kube-flannel/kube-flannel-ds",
+	"kube-system/canal",
+	"kube-system/weave-net",
+	"kube-system/kube-router",
+	"kube-system/antrea-agent",
+	"kube-system/kube-ovn-cni",
+}
+
+// ComponentHealth 单个组件的健康状态
+type ComponentHealth struct {
+	Name     string            `json:"name"`
+	Category string            `json:"category"`
+	Status   string            `json:"status"`
+	Message  string            `json:"message,omitempty"`
+	Checks   map[string]string `json:"checks,omitempty"` // 子检查项，如 readyz 各检查或 etcd 成员
+}
+
+// CheckComponents 检查控制面与附加组件，各项检查相互独立
+func (cm *ClusterManager) CheckComponents(ctx context.Context, clientset kubernetes.Interface) []ComponentHealth {
+	readyz := checkAPIServerReadyz(ctx, clientset)
+	return []ComponentHealth{
+		readyz,
+		checkEtcd(ctx, clientset, readyz),
+		checkLeaderLease(ctx, clientset, "kube-scheduler"),
+		checkLeaderLease(ctx, clientset, "kube-controller-manager"),
+		checkCoreDNS(ctx, clientset),
+		checkCNI(ctx, clientset),
+		checkKubeProxy(ctx, clientset),
+		checkMetricsServer(ctx, clientset),
+	}
+}
+
+// OverallHealth 根据组件状态计算集群整体健康状态
+func OverallHealth(components []ComponentHealth) string {
+	overall := ClusterHealthHealthy
+	for _, component := range components {
+		if component.Status != ComponentStatusUnhealthy {
+			continue
+		}
+		if component.Category == ComponentCategoryControlPlane {
+			return ClusterHealthUnhealthy
+		}
+		overall = ClusterHealthDegraded
+	}
+	return overall
+}
+
+// HealthDetails 将组件检查结果转换为 ClusterState.Details
+func HealthDetails(components []ComponentHealth, checkedAt time.Time) model.JSONMap {
+	return model.JSONMap{
+		"checked_at": checkedAt,
+		"components": toJSONValue(components),
+	}
+}
+
+// checkAPIServerReadyz 读取 /readyz?verbose 的逐项结果
+// 检查未通过时 apiserver 返回 500，但响应体仍包含明细
+func checkAPIServerReadyz(ctx context.Context, clientset kubernetes.Interface) ComponentHealth {
+	component := ComponentHealth{Name: "kube-apiserver", Category: ComponentCategoryControlPlane}
+
+	body, err := clientset.Discovery().RESTClient().Get().AbsPath("/readyz").Param("verbose", "").DoRaw(ctx)
+	checks, failed := parseHealthzVerbose(string(body))
+	component.Checks = checks
+	switch {
+	case len(checks) == 0 && err != nil:
+		component.Status = ComponentStatusUnhealthy
+		component.Message = err.Error()
+	case len(failed) > 0:
+		component.Status = ComponentStatusUnhealthy
+		component.Message = "failed checks: " + strings.Join(failed, ", ")
+	default:
+		component.Status = ComponentStatusHealthy
+	}
+	return component
+}
+
+// checkEtcd 通过 apiserver 的 etcd 检查项与 etcd 静态Pod判断成员健康
+func checkEtcd(ctx context.Context, clientset kubernetes.Interface, readyz ComponentHealth) ComponentHealth {
+	component := ComponentHealth{Name: "etcd", Category: ComponentCategoryControlPlane, Checks: map[string]string{}}
+
+	var failed []string
+	for name, result := range readyz.Checks {
+		if !strings.HasPrefix(name, "etcd") {
+			continue
+		}
+		component.Checks["apiserver:"+name] = result
+		if result != "ok" {
+			failed = append(failed, name)
+		}
+	}
+
+	// kubeadm 部署的集群可以看到每个 etcd 成员的静态Pod，托管集群或外置 etcd 则没有
+	pods, err := clientset.CoreV1().Pods("kube-system").List(ctx, metav1.ListOptions{LabelSelector: "component=etcd"})
+	if err == nil {
+		for _, pod := range pods.Items {
+			if isPodReady(pod) {
+				component.Checks["member:"+pod.Spec.NodeName] = "ok"
+				continue
+			}
+			component.Checks["member:"+pod.Spec.NodeName] = "not ready"
+			failed = append(failed, "member "+pod.Spec.NodeName)
+		}
+	}
+
+	switch {
+	case len(failed) > 0:
+		component.Status = ComponentStatusUnhealthy
+		component.Message = "failed: " + strings.Join(failed, ", ")
+	case len(component.Checks) == 0:
+		component.Status = ComponentStatusUnknown
+		component.Message = "etcd health is not exposed by this cluster"
+	default:
+		component.Status = ComponentStatusHealthy
+	}
+	return component
+}
+
+// checkLeaderLease 检查 kube-system 中领导者租约是否按时续约
+func checkLeaderLease(ctx context.Context, clientset kubernetes.Interface, name string) ComponentHealth {
+	component := ComponentHealth{Name: name, Category: ComponentCategoryControlPlane}
+
+	lease, err := clientset.CoordinationV1().Leases("kube-system").Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		component.Status = ComponentStatusUnknown
+		component.Message = fmt.Sprintf("leader lease unavailable: %v", err)
+		return component
+	}
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		component.Status = ComponentStatusUnhealthy
+		component.Message = "leader lease has never been renewed"
+		return component
+	}
+
+	holder := ""
+	if lease.Spec.HolderIdentity != nil {
+		holder = *lease.Spec.HolderIdentity
+	}
+	expiresAt := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds)*time.Second + leaseStaleGrace)
+	component.Checks = map[string]string{
+		"holder":     holder,
+		"renew_time": lease.Spec.RenewTime.UTC().Format(time.RFC3339),
+	}
+	if time.Now().After(expiresAt) {
+		component.Status = ComponentStatusUnhealthy
+		component.Message = fmt.Sprintf("leader lease held by %s expired at %s", holder, expiresAt.UTC().Format(time.RFC3339))
+		return component
+	}
+	component.Status = ComponentStatusHealthy
+	return component
+}
+
+// checkCoreDNS 检查 CoreDNS Deployment 的就绪副本
+func checkCoreDNS(ctx context.Context, clientset kubernetes.Interface) ComponentHealth {
+	component := ComponentHealth{Name: "coredns", Category: ComponentCategoryAddon}
+
+	deployment, err := clientset.AppsV1().Deployments("kube-system").Get(ctx, "coredns", metav1.GetOptions{})
+	if err != nil {
+		component.Status = ComponentStatusUnknown
+		component.Message = fmt.Sprintf("coredns deployment unavailable: %v", err)
+		return component
+	}
+	return deploymentHealth(component, deployment)
+}
+
+// checkCNI 识别常见 CNI 插件并检查其 DaemonSet 就绪情况
+func checkCNI(ctx context.Context, clientset kubernetes.Interface) ComponentHealth {
+	component := ComponentHealth{Name: "cni", Category: ComponentCategoryAddon}
+
+	var found []ComponentHealth
+	for _, key := range cniDaemonSets {
+		parts := strings.SplitN(key, "/", 2)
+		ds, err := clientset.AppsV1().DaemonSets(parts[0]).Get(ctx, parts[1], metav1.GetOptions{})
+		if err != nil {
+			continue
+		}
+		found = append(found, daemonSetHealth(ComponentHealth{Name: key}, ds))
+	}
+	if len(found) == 0 {
+		component.Status = ComponentStatusUnknown
+		component.Message = "no known CNI DaemonSet found"
+		return component
+	}
+
+	component.Status = ComponentStatusHealthy
+	component.Checks = make(map[string]string, len(found))
+	var unhealthy []string
+	for _, ds := range found {
+		component.Checks[ds.Name] = ds.Message
+		if ds.Status == ComponentStatusUnhealthy {
+			unhealthy = append(unhealthy, ds.Name)
+		}
+	}
+	if len(unhealthy) > 0 {
+		component.Status = ComponentStatusUnhealthy
+		component.Message = "not ready: " + strings.Join(unhealthy, ", ")
+	}
+	return component
+}
+
+// checkKubeProxy 检查 kube-proxy DaemonSet，使用 eBPF 替代方案的集群可能没有
+func checkKubeProxy(ctx context.Context, clientset kubernetes.Interface) ComponentHealth {
+	component := ComponentHealth{Name: "kube-proxy", Category: ComponentCategoryAddon}
+
+	ds, err := clientset.AppsV1().DaemonSets("kube-system").Get(ctx, "kube-proxy", metav1.GetOptions{})
+	if err != nil {
+		component.Status = ComponentStatusUnknown
+		component.Message = fmt.Sprintf("kube-proxy daemonset unavailable: %v", err)
+		return component
+	}
+	return daemonSetHealth(component, ds)
+}
+
+// checkMetricsServer 通过 metrics.k8s.io 的发现接口判断 metrics-server 是否可用
+func checkMetricsServer(ctx context.Context, clientset kubernetes.Interface) ComponentHealth {
+	component := ComponentHealth{Name: "metrics-server", Category: ComponentCategoryAddon}
+
+	_, err := clientset.Discovery().ServerResourcesForGroupVersion("metrics.k8s.io/v1beta1")
+	switch {
+	case err == nil:
+		component.Status = ComponentStatusHealthy
+	case apierrors.IsNotFound(err):
+		component.Status = ComponentStatusUnknown
+		component.Message = "metrics.k8s.io API is not registered"
+	default:
+		// APIService 已注册但后端不可用时发现接口返回 503
+		component.Status = ComponentStatusUnhealthy
+		component.Message = err.Error()
+	}
+	return component
+}
+
+// deploymentHealth 根据就绪副本数判断 Deployment 健康状态
+func deploymentHealth(component ComponentHealth, deployment *appsv1.Deployment) ComponentHealth {
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	component.Message = fmt.Sprintf("%d/%d replicas ready", deployment.Status.ReadyReplicas, desired)
+	if deployment.Status.ReadyReplicas < desired || (desired > 0 && deployment.Status.ReadyReplicas == 0) {
+		component.Status = ComponentStatusUnhealthy
+		return component
+	}
+	component.Status = ComponentStatusHealthy
+	return component
+}
+
+// daemonSetHealth 根据就绪Pod数判断 DaemonSet 健康状态
+func daemonSetHealth(component ComponentHealth, ds *appsv1.DaemonSet) ComponentHealth {
+	component.Message = fmt.Sprintf("%d/%d pods ready", ds.Status.NumberReady, ds.Status.DesiredNumberScheduled)
+	if ds.Status.NumberReady < ds.Status.DesiredNumberScheduled {
+		component.Status = ComponentStatusUnhealthy
+		return component
+	}
+	component.Status = ComponentStatusHealthy
+	return component
+}
+
+// parseHealthzVerbose 解析 [+]name ok / [-]name failed: reason 形式的输出
+func parseHealthzVerbose(body string) (checks map[string]string, failed []string) {
+	checks = make(map[string]string)
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "[+]"):
+			name := strings.Fields(strings.TrimPrefix(line, "[+]"))
+			if len(name) > 0 {
+				checks[name[0]] = "ok"
+			}
+		case strings.HasPrefix(line, "[-]"):
+			rest := strings.TrimPrefix(line, "[-]")
+			name, reason, _ := strings.Cut(rest, " ")
+			checks[name] = strings.TrimSpace(reason)
+			failed = append(failed, name)
+		}
+	}
+	return checks, failed
+}
+
+// isPodReady 判断Pod是否就绪
+func isPodReady(pod corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
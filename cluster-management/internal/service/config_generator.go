@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/taichu-system/cluster-management/internal/model"
+	"sigs.k8s.io/yaml"
 )
 
 // MachineInfo 机器信息结构体
@@ -16,6 +17,7 @@ type MachineInfo struct {
 	IPAddress        string
 	InternalAddress  string
 	User             string
+	SSHPort          int
 	Role             string
 	RegistryAddress  string
 	ImageRepo        string
@@ -44,6 +46,26 @@ type CreateClusterRequest struct {
 	ArtifactPath       string
 	WithPackages       bool
 	AutoApprove        bool
+	Provisioner        string     // 部署驱动，为空时使用默认驱动
+	Addons             []AddonConfig
+	TemplateID         *uuid.UUID // 使用的集群模板版本
+	Topology           TopologyConfig
+}
+
+// AddonConfig 集群插件配置
+type AddonConfig struct {
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace"`
+	Chart     *AddonChart `json:"chart,omitempty"`
+	YamlPaths []string    `json:"yaml_paths,omitempty"`
+}
+
+// AddonChart Helm Chart 插件来源
+type AddonChart struct {
+	Name    string   `json:"name"`
+	Repo    string   `json:"repo"`
+	Version string   `json:"version"`
+	Values  []string `json:"values,omitempty"` // key=value
 }
 
 // ConfigGenerator 配置生成器
@@ -70,12 +92,12 @@ func (g *ConfigGenerator) GenerateConfig(req CreateClusterRequest, machines []*m
 			"name":             machine.Name,
 			"address":          machine.IPAddress,
 			"internalAddress":  machine.InternalAddress,
+			"port":             machine.SSHPort,
 			"user":             machine.User,
 			"role":             machine.Role,
 		}
 
-		// 添加密码（这里应该解密后使用，实际使用中需要从加密服务获取）
-		// host["password"] = decryptedPassword
+		// 凭据不写入生成的配置，执行时由 InjectCredentials 注入
 
 		hosts = append(hosts, host)
 
@@ -90,26 +112,39 @@ func (g *ConfigGenerator) GenerateConfig(req CreateClusterRequest, machines []*m
 		}
 	}
 
+	applyClusterDefaults(&req)
+	ApplyTopologyDefaults(&req.Topology, machines)
+	if err := ValidateTopology(req.Topology, machines); err != nil {
+		return "", err
+	}
+
+	// 独立 etcd 部署在 role=etcd 的机器上，否则与控制平面同机
+	if req.Topology.Etcd.Mode == EtcdModeStacked {
+		etcds = masters
+	}
+
 	// 获取注册表机器
-	var registryHost string
-	var registryPort string
+	var registries []string
+	var privateRegistry string
 	var imageRepo string
 
 	for _, machine := range machineInfos {
-		if machine.Role == "registry" && machine.RegistryAddress != "" {
-			registryHost = machine.IPAddress
-			registryPort = "5000" // 默认端口
-			imageRepo = machine.ImageRepo
-			break
+		if machine.Role == "registry" {
+			registries = append(registries, machine.Name)
+			if privateRegistry == "" && machine.RegistryAddress != "" {
+				privateRegistry = machine.RegistryAddress
+				imageRepo = machine.ImageRepo
+			}
 		}
 	}
+	if req.Topology.Registry.PrivateRegistry != "" {
+		privateRegistry = req.Topology.Registry.PrivateRegistry
+	}
 
-	// 如果没有专门的 registry 机器，使用第一个 master
-	if registryHost == "" && len(masters) > 0 {
+	// 如果没有专门的 registry 机器，使用第一个 master 的镜像仓库配置
+	if imageRepo == "" && len(masters) > 0 {
 		for _, machine := range machineInfos {
 			if machine.Name == masters[0] {
-				registryHost = machine.IPAddress
-				registryPort = "5000"
 				imageRepo = machine.ImageRepo
 				break
 			}
@@ -121,6 +156,47 @@ func (g *ConfigGenerator) GenerateConfig(req CreateClusterRequest, machines []*m
 		imageRepo = "kubesphere"
 	}
 
+	ha := req.Topology.HA
+	internalLoadbalancer := ""
+	if ha.Mode == HAModeKubeVIP || ha.Mode == HAModeHAProxy {
+		internalLoadbalancer = ha.Mode
+	}
+
+	// 构建配置数据
+	configData := map[string]interface{}{
+		"clusterName":          req.ClusterName,
+		"hosts":                hosts,
+		"masters":              masters,
+		"workers":              workers,
+		"etcds":                etcds,
+		"registries":           registries,
+		"kubernetesVersion":    req.Kubernetes.Version,
+		"imageRepo":            imageRepo,
+		"containerManager":     req.Kubernetes.ContainerManager,
+		"networkPlugin":        req.Network.Plugin,
+		"podsCIDR":             req.Network.PodsCIDR,
+		"serviceCIDR":          req.Network.ServiceCIDR,
+		"internalLoadbalancer": internalLoadbalancer,
+		"lbDomain":             ha.Domain,
+		"lbAddress":            ha.Address,
+		"lbPort":               ha.Port,
+		"extraSANs":            req.Topology.ExtraSANs,
+		"apiserverArgs":        sortedArgs(req.Topology.APIServerExtraArgs),
+		"kubeletArgs":          sortedArgs(req.Topology.KubeletExtraArgs),
+		"hasRegistryHosts":     len(registries) > 0,
+		"privateRegistry":      privateRegistry,
+		"namespaceOverride":    req.Topology.Registry.NamespaceOverride,
+		"registryMirrors":      req.Topology.Registry.Mirrors,
+		"insecureRegistries":   req.Topology.Registry.InsecureRegistries,
+		"addons":               req.Addons,
+	}
+
+	// 渲染模板
+	return g.renderTemplate(configData)
+}
+
+// applyClusterDefaults 填充网络与Kubernetes默认值
+func applyClusterDefaults(req *CreateClusterRequest) {
 	if req.Network.PodsCIDR == "" {
 		req.Network.PodsCIDR = "10.233.64.0/18"
 	}
@@ -136,27 +212,6 @@ func (g *ConfigGenerator) GenerateConfig(req CreateClusterRequest, machines []*m
 	if req.Kubernetes.ContainerManager == "" {
 		req.Kubernetes.ContainerManager = "containerd"
 	}
-
-	// 构建配置数据
-	configData := map[string]interface{}{
-		"clusterName":     req.ClusterName,
-		"hosts":           hosts,
-		"masters":         masters,
-		"workers":         workers,
-		"etcds":           etcds,
-		"kubernetesVersion": req.Kubernetes.Version,
-		"imageRepo":       imageRepo,
-		"containerManager": req.Kubernetes.ContainerManager,
-		"networkPlugin":   req.Network.Plugin,
-		"podsCIDR":        req.Network.PodsCIDR,
-		"serviceCIDR":     req.Network.ServiceCIDR,
-		"registryHost":    registryHost,
-		"registryPort":    registryPort,
-		"hasRegistry":     registryHost != "",
-	}
-
-	// 渲染模板
-	return g.renderTemplate(configData)
 }
 
 // convertMachines 转换机器模型
@@ -169,6 +224,7 @@ func (g *ConfigGenerator) convertMachines(machines []*model.Machine) []MachineIn
 			IPAddress:        machine.IPAddress,
 			InternalAddress:  machine.InternalAddress,
 			User:             machine.User,
+			SSHPort:          sshPortOrDefault(machine.SSHPort),
 			Role:             machine.Role,
 			RegistryAddress:  machine.RegistryAddress,
 			ImageRepo:        machine.ImageRepo,
@@ -189,6 +245,7 @@ spec:
   - name: {{.name}}
     address: {{.address}}
     internalAddress: {{.internalAddress}}
+    port: {{.port}}
     user: {{.user}}
     role: [{{.role}}]
 {{- end }}
@@ -210,34 +267,99 @@ spec:
 {{- range .workers }}
       - {{.}}
 {{- end }}
+{{- end }}
+{{- if .registries }}
+    registry:
+{{- range .registries }}
+      - {{.}}
+{{- end }}
 {{- end }}
   controlPlaneEndpoint:
-    domain: lb.kubesphere.local
-    address: ""
-    port: 6443
+{{- if .internalLoadbalancer }}
+    internalLoadbalancer: {{.internalLoadbalancer}}
+{{- end }}
+    domain: {{.lbDomain}}
+    address: "{{.lbAddress}}"
+    port: {{.lbPort}}
   kubernetes:
     version: {{.kubernetesVersion}}
     imageRepo: "{{.imageRepo}}"
-    etcd:
-      type: kubekey
     containerManager: {{.containerManager}}
+{{- if .extraSANs }}
+    apiserverCertExtraSans:
+{{- range .extraSANs }}
+    - {{printf "%q" .}}
+{{- end }}
+{{- end }}
+{{- if .apiserverArgs }}
+    apiserverArgs:
+{{- range .apiserverArgs }}
+    - {{printf "%q" .}}
+{{- end }}
+{{- end }}
+{{- if .kubeletArgs }}
+    kubeletArgs:
+{{- range .kubeletArgs }}
+    - {{printf "%q" .}}
+{{- end }}
+{{- end }}
+  etcd:
+    type: kubekey
   network:
     plugin: {{.networkPlugin}}
     kubePodsCIDR: {{.podsCIDR}}
     kubeServiceCIDR: {{.serviceCIDR}}
-{{- if .hasRegistry }}
   registry:
+{{- if .hasRegistryHosts }}
     type: kubekey
-    registries:
-      - {{.registryHost}}:{{.registryPort}}
+{{- end }}
+    privateRegistry: "{{.privateRegistry}}"
+    namespaceOverride: "{{.namespaceOverride}}"
+{{- if .registryMirrors }}
+    registryMirrors:
+{{- range .registryMirrors }}
+    - {{printf "%q" .}}
+{{- end }}
 {{- else }}
-  registry:
-    type: kubekey
-    insecureRegistry: false
     registryMirrors: []
-    privateRegistry: ""
 {{- end }}
+{{- if .insecureRegistries }}
+    insecureRegistries:
+{{- range .insecureRegistries }}
+    - {{printf "%q" .}}
+{{- end }}
+{{- else }}
+    insecureRegistries: []
+{{- end }}
+{{- if .addons }}
+  addons:
+{{- range .addons }}
+  - name: {{.Name}}
+    namespace: {{.Namespace}}
+    sources:
+{{- if .Chart }}
+      chart:
+        name: {{.Chart.Name}}
+        repo: {{.Chart.Repo}}
+        version: "{{.Chart.Version}}"
+{{- if .Chart.Values }}
+        values:
+{{- range .Chart.Values }}
+        - {{.}}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- if .YamlPaths }}
+      yaml:
+        path:
+{{- range .YamlPaths }}
+        - {{.}}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- else }}
   addons: []
+{{- end }}
 `
 
 	t, err := template.New("config").Parse(tmpl)
@@ -252,3 +374,100 @@ spec:
 
 	return buf.String(), nil
 }
+
+// InjectCredentials 将解密后的SSH凭据注入配置，返回的内容只应写入临时文件
+// creds 以主机名为键；带口令的私钥会先解密，因为 kk 不支持私钥口令
+func (g *ConfigGenerator) InjectCredentials(configYaml string, creds map[string]*SSHAuth) (string, error) {
+	var config map[string]interface{}
+	if err := yaml.Unmarshal([]byte(configYaml), &config); err != nil {
+		return "", fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	spec, ok := config["spec"].(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("config has no spec")
+	}
+	hosts, ok := spec["hosts"].([]interface{})
+	if !ok {
+		return "", fmt.Errorf("config has no hosts")
+	}
+
+	for _, item := range hosts {
+		host, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := host["name"].(string)
+		auth, ok := creds[name]
+		if !ok || auth == nil {
+			return "", fmt.Errorf("no credential for host %s", name)
+		}
+
+		if auth.Username != "" {
+			host["user"] = auth.Username
+		}
+		if auth.PrivateKey != "" {
+			privateKey, err := DecryptSSHPrivateKey(auth.PrivateKey, auth.Passphrase)
+			if err != nil {
+				return "", fmt.Errorf("host %s: %w", name, err)
+			}
+			host["privateKey"] = privateKey
+		}
+		if auth.Password != "" {
+			host["password"] = auth.Password
+		}
+	}
+
+	out, err := yaml.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return string(out), nil
+}
+
+// InjectRegistryAuths 将镜像仓库认证写入 spec.registry.auths，返回的内容只应写入临时文件
+func (g *ConfigGenerator) InjectRegistryAuths(configYaml string, auths map[string]RegistryAuth) (string, error) {
+	if len(auths) == 0 {
+		return configYaml, nil
+	}
+
+	var config map[string]interface{}
+	if err := yaml.Unmarshal([]byte(configYaml), &config); err != nil {
+		return "", fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	spec, ok := config["spec"].(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("config has no spec")
+	}
+	registry, ok := spec["registry"].(map[string]interface{})
+	if !ok {
+		registry = make(map[string]interface{})
+		spec["registry"] = registry
+	}
+
+	entries := make(map[string]interface{}, len(auths))
+	for host, auth := range auths {
+		entries[host] = map[string]interface{}{
+			"username":      auth.Username,
+			"password":      auth.Password,
+			"skipTLSVerify": auth.SkipTLSVerify,
+			"plainHTTP":     auth.PlainHTTP,
+		}
+	}
+	registry["auths"] = entries
+
+	out, err := yaml.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return string(out), nil
+}
+
+// sshPortOrDefault 未设置端口时使用22
+func sshPortOrDefault(port int) int {
+	if port == 0 {
+		return 22
+	}
+	return port
+}
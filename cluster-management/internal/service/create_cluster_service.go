@@ -1,13 +1,10 @@
 package service
 
 import (
-	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"os"
-	"os/exec"
-	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,26 +15,53 @@ import (
 
 // CreateClusterService 集群创建服务
 type CreateClusterService struct {
-	taskRepo       *repository.CreateTaskRepository
-	machineService *MachineService
-	configGen      *ConfigGenerator
+	taskRepo          *repository.CreateTaskRepository
+	machineService    *MachineService
+	provisioners      *ProvisionerRegistry
+	templateService   *ClusterTemplateService
+	clusterService    *ClusterService
+	encryptionService *EncryptionService
 }
 
 // NewCreateClusterService 创建集群创建服务
 func NewCreateClusterService(
 	taskRepo *repository.CreateTaskRepository,
 	machineService *MachineService,
-	configGen *ConfigGenerator,
+	provisioners *ProvisionerRegistry,
+	templateService *ClusterTemplateService,
+	clusterService *ClusterService,
+	encryptionService *EncryptionService,
 ) *CreateClusterService {
 	return &CreateClusterService{
-		taskRepo:       taskRepo,
-		machineService: machineService,
-		configGen:      configGen,
+		taskRepo:          taskRepo,
+		machineService:    machineService,
+		provisioners:      provisioners,
+		templateService:   templateService,
+		clusterService:    clusterService,
+		encryptionService: encryptionService,
 	}
 }
 
 // CreateCluster 创建集群
 func (s *CreateClusterService) CreateCluster(req CreateClusterRequest) (*model.CreateTask, error) {
+	// 使用模板时以模板内容为准
+	var template *model.ClusterTemplate
+	if req.TemplateID != nil {
+		var err error
+		template, err = s.templateService.GetTemplate(*req.TemplateID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.templateService.ApplyTemplate(template, &req); err != nil {
+			return nil, err
+		}
+	}
+
+	provisioner, err := s.provisioners.Get(req.Provisioner)
+	if err != nil {
+		return nil, err
+	}
+
 	// 验证机器配置
 	if err := s.machineService.ValidateClusterNodes(req.MachineIDs); err != nil {
 		return nil, err
@@ -49,28 +73,66 @@ func (s *CreateClusterService) CreateCluster(req CreateClusterRequest) (*model.C
 		return nil, fmt.Errorf("failed to get machines: %w", err)
 	}
 
+	// 检查机器凭据
+	for _, machine := range machines {
+		if err := s.machineService.ValidateMachineAuth(machine); err != nil {
+			return nil, err
+		}
+	}
+
+	if template != nil {
+		if err := s.templateService.CheckRoleRequirements(template, machines); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := ValidateAddons(req.Addons); err != nil {
+		return nil, err
+	}
+	addons, err := AddonsToJSONMap(req.Addons)
+	if err != nil {
+		return nil, fmt.Errorf("invalid addons: %w", err)
+	}
+
 	// 生成配置文件
-	configYaml, err := s.configGen.GenerateConfig(req, machines)
+	configYaml, err := provisioner.RenderConfig(req, machines)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate config: %w", err)
 	}
 
+	registryAuths, err := s.encryptRegistryAuths(req.Topology.Registry.Auths)
+	if err != nil {
+		return nil, err
+	}
+
 	// 创建任务记录
 	task := &model.CreateTask{
-		ClusterName:       req.ClusterName,
-		MachineIDs:        convertUUIDsToJSONMap(req.MachineIDs),
-		ConfigYaml:        configYaml,
-		Status:            "pending",
-		Progress:          0,
-		CurrentStep:       "Preparing configuration",
-		ArtifactPath:      req.ArtifactPath,
-		WithPackages:      req.WithPackages,
-		AutoApprove:       req.AutoApprove,
-		KubernetesVersion: req.Kubernetes.Version,
-		NetworkPlugin:     req.Network.Plugin,
-	}
-
-	if err := s.taskRepo.Create(task); err != nil {
+		ClusterName:            req.ClusterName,
+		MachineIDs:             convertUUIDsToJSONMap(req.MachineIDs),
+		ConfigYaml:             configYaml,
+		Provisioner:            provisioner.Name(),
+		Status:                 "pending",
+		Progress:               0,
+		CurrentStep:            "Preparing configuration",
+		ArtifactPath:           req.ArtifactPath,
+		WithPackages:           req.WithPackages,
+		AutoApprove:            req.AutoApprove,
+		KubernetesVersion:      req.Kubernetes.Version,
+		NetworkPlugin:          req.Network.Plugin,
+		Addons:                 addons,
+		RegistryAuthsEncrypted: registryAuths,
+	}
+	if template != nil {
+		task.TemplateID = &template.ID
+		task.TemplateName = template.Name
+		task.TemplateVersion = template.Version
+	}
+
+	// 部署期间占用机器，避免被其他任务选中；与任务记录在同一事务中写入
+	if err := s.taskRepo.CreateReservingMachines(task, req.MachineIDs); err != nil {
+		if errors.Is(err, repository.ErrMachinesUnavailable) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to create task: %w", err)
 	}
 
@@ -90,6 +152,11 @@ func (s *CreateClusterService) ListTasks(page, limit int) ([]*model.CreateTask,
 	return s.taskRepo.List(page, limit)
 }
 
+// ListProvisioners 获取可用的部署驱动及默认驱动
+func (s *CreateClusterService) ListProvisioners() ([]string, string) {
+	return s.provisioners.Names(), s.provisioners.DefaultDriver()
+}
+
 // executeCreateTask 执行创建任务（异步）
 func (s *CreateClusterService) executeCreateTask(taskID uuid.UUID) {
 	// 获取任务
@@ -99,179 +166,206 @@ func (s *CreateClusterService) executeCreateTask(taskID uuid.UUID) {
 	}
 
 	// 更新状态为运行中
-	s.taskRepo.UpdateStatus(taskID, "running")
-	s.taskRepo.UpdateProgress(taskID, 0, "Starting cluster creation")
+	now := time.Now()
+	s.taskRepo.UpdateFields(taskID, map[string]interface{}{
+		"status":       constants.StatusRunning,
+		"progress":     0,
+		"current_step": "Starting cluster creation",
+		"started_at":   &now,
+	})
+
+	err = s.runCreate(task)
 
-	// 创建临时配置文件
-	configFile, err := s.createTempConfigFile(task.ConfigYaml)
+	completedAt := time.Now()
+	fields := map[string]interface{}{
+		"completed_at": &completedAt,
+	}
 	if err != nil {
-		s.taskRepo.UpdateStatus(taskID, "failed")
-		s.taskRepo.UpdateProgress(taskID, 0, fmt.Sprintf("Failed to create config file: %v", err))
-		return
+		fields["status"] = constants.StatusFailed
+		fields["current_step"] = fmt.Sprintf("Cluster creation failed: %v", err)
+		fields["error_msg"] = err.Error()
+		s.releaseTaskMachines(task)
+	} else {
+		fields["status"] = constants.StatusSuccess
+		fields["progress"] = 100
+		fields["current_step"] = "Cluster creation completed successfully"
 	}
-	defer os.Remove(configFile)
+	s.taskRepo.UpdateFields(taskID, fields)
+}
 
-	// 准备 kk 命令
-	cmdArgs := []string{"create", "cluster", "-f", configFile}
-	if task.ArtifactPath != "" {
-		cmdArgs = append(cmdArgs, "-a", task.ArtifactPath)
+// runCreate 使用任务记录的驱动创建集群，成功后登记到集群列表
+func (s *CreateClusterService) runCreate(task *model.CreateTask) error {
+	// 历史任务未记录驱动，均由 kk 创建
+	driver := task.Provisioner
+	if driver == "" {
+		driver = ProvisionerKK
 	}
-	if task.WithPackages {
-		cmdArgs = append(cmdArgs, "--with-packages")
+	provisioner, err := s.provisioners.Get(driver)
+	if err != nil {
+		return err
 	}
-	if task.AutoApprove {
-		cmdArgs = append(cmdArgs, "--yes")
+
+	op, err := s.buildOperation(task)
+	if err != nil {
+		return fmt.Errorf("failed to resolve machine credentials: %w", err)
 	}
 
-	// 启动命令
-	cmd := exec.Command("kk", cmdArgs...)
-	cmd.Dir = "." // 设置工作目录
+	if err := provisioner.Create(context.Background(), op); err != nil {
+		return err
+	}
 
-	// 设置环境变量
-	cmd.Env = append(os.Environ(),
-		"KK_ZONE=cn", // 使用中国区域
-	)
+	// 集群已部署成功，登记失败不回滚，仅记录日志供人工导入
+	if err := s.registerCluster(task, provisioner, op); err != nil {
+		op.Reporter.Log(fmt.Sprintf("failed to register cluster: %v", err), true)
+		machineIDs, _ := parseJSONMapUUIDs(task.MachineIDs)
+		s.machineService.SetMachinesStatus(machineIDs, model.MachineStatusInUse)
+	}
+	return nil
+}
 
-	stdout, err := cmd.StdoutPipe()
+// registerCluster 获取 kubeconfig 并登记集群，同时关联任务与机器
+func (s *CreateClusterService) registerCluster(task *model.CreateTask, provisioner Provisioner, op *ProvisionOperation) error {
+	kubeconfig, err := provisioner.FetchKubeconfig(context.Background(), op)
 	if err != nil {
-		s.taskRepo.UpdateStatus(taskID, constants.StatusFailed)
-		s.taskRepo.UpdateProgress(taskID, 0, fmt.Sprintf("Failed to create stdout pipe: %v", err))
-		return
+		return fmt.Errorf("failed to fetch kubeconfig: %w", err)
 	}
-	defer stdout.Close()
 
-	stderr, err := cmd.StderrPipe()
+	encrypted, err := s.encryptionService.Encrypt(kubeconfig)
 	if err != nil {
-		s.taskRepo.UpdateStatus(taskID, "failed")
-		s.taskRepo.UpdateProgress(taskID, 0, fmt.Sprintf("Failed to create stderr pipe: %v", err))
-		return
+		return fmt.Errorf("failed to encrypt kubeconfig: %w", err)
 	}
-	defer stderr.Close()
 
-	// 更新开始时间
-	now := time.Now()
-	task.StartedAt = &now
-	s.taskRepo.Update(task)
+	cluster, err := s.clusterService.Create(&model.Cluster{
+		Name:                task.ClusterName,
+		KubeconfigEncrypted: encrypted,
+		Version:             task.KubernetesVersion,
+		ImportSource:        constants.ClusterSourcePlatform,
+		Labels: model.JSONMap{
+			"provisioner":    provisioner.Name(),
+			"create_task_id": task.ID.String(),
+		},
+	})
+	if err != nil {
+		return err
+	}
 
-	// 启动命令
-	if err := cmd.Start(); err != nil {
-		s.taskRepo.UpdateStatus(taskID, "failed")
-		s.taskRepo.UpdateProgress(taskID, 0, fmt.Sprintf("Failed to start command: %v", err))
-		return
+	if err := s.taskRepo.UpdateFields(task.ID, map[string]interface{}{"cluster_id": cluster.ID}); err != nil {
+		return err
 	}
 
-	// 实时读取日志
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	machineIDs := make([]uuid.UUID, 0, len(op.Machines))
+	for _, machine := range op.Machines {
+		machineIDs = append(machineIDs, machine.ID)
+	}
+	if err := s.machineService.BindMachinesToCluster(machineIDs, cluster.ID); err != nil {
+		return err
+	}
 
-	// 处理 stdout
-	go s.readOutput(ctx, taskID, stdout, false)
-	// 处理 stderr
-	go s.readOutput(ctx, taskID, stderr, true)
+	op.Reporter.Log(fmt.Sprintf("cluster %s registered with id %s", cluster.Name, cluster.ID), false)
+	return nil
+}
 
-	// 等待命令完成
-	err = cmd.Wait()
+// releaseTaskMachines 创建失败后将机器放回可用池
+func (s *CreateClusterService) releaseTaskMachines(task *model.CreateTask) {
+	machineIDs, err := parseJSONMapUUIDs(task.MachineIDs)
+	if err != nil {
+		return
+	}
+	s.machineService.ReleaseMachines(machineIDs)
+}
 
-	// 更新完成时间
-	completedAt := time.Now()
-	task.CompletedAt = &completedAt
+// buildOperation 加载任务机器并解密凭据，凭据仅保存在内存中
+func (s *CreateClusterService) buildOperation(task *model.CreateTask) (*ProvisionOperation, error) {
+	machineIDs, err := parseJSONMapUUIDs(task.MachineIDs)
+	if err != nil {
+		return nil, err
+	}
 
+	machines, err := s.machineService.GetMachinesByIDs(machineIDs)
 	if err != nil {
-		s.taskRepo.UpdateStatus(taskID, constants.StatusFailed)
-		s.taskRepo.UpdateProgress(taskID, task.Progress, fmt.Sprintf("Cluster creation failed: %v", err))
-	} else {
-		s.taskRepo.UpdateStatus(taskID, constants.StatusSuccess)
-		s.taskRepo.UpdateProgress(taskID, 100, "Cluster creation completed successfully")
+		return nil, fmt.Errorf("failed to get machines: %w", err)
 	}
 
-	s.taskRepo.Update(task)
-}
+	addons, err := AddonsFromJSONMap(task.Addons)
+	if err != nil {
+		return nil, fmt.Errorf("invalid addons: %w", err)
+	}
 
-// readOutput 读取命令输出
-func (s *CreateClusterService) readOutput(ctx context.Context, taskID uuid.UUID, reader io.ReadCloser, isError bool) {
-	scanner := bufio.NewScanner(reader)
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-			if !scanner.Scan() {
-				return
-			}
-
-			line := scanner.Text()
-			timestamp := time.Now().Format("2006-01-02 15:04:05")
-
-			// 格式化日志行
-			logLine := fmt.Sprintf("[%s] %s\n", timestamp, line)
-			if isError {
-				logLine = fmt.Sprintf("[%s] ERROR: %s\n", timestamp, line)
-			}
-
-			// 追加日志
-			s.taskRepo.AppendLogs(taskID, logLine)
-
-			// 解析进度
-			s.updateProgressFromLog(taskID, line)
+	creds := make(map[string]*SSHAuth, len(machines))
+	for _, machine := range machines {
+		auth, err := s.machineService.ResolveSSHAuth(machine)
+		if err != nil {
+			return nil, err
 		}
+		creds[machine.Name] = auth
 	}
-}
 
-// updateProgressFromLog 从日志中更新进度
-func (s *CreateClusterService) updateProgressFromLog(taskID uuid.UUID, logLine string) {
-	// 解析不同阶段的进度
-	var progress int
-	var currentStep string
-
-	switch {
-	case strings.Contains(logLine, "Checking an existing installation"):
-		progress = 5
-		currentStep = "Checking existing installation"
-	case strings.Contains(logLine, "Preparing for installation"):
-		progress = 10
-		currentStep = "Preparing for installation"
-	case strings.Contains(logLine, "Downloading"):
-		progress = 20
-		currentStep = "Downloading required packages"
-	case strings.Contains(logLine, "downloading kubeadm"):
-		progress = 30
-		currentStep = "Downloading kubeadm binary"
-	case strings.Contains(logLine, "pulling images"):
-		progress = 40
-		currentStep = "Pulling container images"
-	case strings.Contains(logLine, "Installing"):
-		progress = 60
-		currentStep = "Installing Kubernetes components"
-	case strings.Contains(logLine, "Configuring"):
-		progress = 80
-		currentStep = "Configuring cluster"
-	case strings.Contains(logLine, "Installing kubesphere"):
-		progress = 90
-		currentStep = "Installing KubeSphere"
-	case strings.Contains(logLine, "successfully installed") || strings.Contains(logLine, "KubeSphere is successfully installed"):
-		progress = 100
-		currentStep = "Installation completed"
-	}
-
-	if progress > 0 {
-		s.taskRepo.UpdateProgress(taskID, progress, currentStep)
+	registryAuths, err := s.decryptRegistryAuths(task.RegistryAuthsEncrypted)
+	if err != nil {
+		return nil, err
 	}
+
+	return &ProvisionOperation{
+		ClusterName:   task.ClusterName,
+		NetworkPlugin: task.NetworkPlugin,
+		ConfigYaml:    task.ConfigYaml,
+		Machines:      machines,
+		Credentials:   creds,
+		Addons:        addons,
+		RegistryAuths: registryAuths,
+		ArtifactPath:  task.ArtifactPath,
+		WithPackages:  task.WithPackages,
+		AutoApprove:   task.AutoApprove,
+		Reporter:      &taskReporter{taskRepo: s.taskRepo, taskID: task.ID},
+	}, nil
 }
 
-// createTempConfigFile 创建临时配置文件
-func (s *CreateClusterService) createTempConfigFile(configYaml string) (string, error) {
-	tmpFile, err := os.CreateTemp("", "kubekey-config-*.yaml")
+// encryptRegistryAuths 加密镜像仓库认证，未配置时返回空字符串
+func (s *CreateClusterService) encryptRegistryAuths(auths map[string]RegistryAuth) (string, error) {
+	if len(auths) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(auths)
 	if err != nil {
 		return "", err
 	}
-	defer tmpFile.Close()
+	encrypted, err := s.encryptionService.Encrypt(string(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt registry auths: %w", err)
+	}
+	return encrypted, nil
+}
 
-	if _, err := tmpFile.WriteString(configYaml); err != nil {
-		os.Remove(tmpFile.Name())
-		return "", err
+// decryptRegistryAuths 解密镜像仓库认证
+func (s *CreateClusterService) decryptRegistryAuths(encrypted string) (map[string]RegistryAuth, error) {
+	if encrypted == "" {
+		return nil, nil
+	}
+	data, err := s.encryptionService.Decrypt(encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt registry auths: %w", err)
+	}
+	var auths map[string]RegistryAuth
+	if err := json.Unmarshal([]byte(data), &auths); err != nil {
+		return nil, fmt.Errorf("invalid registry auths: %w", err)
 	}
+	return auths, nil
+}
+
+// taskReporter 将驱动日志与进度写入创建任务
+type taskReporter struct {
+	taskRepo *repository.CreateTaskRepository
+	taskID   uuid.UUID
+}
+
+// Log 追加带时间戳的日志
+func (r *taskReporter) Log(line string, isError bool) {
+	appendTimestampedLog(func(log string) { r.taskRepo.AppendLogs(r.taskID, log) }, line, isError)
+}
 
-	return tmpFile.Name(), nil
+// Progress 更新进度
+func (r *taskReporter) Progress(progress int, step string) {
+	r.taskRepo.UpdateProgress(r.taskID, progress, step)
 }
 
 // convertUUIDsToJSONMap 转换UUID列表为JSON
@@ -282,3 +376,20 @@ func convertUUIDsToJSONMap(ids []uuid.UUID) map[string]interface{} {
 	}
 	return result
 }
+
+// parseJSONMapUUIDs 解析 convertUUIDsToJSONMap 生成的UUID列表
+func parseJSONMapUUIDs(m model.JSONMap) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(m))
+	for i := 0; i < len(m); i++ {
+		value, ok := m[fmt.Sprintf("%d", i)].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid machine id at index %d", i)
+		}
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid machine id %s: %w", value, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/constants"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"github.com/taichu-system/cluster-management/internal/repository"
+	"github.com/taichu-system/cluster-management/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// openCreateClusterDB 创建流程涉及的表
+func openCreateClusterDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	return testutil.OpenDB(t,
+		&model.Cluster{}, &model.ClusterState{}, &model.CreateTask{}, &model.ClusterTemplate{},
+		&model.Machine{}, &model.MachineCredential{},
+	)
+}
+
+// createClusterFixture 使用模拟驱动的创建服务与两台可用机器
+type createClusterFixture struct {
+	service    *CreateClusterService
+	fake       *FakeProvisioner
+	taskRepo   *repository.CreateTaskRepository
+	machineIDs []uuid.UUID
+	machines   *MachineService
+}
+
+func newCreateClusterFixture(t *testing.T, db *gorm.DB) *createClusterFixture {
+	t.Helper()
+	encryption, err := NewEncryptionService("test-encryption-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := NewFakeProvisioner()
+	registry := NewProvisionerRegistry(ProvisionerFake, fake)
+	taskRepo := repository.NewCreateTaskRepository(db)
+	machineService := NewMachineService(repository.NewMachineRepository(db), repository.NewMachineCredentialRepository(db), encryption)
+	clusterService := NewClusterService(repository.NewClusterRepository(db), nil, nil, encryption, nil, nil, nil)
+	templateService := NewClusterTemplateService(repository.NewClusterTemplateRepository(db), registry)
+
+	f := &createClusterFixture{
+		service:  NewCreateClusterService(taskRepo, machineService, registry, templateService, clusterService, encryption),
+		fake:     fake,
+		taskRepo: taskRepo,
+		machines: machineService,
+	}
+	suffix := uuid.NewString()[:8]
+	for i, role := range []string{"master", "worker"} {
+		machine := &model.Machine{
+			ID:        uuid.New(),
+			Name:      fmt.Sprintf("%s-%s", role, suffix),
+			IPAddress: fmt.Sprintf("10.0.0.%d", i+1),
+			User:      "root",
+			Password:  "secret",
+			AuthType:  model.MachineAuthTypePassword,
+			SSHPort:   22,
+			Role:      role,
+			Status:    model.MachineStatusAvailable,
+		}
+		if err := machineService.CreateMachine(machine); err != nil {
+			t.Fatalf("failed to create machine: %v", err)
+		}
+		f.machineIDs = append(f.machineIDs, machine.ID)
+	}
+	return f
+}
+
+// waitForTask 等待异步执行的创建任务结束
+func (f *createClusterFixture) waitForTask(t *testing.T, id uuid.UUID) *model.CreateTask {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		task, err := f.taskRepo.GetByID(id)
+		if err != nil {
+			t.Fatalf("failed to get task: %v", err)
+		}
+		if task.Status == constants.StatusSuccess || task.Status == constants.StatusFailed {
+			return task
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("task %s did not finish in time", id)
+	return nil
+}
+
+func TestCreateClusterWithFakeProvisioner(t *testing.T) {
+	f := newCreateClusterFixture(t, openCreateClusterDB(t))
+
+	task, err := f.service.CreateCluster(CreateClusterRequest{
+		ClusterName: "fake-" + uuid.NewString()[:8],
+		MachineIDs:  f.machineIDs,
+		Provisioner: ProvisionerFake,
+		Kubernetes:  KubernetesConfig{Version: "v1.28.3"},
+		Network:     NetworkConfig{Plugin: "calico"},
+	})
+	if err != nil {
+		t.Fatalf("CreateCluster failed: %v", err)
+	}
+	if task.Provisioner != ProvisionerFake {
+		t.Errorf("task provisioner = %q, want %q", task.Provisioner, ProvisionerFake)
+	}
+
+	task = f.waitForTask(t, task.ID)
+	if task.Status != constants.StatusSuccess {
+		t.Fatalf("task status = %q (%s), want %q", task.Status, task.ErrorMsg, constants.StatusSuccess)
+	}
+	if task.Provisioner != ProvisionerFake {
+		t.Errorf("stored task provisioner = %q, want %q", task.Provisioner, ProvisionerFake)
+	}
+	if task.ClusterID == nil {
+		t.Fatal("task was not linked to the registered cluster")
+	}
+
+	machines, err := f.machines.GetMachinesByIDs(f.machineIDs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, machine := range machines {
+		if machine.Status != model.MachineStatusInUse {
+			t.Errorf("machine %s status = %q, want %q", machine.Name, machine.Status, model.MachineStatusInUse)
+		}
+		if machine.ClusterID == nil || *machine.ClusterID != *task.ClusterID {
+			t.Errorf("machine %s is not bound to cluster %s", machine.Name, *task.ClusterID)
+		}
+	}
+
+	calls := strings.Join(f.fake.Calls(), ",")
+	if calls != "render,create,kubeconfig" {
+		t.Errorf("provisioner calls = %s, want render,create,kubeconfig", calls)
+	}
+}
+
+func TestCreateClusterReleasesMachinesOnFailure(t *testing.T) {
+	f := newCreateClusterFixture(t, openCreateClusterDB(t))
+	f.fake.FailOn("create", errors.New("simulated failure"))
+
+	task, err := f.service.CreateCluster(CreateClusterRequest{
+		ClusterName: "fake-" + uuid.NewString()[:8],
+		MachineIDs:  f.machineIDs,
+		Provisioner: ProvisionerFake,
+	})
+	if err != nil {
+		t.Fatalf("CreateCluster failed: %v", err)
+	}
+
+	task = f.waitForTask(t, task.ID)
+	if task.Status != constants.StatusFailed {
+		t.Fatalf("task status = %q, want %q", task.Status, constants.StatusFailed)
+	}
+	machines, err := f.machines.GetMachinesByIDs(f.machineIDs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, machine := range machines {
+		if machine.Status != model.MachineStatusAvailable {
+			t.Errorf("machine %s status = %q, want %q", machine.Name, machine.Status, model.MachineStatusAvailable)
+		}
+	}
+}
+
+func TestCreateClusterRejectsReservedMachines(t *testing.T) {
+	f := newCreateClusterFixture(t, openCreateClusterDB(t))
+	// 模拟另一任务在校验之后抢先占用了机器
+	if err := f.machines.SetMachinesStatus(f.machineIDs[1:], model.MachineStatusDeploying); err != nil {
+		t.Fatal(err)
+	}
+
+	task := &model.CreateTask{ClusterName: "fake-" + uuid.NewString()[:8], Provisioner: ProvisionerFake, Status: "pending"}
+	err := f.taskRepo.CreateReservingMachines(task, f.machineIDs)
+	if !errors.Is(err, repository.ErrMachinesUnavailable) {
+		t.Fatalf("CreateReservingMachines error = %v, want ErrMachinesUnavailable", err)
+	}
+	if _, err := f.taskRepo.GetByID(task.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("task row should be rolled back, got err = %v", err)
+	}
+	machine, err := f.machines.GetMachine(f.machineIDs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if machine.Status != model.MachineStatusAvailable {
+		t.Errorf("machine %s status = %q, want %q", machine.Name, machine.Status, model.MachineStatusAvailable)
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+// deprecatedAPI 已弃用或已移除的 API 版本，版本号为 Kubernetes 次版本
+type deprecatedAPI struct {
+	Group        string
+	Version      string
+	Kind         string
+	DeprecatedIn string
+	RemovedIn    string
+	Replacement  string
+}
+
+// apiVersion 返回 group/version 形式的 apiVersion
+func (d deprecatedAPI) apiVersion() string {
+	if d.Group == "" {
+		return d.Version
+	}
+	return d.Group + "/" + d.Version
+}
+
+// deprecatedAPICatalog 上游 Deprecated API Migration Guide 中列出的移除项
+var deprecatedAPICatalog = []deprecatedAPI{
+	// v1.16
+	{"extensions", "v1beta1", "Deployment", "v1.9", "v1.16", "apps/v1"},
+	{"extensions", "v1beta1", "DaemonSet", "v1.9", "v1.16", "apps/v1"},
+	{"extensions", "v1beta1", "ReplicaSet", "v1.9", "v1.16", "apps/v1"},
+	{"extensions", "v1beta1", "NetworkPolicy", "v1.9", "v1.16", "networking.k8s.io/v1"},
+	{"extensions", "v1beta1", "PodSecurityPolicy", "v1.10", "v1.16", "policy/v1beta1"},
+	{"apps", "v1beta1", "Deployment", "v1.9", "v1.16", "apps/v1"},
+	{"apps", "v1beta1", "StatefulSet", "v1.9", "v1.16", "apps/v1"},
+	{"apps", "v1beta2", "Deployment", "v1.9", "v1.16", "apps/v1"},
+	{"apps", "v1beta2", "StatefulSet", "v1.9", "v1.16", "apps/v1"},
+	{"apps", "v1beta2", "DaemonSet", "v1.9", "v1.16", "apps/v1"},
+	{"apps", "v1beta2", "ReplicaSet", "v1.9", "v1.16", "apps/v1"},
+
+	// v1.22
+	{"admissionregistration.k8s.io", "v1beta1", "MutatingWebhookConfiguration", "v1.16", "v1.22", "admissionregistration.k8s.io/v1"},
+	{"admissionregistration.k8s.io", "v1beta1", "ValidatingWebhookConfiguration", "v1.16", "v1.22", "admissionregistration.k8s.io/v1"},
+	{"apiextensions.k8s.io", "v1beta1", "CustomResourceDefinition", "v1.16", "v1.22", "apiextensions.k8s.io/v1"},
+	{"apiregistration.k8s.io", "v1beta1", "APIService", "v1.19", "v1.22", "apiregistration.k8s.io/v1"},
+	{"certificates.k8s.io", "v1beta1", "CertificateSigningRequest", "v1.19", "v1.22", "certificates.k8s.io/v1"},
+	{"coordination.k8s.io", "v1beta1", "Lease", "v1.19", "v1.22", "coordination.k8s.io/v1"},
+	{"extensions", "v1beta1", "Ingress", "v1.14", "v1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io", "v1beta1", "Ingress", "v1.19", "v1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io", "v1beta1", "IngressClass", "v1.19", "v1.22", "networking.k8s.io/v1"},
+	{"rbac.authorization.k8s.io", "v1beta1", "ClusterRole", "v1.17", "v1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io", "v1beta1", "ClusterRoleBinding", "v1.17", "v1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io", "v1beta1", "Role", "v1.17", "v1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io", "v1beta1", "RoleBinding", "v1.17", "v1.22", "rbac.authorization.k8s.io/v1"},
+	{"scheduling.k8s.io", "v1beta1", "PriorityClass", "v1.14", "v1.22", "scheduling.k8s.io/v1"},
+	{"storage.k8s.io", "v1beta1", "CSIDriver", "v1.19", "v1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io", "v1beta1", "CSINode", "v1.17", "v1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io", "v1beta1", "StorageClass", "v1.19", "v1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io", "v1beta1", "VolumeAttachment", "v1.19", "v1.22", "storage.k8s.io/v1"},
+
+	// v1.25
+	{"batch", "v1beta1", "CronJob", "v1.21", "v1.25", "batch/v1"},
+	{"discovery.k8s.io", "v1beta1", "EndpointSlice", "v1.21", "v1.25", "discovery.k8s.io/v1"},
+	{"events.k8s.io", "v1beta1", "Event", "v1.19", "v1.25", "events.k8s.io/v1"},
+	{"autoscaling", "v2beta1", "HorizontalPodAutoscaler", "v1.22", "v1.25", "autoscaling/v2"},
+	{"policy", "v1beta1", "PodDisruptionBudget", "v1.21", "v1.25", "policy/v1"},
+	{"policy", "v1beta1", "PodSecurityPolicy", "v1.21", "v1.25", ""},
+	{"node.k8s.io", "v1beta1", "RuntimeClass", "v1.20", "v1.25", "node.k8s.io/v1"},
+
+	// v1.26
+	{"flowcontrol.apiserver.k8s.io", "v1beta1", "FlowSchema", "v1.23", "v1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io", "v1beta1", "PriorityLevelConfiguration", "v1.23", "v1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	{"autoscaling", "v2beta2", "HorizontalPodAutoscaler", "v1.23", "v1.26", "autoscaling/v2"},
+
+	// v1.27
+	{"storage.k8s.io", "v1beta1", "CSIStorageCapacity", "v1.24", "v1.27", "storage.k8s.io/v1"},
+
+	// v1.29
+	{"flowcontrol.apiserver.k8s.io", "v1beta2", "FlowSchema", "v1.26", "v1.29", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io", "v1beta2", "PriorityLevelConfiguration", "v1.26", "v1.29", "flowcontrol.apiserver.k8s.io/v1"},
+
+	// v1.32
+	{"flowcontrol.apiserver.k8s.io", "v1beta3", "FlowSchema", "v1.29", "v1.32", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io", "v1beta3", "PriorityLevelConfiguration", "v1.29", "v1.32", "flowcontrol.apiserver.k8s.io/v1"},
+}
@@ -0,0 +1,484 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/repository"
+	"gorm.io/gorm"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// ErrInvalidTargetVersion 目标版本格式无效
+var ErrInvalidTargetVersion = errors.New("invalid target version")
+
+// 弃用API的发现来源
+const (
+	DeprecatedAPISourceLive        = "live"         // 集群中仅以该版本存储的对象
+	DeprecatedAPISourceLastApplied = "last_applied" // kubectl apply 记录的配置
+	DeprecatedAPISourceHelm        = "helm"         // Helm 发布清单
+)
+
+// 弃用API在目标版本中的状态
+const (
+	DeprecatedAPIStatusDeprecated = "deprecated"
+	DeprecatedAPIStatusRemoved    = "removed"
+)
+
+const (
+	lastAppliedAnnotation     = "kubectl.kubernetes.io/last-applied-configuration"
+	helmReleaseNameAnnotation = "meta.helm.sh/release-name"
+	helmReleaseSelector       = "owner=helm,status=deployed"
+	partialMetadataListAccept = "application/json;as=PartialObjectMetadataList;v=v1;g=meta.k8s.io,application/json"
+	deprecatedAPIListPageSize = "500"
+	deprecatedAPIScanTimeout  = 2 * time.Minute
+)
+
+var yamlDocumentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+// DeprecatedAPIFinding 使用弃用API的对象
+type DeprecatedAPIFinding struct {
+	Source          string     `json:"source"`
+	Status          string     `json:"status"`
+	APIVersion      string     `json:"api_version"`
+	Kind            string     `json:"kind"`
+	Namespace       string     `json:"namespace,omitempty"`
+	Name            string     `json:"name"`
+	Owner           string     `json:"owner,omitempty"`
+	ApplicationID   *uuid.UUID `json:"application_id,omitempty"`
+	ApplicationName string     `json:"application_name,omitempty"`
+	DeprecatedIn    string     `json:"deprecated_in"`
+	RemovedIn       string     `json:"removed_in"`
+	Replacement     string     `json:"replacement,omitempty"`
+
+	labels map[string]string
+}
+
+// DeprecatedAPIReport 升级前弃用API扫描报告
+type DeprecatedAPIReport struct {
+	ClusterID      uuid.UUID              `json:"cluster_id"`
+	CurrentVersion string                 `json:"current_version"`
+	TargetVersion  string                 `json:"target_version"`
+	ScannedAt      time.Time              `json:"scanned_at"`
+	Findings       []DeprecatedAPIFinding `json:"findings"`
+	Summary        map[string]int         `json:"summary"`
+	Errors         []string               `json:"errors,omitempty"` // 部分资源扫描失败时不影响其余结果
+}
+
+// DeprecatedAPIService 升级前的弃用与移除API扫描服务
+type DeprecatedAPIService struct {
+	clusterRepo       *repository.ClusterRepository
+	environmentRepo   *repository.EnvironmentRepository
+	applicationRepo   *repository.ApplicationRepository
+	clusterManager    *ClusterManager
+	encryptionService *EncryptionService
+}
+
+// NewDeprecatedAPIService 创建弃用API扫描服务
+func NewDeprecatedAPIService(
+	clusterRepo *repository.ClusterRepository,
+	environmentRepo *repository.EnvironmentRepository,
+	applicationRepo *repository.ApplicationRepository,
+	clusterManager *ClusterManager,
+	encryptionService *EncryptionService,
+) *DeprecatedAPIService {
+	return &DeprecatedAPIService{
+		clusterRepo:       clusterRepo,
+		environmentRepo:   environmentRepo,
+		applicationRepo:   applicationRepo,
+		clusterManager:    clusterManager,
+		encryptionService: encryptionService,
+	}
+}
+
+// deprecatedAPIScan 单次扫描的中间状态
+type deprecatedAPIScan struct {
+	rules      map[string]deprecatedAPI
+	statuses   map[string]string
+	namespaced map[string]bool // Kind 是否为命名空间级资源
+	seen       map[string]bool
+	report     *DeprecatedAPIReport
+}
+
+// Scan 扫描集群中在目标版本已弃用或移除的API使用情况
+func (s *DeprecatedAPIService) Scan(ctx context.Context, clusterID uuid.UUID, targetVersion string) (*DeprecatedAPIReport, error) {
+	target, err := utilversion.ParseGeneric(targetVersion)
+	if err != nil || target.Major() != 1 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTargetVersion, targetVersion)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deprecatedAPIScanTimeout)
+	defer cancel()
+
+	clientset, err := s.clientForCluster(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	scan := &deprecatedAPIScan{
+		rules:      make(map[string]deprecatedAPI),
+		statuses:   make(map[string]string),
+		namespaced: make(map[string]bool),
+		seen:       make(map[string]bool),
+		report: &DeprecatedAPIReport{
+			ClusterID:     clusterID,
+			TargetVersion: targetVersion,
+			ScannedAt:     time.Now(),
+			Findings:      []DeprecatedAPIFinding{},
+			Summary:       make(map[string]int),
+		},
+	}
+	scan.loadCatalog(target)
+
+	if info, err := clientset.Discovery().ServerVersion(); err == nil {
+		scan.report.CurrentVersion = info.GitVersion
+	}
+	if len(scan.rules) == 0 {
+		return scan.report, nil
+	}
+
+	if err := s.scanObjects(ctx, clientset, scan); err != nil {
+		return nil, err
+	}
+	s.scanHelmReleases(ctx, clientset, scan)
+	s.mapApplications(clusterID, scan.report)
+
+	findings := scan.report.Findings
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Status != b.Status {
+			return a.Status == DeprecatedAPIStatusRemoved
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Source < b.Source
+	})
+	for _, finding := range findings {
+		scan.report.Summary[finding.Status]++
+		scan.report.Summary["source_"+finding.Source]++
+	}
+	return scan.report, nil
+}
+
+// scanObjects 遍历弃用种类在集群中的首选版本资源
+// 首选版本本身已弃用时对象只能以该版本存储，记为 live；同时检查 last-applied 注解中的 apiVersion
+func (s *DeprecatedAPIService) scanObjects(ctx context.Context, clientset kubernetes.Interface, scan *deprecatedAPIScan) error {
+	kinds := make(map[string]bool)
+	for _, rule := range scan.rules {
+		kinds[rule.Kind] = true
+	}
+
+	// 部分聚合API不可用时发现接口仍返回其余分组
+	lists, err := clientset.Discovery().ServerPreferredResources()
+	if err != nil {
+		if len(lists) == 0 {
+			return fmt.Errorf("failed to discover server resources: %w", err)
+		}
+		scan.report.Errors = append(scan.report.Errors, err.Error())
+	}
+
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range list.APIResources {
+			if strings.Contains(resource.Name, "/") || !kinds[resource.Kind] || !containsVerb(resource.Verbs, "list") {
+				continue
+			}
+			scan.namespaced[resource.Kind] = resource.Namespaced
+
+			items, err := listObjectMetadata(ctx, clientset, gv, resource.Name)
+			if err != nil {
+				scan.report.Errors = append(scan.report.Errors, fmt.Sprintf("list %s %s: %v", list.GroupVersion, resource.Name, err))
+				continue
+			}
+
+			_, live := scan.rules[deprecatedAPIKey(list.GroupVersion, resource.Kind)]
+			for i := range items {
+				meta := &items[i].ObjectMeta
+				if live {
+					scan.add(DeprecatedAPISourceLive, list.GroupVersion, resource.Kind, meta.Namespace, meta.Name, objectOwner(meta), meta.Labels)
+				}
+				applied := meta.Annotations[lastAppliedAnnotation]
+				if applied == "" {
+					continue
+				}
+				var head struct {
+					APIVersion string `json:"apiVersion"`
+					Kind       string `json:"kind"`
+				}
+				if json.Unmarshal([]byte(applied), &head) != nil {
+					continue
+				}
+				scan.add(DeprecatedAPISourceLastApplied, head.APIVersion, head.Kind, meta.Namespace, meta.Name, objectOwner(meta), meta.Labels)
+			}
+		}
+	}
+	return nil
+}
+
+// scanHelmReleases 检查以 Secret 存储的已部署 Helm 发布清单
+func (s *DeprecatedAPIService) scanHelmReleases(ctx context.Context, clientset kubernetes.Interface, scan *deprecatedAPIScan) {
+	secrets, err := clientset.CoreV1().Secrets("").List(ctx, metav1.ListOptions{LabelSelector: helmReleaseSelector})
+	if err != nil {
+		scan.report.Errors = append(scan.report.Errors, fmt.Sprintf("list helm releases: %v", err))
+		return
+	}
+
+	for _, secret := range secrets.Items {
+		release, err := decodeHelmRelease(secret.Data["release"])
+		if err != nil {
+			scan.report.Errors = append(scan.report.Errors, fmt.Sprintf("decode helm release %s/%s: %v", secret.Namespace, secret.Name, err))
+			continue
+		}
+		owner := fmt.Sprintf("helm:%s (revision %d)", release.Name, release.Version)
+
+		for _, doc := range yamlDocumentSeparator.Split(release.Manifest, -1) {
+			var object struct {
+				APIVersion string `json:"apiVersion"`
+				Kind       string `json:"kind"`
+				Metadata   struct {
+					Name      string            `json:"name"`
+					Namespace string            `json:"namespace"`
+					Labels    map[string]string `json:"labels"`
+				} `json:"metadata"`
+			}
+			if err := yaml.Unmarshal([]byte(doc), &object); err != nil || object.Kind == "" {
+				continue
+			}
+			namespace := object.Metadata.Namespace
+			if namespace == "" && scan.isNamespaced(object.Kind) {
+				namespace = release.Namespace
+			}
+			scan.add(DeprecatedAPISourceHelm, object.APIVersion, object.Kind, namespace, object.Metadata.Name, owner, object.Metadata.Labels)
+		}
+	}
+}
+
+// mapApplications 根据命名空间对应的环境与应用标签关联应用
+func (s *DeprecatedAPIService) mapApplications(clusterID uuid.UUID, report *DeprecatedAPIReport) {
+	environments := make(map[string]string)
+	for i := range report.Findings {
+		finding := &report.Findings[i]
+		if finding.Namespace == "" {
+			continue
+		}
+
+		envID, ok := environments[finding.Namespace]
+		if !ok {
+			if env, err := s.environmentRepo.GetByNamespace(clusterID.String(), finding.Namespace); err == nil {
+				envID = env.ID.String()
+			}
+			environments[finding.Namespace] = envID
+		}
+		if envID == "" {
+			continue
+		}
+
+		name := AppNameFromLabels(finding.labels)
+		if name == "" {
+			name = finding.Name
+		}
+		app, err := s.applicationRepo.GetByName(envID, name)
+		if err != nil {
+			continue
+		}
+		finding.ApplicationID = &app.ID
+		finding.ApplicationName = app.Name
+	}
+}
+
+// clientForCluster 获取集群客户端
+func (s *DeprecatedAPIService) clientForCluster(ctx context.Context, clusterID uuid.UUID) (*kubernetes.Clientset, error) {
+	cluster, err := s.clusterRepo.GetByID(clusterID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClusterNotFound
+		}
+		return nil, err
+	}
+
+	kubeconfig, err := s.encryptionService.Decrypt(cluster.KubeconfigEncrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt kubeconfig: %w", err)
+	}
+
+	return s.clusterManager.GetClientForCluster(ctx, cluster.ID, kubeconfig)
+}
+
+// add 记录命中规则的对象，同一来源的同一对象只记录一次
+func (scan *deprecatedAPIScan) add(source, apiVersion, kind, namespace, name, owner string, labels map[string]string) {
+	key := deprecatedAPIKey(apiVersion, kind)
+	rule, ok := scan.rules[key]
+	if !ok {
+		return
+	}
+	seenKey := strings.Join([]string{source, key, namespace, name}, "|")
+	if scan.seen[seenKey] {
+		return
+	}
+	scan.seen[seenKey] = true
+
+	scan.report.Findings = append(scan.report.Findings, DeprecatedAPIFinding{
+		Source:       source,
+		Status:       scan.statuses[key],
+		APIVersion:   apiVersion,
+		Kind:         kind,
+		Namespace:    namespace,
+		Name:         name,
+		Owner:        owner,
+		DeprecatedIn: rule.DeprecatedIn,
+		RemovedIn:    rule.RemovedIn,
+		Replacement:  rule.Replacement,
+		labels:       labels,
+	})
+}
+
+// loadCatalog 载入在目标版本已弃用或移除的规则
+func (scan *deprecatedAPIScan) loadCatalog(target *utilversion.Version) {
+	for _, rule := range deprecatedAPICatalog {
+		status := deprecationStatus(rule, target)
+		if status == "" {
+			continue
+		}
+		key := deprecatedAPIKey(rule.apiVersion(), rule.Kind)
+		scan.rules[key] = rule
+		scan.statuses[key] = status
+	}
+}
+
+// isNamespaced 判断种类是否为命名空间级资源，集群未提供该种类时按命名空间级处理
+func (scan *deprecatedAPIScan) isNamespaced(kind string) bool {
+	namespaced, ok := scan.namespaced[kind]
+	return !ok || namespaced
+}
+
+// helmRelease Helm 发布记录中扫描需要的字段
+type helmRelease struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Version   int    `json:"version"`
+	Manifest  string `json:"manifest"`
+}
+
+// decodeHelmRelease 解码 Helm 存储在 Secret 中的发布记录（base64 + gzip + JSON）
+func decodeHelmRelease(data []byte) (*helmRelease, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty release data")
+	}
+	decoded, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(decoded, []byte{0x1f, 0x8b, 0x08}) {
+		reader, err := gzip.NewReader(bytes.NewReader(decoded))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		if decoded, err = io.ReadAll(reader); err != nil {
+			return nil, err
+		}
+	}
+
+	var release helmRelease
+	if err := json.Unmarshal(decoded, &release); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+// listObjectMetadata 分页列出资源的元数据
+func listObjectMetadata(ctx context.Context, clientset kubernetes.Interface, gv schema.GroupVersion, resource string) ([]metav1.PartialObjectMetadata, error) {
+	path := "/apis/" + gv.Group + "/" + gv.Version + "/" + resource
+	if gv.Group == "" {
+		path = "/api/" + gv.Version + "/" + resource
+	}
+
+	var items []metav1.PartialObjectMetadata
+	continueToken := ""
+	for {
+		request := clientset.Discovery().RESTClient().Get().
+			AbsPath(path).
+			SetHeader("Accept", partialMetadataListAccept).
+			Param("limit", deprecatedAPIListPageSize)
+		if continueToken != "" {
+			request = request.Param("continue", continueToken)
+		}
+		raw, err := request.Do(ctx).Raw()
+		if err != nil {
+			return nil, err
+		}
+
+		var page metav1.PartialObjectMetadataList
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return nil, err
+		}
+		items = append(items, page.Items...)
+		if page.Continue == "" {
+			return items, nil
+		}
+		continueToken = page.Continue
+	}
+}
+
+// deprecationStatus 返回规则在目标版本中的状态，尚未弃用时返回空
+func deprecationStatus(rule deprecatedAPI, target *utilversion.Version) string {
+	if removed, err := utilversion.ParseGeneric(rule.RemovedIn); err == nil && target.AtLeast(removed) {
+		return DeprecatedAPIStatusRemoved
+	}
+	if deprecated, err := utilversion.ParseGeneric(rule.DeprecatedIn); err == nil && target.AtLeast(deprecated) {
+		return DeprecatedAPIStatusDeprecated
+	}
+	return ""
+}
+
+// objectOwner 返回对象的归属：Helm 发布或控制器
+func objectOwner(meta *metav1.ObjectMeta) string {
+	if release := meta.Annotations[helmReleaseNameAnnotation]; release != "" {
+		return "helm:" + release
+	}
+	if ref := metav1.GetControllerOfNoCopy(meta); ref != nil {
+		return ref.Kind + "/" + ref.Name
+	}
+	if len(meta.OwnerReferences) > 0 {
+		return meta.OwnerReferences[0].Kind + "/" + meta.OwnerReferences[0].Name
+	}
+	return ""
+}
+
+func deprecatedAPIKey(apiVersion, kind string) string {
+	return apiVersion + "/" + kind
+}
+
+func containsVerb(verbs metav1.Verbs, verb string) bool {
+	for _, v := range verbs {
+		if v == verb {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"testing"
+
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+)
+
+func newTestDeprecatedAPIScan(t *testing.T, targetVersion string) *deprecatedAPIScan {
+	t.Helper()
+	target, err := utilversion.ParseGeneric(targetVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scan := &deprecatedAPIScan{
+		rules:      make(map[string]deprecatedAPI),
+		statuses:   make(map[string]string),
+		namespaced: make(map[string]bool),
+		seen:       make(map[string]bool),
+		report:     &DeprecatedAPIReport{Findings: []DeprecatedAPIFinding{}, Summary: make(map[string]int)},
+	}
+	scan.loadCatalog(target)
+	return scan
+}
+
+func TestDeprecatedAPICatalogLookup(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      string
+		apiVersion  string
+		kind        string
+		wantStatus  string
+		replacement string
+	}{
+		{name: "removed in target", target: "v1.25.0", apiVersion: "batch/v1beta1", kind: "CronJob", wantStatus: DeprecatedAPIStatusRemoved, replacement: "batch/v1"},
+		{name: "removed before target", target: "v1.28.3", apiVersion: "extensions/v1beta1", kind: "Ingress", wantStatus: DeprecatedAPIStatusRemoved, replacement: "networking.k8s.io/v1"},
+		{name: "deprecated in target", target: "v1.21", apiVersion: "batch/v1beta1", kind: "CronJob", wantStatus: DeprecatedAPIStatusDeprecated, replacement: "batch/v1"},
+		{name: "deprecated between releases", target: "1.24.17", apiVersion: "policy/v1beta1", kind: "PodDisruptionBudget", wantStatus: DeprecatedAPIStatusDeprecated, replacement: "policy/v1"},
+		{name: "not yet deprecated", target: "v1.20.0", apiVersion: "batch/v1beta1", kind: "CronJob"},
+		{name: "removed without replacement", target: "v1.25.0", apiVersion: "policy/v1beta1", kind: "PodSecurityPolicy", wantStatus: DeprecatedAPIStatusRemoved},
+		{name: "multi-part group", target: "v1.29.0", apiVersion: "flowcontrol.apiserver.k8s.io/v1beta2", kind: "FlowSchema", wantStatus: DeprecatedAPIStatusRemoved, replacement: "flowcontrol.apiserver.k8s.io/v1"},
+		{name: "same version of another kind", target: "v1.25.0", apiVersion: "batch/v1beta1", kind: "Job"},
+		{name: "stable API", target: "v1.32.0", apiVersion: "apps/v1", kind: "Deployment"},
+		{name: "kind is case sensitive", target: "v1.25.0", apiVersion: "batch/v1beta1", kind: "cronjob"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scan := newTestDeprecatedAPIScan(t, tt.target)
+			key := deprecatedAPIKey(tt.apiVersion, tt.kind)
+			rule, ok := scan.rules[key]
+			if tt.wantStatus == "" {
+				if ok {
+					t.Fatalf("%s %s matched rule %+v, want no match", tt.apiVersion, tt.kind, rule)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("%s %s did not match any rule", tt.apiVersion, tt.kind)
+			}
+			if status := scan.statuses[key]; status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", status, tt.wantStatus)
+			}
+			if rule.Replacement != tt.replacement {
+				t.Errorf("replacement = %q, want %q", rule.Replacement, tt.replacement)
+			}
+		})
+	}
+}
+
+func TestDeprecatedAPICatalogEntries(t *testing.T) {
+	seen := make(map[string]bool, len(deprecatedAPICatalog))
+	for _, rule := range deprecatedAPICatalog {
+		key := deprecatedAPIKey(rule.apiVersion(), rule.Kind)
+		if seen[key] {
+			t.Errorf("duplicate catalog entry %s", key)
+		}
+		seen[key] = true
+
+		deprecated, err := utilversion.ParseGeneric(rule.DeprecatedIn)
+		if err != nil {
+			t.Errorf("%s: invalid deprecated version %q", key, rule.DeprecatedIn)
+			continue
+		}
+		removed, err := utilversion.ParseGeneric(rule.RemovedIn)
+		if err != nil {
+			t.Errorf("%s: invalid removed version %q", key, rule.RemovedIn)
+			continue
+		}
+		if removed.LessThan(deprecated) {
+			t.Errorf("%s: removed in %s before deprecated in %s", key, rule.RemovedIn, rule.DeprecatedIn)
+		}
+		if rule.Replacement == rule.apiVersion() {
+			t.Errorf("%s: replacement is the deprecated version", key)
+		}
+	}
+}
+
+func TestDeprecatedAPIScanAdd(t *testing.T) {
+	scan := newTestDeprecatedAPIScan(t, "v1.25.0")
+	scan.add(DeprecatedAPISourceLive, "batch/v1beta1", "CronJob", "default", "backup", "", nil)
+	scan.add(DeprecatedAPISourceLive, "batch/v1beta1", "CronJob", "default", "backup", "", nil)
+	scan.add(DeprecatedAPISourceLastApplied, "batch/v1beta1", "CronJob", "default", "backup", "", nil)
+	scan.add(DeprecatedAPISourceLive, "batch/v1", "CronJob", "default", "report", "", nil)
+
+	findings := scan.report.Findings
+	if len(findings) != 2 {
+		t.Fatalf("findings = %d, want 2 (one per source)", len(findings))
+	}
+	for _, finding := range findings {
+		if finding.Status != DeprecatedAPIStatusRemoved || finding.RemovedIn != "v1.25" || finding.Replacement != "batch/v1" {
+			t.Errorf("finding = %+v, want removed in v1.25 with replacement batch/v1", finding)
+		}
+	}
+}
+
+func TestDecodeHelmRelease(t *testing.T) {
+	payload := []byte(`{"name":"web","namespace":"apps","version":3,"manifest":"apiVersion: batch/v1beta1\nkind: CronJob\n"}`)
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write(payload)
+	writer.Close()
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{name: "gzip compressed", data: []byte(base64.StdEncoding.EncodeToString(compressed.Bytes()))},
+		{name: "plain json", data: []byte(base64.StdEncoding.EncodeToString(payload))},
+		{name: "empty", data: nil, wantErr: true},
+		{name: "not base64", data: []byte("%%%"), wantErr: true},
+		{name: "not json", data: []byte(base64.StdEncoding.EncodeToString([]byte("manifest"))), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release, err := decodeHelmRelease(tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if release.Name != "web" || release.Namespace != "apps" || release.Version != 3 || release.Manifest == "" {
+				t.Errorf("release = %+v", release)
+			}
+		})
+	}
+}
@@ -81,7 +81,7 @@ func (s *EventService) SyncEventsFromKubernetes(clusterID string) error {
 	}
 
 	ctx := context.Background()
-	clientset, err := s.clusterManager.GetClient(ctx, kubeconfig)
+	clientset, err := s.clusterManager.GetClientForCluster(ctx, cluster.ID, kubeconfig)
 	if err != nil {
 		return fmt.Errorf("failed to get client: %w", err)
 	}
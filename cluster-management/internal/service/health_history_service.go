@@ -0,0 +1,612 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"github.com/taichu-system/cluster-management/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidTimeWindow = errors.New("invalid time window")
+	ErrInvalidSLOTarget  = errors.New("slo target must be between 0 and 100 (exclusive)")
+)
+
+const (
+	defaultHealthRawRetention   = 30 * 24 * time.Hour
+	defaultHealthDailyRetention = 400 * 24 * time.Hour
+	defaultSLOTarget            = 99.9
+	// healthCompactBatchSize 每批降采样的区间数量
+	healthCompactBatchSize = 1000
+)
+
+// SLO 状态
+const (
+	SLOStatusOK        = "ok"
+	SLOStatusAtRisk    = "at_risk"
+	SLOStatusExhausted = "exhausted"
+)
+
+// sloBurnWindows 多窗口燃烧率告警阈值，取自 SRE Workbook 中 99.9% 目标的快速/慢速燃烧建议
+var sloBurnWindows = []struct {
+	Name      string
+	Window    time.Duration
+	Threshold float64
+}{
+	{"1h", time.Hour, 14.4},
+	{"6h", 6 * time.Hour, 6},
+	{"24h", 24 * time.Hour, 3},
+}
+
+// HealthHistoryService 集群健康历史、可用性与 SLO 统计
+// 原始状态区间保留 rawRetention，过期后按天降采样，降采样数据保留 dailyRetention
+type HealthHistoryService struct {
+	clusterRepo    *repository.ClusterRepository
+	historyRepo    *repository.ClusterHealthHistoryRepository
+	rawRetention   time.Duration
+	dailyRetention time.Duration
+	sloTarget      float64
+}
+
+// NewHealthHistoryService 创建健康历史服务，参数为零值时使用默认值
+func NewHealthHistoryService(
+	clusterRepo *repository.ClusterRepository,
+	historyRepo *repository.ClusterHealthHistoryRepository,
+	rawRetention time.Duration,
+	dailyRetention time.Duration,
+	sloTarget float64,
+) *HealthHistoryService {
+	if rawRetention <= 0 {
+		rawRetention = defaultHealthRawRetention
+	}
+	if dailyRetention < rawRetention {
+		dailyRetention = defaultHealthDailyRetention
+	}
+	if sloTarget <= 0 || sloTarget >= 100 {
+		sloTarget = defaultSLOTarget
+	}
+	return &HealthHistoryService{
+		clusterRepo:    clusterRepo,
+		historyRepo:    historyRepo,
+		rawRetention:   rawRetention,
+		dailyRetention: dailyRetention,
+		sloTarget:      sloTarget,
+	}
+}
+
+// UptimeReport 时间窗口内的可用性统计
+// degraded 仅附加组件异常，API 仍可用，计入可用时间
+type UptimeReport struct {
+	ClusterID          uuid.UUID        `json:"cluster_id"`
+	Start              time.Time        `json:"start"`
+	End                time.Time        `json:"end"`
+	ObservedSeconds    int64            `json:"observed_seconds"`
+	NoDataSeconds      int64            `json:"no_data_seconds"` // 集群未纳管或检查未运行的时间，不计入可用率
+	AvailableSeconds   int64            `json:"available_seconds"`
+	UnavailableSeconds int64            `json:"unavailable_seconds"`
+	StatusSeconds      map[string]int64 `json:"status_seconds"`
+	UptimePercent      float64          `json:"uptime_percent"`
+	IncidentCount      int              `json:"incident_count"`
+	// Resolution 为 daily 或 mixed 时，早于原始保留期的部分按天粒度按比例折算
+	Resolution string `json:"resolution"`
+}
+
+// IncidentEvent 故障时间线中的一次状态变化
+type IncidentEvent struct {
+	Status          string     `json:"status"`
+	Message         string     `json:"message,omitempty"`
+	StartedAt       time.Time  `json:"started_at"`
+	EndedAt         *time.Time `json:"ended_at"`
+	DurationSeconds int64      `json:"duration_seconds"`
+}
+
+// Incident 一次连续的不可用过程
+type Incident struct {
+	StartedAt       time.Time       `json:"started_at"`
+	EndedAt         *time.Time      `json:"ended_at"`
+	DurationSeconds int64           `json:"duration_seconds"`
+	Ongoing         bool            `json:"ongoing"`
+	WorstStatus     string          `json:"worst_status"`
+	Timeline        []IncidentEvent `json:"timeline"`
+}
+
+// IncidentReport 时间窗口内的故障列表
+type IncidentReport struct {
+	ClusterID          uuid.UUID  `json:"cluster_id"`
+	Start              time.Time  `json:"start"`
+	End                time.Time  `json:"end"`
+	Incidents          []Incident `json:"incidents"`
+	TotalCount         int        `json:"total_count"`
+	TotalDowntime      int64      `json:"total_downtime_seconds"`
+	MeanTimeToRecovery int64      `json:"mttr_seconds"`
+	// RetentionStart 之前只保留按天汇总的故障次数，没有时间线
+	RetentionStart time.Time `json:"retention_start"`
+}
+
+// SLOBurnRate 单个窗口的燃烧率
+type SLOBurnRate struct {
+	Window    string  `json:"window"`
+	BurnRate  float64 `json:"burn_rate"`
+	Threshold float64 `json:"threshold"`
+	Alerting  bool    `json:"alerting"`
+}
+
+// SLOReport SLO 达成与错误预算消耗
+type SLOReport struct {
+	ClusterID              uuid.UUID     `json:"cluster_id"`
+	Target                 float64       `json:"target"`
+	Window                 string        `json:"window"`
+	Start                  time.Time     `json:"start"`
+	End                    time.Time     `json:"end"`
+	UptimePercent          float64       `json:"uptime_percent"`
+	ErrorBudgetSeconds     int64         `json:"error_budget_seconds"`
+	BudgetConsumedSeconds  int64         `json:"budget_consumed_seconds"`
+	BudgetRemainingPercent float64       `json:"budget_remaining_percent"`
+	BurnRate               float64       `json:"burn_rate"` // 整个窗口的平均燃烧率，1 表示恰好在窗口结束时耗尽预算
+	BurnRates              []SLOBurnRate `json:"burn_rates"`
+	Status                 string        `json:"status"`
+}
+
+// Record 记录一次健康检查结果
+func (s *HealthHistoryService) Record(clusterID uuid.UUID, status, message string, checkedAt time.Time) error {
+	return s.historyRepo.Record(clusterID, status, message, checkedAt)
+}
+
+// GetUptime 统计任意时间窗口内的可用率
+func (s *HealthHistoryService) GetUptime(clusterID uuid.UUID, start, end time.Time) (*UptimeReport, error) {
+	if err := s.ensureCluster(clusterID); err != nil {
+		return nil, err
+	}
+	if !end.After(start) {
+		return nil, ErrInvalidTimeWindow
+	}
+	return s.computeUptime(clusterID, start, end)
+}
+
+// ListIncidents 获取时间窗口内的故障及其状态时间线，includeDegraded 为 true 时 degraded 也视为故障
+func (s *HealthHistoryService) ListIncidents(clusterID uuid.UUID, start, end time.Time, includeDegraded bool) (*IncidentReport, error) {
+	if err := s.ensureCluster(clusterID); err != nil {
+		return nil, err
+	}
+	if !end.After(start) {
+		return nil, ErrInvalidTimeWindow
+	}
+
+	periods, err := s.historyRepo.ListPeriods(clusterID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list health periods: %w", err)
+	}
+
+	isIncident := func(status string) bool {
+		return !isAvailableStatus(status) || (includeDegraded && status == ClusterHealthDegraded)
+	}
+
+	now := time.Now()
+	report := &IncidentReport{
+		ClusterID:      clusterID,
+		Start:          start,
+		End:            end,
+		Incidents:      []Incident{},
+		RetentionStart: s.rawCutoff(now),
+	}
+
+	var current *Incident
+	flush := func() {
+		if current == nil {
+			return
+		}
+		last := current.Timeline[len(current.Timeline)-1]
+		current.EndedAt = last.EndedAt
+		current.Ongoing = last.EndedAt == nil
+		endAt := now
+		if last.EndedAt != nil {
+			endAt = *last.EndedAt
+		}
+		current.DurationSeconds = int64(endAt.Sub(current.StartedAt).Seconds())
+		report.Incidents = append(report.Incidents, *current)
+		current = nil
+	}
+
+	for _, period := range periods {
+		if !isIncident(period.Status) {
+			flush()
+			continue
+		}
+		event := IncidentEvent{
+			Status:          period.Status,
+			Message:         period.Message,
+			StartedAt:       period.StartedAt,
+			EndedAt:         period.EndedAt,
+			DurationSeconds: periodDurationSeconds(period, now),
+		}
+		// 区间首尾相接才合并为同一故障，中间缺少数据时视为两次故障
+		if current != nil {
+			prev := current.Timeline[len(current.Timeline)-1]
+			if prev.EndedAt == nil || !prev.EndedAt.Equal(period.StartedAt) {
+				flush()
+			}
+		}
+		if current == nil {
+			current = &Incident{StartedAt: period.StartedAt, WorstStatus: period.Status}
+		}
+		current.Timeline = append(current.Timeline, event)
+		if healthSeverity(period.Status) > healthSeverity(current.WorstStatus) {
+			current.WorstStatus = period.Status
+		}
+	}
+	flush()
+
+	var recovered, recoveredSeconds int64
+	for _, incident := range report.Incidents {
+		report.TotalDowntime += incident.DurationSeconds
+		if !incident.Ongoing {
+			recovered++
+			recoveredSeconds += incident.DurationSeconds
+		}
+	}
+	report.TotalCount = len(report.Incidents)
+	if recovered > 0 {
+		report.MeanTimeToRecovery = recoveredSeconds / recovered
+	}
+
+	// 最新的故障排在前面
+	sort.SliceStable(report.Incidents, func(i, j int) bool {
+		return report.Incidents[i].StartedAt.After(report.Incidents[j].StartedAt)
+	})
+	return report, nil
+}
+
+// GetSLO 计算截至当前的滚动窗口 SLO，target 为 0 时使用配置的默认目标
+func (s *HealthHistoryService) GetSLO(clusterID uuid.UUID, target float64, window time.Duration) (*SLOReport, error) {
+	if err := s.ensureCluster(clusterID); err != nil {
+		return nil, err
+	}
+	if target == 0 {
+		target = s.sloTarget
+	}
+	if target <= 0 || target >= 100 {
+		return nil, ErrInvalidSLOTarget
+	}
+	if window <= 0 {
+		return nil, ErrInvalidTimeWindow
+	}
+
+	end := time.Now()
+	start := end.Add(-window)
+	uptime, err := s.computeUptime(clusterID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := 1 - target/100
+	report := &SLOReport{
+		ClusterID:             clusterID,
+		Target:                target,
+		Window:                formatWindow(window),
+		Start:                 start,
+		End:                   end,
+		UptimePercent:         uptime.UptimePercent,
+		ErrorBudgetSeconds:    int64(float64(uptime.ObservedSeconds) * allowed),
+		BudgetConsumedSeconds: uptime.UnavailableSeconds,
+		BurnRate:              burnRate(uptime, allowed),
+		BurnRates:             []SLOBurnRate{},
+		Status:                SLOStatusOK,
+	}
+	if report.ErrorBudgetSeconds > 0 {
+		report.BudgetRemainingPercent = roundPercent(100 * (1 - float64(report.BudgetConsumedSeconds)/float64(report.ErrorBudgetSeconds)))
+	} else if report.BudgetConsumedSeconds == 0 {
+		report.BudgetRemainingPercent = 100
+	}
+
+	for _, bw := range sloBurnWindows {
+		if bw.Window > window {
+			continue
+		}
+		short, err := s.computeUptime(clusterID, end.Add(-bw.Window), end)
+		if err != nil {
+			return nil, err
+		}
+		rate := burnRate(short, allowed)
+		alerting := rate >= bw.Threshold
+		report.BurnRates = append(report.BurnRates, SLOBurnRate{
+			Window:    bw.Name,
+			BurnRate:  rate,
+			Threshold: bw.Threshold,
+			Alerting:  alerting,
+		})
+		if alerting {
+			report.Status = SLOStatusAtRisk
+		}
+	}
+	if report.BudgetConsumedSeconds > report.ErrorBudgetSeconds {
+		report.Status = SLOStatusExhausted
+	}
+	return report, nil
+}
+
+// Compact 将超过原始保留期的区间按天降采样，并清理超过降采样保留期的数据
+func (s *HealthHistoryService) Compact() (folded int, deleted int64, err error) {
+	now := time.Now()
+	cutoff := s.rawCutoff(now)
+
+	for {
+		periods, err := s.historyRepo.ListClosedBefore(cutoff, healthCompactBatchSize)
+		if err != nil {
+			return folded, deleted, fmt.Errorf("failed to list expired health periods: %w", err)
+		}
+		if len(periods) == 0 {
+			break
+		}
+		ids := make([]uuid.UUID, 0, len(periods))
+		for _, period := range periods {
+			ids = append(ids, period.ID)
+		}
+		if err := s.historyRepo.ApplyDaily(foldDailyHealth(periods), ids); err != nil {
+			return folded, deleted, fmt.Errorf("failed to downsample health periods: %w", err)
+		}
+		folded += len(periods)
+		if len(periods) < healthCompactBatchSize {
+			break
+		}
+	}
+
+	deleted, err = s.historyRepo.DeleteDailyBefore(utcDay(now.Add(-s.dailyRetention)))
+	if err != nil {
+		return folded, deleted, fmt.Errorf("failed to delete expired daily health data: %w", err)
+	}
+	return folded, deleted, nil
+}
+
+// computeUptime 合并原始区间与降采样数据计算各状态时长
+func (s *HealthHistoryService) computeUptime(clusterID uuid.UUID, start, end time.Time) (*UptimeReport, error) {
+	now := time.Now()
+	report := &UptimeReport{
+		ClusterID: clusterID,
+		Start:     start,
+		End:       end,
+		StatusSeconds: map[string]int64{
+			ClusterHealthHealthy:      0,
+			ClusterHealthDegraded:     0,
+			ClusterHealthUnhealthy:    0,
+			ClusterHealthDisconnected: 0,
+		},
+		Resolution: "raw",
+	}
+
+	periods, err := s.historyRepo.ListPeriods(clusterID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list health periods: %w", err)
+	}
+	var prev *model.ClusterHealthPeriod
+	for _, period := range periods {
+		periodEnd := now
+		if period.EndedAt != nil {
+			periodEnd = *period.EndedAt
+		}
+		seconds := overlapSeconds(period.StartedAt, periodEnd, start, end)
+		if _, ok := report.StatusSeconds[period.Status]; ok && seconds > 0 {
+			report.StatusSeconds[period.Status] += seconds
+			// 与前一个不可用区间首尾相接时属于同一次故障
+			continuing := prev != nil && !isAvailableStatus(prev.Status) && prev.EndedAt != nil && prev.EndedAt.Equal(period.StartedAt)
+			if !isAvailableStatus(period.Status) && !period.StartedAt.Before(start) && !continuing {
+				report.IncidentCount++
+			}
+		}
+		prev = period
+	}
+
+	if start.Before(s.rawCutoff(now)) {
+		rows, err := s.historyRepo.ListDaily(clusterID, utcDay(start), utcDay(end))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list daily health data: %w", err)
+		}
+		for _, row := range rows {
+			dayStart := utcDay(row.Day)
+			fraction := float64(overlapSeconds(dayStart, dayStart.Add(24*time.Hour), start, end)) / (24 * 3600)
+			if fraction <= 0 {
+				continue
+			}
+			report.StatusSeconds[ClusterHealthHealthy] += int64(float64(row.HealthySeconds) * fraction)
+			report.StatusSeconds[ClusterHealthDegraded] += int64(float64(row.DegradedSeconds) * fraction)
+			report.StatusSeconds[ClusterHealthUnhealthy] += int64(float64(row.UnhealthySeconds) * fraction)
+			report.StatusSeconds[ClusterHealthDisconnected] += int64(float64(row.DisconnectedSeconds) * fraction)
+			if !row.Day.Before(utcDay(start)) {
+				report.IncidentCount += row.IncidentCount
+			}
+		}
+		if len(rows) > 0 {
+			report.Resolution = "mixed"
+			if len(periods) == 0 {
+				report.Resolution = "daily"
+			}
+		}
+	}
+
+	report.AvailableSeconds = report.StatusSeconds[ClusterHealthHealthy] + report.StatusSeconds[ClusterHealthDegraded]
+	report.UnavailableSeconds = report.StatusSeconds[ClusterHealthUnhealthy] + report.StatusSeconds[ClusterHealthDisconnected]
+	report.ObservedSeconds = report.AvailableSeconds + report.UnavailableSeconds
+
+	windowEnd := end
+	if windowEnd.After(now) {
+		windowEnd = now
+	}
+	if total := int64(windowEnd.Sub(start).Seconds()); total > report.ObservedSeconds {
+		report.NoDataSeconds = total - report.ObservedSeconds
+	}
+	if report.ObservedSeconds > 0 {
+		report.UptimePercent = roundPercent(100 * float64(report.AvailableSeconds) / float64(report.ObservedSeconds))
+	}
+	return report, nil
+}
+
+func (s *HealthHistoryService) ensureCluster(clusterID uuid.UUID) error {
+	if _, err := s.clusterRepo.GetByID(clusterID.String()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrClusterNotFound
+		}
+		return fmt.Errorf("failed to get cluster: %w", err)
+	}
+	return nil
+}
+
+// rawCutoff 原始区间保留期的起点，对齐到 UTC 零点以保证降采样按整天进行
+func (s *HealthHistoryService) rawCutoff(now time.Time) time.Time {
+	return utcDay(now.Add(-s.rawRetention))
+}
+
+// HealthHistoryMessage 生成状态区间的说明，断连时为错误信息，否则列出异常组件
+func HealthHistoryMessage(result *HealthCheckResult) string {
+	if result.Error != "" {
+		return result.Error
+	}
+	var unhealthy []string
+	for _, component := range result.Components {
+		if component.Status == ComponentStatusUnhealthy {
+			unhealthy = append(unhealthy, component.Name)
+		}
+	}
+	if len(unhealthy) == 0 {
+		return ""
+	}
+	return "unhealthy components: " + strings.Join(unhealthy, ", ")
+}
+
+// foldDailyHealth 将区间按 UTC 日切分并累加各状态时长
+func foldDailyHealth(periods []*model.ClusterHealthPeriod) []*model.ClusterHealthDaily {
+	type key struct {
+		clusterID uuid.UUID
+		day       time.Time
+	}
+	buckets := make(map[key]*model.ClusterHealthDaily)
+	var order []key
+	bucket := func(clusterID uuid.UUID, day time.Time) *model.ClusterHealthDaily {
+		k := key{clusterID, day}
+		row, ok := buckets[k]
+		if !ok {
+			row = &model.ClusterHealthDaily{ClusterID: clusterID, Day: day}
+			buckets[k] = row
+			order = append(order, k)
+		}
+		return row
+	}
+
+	for _, period := range periods {
+		if period.EndedAt == nil {
+			continue
+		}
+		if !isAvailableStatus(period.Status) {
+			bucket(period.ClusterID, utcDay(period.StartedAt)).IncidentCount++
+		}
+		for day := utcDay(period.StartedAt); day.Before(*period.EndedAt); day = day.Add(24 * time.Hour) {
+			seconds := overlapSeconds(period.StartedAt, *period.EndedAt, day, day.Add(24*time.Hour))
+			if seconds <= 0 {
+				continue
+			}
+			row := bucket(period.ClusterID, day)
+			switch period.Status {
+			case ClusterHealthHealthy:
+				row.HealthySeconds += seconds
+			case ClusterHealthDegraded:
+				row.DegradedSeconds += seconds
+			case ClusterHealthUnhealthy:
+				row.UnhealthySeconds += seconds
+			case ClusterHealthDisconnected:
+				row.DisconnectedSeconds += seconds
+			}
+		}
+	}
+
+	rows := make([]*model.ClusterHealthDaily, 0, len(order))
+	for _, k := range order {
+		rows = append(rows, buckets[k])
+	}
+	return rows
+}
+
+// isAvailableStatus healthy 与 degraded 视为可用
+func isAvailableStatus(status string) bool {
+	return status == ClusterHealthHealthy || status == ClusterHealthDegraded
+}
+
+// healthSeverity 状态严重程度，用于确定故障中最严重的状态
+func healthSeverity(status string) int {
+	switch status {
+	case ClusterHealthDisconnected:
+		return 3
+	case ClusterHealthUnhealthy:
+		return 2
+	case ClusterHealthDegraded:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func periodDurationSeconds(period *model.ClusterHealthPeriod, now time.Time) int64 {
+	if period.EndedAt != nil {
+		return int64(period.EndedAt.Sub(period.StartedAt).Seconds())
+	}
+	return int64(now.Sub(period.StartedAt).Seconds())
+}
+
+// overlapSeconds 计算 [aStart, aEnd) 与 [bStart, bEnd) 的重叠秒数
+func overlapSeconds(aStart, aEnd, bStart, bEnd time.Time) int64 {
+	if aStart.Before(bStart) {
+		aStart = bStart
+	}
+	if aEnd.After(bEnd) {
+		aEnd = bEnd
+	}
+	if !aEnd.After(aStart) {
+		return 0
+	}
+	return int64(aEnd.Sub(aStart).Seconds())
+}
+
+func burnRate(uptime *UptimeReport, allowed float64) float64 {
+	if uptime.ObservedSeconds == 0 || allowed <= 0 {
+		return 0
+	}
+	rate := float64(uptime.UnavailableSeconds) / float64(uptime.ObservedSeconds) / allowed
+	return float64(int64(rate*100+0.5)) / 100
+}
+
+func roundPercent(value float64) float64 {
+	return float64(int64(value*1000+0.5)) / 1000
+}
+
+func utcDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// formatWindow 以天或小时表示窗口长度
+func formatWindow(window time.Duration) string {
+	if window%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", int(window/(24*time.Hour)))
+	}
+	return window.String()
+}
+
+// ParseWindow 解析 30d、12h、90m 形式的窗口长度
+func ParseWindow(value string) (time.Duration, error) {
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil || days <= 0 {
+			return 0, fmt.Errorf("%w: %s", ErrInvalidTimeWindow, value)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	window, err := time.ParseDuration(value)
+	if err != nil || window <= 0 {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidTimeWindow, value)
+	}
+	return window, nil
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"github.com/taichu-system/cluster-management/internal/repository"
+	"github.com/taichu-system/cluster-management/internal/testutil"
+)
+
+// healthPeriod 构造状态区间，end 为零值时表示当前状态
+func healthPeriod(clusterID uuid.UUID, status string, start, end time.Time) *model.ClusterHealthPeriod {
+	period := &model.ClusterHealthPeriod{ClusterID: clusterID, Status: status, StartedAt: start, LastCheckedAt: start}
+	if !end.IsZero() {
+		period.EndedAt = &end
+	}
+	return period
+}
+
+func TestOverlapSeconds(t *testing.T) {
+	base := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+	tests := []struct {
+		name                       string
+		aStart, aEnd, bStart, bEnd time.Time
+		want                       int64
+	}{
+		{name: "inside window", aStart: at(10), aEnd: at(20), bStart: at(0), bEnd: at(60), want: 600},
+		{name: "covers window", aStart: at(0), aEnd: at(120), bStart: at(30), bEnd: at(60), want: 1800},
+		{name: "starts before window", aStart: at(0), aEnd: at(40), bStart: at(30), bEnd: at(60), want: 600},
+		{name: "ends after window", aStart: at(50), aEnd: at(90), bStart: at(30), bEnd: at(60), want: 600},
+		{name: "touching is not overlapping", aStart: at(0), aEnd: at(30), bStart: at(30), bEnd: at(60)},
+		{name: "disjoint", aStart: at(70), aEnd: at(80), bStart: at(30), bEnd: at(60)},
+		{name: "empty interval", aStart: at(40), aEnd: at(40), bStart: at(30), bEnd: at(60)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := overlapSeconds(tt.aStart, tt.aEnd, tt.bStart, tt.bEnd); got != tt.want {
+				t.Errorf("overlapSeconds() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFoldDailyHealth(t *testing.T) {
+	clusterA, clusterB := uuid.New(), uuid.New()
+	day1 := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	day3 := day2.Add(24 * time.Hour)
+
+	type dailyRow struct {
+		cluster                                    uuid.UUID
+		day                                        time.Time
+		healthy, degraded, unhealthy, disconnected int64
+		incidents                                  int
+	}
+	tests := []struct {
+		name    string
+		periods []*model.ClusterHealthPeriod
+		want    []dailyRow
+	}{
+		{
+			name: "periods within one day",
+			periods: []*model.ClusterHealthPeriod{
+				healthPeriod(clusterA, ClusterHealthHealthy, day1, day1.Add(10*time.Hour)),
+				healthPeriod(clusterA, ClusterHealthDegraded, day1.Add(10*time.Hour), day1.Add(12*time.Hour)),
+				healthPeriod(clusterA, ClusterHealthUnhealthy, day1.Add(12*time.Hour), day1.Add(13*time.Hour)),
+				healthPeriod(clusterA, ClusterHealthDisconnected, day1.Add(13*time.Hour), day1.Add(13*time.Hour+30*time.Minute)),
+			},
+			want: []dailyRow{{cluster: clusterA, day: day1, healthy: 36000, degraded: 7200, unhealthy: 3600, disconnected: 1800, incidents: 2}},
+		},
+		{
+			name: "period split at utc midnight",
+			periods: []*model.ClusterHealthPeriod{
+				healthPeriod(clusterA, ClusterHealthUnhealthy, day1.Add(22*time.Hour), day3.Add(2*time.Hour)),
+			},
+			want: []dailyRow{
+				{cluster: clusterA, day: day1, unhealthy: 2 * 3600, incidents: 1},
+				{cluster: clusterA, day: day2, unhealthy: 24 * 3600},
+				{cluster: clusterA, day: day3, unhealthy: 2 * 3600},
+			},
+		},
+		{
+			name: "non utc start is bucketed by utc day",
+			periods: []*model.ClusterHealthPeriod{
+				healthPeriod(clusterA, ClusterHealthHealthy, day2.Add(-time.Hour).In(time.FixedZone("UTC+8", 8*3600)), day2.Add(time.Hour)),
+			},
+			want: []dailyRow{
+				{cluster: clusterA, day: day1, healthy: 3600},
+				{cluster: clusterA, day: day2, healthy: 3600},
+			},
+		},
+		{
+			name: "clusters are kept apart",
+			periods: []*model.ClusterHealthPeriod{
+				healthPeriod(clusterA, ClusterHealthHealthy, day1, day1.Add(time.Hour)),
+				healthPeriod(clusterB, ClusterHealthDisconnected, day1, day1.Add(time.Hour)),
+			},
+			want: []dailyRow{
+				{cluster: clusterA, day: day1, healthy: 3600},
+				{cluster: clusterB, day: day1, disconnected: 3600, incidents: 1},
+			},
+		},
+		{
+			name: "open period is skipped",
+			periods: []*model.ClusterHealthPeriod{
+				healthPeriod(clusterA, ClusterHealthUnhealthy, day1, time.Time{}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := foldDailyHealth(tt.periods)
+			if len(rows) != len(tt.want) {
+				t.Fatalf("rows = %d, want %d", len(rows), len(tt.want))
+			}
+			for i, want := range tt.want {
+				row := rows[i]
+				got := dailyRow{row.ClusterID, row.Day, row.HealthySeconds, row.DegradedSeconds, row.UnhealthySeconds, row.DisconnectedSeconds, row.IncidentCount}
+				if got != want {
+					t.Errorf("row %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestComputeUptime(t *testing.T) {
+	db := testutil.OpenDB(t, &model.ClusterHealthPeriod{}, &model.ClusterHealthDaily{})
+	service := NewHealthHistoryService(nil, repository.NewClusterHealthHistoryRepository(db), 24*time.Hour, 0, 0)
+
+	// 窗口起点早于原始保留期，原始区间与降采样数据同时参与计算
+	end := utcDay(time.Now()).Add(-time.Hour)
+	start := end.Add(-24 * time.Hour)
+	clusterID := uuid.New()
+	periods := []*model.ClusterHealthPeriod{
+		// 窗口开始前已处于不可用状态，不计为窗口内的故障
+		healthPeriod(clusterID, ClusterHealthUnhealthy, start.Add(-time.Hour), start.Add(time.Hour)),
+		healthPeriod(clusterID, ClusterHealthHealthy, start.Add(time.Hour), start.Add(10*time.Hour)),
+		healthPeriod(clusterID, ClusterHealthDisconnected, start.Add(10*time.Hour), start.Add(11*time.Hour)),
+		// 与前一个不可用区间首尾相接，属于同一次故障
+		healthPeriod(clusterID, ClusterHealthUnhealthy, start.Add(11*time.Hour), start.Add(12*time.Hour)),
+		healthPeriod(clusterID, ClusterHealthDegraded, start.Add(12*time.Hour), start.Add(14*time.Hour)),
+		// 窗口结束后的区间不参与计算
+		healthPeriod(clusterID, ClusterHealthUnhealthy, end.Add(time.Minute), end.Add(time.Hour)),
+		healthPeriod(uuid.New(), ClusterHealthUnhealthy, start, end),
+	}
+	if err := db.Create(&periods).Error; err != nil {
+		t.Fatal(err)
+	}
+	// start 所在日的降采样数据按窗口覆盖比例折算
+	day := &model.ClusterHealthDaily{
+		ClusterID:        clusterID,
+		Day:              utcDay(start),
+		HealthySeconds:   20 * 3600,
+		UnhealthySeconds: 4 * 3600,
+		IncidentCount:    2,
+	}
+	if err := db.Create(day).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := service.computeUptime(clusterID, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fraction := float64(overlapSeconds(utcDay(start), utcDay(start).Add(24*time.Hour), start, end)) / (24 * 3600)
+	want := map[string]int64{
+		ClusterHealthHealthy:      9*3600 + int64(20*3600*fraction),
+		ClusterHealthDegraded:     2 * 3600,
+		ClusterHealthUnhealthy:    2*3600 + int64(4*3600*fraction),
+		ClusterHealthDisconnected: 3600,
+	}
+	for status, seconds := range want {
+		if report.StatusSeconds[status] != seconds {
+			t.Errorf("%s seconds = %d, want %d", status, report.StatusSeconds[status], seconds)
+		}
+	}
+	if report.AvailableSeconds != want[ClusterHealthHealthy]+want[ClusterHealthDegraded] {
+		t.Errorf("available seconds = %d", report.AvailableSeconds)
+	}
+	if report.ObservedSeconds+report.NoDataSeconds != 24*3600 {
+		t.Errorf("observed %d + no data %d, want the 24h window", report.ObservedSeconds, report.NoDataSeconds)
+	}
+	// 原始区间中的一次故障加上 start 当天已汇总的两次
+	if report.IncidentCount != 3 {
+		t.Errorf("incident count = %d, want 3", report.IncidentCount)
+	}
+	if report.Resolution != "mixed" {
+		t.Errorf("resolution = %q, want mixed", report.Resolution)
+	}
+	wantPercent := roundPercent(100 * float64(report.AvailableSeconds) / float64(report.ObservedSeconds))
+	if report.UptimePercent != wantPercent {
+		t.Errorf("uptime = %v, want %v", report.UptimePercent, wantPercent)
+	}
+}
+
+func TestBurnRate(t *testing.T) {
+	tests := []struct {
+		name        string
+		observed    int64
+		unavailable int64
+		allowed     float64
+		want        float64
+	}{
+		{name: "no data", allowed: 0.001},
+		{name: "no downtime", observed: 3600, allowed: 0.001},
+		{name: "exactly on budget", observed: 1000000, unavailable: 1000, allowed: 0.001, want: 1},
+		{name: "fast burn", observed: 3600, unavailable: 52, allowed: 0.001, want: 14.44},
+		{name: "rounded to two decimals", observed: 3000, unavailable: 1, allowed: 0.001, want: 0.33},
+		{name: "no budget allowed", observed: 3600, unavailable: 60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uptime := &UptimeReport{ObservedSeconds: tt.observed, UnavailableSeconds: tt.unavailable}
+			if got := burnRate(uptime, tt.allowed); got != tt.want {
+				t.Errorf("burnRate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeriodDurationSeconds(t *testing.T) {
+	start := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	now := start.Add(3 * time.Hour)
+	if got := periodDurationSeconds(healthPeriod(uuid.Nil, ClusterHealthHealthy, start, start.Add(time.Hour)), now); got != 3600 {
+		t.Errorf("closed period = %d, want 3600", got)
+	}
+	if got := periodDurationSeconds(healthPeriod(uuid.Nil, ClusterHealthHealthy, start, time.Time{}), now); got != 3*3600 {
+		t.Errorf("open period = %d, want %d", got, 3*3600)
+	}
+}
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "30d", want: 30 * 24 * time.Hour},
+		{value: "1d", want: 24 * time.Hour},
+		{value: "12h", want: 12 * time.Hour},
+		{value: "90m", want: 90 * time.Minute},
+		{value: "0d", wantErr: true},
+		{value: "-1d", wantErr: true},
+		{value: "1.5d", wantErr: true},
+		{value: "d", wantErr: true},
+		{value: "0h", wantErr: true},
+		{value: "-2h", wantErr: true},
+		{value: "30", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseWindow(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidTimeWindow) {
+					t.Fatalf("ParseWindow(%q) error = %v, want ErrInvalidTimeWindow", tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseWindow(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+			// 天级窗口格式化后应能原样解析回来
+			if formatted := formatWindow(got); formatted != tt.value && tt.want%(24*time.Hour) == 0 {
+				t.Errorf("formatWindow(%v) = %q, want %q", got, formatted, tt.value)
+			}
+		})
+	}
+}
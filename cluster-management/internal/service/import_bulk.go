@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// contextPrecheckConcurrency 并发预检的上下文数量，单个预检的超时为客户端超时
+const contextPrecheckConcurrency = 5
+
+var (
+	// ErrKubeconfigContextNotFound 选择的上下文不在 kubeconfig 中
+	ErrKubeconfigContextNotFound = errors.New("kubeconfig context not found")
+	// ErrNoContextsSelected 批量导入未选择上下文
+	ErrNoContextsSelected = errors.New("no kubeconfig contexts selected")
+)
+
+// KubeconfigContext kubeconfig 中的一个上下文及连通性预检结果
+type KubeconfigContext struct {
+	Name      string `json:"name"`
+	Cluster   string `json:"cluster"`
+	User      string `json:"user"`
+	Namespace string `json:"namespace,omitempty"`
+	Server    string `json:"server"`
+	Current   bool   `json:"current"`
+	Checked   bool   `json:"checked"`
+	Reachable bool   `json:"reachable"`
+	Version   string `json:"version,omitempty"`
+	LatencyMs int64  `json:"latency_ms,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// BulkImportContext 批量导入选择的上下文，Name 为空时使用上下文名称作为集群名称
+type BulkImportContext struct {
+	Context     string `json:"context" binding:"required"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// BulkImportRequest 批量导入请求，标签、环境类型、区域与凭据方式应用到每个集群
+type BulkImportRequest struct {
+	ImportSource    string              `json:"import_source" binding:"required"`
+	Kubeconfig      string              `json:"kubeconfig" binding:"required"`
+	Contexts        []BulkImportContext `json:"contexts" binding:"required,min=1,dive"`
+	EnvironmentType string              `json:"environment_type"`
+	Region          string              `json:"region"`
+	Labels          map[string]string   `json:"labels"`
+	CredentialMode  string              `json:"credential_mode" binding:"omitempty,oneof=kubeconfig service_account"`
+	OnDuplicate     string              `json:"on_duplicate" binding:"omitempty,oneof=reject merge"`
+}
+
+// BulkImportResult 单个上下文的导入结果，失败时 Error 不为空
+type BulkImportResult struct {
+	Context   string     `json:"context"`
+	Name      string     `json:"name"`
+	ImportID  *uuid.UUID `json:"import_id,omitempty"`
+	ClusterID *uuid.UUID `json:"cluster_id,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// ListKubeconfigContexts 解析多上下文 kubeconfig，precheck 为 true 时并发检查各上下文的 apiserver 是否可达
+func (s *ImportService) ListKubeconfigContexts(ctx context.Context, kubeconfig string, precheck bool) ([]KubeconfigContext, error) {
+	config, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKubeConfig, err)
+	}
+	if len(config.Contexts) == 0 {
+		return nil, fmt.Errorf("%w: no contexts defined", ErrInvalidKubeConfig)
+	}
+
+	contexts := make([]KubeconfigContext, 0, len(config.Contexts))
+	for name, kubeContext := range config.Contexts {
+		item := KubeconfigContext{
+			Name:      name,
+			Cluster:   kubeContext.Cluster,
+			User:      kubeContext.AuthInfo,
+			Namespace: kubeContext.Namespace,
+			Current:   name == config.CurrentContext,
+		}
+		if cluster, ok := config.Clusters[kubeContext.Cluster]; ok {
+			item.Server = cluster.Server
+		}
+		contexts = append(contexts, item)
+	}
+	sort.Slice(contexts, func(i, j int) bool { return contexts[i].Name < contexts[j].Name })
+
+	if precheck {
+		s.precheckContexts(ctx, config, contexts)
+	}
+	return contexts, nil
+}
+
+// precheckContexts 读取各上下文的 apiserver 版本，单个上下文失败不影响其他上下文
+func (s *ImportService) precheckContexts(ctx context.Context, config *clientcmdapi.Config, contexts []KubeconfigContext) {
+	slots := make(chan struct{}, contextPrecheckConcurrency)
+	var wg sync.WaitGroup
+	for i := range contexts {
+		wg.Add(1)
+		go func(item *KubeconfigContext) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			item.Checked = true
+			kubeconfig, err := contextKubeconfig(config, item.Name)
+			if err != nil {
+				item.Error = err.Error()
+				return
+			}
+
+			start := time.Now()
+			clientset, err := s.clusterManager.GetClient(ctx, kubeconfig)
+			if err != nil {
+				item.Error = err.Error()
+				return
+			}
+			info, err := clientset.Discovery().ServerVersion()
+			item.LatencyMs = time.Since(start).Milliseconds()
+			if err != nil {
+				item.Error = err.Error()
+				return
+			}
+			item.Reachable = true
+			item.Version = info.GitVersion
+		}(&contexts[i])
+	}
+	wg.Wait()
+}
+
+// BulkImport 为选择的每个上下文创建独立的集群与导入记录，单个上下文失败不影响其他上下文
+// 返回的结果与 Contexts 顺序一致，调用方负责对成功的导入记录执行 ExecuteImport
+func (s *ImportService) BulkImport(req BulkImportRequest) ([]BulkImportResult, error) {
+	if len(req.Contexts) == 0 {
+		return nil, ErrNoContextsSelected
+	}
+	config, err := clientcmd.Load([]byte(req.Kubeconfig))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKubeConfig, err)
+	}
+	for _, selected := range req.Contexts {
+		if _, ok := config.Contexts[selected.Context]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrKubeconfigContextNotFound, selected.Context)
+		}
+	}
+
+	results := make([]BulkImportResult, 0, len(req.Contexts))
+	for _, selected := range req.Contexts {
+		name := selected.Name
+		if name == "" {
+			name = selected.Context
+		}
+		result := BulkImportResult{Context: selected.Context, Name: name}
+
+		kubeconfig, err := contextKubeconfig(config, selected.Context)
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		importRecord, err := s.ImportCluster(req.ImportSource, name, selected.Description, req.EnvironmentType, req.Region, kubeconfig, req.Labels, nil, req.CredentialMode, req.OnDuplicate)
+		if err != nil {
+			log.Printf("Bulk import of context %s failed: %v", selected.Context, err)
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+		importRecord.ValidationResults["kubeconfig_context"] = selected.Context
+		if err := s.importRepo.Update(importRecord); err != nil {
+			log.Printf("Failed to record kubeconfig context of import %s: %v", importRecord.ID, err)
+		}
+
+		result.ImportID = &importRecord.ID
+		result.ClusterID = importRecord.ClusterID
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// contextKubeconfig 提取单个上下文及其引用的集群与用户，生成只包含该上下文的 kubeconfig
+func contextKubeconfig(config *clientcmdapi.Config, contextName string) (string, error) {
+	single := config.DeepCopy()
+	single.CurrentContext = contextName
+	if err := clientcmdapi.MinifyConfig(single); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidKubeConfig, err)
+	}
+	data, err := clientcmd.Write(*single)
+	if err != nil {
+		return "", fmt.Errorf("failed to write kubeconfig of context %s: %w", contextName, err)
+	}
+	return string(data), nil
+}
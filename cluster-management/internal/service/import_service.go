@@ -6,6 +6,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/google/uuid"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
@@ -26,6 +27,9 @@ type ImportService struct {
 	applicationRepo    *repository.ApplicationRepository
 	quotaRepo          *repository.QuotaRepository
 	resourceClassifier *ResourceClassifier
+	connectionService  *ClusterConnectionService
+	managerAccounts    *ManagerAccountService
+	validator          *ImportValidator
 }
 
 type ImportRecordWithDetails struct {
@@ -42,21 +46,30 @@ func NewImportService(
 	environmentRepo *repository.EnvironmentRepository,
 	applicationRepo *repository.ApplicationRepository,
 	quotaRepo *repository.QuotaRepository,
+	connectionService *ClusterConnectionService,
+	managerAccounts *ManagerAccountService,
+	validator *ImportValidator,
 ) *ImportService {
 	return &ImportService{
-		importRepo:      importRepo,
-		clusterRepo:     clusterRepo,
-		encryptionSvc:   encryptionSvc,
-		clusterManager:  clusterManager,
-		clusterService:  clusterService,
-		tenantRepo:      tenantRepo,
-		environmentRepo: environmentRepo,
-		applicationRepo: applicationRepo,
-		quotaRepo:       quotaRepo,
+		importRepo:        importRepo,
+		clusterRepo:       clusterRepo,
+		encryptionSvc:     encryptionSvc,
+		clusterManager:    clusterManager,
+		clusterService:    clusterService,
+		tenantRepo:        tenantRepo,
+		environmentRepo:   environmentRepo,
+		applicationRepo:   applicationRepo,
+		quotaRepo:         quotaRepo,
+		connectionService: connectionService,
+		managerAccounts:   managerAccounts,
+		validator:         validator,
 	}
 }
 
-func (s *ImportService) ImportCluster(importSource, name, description, environmentType, region, kubeconfig string, labels map[string]string) (*model.ImportRecord, error) {
+// ImportCluster 创建导入记录与集群，connection 不为空时保存连接设置（堡垒机/代理），之后的导入与同步都经由该连接
+// credentialMode 为 service_account 时导入凭据只在执行导入时使用一次，用于创建最小权限 ServiceAccount
+// onDuplicate 为 merge 时集群已被导入则不创建新集群，标签合并到已导入的集群
+func (s *ImportService) ImportCluster(importSource, name, description, environmentType, region, kubeconfig string, labels map[string]string, connection *ClusterConnectionInput, credentialMode, onDuplicate string) (*model.ImportRecord, error) {
 	log.Printf("Starting ImportCluster with importSource=%s, name=%s", importSource, name)
 
 	// 验证kubeconfig
@@ -68,6 +81,16 @@ func (s *ImportService) ImportCluster(importSource, name, description, environme
 
 	log.Printf("Kubeconfig validation passed")
 
+	if credentialMode == "" {
+		credentialMode = model.ClusterCredentialKubeconfig
+	}
+	if credentialMode == model.ClusterCredentialServiceAccount && s.managerAccounts == nil {
+		return nil, fmt.Errorf("service account credential mode is not available")
+	}
+	if onDuplicate == "" {
+		onDuplicate = DuplicateActionReject
+	}
+
 	// 创建导入记录
 	importRecord := &model.ImportRecord{
 		ImportSource: importSource,
@@ -75,6 +98,8 @@ func (s *ImportService) ImportCluster(importSource, name, description, environme
 		ImportedBy:   "api-user",
 		ValidationResults: map[string]interface{}{
 			"kubeconfig_valid": true,
+			"credential_mode":  credentialMode,
+			"on_duplicate":     onDuplicate,
 		},
 		ImportedResources: map[string]interface{}{
 			"nodes":       "pending",
@@ -135,6 +160,17 @@ func (s *ImportService) ImportCluster(importSource, name, description, environme
 
 	log.Printf("Cluster created with ID: %s", cluster.ID.String())
 
+	if connection != nil && s.connectionService != nil {
+		if _, err := s.connectionService.Save(cluster.ID, *connection); err != nil {
+			log.Printf("Failed to save connection settings: %v", err)
+			if delErr := s.clusterRepo.Delete(cluster.ID.String()); delErr != nil {
+				log.Printf("Failed to roll back cluster %s: %v", cluster.ID.String(), delErr)
+			}
+			s.handleImportError(importRecord, err)
+			return nil, err
+		}
+	}
+
 	// 更新导入记录
 	importRecord.ClusterID = &cluster.ID
 	if err := s.importRepo.Update(importRecord); err != nil {
@@ -189,6 +225,47 @@ func (s *ImportService) ExecuteImport(importID string) error {
 
 	log.Printf("Successfully decrypted kubeconfig")
 
+	// 经集群代理连接时，等待代理注册并建立隧道后再访问集群
+	if s.connectionService != nil {
+		if connection, err := s.connectionService.Get(cluster.ID); err == nil && connection.Mode == model.ClusterConnectionAgent {
+			importRecord.ImportStatus = "waiting_for_agent"
+			if err := s.importRepo.Update(importRecord); err != nil {
+				log.Printf("Failed to update import status to waiting_for_agent: %v", err)
+			}
+			if err := s.connectionService.AwaitAgent(context.Background(), cluster.ID); err != nil {
+				log.Printf("Cluster agent did not connect: %v", err)
+				return s.handleImportError(importRecord, err)
+			}
+		}
+	}
+
+	// 最小权限导入：用导入凭据创建 ServiceAccount，之后只保存其令牌
+	if mode, _ := importRecord.ValidationResults["credential_mode"].(string); mode == model.ClusterCredentialServiceAccount {
+		importRecord.ImportStatus = "creating_service_account"
+		if err := s.importRepo.Update(importRecord); err != nil {
+			log.Printf("Failed to update import status to creating_service_account: %v", err)
+		}
+		kubeconfig, err = s.createManagerAccount(cluster)
+		if err != nil {
+			log.Printf("Failed to create manager service account: %v", err)
+			return s.handleImportError(importRecord, err)
+		}
+	}
+
+	report, err := s.validateCluster(importRecord, cluster, kubeconfig)
+	if err != nil {
+		log.Printf("Import validation failed: %v", err)
+		return s.handleImportError(importRecord, err)
+	}
+	if len(report.Duplicates) > 0 {
+		if err := s.mergeDuplicate(importRecord, cluster, report.Duplicates[0], report.Fingerprint); err != nil {
+			log.Printf("Failed to merge duplicate cluster: %v", err)
+			return s.handleImportError(importRecord, err)
+		}
+		log.Printf("ExecuteImport merged cluster %s into %s", cluster.ID, report.Duplicates[0])
+		return nil
+	}
+
 	// 更新状态为importing
 	importRecord.ImportStatus = "importing"
 	if err := s.importRepo.Update(importRecord); err != nil {
@@ -222,9 +299,126 @@ func (s *ImportService) ExecuteImport(importID string) error {
 	return nil
 }
 
+// createManagerAccount 创建最小权限 ServiceAccount 并用其令牌替换保存的导入凭据
+// 失败时清除导入凭据，不保留管理员 kubeconfig，需重新导入
+func (s *ImportService) createManagerAccount(cluster *model.Cluster) (string, error) {
+	importKubeconfig, err := s.encryptionSvc.Decrypt(cluster.KubeconfigEncrypted)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt kubeconfig: %w", err)
+	}
+
+	kubeconfig, account, err := s.managerAccounts.Bootstrap(context.Background(), cluster.ID, importKubeconfig)
+	if err == nil {
+		var encrypted string
+		if encrypted, err = s.encryptionSvc.Encrypt(kubeconfig); err == nil {
+			err = s.clusterRepo.UpdateKubeconfig(cluster.ID.String(), encrypted)
+		}
+	}
+	if err == nil {
+		err = s.managerAccounts.SaveAccount(account)
+	}
+	if err != nil {
+		if clearErr := s.clusterRepo.UpdateKubeconfig(cluster.ID.String(), ""); clearErr != nil {
+			log.Printf("Failed to clear import credential of cluster %s: %v", cluster.ID, clearErr)
+		}
+		s.clusterManager.InvalidateCluster(cluster.ID)
+		return "", fmt.Errorf("failed to create manager service account: %w", err)
+	}
+
+	s.clusterManager.InvalidateCluster(cluster.ID)
+	log.Printf("Replaced import credential of cluster %s with service account %s", cluster.ID, ManagerServiceAccountName)
+	return kubeconfig, nil
+}
+
+// validateCluster 执行导入校验并按校验项写入校验结果，存在未通过的 fatal 校验项时导入失败
+// 集群已被导入且 on_duplicate 为 merge 时不返回错误，由调用方合并到报告中的 Duplicates
+func (s *ImportService) validateCluster(importRecord *model.ImportRecord, cluster *model.Cluster, kubeconfig string) (*ImportValidationReport, error) {
+	ctx := context.Background()
+	clientset, err := s.clusterManager.GetClientForCluster(ctx, cluster.ID, kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	var extra []permissionCheck
+	if mode, _ := importRecord.ValidationResults["credential_mode"].(string); mode == model.ClusterCredentialServiceAccount {
+		extra = append(extra, s.managerAccounts.TokenPermissionCheck())
+	}
+	report := s.validator.Validate(ctx, cluster.ID, clientset, extra...)
+
+	importRecord.ValidationResults["checks"] = report.Checks
+	importRecord.ValidationResults["summary"] = report.Summary()
+	if report.KubernetesVersion != "" {
+		importRecord.ValidationResults["kubernetes_version"] = report.KubernetesVersion
+	}
+	if report.Fingerprint != nil {
+		importRecord.ValidationResults["fingerprint"] = report.Fingerprint
+	}
+	if len(report.CNI) > 0 {
+		importRecord.ValidationResults["cni"] = report.CNI
+	}
+
+	onDuplicate, _ := importRecord.ValidationResults["on_duplicate"].(string)
+	if onDuplicate == DuplicateActionMerge && len(report.Duplicates) > 0 {
+		return report, report.Error(ValidationCheckDuplicate)
+	}
+	if err := report.Error(); err != nil {
+		return report, err
+	}
+
+	if fingerprint := report.Fingerprint; fingerprint != nil {
+		if err := s.clusterRepo.UpdateFingerprint(cluster.ID.String(), fingerprint.ClusterUID, fingerprint.CAHash, fingerprint.Fingerprint); err != nil {
+			log.Printf("Failed to record fingerprint of cluster %s: %v", cluster.ID, err)
+		}
+	}
+	return report, nil
+}
+
+// mergeDuplicate 将重复导入合并到最早导入的同一集群：合并标签、导入记录指向已有集群并删除本次创建的集群
+// 已有集群的凭据保持不变
+func (s *ImportService) mergeDuplicate(importRecord *model.ImportRecord, cluster *model.Cluster, targetID uuid.UUID, fingerprint *ClusterFingerprint) error {
+	target, err := s.clusterRepo.GetByID(targetID.String())
+	if err != nil {
+		return fmt.Errorf("failed to get cluster %s: %w", targetID, err)
+	}
+
+	labels := model.JSONMap{}
+	for k, v := range target.Labels {
+		labels[k] = v
+	}
+	for k, v := range cluster.Labels {
+		if _, exists := labels[k]; !exists {
+			labels[k] = v
+		}
+	}
+	if err := s.clusterRepo.UpdateLabels(target.ID.String(), labels); err != nil {
+		return fmt.Errorf("failed to merge labels: %w", err)
+	}
+	if target.Fingerprint == "" && fingerprint != nil {
+		if err := s.clusterRepo.UpdateFingerprint(target.ID.String(), fingerprint.ClusterUID, fingerprint.CAHash, fingerprint.Fingerprint); err != nil {
+			log.Printf("Failed to record fingerprint of cluster %s: %v", target.ID, err)
+		}
+	}
+
+	importRecord.ClusterID = &target.ID
+	importRecord.ImportStatus = constants.StatusCompleted
+	importRecord.CompletedAt = func() *time.Time { now := time.Now(); return &now }()
+	importRecord.ValidationResults["merged_into"] = target.ID.String()
+	importRecord.ValidationResults["merged_cluster_name"] = target.Name
+	importRecord.ValidationResults["import_completed"] = true
+	if err := s.importRepo.Update(importRecord); err != nil {
+		return fmt.Errorf("failed to update import record: %w", err)
+	}
+
+	if err := s.clusterRepo.Delete(cluster.ID.String()); err != nil {
+		log.Printf("Failed to delete merged cluster %s: %v", cluster.ID, err)
+	}
+	s.clusterManager.InvalidateCluster(cluster.ID)
+	return nil
+}
+
 func (s *ImportService) performImport(importRecord *model.ImportRecord, cluster *model.Cluster, kubeconfig string) error {
 	ctx := context.Background()
-	clientset, err := s.clusterManager.GetClient(ctx, kubeconfig)
+	clientset, err := s.clusterManager.GetClientForCluster(ctx, cluster.ID, kubeconfig)
 	if err != nil {
 		return fmt.Errorf("failed to get client: %w", err)
 	}
@@ -0,0 +1,425 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/taichu-system/cluster-management/internal/repository"
+)
+
+// 导入校验项
+const (
+	ValidationCheckConnectivity  = "connectivity"
+	ValidationCheckVersion       = "version"
+	ValidationCheckRBAC          = "rbac"
+	ValidationCheckMetricsAPI    = "metrics_api"
+	ValidationCheckNodeReadiness = "node_readiness"
+	ValidationCheckDuplicate     = "duplicate"
+	ValidationCheckCNI           = "cni"
+)
+
+// 校验项结果
+const (
+	ValidationStatusPassed  = "passed"
+	ValidationStatusFailed  = "failed"
+	ValidationStatusSkipped = "skipped"
+)
+
+// 校验项未通过时的严重程度，fatal 阻止导入
+const (
+	ValidationSeverityFatal   = "fatal"
+	ValidationSeverityWarning = "warning"
+	ValidationSeverityInfo    = "info"
+)
+
+const (
+	defaultImportMinVersion        = "1.20"
+	defaultImportMaxVersion        = "1.32"
+	defaultImportValidationTimeout = 60 * time.Second
+	maxReportedNotReadyNodes       = 20
+)
+
+// cniSignatures 通过 DaemonSet 名称识别 CNI 插件
+var cniSignatures = []struct {
+	Plugin string
+	Prefix string
+}{
+	{Plugin: "calico", Prefix: "calico-node"},
+	{Plugin: "cilium", Prefix: "cilium"},
+	{Plugin: "flannel", Prefix: "kube-flannel"},
+	{Plugin: "canal", Prefix: "canal"},
+	{Plugin: "weave", Prefix: "weave-net"},
+	{Plugin: "antrea", Prefix: "antrea-agent"},
+	{Plugin: "kube-ovn", Prefix: "kube-ovn-cni"},
+	{Plugin: "kube-router", Prefix: "kube-router"},
+	{Plugin: "aws-vpc-cni", Prefix: "aws-node"},
+	{Plugin: "azure-cni", Prefix: "azure-cni"},
+	{Plugin: "multus", Prefix: "kube-multus"},
+}
+
+// ValidationCheckResult 单项校验结果
+type ValidationCheckResult struct {
+	Name     string                 `json:"name"`
+	Status   string                 `json:"status"`   // passed/failed/skipped
+	Severity string                 `json:"severity"` // fatal/warning/info
+	Message  string                 `json:"message,omitempty"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+}
+
+// ImportValidationReport 导入校验报告
+type ImportValidationReport struct {
+	Checks            []ValidationCheckResult `json:"checks"`
+	KubernetesVersion string                  `json:"kubernetes_version,omitempty"`
+	Fingerprint       *ClusterFingerprint     `json:"fingerprint,omitempty"`
+	Duplicates        []uuid.UUID             `json:"duplicates,omitempty"` // 已导入的同一集群，按创建时间排序
+	CNI               []string                `json:"cni,omitempty"`
+}
+
+// Fatal 未通过的 fatal 校验项，ignore 为调用方另行处理的校验项
+func (r *ImportValidationReport) Fatal(ignore ...string) []ValidationCheckResult {
+	var fatal []ValidationCheckResult
+	for _, check := range r.Checks {
+		if check.Status == ValidationStatusFailed && check.Severity == ValidationSeverityFatal && !slices.Contains(ignore, check.Name) {
+			fatal = append(fatal, check)
+		}
+	}
+	return fatal
+}
+
+// Summary 各结果的数量与阻止导入的校验项
+func (r *ImportValidationReport) Summary() map[string]interface{} {
+	summary := map[string]interface{}{
+		"passed":   0,
+		"warnings": 0,
+		"skipped":  0,
+	}
+	fatal := []string{}
+	for _, check := range r.Checks {
+		switch {
+		case check.Status == ValidationStatusPassed:
+			summary["passed"] = summary["passed"].(int) + 1
+		case check.Status == ValidationStatusSkipped:
+			summary["skipped"] = summary["skipped"].(int) + 1
+		case check.Severity == ValidationSeverityFatal:
+			fatal = append(fatal, check.Name)
+		default:
+			summary["warnings"] = summary["warnings"].(int) + 1
+		}
+	}
+	summary["fatal"] = fatal
+	return summary
+}
+
+// Error 汇总 fatal 校验项作为导入失败原因
+func (r *ImportValidationReport) Error(ignore ...string) error {
+	fatal := r.Fatal(ignore...)
+	if len(fatal) == 0 {
+		return nil
+	}
+	messages := make([]string, 0, len(fatal))
+	for _, check := range fatal {
+		messages = append(messages, fmt.Sprintf("%s: %s", check.Name, check.Message))
+	}
+	return fmt.Errorf("import validation failed: %s", strings.Join(messages, "; "))
+}
+
+func (r *ImportValidationReport) add(check ValidationCheckResult) {
+	r.Checks = append(r.Checks, check)
+}
+
+// ImportValidator 导入时对集群执行的结构化校验
+type ImportValidator struct {
+	clusterRepo *repository.ClusterRepository
+	minVersion  *utilversion.Version
+	maxVersion  *utilversion.Version
+	timeout     time.Duration
+}
+
+// NewImportValidator 创建导入校验器，minVersion/maxVersion 为支持的 Kubernetes 版本范围
+func NewImportValidator(clusterRepo *repository.ClusterRepository, minVersion, maxVersion string, timeout time.Duration) *ImportValidator {
+	if timeout <= 0 {
+		timeout = defaultImportValidationTimeout
+	}
+	return &ImportValidator{
+		clusterRepo: clusterRepo,
+		minVersion:  parseSupportedVersion(minVersion, defaultImportMinVersion),
+		maxVersion:  parseSupportedVersion(maxVersion, defaultImportMaxVersion),
+		timeout:     timeout,
+	}
+}
+
+func parseSupportedVersion(value, fallback string) *utilversion.Version {
+	if value == "" {
+		value = fallback
+	}
+	parsed, err := utilversion.ParseGeneric(value)
+	if err != nil {
+		log.Printf("Invalid supported Kubernetes version %q, using %s: %v", value, fallback, err)
+		parsed = utilversion.MustParseGeneric(fallback)
+	}
+	return parsed
+}
+
+// Validate 依次执行连通性、版本、RBAC、metrics API、节点就绪、重复导入与 CNI 校验
+// 连通性失败时其余校验项跳过；extra 为额外的权限检查项
+func (v *ImportValidator) Validate(ctx context.Context, clusterID uuid.UUID, clientset kubernetes.Interface, extra ...permissionCheck) *ImportValidationReport {
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+
+	report := &ImportValidationReport{Checks: []ValidationCheckResult{}}
+
+	info, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		report.add(ValidationCheckResult{
+			Name:     ValidationCheckConnectivity,
+			Status:   ValidationStatusFailed,
+			Severity: ValidationSeverityFatal,
+			Message:  fmt.Sprintf("failed to reach apiserver: %v", err),
+		})
+		for _, name := range []string{ValidationCheckVersion, ValidationCheckRBAC, ValidationCheckMetricsAPI, ValidationCheckNodeReadiness, ValidationCheckDuplicate, ValidationCheckCNI} {
+			report.add(ValidationCheckResult{Name: name, Status: ValidationStatusSkipped, Severity: ValidationSeverityInfo, Message: "apiserver unreachable"})
+		}
+		return report
+	}
+	report.KubernetesVersion = info.GitVersion
+	report.add(ValidationCheckResult{
+		Name:     ValidationCheckConnectivity,
+		Status:   ValidationStatusPassed,
+		Severity: ValidationSeverityFatal,
+		Details:  map[string]interface{}{"platform": info.Platform},
+	})
+
+	report.add(v.checkVersion(info.GitVersion))
+	report.add(v.checkRBAC(ctx, clientset, extra))
+	report.add(v.checkMetricsAPI(ctx, clientset))
+	report.add(v.checkNodeReadiness(ctx, clientset))
+
+	report.add(v.checkDuplicate(ctx, clusterID, clientset, report))
+
+	cni, plugins := v.checkCNI(ctx, clientset)
+	report.CNI = plugins
+	report.add(cni)
+	return report
+}
+
+// checkVersion 低于最低版本时阻止导入，高于已验证的最高版本仅提示
+func (v *ImportValidator) checkVersion(gitVersion string) ValidationCheckResult {
+	check := ValidationCheckResult{
+		Name:     ValidationCheckVersion,
+		Severity: ValidationSeverityFatal,
+		Details: map[string]interface{}{
+			"version":     gitVersion,
+			"min_version": v.minVersion.String(),
+			"max_version": v.maxVersion.String(),
+		},
+	}
+	version, err := utilversion.ParseGeneric(gitVersion)
+	if err != nil {
+		check.Status = ValidationStatusFailed
+		check.Severity = ValidationSeverityWarning
+		check.Message = fmt.Sprintf("cannot parse Kubernetes version %q: %v", gitVersion, err)
+		return check
+	}
+
+	minor := utilversion.MajorMinor(version.Major(), version.Minor())
+	switch {
+	case minor.LessThan(v.minVersion):
+		check.Status = ValidationStatusFailed
+		check.Message = fmt.Sprintf("Kubernetes %s is older than the minimum supported version %s", gitVersion, v.minVersion)
+	case v.maxVersion.LessThan(minor):
+		check.Status = ValidationStatusFailed
+		check.Severity = ValidationSeverityWarning
+		check.Message = fmt.Sprintf("Kubernetes %s is newer than the latest verified version %s", gitVersion, v.maxVersion)
+	default:
+		check.Status = ValidationStatusPassed
+	}
+	return check
+}
+
+// checkRBAC 检查平台使用的每个操作，缺少必需权限时阻止导入
+func (v *ImportValidator) checkRBAC(ctx context.Context, clientset kubernetes.Interface, extra []permissionCheck) ValidationCheckResult {
+	check := ValidationCheckResult{Name: ValidationCheckRBAC, Severity: ValidationSeverityFatal}
+	permissions, err := CheckPermissions(ctx, clientset, extra...)
+	if err != nil {
+		// 无法检查权限时不阻止导入，各功能在缺少权限时自行报错
+		check.Status = ValidationStatusSkipped
+		check.Severity = ValidationSeverityWarning
+		check.Message = err.Error()
+		return check
+	}
+	check.Details = permissions.toJSONMap()
+
+	if missing := permissions.RequiredMissing(); len(missing) > 0 {
+		check.Status = ValidationStatusFailed
+		check.Message = "credential is missing required permissions: " + formatMissingPermissions(missing)
+		return check
+	}
+	if len(permissions.Missing) > 0 {
+		features := map[string]bool{}
+		for _, m := range permissions.Missing {
+			features[m.Feature] = true
+		}
+		names := make([]string, 0, len(features))
+		for feature := range features {
+			names = append(names, feature)
+		}
+		sort.Strings(names)
+		check.Status = ValidationStatusFailed
+		check.Severity = ValidationSeverityWarning
+		check.Message = "features unavailable due to missing permissions: " + strings.Join(names, ", ")
+		return check
+	}
+	check.Status = ValidationStatusPassed
+	return check
+}
+
+// checkMetricsAPI 没有 metrics API 时节点与 Pod 用量、弹性策略不可用
+func (v *ImportValidator) checkMetricsAPI(ctx context.Context, clientset kubernetes.Interface) ValidationCheckResult {
+	check := ValidationCheckResult{Name: ValidationCheckMetricsAPI, Severity: ValidationSeverityWarning}
+	component := checkMetricsServer(ctx, clientset)
+	if component.Status == ComponentStatusHealthy {
+		check.Status = ValidationStatusPassed
+		return check
+	}
+	check.Status = ValidationStatusFailed
+	check.Message = "metrics.k8s.io unavailable, resource usage will not be reported: " + component.Message
+	return check
+}
+
+// checkNodeReadiness 统计节点就绪情况
+func (v *ImportValidator) checkNodeReadiness(ctx context.Context, clientset kubernetes.Interface) ValidationCheckResult {
+	check := ValidationCheckResult{Name: ValidationCheckNodeReadiness, Severity: ValidationSeverityWarning}
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		check.Status = ValidationStatusSkipped
+		check.Message = fmt.Sprintf("failed to list nodes: %v", err)
+		return check
+	}
+
+	ready := 0
+	notReady := []string{}
+	for _, node := range nodes.Items {
+		if nodeReady(node) {
+			ready++
+		} else if len(notReady) < maxReportedNotReadyNodes {
+			notReady = append(notReady, node.Name)
+		}
+	}
+	check.Details = map[string]interface{}{
+		"total":     len(nodes.Items),
+		"ready":     ready,
+		"not_ready": notReady,
+	}
+	switch {
+	case len(nodes.Items) == 0:
+		check.Status = ValidationStatusFailed
+		check.Message = "cluster has no nodes"
+	case ready < len(nodes.Items):
+		check.Status = ValidationStatusFailed
+		check.Message = fmt.Sprintf("%d of %d nodes are not ready", len(nodes.Items)-ready, len(nodes.Items))
+	default:
+		check.Status = ValidationStatusPassed
+	}
+	return check
+}
+
+// checkDuplicate 以 kube-system 命名空间 UID 与根 CA 哈希识别同一集群，已被其他集群记录导入时阻止导入
+func (v *ImportValidator) checkDuplicate(ctx context.Context, clusterID uuid.UUID, clientset kubernetes.Interface, report *ImportValidationReport) ValidationCheckResult {
+	check := ValidationCheckResult{Name: ValidationCheckDuplicate, Severity: ValidationSeverityFatal}
+	fingerprint, err := ReadClusterFingerprint(ctx, clientset)
+	if err != nil {
+		check.Status = ValidationStatusSkipped
+		check.Severity = ValidationSeverityWarning
+		check.Message = err.Error()
+		return check
+	}
+	report.Fingerprint = fingerprint
+	check.Details = map[string]interface{}{
+		"cluster_uid": fingerprint.ClusterUID,
+		"ca_hash":     fingerprint.CAHash,
+		"fingerprint": fingerprint.Fingerprint,
+	}
+
+	if v.clusterRepo == nil {
+		check.Status = ValidationStatusPassed
+		return check
+	}
+	duplicates, err := v.clusterRepo.FindDuplicates(fingerprint.ClusterUID, fingerprint.CAHash, clusterID.String())
+	if err != nil {
+		check.Status = ValidationStatusSkipped
+		check.Severity = ValidationSeverityWarning
+		check.Message = fmt.Sprintf("failed to look up clusters: %v", err)
+		return check
+	}
+	if len(duplicates) == 0 {
+		check.Status = ValidationStatusPassed
+		return check
+	}
+
+	names := make([]string, 0, len(duplicates))
+	ids := make([]string, 0, len(duplicates))
+	for _, duplicate := range duplicates {
+		names = append(names, duplicate.Name)
+		ids = append(ids, duplicate.ID.String())
+		report.Duplicates = append(report.Duplicates, duplicate.ID)
+	}
+	check.Details["duplicate_cluster_ids"] = ids
+	check.Status = ValidationStatusFailed
+	check.Message = fmt.Sprintf("cluster is already imported as %s", strings.Join(names, ", "))
+	return check
+}
+
+// checkCNI 通过 DaemonSet 名称识别 CNI 插件，仅作提示
+func (v *ImportValidator) checkCNI(ctx context.Context, clientset kubernetes.Interface) (ValidationCheckResult, []string) {
+	check := ValidationCheckResult{Name: ValidationCheckCNI, Severity: ValidationSeverityInfo}
+	daemonSets, err := clientset.AppsV1().DaemonSets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		check.Status = ValidationStatusSkipped
+		check.Message = fmt.Sprintf("failed to list daemonsets: %v", err)
+		return check, nil
+	}
+
+	found := map[string]bool{}
+	for _, ds := range daemonSets.Items {
+		for _, signature := range cniSignatures {
+			if strings.HasPrefix(ds.Name, signature.Prefix) {
+				found[signature.Plugin] = true
+			}
+		}
+	}
+	plugins := make([]string, 0, len(found))
+	for plugin := range found {
+		plugins = append(plugins, plugin)
+	}
+	sort.Strings(plugins)
+
+	check.Details = map[string]interface{}{"plugins": plugins}
+	if len(plugins) == 0 {
+		check.Status = ValidationStatusFailed
+		check.Message = "no known CNI plugin detected"
+		return check, plugins
+	}
+	check.Status = ValidationStatusPassed
+	return check, plugins
+}
+
+// nodeReady 节点 Ready 条件为 True
+func nodeReady(node corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/taichu-system/cluster-management/internal/model"
+	"sigs.k8s.io/yaml"
+)
+
+// criSockets 容器运行时对应的CRI套接字
+var criSockets = map[string]string{
+	"containerd": "unix:///run/containerd/containerd.sock",
+	"docker":     "unix:///var/run/cri-dockerd.sock",
+	"crio":       "unix:///var/run/crio/crio.sock",
+	"isula":      "unix:///var/run/isulad.sock",
+}
+
+// GenerateKubeadmConfig 生成 kubeadm init 使用的配置文件
+func (g *ConfigGenerator) GenerateKubeadmConfig(req CreateClusterRequest, machines []*model.Machine) (string, error) {
+	applyClusterDefaults(&req)
+
+	var firstMaster *model.Machine
+	sans := make([]string, 0)
+	for _, machine := range machines {
+		if machine.Role != "master" {
+			continue
+		}
+		if firstMaster == nil {
+			firstMaster = machine
+		}
+		sans = append(sans, machine.Name, machine.IPAddress)
+		if machine.InternalAddress != "" && machine.InternalAddress != machine.IPAddress {
+			sans = append(sans, machine.InternalAddress)
+		}
+	}
+	if firstMaster == nil {
+		return "", fmt.Errorf("at least one master node is required")
+	}
+
+	ApplyTopologyDefaults(&req.Topology, machines)
+	if err := ValidateTopology(req.Topology, machines); err != nil {
+		return "", err
+	}
+	if err := validateKubeadmTopology(req.Topology); err != nil {
+		return "", err
+	}
+	sans = append(sans, req.Topology.ExtraSANs...)
+
+	criSocket, ok := criSockets[req.Kubernetes.ContainerManager]
+	if !ok {
+		return "", fmt.Errorf("unsupported container manager for kubeadm: %s", req.Kubernetes.ContainerManager)
+	}
+
+	imageRepo := req.Kubernetes.ImageRepo
+	if imageRepo == "" && req.Topology.Registry.PrivateRegistry != "" {
+		imageRepo = req.Topology.Registry.PrivateRegistry
+	}
+	if imageRepo == "" {
+		imageRepo = "registry.k8s.io"
+	}
+
+	advertiseAddress := machineInternalAddress(firstMaster)
+	controlPlaneEndpoint := net.JoinHostPort(advertiseAddress, "6443")
+	if req.Topology.HA.Mode == HAModeExternal {
+		controlPlaneEndpoint = net.JoinHostPort(req.Topology.HA.Address, strconv.Itoa(req.Topology.HA.Port))
+		sans = append(sans, req.Topology.HA.Address)
+	}
+
+	data := map[string]interface{}{
+		"clusterName":          req.ClusterName,
+		"kubernetesVersion":    req.Kubernetes.Version,
+		"imageRepo":            imageRepo,
+		"criSocket":            criSocket,
+		"nodeName":             firstMaster.Name,
+		"advertiseAddress":     advertiseAddress,
+		"controlPlaneEndpoint": controlPlaneEndpoint,
+		"podsCIDR":             req.Network.PodsCIDR,
+		"serviceCIDR":          req.Network.ServiceCIDR,
+		"certSANs":             sans,
+		"apiserverArgs":        req.Topology.APIServerExtraArgs,
+		"kubeletArgs":          req.Topology.KubeletExtraArgs,
+	}
+
+	tmpl := `apiVersion: kubeadm.k8s.io/v1beta3
+kind: InitConfiguration
+nodeRegistration:
+  name: {{.nodeName}}
+  criSocket: {{.criSocket}}
+{{- if .kubeletArgs }}
+  kubeletExtraArgs:
+{{- range $key, $value := .kubeletArgs }}
+    {{$key}}: {{printf "%q" $value}}
+{{- end }}
+{{- end }}
+localAPIEndpoint:
+  advertiseAddress: {{.advertiseAddress}}
+  bindPort: 6443
+---
+apiVersion: kubeadm.k8s.io/v1beta3
+kind: ClusterConfiguration
+clusterName: {{.clusterName}}
+kubernetesVersion: {{.kubernetesVersion}}
+controlPlaneEndpoint: "{{.controlPlaneEndpoint}}"
+imageRepository: "{{.imageRepo}}"
+networking:
+  podSubnet: {{.podsCIDR}}
+  serviceSubnet: {{.serviceCIDR}}
+apiServer:
+  certSANs:
+{{- range .certSANs }}
+  - "{{.}}"
+{{- end }}
+{{- if .apiserverArgs }}
+  extraArgs:
+{{- range $key, $value := .apiserverArgs }}
+    {{$key}}: {{printf "%q" $value}}
+{{- end }}
+{{- end }}
+---
+apiVersion: kubelet.config.k8s.io/v1beta1
+kind: KubeletConfiguration
+cgroupDriver: systemd
+`
+
+	t, err := template.New("kubeadm").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// validateKubeadmTopology kubeadm 驱动仅支持外部负载均衡与同机 etcd，镜像加速需在节点运行时中预先配置
+func validateKubeadmTopology(topology TopologyConfig) error {
+	switch topology.HA.Mode {
+	case HAModeNone, HAModeExternal:
+	default:
+		return topologyError("kubeadm driver does not support %s mode, use an external load balancer", topology.HA.Mode)
+	}
+	if topology.Etcd.Mode != EtcdModeStacked {
+		return topologyError("kubeadm driver only supports stacked etcd")
+	}
+	registry := topology.Registry
+	if len(registry.Mirrors) > 0 || len(registry.InsecureRegistries) > 0 || len(registry.Auths) > 0 {
+		return topologyError("kubeadm driver does not configure registry mirrors, insecure registries or auths; configure the container runtime on the nodes")
+	}
+	if registry.NamespaceOverride != "" {
+		return topologyError("kubeadm driver does not support registry namespace override")
+	}
+	return nil
+}
+
+// kubeletExtraArgsFromConfig 从 kubeadm 配置读取 kubelet 额外参数，用于加入集群的节点
+func kubeletExtraArgsFromConfig(configYaml string) (map[string]string, error) {
+	first := strings.SplitN(configYaml, "\n---\n", 2)[0]
+	var init struct {
+		NodeRegistration struct {
+			KubeletExtraArgs map[string]string `json:"kubeletExtraArgs"`
+		} `json:"nodeRegistration"`
+	}
+	if err := yaml.Unmarshal([]byte(first), &init); err != nil {
+		return nil, fmt.Errorf("failed to parse kubeadm config: %w", err)
+	}
+	return init.NodeRegistration.KubeletExtraArgs, nil
+}
+
+// machineInternalAddress 返回机器内网地址，未配置时使用IP地址
+func machineInternalAddress(machine *model.Machine) string {
+	if machine.InternalAddress != "" {
+		return machine.InternalAddress
+	}
+	return machine.IPAddress
+}
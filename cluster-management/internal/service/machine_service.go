@@ -13,16 +13,33 @@ import (
 
 // MachineService 机器服务
 type MachineService struct {
-	machineRepo *repository.MachineRepository
+	machineRepo       *repository.MachineRepository
+	credentialRepo    *repository.MachineCredentialRepository
+	encryptionService *EncryptionService
+	sshService        *SSHService
 }
 
 // NewMachineService 创建机器服务
-func NewMachineService(machineRepo *repository.MachineRepository) *MachineService {
+func NewMachineService(
+	machineRepo *repository.MachineRepository,
+	credentialRepo *repository.MachineCredentialRepository,
+	encryptionService *EncryptionService,
+) *MachineService {
 	return &MachineService{
-		machineRepo: machineRepo,
+		machineRepo:       machineRepo,
+		credentialRepo:    credentialRepo,
+		encryptionService: encryptionService,
+		sshService:        NewSSHService(),
 	}
 }
 
+// MachineSecrets 机器明文凭据，仅在写入时使用，落库前加密
+type MachineSecrets struct {
+	Password   string
+	PrivateKey string
+	Passphrase string
+}
+
 // CreateMachine 创建机器
 func (s *MachineService) CreateMachine(machine *model.Machine) error {
 	err := s.machineRepo.GetDB().Transaction(func(tx *gorm.DB) error {
@@ -86,6 +103,31 @@ func (s *MachineService) GetMachinesByRole(role string) ([]*model.Machine, error
 	return s.machineRepo.GetByRole(role)
 }
 
+// SetMachinesStatus 批量更新机器状态
+func (s *MachineService) SetMachinesStatus(ids []uuid.UUID, status string) error {
+	return s.machineRepo.UpdateStatusByIDs(ids, status)
+}
+
+// BindMachinesToCluster 将机器关联到平台创建的集群
+func (s *MachineService) BindMachinesToCluster(ids []uuid.UUID, clusterID uuid.UUID) error {
+	return s.machineRepo.BindCluster(ids, clusterID)
+}
+
+// GetMachinesByCluster 获取集群使用的机器
+func (s *MachineService) GetMachinesByCluster(clusterID uuid.UUID) ([]*model.Machine, error) {
+	return s.machineRepo.GetByClusterID(clusterID)
+}
+
+// FindMachinesByAddresses 根据名称或地址查找机器
+func (s *MachineService) FindMachinesByAddresses(names, addresses []string) ([]*model.Machine, error) {
+	return s.machineRepo.GetByAddresses(names, addresses)
+}
+
+// ReleaseMachines 将机器放回可用池
+func (s *MachineService) ReleaseMachines(ids []uuid.UUID) (int64, error) {
+	return s.machineRepo.ReleaseByIDs(ids)
+}
+
 // ValidateClusterNodes 验证集群节点配置
 func (s *MachineService) ValidateClusterNodes(machineIDs []uuid.UUID) error {
 	machines, err := s.machineRepo.GetByIDs(machineIDs)
@@ -112,10 +154,337 @@ func (s *MachineService) ValidateClusterNodes(machineIDs []uuid.UUID) error {
 
 	// 检查机器状态
 	for _, machine := range machines {
-		if machine.Status != "available" {
+		if machine.Status != model.MachineStatusAvailable {
 			return fmt.Errorf("machine %s is not available (status: %s)", machine.Name, machine.Status)
 		}
 	}
 
 	return nil
 }
+
+// ApplySecrets 校验并加密凭据后写入机器，未提供的字段保留原值
+func (s *MachineService) ApplySecrets(machine *model.Machine, secrets MachineSecrets) error {
+	if secrets.PrivateKey != "" {
+		if _, err := ParseSSHPrivateKey(secrets.PrivateKey, secrets.Passphrase); err != nil {
+			return err
+		}
+	}
+
+	// 历史数据为明文，先整体加密
+	if err := s.encryptPlaintextSecrets(machine); err != nil {
+		return err
+	}
+
+	if secrets.Password != "" {
+		encrypted, err := s.encryptSecret(secrets.Password)
+		if err != nil {
+			return err
+		}
+		machine.Password = encrypted
+	}
+
+	if secrets.PrivateKey != "" {
+		encryptedKey, err := s.encryptSecret(secrets.PrivateKey)
+		if err != nil {
+			return err
+		}
+		// 更换私钥时口令随之替换，避免残留旧口令
+		encryptedPassphrase, err := s.encryptSecret(secrets.Passphrase)
+		if err != nil {
+			return err
+		}
+		machine.PrivateKey = encryptedKey
+		machine.Passphrase = encryptedPassphrase
+	}
+
+	if machine.AuthType == "" {
+		if machine.PrivateKey != "" {
+			machine.AuthType = model.MachineAuthTypePrivateKey
+		} else {
+			machine.AuthType = model.MachineAuthTypePassword
+		}
+	}
+
+	return nil
+}
+
+// EncryptPlaintextSecrets 加密历史遗留的明文机器凭据，服务启动时执行，返回加密的机器数量
+func (s *MachineService) EncryptPlaintextSecrets() (int, error) {
+	machines, err := s.machineRepo.ListPlaintextSecrets()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list machines with plaintext secrets: %w", err)
+	}
+
+	encrypted := 0
+	for _, machine := range machines {
+		if err := s.encryptPlaintextSecrets(machine); err != nil {
+			return encrypted, fmt.Errorf("failed to encrypt secrets of machine %s: %w", machine.Name, err)
+		}
+		// 并发编辑已加密的机器不会被覆盖
+		updated, err := s.machineRepo.UpdateSecretsIfPlaintext(machine)
+		if err != nil {
+			return encrypted, fmt.Errorf("failed to save secrets of machine %s: %w", machine.Name, err)
+		}
+		if updated {
+			encrypted++
+		}
+	}
+	return encrypted, nil
+}
+
+// encryptPlaintextSecrets 加密机器上的明文凭据并标记为已加密
+func (s *MachineService) encryptPlaintextSecrets(machine *model.Machine) error {
+	if machine.SecretsEncrypted {
+		return nil
+	}
+	for _, field := range []*string{&machine.Password, &machine.PrivateKey, &machine.Passphrase} {
+		encrypted, err := s.encryptSecret(*field)
+		if err != nil {
+			return err
+		}
+		*field = encrypted
+	}
+	machine.SecretsEncrypted = true
+	return nil
+}
+
+// ValidateMachineAuth 检查机器是否具备与认证方式匹配的凭据
+func (s *MachineService) ValidateMachineAuth(machine *model.Machine) error {
+	if machine.CredentialID != nil {
+		return nil
+	}
+	switch machine.AuthType {
+	case model.MachineAuthTypePrivateKey:
+		if machine.PrivateKey == "" {
+			return fmt.Errorf("machine %s uses private_key auth but has no private key", machine.Name)
+		}
+	default:
+		if machine.Password == "" {
+			return fmt.Errorf("machine %s uses password auth but has no password", machine.Name)
+		}
+	}
+	return nil
+}
+
+// ResolveSSHAuth 解密机器的SSH凭据，共享凭据优先
+func (s *MachineService) ResolveSSHAuth(machine *model.Machine) (*SSHAuth, error) {
+	if machine.CredentialID != nil {
+		credential, err := s.credentialRepo.GetByID(*machine.CredentialID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get credential for machine %s: %w", machine.Name, err)
+		}
+		auth, err := s.decryptCredential(credential)
+		if err != nil {
+			return nil, err
+		}
+		auth.Port = machine.SSHPort
+		return auth, nil
+	}
+
+	auth := &SSHAuth{
+		Username: machine.User,
+		Port:     machine.SSHPort,
+	}
+
+	fields := []struct {
+		src string
+		dst *string
+	}{
+		{machine.Password, &auth.Password},
+		{machine.PrivateKey, &auth.PrivateKey},
+		{machine.Passphrase, &auth.Passphrase},
+	}
+	for _, f := range fields {
+		if !machine.SecretsEncrypted {
+			*f.dst = f.src
+			continue
+		}
+		plaintext, err := s.decryptSecret(f.src)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt credential for machine %s: %w", machine.Name, err)
+		}
+		*f.dst = plaintext
+	}
+
+	// 指定私钥认证时不再回退到密码
+	if machine.AuthType == model.MachineAuthTypePrivateKey {
+		auth.Password = ""
+	}
+
+	return auth, nil
+}
+
+// ResolveCredentialAuth 解密共享凭据，供机器以外的SSH连接（如堡垒机）使用
+func (s *MachineService) ResolveCredentialAuth(credentialID uuid.UUID) (*SSHAuth, error) {
+	credential, err := s.credentialRepo.GetByID(credentialID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credential %s: %w", credentialID, err)
+	}
+	return s.decryptCredential(credential)
+}
+
+// ConnectMachine 使用机器凭据建立SSH连接
+func (s *MachineService) ConnectMachine(machine *model.Machine) (*SSHClient, error) {
+	auth, err := s.ResolveSSHAuth(machine)
+	if err != nil {
+		return nil, err
+	}
+	return s.sshService.ConnectWithAuth(machine.IPAddress, auth)
+}
+
+// CreateCredential 创建共享凭据
+func (s *MachineService) CreateCredential(credential *model.MachineCredential, secrets MachineSecrets) error {
+	exists, err := s.credentialRepo.ExistsByName(credential.Name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("credential %s already exists", credential.Name)
+	}
+
+	if err := s.applyCredentialSecrets(credential, secrets); err != nil {
+		return err
+	}
+	return s.credentialRepo.Create(credential)
+}
+
+// GetCredential 获取共享凭据
+func (s *MachineService) GetCredential(id uuid.UUID) (*model.MachineCredential, error) {
+	return s.credentialRepo.GetByID(id)
+}
+
+// ListCredentials 获取共享凭据列表
+func (s *MachineService) ListCredentials() ([]*model.MachineCredential, error) {
+	return s.credentialRepo.List()
+}
+
+// UpdateCredential 更新共享凭据，未提供的敏感字段保留原值
+func (s *MachineService) UpdateCredential(credential *model.MachineCredential, secrets MachineSecrets) error {
+	if err := s.applyCredentialSecrets(credential, secrets); err != nil {
+		return err
+	}
+	return s.credentialRepo.Update(credential)
+}
+
+// DeleteCredential 删除共享凭据，仍被机器引用时拒绝删除
+func (s *MachineService) DeleteCredential(id uuid.UUID) error {
+	count, err := s.machineRepo.CountByCredential(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("credential is still assigned to %d machines", count)
+	}
+	return s.credentialRepo.Delete(id)
+}
+
+// AssignCredential 批量为机器分配共享凭据
+func (s *MachineService) AssignCredential(credentialID uuid.UUID, machineIDs []uuid.UUID) (int64, error) {
+	if _, err := s.credentialRepo.GetByID(credentialID); err != nil {
+		return 0, fmt.Errorf("credential not found: %w", err)
+	}
+	if err := s.checkMachinesExist(machineIDs); err != nil {
+		return 0, err
+	}
+	return s.machineRepo.AssignCredential(machineIDs, &credentialID)
+}
+
+// UnassignCredential 批量解除机器的共享凭据，恢复使用机器自身凭据
+func (s *MachineService) UnassignCredential(credentialID uuid.UUID, machineIDs []uuid.UUID) (int64, error) {
+	if err := s.checkMachinesExist(machineIDs); err != nil {
+		return 0, err
+	}
+	return s.machineRepo.ClearCredential(machineIDs, credentialID)
+}
+
+// checkMachinesExist 检查机器是否全部存在
+func (s *MachineService) checkMachinesExist(machineIDs []uuid.UUID) error {
+	machines, err := s.machineRepo.GetByIDs(machineIDs)
+	if err != nil {
+		return err
+	}
+	if len(machines) != len(machineIDs) {
+		return fmt.Errorf("some machines not found: expected %d, found %d", len(machineIDs), len(machines))
+	}
+	return nil
+}
+
+// applyCredentialSecrets 校验并加密共享凭据的敏感字段
+func (s *MachineService) applyCredentialSecrets(credential *model.MachineCredential, secrets MachineSecrets) error {
+	if secrets.PrivateKey != "" {
+		if _, err := ParseSSHPrivateKey(secrets.PrivateKey, secrets.Passphrase); err != nil {
+			return err
+		}
+		encryptedKey, err := s.encryptSecret(secrets.PrivateKey)
+		if err != nil {
+			return err
+		}
+		encryptedPassphrase, err := s.encryptSecret(secrets.Passphrase)
+		if err != nil {
+			return err
+		}
+		credential.PrivateKey = encryptedKey
+		credential.Passphrase = encryptedPassphrase
+	}
+
+	if secrets.Password != "" {
+		encrypted, err := s.encryptSecret(secrets.Password)
+		if err != nil {
+			return err
+		}
+		credential.Password = encrypted
+	}
+
+	switch credential.AuthType {
+	case model.MachineAuthTypePrivateKey:
+		if credential.PrivateKey == "" {
+			return errors.New("private key is required for private_key auth")
+		}
+	case model.MachineAuthTypePassword, "":
+		credential.AuthType = model.MachineAuthTypePassword
+		if credential.Password == "" {
+			return errors.New("password is required for password auth")
+		}
+	default:
+		return fmt.Errorf("unsupported auth type: %s", credential.AuthType)
+	}
+
+	return nil
+}
+
+// decryptCredential 解密共享凭据
+func (s *MachineService) decryptCredential(credential *model.MachineCredential) (*SSHAuth, error) {
+	auth := &SSHAuth{Username: credential.Username}
+
+	var err error
+	if credential.AuthType == model.MachineAuthTypePrivateKey {
+		if auth.PrivateKey, err = s.decryptSecret(credential.PrivateKey); err != nil {
+			return nil, fmt.Errorf("failed to decrypt private key of credential %s: %w", credential.Name, err)
+		}
+		if auth.Passphrase, err = s.decryptSecret(credential.Passphrase); err != nil {
+			return nil, fmt.Errorf("failed to decrypt passphrase of credential %s: %w", credential.Name, err)
+		}
+		return auth, nil
+	}
+
+	if auth.Password, err = s.decryptSecret(credential.Password); err != nil {
+		return nil, fmt.Errorf("failed to decrypt password of credential %s: %w", credential.Name, err)
+	}
+	return auth, nil
+}
+
+// encryptSecret 加密敏感字段，空值保持为空
+func (s *MachineService) encryptSecret(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	return s.encryptionService.Encrypt(plaintext)
+}
+
+// decryptSecret 解密敏感字段，空值保持为空
+func (s *MachineService) decryptSecret(ciphertext string) (string, error) {
+	if ciphertext == "" {
+		return "", nil
+	}
+	return s.encryptionService.Decrypt(ciphertext)
+}
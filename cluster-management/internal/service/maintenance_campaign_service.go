@@ -0,0 +1,686 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/constants"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"github.com/taichu-system/cluster-management/internal/repository"
+	"golang.org/x/crypto/ssh"
+	"gorm.io/gorm"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	// ErrCampaignNotFound 维护活动不存在
+	ErrCampaignNotFound = errors.New("maintenance campaign not found")
+	// ErrCampaignInProgress 集群已有未结束的维护活动
+	ErrCampaignInProgress = errors.New("cluster already has an unfinished maintenance campaign")
+	// ErrCampaignStateConflict 活动当前状态不允许该操作
+	ErrCampaignStateConflict = errors.New("operation not allowed in current campaign status")
+	// ErrInvalidCampaign 维护活动参数无效
+	ErrInvalidCampaign = errors.New("invalid maintenance campaign")
+)
+
+const (
+	defaultMaintenanceReadyTimeout = 15 * time.Minute
+	nodeReadyPollInterval          = 10 * time.Second
+)
+
+// 节点维护步骤
+const (
+	maintenanceStepDrain     = "drain"
+	maintenanceStepCommands  = "commands"
+	maintenanceStepWaitReady = "wait_ready"
+	maintenanceStepUncordon  = "uncordon"
+)
+
+// MaintenanceCampaignRequest 创建维护活动的参数，Selector 与 NodeNames 二选一
+type MaintenanceCampaignRequest struct {
+	ClusterID           uuid.UUID
+	Name                string
+	Selector            string
+	NodeNames           []string
+	MaxUnavailable      int
+	Commands            []string
+	DrainOptions        DrainOptions
+	ReadyTimeoutSeconds int
+}
+
+// MaintenanceCampaignService 节点滚动维护服务：逐批排空节点、执行SSH命令并等待节点恢复
+type MaintenanceCampaignService struct {
+	clusterRepo       *repository.ClusterRepository
+	campaignRepo      *repository.MaintenanceCampaignRepository
+	machineService    *MachineService
+	clusterManager    *ClusterManager
+	encryptionService *EncryptionService
+	auditService      *AuditService
+}
+
+// NewMaintenanceCampaignService 创建节点滚动维护服务
+func NewMaintenanceCampaignService(
+	clusterRepo *repository.ClusterRepository,
+	campaignRepo *repository.MaintenanceCampaignRepository,
+	machineService *MachineService,
+	clusterManager *ClusterManager,
+	encryptionService *EncryptionService,
+	auditService *AuditService,
+) *MaintenanceCampaignService {
+	return &MaintenanceCampaignService{
+		clusterRepo:       clusterRepo,
+		campaignRepo:      campaignRepo,
+		machineService:    machineService,
+		clusterManager:    clusterManager,
+		encryptionService: encryptionService,
+		auditService:      auditService,
+	}
+}
+
+// RecoverInterrupted 服务启动时将中断的活动标记为暂停，由操作员确认节点状态后恢复
+func (s *MaintenanceCampaignService) RecoverInterrupted() (int64, error) {
+	return s.campaignRepo.MarkInterrupted()
+}
+
+// Create 解析目标节点并创建维护活动，随后异步执行
+func (s *MaintenanceCampaignService) Create(req MaintenanceCampaignRequest, operator string) (*model.MaintenanceCampaign, error) {
+	if req.Selector == "" && len(req.NodeNames) == 0 {
+		return nil, fmt.Errorf("%w: selector or node_names is required", ErrInvalidCampaign)
+	}
+	if len(req.Commands) == 0 {
+		return nil, fmt.Errorf("%w: at least one command is required", ErrInvalidCampaign)
+	}
+	if req.MaxUnavailable <= 0 {
+		req.MaxUnavailable = 1
+	}
+	if req.ReadyTimeoutSeconds <= 0 {
+		req.ReadyTimeoutSeconds = int(defaultMaintenanceReadyTimeout / time.Second)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	clientset, err := s.clientForCluster(ctx, req.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := s.campaignRepo.ExistsActive(req.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+	if active {
+		return nil, ErrCampaignInProgress
+	}
+
+	nodes, err := resolveMaintenanceNodes(ctx, clientset, req.Selector, req.NodeNames)
+	if err != nil {
+		return nil, err
+	}
+	machines, err := s.matchMachines(req.ClusterID, nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	optionsMap, _ := toJSONValue(req.DrainOptions).(map[string]interface{})
+	campaign := &model.MaintenanceCampaign{
+		ClusterID:           req.ClusterID,
+		Name:                req.Name,
+		Selector:            req.Selector,
+		MaxUnavailable:      req.MaxUnavailable,
+		Commands:            model.StringSlice(req.Commands),
+		DrainOptions:        optionsMap,
+		ReadyTimeoutSeconds: req.ReadyTimeoutSeconds,
+		Status:              constants.StatusPending,
+		CurrentStep:         "Waiting to start",
+		CreatedBy:           operator,
+	}
+	runs := make([]*model.MaintenanceNodeRun, 0, len(nodes))
+	for i, node := range nodes {
+		campaign.NodeNames = append(campaign.NodeNames, node.Name)
+		runs = append(runs, &model.MaintenanceNodeRun{
+			NodeName:  node.Name,
+			MachineID: machines[node.Name].ID,
+			Sequence:  i + 1,
+			Status:    constants.StatusPending,
+		})
+	}
+	if err := s.campaignRepo.Create(campaign, runs); err != nil {
+		return nil, fmt.Errorf("failed to create maintenance campaign: %w", err)
+	}
+	for _, run := range runs {
+		campaign.Nodes = append(campaign.Nodes, *run)
+	}
+
+	go s.executeCampaign(campaign.ID)
+
+	return campaign, nil
+}
+
+// Get 获取维护活动及各节点执行记录
+func (s *MaintenanceCampaignService) Get(id uuid.UUID) (*model.MaintenanceCampaign, error) {
+	campaign, err := s.campaignRepo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCampaignNotFound
+	}
+	return campaign, err
+}
+
+// List 获取维护活动列表
+func (s *MaintenanceCampaignService) List(clusterID *uuid.UUID) ([]*model.MaintenanceCampaign, error) {
+	return s.campaignRepo.List(clusterID)
+}
+
+// Resume 恢复暂停的活动，未成功的节点从头重新执行
+func (s *MaintenanceCampaignService) Resume(id uuid.UUID) (*model.MaintenanceCampaign, error) {
+	campaign, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.Status != constants.StatusPaused {
+		return nil, fmt.Errorf("%w: campaign is %s", ErrCampaignStateConflict, campaign.Status)
+	}
+
+	if err := s.campaignRepo.ResetUnfinishedNodes(id); err != nil {
+		return nil, err
+	}
+	ok, err := s.campaignRepo.UpdateStatusFrom(id, []string{constants.StatusPaused}, map[string]interface{}{
+		"status":       constants.StatusPending,
+		"current_step": "Waiting to resume",
+		"error_msg":    "",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: campaign is no longer paused", ErrCampaignStateConflict)
+	}
+
+	go s.executeCampaign(id)
+
+	return s.Get(id)
+}
+
+// Cancel 取消活动，正在维护的节点会执行完当前流程，之后不再启动新节点
+func (s *MaintenanceCampaignService) Cancel(id uuid.UUID) (*model.MaintenanceCampaign, error) {
+	campaign, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{
+		"status":       constants.StatusCancelled,
+		"current_step": "Cancelled",
+	}
+	switch campaign.Status {
+	case constants.StatusRunning:
+		fields["current_step"] = "Cancelled, waiting for in-flight nodes"
+	case constants.StatusPending, constants.StatusPaused:
+		now := time.Now()
+		fields["completed_at"] = &now
+	default:
+		return nil, fmt.Errorf("%w: campaign is %s", ErrCampaignStateConflict, campaign.Status)
+	}
+
+	ok, err := s.campaignRepo.UpdateStatusFrom(id, []string{campaign.Status}, fields)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: campaign status changed, retry", ErrCampaignStateConflict)
+	}
+	return s.Get(id)
+}
+
+// executeCampaign 执行维护活动（异步）
+// 同时维护的节点数不超过 MaxUnavailable，任一节点失败后不再启动新节点并暂停活动
+func (s *MaintenanceCampaignService) executeCampaign(id uuid.UUID) {
+	campaign, err := s.campaignRepo.GetByID(id)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	fields := map[string]interface{}{
+		"status":       constants.StatusRunning,
+		"current_step": "Starting",
+	}
+	if campaign.StartedAt == nil {
+		fields["started_at"] = &now
+	}
+	ok, err := s.campaignRepo.UpdateStatusFrom(id, []string{constants.StatusPending}, fields)
+	if err != nil || !ok {
+		return
+	}
+
+	ctx := context.Background()
+	clientset, err := s.clientForCluster(ctx, campaign.ClusterID)
+	if err != nil {
+		s.finishCampaign(campaign, []string{err.Error()})
+		return
+	}
+
+	var opts DrainOptions
+	if data, err := json.Marshal(campaign.DrainOptions); err == nil {
+		json.Unmarshal(data, &opts)
+	}
+
+	machineIDs := make([]uuid.UUID, 0, len(campaign.Nodes))
+	for _, run := range campaign.Nodes {
+		machineIDs = append(machineIDs, run.MachineID)
+	}
+	machines, err := s.machineService.GetMachinesByIDs(machineIDs)
+	if err != nil {
+		s.finishCampaign(campaign, []string{fmt.Sprintf("failed to load machines: %v", err)})
+		return
+	}
+	machineByID := make(map[uuid.UUID]*model.Machine, len(machines))
+	for _, machine := range machines {
+		machineByID[machine.ID] = machine
+	}
+
+	maxUnavailable := campaign.MaxUnavailable
+	if maxUnavailable <= 0 {
+		maxUnavailable = 1
+	}
+	slots := make(chan struct{}, maxUnavailable)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var failures []string
+	for i := range campaign.Nodes {
+		run := campaign.Nodes[i]
+		if run.Status == constants.StatusSuccess {
+			continue
+		}
+
+		// 先占用名额再检查，保证判断时已看到此前完成节点的结果
+		slots <- struct{}{}
+		mu.Lock()
+		stop := len(failures) > 0
+		mu.Unlock()
+		if stop || s.isCancelled(id) {
+			<-slots
+			break
+		}
+
+		s.campaignRepo.UpdateFields(id, map[string]interface{}{
+			"current_step": fmt.Sprintf("Maintaining node %s (%d/%d)", run.NodeName, run.Sequence, len(campaign.Nodes)),
+		})
+
+		wg.Add(1)
+		go func(run model.MaintenanceNodeRun) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			err := s.maintainNode(ctx, clientset, campaign, &run, machineByID[run.MachineID], opts)
+			if err != nil {
+				mu.Lock()
+				failures = append(failures, fmt.Sprintf("%s: %v", run.NodeName, err))
+				mu.Unlock()
+			}
+		}(run)
+	}
+	wg.Wait()
+
+	s.finishCampaign(campaign, failures)
+}
+
+// finishCampaign 根据节点执行结果更新活动状态并记录审计事件
+func (s *MaintenanceCampaignService) finishCampaign(campaign *model.MaintenanceCampaign, failures []string) {
+	id := campaign.ID
+	completedAt := time.Now()
+	auditStatus := constants.StatusSuccess
+	details := map[string]interface{}{
+		"campaign_id": id.String(),
+		"name":        campaign.Name,
+		"nodes":       []string(campaign.NodeNames),
+	}
+
+	switch {
+	case s.isCancelled(id):
+		auditStatus = constants.StatusCancelled
+		s.campaignRepo.UpdateFields(id, map[string]interface{}{
+			"current_step": "Cancelled",
+			"completed_at": &completedAt,
+		})
+	case len(failures) > 0:
+		auditStatus = constants.StatusFailed
+		details["error"] = strings.Join(failures, "; ")
+		s.campaignRepo.UpdateStatusFrom(id, []string{constants.StatusRunning}, map[string]interface{}{
+			"status":       constants.StatusPaused,
+			"current_step": "Paused after node failure, failed nodes remain cordoned",
+			"error_msg":    strings.Join(failures, "; "),
+		})
+	default:
+		s.campaignRepo.UpdateStatusFrom(id, []string{constants.StatusRunning}, map[string]interface{}{
+			"status":       constants.StatusSuccess,
+			"current_step": "All nodes maintained",
+			"completed_at": &completedAt,
+		})
+	}
+
+	if s.auditService != nil {
+		s.auditService.CreateAuditEvent(
+			campaign.ClusterID,
+			constants.EventTypeUpdate,
+			"node_maintenance_campaign",
+			constants.ResourceTypeCluster,
+			id.String(),
+			campaign.CreatedBy,
+			"",
+			"",
+			nil,
+			nil,
+			details,
+			auditStatus,
+		)
+	}
+}
+
+// maintainNode 维护单个节点并记录执行结果
+func (s *MaintenanceCampaignService) maintainNode(ctx context.Context, clientset kubernetes.Interface, campaign *model.MaintenanceCampaign, run *model.MaintenanceNodeRun, machine *model.Machine, opts DrainOptions) error {
+	logf := func(line string, isError bool) {
+		appendTimestampedLog(func(log string) { s.campaignRepo.AppendNodeLogs(run.ID, log) }, line, isError)
+	}
+
+	now := time.Now()
+	s.campaignRepo.UpdateNodeRun(run.ID, map[string]interface{}{
+		"status":       constants.StatusRunning,
+		"started_at":   &now,
+		"completed_at": nil,
+	})
+
+	err := s.runNodeMaintenance(ctx, clientset, campaign, run, machine, opts, logf)
+
+	completedAt := time.Now()
+	if err != nil {
+		logf(err.Error(), true)
+		s.campaignRepo.UpdateNodeRun(run.ID, map[string]interface{}{
+			"status":       constants.StatusFailed,
+			"error_msg":    err.Error(),
+			"completed_at": &completedAt,
+		})
+		return err
+	}
+
+	logf("maintenance completed", false)
+	s.campaignRepo.UpdateNodeRun(run.ID, map[string]interface{}{
+		"status":       constants.StatusSuccess,
+		"completed_at": &completedAt,
+	})
+	return nil
+}
+
+// runNodeMaintenance 依次执行排空、SSH命令、等待就绪与解除封锁
+func (s *MaintenanceCampaignService) runNodeMaintenance(ctx context.Context, clientset kubernetes.Interface, campaign *model.MaintenanceCampaign, run *model.MaintenanceNodeRun, machine *model.Machine, opts DrainOptions, logf func(string, bool)) error {
+	if machine == nil {
+		return fmt.Errorf("machine for node %s no longer exists", run.NodeName)
+	}
+	setStep := func(step string) {
+		s.campaignRepo.UpdateNodeRun(run.ID, map[string]interface{}{"step": step})
+	}
+
+	node, err := clientset.CoreV1().Nodes().Get(ctx, run.NodeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get node: %w", err)
+	}
+	// 仅首次执行时记录原始调度状态，恢复执行时节点已被本活动封锁
+	if run.StartedAt == nil {
+		run.WasUnschedulable = node.Spec.Unschedulable
+		s.campaignRepo.UpdateNodeRun(run.ID, map[string]interface{}{"was_unschedulable": run.WasUnschedulable})
+	}
+	bootID := node.Status.NodeInfo.BootID
+
+	setStep(maintenanceStepDrain)
+	result, err := drainNode(ctx, clientset, run.NodeName, opts, logf)
+	if err != nil {
+		return fmt.Errorf("drain failed: %w", err)
+	}
+	logf(fmt.Sprintf("node drained: %d pods evicted, %d skipped", len(result.Evicted), len(result.Skipped)), false)
+
+	setStep(maintenanceStepCommands)
+	rebooted, err := s.runMaintenanceCommands(machine, campaign.Commands, logf)
+	if err != nil {
+		return err
+	}
+
+	setStep(maintenanceStepWaitReady)
+	previousBootID := ""
+	if rebooted {
+		previousBootID = bootID
+	}
+	timeout := time.Duration(campaign.ReadyTimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultMaintenanceReadyTimeout
+	}
+	if err := s.waitForNodeReady(ctx, clientset, run.NodeName, previousBootID, timeout, logf); err != nil {
+		return err
+	}
+
+	setStep(maintenanceStepUncordon)
+	if run.WasUnschedulable {
+		logf("node was cordoned before maintenance, leaving it cordoned", false)
+		return nil
+	}
+	if err := setNodeUnschedulable(ctx, clientset, run.NodeName, false); err != nil {
+		return fmt.Errorf("failed to uncordon node: %w", err)
+	}
+	logf(fmt.Sprintf("node %s uncordoned", run.NodeName), false)
+	return nil
+}
+
+// runMaintenanceCommands 通过SSH依次执行维护命令
+// 最后一条命令执行中连接被远端断开视为节点正在重启
+func (s *MaintenanceCampaignService) runMaintenanceCommands(machine *model.Machine, commands []string, logf func(string, bool)) (rebooted bool, err error) {
+	auth, err := s.machineService.ResolveSSHAuth(machine)
+	if err != nil {
+		return false, err
+	}
+	client, err := s.machineService.ConnectMachine(machine)
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to machine %s: %w", machine.Name, err)
+	}
+	defer client.Close()
+
+	for i, command := range commands {
+		logf(fmt.Sprintf("running command %d/%d: %s", i+1, len(commands), command), false)
+		cmd := command
+		if auth.Username != "root" {
+			cmd = "sudo -n sh -c " + shellQuote(cmd)
+		}
+
+		out, err := client.ExecuteCommand(cmd)
+		if err != nil {
+			if !isSSHConnectionLost(err) {
+				return false, fmt.Errorf("command %d failed: %w", i+1, err)
+			}
+			if i < len(commands)-1 {
+				return true, fmt.Errorf("connection lost during command %d, %d remaining commands were not run", i+1, len(commands)-i-1)
+			}
+			logf("connection closed by remote host, assuming node is rebooting", false)
+			return true, nil
+		}
+		if out = strings.TrimSpace(out); out != "" {
+			logf(out, false)
+		}
+	}
+	return false, nil
+}
+
+// waitForNodeReady 等待节点恢复 Ready，previousBootID 非空时还要求节点已完成重启
+func (s *MaintenanceCampaignService) waitForNodeReady(ctx context.Context, clientset kubernetes.Interface, nodeName, previousBootID string, timeout time.Duration, logf func(string, bool)) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	logf(fmt.Sprintf("waiting up to %s for node to become Ready", timeout), false)
+	for {
+		node, err := clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if err == nil {
+			rebootPending := previousBootID != "" && node.Status.NodeInfo.BootID == previousBootID
+			if !rebootPending && s.clusterManager.isNodeReady(*node) {
+				logf("node is Ready", false)
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if previousBootID != "" {
+				return fmt.Errorf("node did not come back Ready after reboot within %s", timeout)
+			}
+			return fmt.Errorf("node did not become Ready within %s", timeout)
+		case <-time.After(nodeReadyPollInterval):
+		}
+	}
+}
+
+// matchMachines 将节点按名称或地址对应到已登记的机器，优先选择绑定到该集群的机器
+func (s *MaintenanceCampaignService) matchMachines(clusterID uuid.UUID, nodes []corev1.Node) (map[string]*model.Machine, error) {
+	names := make([]string, 0, len(nodes))
+	var addresses []string
+	for _, node := range nodes {
+		names = append(names, node.Name)
+		for _, address := range node.Status.Addresses {
+			addresses = append(addresses, address.Address)
+		}
+	}
+
+	candidates, err := s.machineService.FindMachinesByAddresses(names, addresses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up machines: %w", err)
+	}
+
+	result := make(map[string]*model.Machine, len(nodes))
+	var missing, invalid []string
+	for _, node := range nodes {
+		var match *model.Machine
+		for _, machine := range candidates {
+			if !machineMatchesNode(machine, node) {
+				continue
+			}
+			if match == nil || (machine.ClusterID != nil && *machine.ClusterID == clusterID) {
+				match = machine
+			}
+		}
+		if match == nil {
+			missing = append(missing, node.Name)
+			continue
+		}
+		if err := s.machineService.ValidateMachineAuth(match); err != nil {
+			invalid = append(invalid, err.Error())
+			continue
+		}
+		result[node.Name] = match
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: no registered machine for nodes %s", ErrInvalidCampaign, strings.Join(missing, ", "))
+	}
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCampaign, strings.Join(invalid, "; "))
+	}
+	return result, nil
+}
+
+// isCancelled 检查活动是否已被取消
+func (s *MaintenanceCampaignService) isCancelled(id uuid.UUID) bool {
+	status, err := s.campaignRepo.GetStatus(id)
+	return err == nil && status == constants.StatusCancelled
+}
+
+// clientForCluster 获取集群客户端
+func (s *MaintenanceCampaignService) clientForCluster(ctx context.Context, clusterID uuid.UUID) (*kubernetes.Clientset, error) {
+	cluster, err := s.clusterRepo.GetByID(clusterID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClusterNotFound
+		}
+		return nil, err
+	}
+
+	kubeconfig, err := s.encryptionService.Decrypt(cluster.KubeconfigEncrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt kubeconfig: %w", err)
+	}
+
+	return s.clusterManager.GetClientForCluster(ctx, cluster.ID, kubeconfig)
+}
+
+// resolveMaintenanceNodes 按节点名或标签选择器解析目标节点
+// 指定节点名时保持给定顺序，使用选择器时按名称排序
+func resolveMaintenanceNodes(ctx context.Context, clientset kubernetes.Interface, selector string, nodeNames []string) ([]corev1.Node, error) {
+	if len(nodeNames) > 0 {
+		seen := make(map[string]bool, len(nodeNames))
+		nodes := make([]corev1.Node, 0, len(nodeNames))
+		for _, name := range nodeNames {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			node, err := clientset.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				if apierrors.IsNotFound(err) {
+					return nil, fmt.Errorf("%w: %s", ErrNodeNotFound, name)
+				}
+				return nil, fmt.Errorf("failed to get node %s: %w", name, err)
+			}
+			nodes = append(nodes, *node)
+		}
+		return nodes, nil
+	}
+
+	parsed, err := labels.Parse(selector)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid selector: %v", ErrInvalidCampaign, err)
+	}
+	list, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: parsed.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	if len(list.Items) == 0 {
+		return nil, fmt.Errorf("%w: no nodes match selector %q", ErrInvalidCampaign, selector)
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].Name < list.Items[j].Name
+	})
+	return list.Items, nil
+}
+
+// machineMatchesNode 判断机器是否对应该节点
+func machineMatchesNode(machine *model.Machine, node corev1.Node) bool {
+	if machine.Name == node.Name {
+		return true
+	}
+	for _, address := range node.Status.Addresses {
+		if address.Address == "" {
+			continue
+		}
+		if address.Address == machine.IPAddress || address.Address == machine.InternalAddress {
+			return true
+		}
+	}
+	return false
+}
+
+// isSSHConnectionLost 判断命令是否因远端关闭连接或会话进程被信号终止而中断，通常由重启引起
+func isSSHConnectionLost(err error) bool {
+	var exitMissing *ssh.ExitMissingError
+	if errors.As(err, &exitMissing) || errors.Is(err, io.EOF) {
+		return true
+	}
+	var exitErr *ssh.ExitError
+	return errors.As(err, &exitErr) && exitErr.Signal() != ""
+}
@@ -0,0 +1,501 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"github.com/taichu-system/cluster-management/internal/repository"
+	"gorm.io/gorm"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// ManagerServiceAccountName 管理端在集群内使用的 ServiceAccount、ClusterRole 与 ClusterRoleBinding 名称
+const ManagerServiceAccountName = "taichu-manager"
+
+const (
+	defaultManagerNamespace    = "taichu-system"
+	defaultManagerTokenTTL     = 7 * 24 * time.Hour
+	defaultManagerRotateBefore = 48 * time.Hour
+
+	managerTokenRoleName          = ManagerServiceAccountName + "-token"
+	managerUserKubeconfigRoleName = ManagerServiceAccountName + "-user-kubeconfig"
+)
+
+// ErrManagerAccountNotFound 集群未使用最小权限 ServiceAccount
+var ErrManagerAccountNotFound = errors.New("cluster does not use a manager service account")
+
+// ManagerAccountService 最小权限导入：使用导入凭据创建 taichu-manager ServiceAccount，只保存其令牌并定期轮换
+// 令牌通过 TokenRequest 签发，ServiceAccount 只被授权为自身签发令牌，轮换时不再需要导入凭据
+type ManagerAccountService struct {
+	clusterRepo         *repository.ClusterRepository
+	accountRepo         *repository.ClusterServiceAccountRepository
+	credentialRepo      *repository.ClusterCredentialRepository
+	encryptionService   *EncryptionService
+	clusterManager      *ClusterManager
+	namespace           string
+	tokenTTL            time.Duration
+	rotateBefore        time.Duration
+	grantImpersonation  bool
+	grantUserKubeconfig bool
+	userKubeconfigNS    string
+	listeners           credentialListeners
+}
+
+// NewManagerAccountService 创建最小权限 ServiceAccount 服务
+// grantImpersonation 与 grantUserKubeconfig 分别授予 API 代理与用户 kubeconfig 签发所需的权限
+func NewManagerAccountService(
+	clusterRepo *repository.ClusterRepository,
+	accountRepo *repository.ClusterServiceAccountRepository,
+	credentialRepo *repository.ClusterCredentialRepository,
+	encryptionService *EncryptionService,
+	clusterManager *ClusterManager,
+	namespace string,
+	tokenTTL, rotateBefore time.Duration,
+	grantImpersonation, grantUserKubeconfig bool,
+	userKubeconfigNamespace string,
+) *ManagerAccountService {
+	if namespace == "" {
+		namespace = defaultManagerNamespace
+	}
+	if tokenTTL <= 0 {
+		tokenTTL = defaultManagerTokenTTL
+	}
+	if rotateBefore <= 0 || rotateBefore >= tokenTTL {
+		rotateBefore = tokenTTL / 3
+		if rotateBefore > defaultManagerRotateBefore {
+			rotateBefore = defaultManagerRotateBefore
+		}
+	}
+	if userKubeconfigNamespace == "" {
+		userKubeconfigNamespace = defaultKubeconfigSANamespace
+	}
+	return &ManagerAccountService{
+		clusterRepo:         clusterRepo,
+		accountRepo:         accountRepo,
+		credentialRepo:      credentialRepo,
+		encryptionService:   encryptionService,
+		clusterManager:      clusterManager,
+		namespace:           namespace,
+		tokenTTL:            tokenTTL,
+		rotateBefore:        rotateBefore,
+		grantImpersonation:  grantImpersonation,
+		grantUserKubeconfig: grantUserKubeconfig,
+		userKubeconfigNS:    userKubeconfigNamespace,
+	}
+}
+
+// Bootstrap 使用导入凭据创建 ServiceAccount 与 RBAC 并签发令牌，返回只包含该令牌的 kubeconfig
+// 返回的 ServiceAccount 记录尚未保存，调用方保存 kubeconfig 后通过 SaveAccount 写入
+func (s *ManagerAccountService) Bootstrap(ctx context.Context, clusterID uuid.UUID, importKubeconfig string) (string, *model.ClusterServiceAccount, error) {
+	// 导入凭据只使用一次，不放入客户端池
+	clientset, err := s.clusterManager.CandidateClientForCluster(clusterID, importKubeconfig)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	if err := ensureNamespace(ctx, clientset, s.namespace); err != nil {
+		return "", nil, err
+	}
+	if err := s.applyServiceAccount(ctx, clientset); err != nil {
+		return "", nil, err
+	}
+	if err := s.applyClusterRBAC(ctx, clientset); err != nil {
+		return "", nil, err
+	}
+	// 仅允许为自身签发令牌，用于轮换
+	if err := applyRole(ctx, clientset, s.namespace, managerTokenRoleName, []rbacv1.PolicyRule{{
+		APIGroups:     []string{""},
+		Resources:     []string{"serviceaccounts/token"},
+		Verbs:         []string{"create"},
+		ResourceNames: []string{ManagerServiceAccountName},
+	}}, s.subject()); err != nil {
+		return "", nil, err
+	}
+	// 用户 ServiceAccount 名称随用户生成，无法用 resourceNames 限定可签发令牌的对象，
+	// 配合 ClusterRole 中的 RoleBinding 创建与 admin 绑定权限即可获得任意命名空间的 admin，见 managerClusterRoleRules
+	if s.grantUserKubeconfig {
+		if err := ensureNamespace(ctx, clientset, s.userKubeconfigNS); err != nil {
+			return "", nil, err
+		}
+		if err := applyRole(ctx, clientset, s.userKubeconfigNS, managerUserKubeconfigRoleName, []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"serviceaccounts"}, Verbs: []string{"create", "delete"}},
+			{APIGroups: []string{""}, Resources: []string{"serviceaccounts/token"}, Verbs: []string{"create"}},
+		}, s.subject()); err != nil {
+			return "", nil, err
+		}
+	}
+
+	token, expiresAt, err := s.requestToken(ctx, clientset)
+	if err != nil {
+		return "", nil, err
+	}
+	kubeconfig, err := serviceAccountKubeconfig(importKubeconfig, ManagerServiceAccountName, token)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return kubeconfig, &model.ClusterServiceAccount{
+		ClusterID:      clusterID,
+		Namespace:      s.namespace,
+		Name:           ManagerServiceAccountName,
+		ClusterRole:    ManagerServiceAccountName,
+		TokenExpiresAt: expiresAt,
+		LastRotatedAt:  time.Now(),
+	}, nil
+}
+
+// SaveAccount 记录 ServiceAccount 及其令牌过期时间，在令牌所在的 kubeconfig 保存成功后调用
+func (s *ManagerAccountService) SaveAccount(account *model.ClusterServiceAccount) error {
+	if err := s.accountRepo.Save(account); err != nil {
+		return fmt.Errorf("failed to save manager service account: %w", err)
+	}
+	return nil
+}
+
+// RecordRestoredToken 凭据回滚到旧令牌后更新令牌过期时间，使轮换按旧令牌的有效期进行
+func (s *ManagerAccountService) RecordRestoredToken(clusterID uuid.UUID, expiresAt time.Time) error {
+	return s.accountRepo.UpdateFields(clusterID, map[string]interface{}{
+		"token_expires_at":        expiresAt,
+		"last_rotation_error":     "",
+		"last_rotation_failed_at": nil,
+	})
+}
+
+// TokenPermissionCheck 轮换令牌所需的权限，最小权限导入时作为必需项检查
+func (s *ManagerAccountService) TokenPermissionCheck() permissionCheck {
+	return permissionCheck{
+		Feature:     PermissionFeatureSync,
+		Required:    true,
+		Verb:        "create",
+		Resource:    "serviceaccounts",
+		Subresource: "token",
+		Name:        ManagerServiceAccountName,
+		Namespace:   s.namespace,
+	}
+}
+
+// Get 获取集群的管理 ServiceAccount
+func (s *ManagerAccountService) Get(clusterID uuid.UUID) (*model.ClusterServiceAccount, error) {
+	account, err := s.accountRepo.GetByClusterID(clusterID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrManagerAccountNotFound
+		}
+		return nil, err
+	}
+	return account, nil
+}
+
+// AddCredentialListener 注册令牌轮换后的回调
+func (s *ManagerAccountService) AddCredentialListener(listener CredentialListener) {
+	s.listeners.add(listener)
+}
+
+// Rotate 使用当前令牌为 ServiceAccount 签发新令牌并替换保存的 kubeconfig
+// 旧令牌在过期前仍然有效；凭据在轮换期间被更换时返回 repository.ErrClusterCredentialChanged，不覆盖新凭据
+func (s *ManagerAccountService) Rotate(ctx context.Context, clusterID uuid.UUID) (*model.ClusterServiceAccount, error) {
+	account, err := s.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt, err := s.rotate(ctx, account)
+	if errors.Is(err, repository.ErrClusterCredentialChanged) {
+		return nil, err
+	}
+	if err != nil {
+		now := time.Now()
+		s.accountRepo.UpdateFields(clusterID, map[string]interface{}{
+			"last_rotation_error":     err.Error(),
+			"last_rotation_failed_at": now,
+		})
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.accountRepo.UpdateFields(clusterID, map[string]interface{}{
+		"token_expires_at":        expiresAt,
+		"last_rotated_at":         now,
+		"last_rotation_error":     "",
+		"last_rotation_failed_at": nil,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to update manager service account: %w", err)
+	}
+	account.TokenExpiresAt = expiresAt
+	account.LastRotatedAt = now
+	account.LastRotationError = ""
+	account.LastRotationFailedAt = nil
+	return account, nil
+}
+
+// RotateDue 轮换即将过期的令牌，返回成功轮换的数量
+func (s *ManagerAccountService) RotateDue(ctx context.Context) (int, error) {
+	accounts, err := s.accountRepo.ListDue(time.Now().Add(s.rotateBefore))
+	if err != nil {
+		return 0, err
+	}
+
+	rotated := 0
+	for _, account := range accounts {
+		if _, err := s.Rotate(ctx, account.ClusterID); err != nil {
+			// 凭据已被更换，新凭据的令牌由更换流程记录，本轮跳过
+			if errors.Is(err, repository.ErrClusterCredentialChanged) {
+				log.Printf("[MANAGER-SA] Skipped token rotation for cluster %s: credentials changed during rotation", account.ClusterID)
+				continue
+			}
+			log.Printf("[MANAGER-SA] Failed to rotate token for cluster %s (expires at %s): %v",
+				account.ClusterID, account.TokenExpiresAt.Format(time.RFC3339), err)
+			continue
+		}
+		rotated++
+	}
+	return rotated, nil
+}
+
+func (s *ManagerAccountService) rotate(ctx context.Context, account *model.ClusterServiceAccount) (time.Time, error) {
+	cluster, err := s.clusterRepo.GetByID(account.ClusterID.String())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get cluster: %w", err)
+	}
+	kubeconfig, err := s.encryptionService.Decrypt(cluster.KubeconfigEncrypted)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to decrypt kubeconfig: %w", err)
+	}
+	clientset, err := s.clusterManager.GetClientForCluster(ctx, cluster.ID, kubeconfig)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	token, expiresAt, err := s.requestToken(ctx, clientset)
+	if err != nil {
+		return time.Time{}, err
+	}
+	rotated, err := replaceKubeconfigToken(kubeconfig, token)
+	if err != nil {
+		return time.Time{}, err
+	}
+	encrypted, err := s.encryptionService.Encrypt(rotated)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to encrypt kubeconfig: %w", err)
+	}
+	apiServerURL, _ := s.clusterManager.GetAPIServerURL(rotated)
+	credential := &model.ClusterCredential{
+		ClusterID:           cluster.ID,
+		KubeconfigEncrypted: encrypted,
+		Source:              model.ClusterCredentialSourceRotation,
+		APIServerURL:        apiServerURL,
+		// 新令牌由当前凭据在同一集群签发，沿用已记录的集群指纹
+		Fingerprint:         cluster.Fingerprint,
+		FingerprintVerified: cluster.ClusterUID != "",
+		CreatedBy:           "system",
+	}
+	// 以轮换开始时读取的凭据为期望值，期间凭据被更换时放弃本次轮换
+	previous := importedCredential(cluster, s.encryptionService, s.clusterManager)
+	if err := s.credentialRepo.Swap(cluster.KubeconfigEncrypted, previous, credential, clusterCredentialHistoryLimit); err != nil {
+		if errors.Is(err, repository.ErrClusterCredentialChanged) {
+			return time.Time{}, err
+		}
+		return time.Time{}, fmt.Errorf("failed to save kubeconfig: %w", err)
+	}
+	s.clusterManager.InvalidateCluster(cluster.ID)
+	s.listeners.notify(cluster.ID)
+	return expiresAt, nil
+}
+
+func (s *ManagerAccountService) subject() rbacv1.Subject {
+	return rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: s.namespace, Name: ManagerServiceAccountName}
+}
+
+func (s *ManagerAccountService) applyServiceAccount(ctx context.Context, clientset kubernetes.Interface) error {
+	_, err := clientset.CoreV1().ServiceAccounts(s.namespace).Create(ctx, &corev1.ServiceAccount{
+		ObjectMeta: managerObjectMeta(ManagerServiceAccountName, s.namespace),
+	}, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create service account: %w", err)
+	}
+	return nil
+}
+
+// applyClusterRBAC 创建或更新 ClusterRole 与 ClusterRoleBinding，重新导入时同步权限变化
+func (s *ManagerAccountService) applyClusterRBAC(ctx context.Context, clientset kubernetes.Interface) error {
+	clusterRoles := clientset.RbacV1().ClusterRoles()
+	role := &rbacv1.ClusterRole{
+		ObjectMeta: managerObjectMeta(ManagerServiceAccountName, ""),
+		Rules:      managerClusterRoleRules(s.grantImpersonation, s.grantUserKubeconfig),
+	}
+	existing, err := clusterRoles.Get(ctx, role.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		_, err = clusterRoles.Create(ctx, role, metav1.CreateOptions{})
+	case err == nil:
+		existing.Rules = role.Rules
+		_, err = clusterRoles.Update(ctx, existing, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply cluster role: %w", err)
+	}
+
+	bindings := clientset.RbacV1().ClusterRoleBindings()
+	binding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: managerObjectMeta(ManagerServiceAccountName, ""),
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: ManagerServiceAccountName},
+		Subjects:   []rbacv1.Subject{s.subject()},
+	}
+	existingBinding, err := bindings.Get(ctx, binding.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		_, err = bindings.Create(ctx, binding, metav1.CreateOptions{})
+	case err == nil:
+		existingBinding.Subjects = binding.Subjects
+		_, err = bindings.Update(ctx, existingBinding, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply cluster role binding: %w", err)
+	}
+	return nil
+}
+
+func (s *ManagerAccountService) requestToken(ctx context.Context, clientset kubernetes.Interface) (string, time.Time, error) {
+	seconds := int64(s.tokenTTL.Seconds())
+	tokenRequest, err := clientset.CoreV1().ServiceAccounts(s.namespace).CreateToken(ctx, ManagerServiceAccountName, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &seconds},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to request service account token: %w", err)
+	}
+	return tokenRequest.Status.Token, tokenRequest.Status.ExpirationTimestamp.Time, nil
+}
+
+// applyRole 创建或更新命名空间内的 Role 及绑定到 subject 的同名 RoleBinding
+func applyRole(ctx context.Context, clientset kubernetes.Interface, namespace, name string, rules []rbacv1.PolicyRule, subject rbacv1.Subject) error {
+	roles := clientset.RbacV1().Roles(namespace)
+	existing, err := roles.Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		_, err = roles.Create(ctx, &rbacv1.Role{ObjectMeta: managerObjectMeta(name, namespace), Rules: rules}, metav1.CreateOptions{})
+	case err == nil:
+		existing.Rules = rules
+		_, err = roles.Update(ctx, existing, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply role %s/%s: %w", namespace, name, err)
+	}
+
+	_, err = clientset.RbacV1().RoleBindings(namespace).Create(ctx, &rbacv1.RoleBinding{
+		ObjectMeta: managerObjectMeta(name, namespace),
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: name},
+		Subjects:   []rbacv1.Subject{subject},
+	}, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create role binding %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+func ensureNamespace(ctx context.Context, clientset kubernetes.Interface, namespace string) error {
+	_, err := clientset.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
+		ObjectMeta: managerObjectMeta(namespace, ""),
+	}, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create namespace %s: %w", namespace, err)
+	}
+	return nil
+}
+
+func managerObjectMeta(name, namespace string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: namespace,
+		Labels:    map[string]string{kubeconfigManagedByLabel: kubeconfigManagedBy},
+	}
+}
+
+// serviceAccountKubeconfig 以导入 kubeconfig 当前上下文的集群信息生成只含令牌的 kubeconfig
+func serviceAccountKubeconfig(importKubeconfig, user, token string) (string, error) {
+	source, err := clientcmd.Load([]byte(importKubeconfig))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidKubeConfig, err)
+	}
+	current, ok := source.Contexts[source.CurrentContext]
+	if !ok {
+		return "", fmt.Errorf("%w: current context %q not found", ErrInvalidKubeConfig, source.CurrentContext)
+	}
+	cluster, ok := source.Clusters[current.Cluster]
+	if !ok {
+		return "", fmt.Errorf("%w: cluster %q not found", ErrInvalidKubeConfig, current.Cluster)
+	}
+
+	config := clientcmdapi.NewConfig()
+	config.Clusters[current.Cluster] = cluster
+	config.AuthInfos[user] = &clientcmdapi.AuthInfo{Token: token}
+	config.Contexts[user] = &clientcmdapi.Context{Cluster: current.Cluster, AuthInfo: user}
+	config.CurrentContext = user
+
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		return "", fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+	return string(data), nil
+}
+
+// kubeconfigTokenExpiry 解析 kubeconfig 当前上下文用户的 ServiceAccount 令牌的过期时间
+// 令牌不是 JWT 或未设置过期时间时 ok 为 false
+func kubeconfigTokenExpiry(kubeconfig string) (expiresAt time.Time, ok bool) {
+	config, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return time.Time{}, false
+	}
+	current, found := config.Contexts[config.CurrentContext]
+	if !found {
+		return time.Time{}, false
+	}
+	authInfo, found := config.AuthInfos[current.AuthInfo]
+	if !found || authInfo.Token == "" {
+		return time.Time{}, false
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(authInfo.Token, claims); err != nil {
+		return time.Time{}, false
+	}
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return time.Time{}, false
+	}
+	return exp.Time, true
+}
+
+// replaceKubeconfigToken 替换 kubeconfig 当前上下文用户的令牌
+func replaceKubeconfigToken(kubeconfig, token string) (string, error) {
+	config, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidKubeConfig, err)
+	}
+	current, ok := config.Contexts[config.CurrentContext]
+	if !ok {
+		return "", fmt.Errorf("%w: current context %q not found", ErrInvalidKubeConfig, config.CurrentContext)
+	}
+	authInfo, ok := config.AuthInfos[current.AuthInfo]
+	if !ok {
+		return "", fmt.Errorf("%w: user %q not found", ErrInvalidKubeConfig, current.AuthInfo)
+	}
+	authInfo.Token = token
+
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		return "", fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+	return string(data), nil
+}
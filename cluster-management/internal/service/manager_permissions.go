@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// 管理端访问集群所需权限按功能划分
+const (
+	PermissionFeatureSync           = "sync"            // 健康检查、资源同步与 Informer
+	PermissionFeatureClassification = "classification"  // 租户/环境/应用分类与配额同步
+	PermissionFeaturePolicy         = "policy"          // 安全策略、弹性策略与弃用 API 扫描
+	PermissionFeatureEnvironment    = "environment"     // 创建环境命名空间与配额管理
+	PermissionFeatureNodeOperation  = "node_operation"  // 节点封锁与驱逐
+	PermissionFeatureBackup         = "backup"          // 资源备份（含 Secret）
+	PermissionFeatureRestore        = "restore"         // 从备份恢复资源
+	PermissionFeatureAPIProxy       = "api_proxy"       // API 代理模拟用户访问
+	PermissionFeatureUserKubeconfig = "user_kubeconfig" // 为用户签发 kubeconfig
+)
+
+var readVerbs = []string{"get", "list", "watch"}
+
+// managerClusterRoleRules 最小权限 ServiceAccount 的 ClusterRole 规则，覆盖后台 Worker 与环境、节点管理功能
+// 未开启 grantUserKubeconfig 时不包含 Secret 读取，资源备份会跳过 Secret
+// 开启 grantUserKubeconfig 后令牌可在用户命名空间创建 ServiceAccount 并签发其令牌，再在任意命名空间把 admin 绑定给它，
+// 等同于任意命名空间的 admin（含 Secret 读写），不再是最小权限
+func managerClusterRoleRules(grantImpersonation, grantUserKubeconfig bool) []rbacv1.PolicyRule {
+	rules := []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{""},
+			Resources: []string{
+				"nodes", "namespaces", "pods", "services", "endpoints", "events", "configmaps",
+				"persistentvolumes", "persistentvolumeclaims", "resourcequotas", "limitranges", "serviceaccounts",
+			},
+			Verbs: readVerbs,
+		},
+		{
+			APIGroups: []string{
+				"apps", "batch", "autoscaling", "networking.k8s.io", "policy", "storage.k8s.io",
+				"rbac.authorization.k8s.io", "scheduling.k8s.io", "coordination.k8s.io", "discovery.k8s.io",
+				"events.k8s.io", "certificates.k8s.io", "admissionregistration.k8s.io",
+				"apiextensions.k8s.io", "apiregistration.k8s.io", "node.k8s.io", "flowcontrol.apiserver.k8s.io",
+			},
+			Resources: []string{"*"},
+			Verbs:     readVerbs,
+		},
+		{
+			NonResourceURLs: []string{"/version", "/healthz", "/livez", "/livez/*", "/readyz", "/readyz/*", "/api", "/api/*", "/apis", "/apis/*"},
+			Verbs:           []string{"get"},
+		},
+		// 环境命名空间与配额管理
+		{
+			APIGroups: []string{""},
+			Resources: []string{"namespaces"},
+			Verbs:     []string{"create", "update", "patch"},
+		},
+		{
+			APIGroups: []string{""},
+			Resources: []string{"resourcequotas", "limitranges"},
+			Verbs:     []string{"create", "update", "patch", "delete"},
+		},
+		// 节点封锁与驱逐
+		{
+			APIGroups: []string{""},
+			Resources: []string{"nodes"},
+			Verbs:     []string{"update", "patch"},
+		},
+		{
+			APIGroups: []string{""},
+			Resources: []string{"pods/eviction"},
+			Verbs:     []string{"create"},
+		},
+	}
+	if grantImpersonation {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{""},
+			Resources: []string{"users", "groups"},
+			Verbs:     []string{"impersonate"},
+		})
+	}
+	if grantUserKubeconfig {
+		rules = append(rules,
+			rbacv1.PolicyRule{
+				APIGroups: []string{"rbac.authorization.k8s.io"},
+				Resources: []string{"rolebindings"},
+				Verbs:     []string{"create", "delete"},
+			},
+			rbacv1.PolicyRule{
+				APIGroups:     []string{"rbac.authorization.k8s.io"},
+				Resources:     []string{"clusterroles"},
+				Verbs:         []string{"bind"},
+				ResourceNames: []string{"admin", "edit", "view"},
+			},
+		)
+	}
+	return rules
+}
+
+// permissionCheck 导入校验时检查的一项权限
+type permissionCheck struct {
+	Feature     string
+	Required    bool
+	Verb        string
+	Group       string
+	Resource    string
+	Subresource string
+	Name        string
+	Namespace   string
+	Path        string // 非资源 URL
+}
+
+func (p permissionCheck) String() string {
+	if p.Path != "" {
+		return p.Verb + " " + p.Path
+	}
+	resource := p.Resource
+	if p.Subresource != "" {
+		resource += "/" + p.Subresource
+	}
+	if p.Group != "" {
+		resource += "." + p.Group
+	}
+	if p.Name != "" {
+		resource += "/" + p.Name
+	}
+	if p.Namespace != "" {
+		resource += " in " + p.Namespace
+	}
+	return p.Verb + " " + resource
+}
+
+// managerPermissionChecks 平台各功能访问集群时使用的操作，sync 为导入必需
+var managerPermissionChecks = func() []permissionCheck {
+	var checks []permissionCheck
+	add := func(feature string, required bool, group, resource string, verbs ...string) {
+		for _, verb := range verbs {
+			checks = append(checks, permissionCheck{Feature: feature, Required: required, Verb: verb, Group: group, Resource: resource})
+		}
+	}
+
+	add(PermissionFeatureSync, true, "", "nodes", "get", "list", "watch")
+	add(PermissionFeatureSync, true, "", "namespaces", "get", "list")
+	add(PermissionFeatureSync, true, "", "pods", "get", "list")
+	add(PermissionFeatureSync, true, "", "events", "list", "watch")
+	add(PermissionFeatureSync, true, "", "persistentvolumeclaims", "list", "watch")
+	add(PermissionFeatureSync, false, "", "persistentvolumes", "list")
+	checks = append(checks, permissionCheck{Feature: PermissionFeatureSync, Verb: "get", Path: "/readyz"})
+
+	add(PermissionFeatureClassification, false, "apps", "deployments", "get", "list")
+	add(PermissionFeatureClassification, false, "apps", "statefulsets", "list")
+	add(PermissionFeatureClassification, false, "apps", "daemonsets", "get", "list")
+	add(PermissionFeatureClassification, false, "", "services", "list")
+	add(PermissionFeatureClassification, false, "", "configmaps", "get", "list")
+	add(PermissionFeatureClassification, false, "", "resourcequotas", "get", "list")
+	add(PermissionFeatureClassification, false, "", "limitranges", "list")
+	// Informer 模式下增量维护三级模型
+	add(PermissionFeatureClassification, false, "", "namespaces", "watch")
+	add(PermissionFeatureClassification, false, "apps", "deployments", "watch")
+	add(PermissionFeatureClassification, false, "apps", "statefulsets", "watch")
+	add(PermissionFeatureClassification, false, "", "services", "watch")
+	add(PermissionFeatureClassification, false, "", "resourcequotas", "watch")
+
+	add(PermissionFeaturePolicy, false, "networking.k8s.io", "networkpolicies", "list")
+	add(PermissionFeaturePolicy, false, "networking.k8s.io", "ingresses", "list")
+	add(PermissionFeaturePolicy, false, "rbac.authorization.k8s.io", "clusterroles", "list")
+	add(PermissionFeaturePolicy, false, "rbac.authorization.k8s.io", "roles", "list")
+	add(PermissionFeaturePolicy, false, "autoscaling", "horizontalpodautoscalers", "list")
+	add(PermissionFeaturePolicy, false, "apiextensions.k8s.io", "customresourcedefinitions", "list")
+
+	add(PermissionFeatureEnvironment, false, "", "namespaces", "create", "update")
+	add(PermissionFeatureEnvironment, false, "", "resourcequotas", "create", "update")
+	add(PermissionFeatureEnvironment, false, "", "limitranges", "create", "update", "delete")
+
+	add(PermissionFeatureNodeOperation, false, "", "nodes", "update", "patch")
+	checks = append(checks, permissionCheck{Feature: PermissionFeatureNodeOperation, Verb: "create", Resource: "pods", Subresource: "eviction"})
+
+	add(PermissionFeatureBackup, false, "", "secrets", "get", "list")
+	add(PermissionFeatureRestore, false, "apps", "deployments", "create")
+	add(PermissionFeatureRestore, false, "apps", "statefulsets", "create")
+	add(PermissionFeatureRestore, false, "apps", "daemonsets", "create")
+	add(PermissionFeatureRestore, false, "", "services", "create")
+	add(PermissionFeatureRestore, false, "", "configmaps", "create")
+	add(PermissionFeatureRestore, false, "", "secrets", "create")
+	add(PermissionFeatureRestore, false, "networking.k8s.io", "ingresses", "create")
+
+	add(PermissionFeatureAPIProxy, false, "", "users", "impersonate")
+	add(PermissionFeatureAPIProxy, false, "", "groups", "impersonate")
+
+	add(PermissionFeatureUserKubeconfig, false, "rbac.authorization.k8s.io", "rolebindings", "create", "delete")
+	checks = append(checks, permissionCheck{Feature: PermissionFeatureUserKubeconfig, Verb: "bind", Group: "rbac.authorization.k8s.io", Resource: "clusterroles", Name: "edit"})
+	return checks
+}()
+
+// MissingPermission 缺少的权限
+type MissingPermission struct {
+	Feature    string `json:"feature"`
+	Required   bool   `json:"required"`
+	Permission string `json:"permission"`
+}
+
+// PermissionReport 导入凭据的权限检查结果
+type PermissionReport struct {
+	Checked int                 `json:"checked"`
+	Missing []MissingPermission `json:"missing"`
+}
+
+// RequiredMissing 缺少的必需权限
+func (r *PermissionReport) RequiredMissing() []string {
+	var missing []string
+	for _, m := range r.Missing {
+		if m.Required {
+			missing = append(missing, m.Permission)
+		}
+	}
+	return missing
+}
+
+// toJSONMap 转换为导入记录校验结果中的格式
+func (r *PermissionReport) toJSONMap() map[string]interface{} {
+	missing := make([]interface{}, 0, len(r.Missing))
+	for _, m := range r.Missing {
+		missing = append(missing, map[string]interface{}{
+			"feature":    m.Feature,
+			"required":   m.Required,
+			"permission": m.Permission,
+		})
+	}
+	return map[string]interface{}{
+		"checked": r.Checked,
+		"missing": missing,
+	}
+}
+
+// CheckPermissions 使用 SelfSubjectAccessReview 检查当前凭据的权限，extra 为额外检查项（如 ServiceAccount 自身的令牌签发）
+func CheckPermissions(ctx context.Context, clientset kubernetes.Interface, extra ...permissionCheck) (*PermissionReport, error) {
+	checks := append(append([]permissionCheck{}, managerPermissionChecks...), extra...)
+	report := &PermissionReport{Checked: len(checks), Missing: []MissingPermission{}}
+	for _, check := range checks {
+		review := &authorizationv1.SelfSubjectAccessReview{}
+		if check.Path != "" {
+			review.Spec.NonResourceAttributes = &authorizationv1.NonResourceAttributes{Path: check.Path, Verb: check.Verb}
+		} else {
+			review.Spec.ResourceAttributes = &authorizationv1.ResourceAttributes{
+				Namespace:   check.Namespace,
+				Verb:        check.Verb,
+				Group:       check.Group,
+				Resource:    check.Resource,
+				Subresource: check.Subresource,
+				Name:        check.Name,
+			}
+		}
+		result, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to review permission %q: %w", check, err)
+		}
+		if !result.Status.Allowed {
+			report.Missing = append(report.Missing, MissingPermission{
+				Feature:    check.Feature,
+				Required:   check.Required,
+				Permission: check.String(),
+			})
+		}
+	}
+	return report, nil
+}
+
+// formatMissingPermissions 拼接缺少的权限用于错误信息
+func formatMissingPermissions(missing []string) string {
+	return strings.Join(missing, ", ")
+}
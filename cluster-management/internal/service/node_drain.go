@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	defaultDrainTimeout   = 5 * time.Minute
+	evictionRetryInterval = 5 * time.Second
+	podDeletePollInterval = 2 * time.Second
+	mirrorPodAnnotation   = "kubernetes.io/config.mirror"
+)
+
+// DrainOptions 节点排空选项，与 kubectl drain 的同名参数含义一致
+type DrainOptions struct {
+	IgnoreDaemonSets   bool `json:"ignore_daemonsets"`
+	DeleteEmptyDirData bool `json:"delete_emptydir_data"`
+	Force              bool `json:"force"`                // 同时驱逐不受控制器管理的Pod
+	GracePeriodSeconds int  `json:"grace_period_seconds"` // 小于0时使用Pod自身的优雅终止时间
+	TimeoutSeconds     int  `json:"timeout_seconds"`      // 0 表示默认5分钟
+}
+
+// DrainResult 排空结果，Pod 以 namespace/name 表示
+type DrainResult struct {
+	Evicted []string `json:"evicted"`
+	Blocked []string `json:"blocked"` // 附带原因
+	Skipped []string `json:"skipped"`
+}
+
+// setNodeUnschedulable 设置节点是否可调度
+func setNodeUnschedulable(ctx context.Context, clientset kubernetes.Interface, nodeName string, unschedulable bool) error {
+	patch := []byte(fmt.Sprintf(`{"spec":{"unschedulable":%t}}`, unschedulable))
+	_, err := clientset.CoreV1().Nodes().Patch(ctx, nodeName, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// drainNode 封锁节点并通过驱逐API迁移其上的Pod
+// 存在不满足选项的Pod时不驱逐任何Pod，与 kubectl drain 行为一致；PDB 拒绝的驱逐会重试到超时
+func drainNode(ctx context.Context, clientset kubernetes.Interface, nodeName string, opts DrainOptions, logf func(line string, isError bool)) (*DrainResult, error) {
+	timeout := defaultDrainTimeout
+	if opts.TimeoutSeconds > 0 {
+		timeout = time.Duration(opts.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := setNodeUnschedulable(ctx, clientset, nodeName, true); err != nil {
+		return nil, fmt.Errorf("failed to cordon node: %w", err)
+	}
+	logf(fmt.Sprintf("node %s cordoned", nodeName), false)
+
+	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		FieldSelector: "spec.nodeName=" + nodeName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	result := &DrainResult{}
+	var toEvict []corev1.Pod
+	for _, pod := range pods.Items {
+		key := pod.Namespace + "/" + pod.Name
+		if skip, reason := drainFilter(pod, opts); reason != "" {
+			if skip {
+				result.Skipped = append(result.Skipped, key)
+			} else {
+				result.Blocked = append(result.Blocked, fmt.Sprintf("%s: %s", key, reason))
+			}
+			continue
+		}
+		toEvict = append(toEvict, pod)
+	}
+	if len(result.Blocked) > 0 {
+		return result, fmt.Errorf("cannot drain node %s: %d pods do not satisfy the drain options", nodeName, len(result.Blocked))
+	}
+
+	logf(fmt.Sprintf("evicting %d pods, %d skipped", len(toEvict), len(result.Skipped)), false)
+
+	var gracePeriod *int64
+	if opts.GracePeriodSeconds >= 0 {
+		seconds := int64(opts.GracePeriodSeconds)
+		gracePeriod = &seconds
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, pod := range toEvict {
+		wg.Add(1)
+		go func(pod corev1.Pod) {
+			defer wg.Done()
+			key := pod.Namespace + "/" + pod.Name
+			err := evictPod(ctx, clientset, pod, gracePeriod)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				result.Blocked = append(result.Blocked, fmt.Sprintf("%s: %v", key, err))
+				logf(fmt.Sprintf("pod %s not evicted: %v", key, err), true)
+				return
+			}
+			result.Evicted = append(result.Evicted, key)
+			logf(fmt.Sprintf("pod %s evicted", key), false)
+		}(pod)
+	}
+	wg.Wait()
+
+	if len(result.Blocked) > 0 {
+		return result, fmt.Errorf("%d pods could not be evicted from node %s", len(result.Blocked), nodeName)
+	}
+	return result, nil
+}
+
+// drainFilter 判断Pod是否跳过或阻止排空，reason 为空表示需要驱逐
+func drainFilter(pod corev1.Pod, opts DrainOptions) (skip bool, reason string) {
+	if _, ok := pod.Annotations[mirrorPodAnnotation]; ok {
+		return true, "static pod"
+	}
+	// 已结束的Pod不会丢失数据，直接驱逐
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return false, ""
+	}
+
+	controller := metav1.GetControllerOf(&pod)
+	if controller != nil && controller.Kind == "DaemonSet" {
+		if opts.IgnoreDaemonSets {
+			return true, "managed by DaemonSet"
+		}
+		return false, "managed by DaemonSet, set ignore_daemonsets to skip"
+	}
+	if controller == nil && !opts.Force {
+		return false, "not managed by a controller, set force to evict"
+	}
+	if !opts.DeleteEmptyDirData {
+		for _, volume := range pod.Spec.Volumes {
+			if volume.EmptyDir != nil {
+				return false, "uses emptyDir volume " + volume.Name + ", set delete_emptydir_data to evict"
+			}
+		}
+	}
+	return false, ""
+}
+
+// evictPod 驱逐Pod并等待其删除，被PDB拒绝时按间隔重试直到超时
+func evictPod(ctx context.Context, clientset kubernetes.Interface, pod corev1.Pod, gracePeriod *int64) error {
+	eviction := &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+		},
+		DeleteOptions: &metav1.DeleteOptions{GracePeriodSeconds: gracePeriod},
+	}
+
+	for {
+		err := clientset.PolicyV1().Evictions(pod.Namespace).Evict(ctx, eviction)
+		switch {
+		case err == nil:
+			return waitForPodDeleted(ctx, clientset, pod)
+		case apierrors.IsNotFound(err):
+			return nil
+		case apierrors.IsTooManyRequests(err):
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("blocked by PodDisruptionBudget: %v", err)
+			case <-time.After(evictionRetryInterval):
+			}
+		default:
+			return err
+		}
+	}
+}
+
+// waitForPodDeleted 等待Pod删除，同名Pod被重建（UID变化）也视为已删除
+func waitForPodDeleted(ctx context.Context, clientset kubernetes.Interface, pod corev1.Pod) error {
+	for {
+		current, err := clientset.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) || (err == nil && current.UID != pod.UID) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for pod deletion")
+		case <-time.After(podDeletePollInterval):
+		}
+	}
+}
@@ -0,0 +1,351 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"github.com/taichu-system/cluster-management/internal/repository"
+	corev1 "k8s.io/api/core/v1"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+)
+
+// 版本与运行时问题类型
+const (
+	VersionIssueKubeletSkew      = "kubelet_skew"
+	VersionIssueKubeProxySkew    = "kube_proxy_skew"
+	VersionIssueControlPlaneSkew = "control_plane_skew"
+	VersionIssueMixedRuntime     = "mixed_runtime"
+	VersionIssueEndOfLife        = "end_of_life"
+	VersionIssueEndOfLifeSoon    = "end_of_life_soon"
+)
+
+// endOfLifeWarningWindow 到期前多久开始提示
+const endOfLifeWarningWindow = 60 * 24 * time.Hour
+
+// kubernetesEndOfLife 上游各次版本的维护结束日期，早于表中最小版本的均视为已结束
+var kubernetesEndOfLife = map[int]string{
+	25: "2023-10-28",
+	26: "2024-02-28",
+	27: "2024-06-28",
+	28: "2024-10-28",
+	29: "2025-02-28",
+	30: "2025-06-28",
+	31: "2025-10-28",
+	32: "2026-02-28",
+	33: "2026-06-28",
+	34: "2026-10-27",
+	35: "2027-02-28",
+}
+
+// ApplyNodeSystemInfo 将 node.status.nodeInfo 中的版本与运行时信息写入节点记录
+func ApplyNodeSystemInfo(node *model.Node, info corev1.NodeSystemInfo) {
+	node.KubeletVersion = info.KubeletVersion
+	node.KubeProxyVersion = info.KubeProxyVersion
+	node.ContainerRuntime, node.ContainerRuntimeVersion = splitContainerRuntime(info.ContainerRuntimeVersion)
+	node.OSImage = info.OSImage
+	node.KernelVersion = info.KernelVersion
+	node.Architecture = info.Architecture
+}
+
+// VersionIssue 版本偏差或运行时问题
+type VersionIssue struct {
+	Type        string              `json:"type"`
+	Severity    model.AlertSeverity `json:"severity"`
+	ClusterID   uuid.UUID           `json:"cluster_id"`
+	ClusterName string              `json:"cluster_name"`
+	NodeName    string              `json:"node_name,omitempty"`
+	Message     string              `json:"message"`
+}
+
+// ClusterVersionSummary 单个集群的版本与运行时概况
+type ClusterVersionSummary struct {
+	ClusterID           uuid.UUID      `json:"cluster_id"`
+	ClusterName         string         `json:"cluster_name"`
+	ControlPlaneVersion string         `json:"control_plane_version"`
+	EndOfLifeDate       string         `json:"end_of_life_date,omitempty"`
+	NodeCount           int            `json:"node_count"`
+	KubeletVersions     map[string]int `json:"kubelet_versions"`
+	ContainerRuntimes   map[string]int `json:"container_runtimes"`
+	OSImages            map[string]int `json:"os_images"`
+	Architectures       map[string]int `json:"architectures"`
+	IssueCount          int            `json:"issue_count"`
+}
+
+// FleetVersionReport 全部集群的版本偏差与节点运行时清单
+type FleetVersionReport struct {
+	GeneratedAt       time.Time                `json:"generated_at"`
+	Clusters          []*ClusterVersionSummary `json:"clusters"`
+	Issues            []VersionIssue           `json:"issues"`
+	Summary           map[string]int           `json:"summary"`
+	KubeletVersions   map[string]int           `json:"kubelet_versions"`
+	ContainerRuntimes map[string]int           `json:"container_runtimes"`
+	KernelVersions    map[string]int           `json:"kernel_versions"`
+}
+
+// NodeInventoryService 节点运行时清单与版本偏差报告服务
+type NodeInventoryService struct {
+	clusterRepo *repository.ClusterRepository
+	stateRepo   *repository.ClusterStateRepository
+	nodeRepo    *repository.NodeRepository
+}
+
+// NewNodeInventoryService 创建节点运行时清单服务
+func NewNodeInventoryService(
+	clusterRepo *repository.ClusterRepository,
+	stateRepo *repository.ClusterStateRepository,
+	nodeRepo *repository.NodeRepository,
+) *NodeInventoryService {
+	return &NodeInventoryService{
+		clusterRepo: clusterRepo,
+		stateRepo:   stateRepo,
+		nodeRepo:    nodeRepo,
+	}
+}
+
+// FleetVersionReport 基于已同步的节点信息生成全局报告
+func (s *NodeInventoryService) FleetVersionReport() (*FleetVersionReport, error) {
+	clusters, err := s.clusterRepo.FindActiveClusters()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clusters: %w", err)
+	}
+	states, err := s.stateRepo.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster states: %w", err)
+	}
+	versions := make(map[uuid.UUID]string, len(states))
+	for _, state := range states {
+		versions[state.ClusterID] = state.KubernetesVersion
+	}
+
+	now := time.Now()
+	report := &FleetVersionReport{
+		GeneratedAt:       now,
+		Clusters:          make([]*ClusterVersionSummary, 0, len(clusters)),
+		Issues:            []VersionIssue{},
+		Summary:           make(map[string]int),
+		KubeletVersions:   make(map[string]int),
+		ContainerRuntimes: make(map[string]int),
+		KernelVersions:    make(map[string]int),
+	}
+
+	for _, cluster := range clusters {
+		nodes, err := s.nodeRepo.GetByClusterID(cluster.ID.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to list nodes of cluster %s: %w", cluster.Name, err)
+		}
+		summary, issues := evaluateClusterVersions(cluster, versions[cluster.ID], nodes, now)
+		report.Clusters = append(report.Clusters, summary)
+		report.Issues = append(report.Issues, issues...)
+
+		for _, node := range nodes {
+			if node.KubeletVersion != "" {
+				report.KubeletVersions[node.KubeletVersion]++
+			}
+			if runtime := runtimeLabel(node); runtime != "" {
+				report.ContainerRuntimes[runtime]++
+			}
+			if node.KernelVersion != "" {
+				report.KernelVersions[node.KernelVersion]++
+			}
+		}
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		return severityRank(report.Issues[i].Severity) < severityRank(report.Issues[j].Severity)
+	})
+	for _, issue := range report.Issues {
+		report.Summary[issue.Type]++
+	}
+	return report, nil
+}
+
+// evaluateClusterVersions 按上游版本偏差策略检查单个集群
+// 控制面版本优先取健康检查记录的 apiserver 版本，缺失时取控制面节点中最高的 kubelet 版本
+func evaluateClusterVersions(cluster *model.Cluster, apiServerVersion string, nodes []model.Node, now time.Time) (*ClusterVersionSummary, []VersionIssue) {
+	summary := &ClusterVersionSummary{
+		ClusterID:         cluster.ID,
+		ClusterName:       cluster.Name,
+		NodeCount:         len(nodes),
+		KubeletVersions:   make(map[string]int),
+		ContainerRuntimes: make(map[string]int),
+		OSImages:          make(map[string]int),
+		Architectures:     make(map[string]int),
+	}
+	var issues []VersionIssue
+	addIssue := func(issueType string, severity model.AlertSeverity, nodeName, message string) {
+		issues = append(issues, VersionIssue{
+			Type:        issueType,
+			Severity:    severity,
+			ClusterID:   cluster.ID,
+			ClusterName: cluster.Name,
+			NodeName:    nodeName,
+			Message:     message,
+		})
+	}
+
+	var controlPlane []*utilversion.Version
+	var controlPlaneNames []string
+	for _, node := range nodes {
+		if node.KubeletVersion != "" {
+			summary.KubeletVersions[node.KubeletVersion]++
+		}
+		if node.ContainerRuntime != "" {
+			summary.ContainerRuntimes[runtimeLabel(node)]++
+		}
+		if node.OSImage != "" {
+			summary.OSImages[node.OSImage]++
+		}
+		if node.Architecture != "" {
+			summary.Architectures[node.Architecture]++
+		}
+		if node.Type == "control-plane" || node.Type == "master" {
+			if v, err := utilversion.ParseGeneric(node.KubeletVersion); err == nil {
+				controlPlane = append(controlPlane, v)
+				controlPlaneNames = append(controlPlaneNames, node.Name)
+			}
+		}
+	}
+
+	apiServer, err := utilversion.ParseGeneric(apiServerVersion)
+	if err != nil {
+		apiServer = nil
+		for _, v := range controlPlane {
+			if apiServer == nil || !apiServer.AtLeast(v) {
+				apiServer = v
+			}
+		}
+	}
+	if apiServer == nil {
+		summary.IssueCount = len(issues)
+		return summary, issues
+	}
+	summary.ControlPlaneVersion = "v" + apiServer.String()
+
+	// 控制面节点之间最多相差一个次版本
+	for i, v := range controlPlane {
+		if minorDistance(apiServer, v) > 1 {
+			addIssue(VersionIssueControlPlaneSkew, model.AlertSeverityHigh, controlPlaneNames[i],
+				fmt.Sprintf("control-plane node runs v%s, more than one minor version from v%s", v.String(), apiServer.String()))
+		}
+	}
+
+	// 自 1.28 起 kubelet 与 kube-proxy 最多落后 apiserver 三个次版本，之前为两个
+	maxLag := 2
+	if apiServer.Minor() >= 28 {
+		maxLag = 3
+	}
+	for _, node := range nodes {
+		checkComponentSkew(apiServer, maxLag, node.KubeletVersion, func(message string, severity model.AlertSeverity) {
+			addIssue(VersionIssueKubeletSkew, severity, node.Name, "kubelet "+message)
+		})
+		checkComponentSkew(apiServer, maxLag, node.KubeProxyVersion, func(message string, severity model.AlertSeverity) {
+			addIssue(VersionIssueKubeProxySkew, severity, node.Name, "kube-proxy "+message)
+		})
+	}
+
+	if len(summary.ContainerRuntimes) > 1 {
+		runtimes := make([]string, 0, len(summary.ContainerRuntimes))
+		names := make(map[string]bool)
+		for runtime := range summary.ContainerRuntimes {
+			runtimes = append(runtimes, runtime)
+			names[strings.SplitN(runtime, " ", 2)[0]] = true
+		}
+		sort.Strings(runtimes)
+		// 不同运行时视为风险，同一运行时的多个版本仅提示
+		severity := model.AlertSeverityLow
+		if len(names) > 1 {
+			severity = model.AlertSeverityMedium
+		}
+		addIssue(VersionIssueMixedRuntime, severity, "",
+			fmt.Sprintf("nodes run mixed container runtimes: %s", strings.Join(runtimes, ", ")))
+	}
+
+	eol, known := endOfLifeDate(apiServer.Minor())
+	if known {
+		summary.EndOfLifeDate = eol.Format("2006-01-02")
+	}
+	switch {
+	case known && now.After(eol), !known && apiServer.Minor() < minKnownMinor():
+		addIssue(VersionIssueEndOfLife, model.AlertSeverityHigh, "",
+			fmt.Sprintf("Kubernetes 1.%d is end of life", apiServer.Minor()))
+	case known && eol.Sub(now) <= endOfLifeWarningWindow:
+		addIssue(VersionIssueEndOfLifeSoon, model.AlertSeverityMedium, "",
+			fmt.Sprintf("Kubernetes 1.%d reaches end of life on %s", apiServer.Minor(), summary.EndOfLifeDate))
+	}
+
+	summary.IssueCount = len(issues)
+	return summary, issues
+}
+
+// checkComponentSkew 检查节点组件与 apiserver 的版本偏差
+func checkComponentSkew(apiServer *utilversion.Version, maxLag int, componentVersion string, report func(message string, severity model.AlertSeverity)) {
+	v, err := utilversion.ParseGeneric(componentVersion)
+	if err != nil {
+		return
+	}
+	if v.Major() != apiServer.Major() || v.Minor() > apiServer.Minor() {
+		report(fmt.Sprintf("v%s is newer than control plane v%s", v.String(), apiServer.String()), model.AlertSeverityCritical)
+		return
+	}
+	if lag := int(apiServer.Minor() - v.Minor()); lag > maxLag {
+		report(fmt.Sprintf("v%s is %d minor versions behind control plane v%s, maximum supported is %d", v.String(), lag, apiServer.String(), maxLag), model.AlertSeverityHigh)
+	}
+}
+
+// splitContainerRuntime 拆分 containerd://1.7.2 形式的运行时版本
+func splitContainerRuntime(value string) (runtime, version string) {
+	if parts := strings.SplitN(value, "://", 2); len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return value, ""
+}
+
+// runtimeLabel 返回 "containerd 1.7.2" 形式的运行时描述
+func runtimeLabel(node model.Node) string {
+	return strings.TrimSpace(node.ContainerRuntime + " " + node.ContainerRuntimeVersion)
+}
+
+// endOfLifeDate 返回次版本的维护结束日期，known 为 false 表示表中没有该版本
+func endOfLifeDate(minor uint) (eol time.Time, known bool) {
+	value, ok := kubernetesEndOfLife[int(minor)]
+	if !ok {
+		return time.Time{}, false
+	}
+	eol, err := time.Parse("2006-01-02", value)
+	return eol, err == nil
+}
+
+// minKnownMinor 返回维护日期表中最早的次版本
+func minKnownMinor() uint {
+	min := -1
+	for minor := range kubernetesEndOfLife {
+		if min < 0 || minor < min {
+			min = minor
+		}
+	}
+	return uint(min)
+}
+
+func minorDistance(a, b *utilversion.Version) uint {
+	if a.Minor() > b.Minor() {
+		return a.Minor() - b.Minor()
+	}
+	return b.Minor() - a.Minor()
+}
+
+func severityRank(severity model.AlertSeverity) int {
+	switch severity {
+	case model.AlertSeverityCritical:
+		return 0
+	case model.AlertSeverityHigh:
+		return 1
+	case model.AlertSeverityMedium:
+		return 2
+	default:
+		return 3
+	}
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/model"
+	corev1 "k8s.io/api/core/v1"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+)
+
+func inventoryNode(name, nodeType, kubelet, runtime string) model.Node {
+	node := model.Node{Name: name, Type: nodeType, KubeletVersion: kubelet, KubeProxyVersion: kubelet}
+	node.ContainerRuntime, node.ContainerRuntimeVersion = splitContainerRuntime(runtime)
+	return node
+}
+
+func TestEvaluateClusterVersions(t *testing.T) {
+	// 1.30 维护至 2025-06-28
+	now := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name             string
+		apiServer        string
+		nodes            []model.Node
+		wantControlPlane string
+		// wantIssues 问题类型与严重程度，按类型排序后比较
+		wantIssues []string
+	}{
+		{
+			name:      "supported skew",
+			apiServer: "v1.30.2",
+			nodes: []model.Node{
+				inventoryNode("cp-1", "control-plane", "v1.30.2", "containerd://1.7.2"),
+				inventoryNode("worker-1", "worker", "v1.27.9", "containerd://1.7.2"),
+			},
+			wantControlPlane: "v1.30.2",
+		},
+		{
+			name:      "kubelet four minors behind after 1.28",
+			apiServer: "v1.30.2",
+			nodes: []model.Node{
+				inventoryNode("worker-1", "worker", "v1.26.5", "containerd://1.7.2"),
+			},
+			wantControlPlane: "v1.30.2",
+			wantIssues:       []string{"kube_proxy_skew/high", "kubelet_skew/high"},
+		},
+		{
+			name:      "kubelet three minors behind before 1.28",
+			apiServer: "v1.27.3",
+			nodes: []model.Node{
+				inventoryNode("worker-1", "worker", "v1.24.1", "containerd://1.7.2"),
+			},
+			wantControlPlane: "v1.27.3",
+			wantIssues:       []string{"end_of_life/high", "kube_proxy_skew/high", "kubelet_skew/high"},
+		},
+		{
+			name:      "kubelet newer than apiserver",
+			apiServer: "v1.30.0",
+			nodes: []model.Node{
+				inventoryNode("worker-1", "worker", "v1.31.0", "containerd://1.7.2"),
+			},
+			wantControlPlane: "v1.30.0",
+			wantIssues:       []string{"kube_proxy_skew/critical", "kubelet_skew/critical"},
+		},
+		{
+			name:      "control plane nodes two minors apart",
+			apiServer: "v1.30.0",
+			nodes: []model.Node{
+				inventoryNode("cp-1", "control-plane", "v1.30.0", "containerd://1.7.2"),
+				inventoryNode("cp-2", "master", "v1.28.0", "containerd://1.7.2"),
+			},
+			wantControlPlane: "v1.30.0",
+			wantIssues:       []string{"control_plane_skew/high"},
+		},
+		{
+			name: "control plane version from highest control plane kubelet",
+			nodes: []model.Node{
+				inventoryNode("cp-1", "control-plane", "v1.29.4", "containerd://1.7.2"),
+				inventoryNode("cp-2", "control-plane", "v1.30.1", "containerd://1.7.2"),
+				inventoryNode("worker-1", "worker", "v1.31.0", "containerd://1.7.2"),
+			},
+			wantControlPlane: "v1.30.1",
+			wantIssues:       []string{"kube_proxy_skew/critical", "kubelet_skew/critical"},
+		},
+		{
+			name: "no version information",
+			nodes: []model.Node{
+				inventoryNode("worker-1", "worker", "", ""),
+			},
+		},
+		{
+			name:      "same runtime with different versions",
+			apiServer: "v1.30.0",
+			nodes: []model.Node{
+				inventoryNode("worker-1", "worker", "v1.30.0", "containerd://1.7.2"),
+				inventoryNode("worker-2", "worker", "v1.30.0", "containerd://1.6.9"),
+			},
+			wantControlPlane: "v1.30.0",
+			wantIssues:       []string{"mixed_runtime/low"},
+		},
+		{
+			name:      "different runtimes",
+			apiServer: "v1.30.0",
+			nodes: []model.Node{
+				inventoryNode("worker-1", "worker", "v1.30.0", "containerd://1.7.2"),
+				inventoryNode("worker-2", "worker", "v1.30.0", "cri-o://1.30.0"),
+			},
+			wantControlPlane: "v1.30.0",
+			wantIssues:       []string{"mixed_runtime/medium"},
+		},
+		{
+			name:             "end of life",
+			apiServer:        "v1.28.9",
+			wantControlPlane: "v1.28.9",
+			wantIssues:       []string{"end_of_life/high"},
+		},
+		{
+			name:             "older than the end of life table",
+			apiServer:        "v1.20.0",
+			wantControlPlane: "v1.20.0",
+			wantIssues:       []string{"end_of_life/high"},
+		},
+		{
+			name:             "end of life within the warning window",
+			apiServer:        "v1.29.0",
+			wantControlPlane: "v1.29.0",
+			wantIssues:       []string{"end_of_life_soon/medium"},
+		},
+		{
+			name:             "newer than the end of life table",
+			apiServer:        "v1.40.0",
+			wantControlPlane: "v1.40.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &model.Cluster{ID: uuid.New(), Name: "skew"}
+			summary, issues := evaluateClusterVersions(cluster, tt.apiServer, tt.nodes, now)
+
+			if summary.ControlPlaneVersion != tt.wantControlPlane {
+				t.Errorf("control plane version = %q, want %q", summary.ControlPlaneVersion, tt.wantControlPlane)
+			}
+			got := make([]string, 0, len(issues))
+			for _, issue := range issues {
+				got = append(got, issue.Type+"/"+string(issue.Severity))
+				if issue.ClusterID != cluster.ID {
+					t.Errorf("issue %s has cluster %s, want %s", issue.Type, issue.ClusterID, cluster.ID)
+				}
+			}
+			sort.Strings(got)
+			if !equalStringSlices(got, tt.wantIssues) {
+				t.Errorf("issues = %v, want %v", got, tt.wantIssues)
+			}
+			if summary.IssueCount != len(issues) {
+				t.Errorf("issue count = %d, want %d", summary.IssueCount, len(issues))
+			}
+		})
+	}
+}
+
+func TestCheckComponentSkew(t *testing.T) {
+	tests := []struct {
+		name      string
+		apiServer string
+		maxLag    int
+		component string
+		want      model.AlertSeverity
+	}{
+		{name: "same version", apiServer: "1.30.0", maxLag: 3, component: "v1.30.0"},
+		{name: "within lag", apiServer: "1.30.0", maxLag: 3, component: "v1.27.0"},
+		{name: "beyond lag", apiServer: "1.30.0", maxLag: 3, component: "v1.26.0", want: model.AlertSeverityHigh},
+		{name: "beyond older lag", apiServer: "1.27.0", maxLag: 2, component: "v1.24.0", want: model.AlertSeverityHigh},
+		{name: "newer minor", apiServer: "1.30.0", maxLag: 3, component: "v1.31.0", want: model.AlertSeverityCritical},
+		{name: "newer patch is allowed", apiServer: "1.30.0", maxLag: 3, component: "v1.30.5"},
+		{name: "different major", apiServer: "1.30.0", maxLag: 3, component: "v2.0.0", want: model.AlertSeverityCritical},
+		{name: "distribution suffix", apiServer: "1.30.0", maxLag: 3, component: "v1.26.3+k3s1", want: model.AlertSeverityHigh},
+		{name: "unparseable version", apiServer: "1.30.0", maxLag: 3, component: "unknown"},
+		{name: "empty version", apiServer: "1.30.0", maxLag: 3, component: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got model.AlertSeverity
+			calls := 0
+			checkComponentSkew(utilversion.MustParseGeneric(tt.apiServer), tt.maxLag, tt.component, func(_ string, severity model.AlertSeverity) {
+				calls++
+				got = severity
+			})
+			if calls > 1 {
+				t.Fatalf("reported %d times, want at most once", calls)
+			}
+			if got != tt.want {
+				t.Errorf("severity = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyNodeSystemInfo(t *testing.T) {
+	tests := []struct {
+		name        string
+		runtime     string
+		wantRuntime string
+		wantVersion string
+	}{
+		{name: "containerd", runtime: "containerd://1.7.2", wantRuntime: "containerd", wantVersion: "1.7.2"},
+		{name: "cri-o", runtime: "cri-o://1.30.0", wantRuntime: "cri-o", wantVersion: "1.30.0"},
+		{name: "without scheme", runtime: "docker", wantRuntime: "docker"},
+		{name: "empty", runtime: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var node model.Node
+			ApplyNodeSystemInfo(&node, corev1.NodeSystemInfo{
+				KubeletVersion:          "v1.30.2",
+				KubeProxyVersion:        "v1.30.2",
+				ContainerRuntimeVersion: tt.runtime,
+				OSImage:                 "Ubuntu 22.04.4 LTS",
+				KernelVersion:           "5.15.0-105-generic",
+				Architecture:            "amd64",
+			})
+			if node.ContainerRuntime != tt.wantRuntime || node.ContainerRuntimeVersion != tt.wantVersion {
+				t.Errorf("runtime = %q %q, want %q %q", node.ContainerRuntime, node.ContainerRuntimeVersion, tt.wantRuntime, tt.wantVersion)
+			}
+			if node.KubeletVersion != "v1.30.2" || node.OSImage == "" || node.KernelVersion == "" || node.Architecture != "amd64" {
+				t.Errorf("system info not applied: %+v", node)
+			}
+		})
+	}
+}
+
+func equalStringSlices(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,345 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/model"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// ErrInvalidNodeMetadata 节点标签或污点不合法
+var ErrInvalidNodeMetadata = errors.New("invalid node labels or taints")
+
+// 节点标签与污点的修改方式
+const (
+	NodeMetadataAdd     = "add"     // 新增或覆盖同名项
+	NodeMetadataRemove  = "remove"  // 按键删除
+	NodeMetadataReplace = "replace" // 用给定集合替换全部非保留项
+)
+
+// TaintSpec 节点污点
+type TaintSpec struct {
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+	Effect string `json:"effect,omitempty"` // 删除时为空表示匹配该键的全部污点
+}
+
+// NodeLabelChange 节点标签修改
+type NodeLabelChange struct {
+	Operation string            `json:"operation"`
+	Labels    map[string]string `json:"labels,omitempty"` // add/replace 使用
+	Keys      []string          `json:"keys,omitempty"`   // remove 使用
+}
+
+// NodeTaintChange 节点污点修改
+type NodeTaintChange struct {
+	Operation string      `json:"operation"`
+	Taints    []TaintSpec `json:"taints"`
+}
+
+// NodeMetadataResult 单个节点的修改结果，旧值与新值用于审计
+type NodeMetadataResult struct {
+	NodeName string      `json:"node_name"`
+	Success  bool        `json:"success"`
+	Error    string      `json:"error,omitempty"`
+	Old      interface{} `json:"old,omitempty"`
+	New      interface{} `json:"new,omitempty"`
+}
+
+// UpdateNodeLabels 修改单个节点的标签
+func (s *NodeOperationService) UpdateNodeLabels(ctx context.Context, clusterID uuid.UUID, nodeName string, change NodeLabelChange) (*NodeMetadataResult, error) {
+	if err := validateLabelChange(change); err != nil {
+		return nil, err
+	}
+	clientset, err := s.clientForCluster(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	result := updateNode(ctx, clientset, nodeName, func(node *corev1.Node) (interface{}, interface{}) {
+		return applyLabelChange(node, change)
+	})
+	if !result.Success && result.Error == ErrNodeNotFound.Error() {
+		return nil, ErrNodeNotFound
+	}
+	return result, nil
+}
+
+// UpdateNodeTaints 修改单个节点的污点
+func (s *NodeOperationService) UpdateNodeTaints(ctx context.Context, clusterID uuid.UUID, nodeName string, change NodeTaintChange) (*NodeMetadataResult, error) {
+	if err := validateTaintChange(change); err != nil {
+		return nil, err
+	}
+	clientset, err := s.clientForCluster(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	result := updateNode(ctx, clientset, nodeName, func(node *corev1.Node) (interface{}, interface{}) {
+		return applyTaintChange(node, change)
+	})
+	if !result.Success && result.Error == ErrNodeNotFound.Error() {
+		return nil, ErrNodeNotFound
+	}
+	return result, nil
+}
+
+// BulkUpdateNodeLabels 修改标签选择器匹配的全部节点的标签，单个节点失败不影响其余节点
+func (s *NodeOperationService) BulkUpdateNodeLabels(ctx context.Context, clusterID uuid.UUID, selector string, change NodeLabelChange) ([]*NodeMetadataResult, error) {
+	if err := validateLabelChange(change); err != nil {
+		return nil, err
+	}
+	return s.bulkUpdate(ctx, clusterID, selector, func(node *corev1.Node) (interface{}, interface{}) {
+		return applyLabelChange(node, change)
+	})
+}
+
+// BulkUpdateNodeTaints 修改标签选择器匹配的全部节点的污点
+func (s *NodeOperationService) BulkUpdateNodeTaints(ctx context.Context, clusterID uuid.UUID, selector string, change NodeTaintChange) ([]*NodeMetadataResult, error) {
+	if err := validateTaintChange(change); err != nil {
+		return nil, err
+	}
+	return s.bulkUpdate(ctx, clusterID, selector, func(node *corev1.Node) (interface{}, interface{}) {
+		return applyTaintChange(node, change)
+	})
+}
+
+// bulkUpdate 按选择器逐个修改节点
+func (s *NodeOperationService) bulkUpdate(ctx context.Context, clusterID uuid.UUID, selector string, mutate func(*corev1.Node) (interface{}, interface{})) ([]*NodeMetadataResult, error) {
+	if strings.TrimSpace(selector) == "" {
+		return nil, fmt.Errorf("%w: selector is required for bulk updates", ErrInvalidNodeMetadata)
+	}
+	if _, err := labels.Parse(selector); err != nil {
+		return nil, fmt.Errorf("%w: invalid selector: %v", ErrInvalidNodeMetadata, err)
+	}
+
+	clientset, err := s.clientForCluster(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	results := make([]*NodeMetadataResult, 0, len(nodes.Items))
+	for _, node := range nodes.Items {
+		results = append(results, updateNode(ctx, clientset, node.Name, mutate))
+	}
+	return results, nil
+}
+
+// updateNode 读取节点、应用修改并更新，版本冲突时重试
+func updateNode(ctx context.Context, clientset kubernetes.Interface, nodeName string, mutate func(*corev1.Node) (interface{}, interface{})) *NodeMetadataResult {
+	result := &NodeMetadataResult{NodeName: nodeName}
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node, err := clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		result.Old, result.New = mutate(node)
+		_, err = clientset.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			err = ErrNodeNotFound
+		}
+		result.Error = err.Error()
+		result.Old, result.New = nil, nil
+		return result
+	}
+	result.Success = true
+	return result
+}
+
+// applyLabelChange 修改节点标签，返回修改前后的标签
+func applyLabelChange(node *corev1.Node, change NodeLabelChange) (interface{}, interface{}) {
+	old := make(map[string]string, len(node.Labels))
+	for key, value := range node.Labels {
+		old[key] = value
+	}
+	if node.Labels == nil {
+		node.Labels = map[string]string{}
+	}
+
+	switch change.Operation {
+	case NodeMetadataAdd:
+		for key, value := range change.Labels {
+			node.Labels[key] = value
+		}
+	case NodeMetadataRemove:
+		for _, key := range change.Keys {
+			delete(node.Labels, key)
+		}
+	case NodeMetadataReplace:
+		for key := range node.Labels {
+			if !isReservedNodeKey(key) {
+				delete(node.Labels, key)
+			}
+		}
+		for key, value := range change.Labels {
+			node.Labels[key] = value
+		}
+	}
+	return old, node.Labels
+}
+
+// applyTaintChange 修改节点污点，返回修改前后的污点
+func applyTaintChange(node *corev1.Node, change NodeTaintChange) (interface{}, interface{}) {
+	old := FormatNodeTaints(node.Spec.Taints)
+
+	switch change.Operation {
+	case NodeMetadataAdd:
+		for _, spec := range change.Taints {
+			taints := node.Spec.Taints[:0]
+			for _, taint := range node.Spec.Taints {
+				if taint.Key != spec.Key || string(taint.Effect) != spec.Effect {
+					taints = append(taints, taint)
+				}
+			}
+			node.Spec.Taints = append(taints, corev1.Taint{Key: spec.Key, Value: spec.Value, Effect: corev1.TaintEffect(spec.Effect)})
+		}
+	case NodeMetadataRemove:
+		taints := node.Spec.Taints[:0]
+		for _, taint := range node.Spec.Taints {
+			if !matchesTaintSpec(taint, change.Taints) {
+				taints = append(taints, taint)
+			}
+		}
+		node.Spec.Taints = taints
+	case NodeMetadataReplace:
+		var taints []corev1.Taint
+		for _, taint := range node.Spec.Taints {
+			if isReservedNodeKey(taint.Key) {
+				taints = append(taints, taint)
+			}
+		}
+		for _, spec := range change.Taints {
+			taints = append(taints, corev1.Taint{Key: spec.Key, Value: spec.Value, Effect: corev1.TaintEffect(spec.Effect)})
+		}
+		node.Spec.Taints = taints
+	}
+	return old, FormatNodeTaints(node.Spec.Taints)
+}
+
+// matchesTaintSpec 污点是否匹配任一待删除项，未指定 effect 时按键匹配
+func matchesTaintSpec(taint corev1.Taint, specs []TaintSpec) bool {
+	for _, spec := range specs {
+		if taint.Key == spec.Key && (spec.Effect == "" || string(taint.Effect) == spec.Effect) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateLabelChange 校验标签语法与保留键
+func validateLabelChange(change NodeLabelChange) error {
+	switch change.Operation {
+	case NodeMetadataAdd, NodeMetadataReplace:
+		if change.Operation == NodeMetadataAdd && len(change.Labels) == 0 {
+			return fmt.Errorf("%w: labels are required", ErrInvalidNodeMetadata)
+		}
+		for key, value := range change.Labels {
+			if err := validateNodeKey(key); err != nil {
+				return err
+			}
+			if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+				return fmt.Errorf("%w: invalid value for label %s: %s", ErrInvalidNodeMetadata, key, strings.Join(errs, "; "))
+			}
+		}
+	case NodeMetadataRemove:
+		if len(change.Keys) == 0 {
+			return fmt.Errorf("%w: keys are required", ErrInvalidNodeMetadata)
+		}
+		for _, key := range change.Keys {
+			if err := validateNodeKey(key); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w: unknown operation %q", ErrInvalidNodeMetadata, change.Operation)
+	}
+	return nil
+}
+
+// validateTaintChange 校验污点语法、effect 与保留键
+func validateTaintChange(change NodeTaintChange) error {
+	switch change.Operation {
+	case NodeMetadataAdd, NodeMetadataRemove, NodeMetadataReplace:
+	default:
+		return fmt.Errorf("%w: unknown operation %q", ErrInvalidNodeMetadata, change.Operation)
+	}
+	if len(change.Taints) == 0 && change.Operation != NodeMetadataReplace {
+		return fmt.Errorf("%w: taints are required", ErrInvalidNodeMetadata)
+	}
+
+	for _, spec := range change.Taints {
+		if err := validateNodeKey(spec.Key); err != nil {
+			return err
+		}
+		if spec.Value != "" {
+			if errs := validation.IsValidLabelValue(spec.Value); len(errs) > 0 {
+				return fmt.Errorf("%w: invalid value for taint %s: %s", ErrInvalidNodeMetadata, spec.Key, strings.Join(errs, "; "))
+			}
+		}
+		switch corev1.TaintEffect(spec.Effect) {
+		case corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		case "":
+			if change.Operation != NodeMetadataRemove {
+				return fmt.Errorf("%w: effect is required for taint %s", ErrInvalidNodeMetadata, spec.Key)
+			}
+		default:
+			return fmt.Errorf("%w: invalid effect %q for taint %s", ErrInvalidNodeMetadata, spec.Effect, spec.Key)
+		}
+	}
+	return nil
+}
+
+// validateNodeKey 校验标签或污点键，保留键由 Kubernetes 组件维护不允许修改
+func validateNodeKey(key string) error {
+	if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+		return fmt.Errorf("%w: invalid key %q: %s", ErrInvalidNodeMetadata, key, strings.Join(errs, "; "))
+	}
+	if isReservedNodeKey(key) {
+		return fmt.Errorf("%w: key %s uses a reserved prefix", ErrInvalidNodeMetadata, key)
+	}
+	return nil
+}
+
+// isReservedNodeKey 键前缀属于 kubernetes.io 或 k8s.io 域（含 node-role.kubernetes.io 等子域）
+func isReservedNodeKey(key string) bool {
+	prefix, _, found := strings.Cut(key, "/")
+	if !found {
+		return false
+	}
+	for _, domain := range []string{"kubernetes.io", "k8s.io"} {
+		if prefix == domain || strings.HasSuffix(prefix, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatNodeTaints 将污点格式化为 key=value:effect 列表，按字母排序
+func FormatNodeTaints(taints []corev1.Taint) model.StringSlice {
+	result := make(model.StringSlice, 0, len(taints))
+	for _, taint := range taints {
+		entry := taint.Key
+		if taint.Value != "" {
+			entry += "=" + taint.Value
+		}
+		result = append(result, entry+":"+string(taint.Effect))
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/taichu-system/cluster-management/internal/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateLabelChange(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  NodeLabelChange
+		wantErr string
+	}{
+		{name: "add labels", change: NodeLabelChange{Operation: NodeMetadataAdd, Labels: map[string]string{"env": "prod", "example.com/zone": "a"}}},
+		{name: "add empty value", change: NodeLabelChange{Operation: NodeMetadataAdd, Labels: map[string]string{"gpu": ""}}},
+		{name: "add without labels", change: NodeLabelChange{Operation: NodeMetadataAdd}, wantErr: "labels are required"},
+		{name: "replace with no labels", change: NodeLabelChange{Operation: NodeMetadataReplace}},
+		{name: "remove keys", change: NodeLabelChange{Operation: NodeMetadataRemove, Keys: []string{"env"}}},
+		{name: "remove without keys", change: NodeLabelChange{Operation: NodeMetadataRemove}, wantErr: "keys are required"},
+		{name: "unknown operation", change: NodeLabelChange{Operation: "merge"}, wantErr: "unknown operation"},
+		{name: "invalid key", change: NodeLabelChange{Operation: NodeMetadataAdd, Labels: map[string]string{"bad key": "x"}}, wantErr: "invalid key"},
+		{name: "key too long", change: NodeLabelChange{Operation: NodeMetadataAdd, Labels: map[string]string{strings.Repeat("a", 64): "x"}}, wantErr: "invalid key"},
+		{name: "invalid value", change: NodeLabelChange{Operation: NodeMetadataAdd, Labels: map[string]string{"env": "prod env"}}, wantErr: "invalid value for label env"},
+		{
+			name:    "reserved kubernetes.io prefix",
+			change:  NodeLabelChange{Operation: NodeMetadataAdd, Labels: map[string]string{"kubernetes.io/hostname": "node-1"}},
+			wantErr: "reserved prefix",
+		},
+		{
+			name:    "reserved node-role subdomain",
+			change:  NodeLabelChange{Operation: NodeMetadataRemove, Keys: []string{"node-role.kubernetes.io/control-plane"}},
+			wantErr: "reserved prefix",
+		},
+		{
+			name:    "reserved k8s.io prefix",
+			change:  NodeLabelChange{Operation: NodeMetadataReplace, Labels: map[string]string{"k8s.io/owner": "x"}},
+			wantErr: "reserved prefix",
+		},
+		{name: "similar but unreserved prefix", change: NodeLabelChange{Operation: NodeMetadataAdd, Labels: map[string]string{"mykubernetes.io/team": "a"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkNodeMetadataError(t, validateLabelChange(tt.change), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateTaintChange(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  NodeTaintChange
+		wantErr string
+	}{
+		{name: "add taint", change: NodeTaintChange{Operation: NodeMetadataAdd, Taints: []TaintSpec{{Key: "dedicated", Value: "gpu", Effect: "NoSchedule"}}}},
+		{name: "add taint without value", change: NodeTaintChange{Operation: NodeMetadataAdd, Taints: []TaintSpec{{Key: "maintenance", Effect: "NoExecute"}}}},
+		{name: "add without taints", change: NodeTaintChange{Operation: NodeMetadataAdd}, wantErr: "taints are required"},
+		{name: "remove without taints", change: NodeTaintChange{Operation: NodeMetadataRemove}, wantErr: "taints are required"},
+		{name: "replace with no taints", change: NodeTaintChange{Operation: NodeMetadataReplace}},
+		{name: "unknown operation", change: NodeTaintChange{Operation: "merge"}, wantErr: "unknown operation"},
+		{name: "remove by key", change: NodeTaintChange{Operation: NodeMetadataRemove, Taints: []TaintSpec{{Key: "dedicated"}}}},
+		{name: "add without effect", change: NodeTaintChange{Operation: NodeMetadataAdd, Taints: []TaintSpec{{Key: "dedicated"}}}, wantErr: "effect is required"},
+		{name: "invalid effect", change: NodeTaintChange{Operation: NodeMetadataAdd, Taints: []TaintSpec{{Key: "dedicated", Effect: "NoRun"}}}, wantErr: "invalid effect"},
+		{name: "invalid value", change: NodeTaintChange{Operation: NodeMetadataAdd, Taints: []TaintSpec{{Key: "dedicated", Value: "a b", Effect: "NoSchedule"}}}, wantErr: "invalid value for taint"},
+		{name: "invalid key", change: NodeTaintChange{Operation: NodeMetadataAdd, Taints: []TaintSpec{{Key: "-bad", Effect: "NoSchedule"}}}, wantErr: "invalid key"},
+		{
+			name:    "reserved key",
+			change:  NodeTaintChange{Operation: NodeMetadataRemove, Taints: []TaintSpec{{Key: "node.kubernetes.io/unschedulable"}}},
+			wantErr: "reserved prefix",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkNodeMetadataError(t, validateTaintChange(tt.change), tt.wantErr)
+		})
+	}
+}
+
+func checkNodeMetadataError(t *testing.T, err error, wantErr string) {
+	t.Helper()
+	if wantErr == "" {
+		if err != nil {
+			t.Fatalf("error = %v, want nil", err)
+		}
+		return
+	}
+	if !errors.Is(err, ErrInvalidNodeMetadata) {
+		t.Fatalf("error = %v, want ErrInvalidNodeMetadata", err)
+	}
+	if !strings.Contains(err.Error(), wantErr) {
+		t.Errorf("error = %q, want it to contain %q", err, wantErr)
+	}
+}
+
+func TestApplyLabelChange(t *testing.T) {
+	existing := map[string]string{"env": "dev", "team": "a", "kubernetes.io/hostname": "node-1"}
+	tests := []struct {
+		name   string
+		change NodeLabelChange
+		want   map[string]string
+	}{
+		{
+			name:   "add overrides and keeps others",
+			change: NodeLabelChange{Operation: NodeMetadataAdd, Labels: map[string]string{"env": "prod", "zone": "z1"}},
+			want:   map[string]string{"env": "prod", "team": "a", "zone": "z1", "kubernetes.io/hostname": "node-1"},
+		},
+		{
+			name:   "remove keys",
+			change: NodeLabelChange{Operation: NodeMetadataRemove, Keys: []string{"team", "missing"}},
+			want:   map[string]string{"env": "dev", "kubernetes.io/hostname": "node-1"},
+		},
+		{
+			name:   "replace keeps reserved labels",
+			change: NodeLabelChange{Operation: NodeMetadataReplace, Labels: map[string]string{"zone": "z1"}},
+			want:   map[string]string{"zone": "z1", "kubernetes.io/hostname": "node-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels := make(map[string]string, len(existing))
+			for key, value := range existing {
+				labels[key] = value
+			}
+			node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Labels: labels}}
+
+			old, updated := applyLabelChange(node, tt.change)
+			if !reflect.DeepEqual(old, existing) {
+				t.Errorf("old = %v, want %v", old, existing)
+			}
+			if !reflect.DeepEqual(updated, tt.want) {
+				t.Errorf("new = %v, want %v", updated, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyTaintChange(t *testing.T) {
+	existing := []corev1.Taint{
+		{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoExecute},
+		{Key: "node.kubernetes.io/unschedulable", Effect: corev1.TaintEffectNoSchedule},
+	}
+	tests := []struct {
+		name   string
+		change NodeTaintChange
+		want   []string
+	}{
+		{
+			name:   "add replaces same key and effect",
+			change: NodeTaintChange{Operation: NodeMetadataAdd, Taints: []TaintSpec{{Key: "dedicated", Value: "cpu", Effect: "NoSchedule"}}},
+			want:   []string{"dedicated=cpu:NoSchedule", "dedicated=gpu:NoExecute", "node.kubernetes.io/unschedulable:NoSchedule"},
+		},
+		{
+			name:   "remove by key matches all effects",
+			change: NodeTaintChange{Operation: NodeMetadataRemove, Taints: []TaintSpec{{Key: "dedicated"}}},
+			want:   []string{"node.kubernetes.io/unschedulable:NoSchedule"},
+		},
+		{
+			name:   "remove by key and effect",
+			change: NodeTaintChange{Operation: NodeMetadataRemove, Taints: []TaintSpec{{Key: "dedicated", Effect: "NoExecute"}}},
+			want:   []string{"dedicated=gpu:NoSchedule", "node.kubernetes.io/unschedulable:NoSchedule"},
+		},
+		{
+			name:   "replace keeps reserved taints",
+			change: NodeTaintChange{Operation: NodeMetadataReplace, Taints: []TaintSpec{{Key: "maintenance", Effect: "NoExecute"}}},
+			want:   []string{"maintenance:NoExecute", "node.kubernetes.io/unschedulable:NoSchedule"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &corev1.Node{Spec: corev1.NodeSpec{Taints: append([]corev1.Taint(nil), existing...)}}
+			old, updated := applyTaintChange(node, tt.change)
+			if want := FormatNodeTaints(existing); !reflect.DeepEqual(old, want) {
+				t.Errorf("old = %v, want %v", old, want)
+			}
+			if got := updated.(model.StringSlice); !reflect.DeepEqual([]string(got), tt.want) {
+				t.Errorf("new = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/constants"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"github.com/taichu-system/cluster-management/internal/repository"
+	"gorm.io/gorm"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	// ErrNodeNotFound 集群中不存在该节点
+	ErrNodeNotFound = errors.New("node not found")
+	// ErrDrainInProgress 节点已有进行中的排空任务
+	ErrDrainInProgress = errors.New("node drain already in progress")
+	// ErrDrainTaskNotFound 排空任务不存在
+	ErrDrainTaskNotFound = errors.New("drain task not found")
+)
+
+// NodeOperationService 节点运维操作服务：封锁、解除封锁与排空
+type NodeOperationService struct {
+	clusterRepo       *repository.ClusterRepository
+	drainRepo         *repository.NodeDrainRepository
+	clusterManager    *ClusterManager
+	encryptionService *EncryptionService
+	auditService      *AuditService
+}
+
+// NewNodeOperationService 创建节点运维操作服务
+func NewNodeOperationService(
+	clusterRepo *repository.ClusterRepository,
+	drainRepo *repository.NodeDrainRepository,
+	clusterManager *ClusterManager,
+	encryptionService *EncryptionService,
+	auditService *AuditService,
+) *NodeOperationService {
+	return &NodeOperationService{
+		clusterRepo:       clusterRepo,
+		drainRepo:         drainRepo,
+		clusterManager:    clusterManager,
+		encryptionService: encryptionService,
+		auditService:      auditService,
+	}
+}
+
+// Cordon 将节点标记为不可调度
+func (s *NodeOperationService) Cordon(ctx context.Context, clusterID uuid.UUID, nodeName string) error {
+	return s.setUnschedulable(ctx, clusterID, nodeName, true)
+}
+
+// Uncordon 恢复节点调度
+func (s *NodeOperationService) Uncordon(ctx context.Context, clusterID uuid.UUID, nodeName string) error {
+	return s.setUnschedulable(ctx, clusterID, nodeName, false)
+}
+
+// setUnschedulable 设置节点调度状态
+func (s *NodeOperationService) setUnschedulable(ctx context.Context, clusterID uuid.UUID, nodeName string, unschedulable bool) error {
+	clientset, err := s.clientForCluster(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	if err := setNodeUnschedulable(ctx, clientset, nodeName, unschedulable); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ErrNodeNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// RecoverInterrupted 服务启动时将中断的排空任务标记为失败，节点保持不可调度，可重新发起排空
+func (s *NodeOperationService) RecoverInterrupted() (int64, error) {
+	return s.drainRepo.MarkInterrupted()
+}
+
+// StartDrain 创建排空任务并异步执行
+func (s *NodeOperationService) StartDrain(clusterID uuid.UUID, nodeName string, opts DrainOptions, operator string) (*model.NodeDrainTask, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	clientset, err := s.clientForCluster(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, ErrNodeNotFound
+		}
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+
+	active, err := s.drainRepo.ExistsActive(clusterID, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	if active {
+		return nil, ErrDrainInProgress
+	}
+
+	optionsMap, _ := toJSONValue(opts).(map[string]interface{})
+	task := &model.NodeDrainTask{
+		ClusterID:   clusterID,
+		NodeName:    nodeName,
+		Options:     optionsMap,
+		Status:      constants.StatusPending,
+		CurrentStep: "Waiting to start",
+		CreatedBy:   operator,
+	}
+	if err := s.drainRepo.Create(task); err != nil {
+		return nil, fmt.Errorf("failed to create drain task: %w", err)
+	}
+
+	go s.executeDrain(task.ID, clientset, opts)
+
+	return task, nil
+}
+
+// GetDrainTask 获取排空任务
+func (s *NodeOperationService) GetDrainTask(id uuid.UUID) (*model.NodeDrainTask, error) {
+	task, err := s.drainRepo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDrainTaskNotFound
+	}
+	return task, err
+}
+
+// ListDrainTasks 获取节点的排空任务
+func (s *NodeOperationService) ListDrainTasks(clusterID uuid.UUID, nodeName string) ([]*model.NodeDrainTask, error) {
+	return s.drainRepo.ListByNode(clusterID, nodeName)
+}
+
+// executeDrain 执行排空任务（异步）
+func (s *NodeOperationService) executeDrain(id uuid.UUID, clientset kubernetes.Interface, opts DrainOptions) {
+	task, err := s.drainRepo.GetByID(id)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	s.drainRepo.UpdateFields(id, map[string]interface{}{
+		"status":       constants.StatusRunning,
+		"current_step": "Draining node",
+		"started_at":   &now,
+	})
+
+	logf := func(line string, isError bool) {
+		appendTimestampedLog(func(log string) { s.drainRepo.AppendLogs(id, log) }, line, isError)
+	}
+	result, err := drainNode(context.Background(), clientset, task.NodeName, opts, logf)
+
+	completedAt := time.Now()
+	fields := map[string]interface{}{
+		"completed_at": &completedAt,
+	}
+	if result != nil {
+		fields["evicted_pods"] = model.StringSlice(result.Evicted)
+		fields["blocked_pods"] = model.StringSlice(result.Blocked)
+		fields["skipped_pods"] = model.StringSlice(result.Skipped)
+	}
+	status := constants.StatusSuccess
+	if err != nil {
+		status = constants.StatusFailed
+		fields["status"] = constants.StatusFailed
+		fields["current_step"] = "Drain failed, node remains cordoned"
+		fields["error_msg"] = err.Error()
+		logf(err.Error(), true)
+	} else {
+		fields["status"] = constants.StatusSuccess
+		fields["current_step"] = "Node drained"
+	}
+	s.drainRepo.UpdateFields(id, fields)
+
+	if s.auditService != nil {
+		details := map[string]interface{}{
+			"drain_task_id": id.String(),
+			"node_name":     task.NodeName,
+		}
+		if result != nil {
+			details["evicted"] = len(result.Evicted)
+			details["blocked"] = len(result.Blocked)
+		}
+		if err != nil {
+			details["error"] = err.Error()
+		}
+		s.auditService.CreateAuditEvent(
+			task.ClusterID,
+			constants.EventTypeUpdate,
+			"drain_node",
+			constants.ResourceTypeNode,
+			task.NodeName,
+			task.CreatedBy,
+			"",
+			"",
+			nil,
+			nil,
+			details,
+			status,
+		)
+	}
+}
+
+// clientForCluster 获取集群客户端
+func (s *NodeOperationService) clientForCluster(ctx context.Context, clusterID uuid.UUID) (*kubernetes.Clientset, error) {
+	cluster, err := s.clusterRepo.GetByID(clusterID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClusterNotFound
+		}
+		return nil, err
+	}
+
+	kubeconfig, err := s.encryptionService.Decrypt(cluster.KubeconfigEncrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt kubeconfig: %w", err)
+	}
+
+	return s.clusterManager.GetClientForCluster(ctx, cluster.ID, kubeconfig)
+}
@@ -124,7 +124,7 @@ func (s *NodeService) SyncNodesFromKubernetes(clusterID string) error {
 	}
 
 	ctx := context.Background()
-	clientset, err := s.clusterManager.GetClient(ctx, kubeconfig)
+	clientset, err := s.clusterManager.GetClientForCluster(ctx, cluster.ID, kubeconfig)
 	if err != nil {
 		return fmt.Errorf("failed to get client: %w", err)
 	}
@@ -151,7 +151,9 @@ func (s *NodeService) SyncNodesFromKubernetes(clusterID string) error {
 			MemoryUsedBytes: 0, // 需要从metrics-server获取
 			PodCount:        podCount,
 			Labels:          convertToJSONMap(k8sNode.Labels),
+			Taints:          FormatNodeTaints(k8sNode.Spec.Taints),
 		}
+		ApplyNodeSystemInfo(node, k8sNode.Status.NodeInfo)
 
 		if err := s.nodeRepo.UpsertSingle(node); err != nil {
 			return fmt.Errorf("failed to upsert node: %w", err)
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/taichu-system/cluster-management/internal/model"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// 内置驱动名称
+const (
+	ProvisionerKK      = "kk"
+	ProvisionerKubeadm = "kubeadm"
+	ProvisionerFake    = "fake"
+)
+
+// ProvisionReporter 驱动执行过程中的日志与进度上报
+type ProvisionReporter interface {
+	Log(line string, isError bool)
+	Progress(progress int, step string)
+}
+
+// ProvisionOperation 一次驱动操作的上下文
+type ProvisionOperation struct {
+	ClusterName   string
+	NetworkPlugin string
+	ConfigYaml    string                  // 已渲染且不含凭据的配置
+	Machines      []*model.Machine        // 集群当前全部机器
+	Credentials   map[string]*SSHAuth     // 以机器名为键的明文凭据，仅在执行期间存在于内存
+	Addons        []AddonConfig           // 需要额外安装的插件，kk 驱动已渲染在配置中
+	RegistryAuths map[string]RegistryAuth // 镜像仓库认证，与SSH凭据一样仅在执行期间注入
+	ArtifactPath  string
+	WithPackages  bool
+	AutoApprove   bool
+	Reporter      ProvisionReporter
+}
+
+// Provisioner 集群部署驱动
+type Provisioner interface {
+	// Name 驱动名称，记录在创建任务上
+	Name() string
+	// RenderConfig 渲染驱动配置，结果不得包含凭据
+	RenderConfig(req CreateClusterRequest, machines []*model.Machine) (string, error)
+	// Create 创建集群
+	Create(ctx context.Context, op *ProvisionOperation) error
+	// Delete 删除集群并重置全部节点
+	Delete(ctx context.Context, op *ProvisionOperation) error
+	// FetchKubeconfig 获取管理员 kubeconfig，server 指向平台可访问的控制平面地址
+	FetchKubeconfig(ctx context.Context, op *ProvisionOperation) (string, error)
+}
+
+// ProvisionerRegistry 驱动注册表
+type ProvisionerRegistry struct {
+	provisioners  map[string]Provisioner
+	defaultDriver string
+}
+
+// NewProvisionerRegistry 创建驱动注册表
+func NewProvisionerRegistry(defaultDriver string, provisioners ...Provisioner) *ProvisionerRegistry {
+	r := &ProvisionerRegistry{
+		provisioners:  make(map[string]Provisioner, len(provisioners)),
+		defaultDriver: defaultDriver,
+	}
+	for _, p := range provisioners {
+		r.provisioners[p.Name()] = p
+	}
+	if r.defaultDriver == "" {
+		r.defaultDriver = ProvisionerKK
+	}
+	return r
+}
+
+// Get 获取驱动，name 为空时返回默认驱动
+func (r *ProvisionerRegistry) Get(name string) (Provisioner, error) {
+	if name == "" {
+		name = r.defaultDriver
+	}
+	p, ok := r.provisioners[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown provisioner: %s", name)
+	}
+	return p, nil
+}
+
+// Names 返回已注册的驱动名称
+func (r *ProvisionerRegistry) Names() []string {
+	names := make([]string, 0, len(r.provisioners))
+	for name := range r.provisioners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefaultDriver 返回默认驱动名称
+func (r *ProvisionerRegistry) DefaultDriver() string {
+	return r.defaultDriver
+}
+
+// fetchAdminKubeconfig 从首个控制平面读取 admin.conf，并将 server 改写为该节点地址
+func fetchAdminKubeconfig(sshService *SSHService, op *ProvisionOperation) (string, error) {
+	master, err := firstMaster(op.Machines)
+	if err != nil {
+		return "", err
+	}
+	auth, ok := op.Credentials[master.Name]
+	if !ok || auth == nil {
+		return "", fmt.Errorf("no credential for host %s", master.Name)
+	}
+
+	client, err := sshService.ConnectWithAuth(master.IPAddress, auth)
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	cmd := "cat " + kubeadmAdminConfig
+	if auth.Username != "root" {
+		cmd = "sudo -n " + cmd
+	}
+	out, err := client.ExecuteCommand(cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s on %s: %w", kubeadmAdminConfig, master.Name, err)
+	}
+
+	return rewriteKubeconfigServer(out, "https://"+net.JoinHostPort(master.IPAddress, "6443"))
+}
+
+// rewriteKubeconfigServer 将 kubeconfig 中所有集群的 server 替换为指定地址
+func rewriteKubeconfigServer(kubeconfig, server string) (string, error) {
+	config, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return "", fmt.Errorf("invalid kubeconfig: %w", err)
+	}
+	if len(config.Clusters) == 0 {
+		return "", fmt.Errorf("kubeconfig contains no clusters")
+	}
+	for _, cluster := range config.Clusters {
+		cluster.Server = server
+	}
+
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
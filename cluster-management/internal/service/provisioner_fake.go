@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/taichu-system/cluster-management/internal/model"
+)
+
+// FakeProvisioner 不连接任何主机的模拟驱动，用于离线测试创建流程
+type FakeProvisioner struct {
+	mu     sync.Mutex
+	calls  []string
+	errors map[string]error
+}
+
+// NewFakeProvisioner 创建模拟驱动
+func NewFakeProvisioner() *FakeProvisioner {
+	return &FakeProvisioner{
+		errors: make(map[string]error),
+	}
+}
+
+// Name 驱动名称
+func (p *FakeProvisioner) Name() string {
+	return ProvisionerFake
+}
+
+// FailOn 使指定操作返回错误，err 为 nil 时恢复正常
+func (p *FakeProvisioner) FailOn(operation string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err == nil {
+		delete(p.errors, operation)
+		return
+	}
+	p.errors[operation] = err
+}
+
+// Calls 返回已执行的操作记录
+func (p *FakeProvisioner) Calls() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.calls...)
+}
+
+// RenderConfig 渲染仅列出节点的简单配置
+func (p *FakeProvisioner) RenderConfig(req CreateClusterRequest, machines []*model.Machine) (string, error) {
+	if err := p.record("render"); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "driver: fake\nclusterName: %s\nhosts:\n", req.ClusterName)
+	for _, machine := range machines {
+		fmt.Fprintf(&b, "- name: %s\n  address: %s\n  role: %s\n", machine.Name, machine.IPAddress, machine.Role)
+	}
+	return b.String(), nil
+}
+
+// Create 模拟创建集群
+func (p *FakeProvisioner) Create(ctx context.Context, op *ProvisionOperation) error {
+	return p.simulate(ctx, op, "create", op.Machines)
+}
+
+// Delete 模拟删除集群
+func (p *FakeProvisioner) Delete(ctx context.Context, op *ProvisionOperation) error {
+	return p.simulate(ctx, op, "delete", op.Machines)
+}
+
+// FetchKubeconfig 返回指向首个控制平面的模拟 kubeconfig
+func (p *FakeProvisioner) FetchKubeconfig(ctx context.Context, op *ProvisionOperation) (string, error) {
+	if err := p.record("kubeconfig"); err != nil {
+		return "", err
+	}
+	master, err := firstMaster(op.Machines)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: %[1]s
+  cluster:
+    server: https://%[2]s:6443
+    insecure-skip-tls-verify: true
+contexts:
+- name: %[1]s
+  context:
+    cluster: %[1]s
+    user: fake-admin
+current-context: %[1]s
+users:
+- name: fake-admin
+  user:
+    token: fake-token
+`, op.ClusterName, master.IPAddress), nil
+}
+
+// simulate 逐节点上报日志与进度，校验每个节点都有凭据
+func (p *FakeProvisioner) simulate(ctx context.Context, op *ProvisionOperation, operation string, machines []*model.Machine) error {
+	if err := p.record(operation); err != nil {
+		reportLog(op, fmt.Sprintf("fake %s failed: %v", operation, err), true)
+		return err
+	}
+
+	for i, machine := range machines {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, ok := op.Credentials[machine.Name]; !ok {
+			return fmt.Errorf("no credential for host %s", machine.Name)
+		}
+		reportLog(op, fmt.Sprintf("fake %s on %s (%s)", operation, machine.Name, machine.IPAddress), false)
+		reportProgress(op, (i+1)*100/(len(machines)+1), fmt.Sprintf("Fake %s %s", operation, machine.Name))
+	}
+
+	reportProgress(op, 100, fmt.Sprintf("Fake %s completed", operation))
+	return nil
+}
+
+// record 记录操作并返回预设错误
+func (p *FakeProvisioner) record(operation string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, operation)
+	return p.errors[operation]
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/taichu-system/cluster-management/internal/model"
+)
+
+// KKProvisioner 基于 KubeKey(kk) 命令行的部署驱动
+type KKProvisioner struct {
+	configGen  *ConfigGenerator
+	sshService *SSHService
+	binary     string
+}
+
+// NewKKProvisioner 创建 kk 驱动
+func NewKKProvisioner(configGen *ConfigGenerator) *KKProvisioner {
+	return &KKProvisioner{
+		configGen:  configGen,
+		sshService: NewSSHService(),
+		binary:     "kk",
+	}
+}
+
+// Name 驱动名称
+func (p *KKProvisioner) Name() string {
+	return ProvisionerKK
+}
+
+// RenderConfig 渲染 KubeKey 配置
+func (p *KKProvisioner) RenderConfig(req CreateClusterRequest, machines []*model.Machine) (string, error) {
+	return p.configGen.GenerateConfig(req, machines)
+}
+
+// Create 执行 kk create cluster
+func (p *KKProvisioner) Create(ctx context.Context, op *ProvisionOperation) error {
+	args := []string{"create", "cluster"}
+	if op.ArtifactPath != "" {
+		args = append(args, "-a", op.ArtifactPath)
+	}
+	if op.WithPackages {
+		args = append(args, "--with-packages")
+	}
+	if op.AutoApprove {
+		args = append(args, "--yes")
+	}
+	return p.run(ctx, op, args, "")
+}
+
+// Delete 执行 kk delete cluster
+func (p *KKProvisioner) Delete(ctx context.Context, op *ProvisionOperation) error {
+	return p.run(ctx, op, []string{"delete", "cluster"}, "yes\n")
+}
+
+// FetchKubeconfig 读取首个控制平面的 admin.conf，替换 kk 默认的 lb.kubesphere.local 地址
+func (p *KKProvisioner) FetchKubeconfig(ctx context.Context, op *ProvisionOperation) (string, error) {
+	return fetchAdminKubeconfig(p.sshService, op)
+}
+
+// run 注入凭据后写入临时配置并执行 kk 命令
+func (p *KKProvisioner) run(ctx context.Context, op *ProvisionOperation, args []string, stdin string) error {
+	configYaml, err := p.configGen.InjectCredentials(op.ConfigYaml, op.Credentials)
+	if err != nil {
+		return fmt.Errorf("failed to inject credentials: %w", err)
+	}
+	configYaml, err = p.configGen.InjectRegistryAuths(configYaml, op.RegistryAuths)
+	if err != nil {
+		return fmt.Errorf("failed to inject registry auths: %w", err)
+	}
+
+	configFile, err := createTempConfigFile(configYaml)
+	if err != nil {
+		return fmt.Errorf("failed to create config file: %w", err)
+	}
+	defer os.Remove(configFile)
+
+	args = append(args, "-f", configFile)
+	cmd := exec.CommandContext(ctx, p.binary, args...)
+	cmd.Dir = "."
+	cmd.Env = append(os.Environ(),
+		"KK_ZONE=cn", // 使用中国区域
+	)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stderr pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start kk: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		p.readOutput(op.Reporter, stdout, false)
+	}()
+	go func() {
+		defer wg.Done()
+		p.readOutput(op.Reporter, stderr, true)
+	}()
+	wg.Wait()
+
+	return cmd.Wait()
+}
+
+// readOutput 读取 kk 输出并上报日志与进度
+func (p *KKProvisioner) readOutput(reporter ProvisionReporter, reader io.Reader, isError bool) {
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if reporter == nil {
+			continue
+		}
+		reporter.Log(line, isError)
+		if progress, step := kkProgressFromLog(line); progress > 0 {
+			reporter.Progress(progress, step)
+		}
+	}
+}
+
+// kkProgressFromLog 从 kk 日志中解析进度
+func kkProgressFromLog(logLine string) (int, string) {
+	switch {
+	case strings.Contains(logLine, "Checking an existing installation"):
+		return 5, "Checking existing installation"
+	case strings.Contains(logLine, "Preparing for installation"):
+		return 10, "Preparing for installation"
+	case strings.Contains(logLine, "Downloading"):
+		return 20, "Downloading required packages"
+	case strings.Contains(logLine, "downloading kubeadm"):
+		return 30, "Downloading kubeadm binary"
+	case strings.Contains(logLine, "pulling images"):
+		return 40, "Pulling container images"
+	case strings.Contains(logLine, "Installing kubesphere"):
+		return 90, "Installing KubeSphere"
+	case strings.Contains(logLine, "Installing"):
+		return 60, "Installing Kubernetes components"
+	case strings.Contains(logLine, "Configuring"):
+		return 80, "Configuring cluster"
+	case strings.Contains(logLine, "successfully installed") || strings.Contains(logLine, "KubeSphere is successfully installed"):
+		return 100, "Installation completed"
+	}
+	return 0, ""
+}
+
+// createTempConfigFile 创建临时配置文件（os.CreateTemp 默认权限为0600）
+func createTempConfigFile(configYaml string) (string, error) {
+	tmpFile, err := os.CreateTemp("", "kubekey-config-*.yaml")
+	if err != nil {
+		return "", err
+	}
+	defer tmpFile.Close()
+
+	if _, err := tmpFile.WriteString(configYaml); err != nil {
+		os.Remove(tmpFile.Name())
+		return "", err
+	}
+
+	return tmpFile.Name(), nil
+}
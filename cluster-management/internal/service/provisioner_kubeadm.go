@@ -0,0 +1,424 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/taichu-system/cluster-management/internal/model"
+)
+
+const (
+	kubeadmConfigPath  = "/etc/kubernetes/kubeadm-config.yaml"
+	kubeadmUploadPath  = "/tmp/taichu-kubeadm-config.yaml"
+	kubeadmAdminConfig = "/etc/kubernetes/admin.conf"
+)
+
+// cniManifests 网络插件清单地址
+var cniManifests = map[string]string{
+	"calico":  "https://raw.githubusercontent.com/projectcalico/calico/v3.26.1/manifests/calico.yaml",
+	"flannel": "https://github.com/flannel-io/flannel/releases/latest/download/kube-flannel.yml",
+}
+
+// KubeadmProvisioner 通过SSH在各节点执行 kubeadm 的部署驱动
+// 要求节点已预装 kubeadm/kubelet/kubectl 及容器运行时
+type KubeadmProvisioner struct {
+	configGen  *ConfigGenerator
+	sshService *SSHService
+}
+
+// NewKubeadmProvisioner 创建 kubeadm 驱动
+func NewKubeadmProvisioner(configGen *ConfigGenerator) *KubeadmProvisioner {
+	return &KubeadmProvisioner{
+		configGen:  configGen,
+		sshService: NewSSHService(),
+	}
+}
+
+// Name 驱动名称
+func (p *KubeadmProvisioner) Name() string {
+	return ProvisionerKubeadm
+}
+
+// RenderConfig 渲染 kubeadm 配置
+func (p *KubeadmProvisioner) RenderConfig(req CreateClusterRequest, machines []*model.Machine) (string, error) {
+	return p.configGen.GenerateKubeadmConfig(req, machines)
+}
+
+// Create 初始化首个控制平面，安装网络插件并加入其余节点
+func (p *KubeadmProvisioner) Create(ctx context.Context, op *ProvisionOperation) error {
+	masters, workers := splitMachinesByRole(op.Machines)
+	if len(masters) == 0 {
+		return fmt.Errorf("at least one master node is required")
+	}
+
+	reportProgress(op, 5, "Preparing nodes")
+	for _, machine := range append(append([]*model.Machine{}, masters...), workers...) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := p.prepareNode(op, machine); err != nil {
+			return err
+		}
+	}
+
+	reportProgress(op, 20, "Initializing control plane")
+	first := masters[0]
+	if err := p.withSession(op, first, func(s *kubeadmSession) error {
+		if err := s.writeConfig(op.ConfigYaml); err != nil {
+			return err
+		}
+		if _, err := s.run(fmt.Sprintf("kubeadm init --config %s --upload-certs", kubeadmConfigPath)); err != nil {
+			return fmt.Errorf("kubeadm init failed on %s: %w", first.Name, err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	reportProgress(op, 50, "Installing network plugin")
+	if err := p.installNetworkPlugin(op, first); err != nil {
+		return err
+	}
+
+	if err := p.joinNodes(ctx, op, first, masters[1:], workers); err != nil {
+		return err
+	}
+
+	if len(op.Addons) > 0 {
+		reportProgress(op, 90, "Installing addons")
+		if err := p.installAddons(op, first); err != nil {
+			return err
+		}
+	}
+
+	reportProgress(op, 100, "Cluster created")
+	return nil
+}
+
+// Delete 在所有节点执行 kubeadm reset 并清理数据目录
+func (p *KubeadmProvisioner) Delete(ctx context.Context, op *ProvisionOperation) error {
+	var failed []string
+	for i, machine := range op.Machines {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		reportProgress(op, (i+1)*100/(len(op.Machines)+1), fmt.Sprintf("Resetting node %s", machine.Name))
+		if err := p.resetNode(op, machine); err != nil {
+			reportLog(op, err.Error(), true)
+			failed = append(failed, machine.Name)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to reset nodes: %s", strings.Join(failed, ", "))
+	}
+	reportProgress(op, 100, "Cluster deleted")
+	return nil
+}
+
+// FetchKubeconfig 读取首个控制平面的 admin.conf
+func (p *KubeadmProvisioner) FetchKubeconfig(ctx context.Context, op *ProvisionOperation) (string, error) {
+	return fetchAdminKubeconfig(p.sshService, op)
+}
+
+// prepareNode 检查二进制、设置内核参数并写入 kubelet 额外参数
+func (p *KubeadmProvisioner) prepareNode(op *ProvisionOperation, machine *model.Machine) error {
+	kubeletArgs, err := kubeletExtraArgsFromConfig(op.ConfigYaml)
+	if err != nil {
+		return err
+	}
+
+	return p.withSession(op, machine, func(s *kubeadmSession) error {
+		if _, err := s.run("command -v kubeadm && command -v kubelet && command -v kubectl"); err != nil {
+			return fmt.Errorf("kubeadm, kubelet and kubectl must be installed on %s: %w", machine.Name, err)
+		}
+		prepare := strings.Join([]string{
+			"swapoff -a",
+			"modprobe overlay",
+			"modprobe br_netfilter",
+			"sysctl -w net.ipv4.ip_forward=1 net.bridge.bridge-nf-call-iptables=1 net.bridge.bridge-nf-call-ip6tables=1",
+			"systemctl enable kubelet",
+		}, " && ")
+		if _, err := s.run(prepare); err != nil {
+			return fmt.Errorf("failed to prepare node %s: %w", machine.Name, err)
+		}
+
+		// kubeadm join 不接受 kubelet 参数，通过发行版的 kubelet 环境文件传入
+		if len(kubeletArgs) > 0 {
+			flags := make([]string, 0, len(kubeletArgs))
+			for _, arg := range sortedArgs(kubeletArgs) {
+				flags = append(flags, "--"+arg)
+			}
+			env := shellQuote(fmt.Sprintf("KUBELET_EXTRA_ARGS=\"%s\"", strings.Join(flags, " ")))
+			cmd := fmt.Sprintf("for f in /etc/default/kubelet /etc/sysconfig/kubelet; do if [ -d $(dirname $f) ]; then echo %s > $f; fi; done", env)
+			if _, err := s.run(cmd); err != nil {
+				return fmt.Errorf("failed to write kubelet args on %s: %w", machine.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// installNetworkPlugin 在首个控制平面安装网络插件
+func (p *KubeadmProvisioner) installNetworkPlugin(op *ProvisionOperation, first *model.Machine) error {
+	plugin := op.NetworkPlugin
+	manifest := cniManifests[plugin]
+	if manifest == "" {
+		reportLog(op, fmt.Sprintf("No manifest for network plugin %q, skipping CNI installation", plugin), false)
+		return nil
+	}
+
+	return p.withSession(op, first, func(s *kubeadmSession) error {
+		if _, err := s.kubectl("apply -f " + manifest); err != nil {
+			return fmt.Errorf("failed to install network plugin %s: %w", plugin, err)
+		}
+		return nil
+	})
+}
+
+// installAddons 在首个控制平面安装插件，Chart 插件需要节点上有 helm
+func (p *KubeadmProvisioner) installAddons(op *ProvisionOperation, first *model.Machine) error {
+	return p.withSession(op, first, func(s *kubeadmSession) error {
+		for _, addon := range op.Addons {
+			namespace := addon.Namespace
+			if namespace == "" {
+				namespace = "default"
+			}
+
+			for _, path := range addon.YamlPaths {
+				if _, err := s.kubectl(fmt.Sprintf("apply -n %s -f %s", shellQuote(namespace), shellQuote(path))); err != nil {
+					return fmt.Errorf("failed to install addon %s: %w", addon.Name, err)
+				}
+			}
+
+			if addon.Chart != nil {
+				cmd := fmt.Sprintf("helm upgrade --install %s %s --repo %s --namespace %s --create-namespace --kubeconfig %s",
+					shellQuote(addon.Name), shellQuote(addon.Chart.Name), shellQuote(addon.Chart.Repo), shellQuote(namespace), kubeadmAdminConfig)
+				if addon.Chart.Version != "" {
+					cmd += " --version " + shellQuote(addon.Chart.Version)
+				}
+				for _, value := range addon.Chart.Values {
+					cmd += " --set " + shellQuote(value)
+				}
+				if _, err := s.run(cmd); err != nil {
+					return fmt.Errorf("failed to install addon %s: %w", addon.Name, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// joinNodes 生成加入命令并依次加入控制平面与工作节点
+func (p *KubeadmProvisioner) joinNodes(ctx context.Context, op *ProvisionOperation, first *model.Machine, masters, workers []*model.Machine) error {
+	if len(masters) == 0 && len(workers) == 0 {
+		return nil
+	}
+
+	var joinCommand, certificateKey string
+	if err := p.withSession(op, first, func(s *kubeadmSession) error {
+		out, err := s.runSensitive("kubeadm token create --print-join-command")
+		if err != nil {
+			return err
+		}
+		joinCommand = strings.TrimSpace(out)
+
+		if len(masters) > 0 {
+			out, err := s.runSensitive(fmt.Sprintf("kubeadm init phase upload-certs --upload-certs --config %s | tail -1", kubeadmConfigPath))
+			if err != nil {
+				return err
+			}
+			certificateKey = strings.TrimSpace(out)
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to create join command: %w", err)
+	}
+
+	reportProgress(op, 60, "Joining control plane nodes")
+	for _, machine := range masters {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		cmd := fmt.Sprintf("%s --control-plane --certificate-key %s --apiserver-advertise-address %s --node-name %s",
+			joinCommand, shellQuote(certificateKey), shellQuote(machineInternalAddress(machine)), shellQuote(machine.Name))
+		if err := p.runJoin(op, machine, cmd); err != nil {
+			return err
+		}
+	}
+
+	reportProgress(op, 80, "Joining worker nodes")
+	for _, machine := range workers {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		cmd := fmt.Sprintf("%s --node-name %s", joinCommand, shellQuote(machine.Name))
+		if err := p.runJoin(op, machine, cmd); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runJoin 在节点上执行加入命令
+func (p *KubeadmProvisioner) runJoin(op *ProvisionOperation, machine *model.Machine, cmd string) error {
+	return p.withSession(op, machine, func(s *kubeadmSession) error {
+		if _, err := s.runSensitive(cmd); err != nil {
+			return fmt.Errorf("kubeadm join failed on %s: %w", machine.Name, err)
+		}
+		return nil
+	})
+}
+
+// resetNode 重置节点并清理数据目录
+func (p *KubeadmProvisioner) resetNode(op *ProvisionOperation, machine *model.Machine) error {
+	return p.withSession(op, machine, func(s *kubeadmSession) error {
+		if _, err := s.run("kubeadm reset -f"); err != nil {
+			return fmt.Errorf("kubeadm reset failed on %s: %w", machine.Name, err)
+		}
+		if _, err := s.run("rm -rf /etc/cni/net.d /var/lib/etcd /var/lib/kubelet/* /etc/kubernetes/* $HOME/.kube/config"); err != nil {
+			return fmt.Errorf("failed to clean data dirs on %s: %w", machine.Name, err)
+		}
+		return nil
+	})
+}
+
+// withSession 连接节点并执行操作
+func (p *KubeadmProvisioner) withSession(op *ProvisionOperation, machine *model.Machine, fn func(s *kubeadmSession) error) error {
+	auth, ok := op.Credentials[machine.Name]
+	if !ok || auth == nil {
+		return fmt.Errorf("no credential for host %s", machine.Name)
+	}
+
+	client, err := p.sshService.ConnectWithAuth(machine.IPAddress, auth)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	return fn(&kubeadmSession{
+		op:      op,
+		machine: machine,
+		client:  client,
+		sudo:    auth.Username != "root",
+	})
+}
+
+// kubeadmSession 单个节点上的SSH会话
+type kubeadmSession struct {
+	op      *ProvisionOperation
+	machine *model.Machine
+	client  *SSHClient
+	sudo    bool
+}
+
+// run 执行命令，非root用户通过sudo执行
+func (s *kubeadmSession) run(cmd string) (string, error) {
+	s.log(fmt.Sprintf("[%s] %s", s.machine.Name, cmd), false)
+
+	if s.sudo {
+		cmd = "sudo -n sh -c " + shellQuote(cmd)
+	}
+	out, err := s.client.ExecuteCommand(cmd)
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		if line != "" {
+			s.log(fmt.Sprintf("[%s] %s", s.machine.Name, line), false)
+		}
+	}
+	if err != nil {
+		s.log(fmt.Sprintf("[%s] %v", s.machine.Name, err), true)
+	}
+	return out, err
+}
+
+// runSensitive 执行命令或输出中带有加入令牌、证书密钥的命令
+// 任务日志只记录脱敏后的命令，不记录输出，返回的错误同样脱敏
+func (s *kubeadmSession) runSensitive(cmd string) (string, error) {
+	s.log(fmt.Sprintf("[%s] %s", s.machine.Name, redactJoinSecrets(cmd)), false)
+
+	if s.sudo {
+		cmd = "sudo -n sh -c " + shellQuote(cmd)
+	}
+	out, err := s.client.ExecuteSensitiveCommand(cmd)
+	if err != nil {
+		err = errors.New(redactJoinSecrets(err.Error()))
+		s.log(fmt.Sprintf("[%s] %v", s.machine.Name, err), true)
+		return "", err
+	}
+	s.log(fmt.Sprintf("[%s] (output hidden)", s.machine.Name), false)
+	return out, nil
+}
+
+// kubectl 使用 admin.conf 执行 kubectl
+func (s *kubeadmSession) kubectl(args string) (string, error) {
+	return s.run(fmt.Sprintf("kubectl --kubeconfig %s %s", kubeadmAdminConfig, args))
+}
+
+// writeConfig 上传 kubeadm 配置
+func (s *kubeadmSession) writeConfig(content string) error {
+	if err := s.client.WriteFile(kubeadmUploadPath, []byte(content), 0600); err != nil {
+		return fmt.Errorf("failed to upload kubeadm config to %s: %w", s.machine.Name, err)
+	}
+	_, err := s.run(fmt.Sprintf("mkdir -p /etc/kubernetes && install -m 600 %s %s && rm -f %s",
+		kubeadmUploadPath, kubeadmConfigPath, kubeadmUploadPath))
+	return err
+}
+
+// log 上报日志
+func (s *kubeadmSession) log(line string, isError bool) {
+	reportLog(s.op, line, isError)
+}
+
+// splitMachinesByRole 按角色拆分控制平面与工作节点
+func splitMachinesByRole(machines []*model.Machine) (masters, workers []*model.Machine) {
+	for _, machine := range machines {
+		switch machine.Role {
+		case "master":
+			masters = append(masters, machine)
+		case "worker":
+			workers = append(workers, machine)
+		}
+	}
+	return masters, workers
+}
+
+// firstMaster 返回首个控制平面节点
+func firstMaster(machines []*model.Machine) (*model.Machine, error) {
+	for _, m := range machines {
+		if m.Role == "master" {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("no master node available")
+}
+
+// reportProgress 上报进度
+func reportProgress(op *ProvisionOperation, progress int, step string) {
+	if op.Reporter != nil {
+		op.Reporter.Progress(progress, step)
+	}
+}
+
+// reportLog 上报日志
+func reportLog(op *ProvisionOperation, line string, isError bool) {
+	if op.Reporter != nil {
+		op.Reporter.Log(line, isError)
+	}
+}
+
+// joinSecretPattern kubeadm join 命令中的令牌、CA 证书哈希与证书密钥参数
+var joinSecretPattern = regexp.MustCompile(`(--token|--discovery-token-ca-cert-hash|--certificate-key)([ =])\S+`)
+
+// redactJoinSecrets 遮盖加入命令中的令牌与密钥
+func redactJoinSecrets(s string) string {
+	return joinSecretPattern.ReplaceAllString(s, "$1$2******")
+}
+
+// shellQuote 单引号转义
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -76,14 +76,8 @@ func (rc *ResourceClassifier) ClassifyAllNamespaces(clusterID string) ([]*Namesp
 func (rc *ResourceClassifier) ClassifyNamespace(clusterID string, namespace corev1.Namespace) (*NamespaceClassificationResult, error) {
 	ctx := context.Background()
 
-	// 1. 识别租户
-	tenant, err := rc.identifyTenant(namespace)
-	if err != nil {
-		return nil, err
-	}
-
-	// 2. 创建或获取环境
-	env, err := rc.createOrGetEnvironment(clusterID, tenant, namespace)
+	// 1-2. 识别租户，创建或获取环境
+	tenant, env, err := rc.ClassifyNamespaceEnvironment(clusterID, namespace)
 	if err != nil {
 		return nil, err
 	}
@@ -110,6 +104,19 @@ func (rc *ResourceClassifier) ClassifyNamespace(clusterID string, namespace core
 	}, nil
 }
 
+// ClassifyNamespaceEnvironment 识别命名空间所属租户并创建或获取对应环境，不访问集群
+func (rc *ResourceClassifier) ClassifyNamespaceEnvironment(clusterID string, namespace corev1.Namespace) (*model.Tenant, *model.Environment, error) {
+	tenant, err := rc.identifyTenant(namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	env, err := rc.createOrGetEnvironment(clusterID, tenant, namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tenant, env, nil
+}
+
 // identifyTenant 识别租户
 func (rc *ResourceClassifier) identifyTenant(namespace corev1.Namespace) (*model.Tenant, error) {
 	// 检查是否为系统命名空间
@@ -241,6 +248,11 @@ func (rc *ResourceClassifier) discoverApplications(ctx context.Context, env *mod
 
 // extractAppLabel 提取应用标签
 func (rc *ResourceClassifier) extractAppLabel(labels map[string]string) string {
+	return AppNameFromLabels(labels)
+}
+
+// AppNameFromLabels 按优先级从标签中提取应用名称
+func AppNameFromLabels(labels map[string]string) string {
 	// 优先级顺序
 	if name, ok := labels[model.AppLabelKubernetesName]; ok {
 		return name
@@ -256,6 +268,11 @@ func (rc *ResourceClassifier) extractAppLabel(labels map[string]string) string {
 
 // extractCommonLabels 提取公共标签
 func (rc *ResourceClassifier) extractCommonLabels(labels map[string]string) model.JSONMap {
+	return CommonAppLabels(labels)
+}
+
+// CommonAppLabels 提取工作负载上与应用相关的公共标签
+func CommonAppLabels(labels map[string]string) model.JSONMap {
 	commonLabels := make(map[string]interface{})
 	importantKeys := []string{
 		model.AppLabelKubernetesName,
@@ -219,7 +219,7 @@ func (s *RestoreService) executeRestore(restoreID string, cluster *model.Cluster
 
 	// 创建Kubernetes客户端
 	ctx := context.Background()
-	clientset, err := s.clusterManager.GetClient(ctx, kubeconfig)
+	clientset, err := s.clusterManager.GetClientForCluster(ctx, cluster.ID, kubeconfig)
 	if err != nil {
 		s.logRestoreError(restoreID, fmt.Errorf("failed to get client: %w", err))
 		return
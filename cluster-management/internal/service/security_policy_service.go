@@ -55,7 +55,7 @@ func (s *SecurityPolicyService) SyncSecurityPolicyFromKubernetes(ctx context.Con
 	}
 
 	// 创建Kubernetes客户端
-	clientset, err := s.kubernetesService.GetClientset(kubeconfig)
+	clientset, err := s.clusterManager.GetClientForCluster(ctx, cluster.ID, kubeconfig)
 	if err != nil {
 		return fmt.Errorf("failed to create kubernetes client: %w", err)
 	}
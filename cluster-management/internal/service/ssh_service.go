@@ -2,6 +2,7 @@ package service
 
 import (
 	"bytes"
+	"encoding/pem"
 	"fmt"
 	"io"
 	"os"
@@ -26,20 +27,45 @@ type SSHClient struct {
 	client *ssh.Client
 }
 
-// Connect 连接到远程主机
+// SSHAuth SSH认证信息（明文，仅在建立会话时短暂存在于内存中）
+type SSHAuth struct {
+	Username   string
+	Password   string
+	PrivateKey string
+	Passphrase string
+	Port       int
+}
+
+// Connect 使用密码连接到远程主机
 func (s *SSHService) Connect(host, username, password string) (*SSHClient, error) {
+	return s.ConnectWithAuth(host, &SSHAuth{
+		Username: username,
+		Password: password,
+	})
+}
+
+// ConnectWithAuth 使用密码或私钥连接到远程主机
+func (s *SSHService) ConnectWithAuth(host string, auth *SSHAuth) (*SSHClient, error) {
+	authMethods, err := buildSSHAuthMethods(auth)
+	if err != nil {
+		return nil, err
+	}
+
+	port := auth.Port
+	if port == 0 {
+		port = 22
+	}
+
 	config := &ssh.ClientConfig{
-		User: username,
-		Auth: []ssh.AuthMethod{
-			ssh.Password(password),
-		},
+		User:            auth.Username,
+		Auth:            authMethods,
 		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
 		Timeout:         30 * time.Second,
 	}
 
-	fmt.Printf("[SSH] Connecting to %s@%s:22\n", username, host)
+	fmt.Printf("[SSH] Connecting to %s@%s:%d\n", auth.Username, host, port)
 
-	client, err := ssh.Dial("tcp", fmt.Sprintf("%s:22", host), config)
+	client, err := ssh.Dial("tcp", fmt.Sprintf("%s:%d", host, port), config)
 	if err != nil {
 		return nil, fmt.Errorf("SSH dial failed for %s: %w", host, err)
 	}
@@ -49,6 +75,64 @@ func (s *SSHService) Connect(host, username, password string) (*SSHClient, error
 	return &SSHClient{client: client}, nil
 }
 
+// buildSSHAuthMethods 根据认证信息构建SSH认证方式，私钥优先于密码
+func buildSSHAuthMethods(auth *SSHAuth) ([]ssh.AuthMethod, error) {
+	if auth == nil {
+		return nil, fmt.Errorf("ssh auth is required")
+	}
+
+	var methods []ssh.AuthMethod
+	if auth.PrivateKey != "" {
+		signer, err := ParseSSHPrivateKey(auth.PrivateKey, auth.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, ssh.PublicKeys(signer))
+	}
+	if auth.Password != "" {
+		methods = append(methods, ssh.Password(auth.Password))
+	}
+
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("no ssh password or private key provided for user %s", auth.Username)
+	}
+	return methods, nil
+}
+
+// ParseSSHPrivateKey 解析PEM格式私钥，支持带口令的私钥
+func ParseSSHPrivateKey(privateKey, passphrase string) (ssh.Signer, error) {
+	var signer ssh.Signer
+	var err error
+	if passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(privateKey), []byte(passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey([]byte(privateKey))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	return signer, nil
+}
+
+// DecryptSSHPrivateKey 去除私钥口令，返回未加密的OpenSSH格式PEM
+// 用于不支持口令的外部工具（如 kk），结果只应写入临时文件
+func DecryptSSHPrivateKey(privateKey, passphrase string) (string, error) {
+	if passphrase == "" {
+		return privateKey, nil
+	}
+
+	rawKey, err := ssh.ParseRawPrivateKeyWithPassphrase([]byte(privateKey), []byte(passphrase))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt private key: %w", err)
+	}
+
+	block, err := ssh.MarshalPrivateKey(rawKey, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal private key: %w", err)
+	}
+	return string(pem.EncodeToMemory(block)), nil
+}
+
 // Close 关闭连接
 func (c *SSHClient) Close() error {
 	if c.client != nil {
@@ -60,7 +144,16 @@ func (c *SSHClient) Close() error {
 // ExecuteCommand 执行远程命令
 func (c *SSHClient) ExecuteCommand(command string) (string, error) {
 	fmt.Printf("[SSH] Executing command: %s\n", command)
+	return c.execute(command)
+}
+
+// ExecuteSensitiveCommand 执行带有令牌、密钥等敏感信息的命令，不在日志中输出命令内容
+func (c *SSHClient) ExecuteSensitiveCommand(command string) (string, error) {
+	fmt.Printf("[SSH] Executing sensitive command\n")
+	return c.execute(command)
+}
 
+func (c *SSHClient) execute(command string) (string, error) {
 	session, err := c.client.NewSession()
 	if err != nil {
 		return "", fmt.Errorf("failed to create session: %w", err)
@@ -155,3 +248,32 @@ func (c *SSHClient) UploadFile(localFile, remoteFile string) error {
 
 	return nil
 }
+
+// WriteFile 将内容写入远程文件
+func (c *SSHClient) WriteFile(remoteFile string, content []byte, mode os.FileMode) error {
+	sftpClient, err := sftp.NewClient(c.client)
+	if err != nil {
+		return fmt.Errorf("failed to create SFTP client: %w", err)
+	}
+	defer sftpClient.Close()
+
+	if err := sftpClient.MkdirAll(filepath.Dir(remoteFile)); err != nil {
+		return fmt.Errorf("failed to create remote directory: %w", err)
+	}
+
+	remoteFileHandle, err := sftpClient.OpenFile(remoteFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return fmt.Errorf("failed to open remote file: %w", err)
+	}
+	defer remoteFileHandle.Close()
+
+	if err := remoteFileHandle.Chmod(mode); err != nil {
+		return fmt.Errorf("failed to chmod remote file: %w", err)
+	}
+
+	if _, err := remoteFileHandle.Write(content); err != nil {
+		return fmt.Errorf("failed to write remote file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// sshTunnelKeepalive 隧道保活间隔，保活失败时断开，下次拨号自动重连
+	sshTunnelKeepalive   = 30 * time.Second
+	sshTunnelDialTimeout = 15 * time.Second
+)
+
+// SSHTunnelPool 堡垒机SSH连接池，每个集群复用一条SSH连接转发所有 apiserver 连接
+type SSHTunnelPool struct {
+	mu      sync.Mutex
+	tunnels map[string]*sshTunnel
+}
+
+// sshTunnel 单条堡垒机连接
+type sshTunnel struct {
+	key    string
+	addr   string
+	config *ssh.ClientConfig
+
+	mu     sync.Mutex
+	client *ssh.Client
+}
+
+// NewSSHTunnelPool 创建SSH隧道连接池
+func NewSSHTunnelPool() *SSHTunnelPool {
+	return &SSHTunnelPool{tunnels: make(map[string]*sshTunnel)}
+}
+
+// Dialer 返回经堡垒机转发的拨号函数，同一 key 共享连接
+// 设置变更后应先调用 Close 使旧连接失效
+func (p *SSHTunnelPool) Dialer(key, addr string, config *ssh.ClientConfig) func(ctx context.Context, network, address string) (net.Conn, error) {
+	p.mu.Lock()
+	tunnel, ok := p.tunnels[key]
+	if !ok {
+		tunnel = &sshTunnel{key: key, addr: addr, config: config}
+		p.tunnels[key] = tunnel
+	}
+	p.mu.Unlock()
+	return tunnel.dial
+}
+
+// Close 关闭并移除指定 key 的隧道
+func (p *SSHTunnelPool) Close(key string) {
+	p.mu.Lock()
+	tunnel, ok := p.tunnels[key]
+	delete(p.tunnels, key)
+	p.mu.Unlock()
+	if ok {
+		tunnel.reset(nil)
+	}
+}
+
+// CloseAll 关闭全部隧道
+func (p *SSHTunnelPool) CloseAll() {
+	p.mu.Lock()
+	tunnels := p.tunnels
+	p.tunnels = make(map[string]*sshTunnel)
+	p.mu.Unlock()
+	for _, tunnel := range tunnels {
+		tunnel.reset(nil)
+	}
+}
+
+// dial 通过隧道连接目标地址，转发失败时重建一次SSH连接后重试
+func (t *sshTunnel) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	client, err := t.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := client.Dial(network, address)
+	if err == nil {
+		return conn, nil
+	}
+
+	log.Printf("[SSH-TUNNEL] %s: forwarding to %s failed, reconnecting: %v", t.key, address, err)
+	t.reset(client)
+	if client, err = t.connect(ctx); err != nil {
+		return nil, err
+	}
+	conn, err = client.Dial(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s via bastion %s: %w", address, t.addr, err)
+	}
+	return conn, nil
+}
+
+// connect 返回当前SSH连接，不存在时新建
+func (t *sshTunnel) connect(ctx context.Context) (*ssh.Client, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.client != nil {
+		return t.client, nil
+	}
+
+	dialer := &net.Dialer{Timeout: sshTunnelDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", t.addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect bastion %s: %w", t.addr, err)
+	}
+	// 握手阶段受超时约束，建立后取消截止时间
+	conn.SetDeadline(time.Now().Add(sshTunnelDialTimeout))
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, t.addr, t.config)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("SSH handshake with bastion %s failed: %w", t.addr, err)
+	}
+	conn.SetDeadline(time.Time{})
+
+	client := ssh.NewClient(sshConn, chans, reqs)
+	t.client = client
+	go t.keepalive(client)
+	log.Printf("[SSH-TUNNEL] %s: connected to bastion %s", t.key, t.addr)
+	return client, nil
+}
+
+// reset 关闭连接，stale 不为空时仅在其仍是当前连接时关闭，避免并发重连时误关新连接
+func (t *sshTunnel) reset(stale *ssh.Client) {
+	t.mu.Lock()
+	client := t.client
+	if client == nil || (stale != nil && stale != client) {
+		t.mu.Unlock()
+		return
+	}
+	t.client = nil
+	t.mu.Unlock()
+	client.Close()
+}
+
+// keepalive 定期发送保活请求，连接断开后退出
+func (t *sshTunnel) keepalive(client *ssh.Client) {
+	done := make(chan struct{})
+	go func() {
+		client.Wait()
+		close(done)
+	}()
+
+	ticker := time.NewTicker(sshTunnelKeepalive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			t.reset(client)
+			return
+		case <-ticker.C:
+			if _, _, err := client.SendRequest("keepalive@openssh.com", true, nil); err != nil {
+				log.Printf("[SSH-TUNNEL] %s: keepalive to %s failed: %v", t.key, t.addr, err)
+				t.reset(client)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTenantMemberNotFound = errors.New("租户成员不存在")
+	ErrUserNotFound         = errors.New("用户不存在")
+)
+
+// AddTenantMemberRequest 添加租户成员请求
+type AddTenantMemberRequest struct {
+	UserID string `json:"user_id" binding:"required,uuid"`
+	Role   string `json:"role" binding:"omitempty,oneof=owner member viewer"`
+}
+
+// AddTenantMember 添加租户成员，已是成员时更新角色
+func (s *TenantService) AddTenantMember(tenantID string, req AddTenantMemberRequest) (*model.TenantMember, error) {
+	tenant, err := s.getTenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.userRepo.GetByID(req.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	member := &model.TenantMember{
+		TenantID: tenant.ID,
+		UserID:   uuid.MustParse(req.UserID),
+		Role:     req.Role,
+	}
+	if member.Role == "" {
+		member.Role = model.TenantMemberRoleMember
+	}
+	if err := s.memberRepo.Save(member); err != nil {
+		return nil, fmt.Errorf("failed to save tenant member: %w", err)
+	}
+
+	if s.auditService != nil {
+		s.auditService.LogCreate("tenant_member", tenant.ID.String(), "system", "", "", member)
+	}
+	return member, nil
+}
+
+// ListTenantMembers 获取租户成员
+func (s *TenantService) ListTenantMembers(tenantID string) ([]*model.TenantMember, error) {
+	tenant, err := s.getTenant(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return s.memberRepo.ListByTenant(tenant.ID)
+}
+
+// RemoveTenantMember 移除租户成员
+func (s *TenantService) RemoveTenantMember(tenantID, userID string) error {
+	tenant, err := s.getTenant(tenantID)
+	if err != nil {
+		return err
+	}
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return ErrTenantMemberNotFound
+	}
+	found, err := s.memberRepo.Delete(tenant.ID, uid)
+	if err != nil {
+		return fmt.Errorf("failed to delete tenant member: %w", err)
+	}
+	if !found {
+		return ErrTenantMemberNotFound
+	}
+
+	if s.auditService != nil {
+		s.auditService.LogDelete("tenant_member", tenant.ID.String(), "system", "", "", map[string]string{"user_id": userID})
+	}
+	return nil
+}
+
+// ListUserMemberships 获取用户所属的启用租户
+func (s *TenantService) ListUserMemberships(userID uuid.UUID) ([]model.TenantMembership, error) {
+	return s.memberRepo.ListMemberships(userID)
+}
+
+func (s *TenantService) getTenant(tenantID string) (*model.Tenant, error) {
+	tenant, err := s.tenantRepo.GetByID(tenantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTenantNotFound
+		}
+		return nil, err
+	}
+	return tenant, nil
+}
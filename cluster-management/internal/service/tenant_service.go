@@ -13,6 +13,8 @@ type TenantService struct {
 	quotaRepo      *repository.QuotaRepository
 	environmentRepo *repository.EnvironmentRepository
 	auditService   *AuditService
+	memberRepo     *repository.TenantMemberRepository
+	userRepo       *repository.UserRepository
 }
 
 // NewTenantService 创建租户服务
@@ -21,12 +23,16 @@ func NewTenantService(
 	quotaRepo *repository.QuotaRepository,
 	environmentRepo *repository.EnvironmentRepository,
 	auditService *AuditService,
+	memberRepo *repository.TenantMemberRepository,
+	userRepo *repository.UserRepository,
 ) *TenantService {
 	return &TenantService{
 		tenantRepo:     tenantRepo,
 		quotaRepo:      quotaRepo,
 		environmentRepo: environmentRepo,
 		auditService:   auditService,
+		memberRepo:     memberRepo,
+		userRepo:       userRepo,
 	}
 }
 
@@ -0,0 +1,591 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/constants"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"github.com/taichu-system/cluster-management/internal/repository"
+	"gorm.io/gorm"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+var (
+	// ErrUserKubeconfigNotFound 签发记录不存在
+	ErrUserKubeconfigNotFound = errors.New("user kubeconfig not found")
+	// ErrNoTenantNamespaces 用户在该集群上没有可授权的租户环境
+	ErrNoTenantNamespaces = errors.New("user has no tenant environments on this cluster")
+	// ErrInvalidKubeconfigExpiration 申请的有效期超出允许范围
+	ErrInvalidKubeconfigExpiration = errors.New("invalid kubeconfig expiration")
+	// ErrKubeconfigRequiresProxy 集群经隧道访问，用户无法直连 apiserver
+	ErrKubeconfigRequiresProxy = errors.New("cluster is reached through a tunnel, use proxy mode")
+	// ErrKubeconfigRevoked 代理令牌对应的 kubeconfig 已吊销或过期
+	ErrKubeconfigRevoked = errors.New("kubeconfig has been revoked or expired")
+	// ErrKubeconfigServerURLRequired 未配置平台对外地址，无法签发代理模式 kubeconfig
+	ErrKubeconfigServerURLRequired = errors.New("kubeconfig.server_url is not configured, proxy mode is unavailable")
+)
+
+const (
+	defaultUserKubeconfigTTL     = 8 * time.Hour
+	defaultUserKubeconfigMaxTTL  = 7 * 24 * time.Hour
+	minUserKubeconfigTTL         = 10 * time.Minute
+	defaultKubeconfigSANamespace = "taichu-users"
+
+	kubeconfigManagedByLabel = "app.kubernetes.io/managed-by"
+	kubeconfigManagedBy      = "taichu-system"
+	kubeconfigIDLabel        = "taichu-system/kubeconfig-id"
+	kubeconfigUserAnnotation = "taichu-system/username"
+)
+
+// tenantRoleClusterRoles 租户成员角色在环境命名空间内绑定的内置 ClusterRole
+var tenantRoleClusterRoles = map[string]string{
+	model.TenantMemberRoleOwner:  "admin",
+	model.TenantMemberRoleMember: "edit",
+	model.TenantMemberRoleViewer: "view",
+}
+
+// IssueKubeconfigRequest 签发 kubeconfig 请求
+type IssueKubeconfigRequest struct {
+	TenantID          string `json:"tenant_id" binding:"omitempty,uuid"` // 为空时包含用户所属的全部租户
+	Mode              string `json:"mode" binding:"omitempty,oneof=service_account proxy"`
+	ExpirationSeconds int64  `json:"expiration_seconds" binding:"omitempty,min=0"`
+	// ServerURL 平台对外地址，未配置 kubeconfig.server_url 且信任反向代理请求头时由处理器推导
+	ServerURL string `json:"-"`
+}
+
+// IssuedKubeconfig 签发结果，kubeconfig 内容只返回一次
+type IssuedKubeconfig struct {
+	*model.UserKubeconfig
+	Kubeconfig string `json:"kubeconfig"`
+}
+
+// UserKubeconfigService 用户 kubeconfig 签发服务
+// 按用户所属租户在集群上的环境命名空间创建 RoleBinding，替代共享管理员 kubeconfig
+type UserKubeconfigService struct {
+	clusterRepo       *repository.ClusterRepository
+	kubeconfigRepo    *repository.UserKubeconfigRepository
+	environmentRepo   *repository.EnvironmentRepository
+	encryptionService *EncryptionService
+	clusterManager    *ClusterManager
+	connectionService *ClusterConnectionService
+	tenantService     *TenantService
+	proxyService      *ClusterProxyService
+	jwtSecret         string
+	defaultTTL        time.Duration
+	maxTTL            time.Duration
+	serviceAccountNS  string
+	serverURL         string
+}
+
+// NewUserKubeconfigService 创建用户 kubeconfig 签发服务
+func NewUserKubeconfigService(
+	clusterRepo *repository.ClusterRepository,
+	kubeconfigRepo *repository.UserKubeconfigRepository,
+	environmentRepo *repository.EnvironmentRepository,
+	encryptionService *EncryptionService,
+	clusterManager *ClusterManager,
+	connectionService *ClusterConnectionService,
+	tenantService *TenantService,
+	proxyService *ClusterProxyService,
+	jwtSecret string,
+	defaultTTL, maxTTL time.Duration,
+	serviceAccountNamespace, serverURL string,
+) *UserKubeconfigService {
+	if defaultTTL <= 0 {
+		defaultTTL = defaultUserKubeconfigTTL
+	}
+	if maxTTL <= 0 {
+		maxTTL = defaultUserKubeconfigMaxTTL
+	}
+	if defaultTTL > maxTTL {
+		defaultTTL = maxTTL
+	}
+	if serviceAccountNamespace == "" {
+		serviceAccountNamespace = defaultKubeconfigSANamespace
+	}
+	return &UserKubeconfigService{
+		clusterRepo:       clusterRepo,
+		kubeconfigRepo:    kubeconfigRepo,
+		environmentRepo:   environmentRepo,
+		encryptionService: encryptionService,
+		clusterManager:    clusterManager,
+		connectionService: connectionService,
+		tenantService:     tenantService,
+		proxyService:      proxyService,
+		jwtSecret:         jwtSecret,
+		defaultTTL:        defaultTTL,
+		maxTTL:            maxTTL,
+		serviceAccountNS:  serviceAccountNamespace,
+		serverURL:         strings.TrimRight(serverURL, "/"),
+	}
+}
+
+// Issue 为用户签发集群 kubeconfig
+func (s *UserKubeconfigService) Issue(ctx context.Context, clusterID uuid.UUID, identity ProxyIdentity, req IssueKubeconfigRequest) (*IssuedKubeconfig, error) {
+	userID, err := uuid.Parse(identity.UserID)
+	if err != nil || identity.Username == "" {
+		return nil, ErrProxyUnauthenticated
+	}
+	ttl, err := s.expiration(req.ExpirationSeconds)
+	if err != nil {
+		return nil, err
+	}
+	mode := req.Mode
+	if mode == "" {
+		mode = model.UserKubeconfigModeServiceAccount
+	}
+	base := s.serverURL
+	if base == "" {
+		base = strings.TrimRight(req.ServerURL, "/")
+	}
+	if mode == model.UserKubeconfigModeProxy && base == "" {
+		return nil, ErrKubeconfigServerURLRequired
+	}
+
+	cluster, err := s.clusterRepo.GetByID(clusterID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClusterNotFound
+		}
+		return nil, fmt.Errorf("failed to get cluster: %w", err)
+	}
+
+	record := &model.UserKubeconfig{
+		ID:        uuid.New(),
+		ClusterID: clusterID,
+		UserID:    userID,
+		Username:  identity.Username,
+		Mode:      mode,
+		Status:    model.UserKubeconfigStatusActive,
+	}
+	record.BindingName = "taichu-kubeconfig-" + record.ID.String()
+	if req.TenantID != "" {
+		tenantID := uuid.MustParse(req.TenantID)
+		record.TenantID = &tenantID
+	}
+
+	namespaces, err := s.tenantNamespaces(clusterID, userID, record.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	record.Namespaces = make(model.JSONMap, len(namespaces))
+	for namespace, clusterRole := range namespaces {
+		record.Namespaces[namespace] = clusterRole
+	}
+
+	if mode == model.UserKubeconfigModeServiceAccount {
+		connection, err := s.connectionService.Get(clusterID)
+		if err != nil {
+			return nil, err
+		}
+		if connection.Mode != model.ClusterConnectionDirect {
+			return nil, ErrKubeconfigRequiresProxy
+		}
+	}
+
+	adminKubeconfig, err := s.encryptionService.Decrypt(cluster.KubeconfigEncrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt kubeconfig: %w", err)
+	}
+	clientset, err := s.clusterManager.GetClientForCluster(ctx, clusterID, adminKubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	var subject rbacv1.Subject
+	if mode == model.UserKubeconfigModeServiceAccount {
+		subject = rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: s.serviceAccountNS, Name: record.BindingName}
+		record.Subject = s.serviceAccountNS + "/" + record.BindingName
+	} else {
+		subject = rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: s.proxyService.ImpersonatedUser(identity.Username)}
+		record.Subject = subject.Name
+	}
+
+	var server, token string
+	var caData []byte
+	var insecure bool
+	err = func() error {
+		if mode == model.UserKubeconfigModeServiceAccount {
+			if err := s.createServiceAccount(ctx, clientset, record); err != nil {
+				return err
+			}
+		}
+		if err := s.createRoleBindings(ctx, clientset, record, namespaces, subject); err != nil {
+			return err
+		}
+
+		if mode == model.UserKubeconfigModeServiceAccount {
+			expiresAt, saToken, err := s.requestToken(ctx, clientset, record.BindingName, ttl)
+			if err != nil {
+				return err
+			}
+			record.ExpiresAt = expiresAt
+			token = saToken
+
+			restConfig, err := clientcmd.RESTConfigFromKubeConfig([]byte(adminKubeconfig))
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidKubeConfig, err)
+			}
+			server, caData, insecure = restConfig.Host, restConfig.CAData, restConfig.Insecure
+			return nil
+		}
+
+		record.ExpiresAt = time.Now().Add(ttl)
+		server = base + "/api/v1/clusters/" + clusterID.String() + "/proxy"
+		token, err = s.signProxyToken(record, identity)
+		return err
+	}()
+	if err != nil {
+		if cleanupErr := s.cleanup(ctx, clientset, record); cleanupErr != nil {
+			log.Printf("[KUBECONFIG] failed to roll back kubeconfig %s on cluster %s: %v", record.ID, clusterID, cleanupErr)
+		}
+		return nil, err
+	}
+
+	content, err := buildUserKubeconfig(cluster.Name, identity.Username, server, caData, insecure, token, namespaces)
+	if err != nil {
+		s.cleanup(ctx, clientset, record)
+		return nil, err
+	}
+	if err := s.kubeconfigRepo.Create(record); err != nil {
+		s.cleanup(ctx, clientset, record)
+		return nil, fmt.Errorf("failed to save kubeconfig record: %w", err)
+	}
+
+	return &IssuedKubeconfig{UserKubeconfig: record, Kubeconfig: content}, nil
+}
+
+// List 获取集群的签发记录，可按用户与状态过滤
+func (s *UserKubeconfigService) List(clusterID uuid.UUID, userID *uuid.UUID, status string) ([]*model.UserKubeconfig, error) {
+	if _, err := s.clusterRepo.GetByID(clusterID.String()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClusterNotFound
+		}
+		return nil, fmt.Errorf("failed to get cluster: %w", err)
+	}
+	return s.kubeconfigRepo.List(clusterID, userID, status)
+}
+
+// Revoke 吊销 kubeconfig，删除集群内的 RoleBinding 与 ServiceAccount
+// ServiceAccount 令牌在其被删除前始终有效，因此集群清理失败时保持 active 以便重试；代理令牌由平台校验，直接失效
+func (s *UserKubeconfigService) Revoke(ctx context.Context, clusterID, id uuid.UUID, revokedBy string) (*model.UserKubeconfig, error) {
+	record, err := s.kubeconfigRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserKubeconfigNotFound
+		}
+		return nil, fmt.Errorf("failed to get kubeconfig record: %w", err)
+	}
+	if record.ClusterID != clusterID {
+		return nil, ErrUserKubeconfigNotFound
+	}
+	if record.Status != model.UserKubeconfigStatusActive {
+		return record, nil
+	}
+
+	cleanupErr := s.cleanupCluster(ctx, record)
+	if cleanupErr != nil && record.Mode == model.UserKubeconfigModeServiceAccount {
+		return nil, fmt.Errorf("failed to remove service account: %w", cleanupErr)
+	}
+
+	now := time.Now()
+	fields := map[string]interface{}{
+		"status":        model.UserKubeconfigStatusRevoked,
+		"revoked_at":    now,
+		"revoked_by":    revokedBy,
+		"cleanup_error": errorString(cleanupErr),
+	}
+	if err := s.kubeconfigRepo.UpdateFields(record.ID, fields); err != nil {
+		return nil, fmt.Errorf("failed to update kubeconfig record: %w", err)
+	}
+	record.Status = model.UserKubeconfigStatusRevoked
+	record.RevokedAt = &now
+	record.RevokedBy = revokedBy
+	record.CleanupError = errorString(cleanupErr)
+	return record, nil
+}
+
+// ExpireDue 将已过期的签发记录标记为 expired 并清理集群内资源，返回处理数量
+func (s *UserKubeconfigService) ExpireDue(ctx context.Context) (int, error) {
+	records, err := s.kubeconfigRepo.ListExpired(time.Now(), 100)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, record := range records {
+		cleanupErr := s.cleanupCluster(ctx, record)
+		if cleanupErr != nil {
+			log.Printf("[KUBECONFIG] failed to clean up expired kubeconfig %s on cluster %s: %v", record.ID, record.ClusterID, cleanupErr)
+		}
+		if err := s.kubeconfigRepo.UpdateFields(record.ID, map[string]interface{}{
+			"status":        model.UserKubeconfigStatusExpired,
+			"cleanup_error": errorString(cleanupErr),
+		}); err != nil {
+			return expired, err
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// validateKubeconfigToken 校验代理模式 kubeconfig 令牌对应的记录仍然有效且属于该集群
+// 认证中间件只为代理令牌设置 ID，平台令牌的 tokenID 为空
+func validateKubeconfigToken(repo *repository.UserKubeconfigRepository, clusterID uuid.UUID, tokenID string) error {
+	if tokenID == "" {
+		return nil
+	}
+	id, err := uuid.Parse(tokenID)
+	if err != nil {
+		return ErrKubeconfigRevoked
+	}
+	record, err := repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrKubeconfigRevoked
+		}
+		return fmt.Errorf("failed to get kubeconfig record: %w", err)
+	}
+	if record.ClusterID != clusterID || record.Mode != model.UserKubeconfigModeProxy ||
+		record.Status != model.UserKubeconfigStatusActive || time.Now().After(record.ExpiresAt) {
+		return ErrKubeconfigRevoked
+	}
+	return nil
+}
+
+func (s *UserKubeconfigService) expiration(seconds int64) (time.Duration, error) {
+	if seconds == 0 {
+		return s.defaultTTL, nil
+	}
+	ttl := time.Duration(seconds) * time.Second
+	if ttl < minUserKubeconfigTTL || ttl > s.maxTTL {
+		return 0, fmt.Errorf("%w: must be between %s and %s", ErrInvalidKubeconfigExpiration, minUserKubeconfigTTL, s.maxTTL)
+	}
+	return ttl, nil
+}
+
+// tenantNamespaces 用户在集群上可访问的环境命名空间及绑定的 ClusterRole
+func (s *UserKubeconfigService) tenantNamespaces(clusterID, userID uuid.UUID, tenantID *uuid.UUID) (map[string]string, error) {
+	memberships, err := s.tenantService.ListUserMemberships(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant memberships: %w", err)
+	}
+	roles := make(map[uuid.UUID]string, len(memberships))
+	for _, membership := range memberships {
+		if tenantID == nil || membership.TenantID == *tenantID {
+			roles[membership.TenantID] = membership.Role
+		}
+	}
+	if tenantID != nil && len(roles) == 0 {
+		return nil, ErrTenantMemberNotFound
+	}
+
+	environments, err := s.environmentRepo.ListByClusterID(clusterID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list environments: %w", err)
+	}
+	namespaces := make(map[string]string)
+	for _, env := range environments {
+		role, ok := roles[env.TenantID]
+		if !ok || env.Status != model.EnvironmentStatusActive {
+			continue
+		}
+		clusterRole, ok := tenantRoleClusterRoles[role]
+		if !ok {
+			clusterRole = tenantRoleClusterRoles[model.TenantMemberRoleViewer]
+		}
+		// 同一命名空间被多个租户引用时取权限更大的角色
+		if existing, ok := namespaces[env.Namespace]; !ok || clusterRoleRank(clusterRole) > clusterRoleRank(existing) {
+			namespaces[env.Namespace] = clusterRole
+		}
+	}
+	if len(namespaces) == 0 {
+		return nil, ErrNoTenantNamespaces
+	}
+	return namespaces, nil
+}
+
+func clusterRoleRank(clusterRole string) int {
+	switch clusterRole {
+	case "admin":
+		return 3
+	case "edit":
+		return 2
+	}
+	return 1
+}
+
+func (s *UserKubeconfigService) createServiceAccount(ctx context.Context, clientset kubernetes.Interface, record *model.UserKubeconfig) error {
+	_, err := clientset.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   s.serviceAccountNS,
+			Labels: map[string]string{kubeconfigManagedByLabel: kubeconfigManagedBy},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create namespace %s: %w", s.serviceAccountNS, err)
+	}
+
+	_, err = clientset.CoreV1().ServiceAccounts(s.serviceAccountNS).Create(ctx, &corev1.ServiceAccount{
+		ObjectMeta: kubeconfigObjectMeta(record, s.serviceAccountNS),
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create service account: %w", err)
+	}
+	return nil
+}
+
+func (s *UserKubeconfigService) createRoleBindings(ctx context.Context, clientset kubernetes.Interface, record *model.UserKubeconfig, namespaces map[string]string, subject rbacv1.Subject) error {
+	for namespace, clusterRole := range namespaces {
+		_, err := clientset.RbacV1().RoleBindings(namespace).Create(ctx, &rbacv1.RoleBinding{
+			ObjectMeta: kubeconfigObjectMeta(record, namespace),
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "ClusterRole",
+				Name:     clusterRole,
+			},
+			Subjects: []rbacv1.Subject{subject},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to create role binding in namespace %s: %w", namespace, err)
+		}
+	}
+	return nil
+}
+
+func (s *UserKubeconfigService) requestToken(ctx context.Context, clientset kubernetes.Interface, serviceAccount string, ttl time.Duration) (time.Time, string, error) {
+	seconds := int64(ttl.Seconds())
+	tokenRequest, err := clientset.CoreV1().ServiceAccounts(s.serviceAccountNS).CreateToken(ctx, serviceAccount, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &seconds},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("failed to request service account token: %w", err)
+	}
+	// apiserver 可能按 --service-account-max-token-expiration 缩短有效期，以实际签发的为准
+	return tokenRequest.Status.ExpirationTimestamp.Time, tokenRequest.Status.Token, nil
+}
+
+// signProxyToken 签发代理模式使用的令牌，令牌 ID 即签发记录 ID，代理据此校验吊销状态
+// audience 限定为集群 API 代理，认证中间件在其他路由上拒绝该令牌
+func (s *UserKubeconfigService) signProxyToken(record *model.UserKubeconfig, identity ProxyIdentity) (string, error) {
+	claims := JWTClaims{
+		UserID:   identity.UserID,
+		Username: identity.Username,
+		Role:     identity.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        record.ID.String(),
+			Audience:  jwt.ClaimStrings{constants.KubeconfigProxyAudience},
+			ExpiresAt: jwt.NewNumericDate(record.ExpiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    "cluster-management",
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.jwtSecret))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign proxy token: %w", err)
+	}
+	return token, nil
+}
+
+// cleanupCluster 连接集群并删除签发时创建的资源
+func (s *UserKubeconfigService) cleanupCluster(ctx context.Context, record *model.UserKubeconfig) error {
+	cluster, err := s.clusterRepo.GetByID(record.ClusterID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 集群已删除，集群内资源随之不可达
+			return nil
+		}
+		return fmt.Errorf("failed to get cluster: %w", err)
+	}
+	kubeconfig, err := s.encryptionService.Decrypt(cluster.KubeconfigEncrypted)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt kubeconfig: %w", err)
+	}
+	clientset, err := s.clusterManager.GetClientForCluster(ctx, record.ClusterID, kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to get client: %w", err)
+	}
+	return s.cleanup(ctx, clientset, record)
+}
+
+// cleanup 删除 RoleBinding 与 ServiceAccount，已不存在的资源忽略
+func (s *UserKubeconfigService) cleanup(ctx context.Context, clientset kubernetes.Interface, record *model.UserKubeconfig) error {
+	var errs []error
+	for namespace := range record.Namespaces {
+		err := clientset.RbacV1().RoleBindings(namespace).Delete(ctx, record.BindingName, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("role binding in %s: %w", namespace, err))
+		}
+	}
+	if record.Mode == model.UserKubeconfigModeServiceAccount {
+		err := clientset.CoreV1().ServiceAccounts(s.serviceAccountNS).Delete(ctx, record.BindingName, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("service account: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func kubeconfigObjectMeta(record *model.UserKubeconfig, namespace string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      record.BindingName,
+		Namespace: namespace,
+		Labels: map[string]string{
+			kubeconfigManagedByLabel: kubeconfigManagedBy,
+			kubeconfigIDLabel:        record.ID.String(),
+		},
+		Annotations: map[string]string{kubeconfigUserAnnotation: record.Username},
+	}
+}
+
+// buildUserKubeconfig 生成 kubeconfig，默认上下文的命名空间为按名称排序的第一个
+func buildUserKubeconfig(clusterName, username, server string, caData []byte, insecure bool, token string, namespaces map[string]string) (string, error) {
+	names := make([]string, 0, len(namespaces))
+	for namespace := range namespaces {
+		names = append(names, namespace)
+	}
+	sort.Strings(names)
+
+	contextName := username + "@" + clusterName
+	config := clientcmdapi.NewConfig()
+	config.Clusters[clusterName] = &clientcmdapi.Cluster{
+		Server:                   server,
+		CertificateAuthorityData: caData,
+		InsecureSkipTLSVerify:    insecure,
+	}
+	config.AuthInfos[username] = &clientcmdapi.AuthInfo{Token: token}
+	config.Contexts[contextName] = &clientcmdapi.Context{
+		Cluster:   clusterName,
+		AuthInfo:  username,
+		Namespace: names[0],
+	}
+	config.CurrentContext = contextName
+
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		return "", fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+	return string(data), nil
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,109 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/taichu-system/cluster-management/internal/model"
+	"github.com/taichu-system/cluster-management/internal/repository"
+	"github.com/taichu-system/cluster-management/internal/service"
+)
+
+// CertificateExpiryWorker 定期检查集群证书到期时间并产生告警
+type CertificateExpiryWorker struct {
+	clusterRepo        *repository.ClusterRepository
+	certificateService *service.CertificateService
+	wg                 sync.WaitGroup
+	ctx                context.Context
+	cancel             context.CancelFunc
+	checkInterval      time.Duration
+	sem                chan struct{}
+}
+
+// NewCertificateExpiryWorker 创建证书到期检查Worker
+func NewCertificateExpiryWorker(
+	clusterRepo *repository.ClusterRepository,
+	certificateService *service.CertificateService,
+) *CertificateExpiryWorker {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &CertificateExpiryWorker{
+		clusterRepo:        clusterRepo,
+		certificateService: certificateService,
+		ctx:                ctx,
+		cancel:             cancel,
+		checkInterval:      12 * time.Hour,
+		sem:                make(chan struct{}, 5),
+	}
+}
+
+// Start 启动Worker
+func (w *CertificateExpiryWorker) Start() {
+	log.Println("Starting certificate expiry worker...")
+
+	w.wg.Add(1)
+	go w.scheduler()
+}
+
+// Stop 停止Worker
+func (w *CertificateExpiryWorker) Stop() {
+	log.Println("Stopping certificate expiry worker...")
+	w.cancel()
+	w.wg.Wait()
+}
+
+func (w *CertificateExpiryWorker) scheduler() {
+	defer w.wg.Done()
+
+	w.performCheck()
+
+	ticker := time.NewTicker(w.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			w.performCheck()
+		}
+	}
+}
+
+func (w *CertificateExpiryWorker) performCheck() {
+	clusters, err := w.clusterRepo.FindActiveClusters()
+	if err != nil {
+		log.Printf("[CERT-CHECK] Failed to fetch clusters: %v", err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, cluster := range clusters {
+		wg.Add(1)
+		go func(c model.Cluster) {
+			defer wg.Done()
+			w.checkCluster(&c)
+		}(*cluster)
+	}
+	wg.Wait()
+}
+
+func (w *CertificateExpiryWorker) checkCluster(cluster *model.Cluster) {
+	select {
+	case w.sem <- struct{}{}:
+	case <-w.ctx.Done():
+		return
+	}
+	defer func() { <-w.sem }()
+
+	ctx, cancel := context.WithTimeout(w.ctx, 2*time.Minute)
+	defer cancel()
+
+	certificates, err := w.certificateService.CheckCluster(ctx, cluster)
+	if err != nil {
+		log.Printf("[CERT-CHECK] Cluster %s: %v", cluster.Name, err)
+	}
+	log.Printf("[CERT-CHECK] Cluster %s: %d certificates checked", cluster.Name, len(certificates))
+}
@@ -18,6 +18,7 @@ type HealthCheckWorker struct {
 	stateRepo      *repository.ClusterStateRepository
 	clusterManager *service.ClusterManager
 	encryptionSvc  *service.EncryptionService
+	historySvc     *service.HealthHistoryService
 	wg             sync.WaitGroup
 	ctx            context.Context
 	cancel         context.CancelFunc
@@ -31,6 +32,7 @@ func NewHealthCheckWorker(
 	stateRepo *repository.ClusterStateRepository,
 	clusterManager *service.ClusterManager,
 	encryptionSvc *service.EncryptionService,
+	historySvc *service.HealthHistoryService,
 ) *HealthCheckWorker {
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -39,6 +41,7 @@ func NewHealthCheckWorker(
 		stateRepo:      stateRepo,
 		clusterManager: clusterManager,
 		encryptionSvc:  encryptionSvc,
+		historySvc:     historySvc,
 		ctx:            ctx,
 		cancel:         cancel,
 		checkInterval:  5 * time.Minute,
@@ -102,6 +105,12 @@ func (w *HealthCheckWorker) checkCluster(cluster model.Cluster) {
 	w.sem <- struct{}{}
 	defer func() { <-w.sem }()
 
+	// 熔断期内跳过，集群保持上次检查的状态
+	if err := w.clusterManager.AllowRequest(cluster.ID); err != nil {
+		log.Printf("[HEALTH-CHECK] Skipping cluster %s (ID: %s): %v", cluster.Name, cluster.ID.String(), err)
+		return
+	}
+
 	log.Printf("[HEALTH-CHECK] Starting check for cluster %s (ID: %s)", cluster.Name, cluster.ID.String())
 	log.Printf("[HEALTH-CHECK] Kubeconfig encrypted length: %d",
 		len(cluster.KubeconfigEncrypted))
@@ -119,7 +128,7 @@ func (w *HealthCheckWorker) checkCluster(cluster model.Cluster) {
 	ctx, cancel := context.WithTimeout(w.ctx, 30*time.Second)
 	defer cancel()
 
-	clientset, err := w.clusterManager.GetClient(ctx, kubeconfig)
+	clientset, err := w.clusterManager.GetClientForCluster(ctx, cluster.ID, kubeconfig)
 	if err != nil {
 		log.Printf("Failed to get client for cluster %s: %v", cluster.Name, err)
 		w.updateClusterState(cluster.ID, false, err.Error())
@@ -155,6 +164,7 @@ func (w *HealthCheckWorker) updateClusterState(
 				"last_heartbeat_at":  nil,
 				"last_sync_at":       time.Now(),
 				"sync_success":       false,
+				"details":            nil,
 				"updated_at":         time.Now(),
 			}).Error; err != nil {
 				return err
@@ -180,6 +190,7 @@ func (w *HealthCheckWorker) updateClusterState(
 					"last_sync_at":       time.Now(),
 					"sync_success":       true,
 					"sync_error":         "",
+					"details":            service.HealthDetails(healthResult.Components, healthResult.LastHeartbeatAt),
 					"updated_at":         time.Now(),
 				}).Error; err != nil {
 					return err
@@ -192,6 +203,29 @@ func (w *HealthCheckWorker) updateClusterState(
 
 	if err != nil {
 		log.Printf("Failed to update cluster state for %s: %v", clusterID, err)
+		return
+	}
+
+	w.recordHistory(clusterID, success, result)
+}
+
+// recordHistory 将检查结果写入健康历史，用于可用率与 SLO 统计
+func (w *HealthCheckWorker) recordHistory(clusterID uuid.UUID, success bool, result interface{}) {
+	if w.historySvc == nil {
+		return
+	}
+	status := "disconnected"
+	message := ""
+	if !success {
+		message, _ = result.(string)
+	} else if healthResult, ok := result.(*service.HealthCheckResult); ok {
+		status = healthResult.Status
+		message = service.HealthHistoryMessage(healthResult)
+	} else {
+		return
+	}
+	if err := w.historySvc.Record(clusterID, status, message, time.Now()); err != nil {
+		log.Printf("Failed to record health history for %s: %v", clusterID, err)
 	}
 }
 
@@ -0,0 +1,78 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/taichu-system/cluster-management/internal/service"
+)
+
+// HealthHistoryWorker 定期将过期的健康状态区间降采样并清理过期数据
+type HealthHistoryWorker struct {
+	historyService  *service.HealthHistoryService
+	wg              sync.WaitGroup
+	ctx             context.Context
+	cancel          context.CancelFunc
+	compactInterval time.Duration
+}
+
+// NewHealthHistoryWorker 创建健康历史降采样Worker
+func NewHealthHistoryWorker(historyService *service.HealthHistoryService, compactInterval time.Duration) *HealthHistoryWorker {
+	ctx, cancel := context.WithCancel(context.Background())
+	if compactInterval <= 0 {
+		compactInterval = time.Hour
+	}
+
+	return &HealthHistoryWorker{
+		historyService:  historyService,
+		ctx:             ctx,
+		cancel:          cancel,
+		compactInterval: compactInterval,
+	}
+}
+
+// Start 启动Worker
+func (w *HealthHistoryWorker) Start() {
+	log.Println("Starting health history worker...")
+
+	w.wg.Add(1)
+	go w.scheduler()
+}
+
+// Stop 停止Worker
+func (w *HealthHistoryWorker) Stop() {
+	log.Println("Stopping health history worker...")
+	w.cancel()
+	w.wg.Wait()
+}
+
+func (w *HealthHistoryWorker) scheduler() {
+	defer w.wg.Done()
+
+	w.compact()
+
+	ticker := time.NewTicker(w.compactInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			w.compact()
+		}
+	}
+}
+
+func (w *HealthHistoryWorker) compact() {
+	folded, deleted, err := w.historyService.Compact()
+	if err != nil {
+		log.Printf("[HEALTH-HISTORY] Compaction failed: %v", err)
+		return
+	}
+	if folded > 0 || deleted > 0 {
+		log.Printf("[HEALTH-HISTORY] Downsampled %d periods, deleted %d expired daily rows", folded, deleted)
+	}
+}
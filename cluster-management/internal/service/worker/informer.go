@@ -6,52 +6,66 @@ import (
 	"sync"
 	"time"
 
-	"gorm.io/gorm"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
-	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/workqueue"
 
 	"github.com/google/uuid"
 	"github.com/taichu-system/cluster-management/internal/model"
 	"github.com/taichu-system/cluster-management/internal/repository"
+	"github.com/taichu-system/cluster-management/internal/service"
 )
 
+// nodeFlushInterval 节点与事件变更的批量写入间隔
+const nodeFlushInterval = 10 * time.Second
+
 // ClusterInformer 管理 Kubernetes 集群的 Informer
 type ClusterInformer struct {
-	clusterID           uuid.UUID
-	kubeconfig          string
-	clientset           *kubernetes.Clientset
-	ctx                 context.Context
-	nodeInformer        cache.SharedIndexInformer
-	pvcInformer         cache.SharedIndexInformer
-	eventInformer       cache.SharedIndexInformer
+	clusterID     uuid.UUID
+	clientset     *kubernetes.Clientset
+	ctx           context.Context
+	nodeInformer  cache.SharedIndexInformer
+	pvcInformer   cache.SharedIndexInformer
+	eventInformer cache.SharedIndexInformer
+	// 三级模型相关 Informer，classification 为 nil 时不启用
+	namespaceInformer   cache.SharedIndexInformer
+	deploymentInformer  cache.SharedIndexInformer
+	statefulSetInformer cache.SharedIndexInformer
+	serviceInformer     cache.SharedIndexInformer
+	quotaInformer       cache.SharedIndexInformer
+	queue               workqueue.RateLimitingInterface
+	classification      *ClassificationSyncer
 	stopCh              chan struct{}
 	stopOnce            sync.Once
 	nodeRepo            *repository.NodeRepository
 	eventRepo           *repository.EventRepository
 	clusterResourceRepo *repository.ClusterResourceRepository
+	syncRepo            *repository.ResourceSyncRepository
 	cache               ResourceCache
+
+	// 待写入的节点与事件，按 nodeFlushInterval 合并为一个事务写入
+	pendingMu     sync.Mutex
+	dirtyNodes    map[string]struct{}
+	pendingEvents map[string]*corev1.Event
 }
 
-// NewClusterInformer 创建一个新的集群 Informer
+// NewClusterInformer 创建一个新的集群 Informer，config 需已应用集群的连接设置
 func NewClusterInformer(
 	ctx context.Context,
 	clusterID uuid.UUID,
-	kubeconfig string,
+	config *rest.Config,
 	nodeRepo *repository.NodeRepository,
 	eventRepo *repository.EventRepository,
 	clusterResourceRepo *repository.ClusterResourceRepository,
+	syncRepo *repository.ResourceSyncRepository,
 	cache ResourceCache,
+	classification *ClassificationSyncer,
 ) (*ClusterInformer, error) {
-	config, err := clientcmd.RESTConfigFromKubeConfig([]byte(kubeconfig))
-	if err != nil {
-		return nil, err
-	}
-
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, err
@@ -59,14 +73,17 @@ func NewClusterInformer(
 
 	informer := &ClusterInformer{
 		clusterID:           clusterID,
-		kubeconfig:          kubeconfig,
 		clientset:           clientset,
 		ctx:                 ctx,
 		stopCh:              make(chan struct{}),
 		nodeRepo:            nodeRepo,
 		eventRepo:           eventRepo,
 		clusterResourceRepo: clusterResourceRepo,
+		syncRepo:            syncRepo,
 		cache:               cache,
+		classification:      classification,
+		dirtyNodes:          make(map[string]struct{}),
+		pendingEvents:       make(map[string]*corev1.Event),
 	}
 
 	informer.initInformers()
@@ -119,6 +136,10 @@ func (ci *ClusterInformer) initInformers() {
 	)
 
 	ci.setupEventHandlers()
+
+	if ci.classification != nil {
+		ci.initClassificationInformers()
+	}
 }
 
 // setupEventHandlers 设置 Informer 的事件处理程序
@@ -134,8 +155,9 @@ func (ci *ClusterInformer) setupEventHandlers() {
 			ci.handleNodeUpdate(node)
 		},
 		DeleteFunc: func(obj interface{}) {
-			node := obj.(*corev1.Node)
-			ci.handleNodeDelete(node)
+			if node, ok := deletedObject(obj).(*corev1.Node); ok {
+				ci.handleNodeDelete(node)
+			}
 		},
 	})
 
@@ -150,8 +172,9 @@ func (ci *ClusterInformer) setupEventHandlers() {
 			ci.handlePVCUpdate(pvc)
 		},
 		DeleteFunc: func(obj interface{}) {
-			pvc := obj.(*corev1.PersistentVolumeClaim)
-			ci.handlePVCDelete(pvc)
+			if pvc, ok := deletedObject(obj).(*corev1.PersistentVolumeClaim); ok {
+				ci.handlePVCDelete(pvc)
+			}
 		},
 	})
 
@@ -174,6 +197,9 @@ func (ci *ClusterInformer) Start() {
 	go ci.nodeInformer.Run(ci.stopCh)
 	go ci.pvcInformer.Run(ci.stopCh)
 	go ci.eventInformer.Run(ci.stopCh)
+	if ci.classification != nil {
+		go ci.startClassificationInformers()
+	}
 
 	// 等待缓存同步
 	if !cache.WaitForCacheSync(ci.stopCh, ci.nodeInformer.HasSynced, ci.pvcInformer.HasSynced, ci.eventInformer.HasSynced) {
@@ -183,6 +209,12 @@ func (ci *ClusterInformer) Start() {
 
 	log.Printf("Successfully synced caches for cluster %s", ci.clusterID.String())
 
+	// 首次写入缓存同步期间积累的节点与事件，随后定时批量写入
+	// Informer 未运行期间被删除的节点不会产生删除事件，与数据库中的节点比较后一并删除
+	ci.markVanishedNodes()
+	ci.flushPendingChanges()
+	go ci.startPeriodicFlush()
+
 	// 缓存同步后，立即更新一次集群资源统计
 	ci.updateClusterResourceStats()
 
@@ -190,20 +222,50 @@ func (ci *ClusterInformer) Start() {
 	go ci.startPeriodicResourceStatsUpdate()
 }
 
-// Stop 停止 Informer
+// Stop 停止 Informer，写入最后一个批量写入周期内积累的变更，避免重建 Informer 时丢失
 func (ci *ClusterInformer) Stop() {
+	ci.stop(true)
+}
+
+// Discard 停止 Informer 并丢弃未写入的变更，用于集群已删除或停用的情况
+func (ci *ClusterInformer) Discard() {
+	ci.stop(false)
+}
+
+func (ci *ClusterInformer) stop(flush bool) {
 	ci.stopOnce.Do(func() {
 		log.Printf("Stopping informers for cluster %s", ci.clusterID.String())
 		close(ci.stopCh)
+		if ci.queue != nil {
+			ci.queue.ShutDown()
+		}
+		if flush {
+			ci.flushPendingChanges()
+		}
 	})
 }
 
+// markVanishedNodes 将数据库中存在但 Informer 缓存中没有的节点标记为待写入，批量写入时删除
+func (ci *ClusterInformer) markVanishedNodes() {
+	nodes, err := ci.nodeRepo.GetByClusterID(ci.clusterID.String())
+	if err != nil {
+		log.Printf("Failed to load nodes of cluster %s for reconciliation: %v", ci.clusterID.String(), err)
+		return
+	}
+	store := ci.nodeInformer.GetStore()
+	for _, node := range nodes {
+		if _, exists, err := store.GetByKey(node.Name); err == nil && !exists {
+			ci.markNodeDirty(node.Name)
+		}
+	}
+}
+
 // handleNodeAdd 处理节点添加事件
 func (ci *ClusterInformer) handleNodeAdd(node *corev1.Node) {
 	// 减少日志输出，避免冲掉正常应用日志
 	// log.Printf("Node added: %s in cluster %s", node.Name, ci.clusterID.String())
-	// 转换为内部模型并保存到数据库
-	ci.syncNodeToDB(node)
+	// 标记待写入，由定时批量写入处理
+	ci.markNodeDirty(node.Name)
 	// 移除频繁的集群资源统计更新，改为定时更新
 }
 
@@ -211,8 +273,8 @@ func (ci *ClusterInformer) handleNodeAdd(node *corev1.Node) {
 func (ci *ClusterInformer) handleNodeUpdate(node *corev1.Node) {
 	// 减少日志输出，避免冲掉正常应用日志
 	// log.Printf("Node updated: %s in cluster %s", node.Name, ci.clusterID.String())
-	// 转换为内部模型并更新到数据库
-	ci.syncNodeToDB(node)
+	// 标记待写入，由定时批量写入处理
+	ci.markNodeDirty(node.Name)
 	// 移除频繁的集群资源统计更新，改为定时更新
 }
 
@@ -220,8 +282,8 @@ func (ci *ClusterInformer) handleNodeUpdate(node *corev1.Node) {
 func (ci *ClusterInformer) handleNodeDelete(node *corev1.Node) {
 	// 减少日志输出，避免冲掉正常应用日志
 	// log.Printf("Node deleted: %s in cluster %s", node.Name, ci.clusterID.String())
-	// 从数据库中删除节点
-	ci.deleteNodeFromDB(node)
+	// 批量写入时节点已不在缓存中，将从数据库删除
+	ci.markNodeDirty(node.Name)
 	// 移除频繁的集群资源统计更新，改为定时更新
 }
 
@@ -250,7 +312,7 @@ func (ci *ClusterInformer) handleEventAdd(event *corev1.Event) {
 	// Informer模式下需要处理事件存储，因为传统模式的5分钟同步不会运行
 	// 只处理重要事件，避免数据库急剧增长
 	if ci.isImportantEvent(event) {
-		ci.syncEventToDB(event)
+		ci.queueEvent(event)
 	}
 }
 
@@ -259,58 +321,117 @@ func (ci *ClusterInformer) handleEventUpdate(event *corev1.Event) {
 	// Informer模式下需要处理事件存储，因为传统模式的5分钟同步不会运行
 	// 只处理重要事件，避免数据库急剧增长
 	if ci.isImportantEvent(event) {
-		ci.syncEventToDB(event)
+		ci.queueEvent(event)
 	}
 }
 
-// syncNodeToDB 同步节点信息到数据库
-func (ci *ClusterInformer) syncNodeToDB(node *corev1.Node) {
-	// 检查节点是否已存在
-	existingNode, err := ci.nodeRepo.GetByName(ci.clusterID.String(), node.Name)
-	if err != nil && err != gorm.ErrRecordNotFound {
-		log.Printf("Failed to check existing node %s: %v", node.Name, err)
+// markNodeDirty 记录发生变化的节点
+func (ci *ClusterInformer) markNodeDirty(name string) {
+	ci.pendingMu.Lock()
+	ci.dirtyNodes[name] = struct{}{}
+	ci.pendingMu.Unlock()
+}
+
+// queueEvent 记录待写入的事件，同一事件多次更新只写入最新状态
+func (ci *ClusterInformer) queueEvent(event *corev1.Event) {
+	ci.pendingMu.Lock()
+	ci.pendingEvents[string(event.UID)] = event
+	ci.pendingMu.Unlock()
+}
+
+// flushPendingChanges 将积累的节点与事件变更与上次写入的状态比较，在一个事务内批量写入
+// 写入失败时保留变更，下次重试
+func (ci *ClusterInformer) flushPendingChanges() {
+	ci.pendingMu.Lock()
+	dirtyNodes, pendingEvents := ci.dirtyNodes, ci.pendingEvents
+	ci.dirtyNodes = make(map[string]struct{})
+	ci.pendingEvents = make(map[string]*corev1.Event)
+	ci.pendingMu.Unlock()
+
+	if len(dirtyNodes) == 0 && len(pendingEvents) == 0 {
 		return
 	}
 
-	// 转换节点信息
-	internalNode := &model.Node{
-		ClusterID: ci.clusterID,
-		Name:      node.Name,
-		Type:      ci.getNodeType(node),
-		Status:    ci.getNodeStatus(node),
-		Labels:    ci.getNodeLabels(node),
+	err := ci.writePendingChanges(dirtyNodes, pendingEvents)
+	if err == nil {
+		return
 	}
+	log.Printf("Failed to write nodes and events for cluster %s: %v", ci.clusterID.String(), err)
 
-	// 如果节点已存在，使用原有ID
-	if err == nil && existingNode != nil {
-		internalNode.ID = existingNode.ID
+	ci.pendingMu.Lock()
+	for name := range dirtyNodes {
+		ci.dirtyNodes[name] = struct{}{}
+	}
+	for uid, event := range pendingEvents {
+		if _, newer := ci.pendingEvents[uid]; !newer {
+			ci.pendingEvents[uid] = event
+		}
 	}
+	ci.pendingMu.Unlock()
+}
 
-	// 设置资源信息
-	if allocatable := node.Status.Allocatable; allocatable != nil {
-		internalNode.CPUCores = int(allocatable.Cpu().MilliValue() / 1000)
-		internalNode.MemoryBytes = allocatable.Memory().Value()
+func (ci *ClusterInformer) writePendingChanges(dirtyNodes map[string]struct{}, pendingEvents map[string]*corev1.Event) error {
+	state, err := newResourceSyncState(ci.clusterID, ci.cache, ci.nodeRepo, ci.eventRepo)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(dirtyNodes))
+	for name := range dirtyNodes {
+		names = append(names, name)
+	}
+	if err := state.loadNodes(names); err != nil {
+		return err
+	}
+
+	for name := range dirtyNodes {
+		obj, exists, err := ci.nodeInformer.GetStore().GetByKey(name)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			state.deleteNode(name)
+			continue
+		}
+		ci.syncNodeToDB(state, obj.(*corev1.Node))
 	}
 
-	// 使用UpsertSingle方法创建或更新节点
-	if err := ci.nodeRepo.UpsertSingle(internalNode); err != nil {
-		log.Printf("Failed to upsert node %s: %v", node.Name, err)
+	events := make([]model.Event, 0, len(pendingEvents))
+	for _, event := range pendingEvents {
+		events = append(events, ci.syncEventToDB(state, event))
 	}
+	if err := state.putEvents(events); err != nil {
+		return err
+	}
+
+	_, err = state.commit(ci.syncRepo)
+	return err
 }
 
-// deleteNodeFromDB 从数据库中删除节点
-func (ci *ClusterInformer) deleteNodeFromDB(node *corev1.Node) {
-	// 获取节点信息
-	existingNode, err := ci.nodeRepo.GetByName(ci.clusterID.String(), node.Name)
-	if err != nil {
-		log.Printf("Failed to get node %s for deletion: %v", node.Name, err)
-		return
+// syncNodeToDB 将节点转换为内部模型加入写入批次
+func (ci *ClusterInformer) syncNodeToDB(state *resourceSyncState, node *corev1.Node) {
+	// 转换节点信息
+	internalNode := model.Node{
+		Name:   node.Name,
+		Type:   ci.getNodeType(node),
+		Status: ci.getNodeStatus(node),
+		Labels: ci.getNodeLabels(node),
+		Taints: service.FormatNodeTaints(node.Spec.Taints),
 	}
+	service.ApplyNodeSystemInfo(&internalNode, node.Status.NodeInfo)
 
-	// 从数据库删除节点
-	if err := ci.nodeRepo.Delete(existingNode.ID); err != nil {
-		log.Printf("Failed to delete node %s: %v", node.Name, err)
+	// Informer 不统计 Pod 数量，沿用已记录的值
+	if existing, ok := state.nodes[node.Name]; ok {
+		internalNode.PodCount = existing.PodCount
 	}
+
+	// 设置资源信息
+	if allocatable := node.Status.Allocatable; allocatable != nil {
+		internalNode.CPUCores = int(allocatable.Cpu().MilliValue() / 1000)
+		internalNode.MemoryBytes = allocatable.Memory().Value()
+	}
+
+	state.putNode(internalNode)
 }
 
 // getNodeStatus 获取节点状态
@@ -494,29 +615,10 @@ func (ci *ClusterInformer) isImportantEvent(event *corev1.Event) bool {
 	return true
 }
 
-// syncEventToDB 将事件同步到数据库
-func (ci *ClusterInformer) syncEventToDB(event *corev1.Event) {
-	// 检查事件是否已存在
-	exists, err := ci.eventRepo.CheckExists(
-		ci.clusterID.String(),
-		event.Type,
-		event.Message,
-		event.Source.Component,
-		event.FirstTimestamp.Time,
-		event.LastTimestamp.Time,
-	)
-	if err != nil {
-		log.Printf("Failed to check event existence: %v", err)
-		return
-	}
-	if exists {
-		return // 跳过已存在的事件
-	}
-
-	// 转换事件信息
-	dbEvent := &model.Event{
-		ID:             uuid.New(), // 生成新的UUID
-		ClusterID:      ci.clusterID,
+// syncEventToDB 将事件转换为内部模型，节点事件关联节点 ID
+func (ci *ClusterInformer) syncEventToDB(state *resourceSyncState, event *corev1.Event) model.Event {
+	dbEvent := model.Event{
+		EventUID:       string(event.UID),
 		EventType:      event.Type,
 		Message:        event.Message,
 		Severity:       ci.getEventSeverity(event.Type),
@@ -526,18 +628,19 @@ func (ci *ClusterInformer) syncEventToDB(event *corev1.Event) {
 		Count:          int(event.Count),
 	}
 
-	// 如果事件与特定节点相关，设置节点ID
-	if event.InvolvedObject.Kind == "Node" {
-		node, err := ci.nodeRepo.GetByName(ci.clusterID.String(), event.InvolvedObject.Name)
-		if err == nil && node != nil {
-			dbEvent.NodeID = &node.ID
-		}
+	// events.k8s.io 上报的事件只有 eventTime
+	if dbEvent.LastTimestamp.IsZero() {
+		dbEvent.LastTimestamp = event.EventTime.Time
+	}
+	if dbEvent.LastTimestamp.IsZero() {
+		dbEvent.LastTimestamp = event.CreationTimestamp.Time
 	}
 
-	// 创建事件
-	if err := ci.eventRepo.Create(dbEvent); err != nil {
-		log.Printf("Failed to create event: %v", err)
+	// 如果事件与特定节点相关，设置节点ID
+	if event.InvolvedObject.Kind == "Node" {
+		dbEvent.NodeID = state.nodeID(event.InvolvedObject.Name)
 	}
+	return dbEvent
 }
 
 // getEventSeverity 获取事件严重性级别
@@ -545,10 +648,24 @@ func (ci *ClusterInformer) getEventSeverity(eventType string) string {
 	switch eventType {
 	case "Warning":
 		return "warning"
-	case "Normal":
-		return "info"
 	default:
-		return "unknown"
+		// 批量写入时需满足 severity 约束
+		return "info"
+	}
+}
+
+// startPeriodicFlush 定时批量写入节点与事件变更
+func (ci *ClusterInformer) startPeriodicFlush() {
+	ticker := time.NewTicker(nodeFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ci.flushPendingChanges()
+		case <-ci.stopCh:
+			return
+		}
 	}
 }
 
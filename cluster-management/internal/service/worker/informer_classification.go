@@ -0,0 +1,624 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/taichu-system/cluster-management/internal/constants"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"github.com/taichu-system/cluster-management/internal/repository"
+	"github.com/taichu-system/cluster-management/internal/service"
+)
+
+const (
+	// classificationResyncPeriod 三级模型相关 Informer 的全量重放周期，用于修正遗漏的事件
+	classificationResyncPeriod = 30 * time.Minute
+	// classificationMaxRetries 单个待处理项失败后的最大重试次数
+	classificationMaxRetries = 5
+)
+
+// 工作队列中待处理项的类型
+const (
+	classificationKindNamespace   = "namespace"
+	classificationKindApplication = "application"
+	classificationKindQuota       = "quota"
+)
+
+// classificationKey 工作队列中的待处理项，同一命名空间或应用的多次事件在队列中合并
+type classificationKey struct {
+	kind      string
+	namespace string
+	app       string
+}
+
+// ClassificationSyncer 根据 Informer 事件增量维护三级模型：
+// 命名空间归类为环境，Deployment/StatefulSet/Service 聚合为应用，ResourceQuota 同步用量
+type ClassificationSyncer struct {
+	classifier         *service.ResourceClassifier
+	environmentRepo    *repository.EnvironmentRepository
+	applicationRepo    *repository.ApplicationRepository
+	quotaRepo          *repository.QuotaRepository
+	classificationRepo *repository.ResourceClassificationRepository
+}
+
+// NewClassificationSyncer 创建三级模型增量同步器
+func NewClassificationSyncer(
+	tenantRepo *repository.TenantRepository,
+	environmentRepo *repository.EnvironmentRepository,
+	applicationRepo *repository.ApplicationRepository,
+	quotaRepo *repository.QuotaRepository,
+	classificationRepo *repository.ResourceClassificationRepository,
+) *ClassificationSyncer {
+	return &ClassificationSyncer{
+		// 增量同步只使用不访问集群的分类方法
+		classifier:         service.NewResourceClassifier(nil, tenantRepo, environmentRepo, applicationRepo, quotaRepo),
+		environmentRepo:    environmentRepo,
+		applicationRepo:    applicationRepo,
+		quotaRepo:          quotaRepo,
+		classificationRepo: classificationRepo,
+	}
+}
+
+// initClassificationInformers 初始化命名空间、工作负载、Service 与 ResourceQuota 的 Informer
+func (ci *ClusterInformer) initClassificationInformers() {
+	ci.queue = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	namespaceIndexers := cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}
+
+	ci.namespaceInformer = cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return ci.clientset.CoreV1().Namespaces().List(ci.ctx, options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return ci.clientset.CoreV1().Namespaces().Watch(ci.ctx, options)
+			},
+		},
+		&corev1.Namespace{},
+		classificationResyncPeriod,
+		cache.Indexers{},
+	)
+
+	ci.deploymentInformer = cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return ci.clientset.AppsV1().Deployments("").List(ci.ctx, options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return ci.clientset.AppsV1().Deployments("").Watch(ci.ctx, options)
+			},
+		},
+		&appsv1.Deployment{},
+		classificationResyncPeriod,
+		namespaceIndexers,
+	)
+
+	ci.statefulSetInformer = cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return ci.clientset.AppsV1().StatefulSets("").List(ci.ctx, options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return ci.clientset.AppsV1().StatefulSets("").Watch(ci.ctx, options)
+			},
+		},
+		&appsv1.StatefulSet{},
+		classificationResyncPeriod,
+		namespaceIndexers,
+	)
+
+	ci.serviceInformer = cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return ci.clientset.CoreV1().Services("").List(ci.ctx, options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return ci.clientset.CoreV1().Services("").Watch(ci.ctx, options)
+			},
+		},
+		&corev1.Service{},
+		classificationResyncPeriod,
+		namespaceIndexers,
+	)
+
+	ci.quotaInformer = cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return ci.clientset.CoreV1().ResourceQuotas("").List(ci.ctx, options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return ci.clientset.CoreV1().ResourceQuotas("").Watch(ci.ctx, options)
+			},
+		},
+		&corev1.ResourceQuota{},
+		classificationResyncPeriod,
+		namespaceIndexers,
+	)
+
+	ci.setupClassificationHandlers()
+}
+
+// setupClassificationHandlers 事件处理程序只把受影响的命名空间或应用放入队列，由队列统一处理
+func (ci *ClusterInformer) setupClassificationHandlers() {
+	ci.namespaceInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: ci.enqueueNamespace,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			ci.enqueueNamespace(newObj)
+		},
+		DeleteFunc: ci.enqueueNamespace,
+	})
+
+	workloadHandler := cache.ResourceEventHandlerFuncs{
+		AddFunc: ci.enqueueApplication,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if !workloadChanged(oldObj, newObj) {
+				return
+			}
+			// 标签变化可能使工作负载从一个应用移到另一个应用
+			ci.enqueueApplication(oldObj)
+			ci.enqueueApplication(newObj)
+		},
+		DeleteFunc: ci.enqueueApplication,
+	}
+	ci.deploymentInformer.AddEventHandler(workloadHandler)
+	ci.statefulSetInformer.AddEventHandler(workloadHandler)
+	ci.serviceInformer.AddEventHandler(workloadHandler)
+
+	ci.quotaInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: ci.enqueueQuota,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldQuota, newQuota := oldObj.(*corev1.ResourceQuota), newObj.(*corev1.ResourceQuota)
+			if oldQuota.ResourceVersion != newQuota.ResourceVersion && equality.Semantic.DeepEqual(oldQuota.Status, newQuota.Status) {
+				return
+			}
+			ci.enqueueQuota(newObj)
+		},
+		DeleteFunc: ci.enqueueQuota,
+	})
+}
+
+// startClassificationInformers 启动三级模型相关 Informer，缓存同步后开始处理队列
+// 与节点等 Informer 分开等待，集群未授予 watch 权限时不影响节点与事件同步
+func (ci *ClusterInformer) startClassificationInformers() {
+	informers := []cache.SharedIndexInformer{
+		ci.namespaceInformer,
+		ci.deploymentInformer,
+		ci.statefulSetInformer,
+		ci.serviceInformer,
+		ci.quotaInformer,
+	}
+	synced := make([]cache.InformerSynced, 0, len(informers))
+	for _, informer := range informers {
+		go informer.Run(ci.stopCh)
+		synced = append(synced, informer.HasSynced)
+	}
+
+	if !cache.WaitForCacheSync(ci.stopCh, synced...) {
+		log.Printf("Failed to sync classification caches for cluster %s", ci.clusterID.String())
+		return
+	}
+	log.Printf("Classification caches synced for cluster %s, %d items queued", ci.clusterID.String(), ci.queue.Len())
+
+	for ci.processNextClassification() {
+	}
+}
+
+// processNextClassification 处理队列中的一项，失败时按限速重试
+func (ci *ClusterInformer) processNextClassification() bool {
+	item, shutdown := ci.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer ci.queue.Done(item)
+
+	key := item.(classificationKey)
+	var err error
+	switch key.kind {
+	case classificationKindNamespace:
+		err = ci.syncNamespace(key.namespace)
+	case classificationKindApplication:
+		err = ci.syncApplication(key.namespace, key.app)
+	case classificationKindQuota:
+		err = ci.syncQuota(key.namespace)
+	}
+	if err == nil {
+		ci.queue.Forget(item)
+		return true
+	}
+
+	if ci.queue.NumRequeues(item) < classificationMaxRetries {
+		ci.queue.AddRateLimited(item)
+		return true
+	}
+	log.Printf("Dropping %s %s/%s of cluster %s after %d retries: %v",
+		key.kind, key.namespace, key.app, ci.clusterID.String(), classificationMaxRetries, err)
+	ci.queue.Forget(item)
+	return true
+}
+
+func (ci *ClusterInformer) enqueueNamespace(obj interface{}) {
+	if ns, ok := deletedObject(obj).(*corev1.Namespace); ok {
+		ci.queue.Add(classificationKey{kind: classificationKindNamespace, namespace: ns.Name})
+	}
+}
+
+func (ci *ClusterInformer) enqueueApplication(obj interface{}) {
+	var namespace, app string
+	switch o := deletedObject(obj).(type) {
+	case *appsv1.Deployment:
+		namespace, app = o.Namespace, workloadAppName(o.Labels, o.Name)
+	case *appsv1.StatefulSet:
+		namespace, app = o.Namespace, workloadAppName(o.Labels, o.Name)
+	case *corev1.Service:
+		namespace, app = o.Namespace, serviceAppName(o)
+	}
+	if app == "" {
+		return
+	}
+	ci.queue.Add(classificationKey{kind: classificationKindApplication, namespace: namespace, app: app})
+}
+
+func (ci *ClusterInformer) enqueueQuota(obj interface{}) {
+	if quota, ok := deletedObject(obj).(*corev1.ResourceQuota); ok {
+		ci.queue.Add(classificationKey{kind: classificationKindQuota, namespace: quota.Namespace})
+	}
+}
+
+// enqueueNamespaceContents 命名空间刚归类为环境时，补充处理其中已有的应用与配额
+func (ci *ClusterInformer) enqueueNamespaceContents(namespace string) {
+	for _, informer := range []cache.SharedIndexInformer{ci.deploymentInformer, ci.statefulSetInformer, ci.serviceInformer} {
+		objs, err := informer.GetIndexer().ByIndex(cache.NamespaceIndex, namespace)
+		if err != nil {
+			continue
+		}
+		for _, obj := range objs {
+			ci.enqueueApplication(obj)
+		}
+	}
+	ci.queue.Add(classificationKey{kind: classificationKindQuota, namespace: namespace})
+}
+
+// syncNamespace 新命名空间归类为环境，删除或终止中的命名空间更新环境状态
+func (ci *ClusterInformer) syncNamespace(name string) error {
+	obj, exists, err := ci.namespaceInformer.GetStore().GetByKey(name)
+	if err != nil {
+		return err
+	}
+
+	env, err := ci.classification.environmentRepo.GetByNamespace(ci.clusterID.String(), name)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to get environment: %w", err)
+	}
+	if err != nil {
+		if exists {
+			return ci.classifyNamespace(*obj.(*corev1.Namespace))
+		}
+		return nil
+	}
+
+	status := model.EnvironmentStatusActive
+	if !exists {
+		status = model.EnvironmentStatusInactive
+	} else if obj.(*corev1.Namespace).DeletionTimestamp != nil {
+		status = model.EnvironmentStatusTerminating
+	}
+	if env.Status == status {
+		return nil
+	}
+
+	log.Printf("Environment %s of cluster %s: %s -> %s", name, ci.clusterID.String(), env.Status, status)
+	env.Status = status
+	if err := ci.classification.environmentRepo.Update(env); err != nil {
+		return fmt.Errorf("failed to update environment: %w", err)
+	}
+	if status == model.EnvironmentStatusActive {
+		ci.enqueueNamespaceContents(name)
+	}
+	return nil
+}
+
+// classifyNamespace 将命名空间归类到租户并创建环境，记录分类结果
+func (ci *ClusterInformer) classifyNamespace(namespace corev1.Namespace) error {
+	tenant, env, err := ci.classification.classifier.ClassifyNamespaceEnvironment(ci.clusterID.String(), namespace)
+	if err != nil {
+		ci.recordClassification(&model.ResourceClassification{
+			ResourceType: "namespace",
+			ResourceName: namespace.Name,
+			Namespace:    namespace.Name,
+			Status:       model.ClassificationStatusFailed,
+			ErrorMessage: err.Error(),
+		})
+		return fmt.Errorf("failed to classify namespace %s: %w", namespace.Name, err)
+	}
+
+	log.Printf("Classified namespace %s of cluster %s -> tenant: %s", namespace.Name, ci.clusterID.String(), tenant.Name)
+	ci.recordClassification(&model.ResourceClassification{
+		TenantID:           &tenant.ID,
+		EnvironmentID:      &env.ID,
+		ResourceType:       "namespace",
+		ResourceName:       namespace.Name,
+		Namespace:          namespace.Name,
+		AssignedTenant:     tenant.Name,
+		AssignedEnv:        env.Namespace,
+		ClassificationRule: "informer",
+		Status:             model.ClassificationStatusClassified,
+	})
+
+	ci.enqueueNamespaceContents(namespace.Name)
+	return nil
+}
+
+// applicationAggregate 从 Informer 缓存聚合出的应用信息
+type applicationAggregate struct {
+	workloadTypes   model.StringSlice
+	serviceNames    model.StringSlice
+	deploymentCount int
+	podCount        int
+	// 首个工作负载，新建应用时用于标签与分类记录
+	workloadKind   string
+	workloadName   string
+	workloadLabels map[string]string
+}
+
+// syncApplication 按命名空间内同名应用的工作负载与 Service 重新聚合应用
+// 工作负载全部删除后保留应用记录，仅将统计清零，避免丢失手工维护的应用信息
+func (ci *ClusterInformer) syncApplication(namespace, app string) error {
+	env, err := ci.classification.environmentRepo.GetByNamespace(ci.clusterID.String(), namespace)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 命名空间归类后会重新放入该命名空间的应用
+			return nil
+		}
+		return fmt.Errorf("failed to get environment: %w", err)
+	}
+
+	aggregate := ci.aggregateApplication(namespace, app)
+
+	existing, err := ci.classification.applicationRepo.GetByName(env.ID.String(), app)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to get application: %w", err)
+	}
+	if err != nil {
+		if aggregate.workloadName == "" {
+			return nil
+		}
+		application := &model.Application{
+			TenantID:        env.TenantID,
+			EnvironmentID:   env.ID,
+			Name:            app,
+			DisplayName:     app,
+			Description:     "自动发现的应用",
+			Labels:          service.CommonAppLabels(aggregate.workloadLabels),
+			WorkloadTypes:   aggregate.workloadTypes,
+			ServiceNames:    aggregate.serviceNames,
+			DeploymentCount: aggregate.deploymentCount,
+			PodCount:        aggregate.podCount,
+		}
+		if err := ci.classification.applicationRepo.Create(application); err != nil {
+			return fmt.Errorf("failed to create application: %w", err)
+		}
+		log.Printf("Discovered application %s in namespace %s of cluster %s", app, namespace, ci.clusterID.String())
+		ci.recordClassification(&model.ResourceClassification{
+			TenantID:           &env.TenantID,
+			EnvironmentID:      &env.ID,
+			ApplicationID:      &application.ID,
+			ResourceType:       aggregate.workloadKind,
+			ResourceName:       aggregate.workloadName,
+			Namespace:          namespace,
+			AssignedEnv:        env.Namespace,
+			AssignedApp:        app,
+			ClassificationRule: "informer",
+			Status:             model.ClassificationStatusClassified,
+		})
+		return nil
+	}
+
+	if reflect.DeepEqual([]string(existing.WorkloadTypes), []string(aggregate.workloadTypes)) &&
+		reflect.DeepEqual([]string(existing.ServiceNames), []string(aggregate.serviceNames)) &&
+		existing.DeploymentCount == aggregate.deploymentCount &&
+		existing.PodCount == aggregate.podCount {
+		return nil
+	}
+	existing.WorkloadTypes = aggregate.workloadTypes
+	existing.ServiceNames = aggregate.serviceNames
+	existing.DeploymentCount = aggregate.deploymentCount
+	existing.PodCount = aggregate.podCount
+	if err := ci.classification.applicationRepo.Update(existing); err != nil {
+		return fmt.Errorf("failed to update application: %w", err)
+	}
+	return nil
+}
+
+// aggregateApplication 统计命名空间内属于该应用的 Deployment、StatefulSet 与 Service
+func (ci *ClusterInformer) aggregateApplication(namespace, app string) applicationAggregate {
+	aggregate := applicationAggregate{
+		workloadTypes: model.StringSlice{},
+		serviceNames:  model.StringSlice{},
+	}
+	note := func(kind, name string, labels map[string]string) {
+		if aggregate.workloadName == "" || name < aggregate.workloadName {
+			aggregate.workloadKind = kind
+			aggregate.workloadName = name
+			aggregate.workloadLabels = labels
+		}
+	}
+
+	for _, obj := range namespaceObjects(ci.deploymentInformer, namespace) {
+		deployment := obj.(*appsv1.Deployment)
+		if workloadAppName(deployment.Labels, deployment.Name) != app {
+			continue
+		}
+		aggregate.deploymentCount++
+		aggregate.podCount += int(deployment.Status.Replicas)
+		note("deployment", deployment.Name, deployment.Labels)
+	}
+	if aggregate.deploymentCount > 0 {
+		aggregate.workloadTypes = append(aggregate.workloadTypes, "Deployment")
+	}
+
+	statefulSets := 0
+	for _, obj := range namespaceObjects(ci.statefulSetInformer, namespace) {
+		statefulSet := obj.(*appsv1.StatefulSet)
+		if workloadAppName(statefulSet.Labels, statefulSet.Name) != app {
+			continue
+		}
+		statefulSets++
+		aggregate.podCount += int(statefulSet.Status.Replicas)
+		if aggregate.deploymentCount == 0 {
+			note("statefulset", statefulSet.Name, statefulSet.Labels)
+		}
+	}
+	if statefulSets > 0 {
+		aggregate.workloadTypes = append(aggregate.workloadTypes, "StatefulSet")
+	}
+
+	for _, obj := range namespaceObjects(ci.serviceInformer, namespace) {
+		svc := obj.(*corev1.Service)
+		if serviceAppName(svc) == app {
+			aggregate.serviceNames = append(aggregate.serviceNames, svc.Name)
+		}
+	}
+	sort.Strings(aggregate.serviceNames)
+
+	return aggregate
+}
+
+// syncQuota 将命名空间的 ResourceQuota 硬限制与用量写入环境配额
+// 命名空间内有多个 ResourceQuota 时取名称最小的一个，与轮询分类保持一致；ResourceQuota 删除后保留记录
+func (ci *ClusterInformer) syncQuota(namespace string) error {
+	objs := namespaceObjects(ci.quotaInformer, namespace)
+	if len(objs) == 0 {
+		return nil
+	}
+	sort.Slice(objs, func(i, j int) bool {
+		return objs[i].(*corev1.ResourceQuota).Name < objs[j].(*corev1.ResourceQuota).Name
+	})
+	quota := objs[0].(*corev1.ResourceQuota)
+
+	env, err := ci.classification.environmentRepo.GetByNamespace(ci.clusterID.String(), namespace)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get environment: %w", err)
+	}
+
+	hardLimits := make(model.JSONMap)
+	for k, v := range quota.Status.Hard {
+		hardLimits[string(k)] = v.String()
+	}
+	used := make(model.JSONMap)
+	for k, v := range quota.Status.Used {
+		used[string(k)] = v.String()
+	}
+	now := time.Now()
+
+	existing, err := ci.classification.quotaRepo.GetResourceQuotaByEnvironmentID(env.ID.String())
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to get resource quota: %w", err)
+	}
+	if err != nil {
+		resourceQuota := &model.ResourceQuota{
+			EnvironmentID: env.ID,
+			HardLimits:    hardLimits,
+			Used:          used,
+			Status:        constants.StatusActive,
+			LastSyncedAt:  &now,
+		}
+		if err := ci.classification.quotaRepo.CreateResourceQuota(resourceQuota); err != nil {
+			return fmt.Errorf("failed to create resource quota: %w", err)
+		}
+		return nil
+	}
+
+	if reflect.DeepEqual(map[string]interface{}(existing.HardLimits), map[string]interface{}(hardLimits)) &&
+		reflect.DeepEqual(map[string]interface{}(existing.Used), map[string]interface{}(used)) {
+		return nil
+	}
+	existing.HardLimits = hardLimits
+	existing.Used = used
+	existing.LastSyncedAt = &now
+	if err := ci.classification.quotaRepo.UpdateResourceQuota(existing); err != nil {
+		return fmt.Errorf("failed to update resource quota: %w", err)
+	}
+	return nil
+}
+
+func (ci *ClusterInformer) recordClassification(classification *model.ResourceClassification) {
+	if ci.classification.classificationRepo == nil {
+		return
+	}
+	classification.ClusterID = ci.clusterID
+	if classification.Status == model.ClassificationStatusClassified {
+		now := time.Now()
+		classification.ClassifiedAt = &now
+	}
+	if err := ci.classification.classificationRepo.Create(classification); err != nil {
+		log.Printf("Failed to record classification of %s %s: %v", classification.ResourceType, classification.ResourceName, err)
+	}
+}
+
+// namespaceObjects 从 Informer 缓存中取出命名空间内的对象
+func namespaceObjects(informer cache.SharedIndexInformer, namespace string) []interface{} {
+	objs, err := informer.GetIndexer().ByIndex(cache.NamespaceIndex, namespace)
+	if err != nil {
+		return nil
+	}
+	return objs
+}
+
+// deletedObject 删除事件可能携带 DeletedFinalStateUnknown，取出其中的对象
+func deletedObject(obj interface{}) interface{} {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		return tombstone.Obj
+	}
+	return obj
+}
+
+// workloadAppName 工作负载所属应用，没有应用标签时使用工作负载名称
+func workloadAppName(labels map[string]string, name string) string {
+	if app := service.AppNameFromLabels(labels); app != "" {
+		return app
+	}
+	return name
+}
+
+// serviceAppName Service 所属应用，依次取标签与选择器中的应用名
+func serviceAppName(svc *corev1.Service) string {
+	if app := service.AppNameFromLabels(svc.Labels); app != "" {
+		return app
+	}
+	return service.AppNameFromLabels(svc.Spec.Selector)
+}
+
+// workloadChanged 判断更新事件是否影响应用聚合；资源版本相同的周期重放始终处理
+func workloadChanged(oldObj, newObj interface{}) bool {
+	switch o := newObj.(type) {
+	case *appsv1.Deployment:
+		old := oldObj.(*appsv1.Deployment)
+		return old.ResourceVersion == o.ResourceVersion ||
+			!reflect.DeepEqual(old.Labels, o.Labels) || old.Status.Replicas != o.Status.Replicas
+	case *appsv1.StatefulSet:
+		old := oldObj.(*appsv1.StatefulSet)
+		return old.ResourceVersion == o.ResourceVersion ||
+			!reflect.DeepEqual(old.Labels, o.Labels) || old.Status.Replicas != o.Status.Replicas
+	case *corev1.Service:
+		old := oldObj.(*corev1.Service)
+		return old.ResourceVersion == o.ResourceVersion ||
+			!reflect.DeepEqual(old.Labels, o.Labels) || !reflect.DeepEqual(old.Spec.Selector, o.Spec.Selector)
+	}
+	return true
+}
@@ -28,6 +28,7 @@ type InformerResourceSyncWorker struct {
 	securityPolicyRepo    *repository.SecurityPolicyRepository
 	clusterManager        *service.ClusterManager
 	encryptionSvc         *service.EncryptionService
+	syncRepo              *repository.ResourceSyncRepository
 	wg                    sync.WaitGroup
 	ctx                   context.Context
 	cancel                context.CancelFunc
@@ -37,10 +38,15 @@ type InformerResourceSyncWorker struct {
 
 	// Informer 管理
 	clusterInformers map[uuid.UUID]*ClusterInformer
-	informerMutex    sync.RWMutex
+	// 启动 Informer 时使用的加密 kubeconfig，凭据轮换或更新后据此重建 Informer
+	informerCredentials map[uuid.UUID]string
+	informerMutex       sync.RWMutex
 
 	// 缓存层
 	cache ResourceCache
+
+	// 三级模型增量同步，为 nil 时 Informer 不监听命名空间与工作负载
+	classification *ClassificationSyncer
 }
 
 // NewInformerResourceSyncWorker 创建一个新的基于 Informer 模式的资源同步工作器
@@ -54,6 +60,8 @@ func NewInformerResourceSyncWorker(
 	securityPolicyRepo *repository.SecurityPolicyRepository,
 	clusterManager *service.ClusterManager,
 	encryptionSvc *service.EncryptionService,
+	syncRepo *repository.ResourceSyncRepository,
+	classification *ClassificationSyncer,
 ) *InformerResourceSyncWorker {
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -70,13 +78,16 @@ func NewInformerResourceSyncWorker(
 		securityPolicyRepo:    securityPolicyRepo,
 		clusterManager:        clusterManager,
 		encryptionSvc:         encryptionSvc,
+		syncRepo:              syncRepo,
 		ctx:                   ctx,
 		cancel:                cancel,
 		syncInterval:          5 * time.Minute, // 修改为5分钟同步
 		maxConcurrency:        3,               // 降低并发数
 		sem:                   make(chan struct{}, 3),
 		clusterInformers:      make(map[uuid.UUID]*ClusterInformer),
+		informerCredentials:   make(map[uuid.UUID]string),
 		cache:                 cache,
+		classification:        classification,
 	}
 }
 
@@ -189,8 +200,9 @@ func (w *InformerResourceSyncWorker) syncClusterInformers() {
 	for clusterID, informer := range w.clusterInformers {
 		if !activeClusters[clusterID] {
 			log.Printf("Stopping informer for inactive cluster %s", clusterID.String())
-			informer.Stop()
+			informer.Discard()
 			delete(w.clusterInformers, clusterID)
+			delete(w.informerCredentials, clusterID)
 		}
 	}
 	w.informerMutex.Unlock()
@@ -206,9 +218,15 @@ func (w *InformerResourceSyncWorker) addClusterInformer(cluster model.Cluster) {
 	w.informerMutex.Lock()
 	defer w.informerMutex.Unlock()
 
-	// 检查是否已存在
-	if _, exists := w.clusterInformers[cluster.ID]; exists {
-		return
+	// 检查是否已存在，凭据变化时重建
+	if existing, exists := w.clusterInformers[cluster.ID]; exists {
+		if w.informerCredentials[cluster.ID] == cluster.KubeconfigEncrypted {
+			return
+		}
+		log.Printf("Credentials of cluster %s changed, restarting informer", cluster.Name)
+		existing.Stop()
+		delete(w.clusterInformers, cluster.ID)
+		delete(w.informerCredentials, cluster.ID)
 	}
 
 	// 解密 kubeconfig
@@ -218,15 +236,23 @@ func (w *InformerResourceSyncWorker) addClusterInformer(cluster model.Cluster) {
 		return
 	}
 
+	config, err := w.clusterManager.RESTConfigForCluster(cluster.ID, kubeconfig)
+	if err != nil {
+		log.Printf("Failed to build REST config for cluster %s: %v", cluster.Name, err)
+		return
+	}
+
 	// 创建 Informer
 	informer, err := NewClusterInformer(
 		w.ctx,
 		cluster.ID,
-		kubeconfig,
+		config,
 		w.nodeRepo,
 		w.eventRepo,
 		w.clusterResourceRepo,
+		w.syncRepo,
 		w.cache,
+		w.classification,
 	)
 	if err != nil {
 		log.Printf("Failed to create informer for cluster %s: %v", cluster.Name, err)
@@ -235,11 +261,25 @@ func (w *InformerResourceSyncWorker) addClusterInformer(cluster model.Cluster) {
 
 	// 保存并启动 Informer
 	w.clusterInformers[cluster.ID] = informer
+	w.informerCredentials[cluster.ID] = cluster.KubeconfigEncrypted
 	go informer.Start()
 
 	log.Printf("Started informer for cluster %s", cluster.Name)
 }
 
+// ClusterCredentialsChanged 集群凭据更换后立即以新凭据重建 Informer，无需等待下次集群同步
+func (w *InformerResourceSyncWorker) ClusterCredentialsChanged(clusterID uuid.UUID) {
+	if w.ctx.Err() != nil {
+		return
+	}
+	cluster, err := w.clusterRepo.GetByID(clusterID.String())
+	if err != nil {
+		log.Printf("Failed to fetch cluster %s: %v", clusterID, err)
+		return
+	}
+	w.addClusterInformer(*cluster)
+}
+
 // syncPoliciesForAllClusters 为所有集群同步策略
 func (w *InformerResourceSyncWorker) syncPoliciesForAllClusters() {
 	log.Println("Starting policy sync for all clusters...")
@@ -264,6 +304,11 @@ func (w *InformerResourceSyncWorker) syncClusterPolicies(cluster model.Cluster)
 	w.sem <- struct{}{}
 	defer func() { <-w.sem }()
 
+	if err := w.clusterManager.AllowRequest(cluster.ID); err != nil {
+		log.Printf("Skipping policy sync for cluster %s: %v", cluster.Name, err)
+		return
+	}
+
 	kubeconfig, err := w.encryptionSvc.Decrypt(cluster.KubeconfigEncrypted)
 	if err != nil {
 		log.Printf("Failed to decrypt kubeconfig for cluster %s: %v", cluster.Name, err)
@@ -273,7 +318,7 @@ func (w *InformerResourceSyncWorker) syncClusterPolicies(cluster model.Cluster)
 	ctx, cancel := context.WithTimeout(w.ctx, 30*time.Second)
 	defer cancel()
 
-	clientset, err := w.clusterManager.GetClient(ctx, kubeconfig)
+	clientset, err := w.clusterManager.GetClientForCluster(ctx, cluster.ID, kubeconfig)
 	if err != nil {
 		log.Printf("Failed to get client for cluster %s: %v", cluster.Name, err)
 		return
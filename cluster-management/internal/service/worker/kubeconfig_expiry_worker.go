@@ -0,0 +1,76 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/taichu-system/cluster-management/internal/service"
+)
+
+// KubeconfigExpiryWorker 定期将过期的用户 kubeconfig 标记为 expired 并清理集群内的 RoleBinding 与 ServiceAccount
+type KubeconfigExpiryWorker struct {
+	kubeconfigService *service.UserKubeconfigService
+	wg                sync.WaitGroup
+	ctx               context.Context
+	cancel            context.CancelFunc
+	interval          time.Duration
+}
+
+// NewKubeconfigExpiryWorker 创建用户 kubeconfig 过期清理Worker
+func NewKubeconfigExpiryWorker(kubeconfigService *service.UserKubeconfigService, interval time.Duration) *KubeconfigExpiryWorker {
+	ctx, cancel := context.WithCancel(context.Background())
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+
+	return &KubeconfigExpiryWorker{
+		kubeconfigService: kubeconfigService,
+		ctx:               ctx,
+		cancel:            cancel,
+		interval:          interval,
+	}
+}
+
+// Start 启动Worker
+func (w *KubeconfigExpiryWorker) Start() {
+	log.Println("Starting kubeconfig expiry worker...")
+
+	w.wg.Add(1)
+	go w.scheduler()
+}
+
+// Stop 停止Worker
+func (w *KubeconfigExpiryWorker) Stop() {
+	log.Println("Stopping kubeconfig expiry worker...")
+	w.cancel()
+	w.wg.Wait()
+}
+
+func (w *KubeconfigExpiryWorker) scheduler() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			w.expire()
+		}
+	}
+}
+
+func (w *KubeconfigExpiryWorker) expire() {
+	expired, err := w.kubeconfigService.ExpireDue(w.ctx)
+	if err != nil {
+		log.Printf("[KUBECONFIG] Expiry cleanup failed: %v", err)
+		return
+	}
+	if expired > 0 {
+		log.Printf("[KUBECONFIG] Expired %d user kubeconfigs", expired)
+	}
+}
@@ -0,0 +1,78 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/taichu-system/cluster-management/internal/service"
+)
+
+// ManagerTokenRotationWorker 定期轮换最小权限 ServiceAccount 即将过期的令牌
+type ManagerTokenRotationWorker struct {
+	managerAccounts *service.ManagerAccountService
+	wg              sync.WaitGroup
+	ctx             context.Context
+	cancel          context.CancelFunc
+	interval        time.Duration
+}
+
+// NewManagerTokenRotationWorker 创建 ServiceAccount 令牌轮换Worker
+func NewManagerTokenRotationWorker(managerAccounts *service.ManagerAccountService, interval time.Duration) *ManagerTokenRotationWorker {
+	ctx, cancel := context.WithCancel(context.Background())
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	return &ManagerTokenRotationWorker{
+		managerAccounts: managerAccounts,
+		ctx:             ctx,
+		cancel:          cancel,
+		interval:        interval,
+	}
+}
+
+// Start 启动Worker
+func (w *ManagerTokenRotationWorker) Start() {
+	log.Println("Starting manager token rotation worker...")
+
+	w.wg.Add(1)
+	go w.scheduler()
+}
+
+// Stop 停止Worker
+func (w *ManagerTokenRotationWorker) Stop() {
+	log.Println("Stopping manager token rotation worker...")
+	w.cancel()
+	w.wg.Wait()
+}
+
+func (w *ManagerTokenRotationWorker) scheduler() {
+	defer w.wg.Done()
+
+	w.rotate()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			w.rotate()
+		}
+	}
+}
+
+func (w *ManagerTokenRotationWorker) rotate() {
+	rotated, err := w.managerAccounts.RotateDue(w.ctx)
+	if err != nil {
+		log.Printf("[MANAGER-SA] Token rotation failed: %v", err)
+		return
+	}
+	if rotated > 0 {
+		log.Printf("[MANAGER-SA] Rotated %d service account tokens", rotated)
+	}
+}
@@ -130,6 +130,11 @@ func (w *ResourceClassificationWorker) classifyClusterResources(cluster model.Cl
 	w.sem <- struct{}{}
 	defer func() { <-w.sem }()
 
+	if err := w.clusterManager.AllowRequest(cluster.ID); err != nil {
+		log.Printf("Skipping resource classification for cluster %s: %v", cluster.Name, err)
+		return
+	}
+
 	log.Printf("Classifying resources for cluster: %s", cluster.Name)
 
 	// 创建分类历史记录
@@ -160,7 +165,7 @@ func (w *ResourceClassificationWorker) classifyClusterResources(cluster model.Cl
 	defer cancel()
 
 	// 获取K8s客户端
-	clientset, err := w.clusterManager.GetClient(ctx, kubeconfig)
+	clientset, err := w.clusterManager.GetClientForCluster(ctx, cluster.ID, kubeconfig)
 	if err != nil {
 		log.Printf("Failed to get client for cluster %s: %v", cluster.Name, err)
 		w.updateHistoryStatus(history, model.ClassificationHistoryStatusFailed, err.Error())
@@ -0,0 +1,329 @@
+package worker
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"github.com/taichu-system/cluster-management/internal/repository"
+)
+
+const (
+	// eventBaselineWindow 缓存中保留的已写入事件的时间范围，更早的事件按需从数据库加载
+	eventBaselineWindow = time.Hour
+	// eventLookupBatchSize 按 UID 加载事件基线时每次查询的数量
+	eventLookupBatchSize = 500
+)
+
+// resourceSyncState 单个集群一次同步的节点与事件变更
+// 以 ResourceCache 中上次写入的状态为基线计算差异，只写入发生变化的行；缓存失效时从数据库加载基线
+// 基线中没有的节点与事件按名称或 UID 从数据库补充，沿用已有记录的 ID
+type resourceSyncState struct {
+	clusterID uuid.UUID
+	cache     ResourceCache
+	nodeRepo  *repository.NodeRepository
+	eventRepo *repository.EventRepository
+	nodes     map[string]model.Node
+	events    map[string]model.Event
+	batch     repository.ResourceSyncBatch
+
+	unchangedNodes  int
+	unchangedEvents int
+}
+
+// newResourceSyncState 加载集群的节点与事件基线
+func newResourceSyncState(clusterID uuid.UUID, cache ResourceCache, nodeRepo *repository.NodeRepository, eventRepo *repository.EventRepository) (*resourceSyncState, error) {
+	s := &resourceSyncState{
+		clusterID: clusterID,
+		cache:     cache,
+		nodeRepo:  nodeRepo,
+		eventRepo: eventRepo,
+		nodes:     make(map[string]model.Node),
+		events:    make(map[string]model.Event),
+		batch:     repository.ResourceSyncBatch{ClusterID: clusterID},
+	}
+
+	nodes, ok := cache.GetNodes(clusterID)
+	if !ok {
+		var err error
+		nodes, err = nodeRepo.GetByClusterID(clusterID.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load nodes: %w", err)
+		}
+	}
+	for _, node := range nodes {
+		s.nodes[node.Name] = node
+	}
+
+	// 缓存中没有的事件在 putEvents 中按 UID 从数据库加载
+	if events, ok := cache.GetEvents(clusterID); ok {
+		for _, event := range events {
+			s.events[event.EventUID] = event
+		}
+	}
+	return s, nil
+}
+
+// loadNodes 从数据库加载基线中没有的节点，缓存未包含的已有节点沿用数据库中的 ID，删除时也能找到
+func (s *resourceSyncState) loadNodes(names []string) error {
+	var missing []string
+	for _, name := range names {
+		if _, ok := s.nodes[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	stored, err := s.nodeRepo.ListByNames(s.clusterID.String(), missing)
+	if err != nil {
+		return fmt.Errorf("failed to load nodes: %w", err)
+	}
+	for _, node := range stored {
+		s.nodes[node.Name] = node
+	}
+	return nil
+}
+
+// setNodes 以集群当前的全部节点计算差异，基线中已不存在的节点将被删除
+func (s *resourceSyncState) setNodes(current []model.Node) error {
+	names := make([]string, 0, len(current))
+	for _, node := range current {
+		names = append(names, node.Name)
+	}
+	if err := s.loadNodes(names); err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(current))
+	for _, node := range current {
+		seen[node.Name] = true
+		s.putNode(node)
+	}
+	for name := range s.nodes {
+		if !seen[name] {
+			s.deleteNode(name)
+		}
+	}
+	return nil
+}
+
+// putNode 节点与基线不同时加入写入批次，沿用基线中的节点 ID；调用前应先以 loadNodes 加载基线中没有的节点
+func (s *resourceSyncState) putNode(node model.Node) {
+	node.ClusterID = s.clusterID
+	// 批量插入时零值列写入 NULL 而非列默认值
+	if node.Labels == nil {
+		node.Labels = model.JSONMap{}
+	}
+	if node.Taints == nil {
+		node.Taints = model.StringSlice{}
+	}
+	if existing, ok := s.nodes[node.Name]; ok {
+		node.ID = existing.ID
+		node.CreatedAt = existing.CreatedAt
+		if !nodeChanged(existing, node) {
+			s.unchangedNodes++
+			return
+		}
+	} else if node.ID == uuid.Nil {
+		node.ID = uuid.New()
+	}
+	s.nodes[node.Name] = node
+	s.batch.UpsertNodes = append(s.batch.UpsertNodes, node)
+}
+
+// deleteNode 基线中存在的节点加入删除批次
+func (s *resourceSyncState) deleteNode(name string) {
+	if _, ok := s.nodes[name]; !ok {
+		return
+	}
+	delete(s.nodes, name)
+	s.batch.DeleteNodes = append(s.batch.DeleteNodes, name)
+}
+
+// nodeID 获取节点 ID，用于关联节点事件
+func (s *resourceSyncState) nodeID(name string) *uuid.UUID {
+	node, ok := s.nodes[name]
+	if !ok {
+		return nil
+	}
+	id := node.ID
+	return &id
+}
+
+// putEvents 事件与基线不同时加入写入批次，基线中没有的事件先按 UID 从数据库加载
+// 仍未找到的事件与未记录 UID 的历史事件按类型、组件、消息与首次发生时间匹配，匹配成功时更新历史事件并补写 UID
+func (s *resourceSyncState) putEvents(events []model.Event) error {
+	var missing []string
+	for _, event := range events {
+		if _, ok := s.events[event.EventUID]; !ok {
+			missing = append(missing, event.EventUID)
+		}
+	}
+	for start := 0; start < len(missing); start += eventLookupBatchSize {
+		end := start + eventLookupBatchSize
+		if end > len(missing) {
+			end = len(missing)
+		}
+		stored, err := s.eventRepo.ListByUIDs(s.clusterID.String(), missing[start:end])
+		if err != nil {
+			return fmt.Errorf("failed to load events: %w", err)
+		}
+		for _, event := range stored {
+			s.events[event.EventUID] = event
+		}
+	}
+	legacy, err := s.loadLegacyEvents(events)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		event.ClusterID = s.clusterID
+		if event.Count < 1 {
+			event.Count = 1
+		}
+		if existing, ok := s.events[event.EventUID]; ok {
+			event.ID = existing.ID
+			event.CreatedAt = existing.CreatedAt
+			if !eventChanged(existing, event) {
+				s.unchangedEvents++
+				continue
+			}
+		} else if existing, ok := legacy[legacyEventKey(event)]; ok {
+			delete(legacy, legacyEventKey(event))
+			event.ID = existing.ID
+			event.CreatedAt = existing.CreatedAt
+			s.events[event.EventUID] = event
+			s.batch.AdoptEvents = append(s.batch.AdoptEvents, event)
+			continue
+		} else if event.ID == uuid.Nil {
+			event.ID = uuid.New()
+		}
+		s.events[event.EventUID] = event
+		s.batch.UpsertEvents = append(s.batch.UpsertEvents, event)
+	}
+	return nil
+}
+
+// loadLegacyEvents 加载与基线中没有的事件首次发生时间相同、尚未记录 UID 的历史事件
+func (s *resourceSyncState) loadLegacyEvents(events []model.Event) (map[string]model.Event, error) {
+	var timestamps []time.Time
+	seen := make(map[int64]bool)
+	for _, event := range events {
+		if _, ok := s.events[event.EventUID]; ok || event.FirstTimestamp.IsZero() {
+			continue
+		}
+		if second := event.FirstTimestamp.Unix(); !seen[second] {
+			seen[second] = true
+			timestamps = append(timestamps, event.FirstTimestamp)
+		}
+	}
+
+	legacy := make(map[string]model.Event)
+	for start := 0; start < len(timestamps); start += eventLookupBatchSize {
+		end := start + eventLookupBatchSize
+		if end > len(timestamps) {
+			end = len(timestamps)
+		}
+		stored, err := s.eventRepo.ListWithoutUID(s.clusterID.String(), timestamps[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("failed to load legacy events: %w", err)
+		}
+		for _, event := range stored {
+			legacy[legacyEventKey(event)] = event
+		}
+	}
+	return legacy, nil
+}
+
+// legacyEventKey 未记录 UID 的历史事件的匹配键，与原先按内容去重的字段一致
+func legacyEventKey(event model.Event) string {
+	return fmt.Sprintf("%s|%s|%s|%d", event.EventType, event.Component, event.Message, event.FirstTimestamp.Unix())
+}
+
+// commit 在一个事务内写入变更，成功后以写入后的状态更新缓存，失败时清除缓存以便下次从数据库重新加载
+func (s *resourceSyncState) commit(repo *repository.ResourceSyncRepository) (repository.ResourceSyncResult, error) {
+	result, err := repo.Apply(&s.batch)
+	if err != nil {
+		s.cache.InvalidateCluster(s.clusterID)
+		return result, err
+	}
+
+	nodes := make([]model.Node, 0, len(s.nodes))
+	for _, node := range s.nodes {
+		nodes = append(nodes, node)
+	}
+	s.cache.SetNodes(s.clusterID, nodes)
+
+	cutoff := time.Now().Add(-eventBaselineWindow)
+	events := make([]model.Event, 0, len(s.events))
+	for _, event := range s.events {
+		if event.LastTimestamp.After(cutoff) {
+			events = append(events, event)
+		}
+	}
+	s.cache.SetEvents(s.clusterID, events)
+
+	if !s.batch.Empty() {
+		log.Printf("[RESOURCE-SYNC] Cluster %s: %d rows written (nodes: %d upserted, %d deleted, %d unchanged; events: %d upserted, %d unchanged)",
+			s.clusterID, result.RowsWritten(), result.NodesUpserted, result.NodesDeleted, s.unchangedNodes, result.EventsUpserted, s.unchangedEvents)
+	}
+	return result, nil
+}
+
+// nodeChanged 比较同步写入的节点字段，CPU/内存用量不参与比较
+func nodeChanged(old, new model.Node) bool {
+	return old.Type != new.Type ||
+		old.Status != new.Status ||
+		old.CPUCores != new.CPUCores ||
+		old.MemoryBytes != new.MemoryBytes ||
+		old.PodCount != new.PodCount ||
+		!equalJSONMap(old.Labels, new.Labels) ||
+		!equalStrings(old.Taints, new.Taints) ||
+		old.KubeletVersion != new.KubeletVersion ||
+		old.KubeProxyVersion != new.KubeProxyVersion ||
+		old.ContainerRuntime != new.ContainerRuntime ||
+		old.ContainerRuntimeVersion != new.ContainerRuntimeVersion ||
+		old.OSImage != new.OSImage ||
+		old.KernelVersion != new.KernelVersion ||
+		old.Architecture != new.Architecture
+}
+
+// eventChanged 比较同步写入的事件字段
+func eventChanged(old, new model.Event) bool {
+	return !reflect.DeepEqual(old.NodeID, new.NodeID) ||
+		old.EventType != new.EventType ||
+		old.Message != new.Message ||
+		old.Severity != new.Severity ||
+		old.Component != new.Component ||
+		!old.FirstTimestamp.Equal(new.FirstTimestamp) ||
+		!old.LastTimestamp.Equal(new.LastTimestamp) ||
+		old.Count != new.Count
+}
+
+// equalJSONMap 比较标签，nil 与空 map 视为相同
+func equalJSONMap(a, b model.JSONMap) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || !reflect.DeepEqual(v, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalStrings 比较字符串切片，nil 与空切片视为相同
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
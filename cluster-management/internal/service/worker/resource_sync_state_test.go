@@ -0,0 +1,490 @@
+package worker
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taichu-system/cluster-management/internal/model"
+	"github.com/taichu-system/cluster-management/internal/repository"
+	"github.com/taichu-system/cluster-management/internal/testutil"
+	"gorm.io/gorm"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+)
+
+// resourceSyncFixture 一个集群的节点、事件仓库与资源缓存
+type resourceSyncFixture struct {
+	db        *gorm.DB
+	clusterID uuid.UUID
+	cache     *MemoryResourceCache
+	nodeRepo  *repository.NodeRepository
+	eventRepo *repository.EventRepository
+	syncRepo  *repository.ResourceSyncRepository
+}
+
+func newResourceSyncFixture(t *testing.T) *resourceSyncFixture {
+	t.Helper()
+	db := testutil.OpenDB(t, &model.Cluster{}, &model.Node{}, &model.Event{})
+	// 与迁移 001、047 相同的唯一索引
+	for _, sql := range []string{
+		`CREATE UNIQUE INDEX uq_nodes_cluster_name ON nodes(cluster_id, name)`,
+		`CREATE UNIQUE INDEX uq_events_cluster_event_uid ON events(cluster_id, event_uid) WHERE event_uid <> ''`,
+	} {
+		if err := db.Exec(sql).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cluster := &model.Cluster{ID: uuid.New(), Name: "sync-" + uuid.NewString()[:8]}
+	if err := db.Create(cluster).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &resourceSyncFixture{
+		db:        db,
+		clusterID: cluster.ID,
+		cache:     NewMemoryResourceCache(time.Hour),
+		nodeRepo:  repository.NewNodeRepository(db),
+		eventRepo: repository.NewEventRepository(db),
+		syncRepo:  repository.NewResourceSyncRepository(db),
+	}
+}
+
+func (f *resourceSyncFixture) state(t *testing.T) *resourceSyncState {
+	t.Helper()
+	state, err := newResourceSyncState(f.clusterID, f.cache, f.nodeRepo, f.eventRepo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return state
+}
+
+func (f *resourceSyncFixture) createNode(t *testing.T, name, status string) model.Node {
+	t.Helper()
+	node := model.Node{
+		ID:        uuid.New(),
+		ClusterID: f.clusterID,
+		Name:      name,
+		Type:      "worker",
+		Status:    status,
+		Labels:    model.JSONMap{},
+		Taints:    model.StringSlice{},
+	}
+	if err := f.db.Create(&node).Error; err != nil {
+		t.Fatal(err)
+	}
+	return node
+}
+
+func (f *resourceSyncFixture) storedNodes(t *testing.T) map[string]model.Node {
+	t.Helper()
+	nodes, err := f.nodeRepo.GetByClusterID(f.clusterID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]model.Node, len(nodes))
+	for _, node := range nodes {
+		byName[node.Name] = node
+	}
+	return byName
+}
+
+func (f *resourceSyncFixture) storedEvents(t *testing.T) []model.Event {
+	t.Helper()
+	var events []model.Event
+	if err := f.db.Where("cluster_id = ?", f.clusterID).Find(&events).Error; err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+func workerNode(name, status string) model.Node {
+	return model.Node{Name: name, Type: "worker", Status: status}
+}
+
+func nodeNames(nodes []model.Node) []string {
+	names := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func equalNames(a, b []string) bool {
+	sort.Strings(a)
+	sort.Strings(b)
+	return equalStrings(a, b)
+}
+
+func TestResourceSyncStateNodeDiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		stored    map[string]string
+		current   []model.Node
+		upserted  []string
+		deleted   []string
+		unchanged int
+	}{
+		{
+			name:     "new nodes are inserted",
+			current:  []model.Node{workerNode("node-1", "Ready"), workerNode("node-2", "Ready")},
+			upserted: []string{"node-1", "node-2"},
+		},
+		{
+			name:      "unchanged nodes are skipped",
+			stored:    map[string]string{"node-1": "Ready"},
+			current:   []model.Node{workerNode("node-1", "Ready")},
+			unchanged: 1,
+		},
+		{
+			name:     "changed nodes are updated",
+			stored:   map[string]string{"node-1": "Ready"},
+			current:  []model.Node{workerNode("node-1", "NotReady")},
+			upserted: []string{"node-1"},
+		},
+		{
+			name:      "vanished nodes are deleted",
+			stored:    map[string]string{"node-1": "Ready", "node-2": "Ready"},
+			current:   []model.Node{workerNode("node-1", "Ready")},
+			deleted:   []string{"node-2"},
+			unchanged: 1,
+		},
+		{
+			name:    "all nodes vanished",
+			stored:  map[string]string{"node-1": "Ready"},
+			deleted: []string{"node-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newResourceSyncFixture(t)
+			ids := make(map[string]uuid.UUID)
+			for name, status := range tt.stored {
+				ids[name] = f.createNode(t, name, status).ID
+			}
+
+			state := f.state(t)
+			if err := state.setNodes(tt.current); err != nil {
+				t.Fatal(err)
+			}
+			if got := nodeNames(state.batch.UpsertNodes); !equalNames(got, tt.upserted) {
+				t.Errorf("upserted = %v, want %v", got, tt.upserted)
+			}
+			if !equalNames(state.batch.DeleteNodes, tt.deleted) {
+				t.Errorf("deleted = %v, want %v", state.batch.DeleteNodes, tt.deleted)
+			}
+			if state.unchangedNodes != tt.unchanged {
+				t.Errorf("unchanged = %d, want %d", state.unchangedNodes, tt.unchanged)
+			}
+
+			if _, err := state.commit(f.syncRepo); err != nil {
+				t.Fatal(err)
+			}
+			stored := f.storedNodes(t)
+			if len(stored) != len(tt.current) {
+				t.Errorf("stored %d nodes, want %d", len(stored), len(tt.current))
+			}
+			for _, node := range tt.current {
+				row, ok := stored[node.Name]
+				if !ok {
+					t.Errorf("node %s was not stored", node.Name)
+					continue
+				}
+				if row.Status != node.Status {
+					t.Errorf("node %s status = %q, want %q", node.Name, row.Status, node.Status)
+				}
+				if id, ok := ids[node.Name]; ok && row.ID != id {
+					t.Errorf("node %s id changed from %s to %s", node.Name, id, row.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestResourceSyncStateReusesStoredNodeMissingFromCache(t *testing.T) {
+	f := newResourceSyncFixture(t)
+	cached := f.createNode(t, "node-1", "Ready")
+	stored := f.createNode(t, "node-2", "Ready")
+	// 缓存中只有 node-1，node-2 由其他途径写入数据库
+	f.cache.SetNodes(f.clusterID, []model.Node{cached})
+
+	state := f.state(t)
+	if err := state.setNodes([]model.Node{workerNode("node-1", "Ready"), workerNode("node-2", "NotReady")}); err != nil {
+		t.Fatal(err)
+	}
+	if len(state.batch.UpsertNodes) != 1 || state.batch.UpsertNodes[0].ID != stored.ID {
+		t.Fatalf("upserted = %+v, want node-2 with id %s", state.batch.UpsertNodes, stored.ID)
+	}
+	if _, err := state.commit(f.syncRepo); err != nil {
+		t.Fatal(err)
+	}
+
+	nodes, _ := f.cache.GetNodes(f.clusterID)
+	for _, node := range nodes {
+		if node.Name == "node-2" && node.ID != stored.ID {
+			t.Errorf("cached node-2 id = %s, want %s", node.ID, stored.ID)
+		}
+	}
+	if row := f.storedNodes(t)["node-2"]; row.ID != stored.ID || row.Status != "NotReady" {
+		t.Errorf("stored node-2 = %s/%s, want %s/NotReady", row.ID, row.Status, stored.ID)
+	}
+}
+
+func TestResourceSyncStateEventDiff(t *testing.T) {
+	f := newResourceSyncFixture(t)
+	first := time.Now().Add(-10 * time.Minute).Truncate(time.Second)
+	event := model.Event{
+		EventUID:       "uid-1",
+		EventType:      "Warning",
+		Message:        "Back-off restarting failed container",
+		Severity:       "warning",
+		Component:      "kubelet",
+		FirstTimestamp: first,
+		LastTimestamp:  first,
+		Count:          1,
+	}
+
+	state := f.state(t)
+	if err := state.putEvents([]model.Event{event}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := state.commit(f.syncRepo); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		mutate    func(*model.Event)
+		upserted  int
+		unchanged int
+		count     int
+	}{
+		{name: "same event is skipped", mutate: func(*model.Event) {}, unchanged: 1, count: 1},
+		{
+			name: "recurring event is updated in place",
+			mutate: func(e *model.Event) {
+				e.Count = 3
+				e.LastTimestamp = first.Add(5 * time.Minute)
+			},
+			upserted: 1,
+			count:    3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 清除缓存，基线按 UID 从数据库加载
+			f.cache.InvalidateCluster(f.clusterID)
+			next := event
+			tt.mutate(&next)
+
+			state := f.state(t)
+			if err := state.putEvents([]model.Event{next}); err != nil {
+				t.Fatal(err)
+			}
+			if len(state.batch.UpsertEvents) != tt.upserted || state.unchangedEvents != tt.unchanged {
+				t.Errorf("upserted = %d, unchanged = %d, want %d and %d",
+					len(state.batch.UpsertEvents), state.unchangedEvents, tt.upserted, tt.unchanged)
+			}
+			if _, err := state.commit(f.syncRepo); err != nil {
+				t.Fatal(err)
+			}
+
+			events := f.storedEvents(t)
+			if len(events) != 1 {
+				t.Fatalf("stored %d events, want 1", len(events))
+			}
+			if events[0].Count != tt.count {
+				t.Errorf("count = %d, want %d", events[0].Count, tt.count)
+			}
+		})
+	}
+}
+
+func TestResourceSyncStateAdoptsLegacyEvents(t *testing.T) {
+	f := newResourceSyncFixture(t)
+	first := time.Now().Add(-10 * time.Minute).Truncate(time.Second)
+	legacy := model.Event{
+		ID:             uuid.New(),
+		ClusterID:      f.clusterID,
+		EventType:      "Warning",
+		Message:        "Back-off restarting failed container",
+		Severity:       "warning",
+		Component:      "kubelet",
+		FirstTimestamp: first,
+		LastTimestamp:  first,
+		Count:          1,
+	}
+	other := legacy
+	other.ID = uuid.New()
+	other.Message = "Readiness probe failed"
+	for _, event := range []*model.Event{&legacy, &other} {
+		if err := f.db.Create(event).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 迁移 047 之前写入的事件没有 UID
+	if err := f.db.Exec("UPDATE events SET event_uid = NULL").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	current := legacy
+	current.ID = uuid.Nil
+	current.ClusterID = uuid.Nil
+	current.EventUID = "uid-1"
+	current.Count = 4
+	current.LastTimestamp = first.Add(5 * time.Minute)
+	state := f.state(t)
+	if err := state.putEvents([]model.Event{current}); err != nil {
+		t.Fatal(err)
+	}
+	if len(state.batch.AdoptEvents) != 1 || len(state.batch.UpsertEvents) != 0 {
+		t.Fatalf("adopted = %d, upserted = %d, want 1 and 0", len(state.batch.AdoptEvents), len(state.batch.UpsertEvents))
+	}
+	if _, err := state.commit(f.syncRepo); err != nil {
+		t.Fatal(err)
+	}
+
+	events := f.storedEvents(t)
+	if len(events) != 2 {
+		t.Fatalf("stored %d events, want 2", len(events))
+	}
+	for _, event := range events {
+		switch event.ID {
+		case legacy.ID:
+			if event.EventUID != "uid-1" || event.Count != 4 {
+				t.Errorf("legacy event uid = %q, count = %d, want uid-1 and 4", event.EventUID, event.Count)
+			}
+		case other.ID:
+			if event.EventUID != "" {
+				t.Errorf("unrelated legacy event uid = %q, want empty", event.EventUID)
+			}
+		default:
+			t.Errorf("unexpected event %s", event.ID)
+		}
+	}
+
+	// 补写 UID 后再次同步按 UID 去重
+	f.cache.InvalidateCluster(f.clusterID)
+	state = f.state(t)
+	if err := state.putEvents([]model.Event{current}); err != nil {
+		t.Fatal(err)
+	}
+	if state.unchangedEvents != 1 || !state.batch.Empty() {
+		t.Errorf("resync unchanged = %d, batch empty = %v, want 1 and true", state.unchangedEvents, state.batch.Empty())
+	}
+}
+
+// newTestClusterInformer 只包含节点缓存与写入依赖的 Informer，不连接集群
+func newTestClusterInformer(f *resourceSyncFixture, nodes ...string) *ClusterInformer {
+	nodeInformer := cache.NewSharedIndexInformer(&cache.ListWatch{}, &corev1.Node{}, 0, cache.Indexers{})
+	for _, name := range nodes {
+		nodeInformer.GetStore().Add(&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			}},
+		})
+	}
+	return &ClusterInformer{
+		clusterID:     f.clusterID,
+		nodeInformer:  nodeInformer,
+		stopCh:        make(chan struct{}),
+		nodeRepo:      f.nodeRepo,
+		eventRepo:     f.eventRepo,
+		syncRepo:      f.syncRepo,
+		cache:         f.cache,
+		dirtyNodes:    make(map[string]struct{}),
+		pendingEvents: make(map[string]*corev1.Event),
+	}
+}
+
+func TestClusterInformerDeletesVanishedNodesAtStartup(t *testing.T) {
+	tests := []struct {
+		name string
+		// cached 启动前缓存中已有的节点
+		cached []string
+	}{
+		{name: "baseline loaded from database"},
+		{name: "vanished node missing from cache", cached: []string{"node-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newResourceSyncFixture(t)
+			stored := map[string]model.Node{
+				"node-1": f.createNode(t, "node-1", "Ready"),
+				"node-2": f.createNode(t, "node-2", "Ready"),
+			}
+			if tt.cached != nil {
+				var cached []model.Node
+				for _, name := range tt.cached {
+					cached = append(cached, stored[name])
+				}
+				f.cache.SetNodes(f.clusterID, cached)
+			}
+
+			ci := newTestClusterInformer(f, "node-1")
+			ci.markVanishedNodes()
+			ci.flushPendingChanges()
+
+			nodes := f.storedNodes(t)
+			if _, ok := nodes["node-2"]; ok {
+				t.Error("node-2 removed while the informer was down should be deleted")
+			}
+			if row, ok := nodes["node-1"]; !ok || row.ID != stored["node-1"].ID {
+				t.Errorf("node-1 should be kept with id %s", stored["node-1"].ID)
+			}
+			if len(ci.dirtyNodes) != 0 {
+				t.Errorf("%d dirty nodes left after flush", len(ci.dirtyNodes))
+			}
+		})
+	}
+}
+
+func TestClusterInformerStopFlushesPendingChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		discard bool
+		nodes   int
+		events  int
+	}{
+		{name: "stop flushes pending changes", nodes: 1, events: 1},
+		{name: "discard drops pending changes", discard: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newResourceSyncFixture(t)
+			ci := newTestClusterInformer(f, "node-1")
+			ci.markNodeDirty("node-1")
+			ci.queueEvent(&corev1.Event{
+				ObjectMeta:     metav1.ObjectMeta{UID: types.UID("uid-1"), Name: "node-1.event"},
+				InvolvedObject: corev1.ObjectReference{Kind: "Node", Name: "node-1"},
+				Type:           corev1.EventTypeWarning,
+				Reason:         "NodeNotReady",
+				Message:        "Node node-1 status is now: NodeNotReady",
+				FirstTimestamp: metav1.NewTime(time.Now().Add(-time.Minute)),
+				LastTimestamp:  metav1.NewTime(time.Now()),
+				Count:          1,
+			})
+
+			if tt.discard {
+				ci.Discard()
+			} else {
+				ci.Stop()
+			}
+
+			if got := len(f.storedNodes(t)); got != tt.nodes {
+				t.Errorf("stored %d nodes, want %d", got, tt.nodes)
+			}
+			if got := len(f.storedEvents(t)); got != tt.events {
+				t.Errorf("stored %d events, want %d", got, tt.events)
+			}
+		})
+	}
+}
@@ -28,6 +28,9 @@ type ResourceSyncWorker struct {
 	securityPolicyRepo           *repository.SecurityPolicyRepository
 	clusterManager               *service.ClusterManager
 	encryptionSvc                *service.EncryptionService
+	syncRepo                     *repository.ResourceSyncRepository
+	// 节点与事件上次写入的状态，用于只写入变化的行
+	cache                        ResourceCache
 	// 新增：存量资源分类工作器
 	resourceClassificationWorker *ResourceClassificationWorker
 	wg                           sync.WaitGroup
@@ -48,6 +51,7 @@ func NewResourceSyncWorker(
 	securityPolicyRepo *repository.SecurityPolicyRepository,
 	clusterManager *service.ClusterManager,
 	encryptionSvc *service.EncryptionService,
+	syncRepo *repository.ResourceSyncRepository,
 	// 新增参数（可选）
 	resourceClassificationWorker ...*ResourceClassificationWorker,
 ) *ResourceSyncWorker {
@@ -68,6 +72,8 @@ func NewResourceSyncWorker(
 		securityPolicyRepo:           securityPolicyRepo,
 		clusterManager:               clusterManager,
 		encryptionSvc:                encryptionSvc,
+		syncRepo:                     syncRepo,
+		cache:                        NewMemoryResourceCache(15 * time.Minute),
 		resourceClassificationWorker: rcw,
 		ctx:                          ctx,
 		cancel:                       cancel,
@@ -120,39 +126,75 @@ func (w *ResourceSyncWorker) performResourceSync() {
 
 	log.Printf("Found %d active clusters for resource sync", len(clusters))
 
+	var (
+		cycle sync.WaitGroup
+		mu    sync.Mutex
+		total repository.ResourceSyncResult
+	)
 	for _, cluster := range clusters {
 		w.wg.Add(1)
+		cycle.Add(1)
 		go func(c model.Cluster) {
 			defer w.wg.Done()
-			w.syncClusterData(c)
+			defer cycle.Done()
+			result := w.syncClusterData(c)
+
+			mu.Lock()
+			total.NodesUpserted += result.NodesUpserted
+			total.NodesDeleted += result.NodesDeleted
+			total.EventsUpserted += result.EventsUpserted
+			mu.Unlock()
 		}(*cluster)
 	}
+
+	// 汇总本轮各集群写入的行数
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		cycle.Wait()
+		log.Printf("[RESOURCE-SYNC] Cycle completed for %d clusters: %d rows written (nodes: %d upserted, %d deleted; events: %d upserted)",
+			len(clusters), total.RowsWritten(), total.NodesUpserted, total.NodesDeleted, total.EventsUpserted)
+	}()
 }
 
-func (w *ResourceSyncWorker) syncClusterData(cluster model.Cluster) {
+// syncClusterData 同步单个集群，返回本次写入的节点与事件行数
+func (w *ResourceSyncWorker) syncClusterData(cluster model.Cluster) repository.ResourceSyncResult {
+	var result repository.ResourceSyncResult
+
 	w.sem <- struct{}{}
 	defer func() { <-w.sem }()
 
+	if err := w.clusterManager.AllowRequest(cluster.ID); err != nil {
+		log.Printf("Skipping resource sync for cluster %s: %v", cluster.Name, err)
+		return result
+	}
+
 	kubeconfig, err := w.encryptionSvc.Decrypt(cluster.KubeconfigEncrypted)
 	if err != nil {
 		log.Printf("Failed to decrypt kubeconfig for cluster %s: %v", cluster.Name, err)
-		return
+		return result
 	}
 
 	ctx, cancel := context.WithTimeout(w.ctx, 30*time.Second)
 	defer cancel()
 
-	clientset, err := w.clusterManager.GetClient(ctx, kubeconfig)
+	clientset, err := w.clusterManager.GetClientForCluster(ctx, cluster.ID, kubeconfig)
 	if err != nil {
 		log.Printf("Failed to get client for cluster %s: %v", cluster.Name, err)
-		return
+		return result
 	}
 
-	// 同步节点信息
-	w.syncNodes(ctx, clientset, cluster.ID)
-
-	// 同步事件信息
-	w.syncEvents(ctx, clientset, cluster.ID)
+	// 同步节点与事件信息，变更在同一事务内批量写入
+	state, err := newResourceSyncState(cluster.ID, w.cache, w.nodeRepo, w.eventRepo)
+	if err != nil {
+		log.Printf("Failed to load sync state for cluster %s: %v", cluster.Name, err)
+	} else {
+		w.syncNodes(ctx, clientset, state)
+		w.syncEvents(ctx, clientset, state)
+		if result, err = state.commit(w.syncRepo); err != nil {
+			log.Printf("Failed to write nodes and events for cluster %s: %v", cluster.Name, err)
+		}
+	}
 
 	// 同步集群资源使用情况并获取节点信息
 	nodes := w.syncClusterResources(ctx, clientset, cluster.ID)
@@ -178,53 +220,30 @@ func (w *ResourceSyncWorker) syncClusterData(cluster model.Cluster) {
 	w.updateAPIServerURL(ctx, nodes, cluster.ID)
 
 	log.Printf("Resource sync completed for cluster %s", cluster.Name)
+	return result
 }
 
-func (w *ResourceSyncWorker) syncNodes(ctx context.Context, clientset *kubernetes.Clientset, clusterID uuid.UUID) {
+// syncNodes 将集群当前节点与上次写入的状态比较，变化的节点与已删除的节点加入写入批次
+func (w *ResourceSyncWorker) syncNodes(ctx context.Context, clientset *kubernetes.Clientset, state *resourceSyncState) {
 	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		log.Printf("Failed to list nodes: %v", err)
 		return
 	}
 
-	// 先删除不存在的节点
-	existingNodes, err := w.nodeRepo.GetByClusterID(clusterID.String())
-	if err != nil {
-		log.Printf("Failed to get existing nodes: %v", err)
-		return
-	}
-
-	existingNodeMap := make(map[string]bool)
-	for _, node := range existingNodes {
-		existingNodeMap[node.Name] = true
-	}
-
+	current := make([]model.Node, 0, len(nodes.Items))
 	for _, k8sNode := range nodes.Items {
 		nodeName := k8sNode.Name
-		if existingNodeMap[nodeName] {
-			existingNodeMap[nodeName] = false // 标记为仍然存在
-		}
-
-		// 先检查节点是否已存在
-		existingNode, err := w.nodeRepo.GetByName(clusterID.String(), nodeName)
-		if err != nil && err != gorm.ErrRecordNotFound {
-			log.Printf("Failed to check existing node %s: %v", nodeName, err)
-			continue
-		}
 
 		// 转换节点信息
-		node := &model.Node{
-			ClusterID: clusterID,
-			Name:      nodeName,
-			Type:      w.getNodeType(k8sNode),
-			Status:    w.getNodeStatus(k8sNode),
-			Labels:    w.getNodeLabels(k8sNode),
-		}
-
-		// 如果节点已存在，使用原有ID
-		if err == nil && existingNode != nil {
-			node.ID = existingNode.ID
+		node := model.Node{
+			Name:   nodeName,
+			Type:   w.getNodeType(k8sNode),
+			Status: w.getNodeStatus(k8sNode),
+			Labels: w.getNodeLabels(k8sNode),
+			Taints: service.FormatNodeTaints(k8sNode.Spec.Taints),
 		}
+		service.ApplyNodeSystemInfo(&node, k8sNode.Status.NodeInfo)
 
 		// 设置资源信息（显示逻辑CPU数）
 		if capacity := k8sNode.Status.Capacity; capacity != nil {
@@ -251,30 +270,18 @@ func (w *ResourceSyncWorker) syncNodes(ctx context.Context, clientset *kubernete
 			}
 		}
 
-		// 使用UpsertSingle方法创建或更新节点
-		if err := w.nodeRepo.UpsertSingle(node); err != nil {
-			log.Printf("Failed to upsert node %s: %v", nodeName, err)
-		}
+		current = append(current, node)
 	}
 
-	// 删除不存在的节点
-	for nodeName, exists := range existingNodeMap {
-		if exists {
-			node, err := w.nodeRepo.GetByName(clusterID.String(), nodeName)
-			if err != nil {
-				log.Printf("Failed to get node %s for deletion: %v", nodeName, err)
-				continue
-			}
-			if err := w.nodeRepo.Delete(node.ID); err != nil {
-				log.Printf("Failed to delete node %s: %v", nodeName, err)
-			}
-		}
+	if err := state.setNodes(current); err != nil {
+		log.Printf("Failed to diff nodes for cluster %s: %v", state.clusterID, err)
+		return
 	}
-
-	log.Printf("Synced %d nodes for cluster %s", len(nodes.Items), clusterID)
+	log.Printf("Synced %d nodes for cluster %s", len(nodes.Items), state.clusterID)
 }
 
-func (w *ResourceSyncWorker) syncEvents(ctx context.Context, clientset *kubernetes.Clientset, clusterID uuid.UUID) {
+// syncEvents 将最近一小时的事件与上次写入的状态比较，新事件与次数或时间变化的事件加入写入批次
+func (w *ResourceSyncWorker) syncEvents(ctx context.Context, clientset *kubernetes.Clientset, state *resourceSyncState) {
 	// 获取最近一小时的事件
 	now := time.Now()
 	oneHourAgo := now.Add(-1 * time.Hour)
@@ -288,35 +295,15 @@ func (w *ResourceSyncWorker) syncEvents(ctx context.Context, clientset *kubernet
 	}
 
 	// 过滤最近一小时的事件
-	var recentEvents []corev1.Event
-	for _, event := range events.Items {
-		if event.LastTimestamp.Time.After(oneHourAgo) {
-			recentEvents = append(recentEvents, event)
-		}
-	}
-
-	for _, k8sEvent := range recentEvents {
-		// 检查事件是否已存在
-		exists, err := w.eventRepo.CheckExists(
-			clusterID.String(),
-			k8sEvent.Type,
-			k8sEvent.Message,
-			k8sEvent.Source.Component,
-			k8sEvent.FirstTimestamp.Time,
-			k8sEvent.LastTimestamp.Time,
-		)
-		if err != nil {
-			log.Printf("Failed to check event existence: %v", err)
+	var recentEvents []model.Event
+	for _, k8sEvent := range events.Items {
+		if !k8sEvent.LastTimestamp.Time.After(oneHourAgo) {
 			continue
 		}
-		if exists {
-			continue // 跳过已存在的事件
-		}
 
 		// 转换事件信息
-		event := &model.Event{
-			ID:             uuid.New(), // 生成新的UUID
-			ClusterID:      clusterID,
+		event := model.Event{
+			EventUID:       string(k8sEvent.UID),
 			EventType:      k8sEvent.Type,
 			Message:        k8sEvent.Message,
 			Severity:       w.getEventSeverity(k8sEvent.Type),
@@ -328,19 +315,18 @@ func (w *ResourceSyncWorker) syncEvents(ctx context.Context, clientset *kubernet
 
 		// 如果事件与特定节点相关，设置节点ID
 		if k8sEvent.InvolvedObject.Kind == "Node" {
-			node, err := w.nodeRepo.GetByName(clusterID.String(), k8sEvent.InvolvedObject.Name)
-			if err == nil && node != nil {
-				event.NodeID = &node.ID
-			}
+			event.NodeID = state.nodeID(k8sEvent.InvolvedObject.Name)
 		}
 
-		// 创建事件
-		if err := w.eventRepo.Create(event); err != nil {
-			log.Printf("Failed to create event: %v", err)
-		}
+		recentEvents = append(recentEvents, event)
+	}
+
+	if err := state.putEvents(recentEvents); err != nil {
+		log.Printf("Failed to diff events for cluster %s: %v", state.clusterID, err)
+		return
 	}
 
-	log.Printf("Synced %d events for cluster %s", len(recentEvents), clusterID)
+	log.Printf("Synced %d events for cluster %s", len(recentEvents), state.clusterID)
 }
 
 func (w *ResourceSyncWorker) syncClusterResources(ctx context.Context, clientset *kubernetes.Clientset, clusterID uuid.UUID) []corev1.Node {
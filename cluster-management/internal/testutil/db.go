@@ -0,0 +1,73 @@
+// Package testutil 测试使用的临时数据库
+package testutil
+
+import (
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// uuidDefault 以 SQLite 内置函数生成 UUID v4 文本，替代 PostgreSQL 的 gen_random_uuid()
+const uuidDefault = "(lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))), 2) || '-' || " +
+	"substr('89ab', 1 + (abs(random()) % 4), 1) || substr(lower(hex(randomblob(2))), 2) || '-' || lower(hex(randomblob(6))))"
+
+var (
+	uuidFunctionDefault = regexp.MustCompile(`DEFAULT (gen_random_uuid|uuid_generate_v4)\(\)`)
+	castDefault         = regexp.MustCompile(`(DEFAULT '[^']*')::\w+`)
+	timestampType       = regexp.MustCompile(`(?i)^timestamp with time zone`)
+)
+
+// OpenDB 在测试临时目录中创建 SQLite 数据库并按模型建表，测试结束后关闭
+// 模型中 PostgreSQL 专用的列类型与默认值会改写为 SQLite 等价形式；依赖 PostgreSQL 专有语法的查询不适用
+func OpenDB(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+	// WAL 模式下读写互不阻塞，事务外的查询不会因事务持有连接而死锁
+	dsn := "file:" + filepath.Join(t.TempDir(), "test.db") + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)"
+	db, err := gorm.Open(&dialector{Dialector: &sqlite.Dialector{DSN: dsn}}, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+// dialector 改写建表语句中 SQLite 不支持的默认值
+type dialector struct {
+	*sqlite.Dialector
+}
+
+func (d *dialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return migrator{Migrator: d.Dialector.Migrator(db)}
+}
+
+type migrator struct {
+	gorm.Migrator
+}
+
+func (m migrator) FullDataTypeOf(field *schema.Field) clause.Expr {
+	expr := m.Migrator.FullDataTypeOf(field)
+	expr.SQL = uuidFunctionDefault.ReplaceAllString(expr.SQL, "DEFAULT "+uuidDefault)
+	expr.SQL = castDefault.ReplaceAllString(expr.SQL, "$1")
+	// 驱动只按 DATETIME 等声明类型把返回的文本解析为时间
+	expr.SQL = timestampType.ReplaceAllString(expr.SQL, "datetime")
+	return expr
+}
+
+// 仓库通过 Dialector 转换唯一约束等错误，包装后仍需实现
+var _ gorm.ErrorTranslator = (*dialector)(nil)
@@ -0,0 +1,59 @@
+-- 机器SSH凭据：支持私钥认证、加密存储及共享凭据
+-- PostgreSQL 12+
+
+-- ===== 共享凭据表 =====
+CREATE TABLE IF NOT EXISTS machine_credentials (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT,
+    username VARCHAR(100) NOT NULL,
+    auth_type VARCHAR(20) NOT NULL DEFAULT 'password',
+    password TEXT,
+    private_key TEXT,
+    passphrase TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_machine_credential_auth_type CHECK (auth_type IN ('password', 'private_key'))
+);
+
+COMMENT ON COLUMN machine_credentials.password IS '加密后的密码';
+COMMENT ON COLUMN machine_credentials.private_key IS '加密后的私钥';
+COMMENT ON COLUMN machine_credentials.passphrase IS '加密后的私钥口令';
+
+-- ===== machines 表新增认证相关列 =====
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='machines' AND column_name='auth_type') THEN
+        ALTER TABLE machines ADD COLUMN auth_type VARCHAR(20) DEFAULT 'password';
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='machines' AND column_name='ssh_port') THEN
+        ALTER TABLE machines ADD COLUMN ssh_port INTEGER DEFAULT 22;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='machines' AND column_name='private_key') THEN
+        ALTER TABLE machines ADD COLUMN private_key TEXT;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='machines' AND column_name='passphrase') THEN
+        ALTER TABLE machines ADD COLUMN passphrase TEXT;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='machines' AND column_name='credential_id') THEN
+        ALTER TABLE machines ADD COLUMN credential_id UUID REFERENCES machine_credentials(id) ON DELETE SET NULL;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='machines' AND column_name='secrets_encrypted') THEN
+        ALTER TABLE machines ADD COLUMN secrets_encrypted BOOLEAN DEFAULT false;
+    END IF;
+    IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='machines' AND column_name='username') THEN
+        ALTER TABLE machines ALTER COLUMN username DROP NOT NULL;
+    END IF;
+END $$;
+
+-- 加密后的密码长度会超过255，且私钥/共享凭据认证时可为空
+ALTER TABLE machines ALTER COLUMN password TYPE TEXT;
+ALTER TABLE machines ALTER COLUMN password DROP NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_machines_credential_id ON machines(credential_id);
+
+DROP TRIGGER IF EXISTS update_machine_credentials_updated_at ON machine_credentials;
+CREATE TRIGGER update_machine_credentials_updated_at
+    BEFORE UPDATE ON machine_credentials
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,43 @@
+-- 创建任务记录部署驱动，并补齐模型使用但 009 未创建的列
+-- PostgreSQL 12+
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='create_tasks' AND column_name='provisioner') THEN
+        ALTER TABLE create_tasks ADD COLUMN provisioner VARCHAR(50) DEFAULT 'kk';
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='create_tasks' AND column_name='cluster_name') THEN
+        ALTER TABLE create_tasks ADD COLUMN cluster_name VARCHAR(255);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='create_tasks' AND column_name='config_yaml') THEN
+        ALTER TABLE create_tasks ADD COLUMN config_yaml TEXT;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='create_tasks' AND column_name='logs') THEN
+        ALTER TABLE create_tasks ADD COLUMN logs TEXT DEFAULT '';
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='create_tasks' AND column_name='error_msg') THEN
+        ALTER TABLE create_tasks ADD COLUMN error_msg TEXT;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='create_tasks' AND column_name='artifact_path') THEN
+        ALTER TABLE create_tasks ADD COLUMN artifact_path TEXT;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='create_tasks' AND column_name='with_packages') THEN
+        ALTER TABLE create_tasks ADD COLUMN with_packages BOOLEAN DEFAULT false;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='create_tasks' AND column_name='auto_approve') THEN
+        ALTER TABLE create_tasks ADD COLUMN auto_approve BOOLEAN DEFAULT false;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='create_tasks' AND column_name='kubernetes_version') THEN
+        ALTER TABLE create_tasks ADD COLUMN kubernetes_version VARCHAR(50);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='create_tasks' AND column_name='network_plugin') THEN
+        ALTER TABLE create_tasks ADD COLUMN network_plugin VARCHAR(50);
+    END IF;
+END $$;
+
+-- 任务状态使用 success，009 的约束未包含
+ALTER TABLE create_tasks DROP CONSTRAINT IF EXISTS create_tasks_status_check;
+ALTER TABLE create_tasks ADD CONSTRAINT create_tasks_status_check
+    CHECK (status IN ('pending', 'running', 'success', 'completed', 'failed', 'cancelled'));
+
+CREATE INDEX IF NOT EXISTS idx_create_tasks_provisioner ON create_tasks(provisioner);
@@ -0,0 +1,54 @@
+-- 集群模板：同名模板按版本递增保存，创建任务记录所用模板版本
+-- PostgreSQL 12+
+
+CREATE TABLE IF NOT EXISTS cluster_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    version INTEGER NOT NULL,
+    description TEXT,
+    provisioner VARCHAR(50),
+    kubernetes_version VARCHAR(50),
+    image_repo VARCHAR(255),
+    container_manager VARCHAR(50),
+    network_plugin VARCHAR(50),
+    pods_cidr VARCHAR(50),
+    service_cidr VARCHAR(50),
+    artifact_path TEXT,
+    with_packages BOOLEAN DEFAULT false,
+    addons JSONB DEFAULT '{}',
+    role_requirements JSONB DEFAULT '{}',
+    created_by VARCHAR(100),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cluster_templates_name_version ON cluster_templates(name, version);
+
+COMMENT ON COLUMN cluster_templates.version IS '模板版本号，同名模板递增';
+COMMENT ON COLUMN cluster_templates.addons IS '插件名 -> 插件配置';
+COMMENT ON COLUMN cluster_templates.role_requirements IS '角色 -> {min, max} 机器数量要求';
+
+DROP TRIGGER IF EXISTS update_cluster_templates_updated_at ON cluster_templates;
+CREATE TRIGGER update_cluster_templates_updated_at
+    BEFORE UPDATE ON cluster_templates
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- 创建任务记录模板版本与插件
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='create_tasks' AND column_name='template_id') THEN
+        ALTER TABLE create_tasks ADD COLUMN template_id UUID;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='create_tasks' AND column_name='template_name') THEN
+        ALTER TABLE create_tasks ADD COLUMN template_name VARCHAR(255);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='create_tasks' AND column_name='template_version') THEN
+        ALTER TABLE create_tasks ADD COLUMN template_version INTEGER DEFAULT 0;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='create_tasks' AND column_name='addons') THEN
+        ALTER TABLE create_tasks ADD COLUMN addons JSONB DEFAULT '{}';
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_create_tasks_template_id ON create_tasks(template_id);
@@ -0,0 +1,62 @@
+-- 集群退役：机器关联平台创建的集群，退役任务记录执行过程，集群记录删除前写入归档快照
+-- PostgreSQL 12+
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='machines' AND column_name='cluster_id') THEN
+        ALTER TABLE machines ADD COLUMN cluster_id UUID;
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_machines_cluster_id ON machines(cluster_id);
+
+COMMENT ON COLUMN machines.cluster_id IS '由平台创建并占用该机器的集群，退役后清空';
+
+CREATE TABLE IF NOT EXISTS cluster_decommissions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    cluster_id UUID NOT NULL,
+    cluster_name VARCHAR(255) NOT NULL,
+    provisioner VARCHAR(50),
+    final_backup BOOLEAN DEFAULT false,
+    backup_id UUID,
+    force BOOLEAN DEFAULT false,
+    machine_ids JSONB DEFAULT '{}',
+    status VARCHAR(50) DEFAULT 'pending',
+    progress INTEGER DEFAULT 0,
+    current_step VARCHAR(255),
+    logs TEXT,
+    error_msg TEXT,
+    archive_id UUID,
+    created_by VARCHAR(100),
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cluster_decommissions_cluster_id ON cluster_decommissions(cluster_id);
+CREATE INDEX IF NOT EXISTS idx_cluster_decommissions_status ON cluster_decommissions(status);
+
+COMMENT ON COLUMN cluster_decommissions.cluster_id IS '集群归档后记录已删除，不设置外键';
+COMMENT ON COLUMN cluster_decommissions.force IS '节点重置失败时仍继续归档与回收机器';
+
+DROP TRIGGER IF EXISTS update_cluster_decommissions_updated_at ON cluster_decommissions;
+CREATE TRIGGER update_cluster_decommissions_updated_at
+    BEFORE UPDATE ON cluster_decommissions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS cluster_archives (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    cluster_id UUID NOT NULL,
+    cluster_name VARCHAR(255) NOT NULL,
+    decommission_id UUID,
+    snapshot JSONB DEFAULT '{}',
+    archived_by VARCHAR(100),
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cluster_archives_cluster_id ON cluster_archives(cluster_id);
+CREATE INDEX IF NOT EXISTS idx_cluster_archives_cluster_name ON cluster_archives(cluster_name);
+
+COMMENT ON COLUMN cluster_archives.snapshot IS '集群、状态、节点、备份与机器信息快照，不含 kubeconfig';
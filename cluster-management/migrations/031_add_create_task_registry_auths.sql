@@ -0,0 +1,11 @@
+-- 创建任务保存加密的镜像仓库认证，拓扑其余配置已渲染在 config_yaml 中
+-- PostgreSQL 12+
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='create_tasks' AND column_name='registry_auths_encrypted') THEN
+        ALTER TABLE create_tasks ADD COLUMN registry_auths_encrypted TEXT;
+    END IF;
+END $$;
+
+COMMENT ON COLUMN create_tasks.registry_auths_encrypted IS '加密的镜像仓库认证，执行时注入配置，不写入 config_yaml';
@@ -0,0 +1,56 @@
+-- 集群证书到期检查：保存每次检查的证书与告警阈值，记录平台创建集群的证书轮换任务
+-- PostgreSQL 12+
+
+CREATE TABLE IF NOT EXISTS cluster_certificates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    cluster_id UUID NOT NULL REFERENCES clusters(id) ON DELETE CASCADE,
+    source VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    node_name VARCHAR(255),
+    subject VARCHAR(500),
+    issuer VARCHAR(500),
+    serial_number VARCHAR(100),
+    dns_names JSONB DEFAULT '[]',
+    is_ca BOOLEAN DEFAULT false,
+    not_before TIMESTAMPTZ,
+    not_after TIMESTAMPTZ,
+    days_remaining INTEGER,
+    alerted_threshold INTEGER DEFAULT 0,
+    checked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cluster_certificates_cluster_id ON cluster_certificates(cluster_id);
+CREATE INDEX IF NOT EXISTS idx_cluster_certificates_not_after ON cluster_certificates(not_after);
+
+COMMENT ON COLUMN cluster_certificates.source IS 'apiserver: 服务端证书; kubeconfig: 客户端证书与CA; node: 节点上的PKI文件';
+COMMENT ON COLUMN cluster_certificates.alerted_threshold IS '已告警的最小阈值天数(30/14/7)，0 表示未告警';
+
+DROP TRIGGER IF EXISTS update_cluster_certificates_updated_at ON cluster_certificates;
+CREATE TRIGGER update_cluster_certificates_updated_at
+    BEFORE UPDATE ON cluster_certificates
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS certificate_rotations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    cluster_id UUID NOT NULL REFERENCES clusters(id) ON DELETE CASCADE,
+    status VARCHAR(50) DEFAULT 'pending',
+    current_step VARCHAR(255),
+    logs TEXT,
+    error_msg TEXT,
+    created_by VARCHAR(100),
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_certificate_rotations_cluster_id ON certificate_rotations(cluster_id);
+
+DROP TRIGGER IF EXISTS update_certificate_rotations_updated_at ON certificate_rotations;
+CREATE TRIGGER update_certificate_rotations_updated_at
+    BEFORE UPDATE ON certificate_rotations
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
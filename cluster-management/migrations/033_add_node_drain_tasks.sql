@@ -0,0 +1,32 @@
+-- 节点排空任务：记录驱逐、被阻止与跳过的Pod
+-- PostgreSQL 12+
+
+CREATE TABLE IF NOT EXISTS node_drain_tasks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    cluster_id UUID NOT NULL REFERENCES clusters(id) ON DELETE CASCADE,
+    node_name VARCHAR(255) NOT NULL,
+    options JSONB DEFAULT '{}',
+    status VARCHAR(50) DEFAULT 'pending',
+    current_step VARCHAR(255),
+    evicted_pods JSONB DEFAULT '[]',
+    blocked_pods JSONB DEFAULT '[]',
+    skipped_pods JSONB DEFAULT '[]',
+    logs TEXT,
+    error_msg TEXT,
+    created_by VARCHAR(100),
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_node_drain_tasks_cluster_node ON node_drain_tasks(cluster_id, node_name);
+CREATE INDEX IF NOT EXISTS idx_node_drain_tasks_status ON node_drain_tasks(status);
+
+COMMENT ON COLUMN node_drain_tasks.blocked_pods IS '因PDB、超时或排空选项未能驱逐的Pod及原因';
+
+DROP TRIGGER IF EXISTS update_node_drain_tasks_updated_at ON node_drain_tasks;
+CREATE TRIGGER update_node_drain_tasks_updated_at
+    BEFORE UPDATE ON node_drain_tasks
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
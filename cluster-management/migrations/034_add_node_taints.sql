@@ -0,0 +1,11 @@
+-- 节点污点：由资源同步写入，格式为 key=value:effect
+-- PostgreSQL 12+
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='nodes' AND column_name='taints') THEN
+        ALTER TABLE nodes ADD COLUMN taints JSONB DEFAULT '[]'::jsonb;
+    END IF;
+END $$;
+
+COMMENT ON COLUMN nodes.taints IS '节点污点列表，格式为 key=value:effect';
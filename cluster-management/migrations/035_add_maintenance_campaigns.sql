@@ -0,0 +1,60 @@
+-- 节点滚动维护活动：按最大不可用数逐个节点排空、执行SSH命令并等待恢复
+-- PostgreSQL 12+
+
+CREATE TABLE IF NOT EXISTS maintenance_campaigns (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    cluster_id UUID NOT NULL REFERENCES clusters(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    selector VARCHAR(500),
+    node_names JSONB DEFAULT '[]',
+    max_unavailable INTEGER DEFAULT 1,
+    commands JSONB DEFAULT '[]',
+    drain_options JSONB DEFAULT '{}',
+    ready_timeout_seconds INTEGER DEFAULT 900,
+    status VARCHAR(50) DEFAULT 'pending',
+    current_step VARCHAR(255),
+    error_msg TEXT,
+    created_by VARCHAR(100),
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_campaigns_cluster_id ON maintenance_campaigns(cluster_id);
+CREATE INDEX IF NOT EXISTS idx_maintenance_campaigns_status ON maintenance_campaigns(status);
+
+COMMENT ON COLUMN maintenance_campaigns.status IS 'pending/running/paused/success/cancelled，节点失败时暂停';
+
+CREATE TABLE IF NOT EXISTS maintenance_node_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    campaign_id UUID NOT NULL REFERENCES maintenance_campaigns(id) ON DELETE CASCADE,
+    node_name VARCHAR(255) NOT NULL,
+    machine_id UUID,
+    sequence INTEGER,
+    status VARCHAR(50) DEFAULT 'pending',
+    step VARCHAR(50),
+    was_unschedulable BOOLEAN DEFAULT FALSE,
+    logs TEXT,
+    error_msg TEXT,
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_node_runs_campaign_id ON maintenance_node_runs(campaign_id);
+
+COMMENT ON COLUMN maintenance_node_runs.step IS '当前步骤：drain/commands/wait_ready/uncordon';
+
+DROP TRIGGER IF EXISTS update_maintenance_campaigns_updated_at ON maintenance_campaigns;
+CREATE TRIGGER update_maintenance_campaigns_updated_at
+    BEFORE UPDATE ON maintenance_campaigns
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_maintenance_node_runs_updated_at ON maintenance_node_runs;
+CREATE TRIGGER update_maintenance_node_runs_updated_at
+    BEFORE UPDATE ON maintenance_node_runs
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
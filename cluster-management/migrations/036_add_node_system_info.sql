@@ -0,0 +1,29 @@
+-- 节点运行时信息：kubelet/kube-proxy 版本、容器运行时、操作系统与内核，由资源同步写入
+-- PostgreSQL 12+
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='nodes' AND column_name='kubelet_version') THEN
+        ALTER TABLE nodes ADD COLUMN kubelet_version VARCHAR(50);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='nodes' AND column_name='kube_proxy_version') THEN
+        ALTER TABLE nodes ADD COLUMN kube_proxy_version VARCHAR(50);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='nodes' AND column_name='container_runtime') THEN
+        ALTER TABLE nodes ADD COLUMN container_runtime VARCHAR(50);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='nodes' AND column_name='container_runtime_version') THEN
+        ALTER TABLE nodes ADD COLUMN container_runtime_version VARCHAR(100);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='nodes' AND column_name='os_image') THEN
+        ALTER TABLE nodes ADD COLUMN os_image VARCHAR(255);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='nodes' AND column_name='kernel_version') THEN
+        ALTER TABLE nodes ADD COLUMN kernel_version VARCHAR(255);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='nodes' AND column_name='architecture') THEN
+        ALTER TABLE nodes ADD COLUMN architecture VARCHAR(50);
+    END IF;
+END $$;
+
+COMMENT ON COLUMN nodes.container_runtime IS '容器运行时名称，如 containerd、docker、cri-o';
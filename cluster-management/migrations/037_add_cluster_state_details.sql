@@ -0,0 +1,9 @@
+-- 集群组件级健康检查明细：apiserver readyz、etcd、调度器/控制器租约及 CoreDNS、CNI、kube-proxy、metrics-server
+-- PostgreSQL 12+
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='cluster_states' AND column_name='details') THEN
+        ALTER TABLE cluster_states ADD COLUMN details JSONB;
+    END IF;
+END $$;
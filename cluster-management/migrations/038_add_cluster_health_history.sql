@@ -0,0 +1,50 @@
+-- 集群健康历史：按状态变化记录区间与持续时间，超过保留期后按天降采样
+-- 用于可用率、故障时间线与 SLO 错误预算统计
+-- PostgreSQL 12+
+
+CREATE TABLE IF NOT EXISTS cluster_health_periods (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    cluster_id UUID NOT NULL REFERENCES clusters(id) ON DELETE CASCADE,
+    status VARCHAR(50) NOT NULL,
+    message TEXT,
+    started_at TIMESTAMPTZ NOT NULL,
+    ended_at TIMESTAMPTZ,
+    duration_seconds BIGINT DEFAULT 0,
+    last_checked_at TIMESTAMPTZ NOT NULL,
+    check_count INTEGER DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cluster_health_periods_cluster_started ON cluster_health_periods(cluster_id, started_at);
+CREATE INDEX IF NOT EXISTS idx_cluster_health_periods_ended_at ON cluster_health_periods(ended_at);
+-- 每个集群同一时间只有一个未结束的区间
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cluster_health_periods_open ON cluster_health_periods(cluster_id) WHERE ended_at IS NULL;
+
+COMMENT ON COLUMN cluster_health_periods.status IS 'healthy/degraded/unhealthy/disconnected，healthy 与 degraded 计为可用';
+
+CREATE TABLE IF NOT EXISTS cluster_health_daily (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    cluster_id UUID NOT NULL REFERENCES clusters(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    healthy_seconds BIGINT DEFAULT 0,
+    degraded_seconds BIGINT DEFAULT 0,
+    unhealthy_seconds BIGINT DEFAULT 0,
+    disconnected_seconds BIGINT DEFAULT 0,
+    incident_count INTEGER DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT idx_cluster_health_daily_cluster_day UNIQUE (cluster_id, day)
+);
+
+DROP TRIGGER IF EXISTS update_cluster_health_periods_updated_at ON cluster_health_periods;
+CREATE TRIGGER update_cluster_health_periods_updated_at
+    BEFORE UPDATE ON cluster_health_periods
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_cluster_health_daily_updated_at ON cluster_health_daily;
+CREATE TRIGGER update_cluster_health_daily_updated_at
+    BEFORE UPDATE ON cluster_health_daily
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
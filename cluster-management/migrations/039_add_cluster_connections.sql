@@ -0,0 +1,33 @@
+-- 集群连接设置：apiserver 仅能经由 SSH 堡垒机或 HTTP/SOCKS5 代理访问时使用
+-- 密码、私钥与代理地址均加密存储
+-- PostgreSQL 12+
+
+CREATE TABLE IF NOT EXISTS cluster_connections (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    cluster_id UUID NOT NULL UNIQUE REFERENCES clusters(id) ON DELETE CASCADE,
+    mode VARCHAR(20) NOT NULL DEFAULT 'direct',
+    bastion_host VARCHAR(255),
+    bastion_port INTEGER DEFAULT 22,
+    bastion_credential_id UUID REFERENCES machine_credentials(id) ON DELETE SET NULL,
+    bastion_user VARCHAR(100),
+    bastion_password TEXT,
+    bastion_private_key TEXT,
+    bastion_passphrase TEXT,
+    bastion_host_key TEXT,
+    proxy_url TEXT,
+    tls_server_name VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN cluster_connections.mode IS 'direct/ssh_bastion/proxy';
+COMMENT ON COLUMN cluster_connections.bastion_credential_id IS '复用机器凭据登录堡垒机，为空时使用 bastion_user 与加密的密码/私钥';
+COMMENT ON COLUMN cluster_connections.bastion_host_key IS 'authorized_keys 格式的堡垒机主机公钥，为空时不校验';
+COMMENT ON COLUMN cluster_connections.proxy_url IS '加密后的代理地址，可能包含认证信息';
+COMMENT ON COLUMN cluster_connections.tls_server_name IS '经隧道访问时用于校验 apiserver 证书的服务器名称';
+
+DROP TRIGGER IF EXISTS update_cluster_connections_updated_at ON cluster_connections;
+CREATE TRIGGER update_cluster_connections_updated_at
+    BEFORE UPDATE ON cluster_connections
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
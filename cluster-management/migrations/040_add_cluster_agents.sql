@@ -0,0 +1,36 @@
+-- 集群代理：部署在无入站连通性的集群内，主动连接管理端建立反向隧道
+-- 注册令牌一次性使用，令牌与代理密钥仅保存 SHA-256 摘要
+-- PostgreSQL 12+
+
+CREATE TABLE IF NOT EXISTS cluster_agents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    cluster_id UUID NOT NULL UNIQUE REFERENCES clusters(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    token_hash VARCHAR(64),
+    token_expires_at TIMESTAMPTZ,
+    secret_hash VARCHAR(64),
+    agent_version VARCHAR(50),
+    hostname VARCHAR(255),
+    remote_addr VARCHAR(255),
+    registered_at TIMESTAMPTZ,
+    connected_at TIMESTAMPTZ,
+    disconnected_at TIMESTAMPTZ,
+    last_heartbeat_at TIMESTAMPTZ,
+    last_heartbeat JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cluster_agents_token_hash ON cluster_agents(token_hash);
+
+COMMENT ON COLUMN cluster_agents.status IS 'pending/connected/disconnected';
+COMMENT ON COLUMN cluster_agents.token_hash IS '一次性注册令牌摘要，注册后清空';
+COMMENT ON COLUMN cluster_agents.secret_hash IS '代理密钥摘要，重新注册时替换';
+
+COMMENT ON COLUMN cluster_connections.mode IS 'direct/ssh_bastion/proxy/agent';
+
+DROP TRIGGER IF EXISTS update_cluster_agents_updated_at ON cluster_agents;
+CREATE TRIGGER update_cluster_agents_updated_at
+    BEFORE UPDATE ON cluster_agents
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,23 @@
+-- 租户成员：集群 API 代理据此为平台用户推导模拟（Impersonate）的用户组
+-- 用户组格式 <group_prefix>tenant:<租户名> 与 <group_prefix>tenant:<租户名>:<成员角色>
+-- PostgreSQL 12+
+
+CREATE TABLE IF NOT EXISTS tenant_members (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL DEFAULT 'member',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenant_members_tenant_user ON tenant_members(tenant_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_tenant_members_user_id ON tenant_members(user_id);
+
+COMMENT ON COLUMN tenant_members.role IS 'owner/member/viewer';
+
+DROP TRIGGER IF EXISTS update_tenant_members_updated_at ON tenant_members;
+CREATE TRIGGER update_tenant_members_updated_at
+    BEFORE UPDATE ON tenant_members
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,37 @@
+-- 用户 kubeconfig：按所属租户环境的命名空间授权，替代共享管理员 kubeconfig
+-- service_account 模式在集群内创建 ServiceAccount 并通过 TokenRequest 签发短期令牌，proxy 模式指向平台 API 代理
+-- PostgreSQL 12+
+
+CREATE TABLE IF NOT EXISTS user_kubeconfigs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    cluster_id UUID NOT NULL REFERENCES clusters(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    username VARCHAR(100) NOT NULL,
+    tenant_id UUID REFERENCES tenants(id) ON DELETE SET NULL,
+    mode VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    subject VARCHAR(255),
+    binding_name VARCHAR(255),
+    namespaces JSONB,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    revoked_by VARCHAR(100),
+    cleanup_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_kubeconfigs_cluster_id ON user_kubeconfigs(cluster_id);
+CREATE INDEX IF NOT EXISTS idx_user_kubeconfigs_user_id ON user_kubeconfigs(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_kubeconfigs_expires_at ON user_kubeconfigs(expires_at) WHERE status = 'active';
+
+COMMENT ON COLUMN user_kubeconfigs.mode IS 'service_account/proxy';
+COMMENT ON COLUMN user_kubeconfigs.status IS 'active/revoked/expired';
+COMMENT ON COLUMN user_kubeconfigs.subject IS 'RoleBinding 授权主体，service_account 模式为 namespace/name，proxy 模式为模拟用户名';
+COMMENT ON COLUMN user_kubeconfigs.namespaces IS '命名空间 -> 绑定的 ClusterRole';
+
+DROP TRIGGER IF EXISTS update_user_kubeconfigs_updated_at ON user_kubeconfigs;
+CREATE TRIGGER update_user_kubeconfigs_updated_at
+    BEFORE UPDATE ON user_kubeconfigs
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
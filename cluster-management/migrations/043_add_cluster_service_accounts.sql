@@ -0,0 +1,28 @@
+-- 最小权限导入：导入凭据只用于创建 taichu-manager ServiceAccount，集群只保存其令牌
+-- 令牌通过 TokenRequest 签发并在过期前轮换
+-- PostgreSQL 12+
+
+CREATE TABLE IF NOT EXISTS cluster_service_accounts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    cluster_id UUID NOT NULL UNIQUE REFERENCES clusters(id) ON DELETE CASCADE,
+    namespace VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    cluster_role VARCHAR(255) NOT NULL,
+    token_expires_at TIMESTAMPTZ NOT NULL,
+    last_rotated_at TIMESTAMPTZ,
+    last_rotation_error TEXT,
+    last_rotation_failed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cluster_service_accounts_token_expires_at ON cluster_service_accounts(token_expires_at);
+
+COMMENT ON COLUMN cluster_service_accounts.token_expires_at IS '当前令牌过期时间，剩余不足 manager_account.rotate_before 时轮换';
+COMMENT ON COLUMN cluster_service_accounts.last_rotation_error IS '最近一次轮换失败的原因，成功后清空';
+
+DROP TRIGGER IF EXISTS update_cluster_service_accounts_updated_at ON cluster_service_accounts;
+CREATE TRIGGER update_cluster_service_accounts_updated_at
+    BEFORE UPDATE ON cluster_service_accounts
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
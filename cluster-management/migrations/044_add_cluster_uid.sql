@@ -0,0 +1,6 @@
+-- 导入校验：记录集群 kube-system 命名空间 UID，用于识别以不同名称重复导入的同一集群
+-- PostgreSQL 12+
+
+ALTER TABLE clusters ADD COLUMN IF NOT EXISTS cluster_uid VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_clusters_cluster_uid ON clusters(cluster_uid);
@@ -0,0 +1,7 @@
+-- 重复集群识别：集群指纹由 kube-system 命名空间 UID 与根 CA 证书哈希计算
+-- PostgreSQL 12+
+
+ALTER TABLE clusters ADD COLUMN IF NOT EXISTS ca_hash VARCHAR(64);
+ALTER TABLE clusters ADD COLUMN IF NOT EXISTS fingerprint VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_clusters_fingerprint ON clusters(fingerprint);
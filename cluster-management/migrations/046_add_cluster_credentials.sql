@@ -0,0 +1,19 @@
+-- 集群凭据历史：更换 kubeconfig 时保留历史凭据用于回滚，kubeconfig 加密保存
+-- PostgreSQL 12+
+
+CREATE TABLE IF NOT EXISTS cluster_credentials (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    cluster_id UUID NOT NULL REFERENCES clusters(id) ON DELETE CASCADE,
+    kubeconfig_encrypted TEXT NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    rollback_of UUID,
+    api_server_url VARCHAR(255),
+    fingerprint VARCHAR(64),
+    fingerprint_verified BOOLEAN DEFAULT FALSE,
+    active BOOLEAN DEFAULT FALSE,
+    reason TEXT,
+    created_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_cluster_credentials_cluster_id ON cluster_credentials(cluster_id, created_at DESC);
@@ -0,0 +1,6 @@
+-- 事件记录 Kubernetes Event UID，资源同步按 (cluster_id, event_uid) 批量 upsert，同一事件的次数与时间原地更新
+-- PostgreSQL 12+
+
+ALTER TABLE events ADD COLUMN IF NOT EXISTS event_uid VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_events_cluster_event_uid ON events(cluster_id, event_uid) WHERE event_uid <> '';
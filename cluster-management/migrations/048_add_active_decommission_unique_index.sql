@@ -0,0 +1,19 @@
+-- 同一集群只允许一个未结束的退役任务，避免并发请求对同一批节点重复执行删除
+-- PostgreSQL 12+
+
+-- 已存在的重复任务只保留最新的一个
+UPDATE cluster_decommissions d
+SET status = 'failed',
+    current_step = 'Superseded by another decommission of the same cluster',
+    error_msg = 'another decommission of the same cluster was already in progress'
+WHERE d.status IN ('pending', 'running')
+  AND EXISTS (
+      SELECT 1 FROM cluster_decommissions o
+      WHERE o.cluster_id = d.cluster_id
+        AND o.status IN ('pending', 'running')
+        AND (o.created_at > d.created_at OR (o.created_at = d.created_at AND o.id > d.id))
+  );
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_cluster_decommissions_active
+    ON cluster_decommissions(cluster_id)
+    WHERE status IN ('pending', 'running');